@@ -0,0 +1,355 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+)
+
+// PathEnv names the .env file to load instead of searching the default paths
+const PathEnv = "CONFIG_PATH"
+
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	stringSliceType = reflect.TypeOf([]string(nil))
+)
+
+var (
+	dotEnvMu sync.Mutex
+	// dotEnvKeys are the variables the last LoadDotEnv set from a file, so the next one may change them
+	dotEnvKeys map[string]bool
+)
+
+// LoadDotEnv loads the .env file named by CONFIG_PATH, else the first of paths that exists, the YAML or JSON
+// file named by CONFIG_FILE and the secret of CONFIG_SECRETS_PROVIDER. Variables already in the environment
+// win over the secret, which wins over the .env file, which wins over CONFIG_FILE. A CONFIG_PATH, CONFIG_FILE
+// or secret that cannot be read is an error; finding none of paths is not, since deployments set the
+// environment directly.
+//
+// Calling it again picks up edits to the files: variables they set before are updated, or unset when removed
+// from them, while those from the real environment still win.
+func LoadDotEnv(paths ...string) error {
+	dotEnvMu.Lock()
+	defer dotEnvMu.Unlock()
+
+	values, err := readDotEnv(paths)
+	if err != nil {
+		return err
+	}
+
+	// CONFIG_FILE may itself come from the .env file
+	var keys []string
+	if path := lookupEnv(FileEnv, values); path != "" {
+		settings, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", FileEnv, err)
+		}
+		if values == nil {
+			values = make(map[string]string, len(settings))
+		}
+		for key, value := range settings {
+			keys = append(keys, key)
+			if _, set := values[key]; !set {
+				values[key] = value
+			}
+		}
+		logger.Infof("loaded config file from: %s", path)
+	}
+
+	// Secrets are kept out of the container's environment, where docker inspect would show them
+	secrets, err := readSecrets(values)
+	if err != nil {
+		return err
+	}
+	if len(secrets) > 0 && values == nil {
+		values = make(map[string]string, len(secrets))
+	}
+	for key, value := range secrets {
+		values[key] = value
+	}
+	fileKeys = keys
+	applyDotEnv(values)
+	return nil
+}
+
+func readDotEnv(paths []string) (map[string]string, error) {
+	if path := os.Getenv(PathEnv); path != "" {
+		values, err := godotenv.Read(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", PathEnv, err)
+		}
+		logger.Infof("loaded .env file from: %s", path)
+		return values, nil
+	}
+
+	var err error
+	for _, path := range paths {
+		var values map[string]string
+		if values, err = godotenv.Read(path); err == nil {
+			logger.Infof("loaded .env file from: %s", path)
+			return values, nil
+		}
+	}
+	logger.Warnf("could not load .env file from any path: %v", err)
+	return nil, nil
+}
+
+// lookupEnv returns key as the environment will hold it once values are applied
+func lookupEnv(key string, values map[string]string) string {
+	if value, set := os.LookupEnv(key); set && !dotEnvKeys[key] {
+		return value
+	}
+	return values[key]
+}
+
+// applyDotEnv sets values that are unset or were set by the previous file, and unsets what that file set
+// and this one no longer does
+func applyDotEnv(values map[string]string) {
+	keys := make(map[string]bool, len(values))
+	for key, value := range values {
+		if _, set := os.LookupEnv(key); set && !dotEnvKeys[key] {
+			continue
+		}
+		os.Setenv(key, value)
+		keys[key] = true
+	}
+	for key := range dotEnvKeys {
+		if !keys[key] {
+			os.Unsetenv(key)
+		}
+	}
+	dotEnvKeys = keys
+}
+
+// Load sets each field of the struct cfg points to from the variable named by its env tag, e.g.
+//
+//	RequestTimeout time.Duration `env:"REQUEST_TIMEOUT_SECONDS" default:"30" unit:"s"`
+//
+// Fields without one are left alone, so they can be filled in afterwards. The other tags are:
+//   - default: the value used while the variable is unset. A variable set to an empty value gives an empty
+//     string or list; for other types it means nothing and the default applies.
+//   - required:"true": the variable must be set and not empty, see Require
+//   - unit: for a time.Duration, reads the variable as a whole number of that unit, e.g. "s" for *_SECONDS
+//
+// Fields may be strings, bools, integers, floats, time.Durations or []strings. Every invalid field is
+// reported, not just the first.
+func Load(cfg any) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: Load needs a pointer to a struct, got %T", cfg)
+	}
+	v = v.Elem()
+
+	var errs []error
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		value, set := os.LookupEnv(key)
+		if field.Tag.Get("required") == "true" {
+			var err error
+			if value, err = Require(key); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		} else if !set || (value == "" && !keepsEmpty(field.Type)) {
+			if value, set = field.Tag.Lookup("default"); !set {
+				continue
+			}
+		}
+
+		if err := setField(v.Field(i), value, field.Tag.Get("unit")); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Changed returns the env keys of the fields that differ between two structs of the same type, such as a
+// running configuration and one just loaded. Fields without an env tag are not compared.
+func Changed(old, new any) []string {
+	oldValue, newValue := reflect.Indirect(reflect.ValueOf(old)), reflect.Indirect(reflect.ValueOf(new))
+	if oldValue.Kind() != reflect.Struct || oldValue.Type() != newValue.Type() {
+		panic(fmt.Sprintf("config: Changed needs two structs of the same type, got %T and %T", old, new))
+	}
+
+	var keys []string
+	for i := range oldValue.NumField() {
+		key := oldValue.Type().Field(i).Tag.Get("env")
+		if key != "" && !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// String returns the value of key, or defaultValue while key is unset
+func String(key, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// Require returns the value of key. Unset and empty are told apart in the error, as an empty value is
+// usually a template or secret that rendered to nothing rather than a forgotten setting.
+func Require(key string) (string, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return "", fmt.Errorf("%s is required", key)
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s is set but empty", key)
+	}
+	return value, nil
+}
+
+// Int returns key as an integer, or defaultValue while it is unset or empty
+func Int(key string, defaultValue int) (int, error) {
+	return parse(key, defaultValue, func(value string) (int, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q", value)
+		}
+		return n, nil
+	})
+}
+
+// Bool returns key as a flag, or defaultValue while it is unset or empty. true, 1 and yes are true;
+// false, 0 and no are false.
+func Bool(key string, defaultValue bool) (bool, error) {
+	return parse(key, defaultValue, parseBool)
+}
+
+// Duration returns key as a Go duration such as "30s" or "24h", or defaultValue while it is unset or empty.
+// Negative durations are rejected.
+func Duration(key string, defaultValue time.Duration) (time.Duration, error) {
+	return parse(key, defaultValue, func(value string) (time.Duration, error) {
+		return parseDuration(value, "")
+	})
+}
+
+// StringSlice returns key as a comma-separated list, trimming spaces around each item and dropping empty
+// ones, or defaultValue while it is unset
+func StringSlice(key string, defaultValue []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+	return splitList(value)
+}
+
+func parse[T any](key string, defaultValue T, parseValue func(string) (T, error)) (T, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := parseValue(value)
+	if err != nil {
+		return defaultValue, fmt.Errorf("%s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// keepsEmpty reports whether an empty value is meaningful for a field of type t
+func keepsEmpty(t reflect.Type) bool {
+	return t.Kind() == reflect.String || t == stringSliceType
+}
+
+func setField(field reflect.Value, value, unit string) error {
+	switch {
+	case field.Type() == durationType:
+		d, err := parseDuration(value, unit)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case field.Type() == stringSliceType:
+		field.Set(reflect.ValueOf(splitList(value)))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Bool:
+		b, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case field.CanInt():
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(n)
+	case field.CanUint():
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", value)
+		}
+		field.SetUint(n)
+	case field.CanFloat():
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "1", "yes":
+		return true, nil
+	case "false", "0", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", value)
+}
+
+// parseDuration parses a Go duration, or with a unit such as "s" a whole number of that unit
+func parseDuration(value, unit string) (time.Duration, error) {
+	var d time.Duration
+	if unit == "" {
+		var err error
+		if d, err = time.ParseDuration(value); err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+	} else {
+		unitDuration, err := time.ParseDuration("1" + unit)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration unit %q", unit)
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q", value)
+		}
+		d = time.Duration(n) * unitDuration
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return d, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
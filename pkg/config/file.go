@@ -0,0 +1,141 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names a YAML or JSON file of settings, keyed by variable name, e.g.
+//
+//	APP_PORT: 8080
+//	ALLOWED_ORIGINS: [https://shop.example.com, https://admin.example.com]
+//	ROLE_PERMISSIONS_JSON: {catalog_manager: [product:write]}
+//
+// Lists become comma-separated values and objects JSON, the forms the variables themselves take.
+const FileEnv = "CONFIG_FILE"
+
+// fileKeys are the keys of the file the last LoadDotEnv read from CONFIG_FILE, for CheckFileKeys
+var fileKeys []string
+
+// readConfigFile reads path as YAML, or as JSON when it ends in .json, into variable values
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&settings)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &settings)
+	default:
+		return nil, fmt.Errorf("%s must end in .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(settings))
+	var errs []error
+	for key, setting := range settings {
+		value, err := fileValue(setting)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, key, err))
+			continue
+		}
+		values[key] = value
+	}
+	return values, errors.Join(errs...)
+}
+
+// fileValue turns a decoded setting into the text of its variable
+func fileValue(setting any) (string, error) {
+	switch v := setting.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case []any, map[string]any:
+				return marshalSetting(v)
+			}
+			value, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, value)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		return marshalSetting(v)
+	}
+	return "", fmt.Errorf("unsupported value %v; quote it to pass it as text", setting)
+}
+
+func marshalSetting(setting any) (string, error) {
+	data, err := json.Marshal(setting)
+	if err != nil {
+		return "", fmt.Errorf("cannot be written as JSON: %w", err)
+	}
+	return string(data), nil
+}
+
+// CheckFileKeys reports every key of the CONFIG_FILE last loaded that is neither an env tag of the struct
+// cfg points to nor one of untagged, the variables read without a tag. Environment variables cannot be
+// told apart from those of other programs, but a file belongs to one service, so a key it does not know is
+// a typo or a setting of a newer version.
+func CheckFileKeys(cfg any, untagged ...string) error {
+	known := make(map[string]bool, len(untagged))
+	for _, key := range untagged {
+		known[key] = true
+	}
+	t := reflect.TypeOf(cfg)
+	if t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: CheckFileKeys needs a pointer to a struct, got %T", cfg)
+	}
+	for i := range t.Elem().NumField() {
+		if key := t.Elem().Field(i).Tag.Get("env"); key != "" {
+			known[key] = true
+		}
+	}
+
+	dotEnvMu.Lock()
+	defer dotEnvMu.Unlock()
+
+	var unknown []string
+	for _, key := range fileKeys {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return fmt.Errorf("%s: unknown settings %s", FileEnv, strings.Join(unknown, ", "))
+	}
+	return nil
+}
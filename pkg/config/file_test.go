@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to name in a temporary directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// loadFiles runs LoadDotEnv with the .env file dotEnv, when not empty, and the config file configFile, and
+// unsets whatever they set once the test ends
+func loadFiles(t *testing.T, dotEnv, configFile string) error {
+	t.Helper()
+	t.Cleanup(func() {
+		dotEnvMu.Lock()
+		defer dotEnvMu.Unlock()
+		applyDotEnv(nil)
+		fileKeys = nil
+	})
+	if dotEnv != "" {
+		t.Setenv(PathEnv, dotEnv)
+	}
+	t.Setenv(FileEnv, configFile)
+	return LoadDotEnv()
+}
+
+func TestReadConfigFile(t *testing.T) {
+	want := map[string]string{
+		"APP_PORT":              "8080",
+		"RATE":                  "0.5",
+		"TLS_ENABLED":           "true",
+		"JWT_SECRET":            "s3cret",
+		"ALLOWED_ORIGINS":       "https://shop.example.com,https://admin.example.com",
+		"ROLE_PERMISSIONS_JSON": `{"catalog_manager":["product:write"]}`,
+		"NESTED_LIST":           `[["a","b"],["c"]]`,
+		"EMPTY":                 "",
+	}
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "settings.yaml",
+			content: `
+APP_PORT: 8080
+RATE: 0.5
+TLS_ENABLED: true
+JWT_SECRET: s3cret
+ALLOWED_ORIGINS: [https://shop.example.com, https://admin.example.com]
+ROLE_PERMISSIONS_JSON: {catalog_manager: [product:write]}
+NESTED_LIST: [[a, b], [c]]
+EMPTY:
+`,
+		},
+		{
+			name: "settings.json",
+			content: `{
+				"APP_PORT": 8080,
+				"RATE": 0.5,
+				"TLS_ENABLED": true,
+				"JWT_SECRET": "s3cret",
+				"ALLOWED_ORIGINS": ["https://shop.example.com", "https://admin.example.com"],
+				"ROLE_PERMISSIONS_JSON": {"catalog_manager": ["product:write"]},
+				"NESTED_LIST": [["a", "b"], ["c"]],
+				"EMPTY": null
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := readConfigFile(writeFile(t, tt.name, tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(values) != len(want) {
+				t.Errorf("values = %v, want %v", values, want)
+			}
+			for key, value := range want {
+				if values[key] != value {
+					t.Errorf("%s = %q, want %q", key, values[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestReadConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "settings.toml", content: "APP_PORT = 8080", want: "must end in .yaml, .yml or .json"},
+		{name: "settings.yaml", content: "APP_PORT: [8080", want: "settings.yaml"},
+		{name: "settings.json", content: `{"APP_PORT": }`, want: "settings.json"},
+		{name: "dates.yaml", content: "STARTS: 2026-10-16", want: "STARTS: unsupported value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readConfigFile(writeFile(t, tt.name, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("readConfigFile = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := readConfigFile(filepath.Join(t.TempDir(), "missing.yaml")); !os.IsNotExist(err) {
+		t.Errorf("missing file: readConfigFile = %v, want a not-exist error", err)
+	}
+}
+
+func TestLoadDotEnvReadsTheConfigFile(t *testing.T) {
+	t.Setenv("FILE_TEST_TIMEOUT", "5")
+	file := writeFile(t, "settings.yaml", "FILE_TEST_PORT: 8080\nFILE_TEST_TIMEOUT: 30\nFILE_TEST_ORIGINS: [a, b]\nFILE_TEST_MODE: file\n")
+	dotEnv := writeFile(t, ".env", "FILE_TEST_MODE=dotenv\n")
+
+	if err := loadFiles(t, dotEnv, file); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"FILE_TEST_PORT":    "8080",
+		"FILE_TEST_ORIGINS": "a,b",
+		// The environment wins over the file
+		"FILE_TEST_TIMEOUT": "5",
+		// And so does the .env file
+		"FILE_TEST_MODE": "dotenv",
+	} {
+		if got := os.Getenv(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadDotEnvPicksUpConfigFileEdits(t *testing.T) {
+	file := writeFile(t, "settings.yaml", "FILE_TEST_PORT: 8080\nFILE_TEST_MODE: file\n")
+	if err := loadFiles(t, "", file); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(file, []byte("FILE_TEST_PORT: 9090\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadDotEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("FILE_TEST_PORT"); got != "9090" {
+		t.Errorf("FILE_TEST_PORT = %q, want the edited 9090", got)
+	}
+	if _, set := os.LookupEnv("FILE_TEST_MODE"); set {
+		t.Error("FILE_TEST_MODE is still set after it was removed from the file")
+	}
+}
+
+func TestLoadDotEnvRefusesAnUnreadableConfigFile(t *testing.T) {
+	err := loadFiles(t, "", filepath.Join(t.TempDir(), "missing.yaml"))
+	if err == nil || !strings.HasPrefix(err.Error(), FileEnv) {
+		t.Fatalf("LoadDotEnv = %v, want a %s error", err, FileEnv)
+	}
+}
+
+func TestCheckFileKeys(t *testing.T) {
+	type settings struct {
+		Port    int    `env:"FILE_TEST_PORT"`
+		Mode    string `env:"FILE_TEST_MODE"`
+		Derived string
+	}
+	file := writeFile(t, "settings.yaml", "FILE_TEST_PORT: 8080\nFILE_TEST_MODE: file\nFILE_TEST_FLAGS_JSON: {a: true}\nFILE_TEST_PROT: 1\nAAA_TYPO: x\n")
+	if err := loadFiles(t, "", file); err != nil {
+		t.Fatal(err)
+	}
+
+	err := CheckFileKeys(&settings{}, "FILE_TEST_FLAGS_JSON")
+	want := "CONFIG_FILE: unknown settings AAA_TYPO, FILE_TEST_PROT"
+	if err == nil || err.Error() != want {
+		t.Fatalf("CheckFileKeys = %v, want %q", err, want)
+	}
+	if err := CheckFileKeys(settings{}); err == nil {
+		t.Error("CheckFileKeys accepted a struct that is not a pointer")
+	}
+}
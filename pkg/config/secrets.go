@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+)
+
+const (
+	// SecretsProviderEnv picks where secrets are loaded from at startup: "env", the default, loads none, and
+	// "aws-secrets-manager" loads the secret named by SecretARNEnv
+	SecretsProviderEnv = "CONFIG_SECRETS_PROVIDER"
+	// SecretARNEnv names the secret to load, a JSON object of settings keyed by variable name such as
+	// {"JWT_SECRET": "..."}
+	SecretARNEnv = "CONFIG_SECRET_ARN"
+
+	providerEnv               = "env"
+	providerAWSSecretsManager = "aws-secrets-manager"
+
+	// secretsTimeout bounds fetching the secret, so an unreachable provider fails startup instead of hanging it
+	secretsTimeout = 10 * time.Second
+)
+
+// SecretsLoader fetches a secret holding settings keyed by variable name
+type SecretsLoader interface {
+	Load(secretName string) (map[string]string, error)
+}
+
+// MockSecretsLoader serves secrets from memory, keyed by secret name, in place of a real provider
+type MockSecretsLoader map[string]map[string]string
+
+func (m MockSecretsLoader) Load(secretName string) (map[string]string, error) {
+	secret, ok := m[secretName]
+	if !ok {
+		return nil, fmt.Errorf("secret %s not found", secretName)
+	}
+	return secret, nil
+}
+
+var (
+	secretsMu sync.Mutex
+	// secretsLoader is set by SetSecretsLoader, or created on the first load from aws-secrets-manager
+	secretsLoader SecretsLoader
+)
+
+// SetSecretsLoader makes loader serve the secrets of any provider but "env", e.g. a MockSecretsLoader in tests
+func SetSecretsLoader(loader SecretsLoader) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	secretsLoader = loader
+}
+
+// LoadSecrets sets the variables still unset from the secret of CONFIG_SECRETS_PROVIDER, for services that
+// load their .env file themselves rather than with LoadDotEnv. Called before that, the secret wins over the
+// file but not over the real environment.
+func LoadSecrets() error {
+	secrets, err := readSecrets(nil)
+	if err != nil {
+		return err
+	}
+	for key, value := range secrets {
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	return nil
+}
+
+// readSecrets loads the secret of the provider named in the environment or values, or nothing for "env"
+func readSecrets(values map[string]string) (map[string]string, error) {
+	provider := lookupEnv(SecretsProviderEnv, values)
+	switch provider {
+	case "", providerEnv:
+		return nil, nil
+	case providerAWSSecretsManager:
+	default:
+		return nil, fmt.Errorf("%s: unknown provider %q, want %s or %s", SecretsProviderEnv, provider, providerEnv, providerAWSSecretsManager)
+	}
+
+	secretName := lookupEnv(SecretARNEnv, values)
+	if secretName == "" {
+		return nil, fmt.Errorf("%s is required when %s is %s", SecretARNEnv, SecretsProviderEnv, provider)
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	if secretsLoader == nil {
+		loader, err := newAWSSecretsLoader(secretName)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", SecretsProviderEnv, err)
+		}
+		secretsLoader = loader
+	}
+
+	secrets, err := secretsLoader.Load(secretName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", SecretARNEnv, err)
+	}
+	logger.Infof("loaded %d secrets from %s", len(secrets), secretName)
+	return secrets, nil
+}
+
+// awsSecretsLoader reads secrets from AWS Secrets Manager
+type awsSecretsLoader struct {
+	client *secretsmanager.Client
+}
+
+// newAWSSecretsLoader uses the default credential chain: AWS_* variables, the shared config files or the
+// instance, task or pod role. Without AWS_REGION the region is taken from the secret's ARN.
+func newAWSSecretsLoader(secretName string) (*awsSecretsLoader, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Region == "" {
+		if secretARN, err := arn.Parse(secretName); err == nil {
+			cfg.Region = secretARN.Region
+		}
+	}
+	return &awsSecretsLoader{client: secretsmanager.NewFromConfig(cfg)}, nil
+}
+
+// Load reads a secret stored as a JSON object. Values that are not strings take the form they would in a
+// CONFIG_FILE, e.g. lists become comma-separated.
+func (l *awsSecretsLoader) Load(secretName string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+
+	out, err := l.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretName)})
+	if err != nil {
+		return nil, err
+	}
+	if out.SecretString == nil {
+		return nil, fmt.Errorf("secret %s has no string value", secretName)
+	}
+
+	var settings map[string]any
+	decoder := json.NewDecoder(bytes.NewReader([]byte(*out.SecretString)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&settings); err != nil {
+		return nil, fmt.Errorf("secret %s must be a JSON object: %w", secretName, err)
+	}
+
+	secrets := make(map[string]string, len(settings))
+	var errs []error
+	for key, setting := range settings {
+		value, err := fileValue(setting)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		secrets[key] = value
+	}
+	return secrets, errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// useSecrets serves secrets from the mock store for the rest of the test
+func useSecrets(t *testing.T, secrets MockSecretsLoader) {
+	t.Helper()
+	SetSecretsLoader(secrets)
+	t.Cleanup(func() { SetSecretsLoader(nil) })
+	t.Setenv(SecretsProviderEnv, providerAWSSecretsManager)
+	t.Setenv(SecretARNEnv, "arn:aws:secretsmanager:eu-west-1:123456789012:secret:gateway")
+}
+
+// unsetEnv unsets key for the rest of the test, restoring it afterwards
+func unsetEnv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+func TestMockSecretsLoader(t *testing.T) {
+	loader := MockSecretsLoader{"gateway": {"JWT_SECRET": "s3cret"}}
+
+	secret, err := loader.Load("gateway")
+	if err != nil || secret["JWT_SECRET"] != "s3cret" {
+		t.Errorf("Load = %v, %v, want the gateway secret", secret, err)
+	}
+	if secret, err := loader.Load("user-service"); err == nil {
+		t.Errorf("Load of an unknown secret = %v, want an error", secret)
+	}
+}
+
+func TestLoadDotEnvMergesTheSecret(t *testing.T) {
+	useSecrets(t, MockSecretsLoader{"arn:aws:secretsmanager:eu-west-1:123456789012:secret:gateway": {
+		"SECRET_TEST_JWT":     "from-secret",
+		"SECRET_TEST_MODE":    "secret",
+		"SECRET_TEST_TIMEOUT": "60",
+	}})
+	t.Setenv("SECRET_TEST_TIMEOUT", "5")
+	file := writeFile(t, "settings.yaml", "SECRET_TEST_PORT: 8080\nSECRET_TEST_MODE: file\n")
+	dotEnv := writeFile(t, ".env", "SECRET_TEST_MODE=dotenv\nSECRET_TEST_JWT=from-dotenv\n")
+
+	if err := loadFiles(t, dotEnv, file); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"SECRET_TEST_PORT": "8080",
+		// The secret wins over the .env file and the config file
+		"SECRET_TEST_JWT":  "from-secret",
+		"SECRET_TEST_MODE": "secret",
+		// But not over the environment
+		"SECRET_TEST_TIMEOUT": "5",
+	} {
+		if got := os.Getenv(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadDotEnvReadsTheProviderFromTheConfigFile(t *testing.T) {
+	SetSecretsLoader(MockSecretsLoader{"gateway": {"SECRET_TEST_JWT": "from-secret"}})
+	t.Cleanup(func() { SetSecretsLoader(nil) })
+	unsetEnv(t, SecretsProviderEnv)
+	unsetEnv(t, SecretARNEnv)
+	file := writeFile(t, "settings.yaml", "CONFIG_SECRETS_PROVIDER: aws-secrets-manager\nCONFIG_SECRET_ARN: gateway\n")
+
+	if err := loadFiles(t, "", file); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("SECRET_TEST_JWT"); got != "from-secret" {
+		t.Errorf("SECRET_TEST_JWT = %q, want the secret's", got)
+	}
+}
+
+func TestSecretsProviderErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		arn      string
+		want     string
+	}{
+		{name: "unknown provider", provider: "vault", arn: "gateway", want: `unknown provider "vault"`},
+		{name: "no secret", provider: providerAWSSecretsManager, want: "CONFIG_SECRET_ARN is required"},
+		{name: "missing secret", provider: providerAWSSecretsManager, arn: "user-service", want: "secret user-service not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetSecretsLoader(MockSecretsLoader{"gateway": {"SECRET_TEST_JWT": "from-secret"}})
+			t.Cleanup(func() { SetSecretsLoader(nil) })
+			t.Setenv(SecretsProviderEnv, tt.provider)
+			t.Setenv(SecretARNEnv, tt.arn)
+
+			err := loadFiles(t, "", "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("LoadDotEnv = %v, want an error containing %q", err, tt.want)
+			}
+			if err := LoadSecrets(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("LoadSecrets = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvProviderLoadsNothing(t *testing.T) {
+	// Reaching the loader would fail: it has no secrets
+	SetSecretsLoader(MockSecretsLoader{})
+	t.Cleanup(func() { SetSecretsLoader(nil) })
+	t.Setenv(SecretARNEnv, "gateway")
+
+	for _, provider := range []string{"", providerEnv} {
+		t.Setenv(SecretsProviderEnv, provider)
+		if err := LoadSecrets(); err != nil {
+			t.Errorf("provider %q: LoadSecrets = %v, want nil", provider, err)
+		}
+	}
+}
+
+func TestLoadSecretsOnlySetsUnsetVariables(t *testing.T) {
+	useSecrets(t, MockSecretsLoader{"arn:aws:secretsmanager:eu-west-1:123456789012:secret:gateway": {
+		"SECRET_TEST_JWT":     "from-secret",
+		"SECRET_TEST_TIMEOUT": "60",
+	}})
+	unsetEnv(t, "SECRET_TEST_JWT")
+	t.Setenv("SECRET_TEST_TIMEOUT", "5")
+
+	if err := LoadSecrets(); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("SECRET_TEST_JWT"); got != "from-secret" {
+		t.Errorf("SECRET_TEST_JWT = %q, want the secret's", got)
+	}
+	if got := os.Getenv("SECRET_TEST_TIMEOUT"); got != "5" {
+		t.Errorf("SECRET_TEST_TIMEOUT = %q, want the environment's 5", got)
+	}
+}
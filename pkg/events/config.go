@@ -0,0 +1,39 @@
+package events
+
+import "fmt"
+
+const (
+	DriverMemory   = "memory"
+	DriverRabbitMQ = "rabbitmq"
+)
+
+type Config struct {
+	Driver      string
+	RabbitMQURL string
+	Exchange    string
+}
+
+// Bus is both a Publisher and a Subscriber
+type Bus interface {
+	Publisher
+	Subscriber
+}
+
+// NewBus creates the bus implementation selected by cfg.Driver
+func NewBus(cfg Config) (Bus, error) {
+	switch cfg.Driver {
+	case DriverMemory, "":
+		return NewMemoryBus(), nil
+	case DriverRabbitMQ:
+		if cfg.RabbitMQURL == "" {
+			return nil, fmt.Errorf("rabbitmq url is required for the %s driver", DriverRabbitMQ)
+		}
+		exchange := cfg.Exchange
+		if exchange == "" {
+			exchange = "ecommerce.events"
+		}
+		return NewRabbitMQBus(cfg.RabbitMQURL, exchange)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, cfg.Driver)
+	}
+}
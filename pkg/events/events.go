@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPublisherClosed = errors.New("publisher is closed")
+	ErrUnknownDriver   = errors.New("unknown events driver")
+)
+
+// Event is the envelope every message on the bus is wrapped in.
+// Payload holds the versioned, event-specific JSON body.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	Source     string          `json:"source"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// NewEvent builds an envelope for the given payload
+func NewEvent(eventType string, version int, source string, payload interface{}) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+
+	return Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		Version:    version,
+		Source:     source,
+		OccurredAt: time.Now().UTC(),
+		Payload:    data,
+	}, nil
+}
+
+// Decode unmarshals the event payload into v
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// Publisher sends events to the message bus
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// Handler processes a single event. Returning an error causes the event to be redelivered,
+// so handlers must be idempotent (use Event.ID to deduplicate).
+type Handler func(ctx context.Context, event Event) error
+
+// Subscriber delivers events to handlers with at-least-once semantics
+type Subscriber interface {
+	// Subscribe binds queue to the given event types and blocks, dispatching deliveries
+	// to handler until ctx is canceled.
+	Subscribe(ctx context.Context, queue string, eventTypes []string, handler Handler) error
+	Close() error
+}
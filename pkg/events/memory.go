@@ -0,0 +1,68 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBus is an in-process Publisher and Subscriber, intended for tests and local development.
+// Delivery is synchronous: Publish returns the first handler error, mirroring a nack.
+type MemoryBus struct {
+	mu        sync.RWMutex
+	handlers  map[string][]Handler
+	published []Event
+	closed    bool
+}
+
+var (
+	_ Publisher  = (*MemoryBus)(nil)
+	_ Subscriber = (*MemoryBus)(nil)
+)
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{handlers: make(map[string][]Handler)}
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, event Event) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrPublisherClosed
+	}
+	b.published = append(b.published, event)
+	handlers := append([]Handler(nil), b.handlers[event.Type]...)
+	b.mu.Unlock()
+
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe registers handler for eventTypes and blocks until ctx is canceled
+func (b *MemoryBus) Subscribe(ctx context.Context, _ string, eventTypes []string, handler Handler) error {
+	b.mu.Lock()
+	for _, eventType := range eventTypes {
+		b.handlers[eventType] = append(b.handlers[eventType], handler)
+	}
+	b.mu.Unlock()
+
+	<-ctx.Done()
+	return nil
+}
+
+// Published returns a copy of every event published so far
+func (b *MemoryBus) Published() []Event {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]Event(nil), b.published...)
+}
+
+func (b *MemoryBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryBusDeliversToSubscribers(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	delivered := make(chan Event, 1)
+	subscribed := make(chan struct{})
+	go func() {
+		_ = bus.Subscribe(ctx, "notifications", []string{"order.created"}, func(_ context.Context, event Event) error {
+			delivered <- event
+			return nil
+		})
+	}()
+	// Subscribe blocks, so wait for its handler to be registered
+	go func() {
+		for {
+			bus.mu.RLock()
+			n := len(bus.handlers["order.created"])
+			bus.mu.RUnlock()
+			if n > 0 {
+				close(subscribed)
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	<-subscribed
+
+	created, err := NewEvent("order.created", OrderEventsVersion, "order-service", map[string]int{"order_id": 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Publish(ctx, created); err != nil {
+		t.Fatal(err)
+	}
+	// No one subscribed to this type, which is kept all the same
+	if err := bus.Publish(ctx, Event{ID: "other", Type: "order.cancelled"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if event := <-delivered; event.ID != created.ID {
+		t.Errorf("delivered %s, want %s", event.ID, created.ID)
+	}
+	if published := bus.Published(); len(published) != 2 || published[0].ID != created.ID || published[1].ID != "other" {
+		t.Errorf("published %+v, want both events in order", published)
+	}
+}
+
+func TestMemoryBusReturnsTheHandlerError(t *testing.T) {
+	bus := NewMemoryBus()
+	nack := errors.New("nack")
+	bus.handlers["order.created"] = []Handler{func(context.Context, Event) error { return nack }}
+
+	if err := bus.Publish(context.Background(), Event{ID: "e1", Type: "order.created"}); !errors.Is(err, nack) {
+		t.Fatalf("err = %v, want the handler's %v", err, nack)
+	}
+}
+
+func TestMemoryBusRefusesEventsOnceClosed(t *testing.T) {
+	bus := NewMemoryBus()
+	if err := bus.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Publish(context.Background(), Event{ID: "e1", Type: "order.created"}); !errors.Is(err, ErrPublisherClosed) {
+		t.Fatalf("err = %v, want %v", err, ErrPublisherClosed)
+	}
+	if published := bus.Published(); len(published) != 0 {
+		t.Errorf("published %+v, want nothing", published)
+	}
+}
@@ -0,0 +1,47 @@
+package events
+
+import "time"
+
+// Order event types published by the Order service
+const (
+	OrderCreated       = "order.created"
+	OrderStatusChanged = "order.status_changed"
+	OrderCancelled     = "order.cancelled"
+)
+
+// OrderEventsVersion is the current schema version of the order event payloads.
+// Bump it on breaking changes so consumers can branch on Event.Version.
+const OrderEventsVersion = 1
+
+type OrderItemV1 struct {
+	ProductID  uint    `json:"product_id"`
+	Quantity   int     `json:"quantity"`
+	UnitPrice  float32 `json:"unit_price"`
+	TotalPrice float32 `json:"total_price"`
+}
+
+type OrderCreatedV1 struct {
+	OrderID      uint          `json:"order_id"`
+	UserID       uint          `json:"user_id"`
+	Status       string        `json:"status"`
+	ShippingCost float32       `json:"shipping_cost"`
+	Discount     float32       `json:"discount"`
+	Total        float32       `json:"total"`
+	Items        []OrderItemV1 `json:"items"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type OrderStatusChangedV1 struct {
+	OrderID   uint      `json:"order_id"`
+	UserID    uint      `json:"user_id"`
+	OldStatus string    `json:"old_status"`
+	NewStatus string    `json:"new_status"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type OrderCancelledV1 struct {
+	OrderID     uint      `json:"order_id"`
+	UserID      uint      `json:"user_id"`
+	Total       float32   `json:"total"`
+	CancelledAt time.Time `json:"cancelled_at"`
+}
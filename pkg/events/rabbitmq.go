@@ -0,0 +1,139 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/pkg/rabbitmq"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// RabbitMQBus publishes events to a durable topic exchange, using the event type as routing key
+type RabbitMQBus struct {
+	conn     *rabbitmq.RabbitMQ
+	exchange string
+	mu       sync.Mutex
+}
+
+var (
+	_ Publisher  = (*RabbitMQBus)(nil)
+	_ Subscriber = (*RabbitMQBus)(nil)
+)
+
+func NewRabbitMQBus(uri, exchange string) (*RabbitMQBus, error) {
+	conn, err := rabbitmq.NewRabbitMQ(uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to rabbitmq: %w", err)
+	}
+
+	ch := conn.GetChannel()
+	if err := ch.ExchangeDeclare(exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+	}
+
+	// Publisher confirms let Publish report failure so the outbox can retry
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	return &RabbitMQBus{conn: conn, exchange: exchange}, nil
+}
+
+func (b *RabbitMQBus) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	confirm, err := b.conn.GetChannel().PublishWithDeferredConfirmWithContext(ctx, b.exchange, event.Type, true, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    event.ID,
+		Type:         event.Type,
+		Timestamp:    event.OccurredAt,
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish %s: %w", event.Type, err)
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to confirm %s: %w", event.Type, err)
+	}
+	if !acked {
+		return fmt.Errorf("broker nacked %s", event.Type)
+	}
+	return nil
+}
+
+// Subscribe consumes from a durable queue with manual acks. A delivery is acked only after
+// handler succeeds; failures are requeued, giving at-least-once delivery.
+func (b *RabbitMQBus) Subscribe(ctx context.Context, queue string, eventTypes []string, handler Handler) error {
+	ch, err := b.conn.Connection().Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", queue, err)
+	}
+	for _, eventType := range eventTypes {
+		if err := ch.QueueBind(queue, eventType, b.exchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind %s to %s: %w", queue, eventType, err)
+		}
+	}
+	if err := ch.Qos(10, 0, false); err != nil {
+		return fmt.Errorf("failed to set qos: %w", err)
+	}
+
+	deliveries, err := ch.ConsumeWithContext(ctx, queue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", queue, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return fmt.Errorf("delivery channel for %s closed", queue)
+			}
+			b.dispatch(ctx, delivery, handler)
+		}
+	}
+}
+
+func (b *RabbitMQBus) dispatch(ctx context.Context, delivery amqp.Delivery, handler Handler) {
+	var event Event
+	if err := json.Unmarshal(delivery.Body, &event); err != nil {
+		// A malformed message will never succeed, drop it instead of redelivering forever
+		logger.Errorf("event=event_malformed message_id=%s error=%v", delivery.MessageId, err)
+		_ = delivery.Nack(false, false)
+		return
+	}
+
+	if err := handler(ctx, event); err != nil {
+		logger.Warnf("event=event_handler_failed type=%s id=%s error=%v", event.Type, event.ID, err)
+		_ = delivery.Nack(false, true)
+		return
+	}
+
+	if err := delivery.Ack(false); err != nil {
+		logger.Errorf("event=event_ack_failed type=%s id=%s error=%v", event.Type, event.ID, err)
+	}
+}
+
+func (b *RabbitMQBus) Close() error {
+	return b.conn.Close()
+}
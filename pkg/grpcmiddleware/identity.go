@@ -0,0 +1,134 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys carrying the end user a call is made for. They are only as trustworthy as the
+// caller, so servers must also require the internal token.
+const (
+	UserIDHeader         = "x-user-id"
+	UserRoleHeader       = "x-user-role"
+	UserPermissionHeader = "x-user-permission"
+)
+
+type identityKey struct{}
+
+// Identity is the authenticated end user behind a call, as verified by the gateway
+type Identity struct {
+	UserID      uint
+	Roles       []string
+	Permissions []string
+}
+
+func (i Identity) HasPermission(permission string) bool {
+	return slices.Contains(i.Permissions, permission)
+}
+
+// ContextWithIdentity attaches identity to ctx, so outgoing calls made with it forward the user
+func ContextWithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the user a call is made for. It is absent for calls that
+// services make on their own behalf, such as event consumers.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	identity, ok := IdentityFromContext(ctx)
+	return identity.UserID, ok
+}
+
+// AuthorizeUser allows a call acting on userID's data when it is made by userID itself, by a user holding
+// one of permissions, or by a service with no user behind it. Anything else is PermissionDenied.
+func AuthorizeUser(ctx context.Context, userID uint, permissions ...string) error {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok || identity.UserID == userID || slices.ContainsFunc(permissions, identity.HasPermission) {
+		return nil
+	}
+	return status.Error(codes.PermissionDenied, "caller may not access another user's data")
+}
+
+// IdentityUnaryClientInterceptor forwards the identity in the call's context as metadata
+func IdentityUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingIdentity(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// IdentityStreamClientInterceptor is IdentityUnaryClientInterceptor for streaming RPCs
+func IdentityStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingIdentity(ctx), desc, cc, method, opts...)
+	}
+}
+
+// IdentityUnaryServerInterceptor reads the forwarded identity into the handler's context.
+// Chain it after the internal auth interceptor.
+func IdentityUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := incomingIdentity(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// IdentityStreamServerInterceptor is IdentityUnaryServerInterceptor for streaming RPCs
+func IdentityStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := incomingIdentity(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, WrapServerStream(ss, ctx))
+	}
+}
+
+func outgoingIdentity(ctx context.Context) context.Context {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	pairs := []string{UserIDHeader, strconv.FormatUint(uint64(identity.UserID), 10)}
+	for _, role := range identity.Roles {
+		pairs = append(pairs, UserRoleHeader, role)
+	}
+	for _, permission := range identity.Permissions {
+		pairs = append(pairs, UserPermissionHeader, permission)
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+func incomingIdentity(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+	ids := md.Get(UserIDHeader)
+	if len(ids) == 0 {
+		return ctx, nil
+	}
+
+	userID, err := strconv.ParseUint(ids[0], 10, 32)
+	if len(ids) > 1 || err != nil || userID == 0 {
+		return nil, status.Error(codes.Unauthenticated, "invalid "+UserIDHeader)
+	}
+	return ContextWithIdentity(ctx, Identity{
+		UserID:      uint(userID),
+		Roles:       md.Get(UserRoleHeader),
+		Permissions: md.Get(UserPermissionHeader),
+	}), nil
+}
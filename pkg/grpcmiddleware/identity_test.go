@@ -0,0 +1,109 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// receiveIdentity runs a unary call through the server interceptor with md as the incoming metadata and returns
+// the identity the handler saw
+func receiveIdentity(md metadata.MD) (identity Identity, found bool, err error) {
+	ctx := context.Background()
+	if md != nil {
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+	_, err = IdentityUnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: reserveStock},
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			identity, found = IdentityFromContext(ctx)
+			return nil, nil
+		})
+	return identity, found, err
+}
+
+func TestIdentityReachesTheServer(t *testing.T) {
+	sent := Identity{UserID: 7, Roles: []string{"customer", "support"}, Permissions: []string{"order:read"}}
+
+	var md metadata.MD
+	ctx := ContextWithIdentity(context.Background(), sent)
+	err := IdentityUnaryClientInterceptor()(ctx, reserveStock, nil, nil, nil,
+		func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			md, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received, found, err := receiveIdentity(md)
+	if err != nil || !found || !reflect.DeepEqual(received, sent) {
+		t.Fatalf("received %+v, %v, %v from %v, want %+v", received, found, err, md, sent)
+	}
+}
+
+func TestIdentityServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		md        metadata.MD
+		wantFound bool
+		wantCode  codes.Code
+	}{
+		{name: "no metadata"},
+		// Services calling on their own behalf send no user
+		{name: "no user", md: metadata.Pairs(InternalAuthHeader, "internal-token")},
+		{name: "user", md: metadata.Pairs(UserIDHeader, "7"), wantFound: true},
+		{name: "not a number", md: metadata.Pairs(UserIDHeader, "seven"), wantCode: codes.Unauthenticated},
+		{name: "zero", md: metadata.Pairs(UserIDHeader, "0"), wantCode: codes.Unauthenticated},
+		{name: "two users", md: metadata.Pairs(UserIDHeader, "7", UserIDHeader, "8"), wantCode: codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, found, err := receiveIdentity(tt.md)
+			if status.Code(err) != tt.wantCode || found != tt.wantFound {
+				t.Errorf("found = %v, err = %v; want found = %v, code %v", found, err, tt.wantFound, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAuthorizeUser(t *testing.T) {
+	asUser := func(id uint, permissions ...string) context.Context {
+		return ContextWithIdentity(context.Background(), Identity{UserID: id, Permissions: permissions})
+	}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{name: "the user", ctx: asUser(7), want: codes.OK},
+		{name: "a service", ctx: context.Background(), want: codes.OK},
+		{name: "another user", ctx: asUser(8), want: codes.PermissionDenied},
+		{name: "another user with the permission", ctx: asUser(8, "user:read"), want: codes.OK},
+		{name: "another user with another permission", ctx: asUser(8, "order:read"), want: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := AuthorizeUser(tt.ctx, 7, "user:read"); status.Code(err) != tt.want {
+				t.Errorf("AuthorizeUser = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentityStreamServerInterceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(UserIDHeader, "7"))
+	var userID uint
+	err := IdentityStreamServerInterceptor()(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/order.OrderService/WatchOrderStatus"},
+		func(_ interface{}, stream grpc.ServerStream) error {
+			userID, _ = UserIDFromContext(stream.Context())
+			return nil
+		})
+	if err != nil || userID != 7 {
+		t.Errorf("the stream handler saw user %d, %v; want user 7", userID, err)
+	}
+}
@@ -2,6 +2,10 @@ package grpcmiddleware
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -9,33 +13,132 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-const InternalAuthHeader = "x-internal-token"
+const (
+	InternalAuthHeader = "x-internal-token"
+	// InternalCallerHeader names the calling service, its SERVICE_NAME. Every service holds the same
+	// token, so it keeps honest services to their methods rather than stopping a compromised one.
+	InternalCallerHeader = "x-internal-caller"
+)
 
-func InternalAuthUnaryServerInterceptor(expectedToken string) grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		if expectedToken == "" {
-			return handler(ctx, req)
-		}
+// InternalAuthPolicy limits which services may call which methods once the internal token checks out.
+// The zero value lets every caller holding the token call every method.
+type InternalAuthPolicy struct {
+	// Methods maps full method names, e.g. "/product.ProductService/AdjustProductStock", to the callers allowed
+	Methods map[string][]string
+	// DenyUnlisted refuses methods missing from Methods instead of allowing them
+	DenyUnlisted bool
+}
+
+// ParseInternalAuthPolicy reads INTERNAL_AUTH_POLICY_JSON, a JSON object of method names to caller arrays,
+// and INTERNAL_AUTH_DEFAULT, "allow" or "deny" for methods it does not list. Both may be empty.
+func ParseInternalAuthPolicy(policyJSON, defaultMode string) (InternalAuthPolicy, error) {
+	var policy InternalAuthPolicy
+	switch defaultMode {
+	case "", "allow":
+	case "deny":
+		policy.DenyUnlisted = true
+	default:
+		return InternalAuthPolicy{}, fmt.Errorf("INTERNAL_AUTH_DEFAULT must be allow or deny, got %q", defaultMode)
+	}
+	if policyJSON == "" {
+		return policy, nil
+	}
 
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Error(codes.Unauthenticated, "missing metadata")
+	if err := json.Unmarshal([]byte(policyJSON), &policy.Methods); err != nil {
+		return InternalAuthPolicy{}, fmt.Errorf("INTERNAL_AUTH_POLICY_JSON must be a JSON object of string arrays: %w", err)
+	}
+	for method := range policy.Methods {
+		if !strings.HasPrefix(method, "/") || strings.Count(method, "/") != 2 {
+			return InternalAuthPolicy{}, fmt.Errorf("INTERNAL_AUTH_POLICY_JSON: %q is not a full method name such as /package.Service/Method", method)
 		}
+	}
+	return policy, nil
+}
 
-		tokens := md.Get(InternalAuthHeader)
-		if len(tokens) == 0 || tokens[0] != expectedToken {
-			return nil, status.Error(codes.Unauthenticated, "invalid internal token")
+// authorize checks that the caller named in ctx may call method
+func (p InternalAuthPolicy) authorize(ctx context.Context, method string) error {
+	callers, listed := p.Methods[method]
+	if !listed {
+		if p.DenyUnlisted {
+			return status.Errorf(codes.PermissionDenied, "%s is not open to internal callers", method)
 		}
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	names := md.Get(InternalCallerHeader)
+	if len(names) == 0 {
+		return status.Error(codes.PermissionDenied, "missing "+InternalCallerHeader)
+	}
+	if len(names) > 1 || !slices.Contains(callers, names[0]) {
+		return status.Errorf(codes.PermissionDenied, "caller %q may not call %s", strings.Join(names, ","), method)
+	}
+	return nil
+}
 
+// InternalAuthUnaryServerInterceptor requires the internal token, then applies policy to the caller
+func InternalAuthUnaryServerInterceptor(expectedToken string, policy InternalAuthPolicy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkInternalToken(ctx, expectedToken); err != nil {
+			return nil, err
+		}
+		if err := policy.authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
 		return handler(ctx, req)
 	}
 }
 
-func InternalAuthUnaryClientInterceptor(token string) grpc.UnaryClientInterceptor {
-	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		if token != "" {
-			ctx = metadata.AppendToOutgoingContext(ctx, InternalAuthHeader, token)
+// InternalAuthStreamServerInterceptor is InternalAuthUnaryServerInterceptor for streaming RPCs
+func InternalAuthStreamServerInterceptor(expectedToken string, policy InternalAuthPolicy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkInternalToken(ss.Context(), expectedToken); err != nil {
+			return err
 		}
-		return invoker(ctx, method, req, reply, cc, opts...)
+		if err := policy.authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// InternalAuthUnaryClientInterceptor sends the internal token and caller, the calling service's name
+func InternalAuthUnaryClientInterceptor(token, caller string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingInternalAuth(ctx, token, caller), method, req, reply, cc, opts...)
+	}
+}
+
+// InternalAuthStreamClientInterceptor is InternalAuthUnaryClientInterceptor for streaming RPCs
+func InternalAuthStreamClientInterceptor(token, caller string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingInternalAuth(ctx, token, caller), desc, cc, method, opts...)
+	}
+}
+
+func outgoingInternalAuth(ctx context.Context, token, caller string) context.Context {
+	if token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, InternalAuthHeader, token)
+	}
+	if caller != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, InternalCallerHeader, caller)
+	}
+	return ctx
+}
+
+func checkInternalToken(ctx context.Context, expectedToken string) error {
+	if expectedToken == "" {
+		return nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	tokens := md.Get(InternalAuthHeader)
+	if len(tokens) == 0 || tokens[0] != expectedToken {
+		return status.Error(codes.Unauthenticated, "invalid internal token")
 	}
+	return nil
 }
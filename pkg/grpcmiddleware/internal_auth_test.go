@@ -0,0 +1,129 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const reserveStock = "/product.ProductService/ReserveStock"
+
+// callInternal runs a unary call to method through the server interceptor, with md as the incoming metadata,
+// and reports whether the handler ran
+func callInternal(policy InternalAuthPolicy, method string, md metadata.MD) (bool, error) {
+	ctx := context.Background()
+	if md != nil {
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+	called := false
+	_, err := InternalAuthUnaryServerInterceptor("internal-token", policy)(ctx, nil,
+		&grpc.UnaryServerInfo{FullMethod: method},
+		func(context.Context, interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		})
+	return called, err
+}
+
+func TestInternalAuthPolicy(t *testing.T) {
+	policy := InternalAuthPolicy{Methods: map[string][]string{reserveStock: {"order-service"}}}
+	deny := InternalAuthPolicy{Methods: policy.Methods, DenyUnlisted: true}
+	caller := func(name ...string) metadata.MD {
+		md := metadata.Pairs(InternalAuthHeader, "internal-token")
+		for _, n := range name {
+			md.Append(InternalCallerHeader, n)
+		}
+		return md
+	}
+
+	tests := []struct {
+		name   string
+		policy InternalAuthPolicy
+		method string
+		md     metadata.MD
+		want   codes.Code
+	}{
+		{name: "allowed caller", policy: policy, method: reserveStock, md: caller("order-service"), want: codes.OK},
+		{name: "denied caller", policy: policy, method: reserveStock, md: caller("api-gateway"), want: codes.PermissionDenied},
+		{name: "missing caller", policy: policy, method: reserveStock, md: caller(), want: codes.PermissionDenied},
+		{name: "allowed caller named twice", policy: policy, method: reserveStock, md: caller("api-gateway", "order-service"), want: codes.PermissionDenied},
+		{name: "missing metadata", policy: policy, method: reserveStock, want: codes.Unauthenticated},
+		{name: "wrong token", policy: policy, method: reserveStock, md: metadata.Pairs(InternalAuthHeader, "guess", InternalCallerHeader, "order-service"), want: codes.Unauthenticated},
+		{name: "unlisted method allowed by default", policy: policy, method: "/product.ProductService/GetProductByID", md: caller("api-gateway"), want: codes.OK},
+		{name: "unlisted method under deny", policy: deny, method: "/product.ProductService/GetProductByID", md: caller("api-gateway"), want: codes.PermissionDenied},
+		{name: "listed method under deny", policy: deny, method: reserveStock, md: caller("order-service"), want: codes.OK},
+		{name: "zero policy", method: reserveStock, md: caller(), want: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called, err := callInternal(tt.policy, tt.method, tt.md)
+			if status.Code(err) != tt.want {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if called != (tt.want == codes.OK) {
+				t.Errorf("handler called: %v, want %v", called, tt.want == codes.OK)
+			}
+		})
+	}
+}
+
+func TestInternalAuthStreamServerInterceptor(t *testing.T) {
+	policy := InternalAuthPolicy{Methods: map[string][]string{"/order.OrderService/WatchOrderStatus": {"api-gateway"}}}
+	interceptor := InternalAuthStreamServerInterceptor("internal-token", policy)
+	info := &grpc.StreamServerInfo{FullMethod: "/order.OrderService/WatchOrderStatus", IsServerStream: true}
+
+	for caller, want := range map[string]codes.Code{"api-gateway": codes.OK, "cart-service": codes.PermissionDenied} {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(InternalAuthHeader, "internal-token", InternalCallerHeader, caller))
+		err := interceptor(nil, &fakeServerStream{ctx: ctx}, info, func(interface{}, grpc.ServerStream) error { return nil })
+		if status.Code(err) != want {
+			t.Errorf("%s: err = %v, want %v", caller, err, want)
+		}
+	}
+}
+
+func TestInternalAuthClientInterceptorSendsTheCaller(t *testing.T) {
+	var sent metadata.MD
+	err := InternalAuthUnaryClientInterceptor("internal-token", "order-service")(context.Background(), reserveStock, nil, nil, nil,
+		func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			sent, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// What the client sends passes a server holding the same token and a policy naming the caller
+	called, err := callInternal(InternalAuthPolicy{Methods: map[string][]string{reserveStock: {"order-service"}}}, reserveStock, sent)
+	if err != nil || !called {
+		t.Fatalf("metadata %v was refused: %v", sent, err)
+	}
+}
+
+func TestParseInternalAuthPolicy(t *testing.T) {
+	policy, err := ParseInternalAuthPolicy(`{"/product.ProductService/ReserveStock": ["order-service"]}`, "deny")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !policy.DenyUnlisted || len(policy.Methods[reserveStock]) != 1 {
+		t.Errorf("policy = %+v, want ReserveStock for order-service, denying the rest", policy)
+	}
+
+	if policy, err := ParseInternalAuthPolicy("", ""); err != nil || policy.DenyUnlisted || policy.Methods != nil {
+		t.Errorf("empty settings: %+v, %v; want the zero policy", policy, err)
+	}
+
+	for _, tt := range []struct{ policyJSON, mode string }{
+		{mode: "block"},
+		{policyJSON: `["order-service"]`},
+		{policyJSON: `{"ReserveStock": ["order-service"]}`},
+		{policyJSON: `{"/product.ProductService": ["order-service"]}`},
+	} {
+		if _, err := ParseInternalAuthPolicy(tt.policyJSON, tt.mode); err == nil {
+			t.Errorf("ParseInternalAuthPolicy(%q, %q) succeeded", tt.policyJSON, tt.mode)
+		}
+	}
+}
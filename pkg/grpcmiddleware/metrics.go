@@ -0,0 +1,227 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// ClientRequestDuration is the latency of outgoing calls, labelled by gRPC service, method and status code
+var ClientRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "grpc_client_request_duration_seconds",
+	Help:    "Latency of outgoing gRPC calls, by service, method and status code.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"service", "method", "code"})
+
+var clientRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "grpc_client_requests_total",
+	Help: "Outgoing gRPC calls, by service, method and status code.",
+}, []string{"service", "method", "code"})
+
+var clientStreamMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "grpc_client_stream_messages_total",
+	Help: "Messages on outgoing gRPC streams, by service, method and direction (sent or received).",
+}, []string{"service", "method", "direction"})
+
+var serverHandlingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "grpc_server_handling_seconds",
+	Help:    "Time the server took to handle gRPC calls, by service, method, type (unary or stream) and status code.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"service", "method", "type", "code"})
+
+var serverHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "grpc_server_handled_total",
+	Help: "gRPC calls handled by the server, by service, method, type (unary or stream) and status code.",
+}, []string{"service", "method", "type", "code"})
+
+var serverStreamMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "grpc_server_stream_messages_total",
+	Help: "Messages on incoming gRPC streams, by service, method and direction (sent or received).",
+}, []string{"service", "method", "direction"})
+
+// LatencyUnaryClientInterceptor observes the duration of every call in hist and counts it in
+// grpc_client_requests_total. Chain it after the circuit breaker so that only calls that reach the service
+// are measured, not those the open breaker refuses.
+func LatencyUnaryClientInterceptor(hist *prometheus.HistogramVec) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		service, name := splitMethod(method)
+		code := status.Code(err).String()
+		hist.WithLabelValues(service, name, code).Observe(time.Since(start).Seconds())
+		clientRequests.WithLabelValues(service, name, code).Inc()
+		return err
+	}
+}
+
+// splitMethod splits a full method such as /product.ProductService/GetProductByID into its service and
+// method names
+func splitMethod(fullMethod string) (string, string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return "unknown", fullMethod
+	}
+	return service, method
+}
+
+// LatencyStreamClientInterceptor is LatencyUnaryClientInterceptor for streaming calls. A stream is observed
+// when it ends, so its duration covers the whole stream, and every message sent or received on it is counted
+// in grpc_client_stream_messages_total.
+func LatencyStreamClientInterceptor(hist *prometheus.HistogramVec) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		service, name := splitMethod(method)
+		observe := func(err error) {
+			code := status.Code(err).String()
+			hist.WithLabelValues(service, name, code).Observe(time.Since(start).Seconds())
+			clientRequests.WithLabelValues(service, name, code).Inc()
+		}
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			observe(err)
+			return nil, err
+		}
+		return &measuredClientStream{
+			ClientStream: cs,
+			sent:         clientStreamMessages.WithLabelValues(service, name, "sent"),
+			received:     clientStreamMessages.WithLabelValues(service, name, "received"),
+			observe:      observe,
+		}, nil
+	}
+}
+
+// measuredClientStream counts the messages of a client stream and observes it once it ends, which is when
+// RecvMsg first fails: io.EOF for a stream that finished cleanly, its status otherwise
+type measuredClientStream struct {
+	grpc.ClientStream
+	sent     prometheus.Counter
+	received prometheus.Counter
+	observe  func(error)
+	once     sync.Once
+}
+
+func (s *measuredClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.sent.Inc()
+	}
+	return err
+}
+
+func (s *measuredClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.received.Inc()
+		return nil
+	}
+	s.once.Do(func() {
+		if errors.Is(err, io.EOF) {
+			s.observe(nil)
+			return
+		}
+		s.observe(err)
+	})
+	return err
+}
+
+// MetricsUnaryServerInterceptor observes every call in grpc_server_handling_seconds and counts it in
+// grpc_server_handled_total. Chain it before recovery, so a recovered panic is counted as Internal, and
+// before auth, so rejected calls are counted too.
+func MetricsUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeServerCall(info.FullMethod, "unary", start, err)
+		return resp, err
+	}
+}
+
+// MetricsStreamServerInterceptor is MetricsUnaryServerInterceptor for streaming RPCs. The call is observed
+// when the stream ends, and every message sent or received on it is counted in
+// grpc_server_stream_messages_total.
+func MetricsStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		service, name := splitMethod(info.FullMethod)
+		err := handler(srv, &measuredServerStream{
+			ServerStream: ss,
+			sent:         serverStreamMessages.WithLabelValues(service, name, "sent"),
+			received:     serverStreamMessages.WithLabelValues(service, name, "received"),
+		})
+		observeServerCall(info.FullMethod, "stream", start, err)
+		return err
+	}
+}
+
+// measuredServerStream counts the messages of a server stream. Context is passed through, so interceptors
+// further down the chain can still wrap it with WrapServerStream.
+type measuredServerStream struct {
+	grpc.ServerStream
+	sent     prometheus.Counter
+	received prometheus.Counter
+}
+
+func (s *measuredServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Inc()
+	}
+	return err
+}
+
+func (s *measuredServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Inc()
+	}
+	return err
+}
+
+func observeServerCall(fullMethod, callType string, start time.Time, err error) {
+	service, name := splitMethod(fullMethod)
+	code := status.Code(err).String()
+	serverHandlingSeconds.WithLabelValues(service, name, callType, code).Observe(time.Since(start).Seconds())
+	serverHandled.WithLabelValues(service, name, callType, code).Inc()
+}
+
+// ServeMetrics serves the collected metrics at GET /metrics on port until done is closed. Services serve
+// gRPC only, so this is their one HTTP listener, meant for Prometheus inside the cluster.
+func ServeMetrics(done <-chan any, port string) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		logger.Infof("event=server_start component=metrics_server addr=:%s", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("event=server_error component=metrics_server error=%v", err)
+		}
+	}()
+
+	go func() {
+		<-done
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Errorf("event=shutdown_error component=metrics_server error=%v", err)
+		}
+	}()
+}
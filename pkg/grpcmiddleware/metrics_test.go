@@ -0,0 +1,244 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeServerStream answers RecvMsg with recv, one entry per call, and accepts every SendMsg
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv []error
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func (s *fakeServerStream) SendMsg(interface{}) error { return nil }
+
+func (s *fakeServerStream) RecvMsg(interface{}) error {
+	err := s.recv[0]
+	s.recv = s.recv[1:]
+	return err
+}
+
+// fakeClientStream answers RecvMsg with recv, one entry per call, and accepts every SendMsg
+type fakeClientStream struct {
+	grpc.ClientStream
+	recv []error
+}
+
+func (s *fakeClientStream) SendMsg(interface{}) error { return nil }
+
+func (s *fakeClientStream) RecvMsg(interface{}) error {
+	err := s.recv[0]
+	s.recv = s.recv[1:]
+	return err
+}
+
+func TestMetricsUnaryServerInterceptor(t *testing.T) {
+	handled := serverHandled.WithLabelValues("test.MetricsService", "Get", "unary", "NotFound")
+	before := testutil.ToFloat64(handled)
+
+	_, err := MetricsUnaryServerInterceptor()(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: "/test.MetricsService/Get"},
+		func(context.Context, interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "missing")
+		})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("err = %v, want the handler's NotFound", err)
+	}
+	if got := testutil.ToFloat64(handled) - before; got != 1 {
+		t.Errorf("grpc_server_handled_total grew by %v, want 1", got)
+	}
+}
+
+func TestMetricsStreamServerInterceptor(t *testing.T) {
+	handled := serverHandled.WithLabelValues("test.MetricsService", "Watch", "stream", "OK")
+	sent := serverStreamMessages.WithLabelValues("test.MetricsService", "Watch", "sent")
+	received := serverStreamMessages.WithLabelValues("test.MetricsService", "Watch", "received")
+	before := []float64{testutil.ToFloat64(handled), testutil.ToFloat64(sent), testutil.ToFloat64(received)}
+
+	ss := &fakeServerStream{ctx: context.Background(), recv: []error{nil, io.EOF}}
+	err := MetricsStreamServerInterceptor()(nil, ss, &grpc.StreamServerInfo{FullMethod: "/test.MetricsService/Watch"},
+		func(_ interface{}, stream grpc.ServerStream) error {
+			// A later interceptor wrapping the stream must not hide the counting
+			stream = WrapServerStream(stream, stream.Context())
+			for stream.RecvMsg(nil) == nil {
+			}
+			for range 3 {
+				if err := stream.SendMsg(nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tt := range []struct {
+		name    string
+		counter prometheus.Counter
+		want    float64
+	}{
+		{name: "grpc_server_handled_total", counter: handled, want: 1},
+		{name: "messages sent", counter: sent, want: 3},
+		{name: "messages received", counter: received, want: 1},
+	} {
+		if got := testutil.ToFloat64(tt.counter) - before[i]; got != tt.want {
+			t.Errorf("%s grew by %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLatencyStreamClientInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		recv     []error
+		wantCode string
+	}{
+		{name: "stream ends cleanly", recv: []error{nil, nil, io.EOF}, wantCode: "OK"},
+		{name: "stream fails", recv: []error{nil, status.Error(codes.Unavailable, "gone")}, wantCode: "Unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hist := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_stream_seconds"}, []string{"service", "method", "code"})
+			requests := clientRequests.WithLabelValues("test.MetricsService", "Follow", tt.wantCode)
+			before := testutil.ToFloat64(requests)
+
+			cs, err := LatencyStreamClientInterceptor(hist)(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil,
+				"/test.MetricsService/Follow",
+				func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+					return &fakeClientStream{recv: tt.recv}, nil
+				})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for cs.RecvMsg(nil) == nil {
+			}
+			// Reading past the end must not observe the stream twice
+			cs.(*measuredClientStream).ClientStream = &fakeClientStream{recv: []error{io.EOF}}
+			_ = cs.RecvMsg(nil)
+
+			if got := testutil.CollectAndCount(hist); got != 1 {
+				t.Errorf("histogram has %d series, want 1", got)
+			}
+			if got := testutil.ToFloat64(requests) - before; got != 1 {
+				t.Errorf("grpc_client_requests_total{code=%q} grew by %v, want 1", tt.wantCode, got)
+			}
+		})
+	}
+}
+
+func TestLatencyStreamClientInterceptorCountsFailedStarts(t *testing.T) {
+	hist := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_stream_seconds"}, []string{"service", "method", "code"})
+	requests := clientRequests.WithLabelValues("test.MetricsService", "Follow", "PermissionDenied")
+	before := testutil.ToFloat64(requests)
+
+	_, err := LatencyStreamClientInterceptor(hist)(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil,
+		"/test.MetricsService/Follow",
+		func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return nil, status.Error(codes.PermissionDenied, "no")
+		})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("err = %v, want PermissionDenied", err)
+	}
+	if got := testutil.ToFloat64(requests) - before; got != 1 {
+		t.Errorf("grpc_client_requests_total grew by %v, want 1", got)
+	}
+}
+
+// sampleCount returns the number of observations in the series of hist with labels
+func sampleCount(t *testing.T, hist *prometheus.HistogramVec, labels ...string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := hist.WithLabelValues(labels...).(prometheus.Metric).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestLatencyUnaryClientInterceptor(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("product", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	hist := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_unary_seconds"}, []string{"service", "method", "code"})
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(LatencyUnaryClientInterceptor(hist)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := healthpb.NewHealthClient(conn)
+
+	tests := []struct {
+		service  string
+		wantCode codes.Code
+	}{
+		{service: "product", wantCode: codes.OK},
+		{service: "product", wantCode: codes.OK},
+		{service: "unknown", wantCode: codes.NotFound},
+	}
+	requests := map[string]prometheus.Counter{
+		"OK":       clientRequests.WithLabelValues("grpc.health.v1.Health", "Check", "OK"),
+		"NotFound": clientRequests.WithLabelValues("grpc.health.v1.Health", "Check", "NotFound"),
+	}
+	before := map[string]float64{"OK": testutil.ToFloat64(requests["OK"]), "NotFound": testutil.ToFloat64(requests["NotFound"])}
+	for _, tt := range tests {
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: tt.service}); status.Code(err) != tt.wantCode {
+			t.Fatalf("Check(%q) = %v, want %v", tt.service, err, tt.wantCode)
+		}
+	}
+
+	if got := testutil.CollectAndCount(hist); got != 2 {
+		t.Errorf("histogram has %d series, want one per code", got)
+	}
+	for code, want := range map[string]uint64{"OK": 2, "NotFound": 1} {
+		if got := sampleCount(t, hist, "grpc.health.v1.Health", "Check", code); got != want {
+			t.Errorf("histogram observed %d calls with code %s, want %d", got, code, want)
+		}
+	}
+	for code, wantGrowth := range map[string]float64{"OK": 2, "NotFound": 1} {
+		if got := testutil.ToFloat64(requests[code]) - before[code]; got != wantGrowth {
+			t.Errorf("grpc_client_requests_total{code=%q} grew by %v, want %v", code, got, wantGrowth)
+		}
+	}
+}
+
+func TestSplitMethod(t *testing.T) {
+	tests := []struct {
+		fullMethod  string
+		wantService string
+		wantMethod  string
+	}{
+		{fullMethod: "/product.ProductService/GetProductByID", wantService: "product.ProductService", wantMethod: "GetProductByID"},
+		{fullMethod: "GetProductByID", wantService: "unknown", wantMethod: "GetProductByID"},
+	}
+	for _, tt := range tests {
+		if service, method := splitMethod(tt.fullMethod); service != tt.wantService || method != tt.wantMethod {
+			t.Errorf("splitMethod(%q) = %q, %q, want %q, %q", tt.fullMethod, service, method, tt.wantService, tt.wantMethod)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader carries the ID the gateway gave the HTTP request, so every service logs the same one
+const RequestIDHeader = "x-request-id"
+
+// maxRequestIDLength bounds IDs taken from metadata, which end up in every log line of the call
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// ContextWithRequestID attaches id to ctx, so outgoing calls made with it forward the ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// RequestIDUnaryClientInterceptor forwards the request ID in the call's context as metadata
+func RequestIDUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingRequestID(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// RequestIDStreamClientInterceptor is RequestIDUnaryClientInterceptor for streaming RPCs
+func RequestIDStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingRequestID(ctx), desc, cc, method, opts...)
+	}
+}
+
+// RequestIDUnaryServerInterceptor reads the forwarded request ID, or generates one, into the handler's
+// context together with a logger carrying it. Chain it first so every later interceptor logs with it.
+func RequestIDUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(incomingRequestID(ctx), req)
+	}
+}
+
+// RequestIDStreamServerInterceptor is RequestIDUnaryServerInterceptor for streaming RPCs
+func RequestIDStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, WrapServerStream(ss, incomingRequestID(ss.Context())))
+	}
+}
+
+func outgoingRequestID(ctx context.Context) context.Context {
+	id, ok := RequestIDFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, RequestIDHeader, id)
+}
+
+func incomingRequestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDHeader); len(ids) > 0 && len(ids[0]) <= maxRequestIDLength {
+			id = ids[0]
+		}
+	}
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	ctx = ContextWithRequestID(ctx, id)
+	return logger.IntoContext(ctx, logger.FromContext(ctx).With(slog.String("request_id", id)))
+}
@@ -0,0 +1,70 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// MaxConnectionAge is how long a client connection lasts before the server asks the client to reconnect.
+// Reconnecting makes the client re-resolve DNS, which is how it finds pods added since it first connected.
+// Calls in flight, streams included, run to completion on the old connection.
+const MaxConnectionAge = 5 * time.Minute
+
+// KeepaliveMinTime is the shortest interval at which servers accept keepalive pings from clients, even on
+// connections without active calls. A client pinging more often is disconnected with too_many_pings.
+const KeepaliveMinTime = 10 * time.Second
+
+// DefaultServerOptions returns the interceptors every service installs, as matching unary and stream chains:
+// request ID, logging, metrics, panic recovery, internal auth and identity, in that order. Logging and metrics
+// come before recovery so a recovered panic is recorded as Internal, and before auth so rejected calls are
+// recorded too.
+// log is the base logger the request-scoped loggers are derived from. Clients may send keepalive pings
+// every KeepaliveMinTime and are asked to reconnect every MaxConnectionAge.
+func DefaultServerOptions(log *slog.Logger, internalAuthToken string, policy InternalAuthPolicy) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             KeepaliveMinTime,
+			PermitWithoutStream: true,
+		}),
+		// MaxConnectionAgeGrace is left unset, so a closing connection is never cut under a running call
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionAge: MaxConnectionAge,
+		}),
+		grpc.ChainUnaryInterceptor(
+			baseLoggerUnaryServerInterceptor(log),
+			RequestIDUnaryServerInterceptor(),
+			LoggingUnaryServerInterceptor(),
+			MetricsUnaryServerInterceptor(),
+			RecoveryUnaryServerInterceptor(),
+			InternalAuthUnaryServerInterceptor(internalAuthToken, policy),
+			IdentityUnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			baseLoggerStreamServerInterceptor(log),
+			RequestIDStreamServerInterceptor(),
+			LoggingStreamServerInterceptor(),
+			MetricsStreamServerInterceptor(),
+			RecoveryStreamServerInterceptor(),
+			InternalAuthStreamServerInterceptor(internalAuthToken, policy),
+			IdentityStreamServerInterceptor(),
+		),
+	}
+}
+
+// baseLoggerUnaryServerInterceptor stores log in the context for the interceptors after it to build on
+func baseLoggerUnaryServerInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(logger.IntoContext(ctx, log), req)
+	}
+}
+
+func baseLoggerStreamServerInterceptor(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, WrapServerStream(ss, logger.IntoContext(ss.Context(), log)))
+	}
+}
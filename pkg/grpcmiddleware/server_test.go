@@ -0,0 +1,148 @@
+package grpcmiddleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// panickingHealthServer panics when asked about the "panic" service and reports every other one as serving
+type panickingHealthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (panickingHealthServer) Check(_ context.Context, in *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if in.GetService() == "panic" {
+		var m map[string]int
+		m["boom"]++
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (panickingHealthServer) Watch(*healthpb.HealthCheckRequest, healthpb.Health_WatchServer) error {
+	panic("watch is broken")
+}
+
+// logBuffer collects the JSON log entries of the server, which writes them from its own goroutines
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// entries returns the logged entries with the given message
+func (b *logBuffer) entries(t *testing.T, msg string) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var found []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		if entry["msg"] == msg {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// defaultServer serves panickingHealthServer with DefaultServerOptions and returns a client sending the
+// internal token, and the server's log
+func defaultServer(t *testing.T) (healthpb.HealthClient, *logBuffer) {
+	t.Helper()
+	logs := &logBuffer{}
+	log := slog.New(slog.NewJSONHandler(logs, nil))
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(DefaultServerOptions(log, "internal-token", InternalAuthPolicy{})...)
+	healthpb.RegisterHealthServer(server, panickingHealthServer{})
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(RequestIDUnaryClientInterceptor(), InternalAuthUnaryClientInterceptor("internal-token", "order-service")),
+		grpc.WithChainStreamInterceptor(RequestIDStreamClientInterceptor(), InternalAuthStreamClientInterceptor("internal-token", "order-service")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn), logs
+}
+
+func TestDefaultServerOptionsRecoverFromAPanic(t *testing.T) {
+	client, logs := defaultServer(t)
+	ctx := ContextWithRequestID(context.Background(), "req-panic")
+
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "panic"})
+	if status.Code(err) != codes.Internal || status.Convert(err).Message() != "internal error" {
+		t.Fatalf("err = %v, want Internal without the panic's details", err)
+	}
+
+	// The server is still up and answers the next call
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("after the panic: Check = %v, %v, want SERVING", resp, err)
+	}
+
+	panics := logs.entries(t, "grpc_panic")
+	if len(panics) != 1 {
+		t.Fatalf("logged %d grpc_panic entries, want 1", len(panics))
+	}
+	entry := panics[0]
+	if entry["method"] != "/grpc.health.v1.Health/Check" || entry["request_id"] != "req-panic" || entry["level"] != "ERROR" {
+		t.Errorf("grpc_panic entry = %v, want the method and request ID at ERROR", entry)
+	}
+	if !strings.Contains(entry["panic"].(string), "assignment to entry in nil map") || !strings.Contains(entry["stack"].(string), "panickingHealthServer.Check") {
+		t.Errorf("grpc_panic entry = %v, want the panic value and a stack through the handler", entry)
+	}
+
+	// Logging runs outside recovery, so it records the panicking call as Internal
+	requests := logs.entries(t, "grpc_request")
+	if len(requests) != 2 || requests[0]["code"] != "Internal" || requests[0]["request_id"] != "req-panic" || requests[1]["code"] != "OK" {
+		t.Errorf("grpc_request entries = %v, want Internal for req-panic and then OK", requests)
+	}
+	if requests[0]["peer"] == nil {
+		t.Errorf("grpc_request entry = %v, want the peer address", requests[0])
+	}
+}
+
+func TestDefaultServerOptionsRecoverFromAPanicInAStream(t *testing.T) {
+	client, logs := defaultServer(t)
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Internal {
+		t.Fatalf("Recv = %v, want Internal", err)
+	}
+	if panics := logs.entries(t, "grpc_panic"); len(panics) != 1 || panics[0]["panic"] != "watch is broken" {
+		t.Errorf("grpc_panic entries = %v, want the one of Watch", panics)
+	}
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("after the panic: Check = %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package grpcmiddleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// WrappedServerStream is a server stream whose context has been replaced, so values added by stream
+// interceptors, such as the caller's identity or request ID, reach the handler through Context()
+type WrappedServerStream struct {
+	grpc.ServerStream
+	WrappedContext context.Context
+}
+
+// WrapServerStream returns ss with ctx as its context. Wrapping an already wrapped stream replaces the
+// context rather than nesting another layer.
+func WrapServerStream(ss grpc.ServerStream, ctx context.Context) *WrappedServerStream {
+	if wrapped, ok := ss.(*WrappedServerStream); ok {
+		return &WrappedServerStream{ServerStream: wrapped.ServerStream, WrappedContext: ctx}
+	}
+	return &WrappedServerStream{ServerStream: ss, WrappedContext: ctx}
+}
+
+func (s *WrappedServerStream) Context() context.Context {
+	return s.WrappedContext
+}
@@ -0,0 +1,220 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Algorithms JWTManager can sign and verify with. HS256 shares one secret between issuer and verifiers;
+// RS256 and EdDSA let verifiers hold only the public key.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// PublicKey verifies RS256 or EdDSA tokens whose kid header is ID
+type PublicKey struct {
+	ID  string
+	Key crypto.PublicKey
+}
+
+// KeySet supplies the public keys that verify asymmetric tokens
+type KeySet interface {
+	// PublicKeys returns the current keys. kid is the token's kid, which a remote set may refresh to find.
+	PublicKeys(kid string) []PublicKey
+}
+
+// StaticKeySet is a KeySet that never changes, such as keys loaded from PEM files
+type StaticKeySet []PublicKey
+
+func (s StaticKeySet) PublicKeys(string) []PublicKey {
+	return s
+}
+
+// signingMethodFor maps an algorithm name to its signing method
+func signingMethodFor(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case AlgorithmHS256:
+		return jwt.SigningMethodHS256, nil
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256, nil
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("algorithm must be %s, %s or %s, got %q", AlgorithmHS256, AlgorithmRS256, AlgorithmEdDSA, algorithm)
+}
+
+// algorithmFor names the algorithm a public key verifies
+func algorithmFor(key crypto.PublicKey) (string, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return AlgorithmRS256, nil
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA, nil
+	}
+	return "", fmt.Errorf("unsupported key type %T, need RSA or Ed25519", key)
+}
+
+// LoadPrivateKey reads an RSA or Ed25519 private key from a PEM file, in PKCS#8 or, for RSA, PKCS#1 form
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s holds no PEM block", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported key type %T", path, key)
+	}
+	if _, err := algorithmFor(signer.Public()); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return signer, nil
+}
+
+// LoadPublicKeys reads every PUBLIC KEY or RSA PUBLIC KEY block from the PEM files. Each key's kid is its RFC 7638 thumbprint,
+// which matches the kid Generate writes with the private half.
+func LoadPublicKeys(paths ...string) ([]PublicKey, error) {
+	var keys []PublicKey
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read public key: %w", err)
+		}
+		for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+			var key any
+			switch block.Type {
+			case "PUBLIC KEY":
+				key, err = x509.ParsePKIXPublicKey(block.Bytes)
+			case "RSA PUBLIC KEY":
+				key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+			default:
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			id, err := Thumbprint(key)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			keys = append(keys, PublicKey{ID: id, Key: key})
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public key block found")
+	}
+	return keys, nil
+}
+
+// LoadSigningKey loads the private key for algorithm from path. It returns nil for HS256, which signs with
+// the shared secrets from ResolveKeys instead.
+func LoadSigningKey(algorithm, path string) (crypto.Signer, error) {
+	if _, err := signingMethodFor(algorithm); err != nil || algorithm == AlgorithmHS256 {
+		return nil, err
+	}
+	if path == "" {
+		return nil, fmt.Errorf("%s needs a private key file", algorithm)
+	}
+
+	signer, err := LoadPrivateKey(path)
+	if err != nil {
+		return nil, err
+	}
+	if keyAlgorithm, _ := algorithmFor(signer.Public()); keyAlgorithm != algorithm {
+		return nil, fmt.Errorf("%s holds a %s key, not %s", path, keyAlgorithm, algorithm)
+	}
+	return signer, nil
+}
+
+// ResolveKeySet returns the public keys that verify algorithm from a comma-separated list of PEM files or,
+// when there are none, a JWKS URL. It returns nil for HS256, which verifies with the shared secrets instead.
+func ResolveKeySet(algorithm, publicKeyFiles, jwksURL string) (KeySet, error) {
+	if _, err := signingMethodFor(algorithm); err != nil || algorithm == AlgorithmHS256 {
+		return nil, err
+	}
+
+	switch {
+	case publicKeyFiles != "":
+		paths := strings.Split(publicKeyFiles, ",")
+		for i := range paths {
+			paths[i] = strings.TrimSpace(paths[i])
+		}
+		keys, err := LoadPublicKeys(paths...)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if keyAlgorithm, _ := algorithmFor(key.Key); keyAlgorithm != algorithm {
+				return nil, fmt.Errorf("public key %s does not verify %s", key.ID, algorithm)
+			}
+		}
+		return StaticKeySet(keys), nil
+	case jwksURL != "":
+		return NewJWKSKeySet(jwksURL), nil
+	}
+	return nil, fmt.Errorf("%s needs public key files or a JWKS URL", algorithm)
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of an RSA or Ed25519 public key, used as its kid
+func Thumbprint(key crypto.PublicKey) (string, error) {
+	// The members are the required ones for the key type, in lexicographic order, as the RFC requires
+	var members any
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{E: encodeBigInt(big.NewInt(int64(key.E))), Kty: "RSA", N: encodeBigInt(key.N)}
+	case ed25519.PublicKey:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{Crv: "Ed25519", Kty: "OKP", X: base64.RawURLEncoding.EncodeToString(key)}
+	default:
+		return "", fmt.Errorf("unsupported key type %T, need RSA or Ed25519", key)
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
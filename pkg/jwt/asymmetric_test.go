@@ -0,0 +1,265 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// writePEM writes one PEM block to a file in a temporary directory and returns its path
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writePublicKey(t *testing.T, key crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writePEM(t, "jwt.pub.pem", "PUBLIC KEY", der)
+}
+
+// rs256Pair returns an issuer signing with key and a verifier holding only its public half
+func rs256Pair(t *testing.T, key *rsa.PrivateKey) (issuer, verifier *JWTManager) {
+	t.Helper()
+	issuer = NewJWTManager("", time.Hour)
+	if err := issuer.SetSigningKey(key); err != nil {
+		t.Fatal(err)
+	}
+
+	keySet, err := ResolveKeySet(AlgorithmRS256, writePublicKey(t, &key.PublicKey), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier = NewJWTManager("", time.Hour)
+	if err := verifier.SetKeySet(AlgorithmRS256, keySet); err != nil {
+		t.Fatal(err)
+	}
+	return issuer, verifier
+}
+
+func TestRS256VerifiesWithPublicKey(t *testing.T) {
+	issuer, verifier := rs256Pair(t, newRSAKey(t))
+
+	token, err := issuer.Generate(7, "user@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.UserID != 7 || claims.Role != "customer" {
+		t.Errorf("claims = %+v, want user 7 with role customer", claims)
+	}
+}
+
+func TestRS256RejectsOtherAlgorithms(t *testing.T) {
+	key := newRSAKey(t)
+	_, verifier := rs256Pair(t, key)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		UserID:           7,
+	}
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		key    any
+	}{
+		{name: "HS256 with a shared secret", method: jwt.SigningMethodHS256, key: []byte("your-secret-key-change-in-production")},
+		// The alg confusion attack: the published public key used as an HMAC secret
+		{name: "HS256 with the public key as secret", method: jwt.SigningMethodHS256, key: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})},
+		{name: "none", method: jwt.SigningMethodNone, key: jwt.UnsafeAllowNoneSignatureType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwt.NewWithClaims(tt.method, claims).SignedString(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := verifier.Verify(token); err == nil || !strings.Contains(err.Error(), "unexpected signing method") {
+				t.Fatalf("Verify = %v, want an unexpected signing method error", err)
+			}
+		})
+	}
+}
+
+func TestHS256RejectsRS256Tokens(t *testing.T) {
+	issuer, _ := rs256Pair(t, newRSAKey(t))
+	token, err := issuer.Generate(7, "user@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewJWTManager("secret", time.Hour).Verify(token); err == nil {
+		t.Fatal("an HS256 manager accepted an RS256 token")
+	}
+}
+
+func TestRS256RejectsTokensOfAnotherKey(t *testing.T) {
+	issuer, _ := rs256Pair(t, newRSAKey(t))
+	_, verifier := rs256Pair(t, newRSAKey(t))
+
+	token, err := issuer.Generate(7, "user@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(token); err == nil {
+		t.Fatal("a token signed with another key was accepted")
+	}
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPKCS8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantAlg string
+	}{
+		{name: "RSA PKCS#1", path: writePEM(t, "rsa1.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), wantAlg: AlgorithmRS256},
+		{name: "RSA PKCS#8", path: writePEM(t, "rsa8.pem", "PRIVATE KEY", pkcs8), wantAlg: AlgorithmRS256},
+		{name: "Ed25519 PKCS#8", path: writePEM(t, "ed.pem", "PRIVATE KEY", edPKCS8), wantAlg: AlgorithmEdDSA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := LoadPrivateKey(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if alg, _ := algorithmFor(signer.Public()); alg != tt.wantAlg {
+				t.Errorf("algorithm = %s, want %s", alg, tt.wantAlg)
+			}
+		})
+	}
+}
+
+func TestLoadPrivateKeyErrors(t *testing.T) {
+	garbage := filepath.Join(t.TempDir(), "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "missing file", path: filepath.Join(t.TempDir(), "missing.pem")},
+		{name: "no PEM block", path: garbage},
+		{name: "certificate block", path: writePEM(t, "cert.pem", "CERTIFICATE", []byte{1, 2, 3})},
+		{name: "corrupt key", path: writePEM(t, "bad.pem", "PRIVATE KEY", []byte{1, 2, 3})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadPrivateKey(tt.path); err == nil {
+				t.Fatal("LoadPrivateKey succeeded")
+			}
+		})
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	rsaPath := writePEM(t, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(newRSAKey(t)))
+
+	if signer, err := LoadSigningKey(AlgorithmHS256, rsaPath); signer != nil || err != nil {
+		t.Errorf("HS256: got %v, %v; want no key and no error", signer, err)
+	}
+	if signer, err := LoadSigningKey(AlgorithmRS256, rsaPath); signer == nil || err != nil {
+		t.Errorf("RS256: got %v, %v; want the key", signer, err)
+	}
+	if _, err := LoadSigningKey(AlgorithmRS256, ""); err == nil {
+		t.Error("RS256 without a key file succeeded")
+	}
+	if _, err := LoadSigningKey(AlgorithmEdDSA, rsaPath); err == nil {
+		t.Error("EdDSA with an RSA key succeeded")
+	}
+	if _, err := LoadSigningKey("HS512", rsaPath); err == nil {
+		t.Error("an unknown algorithm succeeded")
+	}
+}
+
+func TestResolveKeySet(t *testing.T) {
+	key := newRSAKey(t)
+	rsaPath := writePublicKey(t, &key.PublicKey)
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPath := writePublicKey(t, edPublic)
+
+	keySet, err := ResolveKeySet(AlgorithmRS256, " "+rsaPath+" ", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := keySet.PublicKeys("")
+	wantID, _ := Thumbprint(&key.PublicKey)
+	if len(keys) != 1 || keys[0].ID != wantID {
+		t.Errorf("keys = %+v, want one key with kid %s", keys, wantID)
+	}
+
+	if keySet, err := ResolveKeySet(AlgorithmHS256, rsaPath, ""); keySet != nil || err != nil {
+		t.Errorf("HS256: got %v, %v; want no key set and no error", keySet, err)
+	}
+	if _, err := ResolveKeySet(AlgorithmRS256, edPath, ""); err == nil {
+		t.Error("RS256 with an Ed25519 public key succeeded")
+	}
+	if _, err := ResolveKeySet(AlgorithmRS256, "", ""); err == nil {
+		t.Error("RS256 without public keys or a JWKS URL succeeded")
+	}
+}
+
+func TestSetKeySetRejectsMismatchedSigningKey(t *testing.T) {
+	manager := NewJWTManager("", time.Hour)
+	if err := manager.SetSigningKey(newRSAKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SetKeySet(AlgorithmEdDSA, StaticKeySet{}); err == nil {
+		t.Fatal("EdDSA verification keys were accepted next to an RS256 signing key")
+	}
+	if err := manager.SetKeySet(AlgorithmHS256, StaticKeySet{}); err == nil {
+		t.Fatal("SetKeySet accepted HS256")
+	}
+}
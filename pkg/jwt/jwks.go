@@ -0,0 +1,171 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+)
+
+const (
+	jwksRefreshInterval = 15 * time.Minute
+	// jwksUnknownKidRefresh bounds how often a token with an unknown kid can trigger a fetch
+	jwksUnknownKidRefresh = time.Minute
+	jwksFetchTimeout      = 5 * time.Second
+	jwksMaxBytes          = 1 << 20
+)
+
+// JWK is the JSON Web Key form of an RSA or Ed25519 public key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWKS encodes keys as signature-verification JWKs
+func NewJWKS(keys []PublicKey) (JWKS, error) {
+	jwks := JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk := JWK{Kid: key.ID, Use: "sig"}
+		switch k := key.Key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty, jwk.Alg = "RSA", AlgorithmRS256
+			jwk.N, jwk.E = encodeBigInt(k.N), encodeBigInt(big.NewInt(int64(k.E)))
+		case ed25519.PublicKey:
+			jwk.Kty, jwk.Alg, jwk.Crv = "OKP", AlgorithmEdDSA, "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(k)
+		default:
+			return JWKS{}, fmt.Errorf("unsupported key type %T, need RSA or Ed25519", key.Key)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks, nil
+}
+
+// PublicKeys decodes the signature keys in the set. Keys of other types or uses are skipped, and a key
+// without a kid is named by its thumbprint.
+func (s JWKS) PublicKeys() []PublicKey {
+	keys := make([]PublicKey, 0, len(s.Keys))
+	for _, jwk := range s.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			logger.Warnf("event=jwks_key_skipped kid=%q error=%v", jwk.Kid, err)
+			continue
+		}
+		id := jwk.Kid
+		if id == "" {
+			if id, err = Thumbprint(key); err != nil {
+				continue
+			}
+		}
+		keys = append(keys, PublicKey{ID: id, Key: key})
+	}
+	return keys
+}
+
+func (jwk JWK) publicKey() (any, error) {
+	switch {
+	case jwk.Kty == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid e")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case jwk.Kty == "OKP" && jwk.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid x")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+}
+
+// JWKSKeySet fetches public keys from a JWKS URL. It refetches every 15 minutes, and sooner, at most once a
+// minute, when a token names a kid it has not seen, so a newly rotated key is picked up without a restart.
+// Keys from the last successful fetch stay in use while the URL is unreachable.
+type JWKSKeySet struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      []PublicKey
+	checkedAt time.Time
+}
+
+var _ KeySet = (*JWKSKeySet)(nil)
+
+func NewJWKSKeySet(url string) *JWKSKeySet {
+	return &JWKSKeySet{url: url, client: &http.Client{Timeout: jwksFetchTimeout}}
+}
+
+func (s *JWKSKeySet) PublicKeys(kid string) []PublicKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	age := time.Since(s.checkedAt)
+	unknown := kid != "" && !slices.ContainsFunc(s.keys, func(key PublicKey) bool { return key.ID == kid })
+	if age > jwksRefreshInterval || (unknown && age > jwksUnknownKidRefresh) {
+		s.checkedAt = time.Now()
+		keys, err := s.fetch()
+		if err != nil {
+			logger.Warnf("event=jwks_fetch_failed url=%s error=%v", s.url, err)
+		} else {
+			s.keys = keys
+		}
+	}
+	return s.keys
+}
+
+func (s *JWKSKeySet) fetch() ([]PublicKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var jwks JWKS
+	if err := json.NewDecoder(io.LimitReader(resp.Body, jwksMaxBytes)).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("decode JWKS: %w", err)
+	}
+	return jwks.PublicKeys(), nil
+}
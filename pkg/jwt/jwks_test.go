@@ -0,0 +1,127 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJWKSRoundTrip(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []PublicKey{{ID: "rsa-1", Key: &rsaKey.PublicKey}, {ID: "ed-1", Key: edPublic}}
+
+	jwks, err := NewJWKS(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded JWKS
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.PublicKeys(); !reflect.DeepEqual(got, keys) {
+		t.Errorf("decoded %+v, want %+v", got, keys)
+	}
+
+	// Keys for encryption and keys of unknown types are skipped, and a key without a kid gets its thumbprint
+	decoded.Keys[0].Kid = ""
+	decoded.Keys = append(decoded.Keys, JWK{Kty: "RSA", Kid: "enc", Use: "enc", N: decoded.Keys[0].N, E: decoded.Keys[0].E}, JWK{Kty: "EC", Kid: "ec"})
+	thumbprint, _ := Thumbprint(&rsaKey.PublicKey)
+	got := decoded.PublicKeys()
+	if len(got) != 2 || got[0].ID != thumbprint || got[1].ID != "ed-1" {
+		t.Errorf("decoded %+v, want the RSA key named %s and the Ed25519 key", got, thumbprint)
+	}
+}
+
+// jwksServer serves the JWKS of the keys stored in it, or 503 while it holds none, and counts its requests
+type jwksServer struct {
+	keys     atomic.Pointer[[]PublicKey]
+	requests atomic.Int32
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.requests.Add(1)
+	keys := s.keys.Load()
+	if keys == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	jwks, _ := NewJWKS(*keys)
+	_ = json.NewEncoder(w).Encode(jwks)
+}
+
+func (s *jwksServer) serve(keys ...*rsa.PrivateKey) {
+	public := make([]PublicKey, 0, len(keys))
+	for _, key := range keys {
+		id, _ := Thumbprint(&key.PublicKey)
+		public = append(public, PublicKey{ID: id, Key: &key.PublicKey})
+	}
+	s.keys.Store(&public)
+}
+
+func TestJWKSKeySetPicksUpRotatedKeys(t *testing.T) {
+	oldKey, newKey := newRSAKey(t), newRSAKey(t)
+	server := &jwksServer{}
+	server.serve(oldKey)
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	keySet := NewJWKSKeySet(ts.URL)
+	verifier := NewJWTManager("", time.Hour)
+	if err := verifier.SetKeySet(AlgorithmRS256, keySet); err != nil {
+		t.Fatal(err)
+	}
+	sign := func(key *rsa.PrivateKey) string {
+		issuer := NewJWTManager("", time.Hour)
+		if err := issuer.SetSigningKey(key); err != nil {
+			t.Fatal(err)
+		}
+		token, err := issuer.Generate(7, "user@example.com", "customer")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	if _, err := verifier.Verify(sign(oldKey)); err != nil {
+		t.Fatalf("token of the served key: %v", err)
+	}
+	server.serve(oldKey, newKey)
+	newToken := sign(newKey)
+
+	// A fetch was just made, so an unknown kid waits out jwksUnknownKidRefresh rather than fetching again
+	if _, err := verifier.Verify(newToken); err == nil {
+		t.Fatal("a token of a key not fetched yet verified")
+	}
+	if n := server.requests.Load(); n != 1 {
+		t.Fatalf("fetched %d times, want 1", n)
+	}
+	keySet.checkedAt = time.Now().Add(-2 * jwksUnknownKidRefresh)
+	if _, err := verifier.Verify(newToken); err != nil {
+		t.Fatalf("token of the rotated key: %v", err)
+	}
+
+	// Keys from the last fetch stay in use while the URL fails
+	server.keys.Store(nil)
+	keySet.checkedAt = time.Now().Add(-2 * jwksRefreshInterval)
+	if _, err := verifier.Verify(sign(oldKey)); err != nil {
+		t.Fatalf("token verified while the JWKS is down: %v", err)
+	}
+	if n := server.requests.Load(); n != 3 {
+		t.Errorf("fetched %d times, want 3", n)
+	}
+}
@@ -1,6 +1,11 @@
 package jwt
 
 import (
+	"crypto"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -10,46 +15,243 @@ type UserClaims struct {
 	jwt.RegisteredClaims
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
-	Role   string `json:"role"`
+	// Role is the primary role, kept for consumers that predate Roles
+	Role  string   `json:"role"`
+	Roles []string `json:"roles,omitempty"`
+	// Permissions is resolved from the roles when the token is issued
+	Permissions []string `json:"permissions,omitempty"`
+	// TwoFactor is set on tokens issued after the user passed a two-factor check
+	TwoFactor bool `json:"two_factor,omitempty"`
+	// Purpose marks tokens that are not access tokens, such as PurposeTwoFactorChallenge
+	Purpose string `json:"purpose,omitempty"`
+	// ImpersonatedBy is the admin a token from GenerateImpersonation was issued to, acting as UserID
+	ImpersonatedBy uint `json:"impersonated_by,omitempty"`
 }
 
+// Impersonated reports whether the token was issued to an admin acting as the user, rather than to the user
+func (c *UserClaims) Impersonated() bool {
+	return c.ImpersonatedBy != 0
+}
+
+// PurposeTwoFactorChallenge marks the token a password login returns to users with two-factor authentication.
+// It only proves the password was right, and is exchanged for an access token together with a code.
+const PurposeTwoFactorChallenge = "two_factor_challenge"
+
+// ErrTokenPurpose is returned for a token issued for another use than the one it is presented for, e.g. a
+// two-factor challenge presented as an access token
+var ErrTokenPurpose = errors.New("token was issued for another purpose")
+
 type JWTService interface {
-	Generate(userID uint, email, role string) (string, error)
+	Generate(userID uint, email string, roles ...string) (string, error)
 	Validate(token string) (*UserClaims, error)
 }
 
+// DefaultLeeway is how far the issuer's and verifier's clocks may drift apart
+const DefaultLeeway = 30 * time.Second
+
 type JWTManager struct {
-	secretKey     string
-	tokenDuration time.Duration
+	// method is the only algorithm tokens are issued or accepted with: HS256 unless
+	// SetSigningKey or SetKeySet switch to an asymmetric one
+	method jwt.SigningMethod
+	// keys[0] signs new HS256 tokens; every key is accepted when verifying
+	keys []Key
+	// signer signs RS256 and EdDSA tokens under the kid signerID
+	signer   crypto.Signer
+	signerID string
+	// publicKeys verify RS256 and EdDSA tokens
+	publicKeys      KeySet
+	tokenDuration   time.Duration
+	rolePermissions map[string][]string
+	issuer          string
+	audience        string
+	leeway          time.Duration
 }
 
 func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
-	return &JWTManager{secretKey, tokenDuration}
+	return &JWTManager{method: jwt.SigningMethodHS256, keys: []Key{{Secret: secretKey}}, tokenDuration: tokenDuration, rolePermissions: DefaultRolePermissions, leeway: DefaultLeeway}
+}
+
+// SetKeys replaces the secret given to NewJWTManager. The first key signs new tokens and the rest are
+// only used to verify, so tokens signed before a rotation keep working until they expire. keys must not be empty.
+func (manager *JWTManager) SetKeys(keys []Key) {
+	manager.keys = keys
+}
+
+// SetSigningKey makes Generate sign with an RSA or Ed25519 private key, as RS256 or EdDSA. The kid is the
+// key's RFC 7638 thumbprint, and Verify accepts the key's own tokens alongside any from SetKeySet.
+func (manager *JWTManager) SetSigningKey(signer crypto.Signer) error {
+	algorithm, err := algorithmFor(signer.Public())
+	if err != nil {
+		return err
+	}
+	if manager.publicKeys != nil && manager.method.Alg() != algorithm {
+		return fmt.Errorf("signing key is %s but verification keys are %s", algorithm, manager.method.Alg())
+	}
+	id, err := Thumbprint(signer.Public())
+	if err != nil {
+		return err
+	}
+
+	manager.method, _ = signingMethodFor(algorithm)
+	manager.signer = signer
+	manager.signerID = id
+	return nil
+}
+
+// SetKeySet makes Verify accept only algorithm, RS256 or EdDSA, checked against keys. HS256 tokens are then
+// rejected, so a public key can never be replayed as an HMAC secret.
+func (manager *JWTManager) SetKeySet(algorithm string, keys KeySet) error {
+	if algorithm == AlgorithmHS256 {
+		return fmt.Errorf("%s verifies with shared secrets, see SetKeys", AlgorithmHS256)
+	}
+	method, err := signingMethodFor(algorithm)
+	if err != nil {
+		return err
+	}
+	if manager.signer != nil && manager.method != method {
+		return fmt.Errorf("verification keys are %s but the signing key is %s", algorithm, manager.method.Alg())
+	}
+
+	manager.method = method
+	manager.publicKeys = keys
+	return nil
+}
+
+// PublicKeys returns the keys that verify asymmetric tokens, for publishing as a JWKS. It is empty under HS256.
+func (manager *JWTManager) PublicKeys() []PublicKey {
+	var keys []PublicKey
+	if manager.publicKeys != nil {
+		keys = slices.Clone(manager.publicKeys.PublicKeys(""))
+	}
+	if manager.signer != nil && !slices.ContainsFunc(keys, func(key PublicKey) bool { return key.ID == manager.signerID }) {
+		keys = append(keys, PublicKey{ID: manager.signerID, Key: manager.signer.Public()})
+	}
+	return keys
+}
+
+// SetIssuerAudience sets the iss and aud claims Generate writes and Verify requires. An empty value is neither set nor checked.
+func (manager *JWTManager) SetIssuerAudience(issuer, audience string) {
+	manager.issuer = issuer
+	manager.audience = audience
+}
+
+// SetLeeway sets the clock skew Verify tolerates on exp, nbf and iat
+func (manager *JWTManager) SetLeeway(leeway time.Duration) {
+	manager.leeway = leeway
+}
+
+// SetRolePermissions replaces the role to permissions mapping used by Generate and Verify
+func (manager *JWTManager) SetRolePermissions(rolePermissions map[string][]string) {
+	manager.rolePermissions = rolePermissions
+}
+
+// Generate issues a token for roles, the first of which is the primary role
+func (manager *JWTManager) Generate(userID uint, email string, roles ...string) (string, error) {
+	return manager.sign(manager.accessClaims(userID, email, roles))
+}
+
+// GenerateTwoFactor issues a token like Generate, marked as issued after a two-factor check
+func (manager *JWTManager) GenerateTwoFactor(userID uint, email string, roles ...string) (string, error) {
+	claims := manager.accessClaims(userID, email, roles)
+	claims.TwoFactor = true
+	return manager.sign(claims)
 }
 
-func (manager *JWTManager) Generate(userID uint, email, role string) (string, error) {
+// GenerateChallenge issues a PurposeTwoFactorChallenge token for userID, valid for ttl, with challengeID as its
+// jti so the issuer can tell the challenges apart and retire each once it is used up
+func (manager *JWTManager) GenerateChallenge(userID uint, challengeID string, ttl time.Duration) (string, error) {
+	claims := manager.newClaims(userID, ttl)
+	claims.ID = challengeID
+	claims.Purpose = PurposeTwoFactorChallenge
+	return manager.sign(claims)
+}
+
+// GenerateImpersonation issues adminID an access token for userID, valid for ttl, that carries adminID as
+// impersonated_by
+func (manager *JWTManager) GenerateImpersonation(adminID, userID uint, email string, roles []string, ttl time.Duration) (string, error) {
+	claims := manager.accessClaims(userID, email, roles)
+	claims.ExpiresAt = jwt.NewNumericDate(claims.IssuedAt.Add(ttl))
+	claims.ImpersonatedBy = adminID
+	return manager.sign(claims)
+}
+
+func (manager *JWTManager) accessClaims(userID uint, email string, roles []string) UserClaims {
+	var role string
+	if len(roles) > 0 {
+		role = roles[0]
+	}
+
+	claims := manager.newClaims(userID, manager.tokenDuration)
+	claims.Email = email
+	claims.Role = role
+	claims.Roles = roles
+	claims.Permissions = manager.permissionsFor(roles)
+	return claims
+}
+
+// newClaims returns the registered claims of a token for userID that expires after ttl
+func (manager *JWTManager) newClaims(userID uint, ttl time.Duration) UserClaims {
+	now := time.Now()
 	claims := UserClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(manager.tokenDuration)),
+			Issuer:    manager.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 		},
 		UserID: userID,
-		Email:  email,
-		Role:   role,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(manager.secretKey))
+	if manager.audience != "" {
+		claims.Audience = jwt.ClaimStrings{manager.audience}
+	}
+	return claims
 }
 
+func (manager *JWTManager) sign(claims UserClaims) (string, error) {
+	token := jwt.NewWithClaims(manager.method, claims)
+	switch {
+	case manager.signer != nil:
+		token.Header["kid"] = manager.signerID
+		return token.SignedString(manager.signer)
+	case manager.method != jwt.SigningMethodHS256:
+		return "", fmt.Errorf("no %s signing key configured", manager.method.Alg())
+	}
+
+	signingKey := manager.keys[0]
+	if signingKey.ID != "" {
+		token.Header["kid"] = signingKey.ID
+	}
+	return token.SignedString([]byte(signingKey.Secret))
+}
+
+// Verify checks the signature against the configured keys, then the time claims with the configured leeway, then iss and aud.
+// Tokens must carry an exp claim and be signed with the configured algorithm, HS256 by default; alg=none and
+// every other algorithm are rejected. So are tokens with a purpose, which are not access tokens.
 func (manager *JWTManager) Verify(accessToken string) (*UserClaims, error) {
-	token, err := jwt.ParseWithClaims(
-		accessToken,
-		&UserClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(manager.secretKey), nil
-		},
-	)
+	claims, err := manager.verify(accessToken, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Tokens issued before multiple roles or permissions existed only carry a role
+	if len(claims.Roles) == 0 && claims.Role != "" {
+		claims.Roles = []string{claims.Role}
+	}
+	if claims.Permissions == nil {
+		claims.Permissions = manager.permissionsFor(claims.Roles)
+	}
+
+	return claims, nil
+}
 
+// VerifyChallenge checks a token from GenerateChallenge as Verify checks access tokens, and returns its claims
+func (manager *JWTManager) VerifyChallenge(challengeToken string) (*UserClaims, error) {
+	return manager.verify(challengeToken, PurposeTwoFactorChallenge)
+}
+
+// verify checks a token's signature and claims, and that it was issued for purpose
+func (manager *JWTManager) verify(tokenString, purpose string) (*UserClaims, error) {
+	token, err := manager.verifySignature(tokenString)
 	if err != nil {
 		return nil, err
 	}
@@ -59,5 +261,110 @@ func (manager *JWTManager) Verify(accessToken string) (*UserClaims, error) {
 		return nil, jwt.ErrTokenMalformed
 	}
 
+	if err := manager.validateClaims(claims, time.Now()); err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purpose {
+		return nil, ErrTokenPurpose
+	}
 	return claims, nil
 }
+
+// verifySignature checks the signature against the key named by the kid header. A kid none of the keys has is
+// rejected rather than tried against them all, so a retired key ID stops being accepted once it is dropped.
+// Tokens without a kid, signed before keys had one, are checked against every key.
+func (manager *JWTManager) verifySignature(accessToken string) (*jwt.Token, error) {
+	token, parts, err := new(jwt.Parser).ParseUnverified(accessToken, &UserClaims{})
+	if err != nil {
+		return nil, err
+	}
+	if token.Method != manager.method {
+		return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+	}
+
+	signingString := strings.Join(parts[:2], ".")
+	kid, _ := token.Header["kid"].(string)
+	keys := manager.verificationKeys(kid)
+	if len(keys) == 0 && kid != "" {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	for _, key := range keys {
+		if err := manager.method.Verify(signingString, parts[2], key); err == nil {
+			token.Signature = parts[2]
+			token.Valid = true
+			return token, nil
+		}
+	}
+	return nil, jwt.ErrSignatureInvalid
+}
+
+// verificationKeys lists the keys to try for a token, only the one named kid if it has one: HMAC secrets under
+// HS256, otherwise the public keys from SetKeySet and the signing key's own
+func (manager *JWTManager) verificationKeys(kid string) []any {
+	var keys []any
+	if manager.method == jwt.SigningMethodHS256 {
+		for _, key := range keysFor(manager.keys, kid, func(key Key) string { return key.ID }) {
+			keys = append(keys, []byte(key.Secret))
+		}
+		return keys
+	}
+
+	var publicKeys []PublicKey
+	if manager.publicKeys != nil {
+		publicKeys = slices.Clone(manager.publicKeys.PublicKeys(kid))
+	}
+	if manager.signer != nil {
+		publicKeys = append(publicKeys, PublicKey{ID: manager.signerID, Key: manager.signer.Public()})
+	}
+	for _, key := range keysFor(publicKeys, kid, func(key PublicKey) string { return key.ID }) {
+		keys = append(keys, key.Key)
+	}
+	return keys
+}
+
+// keysFor returns the keys named kid, or every key for a token without one
+func keysFor[K any](keys []K, kid string, idOf func(K) string) []K {
+	if kid == "" {
+		return keys
+	}
+	var named []K
+	for _, key := range keys {
+		if idOf(key) == kid {
+			named = append(named, key)
+		}
+	}
+	return named
+}
+
+// validateClaims applies the registered claim checks jwt.Parser would, widened by the leeway
+func (manager *JWTManager) validateClaims(claims *UserClaims, now time.Time) error {
+	if !claims.VerifyExpiresAt(now.Add(-manager.leeway), true) {
+		return jwt.ErrTokenExpired
+	}
+	if !claims.VerifyNotBefore(now.Add(manager.leeway), false) {
+		return jwt.ErrTokenNotValidYet
+	}
+	if !claims.VerifyIssuedAt(now.Add(manager.leeway), false) {
+		return jwt.ErrTokenUsedBeforeIssued
+	}
+	if manager.issuer != "" && !claims.VerifyIssuer(manager.issuer, true) {
+		return jwt.ErrTokenInvalidIssuer
+	}
+	if manager.audience != "" && !claims.VerifyAudience(manager.audience, true) {
+		return jwt.ErrTokenInvalidAudience
+	}
+	return nil
+}
+
+// permissionsFor returns the union of the permissions of roles
+func (manager *JWTManager) permissionsFor(roles []string) []string {
+	var permissions []string
+	for _, role := range roles {
+		for _, permission := range manager.rolePermissions[role] {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
@@ -0,0 +1,219 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// newVerifier returns an HS256 manager requiring the user-service issuer and the api-gateway audience, with
+// the default leeway
+func newVerifier() *JWTManager {
+	manager := NewJWTManager("secret", time.Hour)
+	manager.SetIssuerAudience("user-service", "api-gateway")
+	return manager
+}
+
+// signedToken signs a token for user 7 with method and key, with the registered claims edit leaves
+func signedToken(t *testing.T, method jwt.SigningMethod, key any, edit func(*jwt.RegisteredClaims)) string {
+	t.Helper()
+	now := time.Now()
+	claims := UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "user-service",
+			Audience:  jwt.ClaimStrings{"api-gateway"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		UserID: 7,
+		Role:   "customer",
+	}
+	if edit != nil {
+		edit(&claims.RegisteredClaims)
+	}
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	ago := func(d time.Duration) *jwt.NumericDate { return jwt.NewNumericDate(time.Now().Add(-d)) }
+	tests := []struct {
+		name    string
+		token   func(t *testing.T) string
+		wantErr error
+		// wantAlgRefused is set for algorithms refused before the signature is looked at
+		wantAlgRefused bool
+	}{
+		{
+			name:  "valid",
+			token: func(t *testing.T) string { return signedToken(t, jwt.SigningMethodHS256, secret, nil) },
+		},
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				return signedToken(t, jwt.SigningMethodHS256, secret, func(c *jwt.RegisteredClaims) { c.IssuedAt, c.ExpiresAt = ago(2*time.Hour), ago(time.Hour) })
+			},
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name: "expired within the leeway",
+			token: func(t *testing.T) string {
+				return signedToken(t, jwt.SigningMethodHS256, secret, func(c *jwt.RegisteredClaims) { c.IssuedAt, c.ExpiresAt = ago(time.Hour), ago(10*time.Second) })
+			},
+		},
+		{
+			name: "without exp",
+			token: func(t *testing.T) string {
+				return signedToken(t, jwt.SigningMethodHS256, secret, func(c *jwt.RegisteredClaims) { c.ExpiresAt = nil })
+			},
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name: "issued by a clock running ahead within the leeway",
+			token: func(t *testing.T) string {
+				return signedToken(t, jwt.SigningMethodHS256, secret, func(c *jwt.RegisteredClaims) { c.IssuedAt, c.NotBefore = ago(-10*time.Second), ago(-10*time.Second) })
+			},
+		},
+		{
+			name: "issued in the future",
+			token: func(t *testing.T) string {
+				return signedToken(t, jwt.SigningMethodHS256, secret, func(c *jwt.RegisteredClaims) { c.IssuedAt = ago(-time.Minute) })
+			},
+			wantErr: jwt.ErrTokenUsedBeforeIssued,
+		},
+		{
+			name: "not valid yet",
+			token: func(t *testing.T) string {
+				return signedToken(t, jwt.SigningMethodHS256, secret, func(c *jwt.RegisteredClaims) { c.NotBefore = ago(-time.Minute) })
+			},
+			wantErr: jwt.ErrTokenNotValidYet,
+		},
+		{
+			name: "wrong issuer",
+			token: func(t *testing.T) string {
+				return signedToken(t, jwt.SigningMethodHS256, secret, func(c *jwt.RegisteredClaims) { c.Issuer = "someone-else" })
+			},
+			wantErr: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name: "without issuer",
+			token: func(t *testing.T) string {
+				return signedToken(t, jwt.SigningMethodHS256, secret, func(c *jwt.RegisteredClaims) { c.Issuer = "" })
+			},
+			wantErr: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name: "wrong audience",
+			token: func(t *testing.T) string {
+				return signedToken(t, jwt.SigningMethodHS256, secret, func(c *jwt.RegisteredClaims) { c.Audience = jwt.ClaimStrings{"admin-panel"} })
+			},
+			wantErr: jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name:    "wrong secret",
+			token:   func(t *testing.T) string { return signedToken(t, jwt.SigningMethodHS256, []byte("other"), nil) },
+			wantErr: jwt.ErrSignatureInvalid,
+		},
+		{
+			name: "alg none",
+			token: func(t *testing.T) string {
+				return signedToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, nil)
+			},
+			wantAlgRefused: true,
+		},
+		{
+			name:           "HS512",
+			token:          func(t *testing.T) string { return signedToken(t, jwt.SigningMethodHS512, secret, nil) },
+			wantAlgRefused: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := newVerifier().Verify(tt.token(t))
+			switch {
+			case tt.wantAlgRefused:
+				if err == nil || !strings.Contains(err.Error(), "unexpected signing method") {
+					t.Fatalf("Verify = %v, want the algorithm refused", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Verify = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("Verify = %v, want the token accepted", err)
+			case claims.UserID != 7:
+				t.Fatalf("claims = %+v, want user 7", claims)
+			}
+		})
+	}
+}
+
+func TestVerifyWithoutLeeway(t *testing.T) {
+	manager := newVerifier()
+	manager.SetLeeway(0)
+	token := signedToken(t, jwt.SigningMethodHS256, []byte("secret"), func(c *jwt.RegisteredClaims) {
+		c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Second))
+	})
+	if _, err := manager.Verify(token); !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Fatalf("Verify = %v, want a token 10s past exp refused without leeway", err)
+	}
+}
+
+func TestGenerateSetsTheConfiguredClaims(t *testing.T) {
+	manager := NewJWTManager("secret", 15*time.Minute)
+	manager.SetIssuerAudience("user-service", "api-gateway")
+	token, err := manager.Generate(7, "mona@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := manager.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Issuer != "user-service" || len(claims.Audience) != 1 || claims.Audience[0] != "api-gateway" {
+		t.Errorf("iss %q and aud %v, want user-service and api-gateway", claims.Issuer, claims.Audience)
+	}
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != 15*time.Minute {
+		t.Errorf("the token lives %v, want 15m", ttl)
+	}
+}
+
+func TestGenerateImpersonation(t *testing.T) {
+	manager := NewJWTManager("secret", 24*time.Hour)
+	manager.SetIssuerAudience("user-service", "api-gateway")
+	token, err := manager.GenerateImpersonation(1, 7, "mona@example.com", []string{"customer", "support"}, 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := manager.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claims.Impersonated() || claims.ImpersonatedBy != 1 {
+		t.Errorf("impersonated_by = %d, want the admin's 1", claims.ImpersonatedBy)
+	}
+	if claims.UserID != 7 || claims.Email != "mona@example.com" || claims.Role != "customer" || len(claims.Roles) != 2 {
+		t.Errorf("claims = %+v, want user 7 with their email and roles", claims)
+	}
+	// The impersonation lifetime replaces the access token one
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != 15*time.Minute {
+		t.Errorf("the token lives %v, want 15m", ttl)
+	}
+
+	regular, err := manager.Generate(7, "mona@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims, err := manager.Verify(regular); err != nil || claims.Impersonated() {
+		t.Errorf("a regular token verifies as %+v, %v, want one that is not impersonated", claims, err)
+	}
+}
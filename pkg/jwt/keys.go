@@ -0,0 +1,78 @@
+package jwt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Key is an HMAC secret named by the kid header of the tokens it signs
+type Key struct {
+	ID     string
+	Secret string
+}
+
+// ParseKeys reads a comma-separated list of kid:secret pairs, e.g. "2026-06:secretB,2026-01:secretA".
+// The first key signs new tokens; the rest are only accepted when verifying, so rotating in a new
+// key does not end the sessions signed with the old one.
+func ParseKeys(value string) ([]Key, error) {
+	var keys []Key
+	for _, pair := range strings.Split(value, ",") {
+		id, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("key %q must be written as kid:secret", pair)
+		}
+		keys = append(keys, Key{ID: strings.TrimSpace(id), Secret: secret})
+	}
+	return keys, validateKeys(keys)
+}
+
+// LoadKeyFiles reads one key per file, the layout secret managers mount: the file name is the kid and
+// its contents, less surrounding whitespace, the secret. The first file holds the signing key.
+func LoadKeyFiles(paths ...string) ([]Key, error) {
+	keys := make([]Key, 0, len(paths))
+	for _, path := range paths {
+		secret, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read key file: %w", err)
+		}
+		keys = append(keys, Key{ID: filepath.Base(path), Secret: strings.TrimSpace(string(secret))})
+	}
+	return keys, validateKeys(keys)
+}
+
+// ResolveKeys picks the first key source that is set: a comma-separated list of key files, then a kid:secret
+// list, then a single secret without a kid, which is how tokens were signed before keys could be rotated
+func ResolveKeys(files, secrets, secret string) ([]Key, error) {
+	switch {
+	case files != "":
+		paths := strings.Split(files, ",")
+		for i := range paths {
+			paths[i] = strings.TrimSpace(paths[i])
+		}
+		return LoadKeyFiles(paths...)
+	case secrets != "":
+		return ParseKeys(secrets)
+	case secret != "":
+		return []Key{{Secret: secret}}, nil
+	}
+	return nil, fmt.Errorf("no signing key configured")
+}
+
+func validateKeys(keys []Key) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("at least one key is required")
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.ID == "" || key.Secret == "" {
+			return fmt.Errorf("every key needs a kid and a secret")
+		}
+		if seen[key.ID] {
+			return fmt.Errorf("kid %q is listed twice", key.ID)
+		}
+		seen[key.ID] = true
+	}
+	return nil
+}
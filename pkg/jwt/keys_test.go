@@ -0,0 +1,209 @@
+package jwt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys("2026-06:secretB, 2026-01:secret:A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Key{{ID: "2026-06", Secret: "secretB"}, {ID: "2026-01", Secret: "secret:A"}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %+v, want %+v", keys, want)
+	}
+
+	for _, value := range []string{"", "secretA", "2026-01:", ":secretA", "2026-01:a,2026-01:b"} {
+		if keys, err := ParseKeys(value); err == nil {
+			t.Errorf("ParseKeys(%q) = %+v, want an error", value, keys)
+		}
+	}
+}
+
+func TestLoadKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{"2026-06": "secretB\n", "2026-01": "  secretA  ", "empty": "\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := ResolveKeys(filepath.Join(dir, "2026-06")+", "+filepath.Join(dir, "2026-01"), "other:secret", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Key{{ID: "2026-06", Secret: "secretB"}, {ID: "2026-01", Secret: "secretA"}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %+v, want %+v", keys, want)
+	}
+
+	if _, err := LoadKeyFiles(filepath.Join(dir, "empty")); err == nil {
+		t.Error("an empty key file was accepted")
+	}
+	if _, err := LoadKeyFiles(filepath.Join(dir, "missing")); err == nil {
+		t.Error("a missing key file was accepted")
+	}
+}
+
+func TestResolveKeysPrecedence(t *testing.T) {
+	if keys, _ := ResolveKeys("", "2026-06:secretB", "secret"); len(keys) != 1 || keys[0].ID != "2026-06" {
+		t.Errorf("JWT_SECRETS and JWT_SECRET: keys = %+v, want the kid:secret list", keys)
+	}
+	if keys, _ := ResolveKeys("", "", "secret"); !reflect.DeepEqual(keys, []Key{{Secret: "secret"}}) {
+		t.Errorf("JWT_SECRET only: keys = %+v, want the secret without a kid", keys)
+	}
+	if _, err := ResolveKeys("", "", ""); err == nil {
+		t.Error("no key at all was accepted")
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey := Key{ID: "2026-01", Secret: "secretA"}
+	newKey := Key{ID: "2026-06", Secret: "secretB"}
+
+	before := NewJWTManager("", time.Hour)
+	before.SetKeys([]Key{oldKey})
+	oldToken, err := before.Generate(7, "mona@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// During the rotation window the new key signs and the old one still verifies
+	during := NewJWTManager("", time.Hour)
+	during.SetKeys([]Key{newKey, oldKey})
+	if claims, err := during.Verify(oldToken); err != nil || claims.UserID != 7 {
+		t.Fatalf("old token during the rotation: %+v, %v; want it accepted", claims, err)
+	}
+	newToken, err := during.Generate(7, "mona@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := headerKid(t, newToken); kid != newKey.ID {
+		t.Errorf("new tokens are signed under kid %q, want %q", kid, newKey.ID)
+	}
+
+	// Once the old key is dropped, so are its tokens
+	after := NewJWTManager("", time.Hour)
+	after.SetKeys([]Key{newKey})
+	if _, err := after.Verify(oldToken); err == nil || !strings.Contains(err.Error(), "unknown kid") {
+		t.Errorf("old token after the rotation: %v, want an unknown kid", err)
+	}
+	if _, err := after.Verify(newToken); err != nil {
+		t.Errorf("new token after the rotation: %v", err)
+	}
+}
+
+func TestKeyRotationFromASingleSecret(t *testing.T) {
+	// Tokens signed with JWT_SECRET have no kid, so they are tried against every key
+	legacyToken, err := NewJWTManager("secretA", time.Hour).Generate(7, "mona@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := headerKid(t, legacyToken); kid != "" {
+		t.Fatalf("a JWT_SECRET token has kid %q", kid)
+	}
+
+	manager := NewJWTManager("", time.Hour)
+	manager.SetKeys([]Key{{ID: "2026-06", Secret: "secretB"}, {ID: "legacy", Secret: "secretA"}})
+	if _, err := manager.Verify(legacyToken); err != nil {
+		t.Errorf("a token without a kid signed by a listed key: %v", err)
+	}
+
+	manager.SetKeys([]Key{{ID: "2026-06", Secret: "secretB"}})
+	if _, err := manager.Verify(legacyToken); err == nil {
+		t.Error("a token without a kid was accepted after its key was dropped")
+	}
+}
+
+func TestVerifyRejectsAKidOfAnotherKey(t *testing.T) {
+	manager := NewJWTManager("", time.Hour)
+	manager.SetKeys([]Key{{ID: "2026-06", Secret: "secretB"}, {ID: "2026-01", Secret: "secretA"}})
+
+	// Signed with a valid key but naming the other one, so it is checked against that key only
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		UserID:           7,
+	})
+	token.Header["kid"] = "2026-06"
+	signed, err := token.SignedString([]byte("secretA"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Verify(signed); err == nil {
+		t.Error("a token signed with one key under the kid of another was accepted")
+	}
+}
+
+// headerKid returns the kid header of token
+func headerKid(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, &UserClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+// tokenWithKid signs a token for user 7 with method and key under the kid header kid
+func tokenWithKid(t *testing.T, method jwt.SigningMethod, key any, kid string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		UserID:           7,
+	})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestVerifyRejectsAnUnknownKid(t *testing.T) {
+	hmac := NewJWTManager("", time.Hour)
+	hmac.SetKeys([]Key{{ID: "2026-06", Secret: "secretB"}, {ID: "2026-01", Secret: "secretA"}})
+
+	rsaKey := newRSAKey(t)
+	rsaIssuer, rsaVerifier := rs256Pair(t, rsaKey)
+	rsaToken, err := rsaIssuer.Generate(7, "mona@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		verifier *JWTManager
+		token    string
+		wantErr  bool
+	}{
+		{name: "HS256 current key", verifier: hmac, token: tokenWithKid(t, jwt.SigningMethodHS256, []byte("secretB"), "2026-06")},
+		{name: "HS256 retired key", verifier: hmac, token: tokenWithKid(t, jwt.SigningMethodHS256, []byte("secretA"), "2026-01")},
+		// Signed with a configured secret, so only the kid check stands between it and acceptance
+		{name: "HS256 unknown kid", verifier: hmac, token: tokenWithKid(t, jwt.SigningMethodHS256, []byte("secretA"), "2025-07"), wantErr: true},
+		{name: "RS256 key of the set", verifier: rsaVerifier, token: rsaToken},
+		{name: "RS256 unknown kid", verifier: rsaVerifier, token: tokenWithKid(t, jwt.SigningMethodRS256, rsaKey, "retired"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.verifier.Verify(tt.token)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "unknown kid") {
+					t.Fatalf("Verify = %+v, %v; want an unknown kid", claims, err)
+				}
+				return
+			}
+			if err != nil || claims.UserID != 7 {
+				t.Fatalf("Verify = %+v, %v; want user 7", claims, err)
+			}
+		})
+	}
+}
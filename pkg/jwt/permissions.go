@@ -0,0 +1,73 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+const (
+	PermissionProductWrite  = "product:write"
+	PermissionCategoryWrite = "category:write"
+	PermissionUserRead      = "user:read"
+	PermissionUserWrite     = "user:write"
+	PermissionOrderRead     = "order:read"
+	PermissionOrderWrite    = "order:write"
+	PermissionReportRead    = "report:read"
+)
+
+// AllPermissions lists every permission the gateway checks
+var AllPermissions = []string{
+	PermissionProductWrite,
+	PermissionCategoryWrite,
+	PermissionUserRead,
+	PermissionUserWrite,
+	PermissionOrderRead,
+	PermissionOrderWrite,
+	PermissionReportRead,
+}
+
+// DefaultRolePermissions is used for any role ROLE_PERMISSIONS_JSON does not mention
+var DefaultRolePermissions = map[string][]string{
+	"admin": {
+		PermissionProductWrite,
+		PermissionCategoryWrite,
+		PermissionUserRead,
+		PermissionUserWrite,
+		PermissionOrderRead,
+		PermissionOrderWrite,
+		PermissionReportRead,
+	},
+	"customer": {},
+}
+
+// ParseRolePermissions merges a JSON object of role to permissions, e.g. {"catalog_manager":["product:write"]},
+// over DefaultRolePermissions. A role listed in value replaces its default entry.
+func ParseRolePermissions(value string) (map[string][]string, error) {
+	result := make(map[string][]string, len(DefaultRolePermissions))
+	for role, permissions := range DefaultRolePermissions {
+		result[role] = slices.Clone(permissions)
+	}
+	if value == "" {
+		return result, nil
+	}
+
+	var overrides map[string][]string
+	if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+		return nil, fmt.Errorf("role permissions must be a JSON object of string arrays: %w", err)
+	}
+	for role, permissions := range overrides {
+		result[role] = permissions
+	}
+	return result, nil
+}
+
+// HasRole reports whether role is one of the token's roles
+func (c *UserClaims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role) || c.Role == role
+}
+
+// HasPermission reports whether the token grants permission
+func (c *UserClaims) HasPermission(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
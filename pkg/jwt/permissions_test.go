@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestParseRolePermissions(t *testing.T) {
+	permissions, err := ParseRolePermissions(`{"catalog_manager": ["product:write"], "customer": ["order:read"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"admin":           DefaultRolePermissions["admin"],
+		"customer":        {PermissionOrderRead},
+		"catalog_manager": {PermissionProductWrite},
+	}
+	if !reflect.DeepEqual(permissions, want) {
+		t.Errorf("permissions = %v, want %v", permissions, want)
+	}
+	if len(DefaultRolePermissions["customer"]) != 0 {
+		t.Errorf("the defaults were changed to %v", DefaultRolePermissions)
+	}
+
+	if _, err := ParseRolePermissions(`{"catalog_manager": "product:write"}`); err == nil {
+		t.Error("a role mapped to a string was accepted")
+	}
+}
+
+func TestCustomRoleOnlyHoldsItsPermissions(t *testing.T) {
+	manager := NewJWTManager("secret", time.Hour)
+	permissions, err := ParseRolePermissions(`{"catalog_manager": ["product:write"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.SetRolePermissions(permissions)
+
+	token, err := manager.Generate(7, "editor@example.com", "catalog_manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := manager.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !claims.HasPermission(PermissionProductWrite) {
+		t.Errorf("permissions = %v, want product:write", claims.Permissions)
+	}
+	for _, permission := range AllPermissions {
+		if permission != PermissionProductWrite && claims.HasPermission(permission) {
+			t.Errorf("the catalog manager holds %s", permission)
+		}
+	}
+	if claims.HasRole("admin") || !claims.HasRole("catalog_manager") {
+		t.Errorf("roles = %v, want catalog_manager only", claims.Roles)
+	}
+}
+
+func TestPermissionsOfTokensWithoutThem(t *testing.T) {
+	manager := NewJWTManager("secret", time.Hour)
+
+	// Issued before permissions existed: only the primary role is in the token
+	claims := manager.newClaims(7, time.Hour)
+	claims.Role = "admin"
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verified, err := manager.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(verified.Permissions, DefaultRolePermissions["admin"]) {
+		t.Errorf("permissions = %v, want the admin role's", verified.Permissions)
+	}
+}
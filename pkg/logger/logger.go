@@ -16,35 +16,36 @@ type logger struct {
 var (
 	globalLogger *logger
 	once         sync.Once
+	zapLevel     = zap.NewAtomicLevel()
 )
 
+// new logs to stdout and, unless path is empty, to a rotated JSON file at path
 func new(env, path string) *logger {
 
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
-	lumberJackLogger := &lumberjack.Logger{
-		Filename:   path,
-		MaxSize:    5,
-		MaxBackups: 10,
-		MaxAge:     15,
-		Compress:   true,
-	}
-
-	var logLevel zapcore.Level
-
-	if env == "development" || env == "local" {
-		logLevel = zap.DebugLevel
+	if isDevelopment(env) {
+		zapLevel.SetLevel(zap.DebugLevel)
 	} else {
-		logLevel = zap.InfoLevel
+		zapLevel.SetLevel(zap.InfoLevel)
 	}
 
-	core := zapcore.NewTee(
-		zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(lumberJackLogger), logLevel),
-		zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.AddSync(os.Stdout), logLevel),
-	)
+	cores := []zapcore.Core{
+		zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.AddSync(os.Stdout), zapLevel),
+	}
+	if path != "" {
+		lumberJackLogger := &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    5,
+			MaxBackups: 10,
+			MaxAge:     15,
+			Compress:   true,
+		}
+		cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(lumberJackLogger), zapLevel))
+	}
 
-	base := zap.New(core)
+	base := zap.New(zapcore.NewTee(cores...))
 
 	return &logger{base.Sugar()}
 }
@@ -56,10 +57,12 @@ func InitGlobal(env string, path string) *logger {
 	return globalLogger
 }
 
+// Get returns the global logger. Until InitGlobal is called, as in tests, it only logs to stdout, so no log file
+// appears in the working directory.
 func Get() *logger {
 
 	if globalLogger == nil {
-		InitGlobal(os.Getenv("APP_ENV"), "logs/system.log")
+		InitGlobal(os.Getenv("APP_ENV"), "")
 	}
 	return globalLogger
 }
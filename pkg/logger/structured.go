@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type contextKey struct{}
+
+// level is shared by every structured logger so SetLevel takes effect without rebuilding them
+var level slog.LevelVar
+
+// NewStructured returns a slog logger that writes JSON in production and readable text in development
+func NewStructured(env string) *slog.Logger {
+	return NewStructuredWithFormat(env, "")
+}
+
+// NewStructuredWithFormat is NewStructured with an explicit FormatText or FormatJSON; an empty format picks by env
+func NewStructuredWithFormat(env, format string) *slog.Logger {
+	if isDevelopment(env) {
+		SetLevel(slog.LevelDebug)
+	} else {
+		SetLevel(slog.LevelInfo)
+	}
+	if format == "" {
+		format = FormatJSON
+		if isDevelopment(env) {
+			format = FormatText
+		}
+	}
+
+	options := &slog.HandlerOptions{Level: &level}
+	if format == FormatText {
+		return slog.New(slog.NewTextHandler(os.Stdout, options))
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, options))
+}
+
+// With returns the default structured logger with attrs attached, for per-handler loggers
+func With(attrs ...any) *slog.Logger {
+	return slog.Default().With(attrs...)
+}
+
+// IntoContext stores a request-scoped logger, typically carrying request_id and user_id
+func IntoContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored by IntoContext, or the default structured logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// Level returns the current minimum level of the structured and printf-style loggers
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the minimum level of the structured and printf-style loggers at runtime and returns the previous one
+func SetLevel(l slog.Level) slog.Level {
+	previous := level.Level()
+	level.Set(l)
+	// slog levels are spaced four apart, zap levels one apart
+	zapLevel.SetLevel(zapcore.Level(l / 4))
+	return previous
+}
+
+// ParseLevel accepts debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+}
+
+func isDevelopment(env string) bool {
+	return env == "development" || env == "local"
+}
@@ -36,3 +36,8 @@ func (r *RabbitMQ) Close() error {
 func (r *RabbitMQ) GetChannel() *amqp.Channel {
 	return r.channel
 }
+
+// Connection exposes the underlying connection so callers can open dedicated channels
+func (r *RabbitMQ) Connection() *amqp.Connection {
+	return r.connection
+}
@@ -0,0 +1,278 @@
+package spiffeauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+// testCA issues SVIDs for one trust domain
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCA(t *testing.T, trustDomain string) *testCA {
+	t.Helper()
+	key := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: trustDomain},
+		URIs:                  []*url.URL{{Scheme: "spiffe", Host: trustDomain}},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+// svid returns the Workload API message carrying a new SVID for path in the CA's trust domain, with serial
+// as its serial number
+func (ca *testCA) svid(t *testing.T, path string, serial int64) *workload.X509SVID {
+	t.Helper()
+	id := &url.URL{Scheme: "spiffe", Host: ca.cert.Subject.CommonName, Path: path}
+	key := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		URIs:         []*url.URL{id},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &workload.X509SVID{SpiffeId: id.String(), X509Svid: der, X509SvidKey: keyDER, Bundle: ca.cert.Raw}
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// fakeWorkloadAPI streams the SVID it holds to every watcher, and again whenever rotate replaces it, as a
+// SPIFFE agent does
+type fakeWorkloadAPI struct {
+	workload.UnimplementedSpiffeWorkloadAPIServer
+	mu      sync.Mutex
+	svid    *workload.X509SVID
+	rotated chan struct{}
+}
+
+// startWorkloadAPI serves a fake Workload API on a unix socket and returns its address
+func startWorkloadAPI(t *testing.T, svid *workload.X509SVID) (*fakeWorkloadAPI, string) {
+	t.Helper()
+	api := &fakeWorkloadAPI{svid: svid, rotated: make(chan struct{})}
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	workload.RegisterSpiffeWorkloadAPIServer(server, api)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return api, "unix://" + socket
+}
+
+func (f *fakeWorkloadAPI) FetchX509SVID(_ *workload.X509SVIDRequest, stream grpc.ServerStreamingServer[workload.X509SVIDResponse]) error {
+	for {
+		f.mu.Lock()
+		svid, rotated := f.svid, f.rotated
+		f.mu.Unlock()
+		if err := stream.Send(&workload.X509SVIDResponse{Svids: []*workload.X509SVID{svid}}); err != nil {
+			return err
+		}
+		select {
+		case <-rotated:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (f *fakeWorkloadAPI) rotate(svid *workload.X509SVID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.svid = svid
+	close(f.rotated)
+	f.rotated = make(chan struct{})
+}
+
+func newTestSource(t *testing.T, addr string) *Source {
+	t.Helper()
+	source, err := NewSource(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { source.Close() })
+	return source
+}
+
+// startServer serves the health service with the source's server options. Each call reports the serial
+// number of the client certificate it came with on the returned channel.
+func startServer(t *testing.T, source *Source) (string, <-chan int64) {
+	t.Helper()
+	clientSerials := make(chan int64, 10)
+	recordPeer := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if p, ok := peer.FromContext(ctx); ok {
+			if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+				clientSerials <- info.State.PeerCertificates[0].SerialNumber.Int64()
+			}
+		}
+		return handler(ctx, req)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(append(source.ServerOptions(), grpc.UnaryInterceptor(recordPeer))...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String(), clientSerials
+}
+
+// check makes one health check over a new connection, so each call runs its own TLS handshake
+func check(t *testing.T, addr string, creds credentials.TransportCredentials) error {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestMTLSBetweenWorkloadsOfTheTrustDomain(t *testing.T) {
+	ca := newTestCA(t, "example.org")
+	_, userAPI := startWorkloadAPI(t, ca.svid(t, "/user-service", 10))
+	_, gatewayAPI := startWorkloadAPI(t, ca.svid(t, "/api-gateway", 20))
+
+	server := newTestSource(t, userAPI)
+	addr, clientSerials := startServer(t, server)
+	client := newTestSource(t, gatewayAPI)
+	if !server.IsEnabled() || !client.IsEnabled() {
+		t.Fatal("a source with a socket path is disabled")
+	}
+
+	if err := check(t, addr, client.ClientCredentials()); err != nil {
+		t.Fatalf("call with the gateway's SVID: %v", err)
+	}
+	if serial := <-clientSerials; serial != 20 {
+		t.Errorf("the server saw client certificate %d, want the gateway's 20", serial)
+	}
+}
+
+func TestMTLSRejectsOtherTrustDomains(t *testing.T) {
+	_, userAPI := startWorkloadAPI(t, newTestCA(t, "example.org").svid(t, "/user-service", 10))
+	_, intruderAPI := startWorkloadAPI(t, newTestCA(t, "intruder.org").svid(t, "/api-gateway", 20))
+
+	addr, _ := startServer(t, newTestSource(t, userAPI))
+	if err := check(t, addr, newTestSource(t, intruderAPI).ClientCredentials()); err == nil {
+		t.Fatal("a client of another trust domain was accepted")
+	}
+}
+
+func TestMTLSRejectsClientsWithoutSVID(t *testing.T) {
+	_, userAPI := startWorkloadAPI(t, newTestCA(t, "example.org").svid(t, "/user-service", 10))
+	addr, _ := startServer(t, newTestSource(t, userAPI))
+
+	if err := check(t, addr, insecure.NewCredentials()); err == nil {
+		t.Fatal("a plaintext client was accepted")
+	}
+}
+
+func TestMTLSUsesRotatedSVIDsWithoutRestart(t *testing.T) {
+	ca := newTestCA(t, "example.org")
+	_, userAPI := startWorkloadAPI(t, ca.svid(t, "/user-service", 10))
+	gatewayWorkloadAPI, gatewayAPI := startWorkloadAPI(t, ca.svid(t, "/api-gateway", 20))
+
+	addr, clientSerials := startServer(t, newTestSource(t, userAPI))
+	client := newTestSource(t, gatewayAPI)
+	creds := client.ClientCredentials()
+	if err := check(t, addr, creds); err != nil {
+		t.Fatal(err)
+	}
+	<-clientSerials
+
+	gatewayWorkloadAPI.rotate(ca.svid(t, "/api-gateway", 21))
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		svid, err := client.x509.GetX509SVID()
+		if err == nil && svid.Certificates[0].SerialNumber.Int64() == 21 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the source never picked up the rotated SVID")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The credentials were built before the rotation and still present the new certificate
+	if err := check(t, addr, creds); err != nil {
+		t.Fatal(err)
+	}
+	if serial := <-clientSerials; serial != 21 {
+		t.Errorf("the server saw client certificate %d after rotation, want 21", serial)
+	}
+}
+
+func TestDisabledSourceIsPlaintext(t *testing.T) {
+	source, err := NewSource(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source.IsEnabled() || source.ServerOptions() != nil || source.Close() != nil {
+		t.Fatal("a source without a socket path is enabled")
+	}
+
+	addr, _ := startServer(t, source)
+	if err := check(t, addr, source.ClientCredentials()); err != nil {
+		t.Fatalf("plaintext call: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
@@ -11,26 +12,50 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/gin-gonic/gin"
 	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	redisClient "github.com/kareemhamed001/e-commerce/pkg/redis"
+	"github.com/kareemhamed001/e-commerce/pkg/spiffeauth"
 	"github.com/kareemhamed001/e-commerce/services/ApiGateway/config"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/audit"
 	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/clients"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/grpcweb"
 	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/handlers"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
 	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/router"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/resolver/dns"
 )
 
+// @title E-Commerce API Gateway
+// @version 1.0
+// @description REST API of the e-commerce platform. The gateway authenticates requests and forwards them to the gRPC services.
+// @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and the JWT.
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key issued by an admin, accepted on permission-protected endpoints.
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		logger.InitGlobal("development", "logs/gateway/system.log")
-		logger.Errorf("Failed to load configuration: %v", err)
-		return
+		// Validate reports every invalid setting, one per line
+		logger.Errorf("Failed to load configuration:\n%v", err)
+		logger.Sync()
+		os.Exit(1)
 	}
 
 	// Initialize logger
 	logger.InitGlobal(cfg.AppEnv, "logs/gateway/system.log")
+	slog.SetDefault(logger.NewStructuredWithFormat(cfg.AppEnv, cfg.LogFormat))
 	logger.Info("event=startup component=api-gateway message=starting")
 	logger.Info("event=config_loaded component=api-gateway message=configuration loaded")
 
@@ -38,13 +63,33 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Initialize gRPC clients
+	spiffeSource, err := spiffeauth.NewSource(context.Background(), cfg.SPIFFEEndpointSocket)
+	if err != nil {
+		logger.Errorf("Failed to load SPIFFE identity: %v", err)
+		return
+	}
+	defer spiffeSource.Close()
+
+	// Initialize gRPC clients. The DNS setting is global and must be in place before the first dial.
+	dns.SetMinResolutionInterval(cfg.GRPCDNSMinResolutionInterval)
 	serviceClients, err := clients.NewServiceClients(
 		cfg.UserServiceURL,
 		cfg.ProductServiceURL,
 		cfg.CartServiceURL,
 		cfg.OrderServiceURL,
+		cfg.NotificationServiceURL,
 		cfg.InternalAuthToken,
+		cfg.ServiceName,
+		clients.ConnectionConfig{
+			LoadBalancePolicy: cfg.GRPCLoadBalancePolicy,
+			DialTimeout:       cfg.GRPCDialTimeout,
+			DialTimeouts:      cfg.GRPCDialTimeouts,
+			BackoffBaseDelay:  cfg.GRPCBackoffBaseDelay,
+			BackoffMaxDelay:   cfg.GRPCBackoffMaxDelay,
+			KeepaliveTime:     cfg.GRPCKeepaliveTime,
+			KeepaliveTimeout:  cfg.GRPCKeepaliveTimeout,
+			Credentials:       spiffeSource.ClientCredentials(),
+		},
 		grpcmiddleware.CircuitBreakerConfig{
 			Enabled:      cfg.CircuitBreakerEnabled,
 			MaxRequests:  cfg.CircuitBreakerMaxRequests,
@@ -67,16 +112,108 @@ func main() {
 	}
 	defer closeClients()
 
+	// In development the services are often still starting; waiting avoids a burst of failed first calls
+	if cfg.AppEnv == "development" {
+		waitCtx, waitCancel := context.WithTimeout(context.Background(), cfg.GRPCDialTimeout)
+		if err := serviceClients.WaitForReady(waitCtx); err != nil {
+			logger.Warnf("event=grpc_clients_not_ready component=grpc_clients error=%v", err)
+		}
+		waitCancel()
+	}
+
+	// Redis is only a response cache here, so the gateway keeps serving without it
+	cacheClient, err := redisClient.NewClientFromSettings(&redisClient.Settings{
+		RedisEnabled:  cfg.RedisEnabled,
+		RedisHost:     cfg.RedisHost,
+		RedisPort:     cfg.RedisPort,
+		RedisPassword: cfg.RedisPassword,
+		RedisDB:       cfg.RedisDB,
+	})
+	if err != nil {
+		logger.Warnf("event=cache_disabled component=redis error=%v", err)
+		cacheClient, _ = redisClient.NewClientFromSettings(&redisClient.Settings{RedisEnabled: false})
+	}
+	defer cacheClient.Close()
+
+	var auditLog audit.Sink = audit.NewWriter(os.Stdout)
+	if cfg.AuditLogPath != "-" {
+		auditLog, err = audit.NewLog(cfg.AuditLogPath)
+		if err != nil {
+			logger.Errorf("Failed to initialize audit log: %v", err)
+			return
+		}
+	}
+	revoker := middleware.NewTokenRevoker(cacheClient, cfg.JWTDuration)
+	maintenance := middleware.NewRedisMaintenanceStore(cacheClient)
+	connections := middleware.NewConnectionTracker()
+	features := middleware.NewRedisFeatureFlagStore(cacheClient, middleware.NewStaticFeatureFlagStore(cfg.FeatureFlags))
+	dedup := middleware.NewRedisDeduplicationStore(cacheClient)
+	// CORS, the block list, the rate limit, the timeouts and the exchange rates follow config reloads, from SIGHUP
+	// or the admin API
+	corsPolicy := middleware.NewCORSPolicy(cfg.CORS())
+	blockList := middleware.NewBlockListPolicy(cfg.BlockList())
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	timeouts := middleware.NewTimeoutPolicy(cfg.Timeouts())
+	currencies := middleware.NewCurrencyPolicy(cfg.Currencies())
+	reloader := config.NewReloader(cfg, corsPolicy, blockList, rateLimiter, timeouts, currencies)
+
+	guestCarts := middleware.NewGuestCarts(cfg.GuestCartSecret, cfg.GuestCartTTL, cfg.TLSEnabled)
+	modificationClock := handlers.NewModificationClock(cacheClient, 24*time.Hour)
+
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(serviceClients.UserClient)
-	productHandler := handlers.NewProductHandler(serviceClients.ProductClient)
-	cartHandler := handlers.NewCartHandler(serviceClients.CartClient)
-	orderHandler := handlers.NewOrderHandler(serviceClients.OrderClient)
+	handlers.SetStrictDecoding(cfg.StrictJSONDecoding)
+	userHandler := handlers.NewUserHandler(serviceClients.UserClient, serviceClients.OrderClient, serviceClients.CartClient, serviceClients.WishlistClient, revoker, auditLog, guestCarts, modificationClock)
+	productHandler := handlers.NewProductHandler(serviceClients.ProductClient, newImagePresigner(cfg), cfg.S3Bucket, cfg.S3Region, cfg.ProductBatchMaxIDs, modificationClock)
+	cartHandler := handlers.NewCartHandler(serviceClients.CartClient, guestCarts)
+	wishlistHandler := handlers.NewWishlistHandler(serviceClients.WishlistClient)
+	reviewHandler := handlers.NewReviewHandler(serviceClients.ReviewClient, serviceClients.OrderClient)
+	orderHandler := handlers.NewOrderHandler(serviceClients.OrderClient, serviceClients.ShippingClient, serviceClients.UserClient)
+	reportHandler := handlers.NewReportHandler(serviceClients.OrderClient, cacheClient)
+	adminHandler := handlers.NewAdminHandler(maintenance, serviceClients, reloader)
+	apiKeys := middleware.NewAPIKeyResolver(serviceClients.UserClient, cfg.APIKeyCacheTTL)
+	apiKeyHandler := handlers.NewAPIKeyHandler(serviceClients.UserClient, apiKeys, auditLog)
+	notificationHandler := handlers.NewNotificationHandler(serviceClients.NotificationClient)
+	graphqlHandler := handlers.NewGraphQLHandler(serviceClients.UserClient, serviceClients.ProductClient, serviceClients.CartClient, serviceClients.OrderClient)
+	var grpcWebProxy *grpcweb.Proxy
+	if cfg.GRPCWebEnabled {
+		grpcWebProxy = grpcweb.NewProxy(serviceClients.Conns, grpcweb.ExposedMethods, corsPolicy.Allows)
+	}
 
 	routerEngine := gin.Default()
+	// X-Forwarded-For and X-Real-IP are only believed from these proxies; gin would otherwise trust every peer
+	if err := routerEngine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Errorf("Failed to set trusted proxies: %v", err)
+		return
+	}
 
 	// Initialize router
-	apiRouter := router.NewRouter(routerEngine, cfg, userHandler, productHandler, cartHandler, orderHandler)
+	apiRouter := router.NewRouter(routerEngine, cfg, router.Deps{
+		UserHandler:         userHandler,
+		ProductHandler:      productHandler,
+		CartHandler:         cartHandler,
+		WishlistHandler:     wishlistHandler,
+		ReviewHandler:       reviewHandler,
+		OrderHandler:        orderHandler,
+		ReportHandler:       reportHandler,
+		AdminHandler:        adminHandler,
+		APIKeyHandler:       apiKeyHandler,
+		NotificationHandler: notificationHandler,
+		GraphQLHandler:      graphqlHandler,
+		GRPCWebProxy:        grpcWebProxy,
+		Revoker:             revoker,
+		APIKeys:             apiKeys,
+		AuditLog:            auditLog,
+		Maintenance:         maintenance,
+		Connections:         connections,
+		Services:            serviceClients,
+		Features:            features,
+		Dedup:               dedup,
+		CORS:                corsPolicy,
+		BlockList:           blockList,
+		Timeouts:            timeouts,
+		RateLimiter:         rateLimiter,
+		Currencies:          currencies,
+	})
 
 	baseCtx, baseCancel := context.WithCancel(context.Background())
 	defer baseCancel()
@@ -92,17 +229,21 @@ func main() {
 		BaseContext: func(_ net.Listener) context.Context {
 			return baseCtx
 		},
+		ConnState: connections.ConnState,
 	}
-	server.RegisterOnShutdown(func() {
-		closeClients()
-	})
-
 	serverErr := make(chan error, 1)
 
 	// Start server in a goroutine
 	go func() {
-		logger.Infof("event=server_start component=http_server addr=:%s", cfg.AppPort)
-		if err := server.ListenAndServe(); err != nil {
+		logger.Infof("event=server_start component=http_server addr=:%s tls=%t", cfg.AppPort, cfg.TLSEnabled)
+		var err error
+		if cfg.TLSEnabled {
+			// Clients that offer h2 through ALPN get HTTP/2, others HTTP/1.1
+			err = server.ListenAndServeTLS(cfg.TLSCertPath, cfg.TLSKeyPath)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil {
 			if errors.Is(err, http.ErrServerClosed) {
 				serverErr <- nil
 				return
@@ -113,6 +254,33 @@ func main() {
 		serverErr <- nil
 	}()
 
+	// Metrics get a listener of their own, so the public port never serves them
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("GET /metrics", promhttp.Handler())
+	metricsServer := &http.Server{
+		Addr:              ":" + cfg.MetricsPort,
+		Handler:           metricsMux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		logger.Infof("event=server_start component=metrics_server addr=:%s", cfg.MetricsPort)
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("event=server_error component=metrics_server error=%v", err)
+		}
+	}()
+
+	reloadCh := make(chan os.Signal, 1)
+	signal.Notify(reloadCh, syscall.SIGHUP)
+	defer signal.Stop(reloadCh)
+	go func() {
+		for range reloadCh {
+			logger.Info("event=config_reload_start trigger=signal")
+			if _, _, err := reloader.Reload(); err != nil {
+				logger.Warnf("event=config_reload_failed trigger=signal error=%v", err)
+			}
+		}
+	}()
+
 	// Wait for interrupt signal or server error for graceful shutdown
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -129,6 +297,12 @@ func main() {
 		return
 	}
 
+	// Fail readiness first and keep serving while load balancers notice, so no new traffic is still
+	// routed here once the server stops accepting it
+	logger.Infof("event=shutdown_step component=http_server action=fail_readiness delay=%s", cfg.ShutdownDrainDelay)
+	connections.BeginShutdown()
+	time.Sleep(cfg.ShutdownDrainDelay)
+
 	// Graceful shutdown with timeout
 	shutdownTimeout := 30 * time.Second
 	logger.Infof("event=shutdown_timeout component=http_server timeout=%s", shutdownTimeout)
@@ -138,6 +312,15 @@ func main() {
 	// Stop accepting new connections immediately
 	logger.Info("event=shutdown_step component=http_server action=disable_keepalives")
 	server.SetKeepAlivesEnabled(false)
+
+	// Streams are drained before the base context is canceled, as canceling it ends them without a final frame
+	logger.Infof("event=shutdown_step component=http_server action=drain_streams streams=%d connections=%d", connections.ActiveStreams(), connections.OpenConnections())
+	drainCtx, drainCancel := context.WithTimeout(shutdownCtx, cfg.StreamDrainPeriod+cfg.StreamFlushWindow)
+	if remaining := connections.Drain(drainCtx, cfg.StreamDrainPeriod); remaining > 0 {
+		logger.Warnf("event=shutdown_streams_cut component=http_server streams=%d", remaining)
+	}
+	drainCancel()
+
 	logger.Info("event=shutdown_step component=http_server action=cancel_base_context")
 	baseCancel()
 	logger.Info("event=shutdown_step component=http_server action=shutdown")
@@ -146,6 +329,12 @@ func main() {
 		logger.Errorf("event=shutdown_error component=http_server error=%v", err)
 	}
 
+	// Metrics stay scrapeable until the API itself has stopped
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		logger.Errorf("event=shutdown_error component=metrics_server error=%v", err)
+	}
+
+	// Only now that no handler is running can the gRPC connections they call through be closed
 	closeClients()
 
 	// Ensure the server goroutine has completed
@@ -155,3 +344,17 @@ func main() {
 
 	logger.Info("event=shutdown_complete component=api-gateway")
 }
+
+// newImagePresigner signs product image uploads, or returns nil when S3_BUCKET is unset
+func newImagePresigner(cfg *config.Config) handlers.S3PresignClient {
+	if cfg.S3Bucket == "" {
+		logger.Warn("event=image_uploads_disabled component=s3 reason=S3_BUCKET is not set")
+		return nil
+	}
+
+	client := s3.New(s3.Options{
+		Region:      cfg.S3Region,
+		Credentials: credentials.NewStaticCredentialsProvider(cfg.AWSAccessKeyID, cfg.AWSSecretAccessKey, ""),
+	})
+	return s3.NewPresignClient(client)
+}
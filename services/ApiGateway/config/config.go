@@ -1,202 +1,565 @@
 package config
 
 import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"net"
+	"net/netip"
 	"os"
-	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/joho/godotenv"
+	envconfig "github.com/kareemhamed001/e-commerce/pkg/config"
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	"golang.org/x/text/currency"
+	"google.golang.org/grpc/balancer/pickfirst"
+	"google.golang.org/grpc/balancer/roundrobin"
 )
 
+// defaultJWTSecret is the JWT_SECRET placeholder for local runs, the default tag of Config.JWTSecret.
+// Validate rejects it outside development.
+const defaultJWTSecret = "your-secret-key-change-in-production"
+
+// defaultGuestCartSecret is the GUEST_CART_SECRET placeholder for local runs; Validate rejects it outside
+// development
+const defaultGuestCartSecret = "your-guest-cart-secret-change-in-production"
+
+// maxProductBatchIDs is the most products the product service's GetProductsByIDs returns in one call
+const maxProductBatchIDs = 100
+
+// DefaultFeatureFlags keeps gated features that shipped before their flag on until configured otherwise
+var DefaultFeatureFlags = map[string]middleware.FeatureFlag{
+	"wishlist": {Enabled: true},
+}
+
 type Config struct {
 	// Server
-	AppPort string
-	AppEnv  string
+	AppPort   string `env:"APP_PORT" default:"8080"`
+	AppEnv    string `env:"APP_ENV" default:"development"`
+	LogFormat string `env:"LOG_FORMAT"`
+	// TLSEnabled serves HTTPS, with HTTP/2, on AppPort using the certificate and key at TLSCertPath and
+	// TLSKeyPath; off serves plain HTTP, for local runs or behind a proxy that terminates TLS
+	TLSEnabled  bool   `env:"TLS_ENABLED" default:"false"`
+	TLSCertPath string `env:"TLS_CERT_PATH"`
+	TLSKeyPath  string `env:"TLS_KEY_PATH"`
+	// MetricsPort serves GET /metrics on a listener of its own, kept off the public port and away from the
+	// ingress
+	MetricsPort string `env:"METRICS_PORT" default:"9090"`
 
 	// JWT
-	JWTSecret string
+	JWTSecret string `env:"JWT_SECRET" default:"your-secret-key-change-in-production"`
+	// JWTKeys verify tokens: JWT_SECRET_FILES, else JWT_SECRETS, else JWT_SECRET alone
+	JWTKeys []customJWT.Key
+	// JWTAlgorithm is JWT_ALG, falling back to JWT_ALGORITHM: HS256 verifies with JWTKeys, RS256 or EdDSA with
+	// JWTKeySet
+	JWTAlgorithm string
+	// JWTKeySet holds the public keys from JWT_PUBLIC_KEY_FILES, else JWT_JWKS_URL; nil under HS256
+	JWTKeySet customJWT.KeySet
+	// JWTDuration is JWT_TTL, falling back to JWT_EXPIRY and JWT_DURATION_HOURS. It must match the user
+	// service so revocations outlive every token they cover.
+	JWTDuration time.Duration
+	// JWTIssuer and JWTAudience are the iss and aud claims tokens must carry, matching the user service's
+	// JWT_ISSUER and JWT_AUDIENCE. JWTAudience defaults to ServiceName.
+	JWTIssuer   string `env:"JWT_ISSUER" default:"user-service"`
+	JWTAudience string
+	// JWTLeeway is the clock skew tolerated on exp, nbf and iat
+	JWTLeeway time.Duration
+	// RolePermissions maps a role to the permissions RequirePermission checks
+	RolePermissions map[string][]string
+	// APIKeyCacheTTL is how long a verified API key is trusted without asking the user service again, so also
+	// how long a key revoked through another gateway instance keeps working on this one
+	APIKeyCacheTTL time.Duration `env:"API_KEY_CACHE_TTL" default:"1m"`
+	// AdminRequireTwoFactor limits admin routes to tokens issued after a two-factor verification
+	AdminRequireTwoFactor bool `env:"ADMIN_REQUIRE_2FA" default:"false"`
+
+	// FeatureFlags are FEATURE_FLAGS_JSON over DefaultFeatureFlags; the feature_flags hash in Redis overrides them
+	FeatureFlags map[string]middleware.FeatureFlag
+
+	// PanicReporter, when set, receives every panic recovered from a handler. It is wired in code, e.g. to
+	// Sentry, rather than loaded from the environment; nil only logs and counts panics.
+	PanicReporter middleware.PanicReporter
 
 	// CORS
-	AllowedOrigins []string
-	AllowedMethods []string
-	AllowedHeaders []string
+	AllowedOrigins []string `env:"ALLOWED_ORIGINS" default:"*"`
+	// AllowedOriginPatterns are regular expressions an origin must match in full, for origins that cannot all
+	// be listed, such as a subdomain per tenant
+	AllowedOriginPatterns []string `env:"ALLOWED_ORIGIN_PATTERNS"`
+	AllowedMethods        []string `env:"ALLOWED_METHODS" default:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
+	AllowedHeaders        []string `env:"ALLOWED_HEADERS" default:"Accept,Authorization,Content-Type,X-Request-ID,X-Guest-Cart-Token,X-Currency"`
+	// AllowCredentials lets browsers send cookies on cross-origin requests. It cannot be combined with "*" in
+	// AllowedOrigins.
+	AllowCredentials bool `env:"ALLOW_CREDENTIALS" default:"false"`
+	// Origins is compiled from AllowedOrigins and AllowedOriginPatterns, for the REST API and gRPC-Web alike
+	Origins *middleware.OriginMatcher
+
+	// Block list: client IP ranges and path prefixes refused with 403, e.g. to stop abuse without a redeploy
+	BlockedCIDRs        []string `env:"BLOCKED_CIDRS"`
+	BlockedPathPrefixes []string `env:"BLOCKED_PATH_PREFIXES"`
+	// BlockedNetworks is parsed from BlockedCIDRs
+	BlockedNetworks []netip.Prefix
+
+	// TrustedProxies are the addresses or CIDRs of the proxies in front of the gateway, such as a load balancer
+	// or ingress. The client IP is read from X-Forwarded-For or X-Real-IP only on requests arriving from one of
+	// them, walking past every trusted hop; empty trusts none, so the headers are ignored and anyone sending
+	// them cannot pick the IP they are rate limited, blocked and audited as.
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
 
 	// Rate Limiting
-	RateLimitRequests int
-	RateLimitWindow   time.Duration
+	RateLimitRequests int           `env:"RATE_LIMIT_REQUESTS" default:"100"`
+	RateLimitWindow   time.Duration `env:"RATE_LIMIT_WINDOW_SECONDS" default:"60" unit:"s"`
+
+	// DedupTTL is how long a POST /api/v1/orders response answers identical repeats; 0 disables it
+	DedupTTL time.Duration `env:"DEDUP_TTL" default:"30s"`
 
 	// Service URLs
-	UserServiceURL    string
-	ProductServiceURL string
-	CartServiceURL    string
-	OrderServiceURL   string
+	UserServiceURL    string `env:"USER_SERVICE_URL" default:"localhost:50051"`
+	ProductServiceURL string `env:"PRODUCT_SERVICE_URL" default:"localhost:50052"`
+	CartServiceURL    string `env:"CART_SERVICE_URL" default:"localhost:50053"`
+	OrderServiceURL   string `env:"ORDER_SERVICE_URL" default:"localhost:50054"`
+	// NotificationServiceURL serves notification history and preferences
+	NotificationServiceURL string `env:"NOTIFICATION_SERVICE_URL" default:"localhost:50059"`
+	// GRPCWebEnabled serves grpcweb.ExposedMethods to browsers under /grpc/
+	GRPCWebEnabled bool `env:"GRPC_WEB_ENABLED" default:"false"`
+	// GRPCLoadBalancePolicy is pick_first or round_robin. round_robin only spreads calls when a service
+	// URL resolves to several addresses, such as a Kubernetes headless service.
+	GRPCLoadBalancePolicy string `env:"GRPC_LOAD_BALANCE_POLICY" default:"pick_first"`
+	// GRPCDNSMinResolutionInterval is the least time between two DNS lookups of a service URL
+	GRPCDNSMinResolutionInterval time.Duration `env:"GRPC_DNS_MIN_RESOLUTION_INTERVAL" default:"30s"`
+	// GRPCDialTimeout bounds each connection attempt to a service; GRPCDialTimeouts overrides it per
+	// service, keyed by user, product, cart, order or notification
+	GRPCDialTimeout  time.Duration `env:"GRPC_DIAL_TIMEOUT" default:"20s"`
+	GRPCDialTimeouts map[string]time.Duration
+	// GRPCBackoffBaseDelay and GRPCBackoffMaxDelay space out reconnection attempts to an unreachable service
+	GRPCBackoffBaseDelay time.Duration `env:"GRPC_BACKOFF_BASE_DELAY" default:"1s"`
+	GRPCBackoffMaxDelay  time.Duration `env:"GRPC_BACKOFF_MAX_DELAY" default:"30s"`
+	// GRPCKeepaliveTime pings service connections idle for that long, 0 disables pings.
+	// GRPCKeepaliveTimeout is how long a ping may go unanswered before the connection is closed.
+	GRPCKeepaliveTime    time.Duration `env:"GRPC_KEEPALIVE_TIME" default:"30s"`
+	GRPCKeepaliveTimeout time.Duration `env:"GRPC_KEEPALIVE_TIMEOUT" default:"10s"`
 
 	// Timeouts
-	RequestTimeout time.Duration
-	IdleTimeout    time.Duration
-	ReadTimeout    time.Duration
-	WriteTimeout   time.Duration
+	RequestTimeout time.Duration `env:"REQUEST_TIMEOUT_SECONDS" default:"30" unit:"s"`
+	IdleTimeout    time.Duration `env:"IDLE_TIMEOUT_SECONDS" default:"120" unit:"s"`
+	ReadTimeout    time.Duration `env:"READ_TIMEOUT_SECONDS" default:"15" unit:"s"`
+	WriteTimeout   time.Duration `env:"WRITE_TIMEOUT_SECONDS" default:"15" unit:"s"`
+	// RouteTimeouts overrides RequestTimeout for routes keyed as "METHOD /path"
+	RouteTimeouts map[string]time.Duration
+	// StreamDrainPeriod is how long shutdown lets SSE and WebSocket streams end on their own,
+	// and StreamFlushWindow how long they then get to send a final frame once told to close
+	StreamDrainPeriod time.Duration `env:"STREAM_DRAIN_PERIOD" default:"10s"`
+	StreamFlushWindow time.Duration `env:"STREAM_FLUSH_WINDOW" default:"5s"`
+	// ShutdownDrainDelay is how long shutdown keeps serving with readiness failed, so load balancers
+	// deregister the instance before it stops accepting requests
+	ShutdownDrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" default:"0s"`
 
 	// Service name
-	ServiceName string
+	ServiceName string `env:"SERVICE_NAME" default:"api-gateway"`
 
 	// Internal service auth
-	InternalAuthToken string
+	InternalAuthToken string `env:"INTERNAL_AUTH_TOKEN"`
+	// SPIFFEEndpointSocket is the SPIFFE Workload API address; when set, gRPC connections use mTLS with its SVID
+	SPIFFEEndpointSocket string `env:"SPIFFE_ENDPOINT_SOCKET"`
+
+	// Audit; AUDIT_LOG_PATH=- writes the audit log to stdout instead of a file
+	AuditLogPath string `env:"AUDIT_LOG_PATH" default:"logs/gateway/audit.log"`
+	// AuditRedactFields are JSON keys, matched case-insensitively at any depth, hidden in audited request bodies
+	AuditRedactFields []string `env:"AUDIT_REDACT_FIELDS" default:"password,current_password,new_password,token,access_token,refresh_token,challenge_token,authorization,secret,code"`
+
+	// StrictJSONDecoding answers 400 to request bodies with fields the endpoint doesn't know, instead of
+	// ignoring them
+	StrictJSONDecoding bool `env:"STRICT_JSON_DECODING" default:"false"`
+
+	// Body logging (debugging only)
+	BodyLogEnabled      bool     `env:"BODY_LOG_ENABLED" default:"false"`
+	BodyLogPaths        []string `env:"BODY_LOG_PATHS"`
+	BodyLogMaxBytes     int      `env:"BODY_LOG_MAX_BYTES" default:"4096"`
+	BodyLogRedactFields []string `env:"BODY_LOG_REDACT_FIELDS" default:"password,token,access_token,refresh_token,authorization,secret"`
+
+	// Profiling
+	EnablePprof bool   `env:"ENABLE_PPROF" default:"false"`
+	PprofToken  string `env:"PPROF_TOKEN"`
+
+	// EnableSwagger serves the generated API docs under /swagger/; on by default outside production
+	EnableSwagger bool
+
+	// Redis (response cache)
+	RedisEnabled  bool   `env:"REDIS_ENABLED" default:"true"`
+	RedisHost     string `env:"REDIS_HOST" default:"localhost"`
+	RedisPort     string `env:"REDIS_PORT" default:"6379"`
+	RedisPassword string `env:"REDIS_PASSWORD"`
+	RedisDB       int    `env:"REDIS_DB" default:"0"`
+
+	// ProductBatchMaxIDs caps the distinct IDs of one batch product lookup. It can only be lowered, as the
+	// product service fetches at most maxProductBatchIDs in one call.
+	ProductBatchMaxIDs int `env:"PRODUCT_BATCH_MAX_IDS" default:"100"`
+
+	// BaseCurrency is the ISO 4217 currency of the catalog's prices. CurrencyRates, from CURRENCY_RATES_JSON,
+	// maps the other currencies product prices can be shown in to the amount of each one unit of BaseCurrency buys.
+	BaseCurrency  string `env:"BASE_CURRENCY" default:"USD"`
+	CurrencyRates map[string]float64
+
+	// GuestCartSecret signs the tokens naming anonymous visitors' carts
+	GuestCartSecret string `env:"GUEST_CART_SECRET" default:"your-guest-cart-secret-change-in-production"`
+	// GuestCartTTL is how long the guest cart cookie lasts, matching the cart service's GUEST_CART_TTL_HOURS
+	GuestCartTTL time.Duration `env:"GUEST_CART_TTL" default:"168h"`
+
+	// Product image storage (S3)
+	S3Bucket           string `env:"S3_BUCKET"`
+	S3Region           string `env:"S3_REGION" default:"us-east-1"`
+	AWSAccessKeyID     string `env:"AWS_ACCESS_KEY_ID"`
+	AWSSecretAccessKey string `env:"AWS_SECRET_ACCESS_KEY"`
 
 	// Circuit breaker
-	CircuitBreakerEnabled      bool
-	CircuitBreakerMaxRequests  uint32
-	CircuitBreakerInterval     time.Duration
-	CircuitBreakerTimeout      time.Duration
-	CircuitBreakerFailureRatio float64
-	CircuitBreakerMinRequests  uint32
+	CircuitBreakerEnabled      bool          `env:"CB_ENABLED" default:"true"`
+	CircuitBreakerMaxRequests  uint32        `env:"CB_MAX_REQUESTS" default:"5"`
+	CircuitBreakerInterval     time.Duration `env:"CB_INTERVAL_SECONDS" default:"60" unit:"s"`
+	CircuitBreakerTimeout      time.Duration `env:"CB_TIMEOUT_SECONDS" default:"20" unit:"s"`
+	CircuitBreakerFailureRatio float64       `env:"CB_FAILURE_RATIO" default:"0.6"`
+	CircuitBreakerMinRequests  uint32        `env:"CB_MIN_REQUESTS" default:"20"`
+}
+
+// untaggedKeys are the variables Load reads below rather than through env tags
+var untaggedKeys = []string{
+	"ENABLE_SWAGGER",
+	"JWT_TTL", "JWT_EXPIRY", "JWT_DURATION_HOURS",
+	"JWT_ALG", "JWT_ALGORITHM",
+	"JWT_AUDIENCE", "JWT_LEEWAY",
+	"JWT_SECRET_FILES", "JWT_SECRETS", "JWT_PUBLIC_KEY_FILES", "JWT_JWKS_URL",
+	"ROLE_PERMISSIONS_JSON", "FEATURE_FLAGS_JSON", "ROUTE_TIMEOUTS_JSON", "GRPC_DIAL_TIMEOUTS_JSON",
+	"CURRENCY_RATES_JSON",
 }
 
 func Load() (*Config, error) {
-	// Try multiple paths for .env file
-	envPaths := []string{
-		filepath.Join("services/ApiGateway/config/.env"),
-		filepath.Join("config/.env"),
-		filepath.Join("./.env"),
+	if err := envconfig.LoadDotEnv("services/ApiGateway/config/.env", "config/.env", "./.env"); err != nil {
+		return nil, err
 	}
 
-	var err error
-	for _, envPath := range envPaths {
-		err = godotenv.Load(envPath)
-		if err == nil {
-			logger.Infof("loaded .env file from: %s", envPath)
-			break
-		}
+	cfg := &Config{}
+	if err := envconfig.CheckFileKeys(cfg, untaggedKeys...); err != nil {
+		return nil, err
 	}
 
+	// Settings with a fixed default come from the env tags; the rest are read below
+	if err := envconfig.Load(cfg); err != nil {
+		return nil, err
+	}
+
+	var err error
+	cfg.EnableSwagger, err = envconfig.Bool("ENABLE_SWAGGER", cfg.AppEnv != "production")
 	if err != nil {
-		logger.Warnf("could not load .env file from any path: %v", err)
+		return nil, err
+	}
+	cfg.Origins = middleware.NewOriginMatcher(cfg.AllowedOrigins, cfg.AllowedOriginPatterns)
+	cfg.BlockedNetworks, err = middleware.ParseNetworks(cfg.BlockedCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("BLOCKED_CIDRS: %w", err)
 	}
 
-	cfg := &Config{
-		// Server
-		AppPort: GetEnv("APP_PORT", "8080"),
-		AppEnv:  GetEnv("APP_ENV", "development"),
+	// JWT_EXPIRY and JWT_DURATION_HOURS are the older names of JWT_TTL
+	ttlKey := "JWT_TTL"
+	if os.Getenv(ttlKey) == "" {
+		ttlKey = "JWT_EXPIRY"
+	}
+	ttlHours, err := envconfig.Int("JWT_DURATION_HOURS", 24)
+	if err != nil {
+		return nil, err
+	}
+	cfg.JWTDuration, err = envconfig.Duration(ttlKey, time.Duration(ttlHours)*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.JWTAudience = envconfig.String("JWT_AUDIENCE", cfg.ServiceName)
 
-		// JWT
-		JWTSecret: GetEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+	cfg.JWTKeys, err = customJWT.ResolveKeys(os.Getenv("JWT_SECRET_FILES"), os.Getenv("JWT_SECRETS"), cfg.JWTSecret)
+	if err != nil {
+		return nil, fmt.Errorf("JWT keys: %w", err)
+	}
 
-		// CORS
-		AllowedOrigins: getEnvArray("ALLOWED_ORIGINS", []string{"*"}),
-		AllowedMethods: getEnvArray("ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
-		AllowedHeaders: getEnvArray("ALLOWED_HEADERS", []string{"Accept", "Authorization", "Content-Type", "X-Request-ID"}),
+	// JWT_ALGORITHM is the older name of JWT_ALG
+	cfg.JWTAlgorithm = envconfig.String("JWT_ALG", envconfig.String("JWT_ALGORITHM", customJWT.AlgorithmHS256))
+	cfg.JWTKeySet, err = customJWT.ResolveKeySet(cfg.JWTAlgorithm, os.Getenv("JWT_PUBLIC_KEY_FILES"), os.Getenv("JWT_JWKS_URL"))
+	if err != nil {
+		return nil, fmt.Errorf("JWT_ALG: %w", err)
+	}
 
-		// Rate Limiting
-		RateLimitRequests: getEnvInt("RATE_LIMIT_REQUESTS", 100),
-		RateLimitWindow:   time.Duration(getEnvInt("RATE_LIMIT_WINDOW_SECONDS", 60)) * time.Second,
+	cfg.JWTLeeway, err = envconfig.Duration("JWT_LEEWAY", customJWT.DefaultLeeway)
+	if err != nil {
+		return nil, err
+	}
 
-		// Service URLs
-		UserServiceURL:    GetEnv("USER_SERVICE_URL", "localhost:50051"),
-		ProductServiceURL: GetEnv("PRODUCT_SERVICE_URL", "localhost:50052"),
-		CartServiceURL:    GetEnv("CART_SERVICE_URL", "localhost:50053"),
-		OrderServiceURL:   GetEnv("ORDER_SERVICE_URL", "localhost:50054"),
+	cfg.RolePermissions, err = customJWT.ParseRolePermissions(os.Getenv("ROLE_PERMISSIONS_JSON"))
+	if err != nil {
+		return nil, fmt.Errorf("ROLE_PERMISSIONS_JSON: %w", err)
+	}
 
-		// Timeouts
-		RequestTimeout: time.Duration(getEnvInt("REQUEST_TIMEOUT_SECONDS", 30)) * time.Second,
-		IdleTimeout:    time.Duration(getEnvInt("IDLE_TIMEOUT_SECONDS", 120)) * time.Second,
-		ReadTimeout:    time.Duration(getEnvInt("READ_TIMEOUT_SECONDS", 15)) * time.Second,
-		WriteTimeout:   time.Duration(getEnvInt("WRITE_TIMEOUT_SECONDS", 15)) * time.Second,
+	featureFlags, err := middleware.ParseFeatureFlags(os.Getenv("FEATURE_FLAGS_JSON"))
+	if err != nil {
+		return nil, fmt.Errorf("FEATURE_FLAGS_JSON: %w", err)
+	}
+	cfg.FeatureFlags = make(map[string]middleware.FeatureFlag, len(DefaultFeatureFlags)+len(featureFlags))
+	maps.Copy(cfg.FeatureFlags, DefaultFeatureFlags)
+	maps.Copy(cfg.FeatureFlags, featureFlags)
 
-		// Service
-		ServiceName: GetEnv("SERVICE_NAME", "api-gateway"),
+	cfg.RouteTimeouts, err = getEnvDurationMap("ROUTE_TIMEOUTS_JSON")
+	if err != nil {
+		return nil, err
+	}
 
-		// Internal service auth
-		InternalAuthToken: GetEnv("INTERNAL_AUTH_TOKEN", ""),
+	cfg.CurrencyRates, err = middleware.ParseCurrencyRates(os.Getenv("CURRENCY_RATES_JSON"))
+	if err != nil {
+		return nil, fmt.Errorf("CURRENCY_RATES_JSON: %w", err)
+	}
 
-		// Circuit breaker
-		CircuitBreakerEnabled:      getEnvBool("CB_ENABLED", true),
-		CircuitBreakerMaxRequests:  uint32(getEnvInt("CB_MAX_REQUESTS", 5)),
-		CircuitBreakerInterval:     time.Duration(getEnvInt("CB_INTERVAL_SECONDS", 60)) * time.Second,
-		CircuitBreakerTimeout:      time.Duration(getEnvInt("CB_TIMEOUT_SECONDS", 20)) * time.Second,
-		CircuitBreakerFailureRatio: getEnvFloat("CB_FAILURE_RATIO", 0.6),
-		CircuitBreakerMinRequests:  uint32(getEnvInt("CB_MIN_REQUESTS", 20)),
+	if err := loadGRPCConnectionSettings(cfg); err != nil {
+		return nil, err
 	}
 
-	if cfg.InternalAuthToken == "" {
-		return nil, fmt.Errorf("INTERNAL_AUTH_TOKEN is required")
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
 	return cfg, nil
 }
 
-func GetEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// Validate checks the loaded settings and reports every problem at once, so a broken deployment is fixed
+// in one pass rather than one restart per mistake
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.InternalAuthToken == "" {
+		errs = append(errs, fmt.Errorf("INTERNAL_AUTH_TOKEN is required"))
 	}
-	return value
+
+	if c.MetricsPort == c.AppPort {
+		errs = append(errs, fmt.Errorf("METRICS_PORT must differ from APP_PORT, so metrics stay off the public listener"))
+	}
+
+	// The key pair is loaded here so a bad path or mismatched key stops startup rather than every handshake
+	if c.TLSEnabled {
+		if c.TLSCertPath == "" || c.TLSKeyPath == "" {
+			errs = append(errs, fmt.Errorf("TLS_CERT_PATH and TLS_KEY_PATH are required when TLS_ENABLED is true"))
+		} else if _, err := tls.LoadX509KeyPair(c.TLSCertPath, c.TLSKeyPath); err != nil {
+			errs = append(errs, fmt.Errorf("TLS_CERT_PATH and TLS_KEY_PATH: %w", err))
+		}
+	}
+
+	if c.S3Bucket != "" && (c.AWSAccessKeyID == "" || c.AWSSecretAccessKey == "") {
+		errs = append(errs, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required when S3_BUCKET is set"))
+	}
+
+	// The default secret is public, so anyone could mint tokens the gateway accepts
+	if c.AppEnv != "development" && c.JWTAlgorithm == customJWT.AlgorithmHS256 {
+		for _, key := range c.JWTKeys {
+			if key.Secret == defaultJWTSecret {
+				errs = append(errs, fmt.Errorf("JWT keys must not use the default JWT_SECRET outside development"))
+				break
+			}
+		}
+	}
+
+	// Anyone could sign guest cart tokens with the default secret and reach other visitors' carts
+	if c.AppEnv != "development" && c.GuestCartSecret == defaultGuestCartSecret {
+		errs = append(errs, fmt.Errorf("GUEST_CART_SECRET must be set outside development"))
+	}
+	if c.GuestCartTTL <= 0 {
+		errs = append(errs, fmt.Errorf("GUEST_CART_TTL must be positive"))
+	}
+
+	// Browsers reject "*" on credentialed requests, so the combination would only hide a missing allowlist
+	if c.AllowCredentials && slices.Contains(c.AllowedOrigins, "*") {
+		errs = append(errs, fmt.Errorf("ALLOWED_ORIGINS must list origins instead of * when ALLOW_CREDENTIALS is true"))
+	}
+
+	if c.JWTDuration <= 0 {
+		errs = append(errs, fmt.Errorf("JWT_TTL must be positive"))
+	}
+
+	serviceURLs := []struct{ key, url string }{
+		{"USER_SERVICE_URL", c.UserServiceURL},
+		{"PRODUCT_SERVICE_URL", c.ProductServiceURL},
+		{"CART_SERVICE_URL", c.CartServiceURL},
+		{"ORDER_SERVICE_URL", c.OrderServiceURL},
+		{"NOTIFICATION_SERVICE_URL", c.NotificationServiceURL},
+	}
+	for _, service := range serviceURLs {
+		if err := checkServiceURL(service.url); err != nil {
+			errs = append(errs, fmt.Errorf("%s %w", service.key, err))
+		}
+	}
+
+	if _, err := middleware.ParseNetworks(c.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("TRUSTED_PROXIES: %w", err))
+	}
+
+	if c.RateLimitRequests <= 0 {
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_REQUESTS must be positive"))
+	}
+
+	if _, err := currency.ParseISO(c.BaseCurrency); err != nil {
+		errs = append(errs, fmt.Errorf("BASE_CURRENCY must be an ISO 4217 currency code, got %q", c.BaseCurrency))
+	}
+
+	if c.ProductBatchMaxIDs <= 0 || c.ProductBatchMaxIDs > maxProductBatchIDs {
+		errs = append(errs, fmt.Errorf("PRODUCT_BATCH_MAX_IDS must be between 1 and %d", maxProductBatchIDs))
+	}
+
+	timeouts := []struct {
+		key     string
+		timeout time.Duration
+	}{
+		{"REQUEST_TIMEOUT_SECONDS", c.RequestTimeout},
+		{"IDLE_TIMEOUT_SECONDS", c.IdleTimeout},
+		{"READ_TIMEOUT_SECONDS", c.ReadTimeout},
+		{"WRITE_TIMEOUT_SECONDS", c.WriteTimeout},
+		{"CB_TIMEOUT_SECONDS", c.CircuitBreakerTimeout},
+		{"RATE_LIMIT_WINDOW_SECONDS", c.RateLimitWindow},
+	}
+	for _, t := range timeouts {
+		if t.timeout <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", t.key))
+		}
+	}
+
+	if c.LogFormat != "" && c.LogFormat != logger.FormatText && c.LogFormat != logger.FormatJSON {
+		errs = append(errs, fmt.Errorf("LOG_FORMAT must be text or json, got %q", c.LogFormat))
+	}
+
+	if c.GRPCLoadBalancePolicy != pickfirst.Name && c.GRPCLoadBalancePolicy != roundrobin.Name {
+		errs = append(errs, fmt.Errorf("GRPC_LOAD_BALANCE_POLICY must be %s or %s, got %q", pickfirst.Name, roundrobin.Name, c.GRPCLoadBalancePolicy))
+	}
+
+	return errors.Join(errs...)
 }
 
-func getEnvInt(key string, defaultValue int) int {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// checkServiceURL accepts the gRPC targets the clients dial: host:port, or host:port behind a resolver scheme
+// such as dns:///host:port. A unix socket target is only checked for a path.
+func checkServiceURL(target string) error {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return errors.New("is required")
 	}
 
-	var intValue int
-	_, err := fmt.Sscanf(value, "%d", &intValue)
-	if err != nil {
-		return defaultValue
+	address := target
+	if scheme, endpoint, ok := strings.Cut(target, "://"); ok {
+		if scheme == "unix" || scheme == "unix-abstract" {
+			if strings.Trim(endpoint, "/") == "" {
+				return fmt.Errorf("must name a socket, got %q", target)
+			}
+			return nil
+		}
+		// The authority, between // and /, names a DNS server rather than the service
+		_, address, _ = strings.Cut(endpoint, "/")
 	}
 
-	return intValue
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || host == "" || strings.ContainsAny(host, " /") {
+		return fmt.Errorf("must be host:port, got %q", target)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("has an invalid port, got %q", target)
+	}
+	return nil
 }
 
-func getEnvBool(key string, defaultValue bool) bool {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// CORS returns the settings of the CORS middleware and gRPC-Web proxy
+func (c *Config) CORS() middleware.CORSConfig {
+	return middleware.CORSConfig{
+		Origins:          c.Origins,
+		AllowedMethods:   c.AllowedMethods,
+		AllowedHeaders:   c.AllowedHeaders,
+		AllowCredentials: c.AllowCredentials,
 	}
+}
 
-	return value == "true" || value == "1" || value == "yes"
+// Timeouts returns the settings of the timeout middleware
+func (c *Config) Timeouts() middleware.TimeoutConfig {
+	return middleware.TimeoutConfig{
+		Request: c.RequestTimeout,
+		Routes:  c.RouteTimeouts,
+	}
 }
 
-func getEnvFloat(key string, defaultValue float64) float64 {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// Currencies returns the settings of the price negotiation middleware. It expects a validated config.
+func (c *Config) Currencies() middleware.CurrencyConfig {
+	cfg := middleware.CurrencyConfig{
+		Base:  currency.MustParseISO(c.BaseCurrency),
+		Rates: make(map[currency.Unit]float64, len(c.CurrencyRates)),
+	}
+	for code, rate := range c.CurrencyRates {
+		cfg.Rates[currency.MustParseISO(code)] = rate
+	}
+	return cfg
+}
+
+// BlockList returns the settings of the block list middleware
+func (c *Config) BlockList() middleware.BlockListConfig {
+	return middleware.BlockListConfig{
+		Networks:     c.BlockedNetworks,
+		PathPrefixes: c.BlockedPathPrefixes,
 	}
+}
 
-	var floatValue float64
-	_, err := fmt.Sscanf(value, "%f", &floatValue)
+// grpcServices are the keys GRPC_DIAL_TIMEOUTS_JSON accepts
+var grpcServices = map[string]struct{}{"user": {}, "product": {}, "cart": {}, "order": {}, "notification": {}}
+
+// loadGRPCConnectionSettings reads the per-service dial timeouts and checks how the gateway dials and keeps
+// its service connections
+func loadGRPCConnectionSettings(cfg *Config) error {
+	if cfg.GRPCDialTimeout == 0 {
+		return fmt.Errorf("GRPC_DIAL_TIMEOUT must be positive")
+	}
+	var err error
+	cfg.GRPCDialTimeouts, err = getEnvDurationMap("GRPC_DIAL_TIMEOUTS_JSON")
 	if err != nil {
-		return defaultValue
+		return err
+	}
+	for service := range cfg.GRPCDialTimeouts {
+		if _, ok := grpcServices[service]; !ok {
+			return fmt.Errorf("GRPC_DIAL_TIMEOUTS_JSON: unknown service %q", service)
+		}
+	}
+
+	if cfg.GRPCBackoffBaseDelay == 0 || cfg.GRPCBackoffMaxDelay < cfg.GRPCBackoffBaseDelay {
+		return fmt.Errorf("GRPC_BACKOFF_BASE_DELAY must be positive and at most GRPC_BACKOFF_MAX_DELAY")
 	}
 
-	return floatValue
+	// The services disconnect clients that ping more often than that
+	if cfg.GRPCKeepaliveTime != 0 && cfg.GRPCKeepaliveTime < grpcmiddleware.KeepaliveMinTime {
+		return fmt.Errorf("GRPC_KEEPALIVE_TIME must be 0 or at least %s", grpcmiddleware.KeepaliveMinTime)
+	}
+	if cfg.GRPCKeepaliveTimeout == 0 {
+		return fmt.Errorf("GRPC_KEEPALIVE_TIMEOUT must be positive")
+	}
+	return nil
 }
 
-func getEnvArray(key string, defaultValue []string) []string {
+// getEnvDurationMap parses a JSON object of duration strings, e.g. {"GET /api/v1/users/me/export":"120s"}
+func getEnvDurationMap(key string) (map[string]time.Duration, error) {
+	result := make(map[string]time.Duration)
 	value := os.Getenv(key)
 	if value == "" {
-		return defaultValue
+		return result, nil
 	}
 
-	var result []string
-	current := ""
-	for _, char := range value {
-		if char == ',' {
-			if current != "" {
-				result = append(result, current)
-				current = ""
-			}
-		} else {
-			current += string(char)
-		}
-	}
-	if current != "" {
-		result = append(result, current)
+	var raw map[string]string
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON object of durations: %w", key, err)
 	}
 
-	if len(result) == 0 {
-		return defaultValue
+	for name, rawDuration := range raw {
+		duration, err := time.ParseDuration(rawDuration)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("%s: invalid duration %q for %s", key, rawDuration, name)
+		}
+		result[name] = duration
 	}
-	return result
+	return result, nil
 }
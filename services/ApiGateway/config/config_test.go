@@ -0,0 +1,635 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	envconfig "github.com/kareemhamed001/e-commerce/pkg/config"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	"golang.org/x/text/currency"
+)
+
+// loadWith runs Load in an empty directory, so no .env file is found, with env set on top of the settings
+// Validate requires
+func loadWith(t *testing.T, env map[string]string) (*Config, error) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Setenv("APP_ENV", "development")
+	t.Setenv("INTERNAL_AUTH_TOKEN", "internal-token")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	return Load()
+}
+
+func writeRSAPublicKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwt.pub.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestJWTAlgDefaultsToHS256(t *testing.T) {
+	cfg, err := loadWith(t, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.JWTAlgorithm != "HS256" || cfg.JWTKeySet != nil {
+		t.Errorf("JWT_ALG unset: algorithm %q with key set %v, want HS256 without one", cfg.JWTAlgorithm, cfg.JWTKeySet)
+	}
+}
+
+func TestJWTAlgorithmIsReadUnderItsOldName(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "JWT_ALGORITHM", env: map[string]string{"JWT_ALGORITHM": "RS256", "JWT_PUBLIC_KEY_FILES": writeRSAPublicKey(t)}, want: "RS256"},
+		{name: "JWT_ALG wins over JWT_ALGORITHM", env: map[string]string{"JWT_ALG": "HS256", "JWT_ALGORITHM": "RS256"}, want: "HS256"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWith(t, tt.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.JWTAlgorithm != tt.want {
+				t.Errorf("algorithm %q, want %q", cfg.JWTAlgorithm, tt.want)
+			}
+		})
+	}
+
+	// An old configuration with a bad value fails startup as under the new name
+	if _, err := loadWith(t, map[string]string{"JWT_ALGORITHM": "HS512"}); err == nil || !strings.HasPrefix(err.Error(), "JWT_ALG:") {
+		t.Errorf("Load = %v, want a JWT_ALG error", err)
+	}
+}
+
+func TestJWTAlgRS256LoadsPublicKeys(t *testing.T) {
+	cfg, err := loadWith(t, map[string]string{
+		"JWT_ALG":              "RS256",
+		"JWT_PUBLIC_KEY_FILES": writeRSAPublicKey(t),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.JWTAlgorithm != "RS256" || cfg.JWTKeySet == nil || len(cfg.JWTKeySet.PublicKeys("")) != 1 {
+		t.Errorf("algorithm %q with key set %v, want RS256 with one public key", cfg.JWTAlgorithm, cfg.JWTKeySet)
+	}
+}
+
+func TestJWTAlgErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{name: "RS256 without keys", env: map[string]string{"JWT_ALG": "RS256"}},
+		{name: "unknown algorithm", env: map[string]string{"JWT_ALG": "HS512"}},
+		{name: "EdDSA with an RSA key", env: map[string]string{"JWT_ALG": "EdDSA", "JWT_PUBLIC_KEY_FILES": writeRSAPublicKey(t)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadWith(t, tt.env)
+			if err == nil || !strings.HasPrefix(err.Error(), "JWT_ALG:") {
+				t.Fatalf("Load = %v, want a JWT_ALG error", err)
+			}
+		})
+	}
+}
+
+func TestMetricsPortMustDifferFromAppPort(t *testing.T) {
+	cfg, err := loadWith(t, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MetricsPort != "9090" {
+		t.Errorf("METRICS_PORT unset: got %q, want 9090", cfg.MetricsPort)
+	}
+
+	_, err = loadWith(t, map[string]string{"APP_PORT": "8080", "METRICS_PORT": "8080"})
+	if err == nil || !strings.Contains(err.Error(), "METRICS_PORT") {
+		t.Fatalf("Load = %v, want a METRICS_PORT error", err)
+	}
+}
+
+func TestRouteTimeoutsJSON(t *testing.T) {
+	cfg, err := loadWith(t, map[string]string{
+		"REQUEST_TIMEOUT_SECONDS": "30",
+		"ROUTE_TIMEOUTS_JSON":     `{"GET /api/v1/admin/reports/revenue": "2m", "GET /health": "500ms"}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := middleware.NewTimeoutPolicy(cfg.Timeouts())
+	if got := policy.Route("GET /api/v1/admin/reports/revenue", time.Second); got != 2*time.Minute {
+		t.Errorf("revenue report timeout = %v, want 2m", got)
+	}
+	if got := policy.Route("GET /health", time.Second); got != 500*time.Millisecond {
+		t.Errorf("health timeout = %v, want 500ms", got)
+	}
+	if got := policy.Route("GET /api/v1/products", time.Second); got != time.Second {
+		t.Errorf("timeout of a route left out = %v, want its 1s default", got)
+	}
+	if policy.Request() != 30*time.Second {
+		t.Errorf("global timeout = %v, want 30s", policy.Request())
+	}
+
+	for _, value := range []string{`["2m"]`, `{"GET /health": "soon"}`, `{"GET /health": "-1s"}`} {
+		if _, err := loadWith(t, map[string]string{"ROUTE_TIMEOUTS_JSON": value}); err == nil || !strings.HasPrefix(err.Error(), "ROUTE_TIMEOUTS_JSON") {
+			t.Errorf("ROUTE_TIMEOUTS_JSON=%s: Load = %v, want a ROUTE_TIMEOUTS_JSON error", value, err)
+		}
+	}
+}
+
+func TestJWTExpiryAndLeeway(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantTTL    time.Duration
+		wantLeeway time.Duration
+	}{
+		{name: "defaults", wantTTL: 24 * time.Hour, wantLeeway: 30 * time.Second},
+		{name: "JWT_EXPIRY", env: map[string]string{"JWT_EXPIRY": "15m", "JWT_LEEWAY": "5s"}, wantTTL: 15 * time.Minute, wantLeeway: 5 * time.Second},
+		{name: "JWT_TTL wins over JWT_EXPIRY", env: map[string]string{"JWT_TTL": "1h", "JWT_EXPIRY": "15m"}, wantTTL: time.Hour, wantLeeway: 30 * time.Second},
+		{name: "JWT_DURATION_HOURS", env: map[string]string{"JWT_DURATION_HOURS": "2"}, wantTTL: 2 * time.Hour, wantLeeway: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWith(t, tt.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.JWTDuration != tt.wantTTL || cfg.JWTLeeway != tt.wantLeeway {
+				t.Errorf("TTL %v and leeway %v, want %v and %v", cfg.JWTDuration, cfg.JWTLeeway, tt.wantTTL, tt.wantLeeway)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := loadWith(t, map[string]string{"JWT_EXPIRY": "a day"}); err == nil {
+			t.Error("an unparseable JWT_EXPIRY was accepted")
+		}
+	})
+	t.Run("audience", func(t *testing.T) {
+		cfg, err := loadWith(t, map[string]string{"SERVICE_NAME": "edge-gateway"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.JWTIssuer != "user-service" || cfg.JWTAudience != "edge-gateway" {
+			t.Errorf("issuer %q and audience %q, want user-service and the service name", cfg.JWTIssuer, cfg.JWTAudience)
+		}
+	})
+	t.Run("JWT_ISSUER and JWT_AUDIENCE", func(t *testing.T) {
+		cfg, err := loadWith(t, map[string]string{"JWT_ISSUER": "staging-user-service", "JWT_AUDIENCE": "staging-gateway"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.JWTIssuer != "staging-user-service" || cfg.JWTAudience != "staging-gateway" {
+			t.Errorf("issuer %q and audience %q, want the configured ones", cfg.JWTIssuer, cfg.JWTAudience)
+		}
+	})
+}
+
+func TestFeatureFlagsJSON(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := loadWith(t, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cfg.FeatureFlags["wishlist"].Enabled {
+			t.Errorf("flags = %+v, want the wishlist on by default", cfg.FeatureFlags)
+		}
+	})
+	t.Run("configured", func(t *testing.T) {
+		cfg, err := loadWith(t, map[string]string{"FEATURE_FLAGS_JSON": `{"wishlist": {"users": ["42"]}, "reviews_v2": true}`})
+		if err != nil {
+			t.Fatal(err)
+		}
+		store := middleware.NewStaticFeatureFlagStore(cfg.FeatureFlags)
+		if store.IsEnabled("wishlist", "7") || !store.IsEnabled("wishlist", "42") || !store.IsEnabled("reviews_v2", "7") {
+			t.Errorf("flags = %+v, want the configured ones over the defaults", cfg.FeatureFlags)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := loadWith(t, map[string]string{"FEATURE_FLAGS_JSON": `{"wishlist": {"percent": 150}}`}); err == nil || !strings.HasPrefix(err.Error(), "FEATURE_FLAGS_JSON") {
+			t.Errorf("Load = %v, want a FEATURE_FLAGS_JSON error", err)
+		}
+	})
+}
+
+func TestCurrencyRatesJSON(t *testing.T) {
+	t.Run("configured", func(t *testing.T) {
+		cfg, err := loadWith(t, map[string]string{"BASE_CURRENCY": "EUR", "CURRENCY_RATES_JSON": `{"usd": 1.09}`})
+		if err != nil {
+			t.Fatal(err)
+		}
+		currencies := cfg.Currencies()
+		if currencies.Base != currency.EUR || len(currencies.Rates) != 1 || currencies.Rates[currency.USD] != 1.09 {
+			t.Errorf("currencies = %+v, want EUR with a USD rate of 1.09", currencies)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := loadWith(t, map[string]string{"CURRENCY_RATES_JSON": `{"EUR": -1}`}); err == nil || !strings.HasPrefix(err.Error(), "CURRENCY_RATES_JSON") {
+			t.Errorf("Load = %v, want a CURRENCY_RATES_JSON error", err)
+		}
+	})
+}
+
+func TestGRPCLoadBalancingSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := loadWith(t, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.GRPCLoadBalancePolicy != "pick_first" || cfg.GRPCDNSMinResolutionInterval != 30*time.Second {
+			t.Errorf("policy %q and DNS interval %v, want pick_first and 30s", cfg.GRPCLoadBalancePolicy, cfg.GRPCDNSMinResolutionInterval)
+		}
+	})
+	t.Run("configured", func(t *testing.T) {
+		cfg, err := loadWith(t, map[string]string{"GRPC_LOAD_BALANCE_POLICY": "round_robin", "GRPC_DNS_MIN_RESOLUTION_INTERVAL": "5s"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.GRPCLoadBalancePolicy != "round_robin" || cfg.GRPCDNSMinResolutionInterval != 5*time.Second {
+			t.Errorf("policy %q and DNS interval %v, want round_robin and 5s", cfg.GRPCLoadBalancePolicy, cfg.GRPCDNSMinResolutionInterval)
+		}
+	})
+	t.Run("invalid policy", func(t *testing.T) {
+		if _, err := loadWith(t, map[string]string{"GRPC_LOAD_BALANCE_POLICY": "least_request"}); err == nil {
+			t.Error("an unsupported load balancing policy was accepted")
+		}
+	})
+	t.Run("invalid interval", func(t *testing.T) {
+		if _, err := loadWith(t, map[string]string{"GRPC_DNS_MIN_RESOLUTION_INTERVAL": "often"}); err == nil {
+			t.Error("an unparseable GRPC_DNS_MIN_RESOLUTION_INTERVAL was accepted")
+		}
+	})
+}
+
+func TestListSettingsAreTrimmed(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{name: "spaces around items", value: "https://shop.example.com, https://admin.example.com", want: []string{"https://shop.example.com", "https://admin.example.com"}},
+		{name: "empty items", value: ",https://shop.example.com,, ,", want: []string{"https://shop.example.com"}},
+		// An empty list is kept rather than replaced by the default, so a list setting can be cleared
+		{name: "only separators", value: " , ,", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWith(t, map[string]string{"ALLOWED_ORIGINS": tt.value})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(cfg.AllowedOrigins, tt.want) {
+				t.Errorf("ALLOWED_ORIGINS=%q gave %q, want %q", tt.value, cfg.AllowedOrigins, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(*Config)
+		wants []string
+	}{
+		{name: "valid", edit: func(*Config) {}},
+		{
+			name:  "default secret in production",
+			edit:  func(c *Config) { c.AppEnv = "production" },
+			wants: []string{"must not use the default JWT_SECRET", "GUEST_CART_SECRET must be set"},
+		},
+		{
+			name: "own secrets in production",
+			edit: func(c *Config) {
+				c.AppEnv = "production"
+				c.JWTKeys = []customJWT.Key{{Secret: "a-real-secret"}}
+				c.GuestCartSecret = "another-real-secret"
+			},
+		},
+		{
+			name: "default secret among rotated keys",
+			edit: func(c *Config) {
+				c.AppEnv = "production"
+				c.JWTKeys = []customJWT.Key{{ID: "2026-06", Secret: "a-real-secret"}, {ID: "old", Secret: defaultJWTSecret}}
+				c.GuestCartSecret = "another-real-secret"
+			},
+			wants: []string{"must not use the default JWT_SECRET"},
+		},
+		{name: "default secret in development", edit: func(c *Config) { c.AppEnv = "development" }},
+		{
+			name: "default secret in staging",
+			edit: func(c *Config) {
+				c.AppEnv = "staging"
+				c.GuestCartSecret = "another-real-secret"
+			},
+			wants: []string{"must not use the default JWT_SECRET outside development"},
+		},
+		{name: "empty service URL", edit: func(c *Config) { c.ProductServiceURL = " " }, wants: []string{"PRODUCT_SERVICE_URL is required"}},
+		{name: "service URL without a port", edit: func(c *Config) { c.CartServiceURL = "cart-service" }, wants: []string{"CART_SERVICE_URL must be host:port"}},
+		{name: "service URL with an invalid port", edit: func(c *Config) { c.OrderServiceURL = "order-service:70000" }, wants: []string{"ORDER_SERVICE_URL has an invalid port"}},
+		{name: "zero rate limit", edit: func(c *Config) { c.RateLimitRequests = 0 }, wants: []string{"RATE_LIMIT_REQUESTS must be positive"}},
+		{name: "zero rate limit window", edit: func(c *Config) { c.RateLimitWindow = 0 }, wants: []string{"RATE_LIMIT_WINDOW_SECONDS must be positive"}},
+		{name: "zero request timeout", edit: func(c *Config) { c.RequestTimeout = 0 }, wants: []string{"REQUEST_TIMEOUT_SECONDS must be positive"}},
+		{name: "product batch cap above the product service's", edit: func(c *Config) { c.ProductBatchMaxIDs = 500 }, wants: []string{"PRODUCT_BATCH_MAX_IDS must be between 1 and 100"}},
+		{name: "zero product batch cap", edit: func(c *Config) { c.ProductBatchMaxIDs = 0 }, wants: []string{"PRODUCT_BATCH_MAX_IDS must be between 1 and 100"}},
+		{name: "lower product batch cap", edit: func(c *Config) { c.ProductBatchMaxIDs = 20 }},
+		{name: "unknown base currency", edit: func(c *Config) { c.BaseCurrency = "DOLLAR" }, wants: []string{`BASE_CURRENCY must be an ISO 4217 currency code, got "DOLLAR"`}},
+		{name: "TLS without a certificate", edit: func(c *Config) { c.TLSEnabled = true }, wants: []string{"TLS_CERT_PATH and TLS_KEY_PATH are required"}},
+		{
+			name: "TLS with a missing certificate",
+			edit: func(c *Config) {
+				c.TLSEnabled = true
+				c.TLSCertPath, c.TLSKeyPath = "missing.crt", "missing.key"
+			},
+			wants: []string{"TLS_CERT_PATH and TLS_KEY_PATH: open missing.crt"},
+		},
+		{name: "negative circuit breaker timeout", edit: func(c *Config) { c.CircuitBreakerTimeout = -time.Second }, wants: []string{"CB_TIMEOUT_SECONDS must be positive"}},
+		{
+			name: "every problem at once",
+			edit: func(c *Config) {
+				c.InternalAuthToken = ""
+				c.UserServiceURL = ""
+				c.OrderServiceURL = ""
+				c.IdleTimeout = 0
+				c.WriteTimeout = -time.Second
+				c.LogFormat = "xml"
+			},
+			wants: []string{
+				"INTERNAL_AUTH_TOKEN is required",
+				"USER_SERVICE_URL",
+				"ORDER_SERVICE_URL",
+				"IDLE_TIMEOUT_SECONDS must be positive",
+				"WRITE_TIMEOUT_SECONDS must be positive",
+				"LOG_FORMAT must be text or json",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWith(t, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(cfg)
+
+			err = cfg.Validate()
+			if len(tt.wants) == 0 {
+				if err != nil {
+					t.Fatalf("Validate = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate passed, want %q", tt.wants)
+			}
+			if lines := strings.Split(err.Error(), "\n"); len(lines) != len(tt.wants) {
+				t.Errorf("Validate reported %d problems, want %d: %v", len(lines), len(tt.wants), err)
+			}
+			for _, want := range tt.wants {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate = %v, want it to report %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckServiceURL(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "user-service:50051"},
+		{target: "10.0.0.7:50051"},
+		{target: "[::1]:50051"},
+		{target: "dns:///user-headless:50051"},
+		{target: "dns://10.0.0.2:53/user-headless:50051"},
+		{target: "unix:///var/run/user.sock"},
+		{target: "", want: "is required"},
+		{target: "user-service", want: "must be host:port"},
+		{target: ":50051", want: "must be host:port"},
+		{target: "user service:50051", want: "must be host:port"},
+		{target: "user-service:0", want: "has an invalid port"},
+		{target: "user-service:grpc", want: "has an invalid port"},
+		{target: "dns:///user-headless", want: "must be host:port"},
+		{target: "unix://", want: "must name a socket"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			err := checkServiceURL(tt.target)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("checkServiceURL = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("checkServiceURL = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRefusesTheDefaultSecretInProduction(t *testing.T) {
+	_, err := loadWith(t, map[string]string{"APP_ENV": "production", "GUEST_CART_SECRET": "another-real-secret"})
+	if err == nil || !strings.Contains(err.Error(), "default JWT_SECRET") {
+		t.Fatalf("Load = %v, want the default secret refused", err)
+	}
+
+	if _, err := loadWith(t, map[string]string{"APP_ENV": "production", "GUEST_CART_SECRET": "another-real-secret", "JWT_SECRET": "a-real-secret"}); err != nil {
+		t.Fatalf("with JWT_SECRET set: Load = %v", err)
+	}
+}
+
+func TestAllowedOriginPatterns(t *testing.T) {
+	cfg, err := loadWith(t, map[string]string{
+		"ALLOWED_ORIGINS":         "https://shop.example.com",
+		"ALLOWED_ORIGIN_PATTERNS": `https://store\d+\.example\.com, https://(`,
+	})
+	if err != nil {
+		t.Fatalf("Load = %v, want an invalid pattern skipped rather than failing startup", err)
+	}
+	for origin, want := range map[string]bool{
+		"https://shop.example.com":    true,
+		"https://store12.example.com": true,
+		"https://admin.example.com":   false,
+	} {
+		if got := cfg.Origins.Allows(origin); got != want {
+			t.Errorf("Allows(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
+
+func TestAllowCredentials(t *testing.T) {
+	cfg, err := loadWith(t, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AllowCredentials {
+		t.Error("ALLOW_CREDENTIALS unset: credentials are allowed, want them off by default")
+	}
+
+	if _, err := loadWith(t, map[string]string{"ALLOW_CREDENTIALS": "true", "ALLOWED_ORIGINS": "*"}); err == nil || !strings.Contains(err.Error(), "ALLOW_CREDENTIALS") {
+		t.Errorf("credentials with *: Load = %v, want an ALLOWED_ORIGINS error", err)
+	}
+	if _, err := loadWith(t, map[string]string{"ALLOW_CREDENTIALS": "true", "ALLOWED_ORIGINS": "https://shop.example.com,*"}); err == nil {
+		t.Error("credentials with * among listed origins were accepted")
+	}
+
+	cfg, err = loadWith(t, map[string]string{"ALLOW_CREDENTIALS": "true", "ALLOWED_ORIGINS": "https://shop.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.CORS().AllowCredentials {
+		t.Error("ALLOW_CREDENTIALS=true did not reach the CORS settings")
+	}
+}
+
+// writeConfigFile writes a settings file for CONFIG_FILE and unsets what loading it sets once the test ends
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// Runs after t.Setenv has restored CONFIG_FILE, so loading again drops the file's settings
+	t.Cleanup(func() { envconfig.LoadDotEnv() })
+	return path
+}
+
+func TestConfigFile(t *testing.T) {
+	file := writeConfigFile(t, "gateway.yaml", `
+APP_PORT: 9000
+RATE_LIMIT_REQUESTS: 50
+ALLOWED_ORIGINS: [https://shop.example.com, https://admin.example.com]
+ROUTE_TIMEOUTS_JSON: {"GET /api/v1/admin/reports/revenue": 2m}
+REQUEST_TIMEOUT_SECONDS: 30
+`)
+	cfg, err := loadWith(t, map[string]string{"CONFIG_FILE": file, "REQUEST_TIMEOUT_SECONDS": "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.AppPort != "9000" || cfg.RateLimitRequests != 50 {
+		t.Errorf("APP_PORT = %q, RATE_LIMIT_REQUESTS = %d, want the file's 9000 and 50", cfg.AppPort, cfg.RateLimitRequests)
+	}
+	if want := []string{"https://shop.example.com", "https://admin.example.com"}; !slices.Equal(cfg.AllowedOrigins, want) {
+		t.Errorf("ALLOWED_ORIGINS = %v, want %v", cfg.AllowedOrigins, want)
+	}
+	if got := cfg.RouteTimeouts["GET /api/v1/admin/reports/revenue"]; got != 2*time.Minute {
+		t.Errorf("revenue report timeout = %v, want 2m", got)
+	}
+	// The environment wins over the file
+	if cfg.RequestTimeout != 5*time.Second {
+		t.Errorf("REQUEST_TIMEOUT_SECONDS = %v, want the environment's 5s", cfg.RequestTimeout)
+	}
+}
+
+func TestConfigFileWithUnknownSettings(t *testing.T) {
+	file := writeConfigFile(t, "gateway.json", `{"APP_PORT": 9000, "RATE_LIMIT": 50, "ALLOWED_ORIGIN": "https://shop.example.com"}`)
+	_, err := loadWith(t, map[string]string{"CONFIG_FILE": file})
+	if want := "CONFIG_FILE: unknown settings ALLOWED_ORIGIN, RATE_LIMIT"; err == nil || err.Error() != want {
+		t.Fatalf("Load = %v, want %q", err, want)
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	cfg, err := loadWith(t, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.TrustedProxies) != 0 {
+		t.Errorf("TrustedProxies = %v, want none by default", cfg.TrustedProxies)
+	}
+
+	cfg, err = loadWith(t, map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8, 192.168.1.4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"10.0.0.0/8", "192.168.1.4"}; !slices.Equal(cfg.TrustedProxies, want) {
+		t.Errorf("TrustedProxies = %v, want %v", cfg.TrustedProxies, want)
+	}
+
+	_, err = loadWith(t, map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8, ingress"})
+	if err == nil || !strings.Contains(err.Error(), `TRUSTED_PROXIES: invalid IP address "ingress"`) {
+		t.Fatalf("Load = %v, want the invalid proxy refused", err)
+	}
+}
+
+func TestStrictJSONDecoding(t *testing.T) {
+	for env, want := range map[string]bool{"": false, "true": true, "false": false} {
+		cfg, err := loadWith(t, map[string]string{"STRICT_JSON_DECODING": env})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.StrictJSONDecoding != want {
+			t.Errorf("STRICT_JSON_DECODING=%q: StrictJSONDecoding = %v, want %v", env, cfg.StrictJSONDecoding, want)
+		}
+	}
+}
+
+func TestJWTSecretFromTheSecretStore(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "secret", want: "from-the-secret-store"},
+		// The real environment still wins
+		{name: "environment", env: map[string]string{"JWT_SECRET": "from-the-environment"}, want: "from-the-environment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Runs last, once the provider is unset again, so loading again drops the secret
+			t.Cleanup(func() { envconfig.LoadDotEnv() })
+			envconfig.SetSecretsLoader(envconfig.MockSecretsLoader{"gateway": {"JWT_SECRET": "from-the-secret-store"}})
+			t.Cleanup(func() { envconfig.SetSecretsLoader(nil) })
+
+			env := map[string]string{"CONFIG_SECRETS_PROVIDER": "aws-secrets-manager", "CONFIG_SECRET_ARN": "gateway"}
+			maps.Copy(env, tt.env)
+			cfg, err := loadWith(t, env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.JWTSecret != tt.want {
+				t.Errorf("JWTSecret = %q, want %q", cfg.JWTSecret, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingSecretFailsLoad(t *testing.T) {
+	t.Cleanup(func() { envconfig.LoadDotEnv() })
+	envconfig.SetSecretsLoader(envconfig.MockSecretsLoader{})
+	t.Cleanup(func() { envconfig.SetSecretsLoader(nil) })
+
+	_, err := loadWith(t, map[string]string{"CONFIG_SECRETS_PROVIDER": "aws-secrets-manager", "CONFIG_SECRET_ARN": "gateway"})
+	if err == nil || !strings.Contains(err.Error(), "secret gateway not found") {
+		t.Fatalf("Load = %v, want the missing secret", err)
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	envconfig "github.com/kareemhamed001/e-commerce/pkg/config"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+)
+
+// reloadable are the settings Reload applies to a running gateway. The others, such as ports, service URLs
+// and JWT keys, are wired into servers and connections at startup and need a restart.
+var reloadable = map[string]bool{
+	"ALLOWED_ORIGINS":           true,
+	"ALLOWED_ORIGIN_PATTERNS":   true,
+	"ALLOWED_METHODS":           true,
+	"ALLOWED_HEADERS":           true,
+	"ALLOW_CREDENTIALS":         true,
+	"BLOCKED_CIDRS":             true,
+	"BLOCKED_PATH_PREFIXES":     true,
+	"RATE_LIMIT_REQUESTS":       true,
+	"RATE_LIMIT_WINDOW_SECONDS": true,
+	"REQUEST_TIMEOUT_SECONDS":   true,
+	"ROUTE_TIMEOUTS_JSON":       true,
+	"BASE_CURRENCY":             true,
+	"CURRENCY_RATES_JSON":       true,
+}
+
+// untaggedSettings are read by Load itself rather than through env tags, so Reload compares them here
+var untaggedSettings = []struct {
+	key   string
+	value func(*Config) any
+}{
+	{"ENABLE_SWAGGER", func(c *Config) any { return c.EnableSwagger }},
+	{"JWT_TTL", func(c *Config) any { return c.JWTDuration }},
+	{"JWT_AUDIENCE", func(c *Config) any { return c.JWTAudience }},
+	{"JWT_LEEWAY", func(c *Config) any { return c.JWTLeeway }},
+	{"JWT_SECRETS", func(c *Config) any { return c.JWTKeys }},
+	{"ROLE_PERMISSIONS_JSON", func(c *Config) any { return c.RolePermissions }},
+	{"FEATURE_FLAGS_JSON", func(c *Config) any { return c.FeatureFlags }},
+	{"ROUTE_TIMEOUTS_JSON", func(c *Config) any { return c.RouteTimeouts }},
+	{"GRPC_DIAL_TIMEOUTS_JSON", func(c *Config) any { return c.GRPCDialTimeouts }},
+	{"CURRENCY_RATES_JSON", func(c *Config) any { return c.CurrencyRates }},
+}
+
+// Reloader loads the configuration again and applies the CORS, block list, rate limit, timeout and currency
+// settings to the running gateway, on SIGHUP or from the admin API
+type Reloader struct {
+	mu sync.Mutex
+	// running is the configuration in effect: the one loaded at startup with every reload applied
+	running    Config
+	cors       *middleware.CORSPolicy
+	blockList  *middleware.BlockListPolicy
+	limiter    *middleware.RateLimiter
+	timeouts   *middleware.TimeoutPolicy
+	currencies *middleware.CurrencyPolicy
+}
+
+// NewReloader creates a reloader for a gateway started with cfg, serving cors, blockList, limiter, timeouts and
+// currencies
+func NewReloader(cfg *Config, cors *middleware.CORSPolicy, blockList *middleware.BlockListPolicy, limiter *middleware.RateLimiter, timeouts *middleware.TimeoutPolicy, currencies *middleware.CurrencyPolicy) *Reloader {
+	return &Reloader{running: *cfg, cors: cors, blockList: blockList, limiter: limiter, timeouts: timeouts, currencies: currencies}
+}
+
+// Reload loads the configuration as at startup and applies the reloadable settings that changed. The other
+// changed settings are logged and listed in restartRequired; they take effect on the next restart. An
+// invalid configuration is an error and changes nothing.
+func (r *Reloader) Reload() (applied, restartRequired []string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changed := envconfig.Changed(&r.running, next)
+	for _, setting := range untaggedSettings {
+		if !reflect.DeepEqual(setting.value(&r.running), setting.value(next)) {
+			changed = append(changed, setting.key)
+		}
+	}
+	for _, key := range changed {
+		if reloadable[key] {
+			applied = append(applied, key)
+		} else {
+			restartRequired = append(restartRequired, key)
+			logger.Warnf("event=config_reload_ignored setting=%s reason=requires a restart", key)
+		}
+	}
+
+	r.running.AllowedOrigins = next.AllowedOrigins
+	r.running.AllowedOriginPatterns = next.AllowedOriginPatterns
+	r.running.AllowedMethods = next.AllowedMethods
+	r.running.AllowedHeaders = next.AllowedHeaders
+	r.running.AllowCredentials = next.AllowCredentials
+	r.running.Origins = next.Origins
+	r.cors.Update(next.CORS())
+
+	r.running.BlockedCIDRs = next.BlockedCIDRs
+	r.running.BlockedPathPrefixes = next.BlockedPathPrefixes
+	r.running.BlockedNetworks = next.BlockedNetworks
+	r.blockList.Update(next.BlockList())
+
+	r.running.RateLimitRequests = next.RateLimitRequests
+	r.running.RateLimitWindow = next.RateLimitWindow
+	r.limiter.SetLimit(next.RateLimitRequests, next.RateLimitWindow)
+
+	r.running.RequestTimeout = next.RequestTimeout
+	r.running.RouteTimeouts = next.RouteTimeouts
+	r.timeouts.Update(next.Timeouts())
+
+	r.running.BaseCurrency = next.BaseCurrency
+	r.running.CurrencyRates = next.CurrencyRates
+	r.currencies.Update(next.Currencies())
+
+	logger.Infof("event=config_reloaded applied=%q", strings.Join(applied, ","))
+	return applied, restartRequired, nil
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+)
+
+// reloadableGateway loads the configuration with env and serves GET / behind the CORS and rate limit
+// middleware built from it, as main does. It returns the reloader and a func that requests / with method
+// from origin.
+func reloadableGateway(t *testing.T, env map[string]string) (*Reloader, func(method, origin string) *httptest.ResponseRecorder) {
+	t.Helper()
+	cfg, err := loadWith(t, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cors := middleware.NewCORSPolicy(cfg.CORS())
+	limiter := middleware.NewRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	reloader := NewReloader(cfg, cors, middleware.NewBlockListPolicy(cfg.BlockList()), limiter,
+		middleware.NewTimeoutPolicy(cfg.Timeouts()), middleware.NewCurrencyPolicy(cfg.Currencies()))
+
+	engine := gin.New()
+	engine.Use(middleware.CORS(cors), limiter.Middleware())
+	engine.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	return reloader, func(method, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/", nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+}
+
+func TestReloadFlipsAllowedOrigins(t *testing.T) {
+	reloader, send := reloadableGateway(t, map[string]string{"ALLOWED_ORIGINS": "https://old.example.com"})
+
+	if w := send(http.MethodOptions, "https://new.example.com"); w.Code != http.StatusForbidden {
+		t.Fatalf("preflight before the reload: status = %d, want 403", w.Code)
+	}
+
+	t.Setenv("ALLOWED_ORIGINS", "https://new.example.com")
+	applied, restartRequired, err := reloader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(applied, []string{"ALLOWED_ORIGINS"}) || len(restartRequired) > 0 {
+		t.Fatalf("applied %v and restart required for %v, want ALLOWED_ORIGINS applied", applied, restartRequired)
+	}
+
+	w := send(http.MethodOptions, "https://new.example.com")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://new.example.com" {
+		t.Fatalf("preflight after the reload: got %d with origin %q, want 204 allowing the new origin", w.Code, w.Header().Get("Access-Control-Allow-Origin"))
+	}
+	if w := send(http.MethodOptions, "https://old.example.com"); w.Code != http.StatusForbidden {
+		t.Fatalf("preflight from the removed origin: status = %d, want 403", w.Code)
+	}
+}
+
+func TestReloadAppliesTheRateLimit(t *testing.T) {
+	reloader, send := reloadableGateway(t, map[string]string{"RATE_LIMIT_REQUESTS": "100"})
+	send(http.MethodGet, "")
+
+	t.Setenv("RATE_LIMIT_REQUESTS", "2")
+	if _, _, err := reloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if w := send(http.MethodGet, ""); w.Code != http.StatusOK {
+		t.Fatalf("second request: status = %d, want 200", w.Code)
+	}
+	if w := send(http.MethodGet, ""); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request: status = %d, want 429 under the reloaded limit of 2", w.Code)
+	}
+}
+
+func TestReloadLeavesSettingsThatNeedARestart(t *testing.T) {
+	reloader, send := reloadableGateway(t, map[string]string{"ALLOWED_ORIGINS": "https://old.example.com"})
+
+	t.Setenv("APP_PORT", "9000")
+	t.Setenv("PRODUCT_SERVICE_URL", "product-headless:50052")
+	t.Setenv("ALLOWED_ORIGINS", "https://new.example.com")
+	applied, restartRequired, err := reloader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(restartRequired)
+	if !slices.Equal(applied, []string{"ALLOWED_ORIGINS"}) || !slices.Equal(restartRequired, []string{"APP_PORT", "PRODUCT_SERVICE_URL"}) {
+		t.Fatalf("applied %v and restart required for %v, want ALLOWED_ORIGINS applied and the rest left", applied, restartRequired)
+	}
+	if reloader.running.AppPort == "9000" || reloader.running.ProductServiceURL == "product-headless:50052" {
+		t.Error("the running configuration took a setting that needs a restart")
+	}
+
+	// Reported again on the next reload, as it still differs from what is running
+	if _, restartRequired, _ := reloader.Reload(); len(restartRequired) != 2 {
+		t.Errorf("second reload: restart required for %v, want APP_PORT and PRODUCT_SERVICE_URL again", restartRequired)
+	}
+	if w := send(http.MethodOptions, "https://new.example.com"); w.Code != http.StatusNoContent {
+		t.Errorf("preflight after the reload: status = %d, want 204", w.Code)
+	}
+}
+
+func TestReloadOfAnInvalidConfigurationChangesNothing(t *testing.T) {
+	reloader, send := reloadableGateway(t, map[string]string{"ALLOWED_ORIGINS": "https://old.example.com"})
+
+	t.Setenv("ALLOWED_ORIGINS", "https://new.example.com")
+	t.Setenv("RATE_LIMIT_REQUESTS", "0")
+	if _, _, err := reloader.Reload(); err == nil {
+		t.Fatal("a configuration with RATE_LIMIT_REQUESTS=0 was applied")
+	}
+	if w := send(http.MethodOptions, "https://old.example.com"); w.Code != http.StatusNoContent {
+		t.Errorf("preflight from the configured origin: status = %d, want 204", w.Code)
+	}
+	if w := send(http.MethodOptions, "https://new.example.com"); w.Code != http.StatusForbidden {
+		t.Errorf("preflight from the origin of the rejected configuration: status = %d, want 403", w.Code)
+	}
+}
+
+func TestReloadAppliesTheBlockList(t *testing.T) {
+	reloader, _ := reloadableGateway(t, map[string]string{"BLOCKED_PATH_PREFIXES": "/internal"})
+
+	t.Setenv("BLOCKED_CIDRS", "203.0.113.0/24")
+	t.Setenv("BLOCKED_PATH_PREFIXES", "/api/v1/reports")
+	applied, _, err := reloader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(applied)
+	if !slices.Equal(applied, []string{"BLOCKED_CIDRS", "BLOCKED_PATH_PREFIXES"}) {
+		t.Fatalf("applied %v, want BLOCKED_CIDRS and BLOCKED_PATH_PREFIXES", applied)
+	}
+
+	tests := []struct {
+		ip, path string
+		want     bool
+	}{
+		{ip: "203.0.113.9", path: "/api/v1/products", want: true},
+		{ip: "192.0.2.1", path: "/api/v1/reports/revenue", want: true},
+		{ip: "192.0.2.1", path: "/internal", want: false},
+	}
+	for _, tt := range tests {
+		if _, blocked := reloader.blockList.Blocks(tt.ip, tt.path); blocked != tt.want {
+			t.Errorf("%s from %s after the reload: blocked = %v, want %v", tt.path, tt.ip, blocked, tt.want)
+		}
+	}
+
+	// An invalid CIDR fails the reload and keeps the running list
+	t.Setenv("BLOCKED_CIDRS", "203.0.113.0/33")
+	if _, _, err := reloader.Reload(); err == nil {
+		t.Fatal("a configuration with an invalid BLOCKED_CIDRS was applied")
+	}
+	if _, blocked := reloader.blockList.Blocks("203.0.113.9", "/"); !blocked {
+		t.Error("the rejected reload unblocked 203.0.113.0/24")
+	}
+}
+
+func TestReloadAppliesTheTimeouts(t *testing.T) {
+	reloader, _ := reloadableGateway(t, map[string]string{"REQUEST_TIMEOUT_SECONDS": "30"})
+
+	t.Setenv("REQUEST_TIMEOUT_SECONDS", "5")
+	t.Setenv("ROUTE_TIMEOUTS_JSON", `{"GET /api/v1/admin/reports/revenue": "2m"}`)
+	applied, _, err := reloader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(applied)
+	if !slices.Equal(applied, []string{"REQUEST_TIMEOUT_SECONDS", "ROUTE_TIMEOUTS_JSON"}) {
+		t.Fatalf("applied %v, want REQUEST_TIMEOUT_SECONDS and ROUTE_TIMEOUTS_JSON", applied)
+	}
+	if got := reloader.timeouts.Request(); got != 5*time.Second {
+		t.Errorf("request timeout = %v, want 5s", got)
+	}
+	if got := reloader.timeouts.Route("GET /api/v1/admin/reports/revenue", time.Second); got != 2*time.Minute {
+		t.Errorf("revenue report timeout = %v, want 2m", got)
+	}
+
+	// An invalid configuration keeps the running timeouts
+	t.Setenv("REQUEST_TIMEOUT_SECONDS", "10")
+	t.Setenv("ROUTE_TIMEOUTS_JSON", `{"GET /api/v1/admin/reports/revenue": "soon"}`)
+	if _, _, err := reloader.Reload(); err == nil {
+		t.Fatal("a configuration with an invalid ROUTE_TIMEOUTS_JSON was applied")
+	}
+	if got := reloader.timeouts.Request(); got != 5*time.Second {
+		t.Errorf("request timeout after the rejected reload = %v, want 5s", got)
+	}
+}
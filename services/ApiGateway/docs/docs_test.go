@@ -0,0 +1,56 @@
+package docs
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/swaggo/swag"
+	"github.com/swaggo/swag/gen"
+)
+
+// TestDocsMatchAnnotations regenerates the spec from the handler annotations with the settings of
+// `make swagger` and fails when the committed files differ
+func TestDocsMatchAnnotations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("parses the whole gateway")
+	}
+	output := filepath.Join(t.TempDir(), "docs")
+	// swag runs from the gateway directory, as `make swagger` does
+	t.Chdir("..")
+	err := gen.New().Build(&gen.Config{
+		SearchDir:          "./",
+		MainAPIFile:        "cmd/main.go",
+		PropNamingStrategy: swag.CamelCase,
+		OutputDir:          output,
+		OutputTypes:        []string{"go", "json", "yaml"},
+		ParseDependency:    1,
+		UseStructNames:     true,
+		ParseDepth:         100,
+		OverridesFile:      gen.DefaultOverridesFile,
+		ParseGoList:        true,
+		LeftTemplateDelim:  "{{",
+		RightTemplateDelim: "}}",
+		CollectionFormat:   "csv",
+		Debugger:           log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatalf("generate docs: %v", err)
+	}
+
+	for _, name := range []string{"docs.go", "swagger.json", "swagger.yaml"} {
+		want, err := os.ReadFile(filepath.Join(output, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join("docs", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("docs/%s is out of date, run 'make swagger'", name)
+		}
+	}
+}
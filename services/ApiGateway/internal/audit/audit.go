@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is a single audit record
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Event  string    `json:"event"`
+	UserID uint      `json:"user_id"`
+	// Role is the primary role of the user
+	Role string `json:"role,omitempty"`
+	// Action is the route called, e.g. "DELETE /api/v1/products/:id", and Resource the path it was called on
+	Action    string `json:"action,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// IP is the client address, resolved through the trusted proxies
+	IP      string         `json:"ip,omitempty"`
+	Outcome string         `json:"outcome"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Sink stores audit entries
+type Sink interface {
+	Record(entry Entry) error
+}
+
+// Writer writes audit entries as JSON lines to w, such as os.Stdout for a log collector to pick up
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Record(entry Entry) error {
+	line, err := marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.w.Write(line)
+	return err
+}
+
+// Log appends audit entries as JSON lines to a file, kept apart from the regular application log
+type Log struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewLog(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	return &Log{path: path}, nil
+}
+
+func (l *Log) Record(entry Entry) error {
+	line, err := marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(line)
+	return err
+}
+
+// marshal encodes entry as a JSON line, stamped with the current time unless it has one
+func marshal(entry Entry) ([]byte, error) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
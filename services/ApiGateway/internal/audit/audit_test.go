@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterWritesJSONLines(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out)
+
+	stamped := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: stamped, Event: "mutating_request", UserID: 1, Role: "admin", Action: "DELETE /api/v1/products/:id", Resource: "/api/v1/products/42", RequestID: "req-42", Outcome: "success"},
+		{Event: "impersonation_started", UserID: 1, Outcome: "success", Details: map[string]any{"impersonated_user_id": 7}},
+	}
+	for _, entry := range entries {
+		if err := w.Record(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wrote %q, want two lines", out.String())
+	}
+	want := `{"time":"2026-10-16T12:00:00Z","event":"mutating_request","user_id":1,"role":"admin","action":"DELETE /api/v1/products/:id","resource":"/api/v1/products/42","request_id":"req-42","outcome":"success"}`
+	if lines[0] != want {
+		t.Errorf("first line = %s, want %s", lines[0], want)
+	}
+
+	var second Entry
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	// Entries without a time are stamped when written
+	if second.Time.IsZero() || second.Event != "impersonation_started" || second.Details["impersonated_user_id"] != float64(7) {
+		t.Errorf("second entry = %+v, want a stamped impersonation_started", second)
+	}
+}
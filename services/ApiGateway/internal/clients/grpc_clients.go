@@ -1,86 +1,214 @@
 package clients
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
+	notificationpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/notification"
 	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
 	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	reviewpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/review"
+	shippingpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/shipping"
 	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	wishlistpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/wishlist"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
 )
 
+// Service names key HealthStatus and ConnectionConfig.DialTimeouts
+const (
+	UserService         = "user"
+	ProductService      = "product"
+	CartService         = "cart"
+	OrderService        = "order"
+	NotificationService = "notification"
+)
+
+// ConnectionConfig tunes how the gateway dials and keeps its service connections
+type ConnectionConfig struct {
+	// LoadBalancePolicy is pick_first or round_robin across the addresses a target resolves to
+	LoadBalancePolicy string
+	// DialTimeout bounds each connection attempt; DialTimeouts overrides it per service
+	DialTimeout  time.Duration
+	DialTimeouts map[string]time.Duration
+	// BackoffBaseDelay and BackoffMaxDelay space out reconnection attempts to an unreachable service
+	BackoffBaseDelay time.Duration
+	BackoffMaxDelay  time.Duration
+	// KeepaliveTime is how long a connection may sit idle before it is pinged, 0 disables pings.
+	// A ping unanswered within KeepaliveTimeout closes the connection so the next call redials.
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	// Credentials secure the connections, e.g. with SPIFFE mTLS; nil leaves them in plaintext
+	Credentials credentials.TransportCredentials
+}
+
+type serviceConn struct {
+	name string
+	conn *grpc.ClientConn
+}
+
 // ServiceClients holds all gRPC client connections
 type ServiceClients struct {
-	UserClient    userpb.UserServiceClient
-	ProductClient productpb.ProductServiceClient
-	CartClient    cartpb.CartServiceClient
-	OrderClient   orderpb.OrderServiceClient
-	conns         []*grpc.ClientConn
+	UserClient     userpb.UserServiceClient
+	ProductClient  productpb.ProductServiceClient
+	ReviewClient   reviewpb.ReviewServiceClient
+	CartClient     cartpb.CartServiceClient
+	WishlistClient wishlistpb.WishlistServiceClient
+	OrderClient    orderpb.OrderServiceClient
+	ShippingClient shippingpb.ShippingServiceClient
+	// NotificationClient serves notification history and preferences
+	NotificationClient notificationpb.NotificationServiceClient
+	// Conns maps full gRPC service names, e.g. "product.ProductService", to the connection serving them
+	Conns map[string]*grpc.ClientConn
+	// UserConn through NotificationConn are the connection to each service, shared by all of its clients
+	UserConn         *grpc.ClientConn
+	ProductConn      *grpc.ClientConn
+	CartConn         *grpc.ClientConn
+	OrderConn        *grpc.ClientConn
+	NotificationConn *grpc.ClientConn
+	conns            []serviceConn
 }
 
-// NewServiceClients creates new gRPC client connections to all services
+// NewServiceClients creates gRPC clients for all services. Dialing does not block: each connection is
+// established in the background and re-established with backoff, so a service that is down at startup
+// only fails the calls made to it. It only returns an error for a target that cannot be parsed.
 func NewServiceClients(
 	userServiceURL,
 	productServiceURL,
 	cartServiceURL,
 	orderServiceURL,
-	internalAuthToken string,
+	notificationServiceURL,
+	internalAuthToken,
+	serviceName string,
+	connConfig ConnectionConfig,
 	cbConfig grpcmiddleware.CircuitBreakerConfig,
 ) (*ServiceClients, error) {
 	clients := &ServiceClients{
-		conns: make([]*grpc.ClientConn, 0),
+		Conns: make(map[string]*grpc.ClientConn),
+		conns: make([]serviceConn, 0),
 	}
 
 	// Connect to User Service
-	userConn, err := createGRPCConnection(userServiceURL, internalAuthToken, cbConfig)
+	userConn, err := createGRPCConnection(userServiceURL, internalAuthToken, serviceName, connConfig.dialTimeout(UserService), connConfig, cbConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to user service: %w", err)
 	}
 	clients.UserClient = userpb.NewUserServiceClient(userConn)
-	clients.conns = append(clients.conns, userConn)
-	logger.Infof("Connected to User Service at %s", userServiceURL)
+	clients.Conns[userpb.UserService_ServiceDesc.ServiceName] = userConn
+	clients.UserConn = userConn
+	clients.conns = append(clients.conns, serviceConn{name: UserService, conn: userConn})
+	logger.Infof("Dialing User Service at %s", userServiceURL)
 
 	// Connect to Product Service
-	productConn, err := createGRPCConnection(productServiceURL, internalAuthToken, cbConfig)
+	productConn, err := createGRPCConnection(productServiceURL, internalAuthToken, serviceName, connConfig.dialTimeout(ProductService), connConfig, cbConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to product service: %w", err)
 	}
 	clients.ProductClient = productpb.NewProductServiceClient(productConn)
-	clients.conns = append(clients.conns, productConn)
-	logger.Infof("Connected to Product Service at %s", productServiceURL)
+	// The product service also serves reviews, so they share its connection
+	clients.ReviewClient = reviewpb.NewReviewServiceClient(productConn)
+	clients.Conns[productpb.ProductService_ServiceDesc.ServiceName] = productConn
+	clients.Conns[reviewpb.ReviewService_ServiceDesc.ServiceName] = productConn
+	clients.ProductConn = productConn
+	clients.conns = append(clients.conns, serviceConn{name: ProductService, conn: productConn})
+	logger.Infof("Dialing Product Service at %s", productServiceURL)
 
 	// Connect to Cart Service
-	cartConn, err := createGRPCConnection(cartServiceURL, internalAuthToken, cbConfig)
+	cartConn, err := createGRPCConnection(cartServiceURL, internalAuthToken, serviceName, connConfig.dialTimeout(CartService), connConfig, cbConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to cart service: %w", err)
 	}
 	clients.CartClient = cartpb.NewCartServiceClient(cartConn)
-	clients.conns = append(clients.conns, cartConn)
-	logger.Infof("Connected to Cart Service at %s", cartServiceURL)
+	// The cart service also serves wishlists, so they share its connection
+	clients.WishlistClient = wishlistpb.NewWishlistServiceClient(cartConn)
+	clients.Conns[cartpb.CartService_ServiceDesc.ServiceName] = cartConn
+	clients.Conns[wishlistpb.WishlistService_ServiceDesc.ServiceName] = cartConn
+	clients.CartConn = cartConn
+	clients.conns = append(clients.conns, serviceConn{name: CartService, conn: cartConn})
+	logger.Infof("Dialing Cart Service at %s", cartServiceURL)
 
 	// Connect to Order Service
-	orderConn, err := createGRPCConnection(orderServiceURL, internalAuthToken, cbConfig)
+	orderConn, err := createGRPCConnection(orderServiceURL, internalAuthToken, serviceName, connConfig.dialTimeout(OrderService), connConfig, cbConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to order service: %w", err)
 	}
 	clients.OrderClient = orderpb.NewOrderServiceClient(orderConn)
-	clients.conns = append(clients.conns, orderConn)
-	logger.Infof("Connected to Order Service at %s", orderServiceURL)
+	// The order service also serves shipping quotes, so they share its connection
+	clients.ShippingClient = shippingpb.NewShippingServiceClient(orderConn)
+	clients.Conns[orderpb.OrderService_ServiceDesc.ServiceName] = orderConn
+	clients.Conns[shippingpb.ShippingService_ServiceDesc.ServiceName] = orderConn
+	clients.OrderConn = orderConn
+	clients.conns = append(clients.conns, serviceConn{name: OrderService, conn: orderConn})
+	logger.Infof("Dialing Order Service at %s", orderServiceURL)
+
+	// Connect to Notification Service
+	notificationConn, err := createGRPCConnection(notificationServiceURL, internalAuthToken, serviceName, connConfig.dialTimeout(NotificationService), connConfig, cbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to notification service: %w", err)
+	}
+	clients.NotificationClient = notificationpb.NewNotificationServiceClient(notificationConn)
+	clients.Conns[notificationpb.NotificationService_ServiceDesc.ServiceName] = notificationConn
+	clients.NotificationConn = notificationConn
+	clients.conns = append(clients.conns, serviceConn{name: NotificationService, conn: notificationConn})
+	logger.Infof("Dialing Notification Service at %s", notificationServiceURL)
+
+	// Start connecting now rather than on the first call, so readiness reflects the services early
+	for _, sc := range clients.conns {
+		sc.conn.Connect()
+	}
 
 	return clients, nil
 }
 
-// createGRPCConnection creates a new gRPC connection with retry logic
-func createGRPCConnection(target, internalAuthToken string, cbConfig grpcmiddleware.CircuitBreakerConfig) (*grpc.ClientConn, error) {
+// dialTimeout returns the connection attempt timeout for service
+func (c ConnectionConfig) dialTimeout(service string) time.Duration {
+	if timeout, ok := c.DialTimeouts[service]; ok {
+		return timeout
+	}
+	return c.DialTimeout
+}
+
+// createGRPCConnection creates a new gRPC connection with retry logic. The target is resolved through DNS
+// and the load balancing policy picks among the addresses it returns: pick_first sticks to one, round_robin
+// spreads calls over all of them.
+func createGRPCConnection(target, internalAuthToken, serviceName string, dialTimeout time.Duration, connConfig ConnectionConfig, cbConfig grpcmiddleware.CircuitBreakerConfig) (*grpc.ClientConn, error) {
+	backoffConfig := backoff.DefaultConfig
+	backoffConfig.BaseDelay = connConfig.BackoffBaseDelay
+	backoffConfig.MaxDelay = connConfig.BackoffMaxDelay
+	creds := connConfig.Credentials
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingPolicy":%q}`, connConfig.LoadBalancePolicy)),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoffConfig,
+			MinConnectTimeout: dialTimeout,
+		}),
 		grpc.WithChainUnaryInterceptor(
-			grpcmiddleware.InternalAuthUnaryClientInterceptor(internalAuthToken),
+			grpcmiddleware.InternalAuthUnaryClientInterceptor(internalAuthToken, serviceName),
+			grpcmiddleware.IdentityUnaryClientInterceptor(),
+			grpcmiddleware.RequestIDUnaryClientInterceptor(),
 			grpcmiddleware.CircuitBreakerUnaryClientInterceptor("api-gateway->"+target, cbConfig),
+			grpcmiddleware.LatencyUnaryClientInterceptor(grpcmiddleware.ClientRequestDuration),
+		),
+		grpc.WithChainStreamInterceptor(
+			grpcmiddleware.InternalAuthStreamClientInterceptor(internalAuthToken, serviceName),
+			grpcmiddleware.IdentityStreamClientInterceptor(),
+			grpcmiddleware.RequestIDStreamClientInterceptor(),
+			grpcmiddleware.LatencyStreamClientInterceptor(grpcmiddleware.ClientRequestDuration),
 		),
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallRecvMsgSize(10*1024*1024), // 10MB
@@ -88,7 +216,17 @@ func createGRPCConnection(target, internalAuthToken string, cbConfig grpcmiddlew
 		),
 	}
 
-	conn, err := grpc.NewClient(target, opts...)
+	if connConfig.KeepaliveTime > 0 {
+		// Pinging idle connections too keeps NAT and load balancer entries alive between bursts of calls.
+		// The services permit it through grpcmiddleware.DefaultServerOptions.
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                connConfig.KeepaliveTime,
+			Timeout:             connConfig.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+
+	conn, err := grpc.NewClient(dnsTarget(target), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial %s: %w", target, err)
 	}
@@ -96,10 +234,64 @@ func createGRPCConnection(target, internalAuthToken string, cbConfig grpcmiddlew
 	return conn, nil
 }
 
+// HealthStatus reports the state of each service connection, keyed by service name. A connection that
+// has gone idle is asked to reconnect, so the next report reflects whether the service is reachable.
+func (sc *ServiceClients) HealthStatus() map[string]connectivity.State {
+	status := make(map[string]connectivity.State, len(sc.conns))
+	for _, c := range sc.conns {
+		state := c.conn.GetState()
+		if state == connectivity.Idle {
+			c.conn.Connect()
+		}
+		status[c.name] = state
+	}
+	return status
+}
+
+// ResetConnectBackoff makes the named service's connection retry now instead of waiting out its backoff,
+// e.g. once an operator knows the service is back. It returns false for an unknown service name.
+func (sc *ServiceClients) ResetConnectBackoff(service string) bool {
+	for _, c := range sc.conns {
+		if c.name == service {
+			// An idle connection has no pending attempt to cut short, so start one
+			c.conn.Connect()
+			c.conn.ResetConnectBackoff()
+			return true
+		}
+	}
+	return false
+}
+
+// WaitForReady blocks until every service connection is ready or ctx is done. Serving does not need it, as
+// calls wait for their own connection; it is for development and tests that want the services up first.
+func (sc *ServiceClients) WaitForReady(ctx context.Context) error {
+	for _, c := range sc.conns {
+		for state := c.conn.GetState(); state != connectivity.Ready; state = c.conn.GetState() {
+			if state == connectivity.Idle {
+				c.conn.Connect()
+			}
+			if !c.conn.WaitForStateChange(ctx, state) {
+				return fmt.Errorf("%s service not ready: %s: %w", c.name, state, ctx.Err())
+			}
+		}
+	}
+	return nil
+}
+
+// dnsTarget resolves a bare host:port through DNS, which the load balancing policy needs to see every
+// address behind a headless service. Targets that name a registered resolver, such as
+// passthrough:///host:port, are kept as they are.
+func dnsTarget(target string) string {
+	if u, err := url.Parse(target); err == nil && resolver.Get(u.Scheme) != nil {
+		return target
+	}
+	return "dns:///" + target
+}
+
 // Close closes all gRPC connections
 func (sc *ServiceClients) Close() error {
-	for _, conn := range sc.conns {
-		if err := conn.Close(); err != nil {
+	for _, c := range sc.conns {
+		if err := c.conn.Close(); err != nil {
 			logger.Errorf("Error closing gRPC connection: %v", err)
 		}
 	}
@@ -0,0 +1,263 @@
+package clients
+
+import (
+	"context"
+	"maps"
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer/pickfirst"
+	"google.golang.org/grpc/balancer/roundrobin"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// countingServer serves the health service on a local port and counts the calls it answers
+func countingServer(t *testing.T, opts ...grpc.ServerOption) (string, *atomic.Int32) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls atomic.Int32
+	server := grpc.NewServer(append(opts, grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		calls.Add(1)
+		return handler(ctx, req)
+	}))...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String(), &calls
+}
+
+func TestLoadBalancePolicySpreadsCallsOverTheResolvedAddresses(t *testing.T) {
+	tests := []struct {
+		policy string
+		scheme string
+		// wantBoth is whether both servers should answer some of the calls
+		wantBoth bool
+	}{
+		{policy: roundrobin.Name, scheme: "roundrobin", wantBoth: true},
+		{policy: pickfirst.Name, scheme: "pickfirst"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			first, firstCalls := countingServer(t)
+			second, secondCalls := countingServer(t)
+
+			// A resolver returning both addresses stands in for the DNS records of a headless service
+			r := manual.NewBuilderWithScheme(tt.scheme)
+			r.InitialState(resolver.State{Addresses: []resolver.Address{{Addr: first}, {Addr: second}}})
+			resolver.Register(r)
+
+			conn, err := createGRPCConnection(r.Scheme()+":///order-headless:50051", "internal-token", "api-gateway", time.Second,
+				ConnectionConfig{LoadBalancePolicy: tt.policy, BackoffBaseDelay: time.Second, BackoffMaxDelay: time.Second},
+				grpcmiddleware.CircuitBreakerConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			client := healthpb.NewHealthClient(conn)
+			const calls = 10
+			for range calls {
+				if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			a, b := firstCalls.Load(), secondCalls.Load()
+			if a+b != calls {
+				t.Fatalf("servers answered %d and %d calls, want %d in all", a, b, calls)
+			}
+			if both := a > 0 && b > 0; both != tt.wantBoth {
+				t.Errorf("servers answered %d and %d calls, want both used: %v", a, b, tt.wantBoth)
+			}
+		})
+	}
+}
+
+// roundRobinClient dials r's addresses with round_robin, as the gateway does with GRPC_LOAD_BALANCE_POLICY=round_robin
+func roundRobinClient(t *testing.T, r *manual.Resolver) healthpb.HealthClient {
+	t.Helper()
+	resolver.Register(r)
+	conn, err := createGRPCConnection(r.Scheme()+":///product-headless:50051", "internal-token", "api-gateway", time.Second,
+		ConnectionConfig{LoadBalancePolicy: roundrobin.Name, BackoffBaseDelay: 100 * time.Millisecond, BackoffMaxDelay: 100 * time.Millisecond},
+		grpcmiddleware.CircuitBreakerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestRoundRobinAlternatesBetweenServers(t *testing.T) {
+	first, firstCalls := countingServer(t)
+	second, secondCalls := countingServer(t)
+	r := manual.NewBuilderWithScheme("alternate")
+	r.InitialState(resolver.State{Addresses: []resolver.Address{{Addr: first}, {Addr: second}}})
+	client := roundRobinClient(t, r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	check := func() {
+		t.Helper()
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Calls made while only one connection is up all go to it, so wait for both first
+	for firstCalls.Load() == 0 || secondCalls.Load() == 0 {
+		check()
+	}
+
+	last := ""
+	for i := range 10 {
+		a, b := firstCalls.Load(), secondCalls.Load()
+		check()
+		served := "first"
+		if secondCalls.Load() > b {
+			served = "second"
+		}
+		if firstCalls.Load()-a+secondCalls.Load()-b != 1 {
+			t.Fatalf("call %d was counted %d times", i, firstCalls.Load()-a+secondCalls.Load()-b)
+		}
+		if served == last {
+			t.Fatalf("call %d went to the %s server again, want the calls to alternate", i, served)
+		}
+		last = served
+	}
+}
+
+func TestServersAddedLaterGetTrafficOnceTheConnectionAgesOut(t *testing.T) {
+	// The services close connections after grpcmiddleware.MaxConnectionAge; a shorter age keeps the test fast
+	aging := grpc.KeepaliveParams(keepalive.ServerParameters{MaxConnectionAge: 200 * time.Millisecond})
+	first, _ := countingServer(t, aging)
+
+	// The resolver answers a re-resolution with the current addresses, as DNS of a headless service does
+	var mu sync.Mutex
+	addresses := []resolver.Address{{Addr: first}}
+	r := manual.NewBuilderWithScheme("scaleup")
+	r.InitialState(resolver.State{Addresses: addresses})
+	r.ResolveNowCallback = func(resolver.ResolveNowOptions) {
+		mu.Lock()
+		state := resolver.State{Addresses: slices.Clone(addresses)}
+		mu.Unlock()
+		go r.UpdateState(state)
+	}
+	client := roundRobinClient(t, r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Scale up: a second server appears in DNS, but nothing tells the client
+	second, secondCalls := countingServer(t, aging)
+	mu.Lock()
+	addresses = append(addresses, resolver.Address{Addr: second})
+	mu.Unlock()
+
+	for secondCalls.Load() == 0 {
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true)); err != nil {
+			t.Fatalf("the added server got no call before %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDNSTarget(t *testing.T) {
+	tests := map[string]string{
+		"order-headless:50051":                  "dns:///order-headless:50051",
+		"localhost:50051":                       "dns:///localhost:50051",
+		"passthrough:///order:50051":            "passthrough:///order:50051",
+		"dns:///order-headless.default:50051":   "dns:///order-headless.default:50051",
+		"dns://10.0.0.10:53/order-headless:123": "dns://10.0.0.10:53/order-headless:123",
+	}
+	for target, want := range tests {
+		if got := dnsTarget(target); got != want {
+			t.Errorf("dnsTarget(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+// refusedAddress returns a local address nothing listens on
+func refusedAddress(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+	return addr
+}
+
+func TestHealthStatusAndResetConnectBackoff(t *testing.T) {
+	up, _ := countingServer(t)
+	down := refusedAddress(t)
+	sc, err := NewServiceClients("passthrough:///"+up, "passthrough:///"+down, "passthrough:///"+up, "passthrough:///"+up, "passthrough:///"+up,
+		"internal-token", "api-gateway",
+		ConnectionConfig{LoadBalancePolicy: pickfirst.Name, DialTimeout: time.Second, BackoffBaseDelay: time.Minute, BackoffMaxDelay: time.Minute},
+		grpcmiddleware.CircuitBreakerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sc.Close()
+
+	// Connections start idle; HealthStatus starts them, and reports their state once they settle
+	want := map[string]connectivity.State{
+		UserService:         connectivity.Ready,
+		ProductService:      connectivity.TransientFailure,
+		CartService:         connectivity.Ready,
+		OrderService:        connectivity.Ready,
+		NotificationService: connectivity.Ready,
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	states := sc.HealthStatus()
+	for !maps.Equal(states, want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("states = %v, want %v", states, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+		states = sc.HealthStatus()
+	}
+
+	// The product service is back; without the reset the connection would wait out its one minute backoff
+	lis, err := net.Listen("tcp", down)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	if !sc.ResetConnectBackoff(ProductService) {
+		t.Fatal("ResetConnectBackoff(product) = false")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for state := sc.ProductConn.GetState(); state != connectivity.Ready; state = sc.ProductConn.GetState() {
+		if !sc.ProductConn.WaitForStateChange(ctx, state) {
+			t.Fatalf("the product connection is %v after the reset, want READY", state)
+		}
+	}
+
+	if sc.ResetConnectBackoff("inventory") {
+		t.Error("ResetConnectBackoff(inventory) = true for an unknown service")
+	}
+}
@@ -0,0 +1,71 @@
+package clients
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
+	notificationpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/notification"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Probe calls a cheap read-only RPC on every service, each bounded by timeout, and returns the error of
+// each service that failed, keyed by service name. An error the service itself returned for the request,
+// such as InvalidArgument, still shows it is up and serving. Unavailable, deadline, auth and internal errors do not.
+func (sc *ServiceClients) Probe(ctx context.Context, timeout time.Duration) map[string]error {
+	probes := map[string]func(ctx context.Context) error{
+		UserService: func(ctx context.Context) error {
+			_, err := sc.UserClient.SearchUsers(ctx, &userpb.SearchUsersRequest{PageNumber: 1, PageSize: 1})
+			return err
+		},
+		ProductService: func(ctx context.Context) error {
+			_, err := sc.ProductClient.ListCategories(ctx, &productpb.ListCategoriesRequest{Page: 1, PerPage: 1})
+			return err
+		},
+		CartService: func(ctx context.Context) error {
+			_, err := sc.CartClient.GetCart(ctx, &cartpb.GetCartRequest{})
+			return err
+		},
+		OrderService: func(ctx context.Context) error {
+			_, err := sc.OrderClient.ListOrders(ctx, &orderpb.ListOrdersRequest{Page: 1, PerPage: 1})
+			return err
+		},
+		NotificationService: func(ctx context.Context) error {
+			_, err := sc.NotificationClient.ListNotifications(ctx, &notificationpb.ListNotificationsRequest{Page: 1, PerPage: 1})
+			return err
+		},
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures = make(map[string]error)
+	)
+	for service, probe := range probes {
+		wg.Go(func() {
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			if err := probe(probeCtx); !answered(err) {
+				mu.Lock()
+				failures[service] = err
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return failures
+}
+
+// answered reports whether err, from a probe, came from a service that handled the request
+func answered(err error) bool {
+	switch status.Code(err) {
+	case codes.OK, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition, codes.OutOfRange:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
+	notificationpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/notification"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// probedService answers the probe RPC of every service with err, or hangs until the caller gives up when
+// hang is set; the methods it does not implement panic through the nil interfaces
+type probedService struct {
+	userpb.UserServiceClient
+	productpb.ProductServiceClient
+	cartpb.CartServiceClient
+	orderpb.OrderServiceClient
+	notificationpb.NotificationServiceClient
+	hang bool
+	err  error
+}
+
+func (p *probedService) answer(ctx context.Context) error {
+	if p.hang {
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return p.err
+}
+
+func (p *probedService) SearchUsers(ctx context.Context, _ *userpb.SearchUsersRequest, _ ...grpc.CallOption) (*userpb.SearchUsersResponse, error) {
+	return &userpb.SearchUsersResponse{}, p.answer(ctx)
+}
+
+func (p *probedService) ListCategories(ctx context.Context, _ *productpb.ListCategoriesRequest, _ ...grpc.CallOption) (*productpb.ListCategoriesResponse, error) {
+	return &productpb.ListCategoriesResponse{}, p.answer(ctx)
+}
+
+func (p *probedService) GetCart(ctx context.Context, _ *cartpb.GetCartRequest, _ ...grpc.CallOption) (*cartpb.CartResponse, error) {
+	return &cartpb.CartResponse{}, p.answer(ctx)
+}
+
+func (p *probedService) ListOrders(ctx context.Context, _ *orderpb.ListOrdersRequest, _ ...grpc.CallOption) (*orderpb.ListOrdersResponse, error) {
+	return &orderpb.ListOrdersResponse{}, p.answer(ctx)
+}
+
+func (p *probedService) ListNotifications(ctx context.Context, _ *notificationpb.ListNotificationsRequest, _ ...grpc.CallOption) (*notificationpb.ListNotificationsResponse, error) {
+	return &notificationpb.ListNotificationsResponse{}, p.answer(ctx)
+}
+
+// probedClients serves each service from services, defaulting to one that answers OK
+func probedClients(services map[string]*probedService) *ServiceClients {
+	get := func(name string) *probedService {
+		if s, ok := services[name]; ok {
+			return s
+		}
+		return &probedService{}
+	}
+	return &ServiceClients{
+		UserClient:         get(UserService),
+		ProductClient:      get(ProductService),
+		CartClient:         get(CartService),
+		OrderClient:        get(OrderService),
+		NotificationClient: get(NotificationService),
+	}
+}
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name     string
+		services map[string]*probedService
+		want     map[string]codes.Code
+	}{
+		{name: "all answer", want: map[string]codes.Code{}},
+		{
+			name:     "service times out",
+			services: map[string]*probedService{OrderService: {hang: true}},
+			want:     map[string]codes.Code{OrderService: codes.DeadlineExceeded},
+		},
+		{
+			name: "services unavailable or failing",
+			services: map[string]*probedService{
+				CartService:         {err: status.Error(codes.Unavailable, "connection refused")},
+				NotificationService: {err: status.Error(codes.Internal, "database down")},
+			},
+			want: map[string]codes.Code{CartService: codes.Unavailable, NotificationService: codes.Internal},
+		},
+		{
+			// The service handled the request, so it is serving
+			name: "errors about the request",
+			services: map[string]*probedService{
+				CartService: {err: status.Error(codes.InvalidArgument, "user_id is required")},
+				UserService: {err: status.Error(codes.NotFound, "no users")},
+			},
+			want: map[string]codes.Code{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			failures := probedClients(tt.services).Probe(context.Background(), 50*time.Millisecond)
+			if elapsed := time.Since(start); elapsed >= time.Second {
+				t.Fatalf("Probe took %v, want it bounded by the 50ms timeout", elapsed)
+			}
+
+			got := make(map[string]codes.Code, len(failures))
+			for service, err := range failures {
+				got[service] = status.Code(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("failures = %v, want %v", failures, tt.want)
+			}
+			for service, code := range tt.want {
+				if got[service] != code {
+					t.Errorf("%s failed with %v, want %v", service, failures[service], code)
+				}
+			}
+		})
+	}
+}
+
+func TestProbeStopsWhenTheCallerGivesUp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	failures := probedClients(map[string]*probedService{UserService: {hang: true}}).Probe(ctx, time.Minute)
+	if err := failures[UserService]; status.Code(err) != codes.Canceled {
+		t.Fatalf("user failed with %v, want Canceled", err)
+	}
+}
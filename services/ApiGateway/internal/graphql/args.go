@@ -0,0 +1,60 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Args holds a field's arguments in the form JSON variables decode to: json.Number, string, bool, nil,
+// []any or map[string]any. An argument that was not given is absent.
+type Args map[string]any
+
+// Int returns an integer argument. ID arguments may be sent as strings, so numeric strings are accepted.
+func (a Args) Int(name string) (int64, bool, error) {
+	value, ok := a[name]
+	if !ok || value == nil {
+		return 0, false, nil
+	}
+
+	var text string
+	switch value := value.(type) {
+	case json.Number:
+		text = value.String()
+	case float64:
+		text = strconv.FormatFloat(value, 'f', -1, 64)
+	case string:
+		text = value
+	default:
+		return 0, false, &Error{Message: fmt.Sprintf("argument %q must be an integer", name)}
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return 0, false, &Error{Message: fmt.Sprintf("argument %q must be an integer", name)}
+	}
+	return n, true, nil
+}
+
+func (a Args) String(name string) (string, bool, error) {
+	value, ok := a[name]
+	if !ok || value == nil {
+		return "", false, nil
+	}
+	text, ok := value.(string)
+	if !ok {
+		return "", false, &Error{Message: fmt.Sprintf("argument %q must be a string", name)}
+	}
+	return text, true, nil
+}
+
+// Decode unmarshals an argument, such as a list of input objects, into target through its JSON form
+func (a Args) Decode(name string, target any) error {
+	body, err := json.Marshal(a[name])
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return &Error{Message: fmt.Sprintf("argument %q has the wrong shape: %v", name, err)}
+	}
+	return nil
+}
@@ -0,0 +1,583 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// maxRootFields bounds the resolvers, and so the gRPC calls, a single request can trigger through aliases
+	maxRootFields = 20
+	// maxDepth bounds how deeply selections nest, the root fields being at depth 1
+	maxDepth = 10
+	// maxComplexity bounds the fields a request selects once its fragments are expanded, so spreads that
+	// repeat each other cannot multiply a small document into an enormous projection
+	maxComplexity = 500
+)
+
+// protoJSON names fields in lowerCamelCase, as GraphQL clients expect, and emits zero values so every
+// selected field is present
+var protoJSON = protojson.MarshalOptions{EmitUnpopulated: true}
+
+// Schema exposes gRPC responses over GraphQL. Root fields are resolved to proto messages, and the
+// message's descriptor is the type that nested selections are checked against and projected from.
+// Fields are selected by their proto JSON names, e.g. totalCount for total_count.
+type Schema struct {
+	Query    map[string]*RootField
+	Mutation map[string]*RootField
+	// FormatError turns a resolver error into a GraphQL error. Without it the error text is the message.
+	FormatError func(error) *Error
+}
+
+// RootField is a query or mutation field, the only fields with resolvers
+type RootField struct {
+	// Args names the arguments the field accepts
+	Args    []string
+	Resolve func(ctx context.Context, params ResolveParams) (proto.Message, error)
+}
+
+// ResolveParams carries what a resolver knows about the field being resolved
+type ResolveParams struct {
+	Args      Args
+	selection *fieldSelection
+}
+
+// SelectedFields names the fields selected below the message at path, e.g. SelectedFields("products") for
+// products { products { id name } }. It is meant for building field masks, so __typename is left out and
+// fields from every fragment are included regardless of their type condition.
+func (p ResolveParams) SelectedFields(path ...string) []string {
+	selections := p.selection.field.Selections
+	for _, name := range path {
+		var next []Selection
+		for _, field := range p.selection.exec.allFields(selections) {
+			if field.Name == name {
+				next = append(next, field.Selections...)
+			}
+		}
+		selections = next
+	}
+
+	var names []string
+	for _, field := range p.selection.exec.allFields(selections) {
+		if field.Name != "__typename" && !slices.Contains(names, field.Name) {
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
+
+// Request is the JSON body of a GraphQL POST
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the JSON result of a request. Data is nil when the request failed before execution.
+type Response struct {
+	Data   *Object  `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error is a GraphQL error; Path is set for errors raised while resolving a field
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Object is a result object whose fields keep the order they were selected in
+type Object struct {
+	keys   []string
+	values map[string]any
+}
+
+func newObject() *Object {
+	return &Object{values: make(map[string]any)}
+}
+
+func (o *Object) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Execute runs the request's operation. Query fields are resolved concurrently and mutation fields one after
+// another, as the spec requires. A failing field is null in Data and reported in Errors.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+
+	operation, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+
+	e := &execution{schema: s, doc: doc}
+	if err := e.checkLimits(operation.Selections); err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+	if e.variables, err = coerceVariables(operation, req.Variables); err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+
+	fields, typeName := s.Query, "Query"
+	if operation.Type == "mutation" {
+		fields, typeName = s.Mutation, "Mutation"
+	}
+	roots, err := e.collectFields(typeName, operation.Selections)
+	if err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+	if len(roots) > maxRootFields {
+		return &Response{Errors: []*Error{{Message: fmt.Sprintf("at most %d root fields may be selected, got %d", maxRootFields, len(roots))}}}
+	}
+
+	selections := make([]*fieldSelection, 0, len(roots))
+	for _, root := range roots {
+		selection := &fieldSelection{exec: e, field: root}
+		if root.Name != "__typename" {
+			if selection.def = fields[root.Name]; selection.def == nil {
+				return &Response{Errors: []*Error{{Message: fmt.Sprintf("cannot query field %q on type %q", root.Name, typeName)}}}
+			}
+			for _, argument := range root.Arguments {
+				if !slices.Contains(selection.def.Args, argument.Name) {
+					return &Response{Errors: []*Error{{Message: fmt.Sprintf("unknown argument %q on field %q", argument.Name, root.Name)}}}
+				}
+			}
+		}
+		selections = append(selections, selection)
+	}
+
+	results := make([]fieldResult, len(selections))
+	if operation.Type == "mutation" {
+		for i, selection := range selections {
+			results[i] = selection.resolve(ctx, typeName)
+		}
+	} else {
+		var wg sync.WaitGroup
+		for i, selection := range selections {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = selection.resolve(ctx, typeName)
+			}()
+		}
+		wg.Wait()
+	}
+
+	resp := &Response{Data: newObject()}
+	for i, selection := range selections {
+		resp.Data.set(selection.field.ResponseKey(), results[i].value)
+		resp.Errors = append(resp.Errors, results[i].errors...)
+	}
+	return resp
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, &Error{Message: "operationName is required when the document has several operations"}
+		}
+		return doc.Operations[0], nil
+	}
+	for _, operation := range doc.Operations {
+		if operation.Name == name {
+			return operation, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+}
+
+func coerceVariables(operation *Operation, provided map[string]any) (map[string]any, error) {
+	variables := make(map[string]any, len(operation.Variables))
+	for _, definition := range operation.Variables {
+		value, ok := provided[definition.Name]
+		switch {
+		case ok && value != nil:
+			variables[definition.Name] = value
+		case !ok && definition.HasDefault:
+			constant, err := resolveValue(definition.Default, nil)
+			if err != nil {
+				return nil, err
+			}
+			variables[definition.Name] = constant
+		case definition.NonNull:
+			return nil, &Error{Message: fmt.Sprintf("variable $%s is required", definition.Name)}
+		case ok:
+			variables[definition.Name] = nil
+		}
+	}
+	return variables, nil
+}
+
+type execution struct {
+	schema    *Schema
+	doc       *Document
+	variables map[string]any
+}
+
+type fieldSelection struct {
+	exec  *execution
+	field *Field
+	def   *RootField
+}
+
+type fieldResult struct {
+	value  any
+	errors []*Error
+}
+
+func (s *fieldSelection) resolve(ctx context.Context, typeName string) fieldResult {
+	key := s.field.ResponseKey()
+	if s.field.Name == "__typename" {
+		return fieldResult{value: typeName}
+	}
+
+	fail := func(err error) fieldResult {
+		gqlErr := asError(err)
+		if _, ok := err.(*Error); !ok && s.exec.schema.FormatError != nil {
+			gqlErr = s.exec.schema.FormatError(err)
+		}
+		gqlErr.Path = append([]any{key}, gqlErr.Path...)
+		return fieldResult{errors: []*Error{gqlErr}}
+	}
+
+	args, err := resolveArguments(s.field.Arguments, s.exec.variables)
+	if err != nil {
+		return fail(err)
+	}
+	msg, err := s.def.Resolve(ctx, ResolveParams{Args: args, selection: s})
+	if err != nil {
+		return fail(err)
+	}
+	if msg == nil || !msg.ProtoReflect().IsValid() {
+		return fieldResult{}
+	}
+	if len(s.field.Selections) == 0 {
+		return fail(&Error{Message: fmt.Sprintf("field %q of type %q must have a selection of subfields", s.field.Name, msg.ProtoReflect().Descriptor().Name())})
+	}
+
+	body, err := protoJSON.Marshal(msg)
+	if err != nil {
+		return fail(err)
+	}
+	var value map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return fail(err)
+	}
+
+	object, err := s.exec.project(msg.ProtoReflect().Descriptor(), value, s.field.Selections, []any{key})
+	if err != nil {
+		return fieldResult{errors: []*Error{asError(err)}}
+	}
+	return fieldResult{value: object}
+}
+
+// project picks the selected fields out of value, the protojson form of a message of type desc
+func (e *execution) project(desc protoreflect.MessageDescriptor, value map[string]any, selections []Selection, path []any) (*Object, error) {
+	typeName := string(desc.Name())
+	fields, err := e.collectFields(typeName, selections)
+	if err != nil {
+		return nil, err
+	}
+
+	object := newObject()
+	for _, field := range fields {
+		key := field.ResponseKey()
+		fieldPath := append(slices.Clone(path), key)
+		if field.Name == "__typename" {
+			object.set(key, typeName)
+			continue
+		}
+
+		fd := desc.Fields().ByJSONName(field.Name)
+		if fd == nil {
+			return nil, &Error{Message: fmt.Sprintf("cannot query field %q on type %q", field.Name, typeName), Path: fieldPath}
+		}
+		if len(field.Arguments) > 0 {
+			return nil, &Error{Message: fmt.Sprintf("field %q takes no arguments", field.Name), Path: fieldPath}
+		}
+
+		raw := value[field.Name]
+		if !fd.IsList() {
+			projected, err := e.projectValue(fd, raw, field, fieldPath)
+			if err != nil {
+				return nil, err
+			}
+			object.set(key, projected)
+			continue
+		}
+
+		items, _ := raw.([]any)
+		list := make([]any, len(items))
+		for i, item := range items {
+			if list[i], err = e.projectValue(fd, item, field, append(slices.Clone(fieldPath), i)); err != nil {
+				return nil, err
+			}
+		}
+		object.set(key, list)
+	}
+	return object, nil
+}
+
+func (e *execution) projectValue(fd protoreflect.FieldDescriptor, value any, field *Field, path []any) (any, error) {
+	if !isObjectField(fd) {
+		if len(field.Selections) > 0 {
+			return nil, &Error{Message: fmt.Sprintf("field %q is a scalar and cannot have a selection", field.Name), Path: path}
+		}
+		return value, nil
+	}
+
+	if len(field.Selections) == 0 {
+		return nil, &Error{Message: fmt.Sprintf("field %q of type %q must have a selection of subfields", field.Name, fd.Message().Name()), Path: path}
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	return e.project(fd.Message(), object, field.Selections, path)
+}
+
+// isObjectField reports whether fd is selected into like an object. Maps and well-known types such as
+// Timestamp have a JSON form that is not an object of their fields, so they are returned whole.
+func isObjectField(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.MessageKind && !fd.IsMap() && !strings.HasPrefix(string(fd.Message().FullName()), "google.protobuf.")
+}
+
+// collectFields flattens fragments into the fields they select on typeName, in selection order, dropping
+// those excluded by @skip or @include. Fields selected twice under one response key are merged.
+func (e *execution) collectFields(typeName string, selections []Selection) ([]*Field, error) {
+	var fields []*Field
+	byKey := make(map[string]*Field)
+	var collect func(selections []Selection, visiting []string) error
+	collect = func(selections []Selection, visiting []string) error {
+		for _, selection := range selections {
+			included, err := e.included(selection.directives())
+			if err != nil {
+				return err
+			}
+			if !included {
+				continue
+			}
+
+			switch selection := selection.(type) {
+			case *Field:
+				key := selection.ResponseKey()
+				existing, ok := byKey[key]
+				if !ok {
+					merged := *selection
+					byKey[key] = &merged
+					fields = append(fields, &merged)
+					continue
+				}
+				if existing.Name != selection.Name {
+					return &Error{Message: fmt.Sprintf("%q selects both %q and %q; use different aliases", key, existing.Name, selection.Name)}
+				}
+				existing.Selections = append(slices.Clip(existing.Selections), selection.Selections...)
+			case *InlineFragment:
+				if selection.TypeCondition == "" || selection.TypeCondition == typeName {
+					if err := collect(selection.Selections, visiting); err != nil {
+						return err
+					}
+				}
+			case *FragmentSpread:
+				fragment, ok := e.doc.Fragments[selection.Name]
+				if !ok {
+					return &Error{Message: fmt.Sprintf("unknown fragment %q", selection.Name)}
+				}
+				if slices.Contains(visiting, selection.Name) {
+					return &Error{Message: fmt.Sprintf("fragment %q spreads itself", selection.Name)}
+				}
+				if fragment.TypeCondition == typeName {
+					if err := collect(fragment.Selections, append(visiting, selection.Name)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	return fields, collect(selections, nil)
+}
+
+// checkLimits rejects an operation that nests deeper than maxDepth or selects more than maxComplexity fields.
+// Every field counts, whatever its directives, and the walk stops at the first field over a limit, so its
+// cost is bounded however the fragments spread one another. Unknown and cyclic spreads are skipped here and
+// reported by collectFields.
+func (e *execution) checkLimits(selections []Selection) error {
+	complexity := 0
+	var walk func(selections []Selection, depth int, visiting []string) error
+	walk = func(selections []Selection, depth int, visiting []string) error {
+		for _, selection := range selections {
+			switch selection := selection.(type) {
+			case *Field:
+				if depth > maxDepth {
+					return &Error{Message: fmt.Sprintf("selections may be nested at most %d levels deep", maxDepth)}
+				}
+				if complexity++; complexity > maxComplexity {
+					return &Error{Message: fmt.Sprintf("at most %d fields may be selected, counting those of every fragment spread", maxComplexity)}
+				}
+				if err := walk(selection.Selections, depth+1, visiting); err != nil {
+					return err
+				}
+			case *InlineFragment:
+				if err := walk(selection.Selections, depth, visiting); err != nil {
+					return err
+				}
+			case *FragmentSpread:
+				fragment, ok := e.doc.Fragments[selection.Name]
+				if !ok || slices.Contains(visiting, selection.Name) {
+					continue
+				}
+				if err := walk(fragment.Selections, depth, append(visiting, selection.Name)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return walk(selections, 1, nil)
+}
+
+// allFields is collectFields for every type condition, used where the selected type is not known yet
+func (e *execution) allFields(selections []Selection) []*Field {
+	var fields []*Field
+	var collect func(selections []Selection, depth int)
+	collect = func(selections []Selection, depth int) {
+		if depth > len(e.doc.Fragments) {
+			return
+		}
+		for _, selection := range selections {
+			switch selection := selection.(type) {
+			case *Field:
+				fields = append(fields, selection)
+			case *InlineFragment:
+				collect(selection.Selections, depth)
+			case *FragmentSpread:
+				if fragment, ok := e.doc.Fragments[selection.Name]; ok {
+					collect(fragment.Selections, depth+1)
+				}
+			}
+		}
+	}
+	collect(selections, 0)
+	return fields
+}
+
+// included evaluates @skip(if:) and @include(if:)
+func (e *execution) included(directives []*Directive) (bool, error) {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			return false, &Error{Message: fmt.Sprintf("unknown directive @%s", directive.Name)}
+		}
+		args, err := resolveArguments(directive.Arguments, e.variables)
+		if err != nil {
+			return false, err
+		}
+		condition, ok := args["if"].(bool)
+		if !ok {
+			return false, &Error{Message: fmt.Sprintf("@%s requires a Boolean \"if\" argument", directive.Name)}
+		}
+		if condition == (directive.Name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func resolveArguments(arguments []*Argument, variables map[string]any) (Args, error) {
+	args := make(Args, len(arguments))
+	for _, argument := range arguments {
+		if variable, ok := argument.Value.(Variable); ok {
+			// An argument given an omitted variable is treated as not given at all
+			if value, ok := variables[string(variable)]; ok {
+				args[argument.Name] = value
+			}
+			continue
+		}
+		value, err := resolveValue(argument.Value, variables)
+		if err != nil {
+			return nil, err
+		}
+		args[argument.Name] = value
+	}
+	return args, nil
+}
+
+// resolveValue turns a parsed value into the form JSON variables decode to, substituting variables
+func resolveValue(value Value, variables map[string]any) (any, error) {
+	switch value := value.(type) {
+	case Variable:
+		return variables[string(value)], nil
+	case EnumValue:
+		return string(value), nil
+	case []Value:
+		list := make([]any, len(value))
+		for i, item := range value {
+			resolved, err := resolveValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = resolved
+		}
+		return list, nil
+	case ObjectValue:
+		object := make(map[string]any, len(value))
+		for _, field := range value {
+			resolved, err := resolveValue(field.Value, variables)
+			if err != nil {
+				return nil, err
+			}
+			object[field.Name] = resolved
+		}
+		return object, nil
+	}
+	return value, nil
+}
+
+func asError(err error) *Error {
+	if gqlErr, ok := err.(*Error); ok {
+		return gqlErr
+	}
+	return &Error{Message: err.Error()}
+}
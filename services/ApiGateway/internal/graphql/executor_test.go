@@ -0,0 +1,346 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	"google.golang.org/protobuf/proto"
+)
+
+// testSchema serves a fixed product list and cart, recording what its resolvers were called with
+type testSchema struct {
+	*Schema
+	mu        sync.Mutex
+	args      []Args
+	selected  [][]string
+	mutations []string
+}
+
+func newTestSchema() *testSchema {
+	s := &testSchema{}
+	s.Schema = &Schema{
+		Query: map[string]*RootField{
+			"products": {Args: []string{"page", "perPage", "cursor", "filter"}, Resolve: func(_ context.Context, params ResolveParams) (proto.Message, error) {
+				s.record(params, "")
+				return &productpb.ListProductsResponse{
+					Products: []*productpb.Product{
+						{Id: 1, Name: "Mouse", Price: 25},
+						{Id: 2, Name: "Keyboard", Price: 40},
+					},
+					TotalCount: 2,
+				}, nil
+			}},
+			"product": {Args: []string{"id"}, Resolve: func(context.Context, ResolveParams) (proto.Message, error) {
+				return nil, errors.New("product not found")
+			}},
+			"missing": {Resolve: func(context.Context, ResolveParams) (proto.Message, error) {
+				return (*productpb.Product)(nil), nil
+			}},
+		},
+		Mutation: map[string]*RootField{
+			"addToCart": {Args: []string{"productId", "quantity"}, Resolve: func(_ context.Context, params ResolveParams) (proto.Message, error) {
+				productID, _, err := params.Args.Int("productId")
+				if err != nil {
+					return nil, err
+				}
+				s.record(params, "addToCart")
+				return &cartpb.CartResponse{UserId: 7, Items: []*cartpb.CartItem{{ProductId: productID, Quantity: 1}}, TotalQuantity: 1}, nil
+			}},
+			"clearCart": {Resolve: func(_ context.Context, params ResolveParams) (proto.Message, error) {
+				s.record(params, "clearCart")
+				return &cartpb.CartResponse{UserId: 7}, nil
+			}},
+		},
+		FormatError: func(err error) *Error {
+			return &Error{Message: err.Error(), Extensions: map[string]any{"code": 404}}
+		},
+	}
+	return s
+}
+
+func (s *testSchema) record(params ResolveParams, mutation string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.args = append(s.args, params.Args)
+	s.selected = append(s.selected, params.SelectedFields("products"))
+	if mutation != "" {
+		s.mutations = append(s.mutations, mutation)
+	}
+}
+
+// execute runs query and returns the response as JSON, as the handler writes it
+func (s *testSchema) execute(t *testing.T, query string, variables map[string]any) string {
+	t.Helper()
+	body, err := json.Marshal(s.Execute(context.Background(), Request{Query: query, Variables: variables}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestExecuteProjectsSelectedFields(t *testing.T) {
+	s := newTestSchema()
+	got := s.execute(t, `{
+		__typename
+		products(page: 2) { totalCount products { name id } kind: __typename }
+	}`, nil)
+
+	want := `{"data":{"__typename":"Query","products":{"totalCount":2,"products":[{"name":"Mouse","id":1},{"name":"Keyboard","id":2}],"kind":"ListProductsResponse"}}}`
+	if got != want {
+		t.Errorf("response\n got %s\nwant %s", got, want)
+	}
+	if page, _, _ := s.args[0].Int("page"); page != 2 {
+		t.Errorf("page = %d, want 2", page)
+	}
+}
+
+func TestExecuteFragments(t *testing.T) {
+	s := newTestSchema()
+	got := s.execute(t, `
+		query {
+			products {
+				...counts
+				products { id }
+				... on ListProductsResponse { products { name } }
+				... on Cart { nextCursor }
+				... { nextCursor }
+			}
+		}
+		fragment counts on ListProductsResponse { totalCount ...prices }
+		fragment prices on ListProductsResponse { products { price } }
+		fragment unused on CartResponse { totalQuantity }
+	`, nil)
+
+	// Fields selected twice are merged in the order they first appear; the Cart fragment does not apply
+	want := `{"data":{"products":{"totalCount":2,"products":[{"price":25,"id":1,"name":"Mouse"},{"price":40,"id":2,"name":"Keyboard"}],"nextCursor":""}}}`
+	if got != want {
+		t.Errorf("response\n got %s\nwant %s", got, want)
+	}
+	// Field masks include fields of every fragment, whatever its type condition
+	if want := []string{"price", "id", "name"}; !reflect.DeepEqual(s.selected[0], want) {
+		t.Errorf("selected fields = %v, want %v", s.selected[0], want)
+	}
+}
+
+func TestExecuteVariables(t *testing.T) {
+	query := `query ($page: Int = 3, $perPage: Int, $cursor: String!, $withCount: Boolean!, $ids: [ID]) {
+		products(page: $page, perPage: $perPage, cursor: $cursor, filter: {ids: $ids, sort: ASC}) {
+			totalCount @include(if: $withCount)
+			products { id }
+		}
+	}`
+
+	t.Run("given and defaulted", func(t *testing.T) {
+		s := newTestSchema()
+		got := s.execute(t, query, map[string]any{"cursor": "abc", "withCount": false, "ids": []any{"1", json.Number("2")}})
+		if want := `{"data":{"products":{"products":[{"id":1},{"id":2}]}}}`; got != want {
+			t.Errorf("response\n got %s\nwant %s", got, want)
+		}
+
+		args := s.args[0]
+		if page, ok, _ := args.Int("page"); page != 3 || !ok {
+			t.Errorf("page = %d, %v; want the default 3", page, ok)
+		}
+		if _, ok := args["perPage"]; ok {
+			t.Error("perPage is set although its variable was omitted")
+		}
+		if cursor, _, _ := args.String("cursor"); cursor != "abc" {
+			t.Errorf("cursor = %q, want abc", cursor)
+		}
+		wantFilter := map[string]any{"ids": []any{"1", json.Number("2")}, "sort": "ASC"}
+		if !reflect.DeepEqual(args["filter"], wantFilter) {
+			t.Errorf("filter = %#v, want %#v", args["filter"], wantFilter)
+		}
+	})
+
+	t.Run("required missing", func(t *testing.T) {
+		got := newTestSchema().execute(t, query, map[string]any{"withCount": true})
+		if want := `{"errors":[{"message":"variable $cursor is required"}]}`; got != want {
+			t.Errorf("response\n got %s\nwant %s", got, want)
+		}
+	})
+
+	t.Run("required null", func(t *testing.T) {
+		got := newTestSchema().execute(t, query, map[string]any{"cursor": nil, "withCount": true})
+		if !strings.Contains(got, "variable $cursor is required") {
+			t.Errorf("response %s, want a required variable error", got)
+		}
+	})
+}
+
+func TestExecuteMutationsRunInOrder(t *testing.T) {
+	s := newTestSchema()
+	got := s.execute(t, `mutation {
+		first: addToCart(productId: "7", quantity: 1) { items { productId } }
+		clearCart { totalQuantity }
+		second: addToCart(productId: 8, quantity: 1) { totalQuantity }
+	}`, nil)
+
+	want := `{"data":{"first":{"items":[{"productId":"7"}]},"clearCart":{"totalQuantity":0},"second":{"totalQuantity":1}}}`
+	if got != want {
+		t.Errorf("response\n got %s\nwant %s", got, want)
+	}
+	if want := []string{"addToCart", "clearCart", "addToCart"}; !reflect.DeepEqual(s.mutations, want) {
+		t.Errorf("mutations ran as %v, want %v", s.mutations, want)
+	}
+}
+
+func TestExecuteOperationName(t *testing.T) {
+	document := `query counts { products { totalCount } } query names { products { products { name } } }`
+	s := newTestSchema()
+
+	got := s.Execute(context.Background(), Request{Query: document, OperationName: "counts"})
+	if body, _ := json.Marshal(got); string(body) != `{"data":{"products":{"totalCount":2}}}` {
+		t.Errorf("counts: %s", body)
+	}
+	if got := s.Execute(context.Background(), Request{Query: document}); got.Data != nil || len(got.Errors) != 1 {
+		t.Errorf("no operationName: %+v, want a request error", got)
+	}
+	if got := s.Execute(context.Background(), Request{Query: document, OperationName: "prices"}); got.Data != nil || len(got.Errors) != 1 {
+		t.Errorf("unknown operationName: %+v, want a request error", got)
+	}
+}
+
+func TestExecuteRequestErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "syntax error", query: "{ products {", want: "unexpected end of document"},
+		{name: "unknown root field", query: "{ orders { totalCount } }", want: `cannot query field "orders" on type "Query"`},
+		{name: "mutation field queried", query: "{ addToCart { totalQuantity } }", want: `cannot query field "addToCart" on type "Query"`},
+		{name: "unknown argument", query: "{ products(sort: ASC) { totalCount } }", want: `unknown argument "sort" on field "products"`},
+		{name: "unknown fragment", query: "{ products { ...missing } }", want: `unknown fragment "missing"`},
+		{name: "fragment cycle", query: "{ products { ...a } } fragment a on ListProductsResponse { ...b } fragment b on ListProductsResponse { ...a }", want: `fragment "a" spreads itself`},
+		{name: "unknown directive", query: "{ products @cached { totalCount } }", want: "unknown directive @cached"},
+		{name: "directive without if", query: "{ products @skip { totalCount } }", want: `@skip requires a Boolean "if" argument`},
+		{name: "conflicting aliases", query: "{ products { count: totalCount count: nextCursor } }", want: `"count" selects both "totalCount" and "nextCursor"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := newTestSchema().Execute(context.Background(), Request{Query: tt.query})
+			if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.want) {
+				t.Fatalf("errors = %+v, want one containing %q", resp.Errors, tt.want)
+			}
+			// Nested selections are checked as the field is projected, so only root level errors drop the data
+			if resp.Data != nil && resp.Data.values["products"] != nil {
+				t.Errorf("data = %+v, want products null", resp.Data)
+			}
+		})
+	}
+}
+
+func TestExecuteFieldErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "resolver error",
+			query: `{ products { totalCount } product(id: 1) { name } }`,
+			want:  `{"data":{"products":{"totalCount":2},"product":null},"errors":[{"message":"product not found","path":["product"],"extensions":{"code":404}}]}`,
+		},
+		{
+			name:  "unknown nested field",
+			query: `{ products { products { id sku } } }`,
+			want:  `{"data":{"products":null},"errors":[{"message":"cannot query field \"sku\" on type \"Product\"","path":["products","products",0,"sku"]}]}`,
+		},
+		{
+			name:  "selection on a scalar",
+			query: `{ products { totalCount { value } } }`,
+			want:  `{"data":{"products":null},"errors":[{"message":"field \"totalCount\" is a scalar and cannot have a selection","path":["products","totalCount"]}]}`,
+		},
+		{
+			name:  "object without selection",
+			query: `{ products }`,
+			want:  `{"data":{"products":null},"errors":[{"message":"field \"products\" of type \"ListProductsResponse\" must have a selection of subfields","path":["products"]}]}`,
+		},
+		{
+			name:  "arguments on a nested field",
+			query: `{ products { totalCount(max: 1) } }`,
+			want:  `{"data":{"products":null},"errors":[{"message":"field \"totalCount\" takes no arguments","path":["products","totalCount"]}]}`,
+		},
+		{
+			name:  "argument of the wrong type",
+			query: `mutation { addToCart(productId: true) { totalQuantity } }`,
+			want:  `{"data":{"addToCart":null},"errors":[{"message":"argument \"productId\" must be an integer","path":["addToCart"]}]}`,
+		},
+		{
+			name:  "nil message",
+			query: `{ missing { id } }`,
+			want:  `{"data":{"missing":null}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTestSchema().execute(t, tt.query, nil); got != tt.want {
+				t.Errorf("response\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteLimits(t *testing.T) {
+	roots := func(n int) string {
+		var b strings.Builder
+		for i := range n {
+			fmt.Fprintf(&b, " p%d: products { totalCount }", i)
+		}
+		return "{" + b.String() + " }"
+	}
+	nested := func(levels int) string {
+		return "{ products " + strings.Repeat("{ products ", levels-1) + "{ id }" + strings.Repeat(" }", levels-1) + " }"
+	}
+	// Each fragment spreads the next twice, so the ten of them select id 1024 times
+	var doubling strings.Builder
+	doubling.WriteString("{ products { products { ...f0 } } }")
+	for i := range 10 {
+		fmt.Fprintf(&doubling, " fragment f%d on Product { ...f%d ...f%d }", i, i+1, i+1)
+	}
+	doubling.WriteString(" fragment f10 on Product { id }")
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "root fields", query: roots(21), want: "at most 20 root fields may be selected, got 21"},
+		{name: "depth", query: nested(10), want: "selections may be nested at most 10 levels deep"},
+		{name: "fields", query: "{ products { products {" + strings.Repeat(" id", 499) + " } } }", want: "at most 500 fields may be selected"},
+		{name: "fragment spreads", query: doubling.String(), want: "at most 500 fields may be selected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := newTestSchema().Execute(context.Background(), Request{Query: tt.query})
+			if resp.Data != nil || len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.want) {
+				t.Fatalf("response %+v, want only an error containing %q", resp, tt.want)
+			}
+		})
+	}
+
+	t.Run("within the limits", func(t *testing.T) {
+		for _, query := range []string{
+			roots(20),
+			"{ products { products {" + strings.Repeat(" id", 498) + " } } }",
+		} {
+			if resp := newTestSchema().Execute(context.Background(), Request{Query: query}); len(resp.Errors) > 0 {
+				t.Errorf("errors %+v for a request within the limits", resp.Errors)
+			}
+		}
+		// Nine levels pass the limit and fail on the schema instead
+		resp := newTestSchema().Execute(context.Background(), Request{Query: nested(9)})
+		if len(resp.Errors) != 1 || strings.Contains(resp.Errors[0].Message, "nested") {
+			t.Errorf("errors %+v, want only a schema error", resp.Errors)
+		}
+	})
+}
@@ -0,0 +1,767 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document is a parsed GraphQL request document
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation is a query or mutation; the shorthand { ... } form is a query
+type Operation struct {
+	Type       string
+	Name       string
+	Variables  []*VariableDefinition
+	Selections []Selection
+}
+
+// VariableDefinition declares $Name. Only whether the type is non-null matters, as arguments are
+// coerced by the resolvers that read them.
+type VariableDefinition struct {
+	Name       string
+	NonNull    bool
+	Default    Value
+	HasDefault bool
+}
+
+// Fragment is a named fragment definition
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Selections    []Selection
+}
+
+// Selection is a *Field, *FragmentSpread or *InlineFragment
+type Selection interface {
+	directives() []*Directive
+}
+
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  []*Argument
+	Directives []*Directive
+	Selections []Selection
+}
+
+// ResponseKey is the name the field's value is returned under
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+}
+
+func (f *Field) directives() []*Directive          { return f.Directives }
+func (f *FragmentSpread) directives() []*Directive { return f.Directives }
+func (f *InlineFragment) directives() []*Directive { return f.Directives }
+
+type Argument struct {
+	Name  string
+	Value Value
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+}
+
+// Value is an argument value: a Variable, a []Value list, an ObjectValue, an EnumValue, or a
+// json.Number, string, bool or nil literal
+type Value any
+
+// Variable refers to $Name
+type Variable string
+
+// EnumValue is a bare enum name such as ASC
+type EnumValue string
+
+type ObjectField struct {
+	Name  string
+	Value Value
+}
+
+// ObjectValue keeps the fields of an input object in the order they were written
+type ObjectValue []ObjectField
+
+// Location is a 1-based position in the document
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// Parse parses a GraphQL document. Only executable definitions, operations and fragments, are allowed.
+func Parse(source string) (*Document, error) {
+	p := &parser{source: strings.TrimPrefix(source, "\ufeff")}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Fragments: make(map[string]*Fragment)}
+	for p.tok.kind != tokenEOF {
+		if p.tok.kind == tokenName && p.tok.value == "fragment" {
+			fragment, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.Fragments[fragment.Name]; ok {
+				return nil, p.errorf("fragment %q is defined more than once", fragment.Name)
+			}
+			doc.Fragments[fragment.Name] = fragment
+			continue
+		}
+
+		operation, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		doc.Operations = append(doc.Operations, operation)
+	}
+	if len(doc.Operations) == 0 {
+		return nil, &Error{Message: "document contains no operation"}
+	}
+	return doc, nil
+}
+
+type parser struct {
+	source string
+	offset int
+	tok    token
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	operation := &Operation{Type: "query"}
+	if p.tok.kind == tokenPunct && p.tok.value == "{" {
+		selections, err := p.parseSelectionSet()
+		if err != nil {
+			return nil, err
+		}
+		operation.Selections = selections
+		return operation, nil
+	}
+
+	if p.tok.kind != tokenName {
+		return nil, p.unexpected()
+	}
+	switch p.tok.value {
+	case "query", "mutation":
+		operation.Type = p.tok.value
+	case "subscription":
+		return nil, p.errorf("subscriptions are not supported")
+	default:
+		return nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokenName {
+		operation.Name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("(") {
+		variables, err := p.parseVariableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		operation.Variables = variables
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	operation.Selections = selections
+	return operation, nil
+}
+
+func (p *parser) parseVariableDefinitions() ([]*VariableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var variables []*VariableDefinition
+	for !p.peek(")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		nonNull, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+
+		variable := &VariableDefinition{Name: name, NonNull: nonNull}
+		if p.peek("=") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if variable.Default, err = p.parseValue(true); err != nil {
+				return nil, err
+			}
+			variable.HasDefault = true
+		}
+		if _, err := p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		variables = append(variables, variable)
+	}
+	return variables, p.advance()
+}
+
+// parseType skips a type reference such as [ID!]! and reports whether it is non-null
+func (p *parser) parseType() (bool, error) {
+	if p.peek("[") {
+		if err := p.advance(); err != nil {
+			return false, err
+		}
+		if _, err := p.parseType(); err != nil {
+			return false, err
+		}
+		if err := p.expect("]"); err != nil {
+			return false, err
+		}
+	} else if _, err := p.parseName(); err != nil {
+		return false, err
+	}
+
+	if p.peek("!") {
+		return true, p.advance()
+	}
+	return false, nil
+}
+
+func (p *parser) parseFragment() (*Fragment, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, p.errorf("a fragment cannot be named \"on\"")
+	}
+	if p.tok.kind != tokenName || p.tok.value != "on" {
+		return nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	typeCondition, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &Fragment{Name: name, TypeCondition: typeCondition, Selections: selections}, nil
+}
+
+func (p *parser) parseSelectionSet() ([]Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []Selection
+	for !p.peek("}") {
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	if len(selections) == 0 {
+		return nil, p.errorf("selection set cannot be empty")
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) parseSelection() (Selection, error) {
+	if p.peek("...") {
+		return p.parseFragmentSelection()
+	}
+
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	field := &Field{Name: name}
+	if p.peek(":") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		field.Alias = name
+		if field.Name, err = p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("(") {
+		if field.Arguments, err = p.parseArguments(); err != nil {
+			return nil, err
+		}
+	}
+	if field.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if field.Selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+func (p *parser) parseFragmentSelection() (Selection, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &FragmentSpread{Name: p.tok.value}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		spread.Directives, err = p.parseDirectives()
+		return spread, err
+	}
+
+	inline := &InlineFragment{}
+	if p.tok.kind == tokenName {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if inline.TypeCondition, err = p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if inline.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if inline.Selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return inline, nil
+}
+
+func (p *parser) parseArguments() ([]*Argument, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var arguments []*Argument
+	for !p.peek(")") {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue(false)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, &Argument{Name: name, Value: value})
+	}
+	if len(arguments) == 0 {
+		return nil, p.errorf("argument list cannot be empty")
+	}
+	return arguments, p.advance()
+}
+
+func (p *parser) parseDirectives() ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek("@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		directive := &Directive{Name: name}
+		if p.peek("(") {
+			if directive.Arguments, err = p.parseArguments(); err != nil {
+				return nil, err
+			}
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+// parseValue parses a value; constant values, such as variable defaults, cannot refer to variables
+func (p *parser) parseValue(constant bool) (Value, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt, tokenFloat:
+		return json.Number(tok.value), p.advance()
+	case tokenString:
+		return tok.value, p.advance()
+	case tokenName:
+		var value Value
+		switch tok.value {
+		case "true", "false":
+			value = tok.value == "true"
+		case "null":
+			value = nil
+		default:
+			value = EnumValue(tok.value)
+		}
+		return value, p.advance()
+	case tokenPunct:
+		switch tok.value {
+		case "$":
+			if constant {
+				return nil, p.errorf("variables are not allowed here")
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, err := p.parseName()
+			return Variable(name), err
+		case "[":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			list := []Value{}
+			for !p.peek("]") {
+				item, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, item)
+			}
+			return list, p.advance()
+		case "{":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			object := ObjectValue{}
+			for !p.peek("}") {
+				name, err := p.parseName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				value, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				object = append(object, ObjectField{Name: name, Value: value})
+			}
+			return object, p.advance()
+		}
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peek(punct) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return p.errorf("unexpected end of document")
+	}
+	return p.errorf("unexpected %q", p.tok.value)
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &Error{Message: "syntax error: " + fmt.Sprintf(format, args...), Locations: []Location{p.location(p.tok.pos)}}
+}
+
+func (p *parser) location(pos int) Location {
+	before := p.source[:pos]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return Location{Line: line, Column: column}
+}
+
+// advance reads the next token, skipping whitespace, commas and comments, which GraphQL ignores
+func (p *parser) advance() error {
+	src := p.source
+	for p.offset < len(src) {
+		c := src[p.offset]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.offset++
+			continue
+		}
+		if c == '#' {
+			for p.offset < len(src) && src[p.offset] != '\n' && src[p.offset] != '\r' {
+				p.offset++
+			}
+			continue
+		}
+		break
+	}
+
+	start := p.offset
+	p.tok = token{kind: tokenEOF, pos: start}
+	if start >= len(src) {
+		return nil
+	}
+
+	c := src[start]
+	switch {
+	case strings.HasPrefix(src[start:], "..."):
+		p.offset += 3
+		p.tok = token{kind: tokenPunct, value: "...", pos: start}
+		return nil
+	case strings.IndexByte("!$()[]{}:=@|&", c) >= 0:
+		p.offset++
+		p.tok = token{kind: tokenPunct, value: string(c), pos: start}
+		return nil
+	case c == '_' || isLetter(c):
+		for p.offset < len(src) && (src[p.offset] == '_' || isLetter(src[p.offset]) || isDigit(src[p.offset])) {
+			p.offset++
+		}
+		p.tok = token{kind: tokenName, value: src[start:p.offset], pos: start}
+		return nil
+	case c == '-' || isDigit(c):
+		return p.lexNumber()
+	case c == '"':
+		return p.lexString()
+	}
+	return p.errorf("unexpected character %q", rune(c))
+}
+
+func (p *parser) lexNumber() error {
+	src := p.source
+	start := p.offset
+	kind := tokenInt
+	if src[p.offset] == '-' {
+		p.offset++
+	}
+	digits := p.offset
+	for p.offset < len(src) && isDigit(src[p.offset]) {
+		p.offset++
+	}
+	if p.offset == digits || (src[digits] == '0' && p.offset-digits > 1) {
+		p.tok.pos = start
+		return p.errorf("invalid number %q", src[start:p.offset])
+	}
+	if p.offset < len(src) && src[p.offset] == '.' {
+		kind = tokenFloat
+		p.offset++
+		fraction := p.offset
+		for p.offset < len(src) && isDigit(src[p.offset]) {
+			p.offset++
+		}
+		if p.offset == fraction {
+			p.tok.pos = start
+			return p.errorf("invalid number %q", src[start:p.offset])
+		}
+	}
+	if p.offset < len(src) && (src[p.offset] == 'e' || src[p.offset] == 'E') {
+		kind = tokenFloat
+		p.offset++
+		if p.offset < len(src) && (src[p.offset] == '+' || src[p.offset] == '-') {
+			p.offset++
+		}
+		exponent := p.offset
+		for p.offset < len(src) && isDigit(src[p.offset]) {
+			p.offset++
+		}
+		if p.offset == exponent {
+			p.tok.pos = start
+			return p.errorf("invalid number %q", src[start:p.offset])
+		}
+	}
+	if p.offset < len(src) && (src[p.offset] == '_' || src[p.offset] == '.' || isLetter(src[p.offset])) {
+		p.tok.pos = start
+		return p.errorf("invalid number %q", src[start:p.offset+1])
+	}
+	p.tok = token{kind: kind, value: src[start:p.offset], pos: start}
+	return nil
+}
+
+func (p *parser) lexString() error {
+	src := p.source
+	start := p.offset
+	if strings.HasPrefix(src[start:], `"""`) {
+		end := strings.Index(src[start+3:], `"""`)
+		for end >= 0 && strings.HasSuffix(src[start+3:start+3+end], `\`) {
+			next := strings.Index(src[start+3+end+3:], `"""`)
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += 3 + next
+		}
+		if end < 0 {
+			p.tok.pos = start
+			return p.errorf("unterminated string")
+		}
+		raw := strings.ReplaceAll(src[start+3:start+3+end], `\"""`, `"""`)
+		p.offset = start + 3 + end + 3
+		p.tok = token{kind: tokenString, value: blockStringValue(raw), pos: start}
+		return nil
+	}
+
+	var b strings.Builder
+	p.offset++
+	for p.offset < len(src) {
+		c := src[p.offset]
+		switch {
+		case c == '"':
+			p.offset++
+			p.tok = token{kind: tokenString, value: b.String(), pos: start}
+			return nil
+		case c == '\n' || c == '\r':
+			p.tok.pos = start
+			return p.errorf("unterminated string")
+		case c == '\\':
+			if p.offset+1 >= len(src) {
+				p.tok.pos = start
+				return p.errorf("unterminated string")
+			}
+			escape := src[p.offset+1]
+			p.offset += 2
+			switch escape {
+			case '"', '\\', '/':
+				b.WriteByte(escape)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if p.offset+4 > len(src) {
+					p.tok.pos = start
+					return p.errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(src[p.offset:p.offset+4], 16, 32)
+				if err != nil {
+					p.tok.pos = start
+					return p.errorf("invalid unicode escape")
+				}
+				b.WriteRune(rune(code))
+				p.offset += 4
+			default:
+				p.tok.pos = start
+				return p.errorf("invalid escape \\%c", escape)
+			}
+		default:
+			b.WriteByte(c)
+			p.offset++
+		}
+	}
+	p.tok.pos = start
+	return p.errorf("unterminated string")
+}
+
+// blockStringValue strips the common indentation and surrounding blank lines of a """block string"""
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\r", "\n"), "\n")
+
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
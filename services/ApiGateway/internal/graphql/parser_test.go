@@ -0,0 +1,153 @@
+package graphql
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMalformedDocuments(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "empty", source: "", want: "document contains no operation"},
+		{name: "only a comment", source: "# nothing here", want: "document contains no operation"},
+		{name: "only fragments", source: "fragment F on Query { me { id } }", want: "document contains no operation"},
+		{name: "unclosed selection set", source: "{ me { id }", want: "unexpected end of document"},
+		{name: "empty selection set", source: "{ }", want: "selection set cannot be empty"},
+		{name: "empty argument list", source: "{ product() { id } }", want: "argument list cannot be empty"},
+		{name: "argument without value", source: "{ product(id: ) { id } }", want: `unexpected ")"`},
+		{name: "missing colon", source: "{ product(id 1) { id } }", want: `unexpected "1"`},
+		{name: "unknown keyword", source: "select { me { id } }", want: `unexpected "select"`},
+		{name: "subscription", source: "subscription { me { id } }", want: "subscriptions are not supported"},
+		{name: "leading zero", source: "{ product(id: 01) { id } }", want: `invalid number "01"`},
+		{name: "fraction without digits", source: "{ product(id: 1.) { id } }", want: `invalid number "1."`},
+		{name: "exponent without digits", source: "{ product(id: 1e) { id } }", want: `invalid number "1e"`},
+		{name: "number running into a name", source: "{ product(id: 1x) { id } }", want: `invalid number "1x"`},
+		{name: "unterminated string", source: `{ product(id: "1) { id } }`, want: "unterminated string"},
+		{name: "string across lines", source: "{ product(id: \"1\n\") { id } }", want: "unterminated string"},
+		{name: "unterminated block string", source: `{ product(id: """1) { id } }`, want: "unterminated string"},
+		{name: "invalid escape", source: `{ product(id: "\q") { id } }`, want: `invalid escape \q`},
+		{name: "invalid unicode escape", source: `{ product(id: "\u12") { id } }`, want: "invalid unicode escape"},
+		{name: "unexpected character", source: "{ me { id } } %", want: `unexpected character '%'`},
+		{name: "variable in a default", source: "query ($a: Int = $b) { me { id } }", want: "variables are not allowed here"},
+		{name: "variable without type", source: "query ($id) { me { id } }", want: `unexpected ")"`},
+		{name: "fragment named on", source: "fragment on on Query { me { id } } { me { id } }", want: `a fragment cannot be named "on"`},
+		{name: "fragment without type condition", source: "fragment F { id } { me { id } }", want: `unexpected "{"`},
+		{name: "fragment defined twice", source: "fragment F on User { id } fragment F on User { name } { me { ...F } }", want: `fragment "F" is defined more than once`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.source)
+			if err == nil {
+				t.Fatalf("Parse succeeded with %+v", doc)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error %q, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrorLocation(t *testing.T) {
+	_, err := Parse("query {\n  me {\n    id,,\n    ?\n  }\n}")
+	gqlErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("error %v, want a *Error", err)
+	}
+	if want := []Location{{Line: 4, Column: 5}}; !reflect.DeepEqual(gqlErr.Locations, want) {
+		t.Errorf("locations = %v, want %v", gqlErr.Locations, want)
+	}
+}
+
+func TestParseDocument(t *testing.T) {
+	doc, err := Parse(`
+		# Both operations and a fragment, commas and comments ignored throughout
+		query Products($page: Int = 2, $status: String!, $ids: [ID!]) @include(if: true) {
+			list: products(page: $page, perPage: 10, cursor: null) {
+				...productFields
+				... on ListProductsResponse @skip(if: false) { totalCount }
+			}
+			myOrders(status: $status, filter: {sort: DESC, ids: [1, "2", 3.5e1], exact: false}) { totalCount }
+		}
+		mutation { addToCart(productId: "7", quantity: 1) { totalQuantity } }
+		fragment productFields on ListProductsResponse { products { id name } }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Operations) != 2 || doc.Operations[0].Type != "query" || doc.Operations[0].Name != "Products" || doc.Operations[1].Type != "mutation" {
+		t.Fatalf("operations = %+v, want the Products query and an unnamed mutation", doc.Operations)
+	}
+	query := doc.Operations[0]
+	wantVariables := []*VariableDefinition{
+		{Name: "page", Default: json.Number("2"), HasDefault: true},
+		{Name: "status", NonNull: true},
+		{Name: "ids"},
+	}
+	if !reflect.DeepEqual(query.Variables, wantVariables) {
+		t.Errorf("variables = %+v, want %+v", query.Variables, wantVariables)
+	}
+
+	list := query.Selections[0].(*Field)
+	if list.Alias != "list" || list.Name != "products" || list.ResponseKey() != "list" {
+		t.Errorf("first field = %+v, want products aliased list", list)
+	}
+	wantArgs := []*Argument{
+		{Name: "page", Value: Variable("page")},
+		{Name: "perPage", Value: json.Number("10")},
+		{Name: "cursor", Value: nil},
+	}
+	if !reflect.DeepEqual(list.Arguments, wantArgs) {
+		t.Errorf("products arguments = %+v, want %+v", list.Arguments, wantArgs)
+	}
+	if spread, ok := list.Selections[0].(*FragmentSpread); !ok || spread.Name != "productFields" {
+		t.Errorf("first selection of products = %+v, want the productFields spread", list.Selections[0])
+	}
+	inline, ok := list.Selections[1].(*InlineFragment)
+	if !ok || inline.TypeCondition != "ListProductsResponse" || len(inline.Directives) != 1 || inline.Directives[0].Name != "skip" {
+		t.Errorf("second selection of products = %+v, want an inline fragment on ListProductsResponse with @skip", list.Selections[1])
+	}
+
+	filter := query.Selections[1].(*Field).Arguments[1].Value
+	wantFilter := ObjectValue{
+		{Name: "sort", Value: EnumValue("DESC")},
+		{Name: "ids", Value: []Value{json.Number("1"), "2", json.Number("3.5e1")}},
+		{Name: "exact", Value: false},
+	}
+	if !reflect.DeepEqual(filter, wantFilter) {
+		t.Errorf("filter = %#v, want %#v", filter, wantFilter)
+	}
+
+	fragment := doc.Fragments["productFields"]
+	if fragment == nil || fragment.TypeCondition != "ListProductsResponse" || len(fragment.Selections) != 1 {
+		t.Errorf("fragment = %+v, want productFields on ListProductsResponse", fragment)
+	}
+}
+
+func TestParseStrings(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "escapes", source: `"tab\there \"quoted\" \\ \/ é"`, want: "tab\there \"quoted\" \\ / é"},
+		{name: "block string", source: "\"\"\"\n    first\n      indented\n\n    last\n  \"\"\"", want: "first\n  indented\n\nlast"},
+		{name: "escaped block quotes", source: `"""say \""" twice"""`, want: `say """ twice`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse("{ product(id: " + tt.source + ") { id } }")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := doc.Operations[0].Selections[0].(*Field).Arguments[0].Value; got != tt.want {
+				t.Errorf("value = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
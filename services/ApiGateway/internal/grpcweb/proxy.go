@@ -0,0 +1,195 @@
+package grpcweb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	reviewpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/review"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExposedMethods are the calls browsers may make over gRPC-Web: the catalog reads that are public on the
+// REST API too. Methods acting for a user are left out, as the proxy does not authenticate callers.
+var ExposedMethods = []string{
+	productpb.ProductService_ListProducts_FullMethodName,
+	productpb.ProductService_GetProductByID_FullMethodName,
+	productpb.ProductService_GetProductsByIDs_FullMethodName,
+	productpb.ProductService_ListCategories_FullMethodName,
+	productpb.ProductService_GetCategoryByID_FullMethodName,
+	reviewpb.ReviewService_ListProductReviews_FullMethodName,
+}
+
+// Proxy lets browsers call backend gRPC methods with gRPC-Web. Only the methods it is given are exposed,
+// and none of the browser's headers are forwarded, so calls reach the backends as the gateway's own
+// service calls and cannot claim a user or an internal token.
+type Proxy struct {
+	web      *grpcweb.WrappedGrpcServer
+	backends map[string]*grpc.ClientConn
+	methods  []string
+}
+
+// NewProxy creates a proxy for methods, full method names such as "/product.ProductService/ListProducts".
+// backends maps full service names to the connection serving them; allowOrigin decides which browser origins
+// may call, as for the REST API.
+func NewProxy(backends map[string]*grpc.ClientConn, methods []string, allowOrigin func(origin string) bool) *Proxy {
+	p := &Proxy{backends: backends, methods: methods}
+
+	// The server has no services of its own: every call goes to forward with its messages left encoded
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(frameCodec{}),
+		grpc.UnknownServiceHandler(p.forward),
+	)
+	p.web = grpcweb.WrapServer(server,
+		grpcweb.WithAllowNonRootResource(true),
+		grpcweb.WithEndpointsFunc(func() []string { return methods }),
+		grpcweb.WithOriginFunc(allowOrigin),
+	)
+	return p
+}
+
+// ServeHTTP answers gRPC-Web calls and their CORS preflights. Plain HTTP requests are refused.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !p.web.IsGrpcWebRequest(r) && !p.web.IsAcceptableGrpcCorsRequest(r) {
+		writeJSONError(w, http.StatusUnsupportedMediaType, "expected a gRPC-Web request for an exposed method")
+		return
+	}
+	p.web.ServeHTTP(w, r)
+}
+
+func (p *Proxy) forward(_ any, serverStream grpc.ServerStream) error {
+	method, ok := grpc.MethodFromServerStream(serverStream)
+	if !ok || !slices.Contains(p.methods, method) {
+		return status.Errorf(codes.Unimplemented, "%s is not exposed over gRPC-Web", method)
+	}
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	conn, ok := p.backends[service]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "no backend serves %s", service)
+	}
+
+	// Canceling ends the backend call when the browser goes away or the proxy returns early
+	ctx, cancel := context.WithCancel(serverStream.Context())
+	defer cancel()
+
+	desc := &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}
+	clientStream, err := conn.NewStream(ctx, desc, method, grpc.ForceCodec(frameCodec{}))
+	if err != nil {
+		return err
+	}
+
+	sendErr := make(chan error, 1)
+	go func() {
+		sendErr <- forwardRequests(serverStream, clientStream)
+	}()
+	recvErr := make(chan error, 1)
+	go func() {
+		recvErr <- forwardResponses(clientStream, serverStream)
+	}()
+
+	for {
+		select {
+		case err := <-sendErr:
+			if err != nil {
+				logger.Warnf("event=grpc_web_forward_failed method=%s error=%v", method, err)
+				return status.Error(codes.Internal, "failed to forward the request")
+			}
+			// The browser is done sending; keep waiting for the backend's responses
+			sendErr = nil
+		case err := <-recvErr:
+			serverStream.SetTrailer(clientStream.Trailer())
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// forwardRequests copies the browser's messages to the backend and half-closes it after the last one
+func forwardRequests(from grpc.ServerStream, to grpc.ClientStream) error {
+	for {
+		frame := &frame{}
+		if err := from.RecvMsg(frame); err != nil {
+			if errors.Is(err, io.EOF) {
+				return to.CloseSend()
+			}
+			return err
+		}
+		if err := to.SendMsg(frame); err != nil {
+			// The backend's status is reported by forwardResponses
+			return nil
+		}
+	}
+}
+
+// forwardResponses copies the backend's headers and messages to the browser. It returns io.EOF when the
+// call succeeded and the backend's status otherwise.
+func forwardResponses(from grpc.ClientStream, to grpc.ServerStream) error {
+	for i := 0; ; i++ {
+		frame := &frame{}
+		if err := from.RecvMsg(frame); err != nil {
+			return err
+		}
+		if i == 0 {
+			header, err := from.Header()
+			if err != nil {
+				return err
+			}
+			if err := to.SendHeader(header); err != nil {
+				return err
+			}
+		}
+		if err := to.SendMsg(frame); err != nil {
+			return err
+		}
+	}
+}
+
+// frame is a message passed through without decoding
+type frame struct {
+	payload []byte
+}
+
+// frameCodec hands messages over as the bytes on the wire, so the proxy needs no generated types
+type frameCodec struct{}
+
+func (frameCodec) Marshal(v any) ([]byte, error) {
+	f, ok := v.(*frame)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "unexpected message type %T", v)
+	}
+	return f.payload, nil
+}
+
+func (frameCodec) Unmarshal(data []byte, v any) error {
+	f, ok := v.(*frame)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected message type %T", v)
+	}
+	f.payload = append([]byte(nil), data...)
+	return nil
+}
+
+func (frameCodec) Name() string {
+	return "proto"
+}
+
+func writeJSONError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error":   http.StatusText(statusCode),
+		"message": message,
+		"code":    statusCode,
+	})
+}
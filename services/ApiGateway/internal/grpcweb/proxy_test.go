@@ -0,0 +1,146 @@
+package grpcweb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// catalogServer knows product 1 and keeps the metadata of the last call it got
+type catalogServer struct {
+	productpb.UnimplementedProductServiceServer
+	md      metadata.MD
+	created bool
+}
+
+func (s *catalogServer) GetProductByID(ctx context.Context, in *productpb.GetProductByIDRequest) (*productpb.GetProductByIDResponse, error) {
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	if in.GetId() != 1 {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	return &productpb.GetProductByIDResponse{Product: &productpb.Product{Id: 1, Name: "Lamp"}}, nil
+}
+
+func (s *catalogServer) CreateProduct(context.Context, *productpb.CreateProductRequest) (*productpb.CreateProductResponse, error) {
+	s.created = true
+	return &productpb.CreateProductResponse{}, nil
+}
+
+// newTestProxy exposes ExposedMethods of srv, served over an in-memory connection, to browsers from example.com
+func newTestProxy(t *testing.T, srv *catalogServer) *Proxy {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	productpb.RegisterProductServiceServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	backends := map[string]*grpc.ClientConn{productpb.ProductService_ServiceDesc.ServiceName: conn}
+	return NewProxy(backends, ExposedMethods, func(origin string) bool { return origin == "https://example.com" })
+}
+
+// callWeb sends msg to method as a browser would over gRPC-Web, with header added, and returns the response
+// message and the grpc-status, from the headers or the trailer frame
+func callWeb(t *testing.T, proxy http.Handler, method string, msg proto.Message, header http.Header) ([]byte, string) {
+	t.Helper()
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(payload)))
+	body = append(body, payload...)
+
+	r := httptest.NewRequest(http.MethodPost, "/grpc"+method, bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/grpc-web+proto")
+	r.Header.Set("Origin", "https://example.com")
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	proxy.ServeHTTP(w, r)
+
+	var message []byte
+	grpcStatus := w.Header().Get("Grpc-Status")
+	rest := w.Body.Bytes()
+	for len(rest) >= 5 {
+		flag, size := rest[0], binary.BigEndian.Uint32(rest[1:5])
+		data := rest[5 : 5+size]
+		rest = rest[5+size:]
+		if flag&0x80 == 0 {
+			message = data
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\r\n") {
+			if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "grpc-status") {
+				grpcStatus = strings.TrimSpace(value)
+			}
+		}
+	}
+	return message, grpcStatus
+}
+
+func TestProxyForwardsExposedMethods(t *testing.T) {
+	srv := &catalogServer{}
+	proxy := newTestProxy(t, srv)
+
+	// The browser's credentials and identity headers stay at the proxy
+	header := http.Header{"Authorization": {"Bearer token"}, "X-User-Id": {"7"}, "X-Internal-Token": {"guess"}}
+	message, grpcStatus := callWeb(t, proxy, productpb.ProductService_GetProductByID_FullMethodName, &productpb.GetProductByIDRequest{Id: 1}, header)
+	if grpcStatus != "0" {
+		t.Fatalf("grpc-status = %q, want 0", grpcStatus)
+	}
+	var resp productpb.GetProductByIDResponse
+	if err := proto.Unmarshal(message, &resp); err != nil || resp.GetProduct().GetName() != "Lamp" {
+		t.Fatalf("response %v, %v, want product 1", &resp, err)
+	}
+	for _, name := range []string{"authorization", "x-user-id", "x-internal-token"} {
+		if values := srv.md.Get(name); len(values) > 0 {
+			t.Errorf("the backend got %s: %v", name, values)
+		}
+	}
+
+	if _, grpcStatus := callWeb(t, proxy, productpb.ProductService_GetProductByID_FullMethodName, &productpb.GetProductByIDRequest{Id: 2}, nil); grpcStatus != "5" {
+		t.Errorf("grpc-status = %q for a missing product, want the backend's NotFound (5)", grpcStatus)
+	}
+}
+
+func TestProxyRefusesOtherMethods(t *testing.T) {
+	srv := &catalogServer{}
+	proxy := newTestProxy(t, srv)
+
+	_, grpcStatus := callWeb(t, proxy, productpb.ProductService_CreateProduct_FullMethodName, &productpb.CreateProductRequest{Name: "Lamp"}, nil)
+	if grpcStatus != "12" || srv.created {
+		t.Errorf("grpc-status = %q, created = %v; want Unimplemented (12) without reaching the backend", grpcStatus, srv.created)
+	}
+
+	w := httptest.NewRecorder()
+	proxy.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/grpc"+productpb.ProductService_ListProducts_FullMethodName, nil))
+	if body, _ := io.ReadAll(w.Body); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("plain HTTP: got %d %s, want 415", w.Code, body)
+	}
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	"google.golang.org/grpc/connectivity"
+)
+
+// ServiceConnections reports and resets the gateway's connections to the backend services, keyed by
+// service name: user, product, cart, order and notification
+type ServiceConnections interface {
+	HealthStatus() map[string]connectivity.State
+	ResetConnectBackoff(service string) bool
+}
+
+// ConfigReloader loads the gateway's configuration again and applies the settings that can change while
+// it runs, returning the changed settings it applied and those that need a restart
+type ConfigReloader interface {
+	Reload() (applied, restartRequired []string, err error)
+}
+
+// AdminHandler handles operational admin HTTP requests
+type AdminHandler struct {
+	maintenance middleware.MaintenanceFlagStore
+	connections ServiceConnections
+	reloader    ConfigReloader
+}
+
+// LogLevelRequest selects the new minimum log level
+type LogLevelRequest struct {
+	Level string `json:"level" example:"debug"`
+}
+
+// LogLevelResponse reports the log level, and the one it replaced after a change
+type LogLevelResponse struct {
+	Level         string `json:"level"`
+	PreviousLevel string `json:"previous_level,omitempty"`
+}
+
+// MaintenanceRequest optionally bounds a maintenance window; without a duration it lasts until disabled
+type MaintenanceRequest struct {
+	Duration string `json:"duration,omitempty" example:"30m"`
+}
+
+// MaintenanceResponse reports the maintenance state after a change
+type MaintenanceResponse struct {
+	Enabled bool   `json:"enabled"`
+	EndsAt  string `json:"ends_at,omitempty"`
+}
+
+// ServiceReconnectResponse reports a service connection's state right after its backoff was reset
+type ServiceReconnectResponse struct {
+	Service string `json:"service" example:"order"`
+	State   string `json:"state" example:"CONNECTING"`
+}
+
+// ConfigReloadResponse lists the changed settings by variable name: applied ones are in effect now, the
+// others once the gateway restarts
+type ConfigReloadResponse struct {
+	Applied         []string `json:"applied" example:"ALLOWED_ORIGINS,RATE_LIMIT_REQUESTS"`
+	RestartRequired []string `json:"restart_required" example:"APP_PORT"`
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(maintenance middleware.MaintenanceFlagStore, connections ServiceConnections, reloader ConfigReloader) *AdminHandler {
+	return &AdminHandler{maintenance: maintenance, connections: connections, reloader: reloader}
+}
+
+// GetLogLevel godoc
+// @Summary Get log level
+// @Description Current minimum log level of the gateway (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} LogLevelResponse
+// @Router /api/v1/admin/log-level [get]
+func (h *AdminHandler) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, LogLevelResponse{Level: strings.ToLower(logger.Level().String())})
+}
+
+// SetLogLevel godoc
+// @Summary Set log level
+// @Description Change the gateway's minimum log level until restart: debug, info, warn or error (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body LogLevelRequest true "New level"
+// @Success 200 {object} LogLevelResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/admin/log-level [put]
+func (h *AdminHandler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req LogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	previous := logger.SetLevel(level)
+	logger.Infof("event=log_level_changed previous=%s level=%s", previous, level)
+
+	writeJSON(w, http.StatusOK, LogLevelResponse{
+		Level:         strings.ToLower(level.String()),
+		PreviousLevel: strings.ToLower(previous.String()),
+	})
+}
+
+// EnableMaintenance godoc
+// @Summary Enable maintenance mode
+// @Description Answer 503 to every route except health checks and admin routes called by admins, on every gateway instance (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MaintenanceRequest false "Optional duration, e.g. 30m"
+// @Success 200 {object} MaintenanceResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/admin/maintenance/enable [post]
+func (h *AdminHandler) EnableMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var duration time.Duration
+	if req.Duration != "" {
+		var err error
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "duration must be a positive duration such as 30m")
+			return
+		}
+	}
+
+	if err := h.maintenance.SetMaintenance(r.Context(), true, duration); err != nil {
+		logger.Errorf("event=maintenance_change_failed enabled=true error=%v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to enable maintenance mode")
+		return
+	}
+	logger.Infof("event=maintenance_enabled duration=%s", duration)
+
+	response := MaintenanceResponse{Enabled: true}
+	if duration > 0 {
+		response.EndsAt = time.Now().Add(duration).UTC().Format(time.RFC3339)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// DisableMaintenance godoc
+// @Summary Disable maintenance mode
+// @Description Serve every route again (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} MaintenanceResponse
+// @Router /api/v1/admin/maintenance/disable [post]
+func (h *AdminHandler) DisableMaintenance(w http.ResponseWriter, r *http.Request) {
+	if err := h.maintenance.SetMaintenance(r.Context(), false, 0); err != nil {
+		logger.Errorf("event=maintenance_change_failed enabled=false error=%v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to disable maintenance mode")
+		return
+	}
+	logger.Info("event=maintenance_disabled")
+
+	writeJSON(w, http.StatusOK, MaintenanceResponse{Enabled: false})
+}
+
+// GetServiceConnectivity godoc
+// @Summary Get service connection states
+// @Description State of the gateway's gRPC connection to each service: IDLE, CONNECTING, READY, TRANSIENT_FAILURE or SHUTDOWN (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Router /api/v1/admin/services/status [get]
+func (h *AdminHandler) GetServiceConnectivity(w http.ResponseWriter, r *http.Request) {
+	states := h.connections.HealthStatus()
+	response := make(map[string]string, len(states))
+	for service, state := range states {
+		response[service] = state.String()
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// ReconnectService godoc
+// @Summary Reconnect to a service
+// @Description Retry the connection to a service now instead of after its reconnection backoff (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Service name: user, product, cart, order or notification"
+// @Success 200 {object} ServiceReconnectResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/services/{name}/reconnect [post]
+func (h *AdminHandler) ReconnectService(c *gin.Context) {
+	service := c.Param("name")
+	if !h.connections.ResetConnectBackoff(service) {
+		writeJSONError(c.Writer, http.StatusNotFound, "unknown service "+service)
+		return
+	}
+
+	state := h.connections.HealthStatus()[service]
+	logger.Infof("event=service_reconnect service=%s state=%s", service, state)
+	writeJSON(c.Writer, http.StatusOK, ServiceReconnectResponse{Service: service, State: state.String()})
+}
+
+// ReloadConfig godoc
+// @Summary Reload configuration
+// @Description Read the environment and .env file again, as SIGHUP does, and apply the changed CORS, block list, rate limit and timeout settings (ALLOWED_ORIGINS, ALLOWED_ORIGIN_PATTERNS, ALLOWED_METHODS, ALLOWED_HEADERS, ALLOW_CREDENTIALS, BLOCKED_CIDRS, BLOCKED_PATH_PREFIXES, RATE_LIMIT_REQUESTS, RATE_LIMIT_WINDOW_SECONDS, REQUEST_TIMEOUT_SECONDS, ROUTE_TIMEOUTS_JSON). Other changed settings are listed as needing a restart. An invalid configuration changes nothing (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ConfigReloadResponse
+// @Failure 422 {object} map[string]string
+// @Router /api/v1/admin/config/reload [post]
+func (h *AdminHandler) ReloadConfig(w http.ResponseWriter, r *http.Request) {
+	applied, restartRequired, err := h.reloader.Reload()
+	if err != nil {
+		logger.Warnf("event=config_reload_failed trigger=admin error=%v", err)
+		writeJSONError(w, http.StatusUnprocessableEntity, "invalid configuration: "+err.Error())
+		return
+	}
+
+	// Empty lists rather than null, so clients can always iterate them
+	response := ConfigReloadResponse{Applied: []string{}, RestartRequired: []string{}}
+	response.Applied = append(response.Applied, applied...)
+	response.RestartRequired = append(response.RestartRequired, restartRequired...)
+	writeJSON(w, http.StatusOK, response)
+}
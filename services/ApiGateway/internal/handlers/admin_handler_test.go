@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	"google.golang.org/grpc/connectivity"
+)
+
+// captureStructuredLogs makes a production structured logger, which starts at info, the default for the rest
+// of the test and returns a func reading what it wrote so far
+func captureStructuredLogs(t *testing.T) func() string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdout.log")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	// The handler holds on to os.Stdout as it is when the logger is made
+	stdout, previousDefault, previousLevel := os.Stdout, slog.Default(), logger.Level()
+	os.Stdout = file
+	slog.SetDefault(logger.NewStructuredWithFormat("production", logger.FormatJSON))
+	os.Stdout = stdout
+	t.Cleanup(func() {
+		slog.SetDefault(previousDefault)
+		logger.SetLevel(previousLevel)
+	})
+
+	return func() string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+}
+
+func TestSetLogLevelAppliesToLaterRequests(t *testing.T) {
+	logs := captureStructuredLogs(t)
+	h := NewAdminHandler(nil, nil, nil)
+
+	engine := gin.New()
+	engine.GET("/log-level", wrap(h.GetLogLevel))
+	engine.PUT("/log-level", wrap(h.SetLogLevel))
+	engine.GET("/ping", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Debug("ping_handled")
+		c.Status(http.StatusNoContent)
+	})
+	send := func(method, target, body string) (int, string) {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w.Code, strings.TrimSpace(w.Body.String())
+	}
+
+	send(http.MethodGet, "/ping", "")
+	if strings.Contains(logs(), "ping_handled") {
+		t.Fatal("a debug message was logged at the info level")
+	}
+
+	if code, body := send(http.MethodPut, "/log-level", `{"level":"debug"}`); code != http.StatusOK || body != `{"level":"debug","previous_level":"info"}` {
+		t.Fatalf("PUT = %d %s, want the new and previous level", code, body)
+	}
+	send(http.MethodGet, "/ping", "")
+	if !strings.Contains(logs(), `"msg":"ping_handled"`) {
+		t.Fatalf("the request after the change did not log at debug level; logs:\n%s", logs())
+	}
+	if code, body := send(http.MethodGet, "/log-level", ""); code != http.StatusOK || body != `{"level":"debug"}` {
+		t.Fatalf("GET = %d %s, want debug", code, body)
+	}
+
+	for _, body := range []string{`{"level":"trace"}`, `{"level":`} {
+		if code, _ := send(http.MethodPut, "/log-level", body); code != http.StatusBadRequest {
+			t.Errorf("PUT %s = %d, want 400", body, code)
+		}
+	}
+	if logger.Level() != slog.LevelDebug {
+		t.Errorf("level = %v after invalid requests, want debug kept", logger.Level())
+	}
+}
+
+func TestMaintenanceEndpoints(t *testing.T) {
+	store := middleware.NewRedisMaintenanceStore(nil)
+	h := NewAdminHandler(store, nil, nil)
+	enabled := func() (bool, time.Duration) {
+		on, remaining, err := store.Maintenance(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return on, remaining
+	}
+
+	w := serve(t, testRequest{method: http.MethodPost, route: "/enable", target: "/enable", body: `{"duration": "30m"}`}, wrap(h.EnableMaintenance))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"enabled":true`) || !strings.Contains(w.Body.String(), `"ends_at"`) {
+		t.Fatalf("enable for 30m: got %d %s", w.Code, w.Body)
+	}
+	if on, remaining := enabled(); !on || remaining <= 29*time.Minute || remaining > 30*time.Minute {
+		t.Fatalf("maintenance on %v with %v left, want on for 30m", on, remaining)
+	}
+
+	w = serve(t, testRequest{method: http.MethodPost, route: "/disable", target: "/disable"}, wrap(h.DisableMaintenance))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"enabled":false}` {
+		t.Fatalf("disable: got %d %s", w.Code, w.Body)
+	}
+	if on, _ := enabled(); on {
+		t.Fatal("maintenance is still on after disabling it")
+	}
+
+	// Without a body maintenance lasts until disabled
+	w = serve(t, testRequest{method: http.MethodPost, route: "/enable", target: "/enable"}, wrap(h.EnableMaintenance))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"enabled":true}` {
+		t.Fatalf("enable: got %d %s", w.Code, w.Body)
+	}
+	if on, remaining := enabled(); !on || remaining != 0 {
+		t.Fatalf("maintenance on %v with %v left, want on without an end", on, remaining)
+	}
+
+	for _, body := range []string{`{"duration": "soon"}`, `{"duration": "-5m"}`, `not json`} {
+		if w := serve(t, testRequest{method: http.MethodPost, route: "/enable", target: "/enable", body: body}, wrap(h.EnableMaintenance)); w.Code != http.StatusBadRequest {
+			t.Errorf("enable with %s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+// fakeServiceConnections reports fixed connection states; resetting a connection's backoff makes it connect
+type fakeServiceConnections struct {
+	states map[string]connectivity.State
+	resets []string
+}
+
+func (f *fakeServiceConnections) HealthStatus() map[string]connectivity.State {
+	return f.states
+}
+
+func (f *fakeServiceConnections) ResetConnectBackoff(service string) bool {
+	if _, ok := f.states[service]; !ok {
+		return false
+	}
+	f.resets = append(f.resets, service)
+	f.states[service] = connectivity.Connecting
+	return true
+}
+
+func TestGetServiceConnectivity(t *testing.T) {
+	connections := &fakeServiceConnections{states: map[string]connectivity.State{
+		"user":         connectivity.Ready,
+		"product":      connectivity.TransientFailure,
+		"cart":         connectivity.Idle,
+		"order":        connectivity.Connecting,
+		"notification": connectivity.Shutdown,
+	}}
+	h := NewAdminHandler(nil, connections, nil)
+
+	w := serve(t, testRequest{method: http.MethodGet, route: "/services/status", target: "/services/status"}, wrap(h.GetServiceConnectivity))
+	want := `{"cart":"IDLE","notification":"SHUTDOWN","order":"CONNECTING","product":"TRANSIENT_FAILURE","user":"READY"}`
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != want {
+		t.Fatalf("got %d %s, want 200 %s", w.Code, w.Body, want)
+	}
+}
+
+func TestReconnectService(t *testing.T) {
+	tests := []struct {
+		name       string
+		service    string
+		wantStatus int
+		want       string
+	}{
+		{name: "known service", service: "product", wantStatus: http.StatusOK, want: `{"service":"product","state":"CONNECTING"}`},
+		{name: "unknown service", service: "inventory", wantStatus: http.StatusNotFound, want: `{"error":"Not Found","message":"unknown service inventory","code":404}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connections := &fakeServiceConnections{states: map[string]connectivity.State{"product": connectivity.TransientFailure}}
+			h := NewAdminHandler(nil, connections, nil)
+
+			w := serve(t, testRequest{method: http.MethodPost, route: "/services/:name/reconnect", target: "/services/" + tt.service + "/reconnect"}, h.ReconnectService)
+			if w.Code != tt.wantStatus || strings.TrimSpace(w.Body.String()) != tt.want {
+				t.Fatalf("got %d %s, want %d %s", w.Code, w.Body, tt.wantStatus, tt.want)
+			}
+			if wantResets := tt.wantStatus == http.StatusOK; (len(connections.resets) == 1) != wantResets {
+				t.Errorf("backoff resets = %v", connections.resets)
+			}
+		})
+	}
+}
+
+// fakeReloader returns a fixed reload outcome
+type fakeReloader struct {
+	applied, restartRequired []string
+	err                      error
+}
+
+func (f fakeReloader) Reload() ([]string, []string, error) {
+	return f.applied, f.restartRequired, f.err
+}
+
+func TestReloadConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		reloader   fakeReloader
+		wantStatus int
+		want       string
+	}{
+		{
+			name:       "applied and left for a restart",
+			reloader:   fakeReloader{applied: []string{"ALLOWED_ORIGINS"}, restartRequired: []string{"APP_PORT"}},
+			wantStatus: http.StatusOK,
+			want:       `{"applied":["ALLOWED_ORIGINS"],"restart_required":["APP_PORT"]}`,
+		},
+		{name: "nothing changed", wantStatus: http.StatusOK, want: `{"applied":[],"restart_required":[]}`},
+		{
+			name:       "invalid configuration",
+			reloader:   fakeReloader{err: errors.New("RATE_LIMIT_REQUESTS must be positive")},
+			wantStatus: http.StatusUnprocessableEntity,
+			want:       `{"error":"Unprocessable Entity","message":"invalid configuration: RATE_LIMIT_REQUESTS must be positive","code":422}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewAdminHandler(nil, nil, tt.reloader)
+			w := serve(t, testRequest{method: http.MethodPost, route: "/config/reload", target: "/config/reload"}, wrap(h.ReloadConfig))
+			if w.Code != tt.wantStatus || strings.TrimSpace(w.Body.String()) != tt.want {
+				t.Fatalf("got %d %s, want %d %s", w.Code, w.Body, tt.wantStatus, tt.want)
+			}
+		})
+	}
+}
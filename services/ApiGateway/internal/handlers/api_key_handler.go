@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/audit"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+)
+
+// APIKeyHandler lets admins issue and revoke API keys for server-to-server consumers
+type APIKeyHandler struct {
+	userClient userpb.UserServiceClient
+	resolver   *middleware.APIKeyResolver
+	auditLog   audit.Sink
+}
+
+// NewAPIKeyHandler creates a new API key handler. Revoked keys are dropped from resolver's cache.
+func NewAPIKeyHandler(userClient userpb.UserServiceClient, resolver *middleware.APIKeyResolver, auditLog audit.Sink) *APIKeyHandler {
+	return &APIKeyHandler{userClient: userClient, resolver: resolver, auditLog: auditLog}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Issue a key that authenticates with the X-API-Key header instead of a JWT, acting as user_id (default: the caller) with scopes as its only permissions. The key is only returned in this response (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAPIKeyRequest true "Key label and scopes"
+// @Success 201 {object} userpb.CreateAPIKeyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(customJWT.AllPermissions, scope) {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("unknown scope %q", scope))
+			return
+		}
+	}
+	if req.UserID == 0 {
+		req.UserID = adminID
+	}
+
+	resp, err := h.userClient.CreateAPIKey(r.Context(), &userpb.CreateAPIKeyRequest{
+		Label:  req.Label,
+		Scopes: req.Scopes,
+		UserId: int32(req.UserID),
+	})
+	if err != nil {
+		logger.Errorf("failed to create api key: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.record(r.Context(), "api_key_created", adminID, map[string]any{
+		"api_key_id": resp.GetApiKey().GetId(),
+		"acts_as":    req.UserID,
+		"scopes":     req.Scopes,
+	})
+	writeProtoJSON(w, http.StatusCreated, resp)
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description Issued keys, newest first and revoked ones included, with when each was last used. The keys themselves are never returned (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Keys per page" default(10)
+// @Success 200 {object} PaginatedResponse
+// @Router /api/v1/admin/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	page, perPage := parsePagination(r)
+
+	resp, err := h.userClient.ListAPIKeys(r.Context(), &userpb.ListAPIKeysRequest{
+		Page:    int32(page),
+		PerPage: int32(perPage),
+	})
+	if err != nil {
+		logger.Errorf("failed to list api keys: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONPaginated(w, http.StatusOK, protoJSONList(resp.GetApiKeys()), newPaginationMeta(r, page, perPage, int(resp.GetTotal())))
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Stop a key from authenticating. This gateway instance refuses it at once, others within API_KEY_CACHE_TTL (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 200 {object} userpb.RevokeAPIKeyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	adminID, ok := middleware.GetUserID(c.Request.Context())
+	if !ok {
+		writeJSONError(c.Writer, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid api key id")
+		return
+	}
+
+	resp, err := h.userClient.RevokeAPIKey(c.Request.Context(), &userpb.RevokeAPIKeyRequest{Id: int32(id)})
+	if err != nil {
+		logger.Errorf("failed to revoke api key: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+	h.resolver.Forget(int32(id))
+
+	h.record(c.Request.Context(), "api_key_revoked", adminID, map[string]any{"api_key_id": id})
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
+}
+
+func (h *APIKeyHandler) record(ctx context.Context, event string, adminID uint, details map[string]any) {
+	err := h.auditLog.Record(audit.Entry{
+		Event:   event,
+		UserID:  adminID,
+		IP:      middleware.GetClientIP(ctx),
+		Outcome: "success",
+		Details: details,
+	})
+	if err != nil {
+		logger.Errorf("event=audit_write_failed user_id=%d outcome=success error=%v", adminID, err)
+	}
+}
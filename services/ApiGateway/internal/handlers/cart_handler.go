@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
@@ -9,35 +8,50 @@ import (
 	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
 )
 
-// CartHandler handles cart-related HTTP requests
+// CartHandler handles cart-related HTTP requests. Authenticated requests use the user's cart; anonymous
+// ones use a guest cart, named by the token AddItem issues.
 type CartHandler struct {
 	cartClient cartpb.CartServiceClient
+	guestCarts *middleware.GuestCarts
 }
 
 // NewCartHandler creates a new cart handler
-func NewCartHandler(cartClient cartpb.CartServiceClient) *CartHandler {
+func NewCartHandler(cartClient cartpb.CartServiceClient, guestCarts *middleware.GuestCarts) *CartHandler {
 	return &CartHandler{
 		cartClient: cartClient,
+		guestCarts: guestCarts,
 	}
 }
 
+// cartOwner is the request's cart: the user's when it is authenticated, or else the guest cart its token
+// names. ok is false for anonymous requests without a valid token.
+func (h *CartHandler) cartOwner(r *http.Request) (userID int64, guestCartID string, ok bool) {
+	if id, ok := middleware.GetUserID(r.Context()); ok {
+		return int64(id), "", true
+	}
+	guestCartID, ok = h.guestCarts.CartID(r)
+	return 0, guestCartID, ok
+}
+
 // GetCart godoc
-// @Summary Get user cart
-// @Description Get the current user's cart
+// @Summary Get cart
+// @Description Get the current user's cart or, without a token, the guest cart named by X-Guest-Cart-Token or the guest_cart cookie. Anonymous visitors without a guest cart get an empty one
 // @Tags cart
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} CartResponse
+// @Param X-Guest-Cart-Token header string false "Guest cart token, for clients that do not keep cookies"
+// @Success 200 {object} cartpb.CartResponse
 // @Router /api/v1/cart [get]
 func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
-	userID, ok := middleware.GetUserID(r.Context())
+	userID, guestCartID, ok := h.cartOwner(r)
 	if !ok {
-		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+		writeProtoJSON(w, http.StatusOK, &cartpb.CartResponse{})
 		return
 	}
 
 	resp, err := h.cartClient.GetCart(r.Context(), &cartpb.GetCartRequest{
-		UserId: int64(userID),
+		UserId:     userID,
+		GuestToken: guestCartID,
 	})
 
 	if err != nil {
@@ -46,40 +60,47 @@ func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeProtoJSON(w, http.StatusOK, resp)
 }
 
 // AddItem godoc
 // @Summary Add item to cart
-// @Description Add a product to the user's cart
+// @Description Add a product to the user's cart. Anonymous visitors add it to their guest cart, which is created on their first item: the response then carries its token in X-Guest-Cart-Token and the guest_cart cookie, to send with later cart requests. Signing in or registering with the token merges the guest cart into the user's
 // @Tags cart
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body AddItemRequest true "Item details"
-// @Success 200 {object} CartResponse
+// @Param X-Guest-Cart-Token header string false "Guest cart token, for clients that do not keep cookies"
+// @Param request body CartItemRequest true "Item details"
+// @Success 200 {object} cartpb.CartResponse
+// @Header 200 {string} X-Guest-Cart-Token "Token of the guest cart, on anonymous requests"
 // @Router /api/v1/cart/items [post]
+// @DeprecatedRouter /api/v1/cart/items/add [post]
 func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
-	userID, ok := middleware.GetUserID(r.Context())
-	if !ok {
-		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
-		return
-	}
+	var req CartItemRequest
 
-	var req struct {
-		ProductID int64 `json:"product_id"`
-		Quantity  int32 `json:"quantity"`
+	if err := decodeJSON(r, &req); err != nil {
+		writeRequestError(w, err)
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid request body")
-		return
+	// An anonymous visitor's first item starts their guest cart
+	userID, guestCartID, ok := h.cartOwner(r)
+	if !ok {
+		var err error
+		guestCartID, err = h.guestCarts.NewCartID()
+		if err != nil {
+			logger.Errorf("failed to create guest cart: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, "failed to create cart")
+			return
+		}
 	}
 
 	resp, err := h.cartClient.AddItem(r.Context(), &cartpb.AddItemRequest{
-		UserId:    int64(userID),
-		ProductId: req.ProductID,
-		Quantity:  req.Quantity,
+		UserId:     userID,
+		GuestToken: guestCartID,
+		ProductId:  req.ProductID,
+		Quantity:   req.Quantity,
 	})
 
 	if err != nil {
@@ -88,40 +109,42 @@ func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	h.keepGuestCart(w, guestCartID)
+	writeProtoJSON(w, http.StatusOK, resp)
 }
 
 // UpdateItem godoc
 // @Summary Update cart item
-// @Description Update the quantity of a cart item
+// @Description Update the quantity of an item in the user's cart or, without a token, the guest cart
 // @Tags cart
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body UpdateItemRequest true "Item update details"
-// @Success 200 {object} CartResponse
+// @Param X-Guest-Cart-Token header string false "Guest cart token, for clients that do not keep cookies"
+// @Param request body CartItemRequest true "Item update details"
+// @Success 200 {object} cartpb.CartResponse
+// @Failure 401 {object} ErrorResponse
 // @Router /api/v1/cart/items [put]
+// @DeprecatedRouter /api/v1/cart/items/update [put]
 func (h *CartHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
-	userID, ok := middleware.GetUserID(r.Context())
+	userID, guestCartID, ok := h.cartOwner(r)
 	if !ok {
 		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
-	var req struct {
-		ProductID int64 `json:"product_id"`
-		Quantity  int32 `json:"quantity"`
-	}
+	var req CartItemRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+	if err := decodeJSON(r, &req); err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
 	resp, err := h.cartClient.UpdateItem(r.Context(), &cartpb.UpdateItemRequest{
-		UserId:    int64(userID),
-		ProductId: req.ProductID,
-		Quantity:  req.Quantity,
+		UserId:     userID,
+		GuestToken: guestCartID,
+		ProductId:  req.ProductID,
+		Quantity:   req.Quantity,
 	})
 
 	if err != nil {
@@ -130,38 +153,41 @@ func (h *CartHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	h.keepGuestCart(w, guestCartID)
+	writeProtoJSON(w, http.StatusOK, resp)
 }
 
 // RemoveItem godoc
 // @Summary Remove item from cart
-// @Description Remove a product from the user's cart
+// @Description Remove a product from the user's cart or, without a token, the guest cart
 // @Tags cart
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body RemoveItemRequest true "Product ID"
-// @Success 200 {object} CartResponse
+// @Param X-Guest-Cart-Token header string false "Guest cart token, for clients that do not keep cookies"
+// @Param request body RemoveCartItemRequest true "Product ID"
+// @Success 200 {object} cartpb.CartResponse
+// @Failure 401 {object} ErrorResponse
 // @Router /api/v1/cart/items [delete]
+// @DeprecatedRouter /api/v1/cart/items/remove [delete]
 func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
-	userID, ok := middleware.GetUserID(r.Context())
+	userID, guestCartID, ok := h.cartOwner(r)
 	if !ok {
 		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
-	var req struct {
-		ProductID int64 `json:"product_id"`
-	}
+	var req RemoveCartItemRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+	if err := decodeJSON(r, &req); err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
 	resp, err := h.cartClient.RemoveItem(r.Context(), &cartpb.RemoveItemRequest{
-		UserId:    int64(userID),
-		ProductId: req.ProductID,
+		UserId:     userID,
+		GuestToken: guestCartID,
+		ProductId:  req.ProductID,
 	})
 
 	if err != nil {
@@ -170,26 +196,31 @@ func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	h.keepGuestCart(w, guestCartID)
+	writeProtoJSON(w, http.StatusOK, resp)
 }
 
 // ClearCart godoc
 // @Summary Clear cart
-// @Description Remove all items from the user's cart
+// @Description Remove all items from the user's cart or, without a token, the guest cart
 // @Tags cart
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} ClearCartResponse
+// @Param X-Guest-Cart-Token header string false "Guest cart token, for clients that do not keep cookies"
+// @Success 200 {object} cartpb.ClearCartResponse
+// @Failure 401 {object} ErrorResponse
 // @Router /api/v1/cart [delete]
+// @DeprecatedRouter /api/v1/cart/clear [delete]
 func (h *CartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
-	userID, ok := middleware.GetUserID(r.Context())
+	userID, guestCartID, ok := h.cartOwner(r)
 	if !ok {
 		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	resp, err := h.cartClient.ClearCart(r.Context(), &cartpb.ClearCartRequest{
-		UserId: int64(userID),
+		UserId:     userID,
+		GuestToken: guestCartID,
 	})
 
 	if err != nil {
@@ -198,5 +229,13 @@ func (h *CartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeProtoJSON(w, http.StatusOK, resp)
+}
+
+// keepGuestCart hands a guest back the token of the cart they changed, restarting the cookie's expiry along
+// with the cart's
+func (h *CartHandler) keepGuestCart(w http.ResponseWriter, guestCartID string) {
+	if guestCartID != "" {
+		h.guestCarts.Set(w, guestCartID)
+	}
 }
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// strictDecoding makes request bodies with fields the target doesn't declare a 400 instead of ignoring them
+var strictDecoding atomic.Bool
+
+// SetStrictDecoding turns on rejecting unknown request body fields, for STRICT_JSON_DECODING
+func SetStrictDecoding(strict bool) {
+	strictDecoding.Store(strict)
+}
+
+// bodyError is a request body that could not be decoded. Field is the JSON path of the field at fault, as
+// in items.quantity, or "" when the error is not about one field.
+type bodyError struct {
+	Field   string
+	Message string
+}
+
+func (e *bodyError) Error() string {
+	return e.Message
+}
+
+// decodeJSON decodes a single JSON value from the body into dst, telling apart malformed JSON, unknown fields
+// and values of the wrong type. The returned error is safe to show to the client.
+func decodeJSON(r *http.Request, dst any) error {
+	decoder := json.NewDecoder(r.Body)
+	if strictDecoding.Load() {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(dst); err != nil {
+		return describeDecodeError(err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return &bodyError{Message: fmt.Sprintf("invalid JSON at byte %d: unexpected data after the request body", decoder.InputOffset())}
+	}
+	return nil
+}
+
+func describeDecodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return &bodyError{Message: "request body is empty"}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &bodyError{Message: "invalid JSON: request body ends before the JSON value is complete"}
+	case errors.As(err, &syntaxErr):
+		return &bodyError{Message: fmt.Sprintf("invalid JSON at byte %d: %s", syntaxErr.Offset, syntaxErr.Error())}
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return &bodyError{Message: fmt.Sprintf("request body must be %s, got %s", jsonKind(typeErr.Type), typeErr.Value)}
+		}
+		if number, ok := strings.CutPrefix(typeErr.Value, "number "); ok && isInteger(typeErr.Type) && !strings.ContainsAny(number, ".eE") {
+			return &bodyError{Field: typeErr.Field, Message: fmt.Sprintf("%s is out of range", typeErr.Field)}
+		}
+		return &bodyError{Field: typeErr.Field, Message: fmt.Sprintf("%s must be %s, got %s", typeErr.Field, jsonKind(typeErr.Type), typeErr.Value)}
+	}
+
+	// encoding/json has no error type for unknown fields
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field = strings.Trim(field, `"`)
+		return &bodyError{Field: field, Message: fmt.Sprintf("unknown field %q", field)}
+	}
+	return &bodyError{Message: "invalid request body"}
+}
+
+// jsonKind names the JSON value a Go type is decoded from
+func jsonKind(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case isInteger(t):
+		return "an integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "a number"
+	case t.Kind() == reflect.String:
+		return "a string"
+	case t.Kind() == reflect.Bool:
+		return "a boolean"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return "an array"
+	case t.Kind() == reflect.Struct || t.Kind() == reflect.Map:
+		return "an object"
+	}
+	return "a " + t.String()
+}
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// writeRequestError answers 400 for a request body decodeRequest or decodeJSON refused, naming the field at
+// fault when there is one
+func writeRequestError(w http.ResponseWriter, err error) {
+	response := ErrorResponse{
+		Error:   http.StatusText(http.StatusBadRequest),
+		Message: err.Error(),
+		Code:    http.StatusBadRequest,
+	}
+	var decodeErr *bodyError
+	if errors.As(err, &decodeErr) {
+		response.Field = decodeErr.Field
+	}
+	writeJSON(w, http.StatusBadRequest, response)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+)
+
+// strictly turns on strict decoding for the rest of the test
+func strictly(t *testing.T) {
+	t.Helper()
+	SetStrictDecoding(true)
+	t.Cleanup(func() { SetStrictDecoding(false) })
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		strict    bool
+		wantField string
+		want      string
+	}{
+		{name: "valid", body: `{"shipping_option_id": "standard", "items": [{"product_id": 4, "quantity": 1}]}`},
+		{name: "unknown field ignored", body: `{"shipping_option_id": "standard", "coupon": "FREE"}`},
+		{name: "empty", body: "", want: "request body is empty"},
+		{name: "cut short", body: `{"shipping_option_id": "stand`, want: "invalid JSON: request body ends before the JSON value is complete"},
+		{name: "syntax error", body: `{"discount": }`, want: "invalid JSON at byte 14: invalid character '}' looking for beginning of value"},
+		{name: "trailing comma", body: `{"discount": 1,}`, want: "invalid JSON at byte 16: invalid character '}' looking for beginning of object key string"},
+		{name: "data after the body", body: `{"discount": 1} {"discount": 2}`, want: "invalid JSON at byte 17: unexpected data after the request body"},
+		{name: "not an object", body: `[1, 2]`, want: "request body must be an object, got array"},
+		{name: "string for a number", body: `{"discount": "ten"}`, wantField: "discount", want: "discount must be a number, got string"},
+		{name: "fraction for an integer", body: `{"address_id": 1.5}`, wantField: "address_id", want: "address_id must be an integer, got number 1.5"},
+		{name: "out of range", body: `{"shipping_duration_days": 3000000000}`, wantField: "shipping_duration_days", want: "shipping_duration_days is out of range"},
+		{name: "object for an array", body: `{"items": {"product_id": 4}}`, wantField: "items", want: "items must be an array, got object"},
+		{
+			name:      "nested field",
+			body:      `{"items": [{"product_id": 4, "quantity": "one"}]}`,
+			wantField: "items.0.quantity",
+			want:      "items.0.quantity must be an integer, got string",
+		},
+		{name: "unknown field in strict mode", body: `{"shipping_option_id": "standard", "coupon": "FREE"}`, strict: true, wantField: "coupon", want: `unknown field "coupon"`},
+		{name: "valid in strict mode", body: `{"shipping_option_id": "standard"}`, strict: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.strict {
+				strictly(t)
+			}
+			var req CreateOrderRequest
+			err := decodeJSON(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)), &req)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("decodeJSON = %v, want no error", err)
+				}
+				return
+			}
+
+			bodyErr, ok := err.(*bodyError)
+			if !ok {
+				t.Fatalf("decodeJSON = %#v, want a *bodyError", err)
+			}
+			if bodyErr.Message != tt.want || bodyErr.Field != tt.wantField {
+				t.Fatalf("decodeJSON = %q about %q, want %q about %q", bodyErr.Message, bodyErr.Field, tt.want, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONIntoProtobufMessages(t *testing.T) {
+	strictly(t)
+	var req productpb.CreateCategoryRequest
+	err := decodeJSON(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": "Books", "color": "red"}`)), &req)
+	if bodyErr, ok := err.(*bodyError); !ok || bodyErr.Field != "color" {
+		t.Fatalf("decodeJSON = %v, want the unknown color field refused", err)
+	}
+}
+
+// TestMalformedBodiesAreExplained sends each class of bad body to the order, cart and product handlers, which
+// must answer 400 naming the problem
+func TestMalformedBodiesAreExplained(t *testing.T) {
+	strictly(t)
+	syntaxError := body{text: `{"name": "x",, }`, want: "invalid JSON at byte 14: invalid character ',' looking for beginning of object key string"}
+	unknownField := body{text: `{"colour": "red"}`, field: "colour", want: `unknown field "colour"`}
+	tests := []struct {
+		name    string
+		route   string
+		handler http.HandlerFunc
+		bodies  []body
+	}{
+		{
+			name:    "order",
+			route:   "/api/v1/orders",
+			handler: NewOrderHandler(&fakeOrderClient{}, nil, nil).CreateOrder,
+			bodies: []body{syntaxError, unknownField,
+				{text: `{"discount": "ten"}`, field: "discount", want: "discount must be a number, got string"}},
+		},
+		{
+			name:    "cart",
+			route:   "/api/v1/cart/items",
+			handler: NewCartHandler(&fakeCartClient{}, nil).AddItem,
+			bodies: []body{syntaxError, unknownField,
+				{text: `{"product_id": 4, "quantity": "two"}`, field: "quantity", want: "quantity must be an integer, got string"}},
+		},
+		{
+			name:    "product",
+			route:   "/api/v1/categories",
+			handler: NewProductHandler(&fakeProductClient{}, nil, "", "", 0, nil).CreateCategory,
+			bodies: []body{syntaxError, unknownField,
+				{text: `{"name": 5}`, field: "name", want: "name must be a string, got number"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, b := range tt.bodies {
+				w := serve(t, testRequest{method: http.MethodPost, route: tt.route, target: tt.route, body: b.text, userID: 7, roles: []string{"admin"}}, wrap(tt.handler))
+				var resp ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("%s: got %d %s: %v", b.text, w.Code, w.Body, err)
+				}
+				if w.Code != http.StatusBadRequest || resp.Message != b.want || resp.Field != b.field {
+					t.Errorf("%s: got %d %s, want 400 %q about %q", b.text, w.Code, w.Body, b.want, b.field)
+				}
+			}
+		})
+	}
+}
+
+// body is a malformed request body and the error it must be answered with
+type body struct {
+	text  string
+	field string
+	want  string
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/audit"
+	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
+	notificationpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/notification"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	wishlistpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/wishlist"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeCall runs the stub a test set up for a method, failing the call when it set none
+func fakeCall[Req, Resp any](stub func(*Req) (*Resp, error), in *Req) (*Resp, error) {
+	if stub == nil {
+		return nil, status.Error(codes.Unimplemented, "not stubbed")
+	}
+	return stub(in)
+}
+
+// fakeUserClient answers the user service methods a test stubs; the rest panic through the nil interface
+type fakeUserClient struct {
+	userpb.UserServiceClient
+	getUserByID    func(*userpb.GetUserByIDRequest) (*userpb.User, error)
+	getAddressByID func(*userpb.GetAddressByIDRequest) (*userpb.GetAddressByIDResponse, error)
+	updateAddress  func(*userpb.UpdateAddressRequest) (*userpb.UpdateAddressResponse, error)
+	listAddresses  func(*userpb.ListAddressesByUserIDRequest) (*userpb.ListAddressesByUserIDResponse, error)
+	deleteAddress  func(*userpb.DeleteAddressRequest) (*userpb.DeleteAddressResponse, error)
+	deleteUser     func(*userpb.DeleteUserRequest) (*userpb.DeleteUserResponse, error)
+	searchUsers    func(*userpb.SearchUsersRequest) (*userpb.SearchUsersResponse, error)
+	impersonate    func(*userpb.ImpersonateUserRequest) (*userpb.ImpersonateUserResponse, error)
+	setupTwoFactor func(*userpb.SetupTwoFactorRequest) (*userpb.SetupTwoFactorResponse, error)
+}
+
+func (f *fakeUserClient) GetUserByID(_ context.Context, in *userpb.GetUserByIDRequest, _ ...grpc.CallOption) (*userpb.User, error) {
+	return fakeCall(f.getUserByID, in)
+}
+
+func (f *fakeUserClient) GetAddressByID(_ context.Context, in *userpb.GetAddressByIDRequest, _ ...grpc.CallOption) (*userpb.GetAddressByIDResponse, error) {
+	return fakeCall(f.getAddressByID, in)
+}
+
+func (f *fakeUserClient) UpdateAddress(_ context.Context, in *userpb.UpdateAddressRequest, _ ...grpc.CallOption) (*userpb.UpdateAddressResponse, error) {
+	return fakeCall(f.updateAddress, in)
+}
+
+func (f *fakeUserClient) ListAddressesByUserID(_ context.Context, in *userpb.ListAddressesByUserIDRequest, _ ...grpc.CallOption) (*userpb.ListAddressesByUserIDResponse, error) {
+	return fakeCall(f.listAddresses, in)
+}
+
+func (f *fakeUserClient) DeleteAddress(_ context.Context, in *userpb.DeleteAddressRequest, _ ...grpc.CallOption) (*userpb.DeleteAddressResponse, error) {
+	return fakeCall(f.deleteAddress, in)
+}
+
+func (f *fakeUserClient) DeleteUser(_ context.Context, in *userpb.DeleteUserRequest, _ ...grpc.CallOption) (*userpb.DeleteUserResponse, error) {
+	return fakeCall(f.deleteUser, in)
+}
+
+func (f *fakeUserClient) SearchUsers(_ context.Context, in *userpb.SearchUsersRequest, _ ...grpc.CallOption) (*userpb.SearchUsersResponse, error) {
+	return fakeCall(f.searchUsers, in)
+}
+
+func (f *fakeUserClient) ImpersonateUser(_ context.Context, in *userpb.ImpersonateUserRequest, _ ...grpc.CallOption) (*userpb.ImpersonateUserResponse, error) {
+	return fakeCall(f.impersonate, in)
+}
+
+func (f *fakeUserClient) SetupTwoFactor(_ context.Context, in *userpb.SetupTwoFactorRequest, _ ...grpc.CallOption) (*userpb.SetupTwoFactorResponse, error) {
+	return fakeCall(f.setupTwoFactor, in)
+}
+
+// fakeOrderClient answers the order service methods a test stubs
+type fakeOrderClient struct {
+	orderpb.OrderServiceClient
+	listOrders          func(*orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error)
+	createOrder         func(*orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error)
+	getOrderByID        func(*orderpb.GetOrderByIDRequest) (*orderpb.GetOrderByIDResponse, error)
+	updateItemQuantity  func(*orderpb.UpdateOrderItemQuantityRequest) (*orderpb.UpdateOrderItemQuantityResponse, error)
+	anonymiseUserOrders func(*orderpb.AnonymiseUserOrdersRequest) (*orderpb.AnonymiseUserOrdersResponse, error)
+	getRevenueSummary   func(*orderpb.GetRevenueSummaryRequest) (*orderpb.GetRevenueSummaryResponse, error)
+	exportOrders        func(*orderpb.ExportOrdersRequest) (*batchStream, error)
+}
+
+func (f *fakeOrderClient) ListOrders(_ context.Context, in *orderpb.ListOrdersRequest, _ ...grpc.CallOption) (*orderpb.ListOrdersResponse, error) {
+	return fakeCall(f.listOrders, in)
+}
+
+func (f *fakeOrderClient) CreateOrder(_ context.Context, in *orderpb.CreateOrderRequest, _ ...grpc.CallOption) (*orderpb.CreateOrderResponse, error) {
+	return fakeCall(f.createOrder, in)
+}
+
+func (f *fakeOrderClient) GetOrderByID(_ context.Context, in *orderpb.GetOrderByIDRequest, _ ...grpc.CallOption) (*orderpb.GetOrderByIDResponse, error) {
+	return fakeCall(f.getOrderByID, in)
+}
+
+func (f *fakeOrderClient) UpdateOrderItemQuantity(_ context.Context, in *orderpb.UpdateOrderItemQuantityRequest, _ ...grpc.CallOption) (*orderpb.UpdateOrderItemQuantityResponse, error) {
+	return fakeCall(f.updateItemQuantity, in)
+}
+
+func (f *fakeOrderClient) AnonymiseUserOrders(_ context.Context, in *orderpb.AnonymiseUserOrdersRequest, _ ...grpc.CallOption) (*orderpb.AnonymiseUserOrdersResponse, error) {
+	return fakeCall(f.anonymiseUserOrders, in)
+}
+
+func (f *fakeOrderClient) GetRevenueSummary(_ context.Context, in *orderpb.GetRevenueSummaryRequest, _ ...grpc.CallOption) (*orderpb.GetRevenueSummaryResponse, error) {
+	return fakeCall(f.getRevenueSummary, in)
+}
+
+func (f *fakeOrderClient) ExportOrders(_ context.Context, in *orderpb.ExportOrdersRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[orderpb.ExportOrdersResponse], error) {
+	stream, err := fakeCall(f.exportOrders, in)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// batchStream receives batches in turn, then end, or io.EOF when end is nil
+type batchStream struct {
+	grpc.ClientStream
+	batches []*orderpb.ExportOrdersResponse
+	end     error
+}
+
+func (s *batchStream) Recv() (*orderpb.ExportOrdersResponse, error) {
+	if len(s.batches) == 0 {
+		if s.end != nil {
+			return nil, s.end
+		}
+		return nil, io.EOF
+	}
+	batch := s.batches[0]
+	s.batches = s.batches[1:]
+	return batch, nil
+}
+
+// fakeCartClient answers the cart service methods a test stubs
+type fakeCartClient struct {
+	cartpb.CartServiceClient
+	getCart   func(*cartpb.GetCartRequest) (*cartpb.CartResponse, error)
+	clearCart func(*cartpb.ClearCartRequest) (*cartpb.ClearCartResponse, error)
+}
+
+func (f *fakeCartClient) GetCart(_ context.Context, in *cartpb.GetCartRequest, _ ...grpc.CallOption) (*cartpb.CartResponse, error) {
+	return fakeCall(f.getCart, in)
+}
+
+func (f *fakeCartClient) ClearCart(_ context.Context, in *cartpb.ClearCartRequest, _ ...grpc.CallOption) (*cartpb.ClearCartResponse, error) {
+	return fakeCall(f.clearCart, in)
+}
+
+// fakeWishlistClient answers the wishlist service methods a test stubs
+type fakeWishlistClient struct {
+	wishlistpb.WishlistServiceClient
+	getWishlist func(*wishlistpb.GetWishlistRequest) (*wishlistpb.WishlistResponse, error)
+	removeItem  func(*wishlistpb.RemoveWishlistItemRequest) (*wishlistpb.WishlistResponse, error)
+}
+
+func (f *fakeWishlistClient) GetWishlist(_ context.Context, in *wishlistpb.GetWishlistRequest, _ ...grpc.CallOption) (*wishlistpb.WishlistResponse, error) {
+	return fakeCall(f.getWishlist, in)
+}
+
+func (f *fakeWishlistClient) RemoveItem(_ context.Context, in *wishlistpb.RemoveWishlistItemRequest, _ ...grpc.CallOption) (*wishlistpb.WishlistResponse, error) {
+	return fakeCall(f.removeItem, in)
+}
+
+// fakeNotificationClient answers the notification service methods a test stubs
+type fakeNotificationClient struct {
+	notificationpb.NotificationServiceClient
+	listNotifications func(*notificationpb.ListNotificationsRequest) (*notificationpb.ListNotificationsResponse, error)
+	markRead          func(*notificationpb.MarkNotificationsReadRequest) (*notificationpb.MarkNotificationsReadResponse, error)
+	updatePreferences func(*notificationpb.UpdateNotificationPreferencesRequest) (*notificationpb.UpdateNotificationPreferencesResponse, error)
+}
+
+func (f *fakeNotificationClient) ListNotifications(_ context.Context, in *notificationpb.ListNotificationsRequest, _ ...grpc.CallOption) (*notificationpb.ListNotificationsResponse, error) {
+	return fakeCall(f.listNotifications, in)
+}
+
+func (f *fakeNotificationClient) MarkNotificationsRead(_ context.Context, in *notificationpb.MarkNotificationsReadRequest, _ ...grpc.CallOption) (*notificationpb.MarkNotificationsReadResponse, error) {
+	return fakeCall(f.markRead, in)
+}
+
+func (f *fakeNotificationClient) UpdateNotificationPreferences(_ context.Context, in *notificationpb.UpdateNotificationPreferencesRequest, _ ...grpc.CallOption) (*notificationpb.UpdateNotificationPreferencesResponse, error) {
+	return fakeCall(f.updatePreferences, in)
+}
+
+// auditRecorder keeps the audit entries a handler records
+type auditRecorder struct {
+	mu      sync.Mutex
+	entries []audit.Entry
+}
+
+func (a *auditRecorder) Record(entry audit.Entry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, entry)
+	return nil
+}
+
+// fakeProductClient answers the product service methods a test stubs
+type fakeProductClient struct {
+	productpb.ProductServiceClient
+	getProductByID func(*productpb.GetProductByIDRequest) (*productpb.GetProductByIDResponse, error)
+	getCategory    func(*productpb.GetCategoryByIDRequest) (*productpb.GetCategoryByIDResponse, error)
+	getProducts    func(*productpb.GetProductsByIDsRequest) (*productpb.GetProductsByIDsResponse, error)
+	listProducts   func(*productpb.ListProductsRequest) (*productpb.ListProductsResponse, error)
+	listCategories func(*productpb.ListCategoriesRequest) (*productpb.ListCategoriesResponse, error)
+	updateProduct  func(*productpb.UpdateProductRequest) (*productpb.UpdateProductResponse, error)
+	adjustStock    func(*productpb.AdjustProductStockRequest) (*productpb.AdjustProductStockResponse, error)
+	createProduct  func(*productpb.CreateProductRequest) (*productpb.CreateProductResponse, error)
+}
+
+func (f *fakeProductClient) GetProductByID(_ context.Context, in *productpb.GetProductByIDRequest, _ ...grpc.CallOption) (*productpb.GetProductByIDResponse, error) {
+	return fakeCall(f.getProductByID, in)
+}
+
+func (f *fakeProductClient) GetCategoryByID(_ context.Context, in *productpb.GetCategoryByIDRequest, _ ...grpc.CallOption) (*productpb.GetCategoryByIDResponse, error) {
+	return fakeCall(f.getCategory, in)
+}
+
+func (f *fakeProductClient) GetProductsByIDs(_ context.Context, in *productpb.GetProductsByIDsRequest, _ ...grpc.CallOption) (*productpb.GetProductsByIDsResponse, error) {
+	return fakeCall(f.getProducts, in)
+}
+
+func (f *fakeProductClient) ListProducts(_ context.Context, in *productpb.ListProductsRequest, _ ...grpc.CallOption) (*productpb.ListProductsResponse, error) {
+	return fakeCall(f.listProducts, in)
+}
+
+func (f *fakeProductClient) ListCategories(_ context.Context, in *productpb.ListCategoriesRequest, _ ...grpc.CallOption) (*productpb.ListCategoriesResponse, error) {
+	return fakeCall(f.listCategories, in)
+}
+
+func (f *fakeProductClient) UpdateProduct(_ context.Context, in *productpb.UpdateProductRequest, _ ...grpc.CallOption) (*productpb.UpdateProductResponse, error) {
+	return fakeCall(f.updateProduct, in)
+}
+
+func (f *fakeProductClient) AdjustProductStock(_ context.Context, in *productpb.AdjustProductStockRequest, _ ...grpc.CallOption) (*productpb.AdjustProductStockResponse, error) {
+	return fakeCall(f.adjustStock, in)
+}
+
+func (f *fakeProductClient) CreateProduct(_ context.Context, in *productpb.CreateProductRequest, _ ...grpc.CallOption) (*productpb.CreateProductResponse, error) {
+	return fakeCall(f.createProduct, in)
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/graphql"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const maxGraphQLBodyBytes = 1 << 20
+
+// GraphQLHandler serves POST /graphql over the same gRPC clients as the REST handlers
+type GraphQLHandler struct {
+	userClient    userpb.UserServiceClient
+	productClient productpb.ProductServiceClient
+	cartClient    cartpb.CartServiceClient
+	orderClient   orderpb.OrderServiceClient
+	schema        *graphql.Schema
+}
+
+// GraphQLOrderItem is an item of the createOrder mutation
+type GraphQLOrderItem struct {
+	ProductID int64 `json:"productId"`
+	Quantity  int32 `json:"quantity"`
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler(userClient userpb.UserServiceClient, productClient productpb.ProductServiceClient, cartClient cartpb.CartServiceClient, orderClient orderpb.OrderServiceClient) *GraphQLHandler {
+	h := &GraphQLHandler{
+		userClient:    userClient,
+		productClient: productClient,
+		cartClient:    cartClient,
+		orderClient:   orderClient,
+	}
+	h.schema = &graphql.Schema{
+		Query: map[string]*graphql.RootField{
+			"me":         {Resolve: h.me},
+			"products":   {Args: []string{"page", "perPage", "cursor"}, Resolve: h.products},
+			"product":    {Args: []string{"id"}, Resolve: h.product},
+			"categories": {Args: []string{"page", "perPage"}, Resolve: h.categories},
+			"cart":       {Resolve: h.cart},
+			"myOrders":   {Args: []string{"page", "perPage", "status"}, Resolve: h.myOrders},
+			"order":      {Args: []string{"id"}, Resolve: h.order},
+		},
+		Mutation: map[string]*graphql.RootField{
+			"addToCart":   {Args: []string{"productId", "quantity"}, Resolve: h.addToCart},
+			"createOrder": {Args: []string{"items", "addressId", "shippingOptionId"}, Resolve: h.createOrder},
+		},
+		FormatError: graphQLError,
+	}
+	return h
+}
+
+// Query godoc
+// @Summary GraphQL
+// @Description Run a GraphQL query or mutation. Queries: me, products, product, categories, cart, myOrders and order;
+// @Description mutations: addToCart and createOrder. me, cart, myOrders, order and both mutations need a bearer token.
+// @Description Types are the gRPC messages with lowerCamelCase field names. Errors carry the REST status in extensions.code.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body graphql.Request true "Query, operationName and variables"
+// @Success 200 {object} graphql.Response
+// @Failure 400 {object} graphql.Response
+// @Router /graphql [post]
+func (h *GraphQLHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBodyBytes))
+	// Numbers stay json.Number so int64 IDs in variables are not rounded through float64
+	decoder.UseNumber()
+	if err := decoder.Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, graphql.Response{Errors: []*graphql.Error{requestError("invalid request body")}})
+		return
+	}
+
+	resp := h.schema.Execute(r.Context(), req)
+	// Errors in the document itself have no status of their own, so they are reported as bad requests
+	for i, gqlErr := range resp.Errors {
+		if gqlErr.Extensions == nil {
+			resp.Errors[i].Extensions = requestError(gqlErr.Message).Extensions
+		}
+	}
+
+	statusCode := http.StatusOK
+	if resp.Data == nil {
+		statusCode = http.StatusBadRequest
+	}
+	writeJSON(w, statusCode, resp)
+}
+
+func (h *GraphQLHandler) me(ctx context.Context, _ graphql.ResolveParams) (proto.Message, error) {
+	userID, err := graphQLUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return h.userClient.GetUserByID(ctx, &userpb.GetUserByIDRequest{Id: int32(userID)})
+}
+
+func (h *GraphQLHandler) products(ctx context.Context, params graphql.ResolveParams) (proto.Message, error) {
+	page, perPage, err := graphQLPagination(params.Args)
+	if err != nil {
+		return nil, err
+	}
+	cursor, _, err := params.Args.String("cursor")
+	if err != nil {
+		return nil, err
+	}
+
+	return h.productClient.ListProducts(ctx, &productpb.ListProductsRequest{
+		Page:    page,
+		PerPage: perPage,
+		Cursor:  cursor,
+		Fields:  productFieldMask(params.SelectedFields("products")),
+	})
+}
+
+func (h *GraphQLHandler) product(ctx context.Context, params graphql.ResolveParams) (proto.Message, error) {
+	id, err := positiveIntArg(params.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.productClient.GetProductByID(ctx, &productpb.GetProductByIDRequest{
+		Id:     id,
+		Fields: productFieldMask(params.SelectedFields()),
+	})
+	return resp.GetProduct(), err
+}
+
+func (h *GraphQLHandler) categories(ctx context.Context, params graphql.ResolveParams) (proto.Message, error) {
+	page, perPage, err := graphQLPagination(params.Args)
+	if err != nil {
+		return nil, err
+	}
+	return h.productClient.ListCategories(ctx, &productpb.ListCategoriesRequest{Page: page, PerPage: perPage})
+}
+
+func (h *GraphQLHandler) cart(ctx context.Context, _ graphql.ResolveParams) (proto.Message, error) {
+	userID, err := graphQLUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return h.cartClient.GetCart(ctx, &cartpb.GetCartRequest{UserId: int64(userID)})
+}
+
+func (h *GraphQLHandler) myOrders(ctx context.Context, params graphql.ResolveParams) (proto.Message, error) {
+	userID, err := graphQLUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, perPage, err := graphQLPagination(params.Args)
+	if err != nil {
+		return nil, err
+	}
+	orderStatus, _, err := params.Args.String("status")
+	if err != nil {
+		return nil, err
+	}
+
+	return h.orderClient.ListOrders(ctx, &orderpb.ListOrdersRequest{
+		Page:    page,
+		PerPage: perPage,
+		UserId:  int64(userID),
+		Status:  orderStatus,
+	})
+}
+
+// order relies on the order service to refuse orders of other users, as the caller is forwarded to it
+func (h *GraphQLHandler) order(ctx context.Context, params graphql.ResolveParams) (proto.Message, error) {
+	if _, err := graphQLUserID(ctx); err != nil {
+		return nil, err
+	}
+	id, err := positiveIntArg(params.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.orderClient.GetOrderByID(ctx, &orderpb.GetOrderByIDRequest{Id: id})
+	return resp.GetOrder(), err
+}
+
+func (h *GraphQLHandler) addToCart(ctx context.Context, params graphql.ResolveParams) (proto.Message, error) {
+	userID, err := graphQLUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	productID, err := positiveIntArg(params.Args, "productId")
+	if err != nil {
+		return nil, err
+	}
+	quantity, err := positiveIntArg(params.Args, "quantity")
+	if err != nil {
+		return nil, err
+	}
+
+	return h.cartClient.AddItem(ctx, &cartpb.AddItemRequest{
+		UserId:    int64(userID),
+		ProductId: productID,
+		Quantity:  int32(quantity),
+	})
+}
+
+// createOrder prices shipping from shippingOptionId for everyone; unlike REST, admins cannot set raw shipping values
+func (h *GraphQLHandler) createOrder(ctx context.Context, params graphql.ResolveParams) (proto.Message, error) {
+	userID, err := graphQLUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []GraphQLOrderItem
+	if err := params.Args.Decode("items", &items); err != nil {
+		return nil, err
+	}
+	addressID, _, err := params.Args.Int("addressId")
+	if err != nil {
+		return nil, err
+	}
+	shippingOptionID, _, err := params.Args.String("shippingOptionId")
+	if err != nil {
+		return nil, err
+	}
+
+	req := CreateOrderRequest{AddressID: addressID, ShippingOptionID: shippingOptionID}
+	for _, item := range items {
+		req.Items = append(req.Items, CreateOrderItemRequest{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	if err := validateRequest(&req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.ShippingOptionID == "" {
+		return nil, status.Error(codes.InvalidArgument, "shippingOptionId is required")
+	}
+	if len(req.Items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "items is required")
+	}
+
+	if req.AddressID, err = ownedAddressID(ctx, h.userClient, userID, req.AddressID); err != nil {
+		return nil, err
+	}
+
+	orderItems := make([]*orderpb.OrderItemInput, 0, len(req.Items))
+	for _, item := range req.Items {
+		orderItems = append(orderItems, &orderpb.OrderItemInput{ProductId: item.ProductID, Quantity: item.Quantity})
+	}
+	resp, err := h.orderClient.CreateOrder(ctx, &orderpb.CreateOrderRequest{
+		UserId:           int64(userID),
+		Items:            orderItems,
+		AddressId:        req.AddressID,
+		ShippingOptionId: req.ShippingOptionID,
+	})
+	return resp.GetOrder(), err
+}
+
+// graphQLUserID returns the authenticated caller; the route only authenticates when a token is sent
+func graphQLUserID(ctx context.Context) (uint, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return userID, nil
+}
+
+// graphQLPagination applies the REST defaults and cap to the page and perPage arguments
+func graphQLPagination(args graphql.Args) (int32, int32, error) {
+	page, _, err := args.Int("page")
+	if err != nil {
+		return 0, 0, err
+	}
+	perPage, _, err := args.Int("perPage")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if page < 1 || page > math.MaxInt32 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	return int32(page), int32(min(perPage, maxPerPage)), nil
+}
+
+// positiveIntArg reads a required argument that must fit the services' int32 IDs and quantities
+func positiveIntArg(args graphql.Args, name string) (int64, error) {
+	id, ok, err := args.Int(name)
+	if err != nil {
+		return 0, err
+	}
+	if !ok || id <= 0 || id > math.MaxInt32 {
+		return 0, status.Errorf(codes.InvalidArgument, "%s must be a positive integer", name)
+	}
+	return id, nil
+}
+
+// productFieldMask limits a product query to the selected fields. Unknown names are left for the query to
+// reject, so they yield no mask.
+func productFieldMask(selected []string) *fieldmaskpb.FieldMask {
+	if len(selected) == 0 {
+		return nil
+	}
+	descriptor := (&productpb.Product{}).ProtoReflect().Descriptor().Fields()
+	paths := make([]string, 0, len(selected))
+	for _, name := range selected {
+		field := descriptor.ByJSONName(name)
+		if field == nil {
+			return nil
+		}
+		paths = append(paths, string(field.Name()))
+	}
+	return &fieldmaskpb.FieldMask{Paths: paths}
+}
+
+// graphQLError reports a resolver error with the status code REST would answer it with
+func graphQLError(err error) *graphql.Error {
+	st := status.Convert(err)
+	statusCode := grpcCodeToHTTP(st.Code())
+	if statusCode >= http.StatusInternalServerError {
+		logger.Errorf("failed to resolve graphql field: %v", err)
+	}
+	return &graphql.Error{
+		Message: st.Message(),
+		Extensions: map[string]any{
+			"code":  statusCode,
+			"error": http.StatusText(statusCode),
+		},
+	}
+}
+
+func requestError(message string) *graphql.Error {
+	return &graphql.Error{
+		Message: message,
+		Extensions: map[string]any{
+			"code":  http.StatusBadRequest,
+			"error": http.StatusText(http.StatusBadRequest),
+		},
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// graphQLClients serve a user, their cart and their orders, recording the user each call was made for
+type graphQLClients struct {
+	user    fakeUserClient
+	product fakeProductClient
+	cart    fakeCartClient
+	order   fakeOrderClient
+	calls   []int64
+}
+
+func newGraphQLClients() *graphQLClients {
+	c := &graphQLClients{}
+	c.user.getUserByID = func(in *userpb.GetUserByIDRequest) (*userpb.User, error) {
+		c.calls = append(c.calls, int64(in.GetId()))
+		return &userpb.User{Id: in.GetId(), Name: "Mona", Email: "mona@example.com"}, nil
+	}
+	c.cart.getCart = func(in *cartpb.GetCartRequest) (*cartpb.CartResponse, error) {
+		c.calls = append(c.calls, in.GetUserId())
+		return &cartpb.CartResponse{UserId: in.GetUserId(), TotalQuantity: 3}, nil
+	}
+	c.order.listOrders = func(in *orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+		c.calls = append(c.calls, in.GetUserId())
+		if in.GetPage() != 1 || in.GetPerPage() != defaultPerPage || in.GetStatus() != "paid" {
+			return nil, status.Errorf(codes.InvalidArgument, "unexpected request %v", in)
+		}
+		return &orderpb.ListOrdersResponse{TotalCount: 4}, nil
+	}
+	c.product.getProductByID = func(*productpb.GetProductByIDRequest) (*productpb.GetProductByIDResponse, error) {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	return c
+}
+
+func (c *graphQLClients) serve(t *testing.T, userID uint, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	h := NewGraphQLHandler(&c.user, &c.product, &c.cart, &c.order)
+	return serve(t, testRequest{method: http.MethodPost, route: "/graphql", target: "/graphql", body: body, userID: userID}, wrap(h.Query))
+}
+
+func TestGraphQLUserQueriesRequireAuthentication(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "me",
+			query: `{"query": "{ me { id name } }"}`,
+			want:  `{"data":{"me":null},"errors":[{"message":"unauthorized","path":["me"],"extensions":{"code":401,"error":"Unauthorized"}}]}`,
+		},
+		{
+			name:  "cart",
+			query: `{"query": "{ cart { totalQuantity } }"}`,
+			want:  `{"data":{"cart":null},"errors":[{"message":"unauthorized","path":["cart"],"extensions":{"code":401,"error":"Unauthorized"}}]}`,
+		},
+		{
+			name:  "myOrders",
+			query: `{"query": "{ myOrders(status: \"paid\") { totalCount } }"}`,
+			want:  `{"data":{"myOrders":null},"errors":[{"message":"unauthorized","path":["myOrders"],"extensions":{"code":401,"error":"Unauthorized"}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := newGraphQLClients()
+			w := clients.serve(t, 0, tt.query)
+			if w.Code != http.StatusOK || w.Body.String() != tt.want+"\n" {
+				t.Fatalf("got %d %s, want 200 %s", w.Code, w.Body, tt.want)
+			}
+			if len(clients.calls) > 0 {
+				t.Errorf("the services were called for users %v", clients.calls)
+			}
+		})
+	}
+}
+
+func TestGraphQLUserQueriesUseTheCaller(t *testing.T) {
+	clients := newGraphQLClients()
+	w := clients.serve(t, 7, `{"query": "query ($status: String) { me { id name } cart { totalQuantity } myOrders(status: $status) { totalCount } }", "variables": {"status": "paid"}}`)
+
+	want := `{"data":{"me":{"id":7,"name":"Mona"},"cart":{"totalQuantity":3},"myOrders":{"totalCount":4}}}` + "\n"
+	if w.Code != http.StatusOK || w.Body.String() != want {
+		t.Fatalf("got %d %s, want 200 %s", w.Code, w.Body, want)
+	}
+	if len(clients.calls) != 3 || clients.calls[0] != 7 || clients.calls[1] != 7 || clients.calls[2] != 7 {
+		t.Errorf("the services were called for users %v, want 7 three times", clients.calls)
+	}
+}
+
+func TestGraphQLPublicQueryErrorsCarryTheRESTStatus(t *testing.T) {
+	w := newGraphQLClients().serve(t, 0, `{"query": "{ product(id: 5) { name } }"}`)
+
+	want := `{"data":{"product":null},"errors":[{"message":"product not found","path":["product"],"extensions":{"code":404,"error":"Not Found"}}]}` + "\n"
+	if w.Code != http.StatusOK || w.Body.String() != want {
+		t.Fatalf("got %d %s, want 200 %s", w.Code, w.Body, want)
+	}
+}
+
+func TestGraphQLBadRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "body is not JSON",
+			body: `query { me { id } }`,
+			want: `{"errors":[{"message":"invalid request body","extensions":{"code":400,"error":"Bad Request"}}]}`,
+		},
+		{
+			name: "syntax error",
+			body: `{"query": "{ me { id }"}`,
+			want: `{"errors":[{"message":"syntax error: unexpected end of document","locations":[{"line":1,"column":12}],"extensions":{"code":400,"error":"Bad Request"}}]}`,
+		},
+		{
+			name: "too deep",
+			body: `{"query": "{ me { a { b { c { d { e { f { g { h { i { j } } } } } } } } } } }"}`,
+			want: `{"errors":[{"message":"selections may be nested at most 10 levels deep","extensions":{"code":400,"error":"Bad Request"}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newGraphQLClients().serve(t, 0, tt.body)
+			if w.Code != http.StatusBadRequest || w.Body.String() != tt.want+"\n" {
+				t.Fatalf("got %d %s, want 400 %s", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// testRequest describes one request to a handler mounted on route
+type testRequest struct {
+	method string
+	route  string
+	target string
+	body   string
+	// userID authenticates the request when set
+	userID uint
+	roles  []string
+	header http.Header
+}
+
+// serve runs req through a router holding only handlers, with path params handed over as the gateway does
+func serve(t *testing.T, req testRequest, handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	return newTestEngine(req.method, req.route, handlers...)(t, req)
+}
+
+// newTestEngine mounts handlers on route once and returns a func serving requests to it, for tests that send
+// several requests through the same handlers
+func newTestEngine(method, route string, handlers ...gin.HandlerFunc) func(*testing.T, testRequest) *httptest.ResponseRecorder {
+	engine := gin.New()
+	engine.Handle(method, route, append([]gin.HandlerFunc{middleware.PathParams()}, handlers...)...)
+
+	return func(t *testing.T, req testRequest) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(req.method, req.target, strings.NewReader(req.body))
+		if req.body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		for name, values := range req.header {
+			r.Header[name] = values
+		}
+		if req.userID != 0 {
+			claims := &customJWT.UserClaims{UserID: req.userID, Roles: req.roles}
+			if len(req.roles) > 0 {
+				claims.Role = req.roles[0]
+			}
+			// The roles grant their default permissions, as in the tokens the gateway issues
+			for _, role := range req.roles {
+				claims.Permissions = append(claims.Permissions, customJWT.DefaultRolePermissions[role]...)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), middleware.UserClaimsKey, claims))
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+}
+
+// wrap adapts the net/http handlers to serve
+func wrap(handler http.HandlerFunc) gin.HandlerFunc {
+	return gin.WrapF(handler)
+}
+
+// errorMessage returns the message of the ErrorResponse w holds, failing the test when it holds none
+func errorMessage(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != w.Code {
+		t.Fatalf("got %d %s, want an error response", w.Code, w.Body)
+	}
+	return body.Message
+}
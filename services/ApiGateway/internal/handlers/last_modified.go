@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	redisClient "github.com/kareemhamed001/e-commerce/pkg/redis"
+	"github.com/redis/go-redis/v9"
+)
+
+const lastModifiedKeyPrefix = "last_modified:"
+
+// ModificationClock dates response bodies for Last-Modified. The services keep no modification time that
+// covers everything a response is built from, such as a product's fresh rating, its discount window or the
+// exchange rate of its pricing, so a body is dated by when the gateway first served it: for each
+// representation the clock remembers the ETag last served and since when. A representation seen for the
+// first time, or after its entry expired, is dated now, which can only cost a client a full response, never
+// hand it a wrong 304. Entries are shared through Redis when it is enabled, so every instance dates a body
+// alike, and kept locally while it is not.
+type ModificationClock struct {
+	cache *redisClient.Client
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	local     map[string]servedVersion
+	lastPrune time.Time
+}
+
+type servedVersion struct {
+	etag  string
+	since time.Time
+}
+
+// NewModificationClock creates a clock whose entries are forgotten ttl after their ETag last changed
+func NewModificationClock(cache *redisClient.Client, ttl time.Duration) *ModificationClock {
+	return &ModificationClock{
+		cache: cache,
+		ttl:   ttl,
+		local: make(map[string]servedVersion),
+	}
+}
+
+// Since returns when the representation named key started being served with etag, recording now when etag
+// is not the one last served
+func (m *ModificationClock) Since(ctx context.Context, key, etag string) time.Time {
+	now := time.Now().Truncate(time.Second)
+	key = lastModifiedKeyPrefix + hashKey(key)
+
+	if m.cache != nil && m.cache.IsEnabled() {
+		since, err := m.sharedSince(ctx, key, etag, now)
+		if err == nil {
+			return since
+		}
+		logger.Warnf("event=last_modified_cache_failed key=%s error=%v", key, err)
+	}
+	return m.localSince(key, etag, now)
+}
+
+func (m *ModificationClock) sharedSince(ctx context.Context, key, etag string, now time.Time) (time.Time, error) {
+	value, err := m.cache.Get(ctx, key).Result()
+	if err == nil {
+		if servedETag, unix, ok := strings.Cut(value, " "); ok && servedETag == etag {
+			if seconds, err := strconv.ParseInt(unix, 10, 64); err == nil {
+				return time.Unix(seconds, 0), nil
+			}
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		return time.Time{}, err
+	}
+
+	if err := m.cache.Set(ctx, key, etag+" "+strconv.FormatInt(now.Unix(), 10), m.ttl).Err(); err != nil {
+		return time.Time{}, err
+	}
+	return now, nil
+}
+
+func (m *ModificationClock) localSince(key, etag string, now time.Time) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if served, ok := m.local[key]; ok && served.etag == etag && now.Sub(served.since) <= m.ttl {
+		return served.since
+	}
+	m.local[key] = servedVersion{etag: etag, since: now}
+
+	if now.Sub(m.lastPrune) > time.Minute {
+		m.lastPrune = now
+		for key, served := range m.local {
+			if now.Sub(served.since) > m.ttl {
+				delete(m.local, key)
+			}
+		}
+	}
+	return now
+}
+
+// hashKey keeps keys short whatever query string or headers a representation is named by
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// backdate moves every version clock has recorded an hour into the past, as if served that long ago
+func backdate(clock *ModificationClock) {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	for key, served := range clock.local {
+		served.since = served.since.Add(-time.Hour)
+		clock.local[key] = served
+	}
+}
+
+func TestModificationClock(t *testing.T) {
+	ctx := context.Background()
+	clock := NewModificationClock(nil, 24*time.Hour)
+
+	first := clock.Since(ctx, "product:1", `"a"`)
+	if time.Since(first) > time.Second {
+		t.Fatalf("a new version is dated %v, want now", first)
+	}
+	backdate(clock)
+	first = first.Add(-time.Hour)
+
+	if got := clock.Since(ctx, "product:1", `"a"`); !got.Equal(first) {
+		t.Errorf("the same version is dated %v, want %v", got, first)
+	}
+	if got := clock.Since(ctx, "product:2", `"a"`); !got.After(first) {
+		t.Errorf("another resource shares the first one's date %v", got)
+	}
+
+	changed := clock.Since(ctx, "product:1", `"b"`)
+	if !changed.After(first) {
+		t.Fatalf("a changed version is dated %v, want after %v", changed, first)
+	}
+	backdate(clock)
+
+	// Going back to an earlier body is a change too
+	if got := clock.Since(ctx, "product:1", `"a"`); !got.After(changed.Add(-time.Hour)) {
+		t.Errorf("a version served again is dated %v, want now", got)
+	}
+}
+
+func TestModificationClockForgetsExpiredVersions(t *testing.T) {
+	ctx := context.Background()
+	clock := NewModificationClock(nil, time.Minute)
+
+	clock.Since(ctx, "product:1", `"a"`)
+	backdate(clock)
+
+	if got := clock.Since(ctx, "product:1", `"a"`); time.Since(got) > time.Second {
+		t.Errorf("an expired version is dated %v, want now", got)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	notificationpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/notification"
+)
+
+// NotificationHandler serves the caller's notification history and preferences.
+// The user ID always comes from the token, so users only ever see and change their own.
+type NotificationHandler struct {
+	notificationClient notificationpb.NotificationServiceClient
+}
+
+// NotificationListResponse is a page of notifications plus the unread count across every page
+type NotificationListResponse struct {
+	PaginatedResponse
+	UnreadCount int `json:"unread_count"`
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationClient notificationpb.NotificationServiceClient) *NotificationHandler {
+	return &NotificationHandler{
+		notificationClient: notificationClient,
+	}
+}
+
+// ListNotifications godoc
+// @Summary List notifications
+// @Description List the caller's notifications with pagination, newest first. unread_count covers every page.
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page, capped at 100" default(10)
+// @Success 200 {object} NotificationListResponse
+// @Router /api/v1/notifications [get]
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	page, perPage := parsePagination(r)
+
+	resp, err := h.notificationClient.ListNotifications(r.Context(), &notificationpb.ListNotificationsRequest{
+		UserId:  int64(userID),
+		Page:    int32(page),
+		PerPage: int32(perPage),
+	})
+	if err != nil {
+		logger.Errorf("failed to list notifications: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NotificationListResponse{
+		PaginatedResponse: PaginatedResponse{Data: protoJSONList(resp.GetNotifications()), Pagination: newPaginationMeta(r, page, perPage, int(resp.GetTotalCount()))},
+		UnreadCount:       int(resp.GetUnreadCount()),
+	})
+}
+
+// MarkNotificationsRead godoc
+// @Summary Mark notifications read
+// @Description Mark the given notifications read, or all of them when ids is empty. IDs of other users' notifications are ignored.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MarkNotificationsReadRequest true "Notification IDs"
+// @Success 200 {object} notificationpb.MarkNotificationsReadResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/notifications/read [post]
+func (h *NotificationHandler) MarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req MarkNotificationsReadRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	resp, err := h.notificationClient.MarkNotificationsRead(r.Context(), &notificationpb.MarkNotificationsReadRequest{
+		UserId: int64(userID),
+		Ids:    req.IDs,
+	})
+	if err != nil {
+		logger.Errorf("failed to mark notifications read: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(w, http.StatusOK, resp)
+}
+
+// UpdatePreferences godoc
+// @Summary Update notification preferences
+// @Description Replace the caller's email, SMS and push toggles. Password reset emails are sent regardless.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body NotificationPreferencesRequest true "Channel toggles"
+// @Success 200 {object} notificationpb.UpdateNotificationPreferencesResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req NotificationPreferencesRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	resp, err := h.notificationClient.UpdateNotificationPreferences(r.Context(), &notificationpb.UpdateNotificationPreferencesRequest{
+		UserId: int64(userID),
+		Preferences: &notificationpb.NotificationPreferences{
+			Email: *req.Email,
+			Sms:   *req.SMS,
+			Push:  *req.Push,
+		},
+	})
+	if err != nil {
+		logger.Errorf("failed to update notification preferences: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	notificationpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/notification"
+)
+
+func TestListNotificationsIsTheCallersOwn(t *testing.T) {
+	var asked *notificationpb.ListNotificationsRequest
+	h := NewNotificationHandler(&fakeNotificationClient{listNotifications: func(in *notificationpb.ListNotificationsRequest) (*notificationpb.ListNotificationsResponse, error) {
+		asked = in
+		return &notificationpb.ListNotificationsResponse{Notifications: []*notificationpb.Notification{{Id: 3}}, TotalCount: 11, UnreadCount: 4}, nil
+	}})
+
+	// A user_id in the query is ignored; the token decides whose notifications these are
+	w := serve(t, testRequest{method: http.MethodGet, route: "/api/v1/notifications", target: "/api/v1/notifications?page=2&user_id=8", userID: 7}, wrap(h.ListNotifications))
+	var body NotificationListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("got %d %s", w.Code, w.Body)
+	}
+	if asked.GetUserId() != 7 || asked.GetPage() != 2 {
+		t.Errorf("asked for %+v, want page 2 of user 7", asked)
+	}
+	if body.UnreadCount != 4 || body.Pagination.Total != 11 || !body.Pagination.HasPrev {
+		t.Errorf("body = %s, want 4 unread of 11 on page 2", w.Body)
+	}
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantIDs    []int64
+		wantStatus int
+	}{
+		{name: "given ids", body: `{"ids": [3, 5]}`, wantIDs: []int64{3, 5}, wantStatus: http.StatusOK},
+		{name: "all", body: `{}`, wantStatus: http.StatusOK},
+		{name: "invalid id", body: `{"ids": [0]}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var asked *notificationpb.MarkNotificationsReadRequest
+			h := NewNotificationHandler(&fakeNotificationClient{markRead: func(in *notificationpb.MarkNotificationsReadRequest) (*notificationpb.MarkNotificationsReadResponse, error) {
+				asked = in
+				return &notificationpb.MarkNotificationsReadResponse{UpdatedCount: int32(len(in.GetIds()))}, nil
+			}})
+
+			w := serve(t, testRequest{method: http.MethodPost, route: "/api/v1/notifications/read", target: "/api/v1/notifications/read", body: tt.body, userID: 7}, wrap(h.MarkNotificationsRead))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if asked != nil {
+					t.Errorf("an invalid request reached the service: %+v", asked)
+				}
+				return
+			}
+			if asked.GetUserId() != 7 || !reflect.DeepEqual(asked.GetIds(), tt.wantIDs) {
+				t.Errorf("asked for %+v, want ids %v of user 7", asked, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestUpdatePreferencesNeedsEveryToggle(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "every toggle", body: `{"email": false, "sms": true, "push": false}`, wantStatus: http.StatusOK},
+		// A missing toggle would otherwise be saved as off
+		{name: "push missing", body: `{"email": false, "sms": true}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var asked *notificationpb.UpdateNotificationPreferencesRequest
+			h := NewNotificationHandler(&fakeNotificationClient{updatePreferences: func(in *notificationpb.UpdateNotificationPreferencesRequest) (*notificationpb.UpdateNotificationPreferencesResponse, error) {
+				asked = in
+				return &notificationpb.UpdateNotificationPreferencesResponse{Preferences: in.GetPreferences()}, nil
+			}})
+
+			w := serve(t, testRequest{method: http.MethodPut, route: "/api/v1/notifications/preferences", target: "/api/v1/notifications/preferences", body: tt.body, userID: 7}, wrap(h.UpdatePreferences))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if asked != nil {
+					t.Errorf("an incomplete request reached the service: %+v", asked)
+				}
+				return
+			}
+			if preferences := asked.GetPreferences(); asked.GetUserId() != 7 || preferences.GetEmail() || !preferences.GetSms() || preferences.GetPush() {
+				t.Errorf("asked for %+v, want only SMS on for user 7", asked)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+)
+
+// exportColumns is the header row of an order export
+var exportColumns = []string{"order_id", "user_id", "status", "shipping_cost", "discount", "total", "created_at"}
+
+// ExportOrders godoc
+// @Summary Export orders as CSV
+// @Description Stream every order matching the filters as CSV, oldest first (admin only). Rows are sent as the
+// @Description order service pages through the orders, so an export of any size is never held in memory. The last
+// @Description line is "# rows: N". A file that ends in "# error: ..." failed partway, and one that ends in neither was cut off.
+// @Tags orders
+// @Produce text/csv
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param from query string false "Created on or after (YYYY-MM-DD)"
+// @Param to query string false "Created on or before (YYYY-MM-DD)"
+// @Param status query string false "pending, paid, shipped, delivered or canceled"
+// @Success 200 {string} string "CSV of order_id, user_id, status, shipping_cost, discount, total and created_at"
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/admin/orders/export [get]
+func (h *OrderHandler) ExportOrders(c *gin.Context) {
+	query := c.Request.URL.Query()
+	if err := checkOrderFilters(query, "from", "to"); err != nil {
+		writeJSONError(c.Writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Returning for any reason, including the client going away, tears down the gRPC stream
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	stream, err := h.orderClient.ExportOrders(ctx, &orderpb.ExportOrdersRequest{
+		Status:    query.Get("status"),
+		StartDate: query.Get("from"),
+		EndDate:   query.Get("to"),
+	})
+	if err != nil {
+		logger.Errorf("failed to export orders: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Errors such as a missing permission only surface on the first Recv, while a JSON error can still be sent
+	batch, err := stream.Recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		logger.Errorf("failed to export orders: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	// The server's WriteTimeout would cut a large export off long before the route deadline does
+	rc := http.NewResponseController(c.Writer)
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := rc.SetWriteDeadline(deadline); err != nil {
+			logger.Warnf("failed to extend write deadline for order export: %v", err)
+		}
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/csv; charset=utf-8")
+	header.Set("Content-Disposition", `attachment; filename="orders.csv"`)
+	header.Set("Cache-Control", "no-store")
+	header.Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	// On shutdown the export stops without its row count, so the client can tell it is incomplete
+	go func() {
+		select {
+		case <-middleware.StreamClosing(c.Request.Context()):
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write(exportColumns)
+	rows := 0
+	for ; err == nil; batch, err = stream.Recv() {
+		for _, order := range batch.GetOrders() {
+			writer.Write(exportRow(order))
+		}
+		rows += len(batch.GetOrders())
+
+		writer.Flush()
+		if writer.Error() != nil {
+			// Client disconnected
+			return
+		}
+		rc.Flush()
+	}
+	// An empty export still gets its header row
+	writer.Flush()
+
+	switch {
+	case errors.Is(err, io.EOF):
+		fmt.Fprintf(c.Writer, "# rows: %d\n", rows)
+	case ctx.Err() != nil:
+		// Client disconnected or the gateway is shutting down
+		return
+	default:
+		logger.Errorf("order export failed after %d rows: %v", rows, err)
+		fmt.Fprintf(c.Writer, "# error: export failed after %d rows\n", rows)
+	}
+	rc.Flush()
+}
+
+func exportRow(order *orderpb.Order) []string {
+	return []string{
+		strconv.FormatInt(order.GetId(), 10),
+		strconv.FormatInt(order.GetUserId(), 10),
+		order.GetStatus(),
+		amount(order.GetShippingCost()),
+		amount(order.GetDiscount()),
+		amount(order.GetTotal()),
+		order.GetCreatedAt(),
+	}
+}
+
+func amount(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', 2, 32)
+}
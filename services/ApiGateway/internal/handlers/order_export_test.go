@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportOrders serves GET target from an order service whose export stream is stream
+func exportOrders(t *testing.T, target string, stream *batchStream) (*orderpb.ExportOrdersRequest, int, string, http.Header) {
+	t.Helper()
+	var sent *orderpb.ExportOrdersRequest
+	orders := &fakeOrderClient{exportOrders: func(in *orderpb.ExportOrdersRequest) (*batchStream, error) {
+		sent = in
+		return stream, nil
+	}}
+	w := serve(t, testRequest{method: http.MethodGet, route: "/api/v1/admin/orders/export", target: target}, NewOrderHandler(orders, nil, nil).ExportOrders)
+	return sent, w.Code, w.Body.String(), w.Header()
+}
+
+func TestExportOrdersStreamsCSV(t *testing.T) {
+	stream := &batchStream{batches: []*orderpb.ExportOrdersResponse{
+		{Orders: []*orderpb.Order{
+			{Id: 1, UserId: 7, Status: "paid", ShippingCost: 5, Total: 25.5, CreatedAt: "2026-03-01T09:00:00Z"},
+			{Id: 2, UserId: 8, Status: "paid", Discount: 2, Total: 8, CreatedAt: "2026-03-01T10:00:00Z"},
+		}},
+		{Orders: []*orderpb.Order{{Id: 3, UserId: 7, Status: "paid", Total: 1.25, CreatedAt: "2026-03-02T08:00:00Z"}}},
+	}}
+
+	sent, code, body, header := exportOrders(t, "/api/v1/admin/orders/export?status=paid&from=2026-03-01&to=2026-03-31", stream)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", code, body)
+	}
+	if sent.GetStatus() != "paid" || sent.GetStartDate() != "2026-03-01" || sent.GetEndDate() != "2026-03-31" {
+		t.Errorf("the order service got %v, want the query's filters", sent)
+	}
+	want := "order_id,user_id,status,shipping_cost,discount,total,created_at\n" +
+		"1,7,paid,5.00,0.00,25.50,2026-03-01T09:00:00Z\n" +
+		"2,8,paid,0.00,2.00,8.00,2026-03-01T10:00:00Z\n" +
+		"3,7,paid,0.00,0.00,1.25,2026-03-02T08:00:00Z\n" +
+		"# rows: 3\n"
+	if body != want {
+		t.Errorf("body:\n%s\nwant:\n%s", body, want)
+	}
+	if contentType, disposition := header.Get("Content-Type"), header.Get("Content-Disposition"); contentType != "text/csv; charset=utf-8" || disposition != `attachment; filename="orders.csv"` {
+		t.Errorf("sent as %q, %q; want an orders.csv attachment", contentType, disposition)
+	}
+}
+
+func TestExportOrdersEnds(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		stream   *batchStream
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "no orders",
+			stream:   &batchStream{},
+			wantCode: http.StatusOK,
+			wantBody: "order_id,user_id,status,shipping_cost,discount,total,created_at\n# rows: 0\n",
+		},
+		{
+			// The stream fails before any row, while a JSON error can still be sent
+			name:     "refused",
+			stream:   &batchStream{end: status.Error(codes.PermissionDenied, "missing permission order:read")},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "failed partway",
+			stream: &batchStream{
+				batches: []*orderpb.ExportOrdersResponse{{Orders: []*orderpb.Order{{Id: 1, UserId: 7, Status: "paid", CreatedAt: "2026-03-01T09:00:00Z"}}}},
+				end:     status.Error(codes.Unavailable, "connection reset"),
+			},
+			wantCode: http.StatusOK,
+			wantBody: "order_id,user_id,status,shipping_cost,discount,total,created_at\n" +
+				"1,7,paid,0.00,0.00,0.00,2026-03-01T09:00:00Z\n" +
+				"# error: export failed after 1 rows\n",
+		},
+		{name: "bad date", target: "?from=March", stream: &batchStream{}, wantCode: http.StatusBadRequest},
+		{name: "bad status", target: "?status=lost", stream: &batchStream{}, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, code, body, _ := exportOrders(t, "/api/v1/admin/orders/export"+tt.target, tt.stream)
+			if code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", code, tt.wantCode, body)
+			}
+			if tt.wantBody != "" && body != tt.wantBody {
+				t.Errorf("body:\n%s\nwant:\n%s", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -1,58 +1,89 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"sync/atomic"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
 	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	shippingpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/shipping"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // OrderHandler handles order-related HTTP requests
 type OrderHandler struct {
-	orderClient orderpb.OrderServiceClient
+	orderClient    orderpb.OrderServiceClient
+	shippingClient shippingpb.ShippingServiceClient
+	userClient     userpb.UserServiceClient
 }
 
 // NewOrderHandler creates a new order handler
-func NewOrderHandler(orderClient orderpb.OrderServiceClient) *OrderHandler {
+func NewOrderHandler(orderClient orderpb.OrderServiceClient, shippingClient shippingpb.ShippingServiceClient, userClient userpb.UserServiceClient) *OrderHandler {
 	return &OrderHandler{
-		orderClient: orderClient,
+		orderClient:    orderClient,
+		shippingClient: shippingClient,
+		userClient:     userClient,
 	}
 }
 
 // CreateOrder godoc
 // @Summary Create order
-// @Description Create a new order
+// @Description Create a new order, shipped to address_id or the user's default address when omitted.
+// @Description shipping_option_id must be an option from the shipping quote; only admins may instead set
+// @Description shipping_cost and shipping_duration_days, which are ignored for everyone else.
+// @Description An identical request from the same user within DEDUP_TTL is answered with the first response
+// @Description and the X-Deduplicated header instead of creating a second order; one sent while the first is
+// @Description still running gets 409, as does an order for more than a product has in stock.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body CreateOrderRequest true "Order details"
-// @Success 201 {object} CreateOrderResponse
+// @Success 201 {object} orderpb.CreateOrderResponse
+// @Failure 409 {object} map[string]string
 // @Router /api/v1/orders [post]
+// @DeprecatedRouter /api/v1/orders/create [post]
 func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
-	userID, ok := middleware.GetUserID(r.Context())
+	claims, ok := middleware.GetUserClaims(r.Context())
 	if !ok {
 		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
+	userID := claims.UserID
+
+	var req CreateOrderRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeRequestError(w, err)
+		return
+	}
 
-	var req struct {
-		ShippingCost         float32 `json:"shipping_cost"`
-		ShippingDurationDays int32   `json:"shipping_duration_days"`
-		Discount             float32 `json:"discount"`
-		Items                []struct {
-			ProductID int64 `json:"product_id"`
-			Quantity  int32 `json:"quantity"`
-		} `json:"items"`
+	// Shipping is priced server-side from the chosen option; raw values are only trusted from admins
+	if !claims.HasRole("admin") {
+		if req.ShippingOptionID == "" {
+			writeJSONError(w, http.StatusBadRequest, "shipping_option_id is required")
+			return
+		}
+		req.ShippingCost = 0
+		req.ShippingDurationDays = 0
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+	addressID, ok := h.resolveAddress(w, r, userID, req.AddressID)
+	if !ok {
 		return
 	}
+	req.AddressID = addressID
 
 	items := make([]*orderpb.OrderItemInput, 0, len(req.Items))
 	for _, item := range req.Items {
@@ -68,6 +99,8 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		ShippingDurationDays: req.ShippingDurationDays,
 		Discount:             req.Discount,
 		Items:                items,
+		AddressId:            req.AddressID,
+		ShippingOptionId:     req.ShippingOptionID,
 	})
 	if err != nil {
 		logger.Errorf("failed to create order: %v", err)
@@ -75,7 +108,60 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, resp)
+	writeProtoJSON(w, http.StatusCreated, resp)
+}
+
+// GetShippingQuote godoc
+// @Summary Quote shipping options
+// @Description List the shipping options, with cost and estimated delivery, for sending items to address_id or the
+// @Description user's default address when omitted. Pass the chosen option's id to CreateOrder as shipping_option_id.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ShippingQuoteRequest true "Destination and items"
+// @Success 200 {object} shippingpb.GetShippingQuoteResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/orders/shipping-quote [post]
+func (h *OrderHandler) GetShippingQuote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req ShippingQuoteRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	addressID, ok := h.resolveAddress(w, r, userID, req.AddressID)
+	if !ok {
+		return
+	}
+
+	items := make([]*shippingpb.ShippingQuoteItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, &shippingpb.ShippingQuoteItem{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	resp, err := h.shippingClient.GetShippingQuote(r.Context(), &shippingpb.GetShippingQuoteRequest{
+		UserId:    int64(userID),
+		AddressId: addressID,
+		Items:     items,
+	})
+	if err != nil {
+		logger.Errorf("failed to quote shipping: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(w, http.StatusOK, resp)
 }
 
 // GetOrderByID godoc
@@ -84,11 +170,12 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 // @Tags orders
 // @Produce json
 // @Security BearerAuth
-// @Param id query int true "Order ID"
-// @Success 200 {object} GetOrderByIDResponse
-// @Router /api/v1/orders/by-id [get]
+// @Param id path int true "Order ID"
+// @Success 200 {object} orderpb.GetOrderByIDResponse
+// @Router /api/v1/orders/{id} [get]
+// @DeprecatedRouter /api/v1/orders/by-id [get]
 func (h *OrderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Query().Get("id")
+	idStr := resourceID(r)
 	if idStr == "" {
 		writeJSONError(w, http.StatusBadRequest, "missing order ID")
 		return
@@ -109,81 +196,208 @@ func (h *OrderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeProtoJSON(w, http.StatusOK, resp)
 }
 
 // ListOrders godoc
 // @Summary List orders
-// @Description List orders with pagination
+// @Description List the caller's orders with pagination, optionally created within a date range. Admins may pass user_id to list another user's orders, and status to filter them.
 // @Tags orders
 // @Produce json
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
-// @Param per_page query int false "Items per page" default(10)
-// @Param user_id query int false "Filter by user ID (admin only)"
-// @Success 200 {object} ListOrdersResponse
+// @Param per_page query int false "Items per page, capped at 100" default(10)
+// @Param user_id query int false "Another user's ID (admin only, ignored for everyone else)"
+// @Param status query string false "pending, paid, shipped, delivered or canceled (admin only)"
+// @Param start_date query string false "Created on or after (YYYY-MM-DD)"
+// @Param end_date query string false "Created on or before (YYYY-MM-DD)"
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /api/v1/orders [get]
 func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
+	claims, ok := middleware.GetUserClaims(r.Context())
+	if !ok {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+		return
 	}
 
-	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
-	if perPage < 1 || perPage > 100 {
-		perPage = 10
+	// user_id is only trusted from admins; everyone else is pinned to their own orders whatever they send
+	userID := int64(claims.UserID)
+	if raw := r.URL.Query().Get("user_id"); raw != "" && claims.HasRole("admin") {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "user_id must be a positive integer")
+			return
+		}
+		userID = id
 	}
 
-	userIDParam := r.URL.Query().Get("user_id")
-	var userIDFilter int64
-	if userIDParam != "" {
-		userIDFilter, _ = strconv.ParseInt(userIDParam, 10, 64)
-	} else {
-		userID, ok := middleware.GetUserID(r.Context())
-		if ok {
-			userIDFilter = int64(userID)
-		}
+	// Filtering by status is for admins; everyone else may only narrow their orders down by date
+	if r.URL.Query().Has("status") && !claims.HasRole("admin") {
+		writeJSONError(w, http.StatusForbidden, "only admins may filter orders by status")
+		return
 	}
 
-	resp, err := h.orderClient.ListOrders(r.Context(), &orderpb.ListOrdersRequest{
+	page, perPage := parsePagination(r)
+
+	req := &orderpb.ListOrdersRequest{
 		Page:    int32(page),
 		PerPage: int32(perPage),
-		UserId:  userIDFilter,
-	})
+		UserId:  userID,
+	}
+	if err := parseOrderFilters(r.URL.Query(), req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.orderClient.ListOrders(r.Context(), req)
+	if err != nil {
+		logger.Errorf("failed to list orders: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONPaginated(w, http.StatusOK, protoJSONList(resp.GetOrders()), newPaginationMeta(r, page, perPage, int(resp.GetTotalCount())))
+}
+
+// AdminListOrders godoc
+// @Summary List all orders
+// @Description List orders across all users with filtering and sorting (admin only)
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page, capped at 100" default(10)
+// @Param status query string false "pending, paid, shipped, delivered or canceled"
+// @Param user_id query int false "Filter by user ID"
+// @Param start_date query string false "Created on or after (YYYY-MM-DD)"
+// @Param end_date query string false "Created on or before (YYYY-MM-DD)"
+// @Param sort_by query string false "id, created_at or total" default(id)
+// @Param sort_order query string false "asc or desc" default(desc)
+// @Success 200 {object} PaginatedResponse
+// @Router /api/v1/admin/orders [get]
+func (h *OrderHandler) AdminListOrders(w http.ResponseWriter, r *http.Request) {
+	page, perPage := parsePagination(r)
+
+	req, err := parseAdminOrdersQuery(r.URL.Query())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.Page = int32(page)
+	req.PerPage = int32(perPage)
+
+	resp, err := h.orderClient.ListOrders(r.Context(), req)
 	if err != nil {
 		logger.Errorf("failed to list orders: %v", err)
 		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeJSONPaginated(w, http.StatusOK, protoJSONList(resp.GetOrders()), newPaginationMeta(r, page, perPage, int(resp.GetTotalCount())))
+}
+
+func parseAdminOrdersQuery(query url.Values) (*orderpb.ListOrdersRequest, error) {
+	req := &orderpb.ListOrdersRequest{
+		SortBy:    query.Get("sort_by"),
+		SortOrder: query.Get("sort_order"),
+	}
+
+	if userID := query.Get("user_id"); userID != "" {
+		id, err := strconv.ParseInt(userID, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("user_id must be a positive integer")
+		}
+		req.UserId = id
+	}
+
+	if err := parseOrderFilters(query, req); err != nil {
+		return nil, err
+	}
+
+	switch req.SortBy {
+	case "", "id", "created_at", "total":
+	default:
+		return nil, fmt.Errorf("sort_by must be one of id, created_at or total")
+	}
+	switch req.SortOrder {
+	case "", "asc", "desc":
+	default:
+		return nil, fmt.Errorf("sort_order must be asc or desc")
+	}
+
+	return req, nil
+}
+
+// parseOrderFilters checks the status and creation date range filters and sets them on req
+func parseOrderFilters(query url.Values, req *orderpb.ListOrdersRequest) error {
+	req.Status = query.Get("status")
+	req.StartDate = query.Get("start_date")
+	req.EndDate = query.Get("end_date")
+	return checkOrderFilters(query, "start_date", "end_date")
+}
+
+// checkOrderFilters checks the status filter of query and the creation date range in its startParam and
+// endParam parameters
+func checkOrderFilters(query url.Values, startParam, endParam string) error {
+	switch query.Get("status") {
+	case "", "pending", "paid", "shipped", "delivered", "canceled":
+	default:
+		return fmt.Errorf("status must be one of pending, paid, shipped, delivered or canceled")
+	}
+
+	var start, end time.Time
+	var err error
+	if value := query.Get(startParam); value != "" {
+		if start, err = time.Parse(reportDateLayout, value); err != nil {
+			return fmt.Errorf("%s must be in YYYY-MM-DD format", startParam)
+		}
+	}
+	if value := query.Get(endParam); value != "" {
+		if end, err = time.Parse(reportDateLayout, value); err != nil {
+			return fmt.Errorf("%s must be in YYYY-MM-DD format", endParam)
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && start.After(end) {
+		return fmt.Errorf("%s must not be after %s", startParam, endParam)
+	}
+	return nil
 }
 
 // AddOrderItem godoc
 // @Summary Add item to order
-// @Description Add a new item to an existing order
+// @Description Add a new item to an existing order. Answers 409 when the product has too little stock.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param id path int true "Order ID"
 // @Param request body AddOrderItemRequest true "Order item details"
-// @Success 200 {object} AddOrderItemResponse
-// @Router /api/v1/orders/items/add [post]
+// @Success 200 {object} orderpb.AddOrderItemResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/orders/{id}/items [post]
+// @DeprecatedRouter /api/v1/orders/items/add [post]
 func (h *OrderHandler) AddOrderItem(w http.ResponseWriter, r *http.Request) {
-	var req orderpb.AddOrderItemRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+	var req AddOrderItemRequest
+	if err := decodeRequestWithPathID(r, &req, &req.OrderID); err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
-	resp, err := h.orderClient.AddOrderItem(r.Context(), &req)
+	resp, err := h.orderClient.AddOrderItem(r.Context(), &orderpb.AddOrderItemRequest{
+		OrderId:   req.OrderID,
+		ProductId: req.ProductID,
+		Quantity:  req.Quantity,
+	})
 	if err != nil {
 		logger.Errorf("failed to add order item: %v", err)
 		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeProtoJSON(w, http.StatusOK, resp)
 }
 
 // RemoveOrderItem godoc
@@ -193,13 +407,27 @@ func (h *OrderHandler) AddOrderItem(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body RemoveOrderItemRequest true "Order item ID"
-// @Success 200 {object} RemoveOrderItemResponse
-// @Router /api/v1/orders/items/remove [delete]
+// @Param id path int true "Order ID"
+// @Param itemID path int true "Order item ID"
+// @Param request body orderpb.RemoveOrderItemRequest false "Order and item ID, only on the deprecated route"
+// @Success 200 {object} orderpb.RemoveOrderItemResponse
+// @Router /api/v1/orders/{id}/items/{itemID} [delete]
+// @DeprecatedRouter /api/v1/orders/items/remove [delete]
 func (h *OrderHandler) RemoveOrderItem(w http.ResponseWriter, r *http.Request) {
+	// DELETE /api/v1/orders/:id/items/:itemID names the item in its path and has no body; the deprecated
+	// route names it in the body
 	var req orderpb.RemoveOrderItemRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+	if _, ok := middleware.GetPathParam(r.Context(), "itemID"); ok {
+		if err := pathID(r, "id", &req.OrderId); err != nil {
+			writeRequestError(w, err)
+			return
+		}
+		if err := pathID(r, "itemID", &req.ItemId); err != nil {
+			writeRequestError(w, err)
+			return
+		}
+	} else if err := decodeJSON(r, &req); err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
@@ -210,32 +438,314 @@ func (h *OrderHandler) RemoveOrderItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeProtoJSON(w, http.StatusOK, resp)
+}
+
+// UpdateOrderItemQuantity godoc
+// @Summary Change an order item's quantity
+// @Description Set the quantity of an item on one of the caller's orders. Only pending orders can be changed.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param itemID path int true "Order item ID"
+// @Param request body UpdateOrderItemQuantityRequest true "New quantity"
+// @Success 200 {object} orderpb.UpdateOrderItemQuantityResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/orders/{id}/items/{itemID} [patch]
+func (h *OrderHandler) UpdateOrderItemQuantity(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c.Request.Context())
+	if !ok {
+		writeJSONError(c.Writer, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid order ID")
+		return
+	}
+	itemID, err := strconv.ParseInt(c.Param("itemID"), 10, 64)
+	if err != nil || itemID <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid order item ID")
+		return
+	}
+
+	var req UpdateOrderItemQuantityRequest
+	if err := decodeRequest(c.Request, &req); err != nil {
+		writeRequestError(c.Writer, err)
+		return
+	}
+
+	orderResp, err := h.orderClient.GetOrderByID(c.Request.Context(), &orderpb.GetOrderByIDRequest{Id: orderID})
+	if err != nil {
+		logger.Errorf("failed to get order: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusNotFound)
+		return
+	}
+	order := orderResp.GetOrder()
+	if order.GetUserId() != int64(userID) {
+		writeJSONError(c.Writer, http.StatusForbidden, "order does not belong to user")
+		return
+	}
+	if order.GetStatus() != "pending" {
+		writeJSONError(c.Writer, http.StatusConflict, "only pending orders can be changed")
+		return
+	}
+
+	resp, err := h.orderClient.UpdateOrderItemQuantity(c.Request.Context(), &orderpb.UpdateOrderItemQuantityRequest{
+		OrderId:  orderID,
+		ItemId:   itemID,
+		Quantity: req.Quantity,
+	})
+	if err != nil {
+		// The order can still leave pending between the check above and the update, which answers 409
+		logger.Errorf("failed to update order item quantity: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
 }
 
 // UpdateOrderStatus godoc
 // @Summary Update order status
-// @Description Update the status of an order (admin only)
+// @Description Update the status of an order (admin only). Canceling puts the order's stock back, so a canceled
+// @Description order cannot move to another status and answers 409.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "Order ID"
 // @Param request body UpdateOrderStatusRequest true "Status update details"
-// @Success 200 {object} UpdateOrderStatusResponse
-// @Router /api/v1/orders/status [patch]
+// @Success 200 {object} orderpb.UpdateOrderStatusResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/orders/{id}/status [patch]
+// @DeprecatedRouter /api/v1/orders/status [patch]
 func (h *OrderHandler) UpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
-	var req orderpb.UpdateOrderStatusRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+	var req UpdateOrderStatusRequest
+	if err := decodeRequestWithPathID(r, &req, &req.OrderID); err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
-	resp, err := h.orderClient.UpdateOrderStatus(r.Context(), &req)
+	resp, err := h.orderClient.UpdateOrderStatus(r.Context(), &orderpb.UpdateOrderStatusRequest{
+		OrderId: req.OrderID,
+		Status:  req.Status,
+	})
 	if err != nil {
 		logger.Errorf("failed to update order status: %v", err)
 		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeProtoJSON(w, http.StatusOK, resp)
+}
+
+// GetOrderInvoice godoc
+// @Summary Download an order invoice
+// @Description Download the order's invoice as a PDF. It is issued with the next invoice number on the first request
+// @Description once the order is paid, and every later download returns the same file (owner or admin)
+// @Tags orders
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param If-None-Match header string false "ETag of the file already held"
+// @Success 200 {file} file "The invoice, named after its number"
+// @Success 304 "The file held is current"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "The order is not paid yet, or was canceled before an invoice was issued"
+// @Router /api/v1/orders/{id}/invoice [get]
+func (h *OrderHandler) GetOrderInvoice(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid order ID")
+		return
+	}
+
+	resp, err := h.orderClient.GetOrderInvoice(c.Request.Context(), &orderpb.GetOrderInvoiceRequest{OrderId: id})
+	if err != nil {
+		logger.Errorf("failed to get order invoice: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	// The file never changes once issued, so clients revalidate it by ETag instead of downloading it again
+	header := c.Writer.Header()
+	etag := generateETag(resp.GetPdf())
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "private, no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", "application/pdf")
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, resp.GetInvoiceNumber()))
+	header.Set("Content-Length", strconv.Itoa(len(resp.GetPdf())))
+	c.Writer.WriteHeader(http.StatusOK)
+	if _, err := c.Writer.Write(resp.GetPdf()); err != nil {
+		logger.Warnf("failed to write invoice %s: %v", resp.GetInvoiceNumber(), err)
+	}
+}
+
+// WatchOrderStatus godoc
+// @Summary Stream order status
+// @Description Stream the order's status as server-sent events: a "status" event now and on every change,
+// @Description then a final "closed" event once the order is delivered or canceled, or "shutdown" when the gateway stops
+// @Tags orders
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {string} string "SSE stream of status events"
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/orders/{id}/status/stream [get]
+func (h *OrderHandler) WatchOrderStatus(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid order ID")
+		return
+	}
+
+	// Returning for any reason, including the client going away, tears down the gRPC stream
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	stream, err := h.orderClient.WatchOrderStatus(ctx, &orderpb.WatchOrderStatusRequest{OrderId: id})
+	if err != nil {
+		logger.Errorf("failed to watch order status: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Errors such as an unknown order only surface on the first Recv, while a JSON error can still be sent
+	event, err := stream.Recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		logger.Errorf("failed to watch order status: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusNotFound)
+		return
+	}
+
+	// The server's WriteTimeout would cut the stream off long before the route deadline does
+	rc := http.NewResponseController(c.Writer)
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := rc.SetWriteDeadline(deadline); err != nil {
+			logger.Warnf("failed to extend write deadline for order status stream: %v", err)
+		}
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	// On shutdown the stream ends with a "shutdown" event, telling the client to reconnect elsewhere
+	var shuttingDown atomic.Bool
+	go func() {
+		select {
+		case <-middleware.StreamClosing(c.Request.Context()):
+			shuttingDown.Store(true)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for ; err == nil; event, err = stream.Recv() {
+		data, marshalErr := protoJSON.Marshal(event)
+		if marshalErr != nil {
+			logger.Errorf("failed to marshal order status event: %v", marshalErr)
+			return
+		}
+		writeSSEEvent(c.Writer, "status", data)
+		rc.Flush()
+	}
+
+	switch {
+	case errors.Is(err, io.EOF):
+		writeSSEEvent(c.Writer, "closed", []byte("{}"))
+	case shuttingDown.Load():
+		writeSSEEvent(c.Writer, "shutdown", []byte("{}"))
+	case ctx.Err() != nil:
+		// Client disconnected; nothing is listening for further frames
+		return
+	default:
+		logger.Errorf("order status stream failed: %v", err)
+		data, _ := json.Marshal(ErrorResponse{
+			Error:   http.StatusText(http.StatusBadGateway),
+			Message: status.Convert(err).Message(),
+			Code:    http.StatusBadGateway,
+		})
+		writeSSEEvent(c.Writer, "error", data)
+	}
+	rc.Flush()
+}
+
+// writeSSEEvent writes a single server-sent event frame
+func writeSSEEvent(w io.Writer, event string, data []byte) {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
+
+// resolveAddress returns addressID, or the user's default address when it is 0, once it is known to be theirs.
+// On failure it writes the error response and returns false.
+func (h *OrderHandler) resolveAddress(w http.ResponseWriter, r *http.Request, userID uint, addressID int64) (int64, bool) {
+	addressID, err := ownedAddressID(r.Context(), h.userClient, userID, addressID)
+	if err != nil {
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return 0, false
+	}
+	return addressID, true
+}
+
+// ownedAddressID is resolveAddress for callers without a response to write. Its errors are gRPC statuses.
+func ownedAddressID(ctx context.Context, userClient userpb.UserServiceClient, userID uint, addressID int64) (int64, error) {
+	if addressID == 0 {
+		defaultID, err := defaultAddressID(ctx, userClient, userID)
+		if err != nil {
+			logger.Errorf("failed to list addresses: %v", err)
+			return 0, err
+		}
+		if defaultID == 0 {
+			return 0, status.Error(codes.InvalidArgument, "address_id is required")
+		}
+		addressID = defaultID
+	}
+
+	addressResp, err := userClient.GetAddressByID(ctx, &userpb.GetAddressByIDRequest{
+		Id: int32(addressID),
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return 0, status.Error(codes.InvalidArgument, "address not found")
+		}
+		logger.Errorf("failed to get address: %v", err)
+		return 0, err
+	}
+	if addressResp.GetAddress().GetUserId() != int32(userID) {
+		return 0, status.Error(codes.PermissionDenied, "address does not belong to user")
+	}
+	return addressID, nil
+}
+
+// defaultAddressID returns the user's default address, or 0 when they have none. The user service lists the
+// default address first, so the first page is enough.
+func defaultAddressID(ctx context.Context, userClient userpb.UserServiceClient, userID uint) (int64, error) {
+	resp, err := userClient.ListAddressesByUserID(ctx, &userpb.ListAddressesByUserIDRequest{UserId: int32(userID)})
+	if err != nil {
+		return 0, err
+	}
+	for _, address := range resp.GetAddresses() {
+		if address.GetIsDefault() {
+			return int64(address.GetId()), nil
+		}
+	}
+	return 0, nil
 }
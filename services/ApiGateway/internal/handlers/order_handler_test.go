@@ -0,0 +1,507 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// addressBook fakes a user service holding addresses, keyed by ID
+func addressBook(addresses ...*userpb.Address) *fakeUserClient {
+	return &fakeUserClient{
+		getAddressByID: func(in *userpb.GetAddressByIDRequest) (*userpb.GetAddressByIDResponse, error) {
+			for _, address := range addresses {
+				if address.Id == in.Id {
+					return &userpb.GetAddressByIDResponse{Address: address}, nil
+				}
+			}
+			return nil, status.Error(codes.NotFound, "address not found")
+		},
+		listAddresses: func(in *userpb.ListAddressesByUserIDRequest) (*userpb.ListAddressesByUserIDResponse, error) {
+			resp := &userpb.ListAddressesByUserIDResponse{}
+			for _, address := range addresses {
+				if address.UserId == in.UserId {
+					resp.Addresses = append(resp.Addresses, address)
+				}
+			}
+			return resp, nil
+		},
+	}
+}
+
+func TestOwnedAddressIDFallsBackToDefault(t *testing.T) {
+	users := addressBook(
+		&userpb.Address{Id: 1, UserId: 7},
+		&userpb.Address{Id: 2, UserId: 7, IsDefault: true},
+		&userpb.Address{Id: 3, UserId: 8},
+	)
+
+	tests := []struct {
+		name      string
+		userID    uint
+		addressID int64
+		want      int64
+		wantCode  codes.Code
+	}{
+		{name: "omitted uses the default", userID: 7, want: 2},
+		{name: "explicit address", userID: 7, addressID: 1, want: 1},
+		{name: "no default", userID: 8, wantCode: codes.InvalidArgument},
+		{name: "address of another user", userID: 7, addressID: 3, wantCode: codes.PermissionDenied},
+		{name: "unknown address", userID: 7, addressID: 9, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ownedAddressID(context.Background(), users, tt.userID, tt.addressID)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("error = %v, want code %v", err, tt.wantCode)
+			}
+			if got != tt.want {
+				t.Errorf("address = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// orderLister fakes an order service listing no orders, keeping the requests it was sent
+func orderLister() (*fakeOrderClient, *[]*orderpb.ListOrdersRequest) {
+	var requests []*orderpb.ListOrdersRequest
+	return &fakeOrderClient{listOrders: func(in *orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+		requests = append(requests, in)
+		return &orderpb.ListOrdersResponse{}, nil
+	}}, &requests
+}
+
+func TestListOrdersIsScopedToTheCaller(t *testing.T) {
+	tests := []struct {
+		name       string
+		roles      []string
+		query      string
+		wantStatus int
+		wantUserID int64
+	}{
+		{name: "own orders", roles: []string{"customer"}, wantStatus: http.StatusOK, wantUserID: 7},
+		{name: "another user's orders", roles: []string{"customer"}, query: "?user_id=8", wantStatus: http.StatusOK, wantUserID: 7},
+		{name: "invalid user_id", roles: []string{"customer"}, query: "?user_id=abc", wantStatus: http.StatusOK, wantUserID: 7},
+		{name: "support staff", roles: []string{"support"}, query: "?user_id=8", wantStatus: http.StatusOK, wantUserID: 7},
+		{name: "status filter", roles: []string{"customer"}, query: "?status=paid", wantStatus: http.StatusForbidden},
+		{name: "admin", roles: []string{"admin"}, query: "?user_id=8&status=paid", wantStatus: http.StatusOK, wantUserID: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders, requests := orderLister()
+			h := NewOrderHandler(orders, nil, nil)
+
+			w := serve(t, testRequest{method: http.MethodGet, route: "/api/v1/orders", target: "/api/v1/orders" + tt.query, userID: 7, roles: tt.roles}, wrap(h.ListOrders))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if len(*requests) > 0 {
+					t.Fatalf("the order service was asked for %v", *requests)
+				}
+				return
+			}
+			if len(*requests) != 1 || (*requests)[0].GetUserId() != tt.wantUserID {
+				t.Fatalf("requests = %v, want one for user %d", *requests, tt.wantUserID)
+			}
+		})
+	}
+
+	t.Run("anonymous", func(t *testing.T) {
+		orders, requests := orderLister()
+		w := serve(t, testRequest{method: http.MethodGet, route: "/api/v1/orders", target: "/api/v1/orders?user_id=8"}, wrap(NewOrderHandler(orders, nil, nil).ListOrders))
+		if w.Code != http.StatusUnauthorized || len(*requests) > 0 {
+			t.Fatalf("got %d after %d requests, want 401 without asking the order service", w.Code, len(*requests))
+		}
+	})
+}
+
+func TestListOrdersFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []string
+		query string
+		want  *orderpb.ListOrdersRequest
+	}{
+		{name: "no filter", roles: []string{"customer"}, want: &orderpb.ListOrdersRequest{}},
+		{name: "start date", roles: []string{"customer"}, query: "start_date=2026-01-01", want: &orderpb.ListOrdersRequest{StartDate: "2026-01-01"}},
+		{name: "end date", roles: []string{"customer"}, query: "end_date=2026-01-31", want: &orderpb.ListOrdersRequest{EndDate: "2026-01-31"}},
+		{
+			name:  "date range",
+			roles: []string{"customer"},
+			query: "start_date=2026-01-01&end_date=2026-01-31",
+			want:  &orderpb.ListOrdersRequest{StartDate: "2026-01-01", EndDate: "2026-01-31"},
+		},
+		{name: "one day", roles: []string{"customer"}, query: "start_date=2026-01-01&end_date=2026-01-01", want: &orderpb.ListOrdersRequest{StartDate: "2026-01-01", EndDate: "2026-01-01"}},
+		{name: "admin by status", roles: []string{"admin"}, query: "status=shipped", want: &orderpb.ListOrdersRequest{Status: "shipped"}},
+		{
+			name:  "admin by status and date range",
+			roles: []string{"admin"},
+			query: "status=canceled&start_date=2026-01-01&end_date=2026-01-31",
+			want:  &orderpb.ListOrdersRequest{Status: "canceled", StartDate: "2026-01-01", EndDate: "2026-01-31"},
+		},
+		{
+			name:  "admin by user, status and date",
+			roles: []string{"admin"},
+			query: "user_id=8&status=pending&end_date=2026-01-31",
+			want:  &orderpb.ListOrdersRequest{UserId: 8, Status: "pending", EndDate: "2026-01-31"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders, requests := orderLister()
+			h := NewOrderHandler(orders, nil, nil)
+
+			w := serve(t, testRequest{method: http.MethodGet, route: "/api/v1/orders", target: "/api/v1/orders?" + tt.query, userID: 7, roles: tt.roles}, wrap(h.ListOrders))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+
+			want := proto.Clone(tt.want).(*orderpb.ListOrdersRequest)
+			want.Page, want.PerPage = 1, defaultPerPage
+			if want.UserId == 0 {
+				want.UserId = 7
+			}
+			if len(*requests) != 1 || !proto.Equal((*requests)[0], want) {
+				t.Fatalf("requests = %v, want %v", *requests, want)
+			}
+		})
+	}
+}
+
+func TestListOrdersRejectsInvalidFilters(t *testing.T) {
+	tests := []struct {
+		name       string
+		roles      []string
+		query      string
+		wantStatus int
+		want       string
+	}{
+		{name: "customer by status", roles: []string{"customer"}, query: "status=paid", wantStatus: http.StatusForbidden, want: "only admins may filter orders by status"},
+		{
+			name:       "customer by status and date",
+			roles:      []string{"customer"},
+			query:      "status=paid&start_date=2026-01-01",
+			wantStatus: http.StatusForbidden,
+			want:       "only admins may filter orders by status",
+		},
+		{name: "support by status", roles: []string{"support"}, query: "status=paid", wantStatus: http.StatusForbidden, want: "only admins may filter orders by status"},
+		// The guard does not depend on the value, so an empty status is refused too
+		{name: "customer by empty status", roles: []string{"customer"}, query: "status=", wantStatus: http.StatusForbidden, want: "only admins may filter orders by status"},
+		{
+			name:       "unknown status",
+			roles:      []string{"admin"},
+			query:      "status=cancelled",
+			wantStatus: http.StatusBadRequest,
+			want:       "status must be one of pending, paid, shipped, delivered or canceled",
+		},
+		{name: "invalid start date", roles: []string{"customer"}, query: "start_date=2026-13-01", wantStatus: http.StatusBadRequest, want: "start_date must be in YYYY-MM-DD format"},
+		{name: "start date with a time", roles: []string{"customer"}, query: "start_date=2026-01-01T00:00:00Z", wantStatus: http.StatusBadRequest, want: "start_date must be in YYYY-MM-DD format"},
+		{name: "invalid end date", roles: []string{"customer"}, query: "end_date=31/01/2026", wantStatus: http.StatusBadRequest, want: "end_date must be in YYYY-MM-DD format"},
+		{
+			name:       "start after end",
+			roles:      []string{"customer"},
+			query:      "start_date=2026-02-01&end_date=2026-01-31",
+			wantStatus: http.StatusBadRequest,
+			want:       "start_date must not be after end_date",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders, requests := orderLister()
+			h := NewOrderHandler(orders, nil, nil)
+
+			w := serve(t, testRequest{method: http.MethodGet, route: "/api/v1/orders", target: "/api/v1/orders?" + tt.query, userID: 7, roles: tt.roles}, wrap(h.ListOrders))
+			if w.Code != tt.wantStatus || errorMessage(t, w) != tt.want {
+				t.Fatalf("got %d %s, want %d %q", w.Code, w.Body, tt.wantStatus, tt.want)
+			}
+			if len(*requests) > 0 {
+				t.Fatalf("the order service was asked for %v", *requests)
+			}
+		})
+	}
+}
+
+func TestAdminListOrdersPassesTheFiltersOn(t *testing.T) {
+	orders, requests := orderLister()
+	h := NewOrderHandler(orders, nil, nil)
+
+	w := serve(t, testRequest{
+		method: http.MethodGet,
+		route:  "/api/v1/admin/orders",
+		target: "/api/v1/admin/orders?page=2&per_page=5&status=shipped&user_id=8&start_date=2026-01-01&end_date=2026-01-31&sort_by=total&sort_order=asc",
+		userID: 1,
+		roles:  []string{"admin"},
+	}, middleware.RequirePermission(customJWT.PermissionOrderRead), wrap(h.AdminListOrders))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+
+	want := &orderpb.ListOrdersRequest{
+		Page: 2, PerPage: 5, UserId: 8, Status: "shipped",
+		StartDate: "2026-01-01", EndDate: "2026-01-31", SortBy: "total", SortOrder: "asc",
+	}
+	if len(*requests) != 1 || !proto.Equal((*requests)[0], want) {
+		t.Fatalf("requests = %v, want %v", *requests, want)
+	}
+}
+
+func TestAdminListOrdersRejectsInvalidFilters(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "user_id=0", want: "user_id must be a positive integer"},
+		{query: "status=lost", want: "status must be one of pending, paid, shipped, delivered or canceled"},
+		{query: "start_date=01-01-2026", want: "start_date must be in YYYY-MM-DD format"},
+		{query: "start_date=2026-02-01&end_date=2026-01-01", want: "start_date must not be after end_date"},
+		{query: "sort_by=user_id", want: "sort_by must be one of id, created_at or total"},
+		{query: "sort_order=up", want: "sort_order must be asc or desc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			orders, requests := orderLister()
+			w := serve(t, testRequest{method: http.MethodGet, route: "/orders", target: "/orders?" + tt.query, userID: 1, roles: []string{"admin"}}, wrap(NewOrderHandler(orders, nil, nil).AdminListOrders))
+			if w.Code != http.StatusBadRequest || errorMessage(t, w) != tt.want {
+				t.Fatalf("got %d %s, want 400 %q", w.Code, w.Body, tt.want)
+			}
+			if len(*requests) > 0 {
+				t.Fatalf("the order service was asked for %v", *requests)
+			}
+		})
+	}
+}
+
+func TestAdminListOrdersIsForAdminsOnly(t *testing.T) {
+	for _, roles := range [][]string{{"customer"}, {"support"}} {
+		t.Run(roles[0], func(t *testing.T) {
+			orders, requests := orderLister()
+			w := serve(t, testRequest{method: http.MethodGet, route: "/api/v1/admin/orders", target: "/api/v1/admin/orders?user_id=8", userID: 7, roles: roles},
+				middleware.RequirePermission(customJWT.PermissionOrderRead), wrap(NewOrderHandler(orders, nil, nil).AdminListOrders))
+			if w.Code != http.StatusForbidden || len(*requests) > 0 {
+				t.Fatalf("got %d after %d requests, want 403 without asking the order service", w.Code, len(*requests))
+			}
+		})
+	}
+}
+
+func TestListOrdersDoesNotShowCustomersOtherUsersOrders(t *testing.T) {
+	// The order service lists whichever user's orders it is asked for
+	orders := &fakeOrderClient{listOrders: func(in *orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+		return &orderpb.ListOrdersResponse{
+			Orders:     []*orderpb.Order{{Id: in.GetUserId() * 100, UserId: in.GetUserId()}},
+			TotalCount: 1,
+		}, nil
+	}}
+	list := newTestEngine(http.MethodGet, "/api/v1/orders", wrap(NewOrderHandler(orders, nil, nil).ListOrders))
+	userIDs := func(w *httptest.ResponseRecorder) []string {
+		var body struct {
+			Data []struct {
+				UserID string `json:"user_id"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("got %d %s: %v", w.Code, w.Body, err)
+		}
+		var ids []string
+		for _, order := range body.Data {
+			ids = append(ids, order.UserID)
+		}
+		return ids
+	}
+
+	// The admin role does not have to be the primary one
+	admin := list(t, testRequest{method: http.MethodGet, target: "/api/v1/orders?user_id=8", userID: 1, roles: []string{"support", "admin"}})
+	if got := userIDs(admin); !reflect.DeepEqual(got, []string{"8"}) {
+		t.Fatalf("an admin asking for user 8 got the orders of %v", got)
+	}
+
+	customer := list(t, testRequest{method: http.MethodGet, target: "/api/v1/orders?user_id=8", userID: 7, roles: []string{"customer"}})
+	if got := userIDs(customer); !reflect.DeepEqual(got, []string{"7"}) {
+		t.Fatalf("a customer asking for user 8 got the orders of %v, want only their own", got)
+	}
+}
+
+func TestUpdateOrderItemQuantity(t *testing.T) {
+	tests := []struct {
+		name       string
+		order      *orderpb.Order
+		target     string
+		body       string
+		updateErr  error
+		wantStatus int
+		wantUpdate bool
+	}{
+		{name: "pending order of the caller", order: &orderpb.Order{Id: 5, UserId: 7, Status: "pending"}, wantStatus: http.StatusOK, wantUpdate: true},
+		{name: "order of another user", order: &orderpb.Order{Id: 5, UserId: 8, Status: "pending"}, wantStatus: http.StatusForbidden},
+		{name: "order already paid", order: &orderpb.Order{Id: 5, UserId: 7, Status: "paid"}, wantStatus: http.StatusConflict},
+		{
+			name:       "order paid after the check",
+			order:      &orderpb.Order{Id: 5, UserId: 7, Status: "pending"},
+			updateErr:  status.Error(codes.FailedPrecondition, "order is no longer pending"),
+			wantStatus: http.StatusConflict,
+			wantUpdate: true,
+		},
+		{name: "unknown order", wantStatus: http.StatusNotFound},
+		{name: "invalid item ID", target: "/api/v1/orders/5/items/abc", wantStatus: http.StatusBadRequest},
+		{name: "zero quantity", body: `{"quantity": 0}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updates []*orderpb.UpdateOrderItemQuantityRequest
+			orders := &fakeOrderClient{
+				getOrderByID: func(in *orderpb.GetOrderByIDRequest) (*orderpb.GetOrderByIDResponse, error) {
+					if tt.order == nil || in.GetId() != tt.order.Id {
+						return nil, status.Error(codes.NotFound, "order not found")
+					}
+					return &orderpb.GetOrderByIDResponse{Order: tt.order}, nil
+				},
+				updateItemQuantity: func(in *orderpb.UpdateOrderItemQuantityRequest) (*orderpb.UpdateOrderItemQuantityResponse, error) {
+					updates = append(updates, in)
+					if tt.updateErr != nil {
+						return nil, tt.updateErr
+					}
+					return &orderpb.UpdateOrderItemQuantityResponse{Order: &orderpb.Order{
+						Id: 5, UserId: 7, Status: "pending", Total: 30,
+						Items: []*orderpb.OrderItem{{Id: in.GetItemId(), Quantity: in.GetQuantity(), UnitPrice: 10, TotalPrice: 30}},
+					}}, nil
+				},
+			}
+			target, body := tt.target, tt.body
+			if target == "" {
+				target = "/api/v1/orders/5/items/9"
+			}
+			if body == "" {
+				body = `{"quantity": 3}`
+			}
+
+			w := serve(t, testRequest{method: http.MethodPatch, route: "/api/v1/orders/:id/items/:itemID", target: target, body: body, userID: 7, roles: []string{"customer"}},
+				NewOrderHandler(orders, nil, nil).UpdateOrderItemQuantity)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantUpdate != (len(updates) > 0) {
+				t.Fatalf("updates = %v, want an update: %v", updates, tt.wantUpdate)
+			}
+			if !tt.wantUpdate {
+				return
+			}
+			if want := (&orderpb.UpdateOrderItemQuantityRequest{OrderId: 5, ItemId: 9, Quantity: 3}); !proto.Equal(updates[0], want) {
+				t.Errorf("update = %v, want %v", updates[0], want)
+			}
+			if w.Code == http.StatusOK {
+				var resp struct {
+					Order struct {
+						Items []struct {
+							Quantity int `json:"quantity"`
+						} `json:"items"`
+					} `json:"order"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Order.Items) != 1 || resp.Order.Items[0].Quantity != 3 {
+					t.Errorf("body = %s, want the updated order", w.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateOrderChecksTheAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID uint
+		// addressID is the JSON of address_id, left out when empty
+		addressID     string
+		wantStatus    int
+		wantAddressID int64
+	}{
+		{name: "address of the caller", userID: 7, addressID: "1", wantStatus: http.StatusCreated, wantAddressID: 1},
+		{name: "default address", userID: 7, wantStatus: http.StatusCreated, wantAddressID: 2},
+		{name: "no address ID and no default", userID: 8, wantStatus: http.StatusBadRequest},
+		{name: "unknown address", userID: 7, addressID: "9", wantStatus: http.StatusBadRequest},
+		{name: "address of another user", userID: 7, addressID: "3", wantStatus: http.StatusForbidden},
+		{name: "negative address ID", userID: 7, addressID: "-1", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := addressBook(
+				&userpb.Address{Id: 1, UserId: 7},
+				&userpb.Address{Id: 2, UserId: 7, IsDefault: true},
+				&userpb.Address{Id: 3, UserId: 8},
+			)
+			var created []*orderpb.CreateOrderRequest
+			orders := &fakeOrderClient{createOrder: func(in *orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error) {
+				created = append(created, in)
+				return &orderpb.CreateOrderResponse{Order: &orderpb.Order{Id: 5, UserId: in.GetUserId()}}, nil
+			}}
+			body := `{"shipping_option_id": "standard", "items": [{"product_id": 4, "quantity": 1}]}`
+			if tt.addressID != "" {
+				body = `{"address_id": ` + tt.addressID + `, ` + body[1:]
+			}
+
+			w := serve(t, testRequest{method: http.MethodPost, route: "/api/v1/orders", target: "/api/v1/orders", body: body, userID: tt.userID, roles: []string{"customer"}},
+				wrap(NewOrderHandler(orders, nil, users).CreateOrder))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusCreated {
+				if len(created) > 0 {
+					t.Fatalf("an order was created with %v", created)
+				}
+				return
+			}
+			if len(created) != 1 || created[0].GetAddressId() != tt.wantAddressID || created[0].GetUserId() != int64(tt.userID) {
+				t.Fatalf("created %v, want one order of user %d to address %d", created, tt.userID, tt.wantAddressID)
+			}
+		})
+	}
+}
+
+func TestCreateOrderRepeatCreatesOneOrder(t *testing.T) {
+	var created int
+	orders := &fakeOrderClient{createOrder: func(in *orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error) {
+		created++
+		return &orderpb.CreateOrderResponse{Order: &orderpb.Order{Id: int64(created), UserId: in.GetUserId()}}, nil
+	}}
+	users := addressBook(&userpb.Address{Id: 1, UserId: 7})
+	send := newTestEngine(http.MethodPost, "/api/v1/orders/create",
+		middleware.Deduplication(middleware.NewMemoryDeduplicationStore(), time.Minute, middleware.DeduplicationKey("create_order")),
+		wrap(NewOrderHandler(orders, nil, users).CreateOrder))
+	req := testRequest{
+		method: http.MethodPost,
+		route:  "/api/v1/orders/create",
+		target: "/api/v1/orders/create",
+		body:   `{"address_id": 1, "shipping_option_id": "standard", "items": [{"product_id": 4, "quantity": 1}]}`,
+		userID: 7,
+		roles:  []string{"customer"},
+	}
+
+	first, second := send(t, req), send(t, req)
+	if first.Code != http.StatusCreated || second.Code != http.StatusCreated {
+		t.Fatalf("statuses %d and %d, want 201 twice: %s", first.Code, second.Code, second.Body)
+	}
+	if created != 1 {
+		t.Fatalf("CreateOrder was called %d times, want once", created)
+	}
+	if second.Body.String() != first.Body.String() || second.Header().Get("X-Deduplicated") != "true" {
+		t.Errorf("repeat answered %s, want the first order %s replayed", second.Body, first.Body)
+	}
+
+	// Another order from the same user goes through
+	req.body = strings.Replace(req.body, `"quantity": 1`, `"quantity": 2`, 1)
+	if w := send(t, req); w.Code != http.StatusCreated || created != 2 {
+		t.Fatalf("a different order: status = %d after %d calls, want it created", w.Code, created)
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// statusStreamServer streams events for every order, then either ends the stream with err or, when hold is
+// set, waits for the client to go away
+type statusStreamServer struct {
+	orderpb.UnimplementedOrderServiceServer
+	events []*orderpb.OrderStatusEvent
+	err    error
+	hold   bool
+	// ended is closed once WatchOrderStatus returns
+	ended chan struct{}
+}
+
+func (s *statusStreamServer) WatchOrderStatus(in *orderpb.WatchOrderStatusRequest, stream grpc.ServerStreamingServer[orderpb.OrderStatusEvent]) error {
+	defer close(s.ended)
+	for _, event := range s.events {
+		event.OrderId = in.GetOrderId()
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+	if s.hold {
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	}
+	return s.err
+}
+
+// startOrderServer serves srv over an in-memory connection and returns a client of it
+func startOrderServer(t *testing.T, srv *statusStreamServer) orderpb.OrderServiceClient {
+	t.Helper()
+	srv.ended = make(chan struct{})
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	orderpb.RegisterOrderServiceServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return orderpb.NewOrderServiceClient(conn)
+}
+
+// sseFrame is one server-sent event, with its data decoded
+type sseFrame struct {
+	event string
+	data  map[string]any
+}
+
+func readSSEFrame(t *testing.T, r *bufio.Reader) sseFrame {
+	t.Helper()
+	var frame sseFrame
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended mid-frame: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return frame
+		case strings.HasPrefix(line, "event: "):
+			frame.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &frame.data); err != nil {
+				t.Fatalf("data of %q is not JSON: %v", line, err)
+			}
+		}
+	}
+}
+
+func TestWatchOrderStatusStreamsEventsUntilClosed(t *testing.T) {
+	orders := startOrderServer(t, &statusStreamServer{events: []*orderpb.OrderStatusEvent{
+		{Status: "paid", UpdatedAt: "2026-01-02T03:04:05Z"},
+		{Status: "shipped", UpdatedAt: "2026-01-03T03:04:05Z"},
+		{Status: "delivered", UpdatedAt: "2026-01-04T03:04:05Z"},
+	}})
+	h := NewOrderHandler(orders, nil, nil)
+
+	w := serve(t, testRequest{method: http.MethodGet, route: "/api/v1/orders/:id/status/stream", target: "/api/v1/orders/5/status/stream"}, h.WatchOrderStatus)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+
+	body := bufio.NewReader(w.Body)
+	var got []sseFrame
+	for range 4 {
+		got = append(got, readSSEFrame(t, body))
+	}
+	want := []sseFrame{
+		{event: "status", data: map[string]any{"order_id": "5", "status": "paid", "updated_at": "2026-01-02T03:04:05Z"}},
+		{event: "status", data: map[string]any{"order_id": "5", "status": "shipped", "updated_at": "2026-01-03T03:04:05Z"}},
+		{event: "status", data: map[string]any{"order_id": "5", "status": "delivered", "updated_at": "2026-01-04T03:04:05Z"}},
+		{event: "closed", data: map[string]any{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("frames = %v, want %v", got, want)
+	}
+	if rest, _ := body.ReadString(0); rest != "" {
+		t.Errorf("unexpected data after the closed event: %q", rest)
+	}
+}
+
+func TestWatchOrderStatusErrors(t *testing.T) {
+	t.Run("unknown order", func(t *testing.T) {
+		orders := startOrderServer(t, &statusStreamServer{err: status.Error(codes.NotFound, "order not found")})
+		w := serve(t, testRequest{method: http.MethodGet, route: "/orders/:id", target: "/orders/5"}, NewOrderHandler(orders, nil, nil).WatchOrderStatus)
+
+		if w.Code != http.StatusNotFound || errorMessage(t, w) != "order not found" {
+			t.Fatalf("got %d %s, want a 404 JSON error before the stream starts", w.Code, w.Body)
+		}
+	})
+
+	t.Run("stream fails midway", func(t *testing.T) {
+		orders := startOrderServer(t, &statusStreamServer{
+			events: []*orderpb.OrderStatusEvent{{Status: "paid"}},
+			err:    status.Error(codes.Unavailable, "database down"),
+		})
+		w := serve(t, testRequest{method: http.MethodGet, route: "/orders/:id", target: "/orders/5"}, NewOrderHandler(orders, nil, nil).WatchOrderStatus)
+
+		body := bufio.NewReader(w.Body)
+		readSSEFrame(t, body)
+		frame := readSSEFrame(t, body)
+		if frame.event != "error" || frame.data["message"] != "database down" || frame.data["code"] != float64(http.StatusBadGateway) {
+			t.Fatalf("last frame = %v, want a 502 error event", frame)
+		}
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		w := serve(t, testRequest{method: http.MethodGet, route: "/orders/:id", target: "/orders/abc"}, NewOrderHandler(&fakeOrderClient{}, nil, nil).WatchOrderStatus)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400", w.Code)
+		}
+	})
+}
+
+func TestWatchOrderStatusStopsWhenTheClientDisconnects(t *testing.T) {
+	srv := &statusStreamServer{events: []*orderpb.OrderStatusEvent{{Status: "paid"}}, hold: true}
+	h := NewOrderHandler(startOrderServer(t, srv), nil, nil)
+	engine := gin.New()
+	engine.GET("/orders/:id", h.WatchOrderStatus)
+	gateway := httptest.NewServer(engine)
+	defer gateway.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gateway.URL+"/orders/5", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if frame := readSSEFrame(t, bufio.NewReader(resp.Body)); frame.event != "status" {
+		t.Fatalf("first frame = %v, want a status event", frame)
+	}
+
+	cancel()
+	select {
+	case <-srv.ended:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the gRPC stream was still open 5s after the client went away")
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultPerPage = 10
+	maxPerPage     = 100
+)
+
+// PaginatedResponse is the envelope returned by every list endpoint
+type PaginatedResponse struct {
+	Data       interface{}    `json:"data"`
+	Pagination PaginationMeta `json:"pagination"`
+}
+
+// PaginationMeta describes where a page sits in the full result set.
+// Cursor-paginated responses have no page number and carry next_cursor instead.
+type PaginationMeta struct {
+	Page       int     `json:"page,omitempty"`
+	PerPage    int     `json:"per_page"`
+	Total      int     `json:"total"`
+	TotalPages int     `json:"total_pages"`
+	Next       *string `json:"next"`
+	Prev       *string `json:"prev"`
+	HasNext    bool    `json:"has_next"`
+	HasPrev    bool    `json:"has_prev"`
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+// parsePagination reads page and per_page; a missing or non-positive per_page uses the default and larger values are capped
+func parsePagination(r *http.Request) (page, perPage int) {
+	page, _ = strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	perPage, _ = strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	return page, min(perPage, maxPerPage)
+}
+
+// newPaginationMeta places page in a result set of total items, building next/prev links from the request URL
+func newPaginationMeta(r *http.Request, page, perPage, total int) PaginationMeta {
+	meta := PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: pageCount(total, perPage),
+	}
+
+	if page < meta.TotalPages {
+		next := pageURL(r.URL, page+1, perPage)
+		meta.Next = &next
+		meta.HasNext = true
+	}
+	if page > 1 && meta.TotalPages > 0 {
+		prev := pageURL(r.URL, min(page-1, meta.TotalPages), perPage)
+		meta.Prev = &prev
+		meta.HasPrev = true
+	}
+	return meta
+}
+
+// newCursorPaginationMeta describes a cursor-paginated page. Cursors only move forward, so there is a next
+// link while nextCursor is set and never a prev link.
+func newCursorPaginationMeta(r *http.Request, perPage, total int, nextCursor string) PaginationMeta {
+	meta := PaginationMeta{
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: pageCount(total, perPage),
+	}
+
+	if nextCursor != "" {
+		next := cursorURL(r.URL, nextCursor, perPage)
+		meta.Next = &next
+		meta.NextCursor = &nextCursor
+		meta.HasNext = true
+	}
+	return meta
+}
+
+// pageCount is the number of pages of perPage items it takes to hold total items
+func pageCount(total, perPage int) int {
+	if perPage <= 0 || total <= 0 {
+		return 0
+	}
+	return (total + perPage - 1) / perPage
+}
+
+// pageURL returns u with its page and per_page query params replaced, keeping every other filter
+func pageURL(u *url.URL, page, perPage int) string {
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	link := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return link.String()
+}
+
+// cursorURL returns u with cursor and per_page replaced and page dropped, keeping every other filter
+func cursorURL(u *url.URL, cursor string, perPage int) string {
+	query := u.Query()
+	query.Del("page")
+	query.Set("cursor", cursor)
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	link := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return link.String()
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+)
+
+func TestNewPaginationMeta(t *testing.T) {
+	link := func(s string) *string { return &s }
+	tests := []struct {
+		name        string
+		page, total int
+		want        PaginationMeta
+	}{
+		{
+			name: "first of several pages", page: 1, total: 234,
+			want: PaginationMeta{Page: 1, PerPage: 10, Total: 234, TotalPages: 24, HasNext: true,
+				Next: link("/api/v1/orders?page=2&per_page=10&status=paid")},
+		},
+		{
+			name: "middle page", page: 12, total: 234,
+			want: PaginationMeta{Page: 12, PerPage: 10, Total: 234, TotalPages: 24, HasNext: true, HasPrev: true,
+				Next: link("/api/v1/orders?page=13&per_page=10&status=paid"),
+				Prev: link("/api/v1/orders?page=11&per_page=10&status=paid")},
+		},
+		{
+			name: "last, partly filled page", page: 24, total: 234,
+			want: PaginationMeta{Page: 24, PerPage: 10, Total: 234, TotalPages: 24, HasPrev: true,
+				Prev: link("/api/v1/orders?page=23&per_page=10&status=paid")},
+		},
+		{
+			name: "last page filled exactly", page: 3, total: 30,
+			want: PaginationMeta{Page: 3, PerPage: 10, Total: 30, TotalPages: 3, HasPrev: true,
+				Prev: link("/api/v1/orders?page=2&per_page=10&status=paid")},
+		},
+		{
+			name: "a single page", page: 1, total: 10,
+			want: PaginationMeta{Page: 1, PerPage: 10, Total: 10, TotalPages: 1},
+		},
+		{
+			// prev points at the last page rather than the empty one before this
+			name: "past the end", page: 30, total: 234,
+			want: PaginationMeta{Page: 30, PerPage: 10, Total: 234, TotalPages: 24, HasPrev: true,
+				Prev: link("/api/v1/orders?page=24&per_page=10&status=paid")},
+		},
+		{
+			name: "no results", page: 2, total: 0,
+			want: PaginationMeta{Page: 2, PerPage: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/orders?status=paid&page=7", nil)
+			got := newPaginationMeta(r, tt.page, 10, tt.total)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("meta = %s, want %s", metaJSON(t, got), metaJSON(t, tt.want))
+			}
+		})
+	}
+}
+
+func TestNewCursorPaginationMeta(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/products?cursor=abc&page=3&per_page=20", nil)
+
+	meta := newCursorPaginationMeta(r, 20, 45, "def")
+	if meta.Page != 0 || meta.TotalPages != 3 || !meta.HasNext || meta.HasPrev || meta.Prev != nil {
+		t.Errorf("meta = %s, want 3 pages with only a next link", metaJSON(t, meta))
+	}
+	if meta.Next == nil || *meta.Next != "/api/v1/products?cursor=def&per_page=20" {
+		t.Errorf("next = %v, want the next cursor without page", meta.Next)
+	}
+
+	if last := newCursorPaginationMeta(r, 20, 45, ""); last.HasNext || last.Next != nil || last.NextCursor != nil {
+		t.Errorf("last page meta = %s, want no next link", metaJSON(t, last))
+	}
+}
+
+func TestWriteJSONPaginated(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/categories", nil)
+	w := httptest.NewRecorder()
+	writeJSONPaginated(w, http.StatusOK, []string{"a", "b"}, newPaginationMeta(r, 1, 2, 3))
+
+	var body struct {
+		Data       []string       `json:"data"`
+		Pagination map[string]any `json:"pagination"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" || len(body.Data) != 2 {
+		t.Fatalf("got %d %q with %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+	for field, want := range map[string]any{
+		"page": 1.0, "per_page": 2.0, "total": 3.0, "total_pages": 2.0, "has_next": true, "has_prev": false,
+	} {
+		if body.Pagination[field] != want {
+			t.Errorf("pagination.%s = %v, want %v", field, body.Pagination[field], want)
+		}
+	}
+}
+
+func metaJSON(t *testing.T, meta PaginationMeta) string {
+	t.Helper()
+	body, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestListEndpointsLinkPages(t *testing.T) {
+	const total = 25 // three pages of 10
+	var requested int32
+	products := &fakeProductClient{
+		listProducts: func(in *productpb.ListProductsRequest) (*productpb.ListProductsResponse, error) {
+			requested = in.GetPage()
+			return &productpb.ListProductsResponse{Products: []*productpb.Product{{Id: 1}}, TotalCount: total}, nil
+		},
+		listCategories: func(in *productpb.ListCategoriesRequest) (*productpb.ListCategoriesResponse, error) {
+			requested = in.GetPage()
+			return &productpb.ListCategoriesResponse{Categories: []*productpb.Category{{Id: 1}}, TotalCount: total}, nil
+		},
+	}
+	users := &fakeUserClient{
+		searchUsers: func(in *userpb.SearchUsersRequest) (*userpb.SearchUsersResponse, error) {
+			requested = in.GetPageNumber()
+			return &userpb.SearchUsersResponse{Users: []*userpb.User{{Id: 1}}, Total: total}, nil
+		},
+	}
+	orders := &fakeOrderClient{
+		listOrders: func(in *orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+			requested = in.GetPage()
+			return &orderpb.ListOrdersResponse{Orders: []*orderpb.Order{{Id: 1}}, TotalCount: total}, nil
+		},
+	}
+	productHandler := NewProductHandler(products, nil, "", "", 0, nil)
+	orderHandler := NewOrderHandler(orders, nil, users)
+	userHandler := NewUserHandler(users, orders, nil, nil, nil, nil, nil, nil)
+
+	endpoints := []struct {
+		name    string
+		path    string
+		filters url.Values
+		handler gin.HandlerFunc
+	}{
+		{name: "ListProducts", path: "/api/v1/products", filters: url.Values{"fields": {"id,name"}}, handler: wrap(productHandler.ListProducts)},
+		{name: "ListCategories", path: "/api/v1/categories", filters: url.Values{}, handler: wrap(productHandler.ListCategories)},
+		{name: "ListOrders", path: "/api/v1/orders", filters: url.Values{"start_date": {"2026-01-01"}}, handler: wrap(orderHandler.ListOrders)},
+		{name: "SearchUsers", path: "/api/v1/users", filters: url.Values{"role": {"admin"}}, handler: userHandler.SearchUsers},
+	}
+	pages := []struct {
+		name             string
+		page, next, prev int
+	}{
+		{name: "first", page: 1, next: 2},
+		{name: "middle", page: 2, next: 3, prev: 1},
+		{name: "last", page: 3, prev: 2},
+	}
+	for _, endpoint := range endpoints {
+		// link is the endpoint's URL for page with its filters kept, or nil for no page
+		link := func(page int) *string {
+			if page == 0 {
+				return nil
+			}
+			query := url.Values{"page": {strconv.Itoa(page)}, "per_page": {"10"}}
+			for name, values := range endpoint.filters {
+				query[name] = values
+			}
+			s := endpoint.path + "?" + query.Encode()
+			return &s
+		}
+
+		for _, tt := range pages {
+			t.Run(endpoint.name+" "+tt.name, func(t *testing.T) {
+				query := url.Values{"page": {strconv.Itoa(tt.page)}}
+				for name, values := range endpoint.filters {
+					query[name] = values
+				}
+				w := serve(t, testRequest{method: http.MethodGet, route: endpoint.path, target: endpoint.path + "?" + query.Encode(), userID: 7}, endpoint.handler)
+
+				var body struct {
+					Data       []json.RawMessage `json:"data"`
+					Pagination PaginationMeta    `json:"pagination"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != http.StatusOK {
+					t.Fatalf("got %d %s", w.Code, w.Body)
+				}
+				if requested != int32(tt.page) || len(body.Data) != 1 {
+					t.Errorf("asked the service for page %d and got %d items, want page %d with 1 item", requested, len(body.Data), tt.page)
+				}
+				want := PaginationMeta{
+					Page: tt.page, PerPage: 10, Total: total, TotalPages: 3,
+					Next: link(tt.next), Prev: link(tt.prev), HasNext: tt.next != 0, HasPrev: tt.prev != 0,
+				}
+				if !reflect.DeepEqual(body.Pagination, want) {
+					t.Errorf("pagination = %s, want %s", metaJSON(t, body.Pagination), metaJSON(t, want))
+				}
+			})
+		}
+	}
+}
@@ -1,23 +1,129 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
-
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
 	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
+const imageUploadURLExpiry = 15 * time.Minute
+
+// S3PresignClient is the part of *s3.PresignClient used to sign image uploads
+type S3PresignClient interface {
+	PresignPutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+}
+
 // ProductHandler handles product-related HTTP requests
 type ProductHandler struct {
 	productClient productpb.ProductServiceClient
+	presigner     S3PresignClient
+	bucket        string
+	region        string
+	// maxBatchIDs caps the distinct IDs of one batch lookup
+	maxBatchIDs int
+	// clock dates product and category responses for Last-Modified
+	clock *ModificationClock
+}
+
+// ProductBatchRequest lists the products to fetch; repeated IDs are fetched once
+type ProductBatchRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// ProductBatchResponse has one entry per distinct requested ID, in request order
+type ProductBatchResponse struct {
+	Products   []ProductBatchItem `json:"products"`
+	MissingIDs []int64            `json:"missing_ids"`
+}
+
+// ProductBatchItem has found=false and a null product when no product has the ID
+type ProductBatchItem struct {
+	ID      int64             `json:"id"`
+	Found   bool              `json:"found"`
+	Product *localizedProduct `json:"product"`
+}
+
+// localizedProduct is a product's JSON with its price, which stays in the base currency, also converted into
+// the request's currency under pricing. Products whose field mask leaves out price have no pricing.
+type localizedProduct struct {
+	protoJSONFields
+	pricing *middleware.LocalPrice
+}
+
+func (p localizedProduct) MarshalJSON() ([]byte, error) {
+	body, err := p.protoJSONFields.MarshalJSON()
+	if err != nil || p.pricing == nil || string(body) == "null" {
+		return body, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	if fields["pricing"], err = json.Marshal(p.pricing); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// localizeProduct limits product to fields, all when empty, and adds its price in the currency
+// middleware.NegotiatePrices picked for the request
+func localizeProduct(r *http.Request, product *productpb.Product, fields []string) localizedProduct {
+	localized := localizedProduct{protoJSONFields: protoJSONFields{product, fields}}
+	locale, ok := middleware.GetPriceLocale(r.Context())
+	if ok && product != nil && (len(fields) == 0 || slices.Contains(fields, "price")) {
+		price := locale.Convert(float64(product.GetPrice()))
+		localized.pricing = &price
+	}
+	return localized
+}
+
+// localizeProducts is localizeProduct for each product of a list
+func localizeProducts(r *http.Request, products []*productpb.Product, fields []string) []localizedProduct {
+	localized := make([]localizedProduct, len(products))
+	for i, product := range products {
+		localized[i] = localizeProduct(r, product, fields)
+	}
+	return localized
+}
+
+type AdjustStockResponse struct {
+	ProductID int64 `json:"product_id"`
+	NewStock  int32 `json:"new_stock"`
+}
+
+// ImageUploadURLResponse tells the client where to PUT the image and where it will be served from
+type ImageUploadURLResponse struct {
+	UploadURL string `json:"upload_url"`
+	PublicURL string `json:"public_url"`
 }
 
-// NewProductHandler creates a new product handler
-func NewProductHandler(productClient productpb.ProductServiceClient) *ProductHandler {
+// NewProductHandler creates a new product handler. A nil presigner disables image uploads.
+func NewProductHandler(productClient productpb.ProductServiceClient, presigner S3PresignClient, bucket, region string, maxBatchIDs int, clock *ModificationClock) *ProductHandler {
 	return &ProductHandler{
 		productClient: productClient,
+		presigner:     presigner,
+		bucket:        bucket,
+		region:        region,
+		maxBatchIDs:   maxBatchIDs,
+		clock:         clock,
 	}
 }
 
@@ -28,36 +134,58 @@ func NewProductHandler(productClient productpb.ProductServiceClient) *ProductHan
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param request body CreateProductRequest true "Product details"
-// @Success 201 {object} CreateProductResponse
+// @Success 201 {object} productpb.CreateProductResponse
 // @Router /api/v1/products [post]
+// @DeprecatedRouter /api/v1/products/create [post]
 func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
-	var req productpb.CreateProductRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+	var req CreateProductRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
-	resp, err := h.productClient.CreateProduct(r.Context(), &req)
+	resp, err := h.productClient.CreateProduct(r.Context(), &productpb.CreateProductRequest{
+		Name:             req.Name,
+		ShortDescription: req.ShortDescription,
+		Description:      req.Description,
+		Price:            req.Price,
+		DiscountType:     discountTypeToProto(req.DiscountType),
+		DiscountValue:    req.DiscountValue,
+		ImageUrl:         req.ImageURL,
+		Quantity:         req.Quantity,
+	})
 	if err != nil {
 		logger.Errorf("failed to create product: %v", err)
 		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, resp)
+	writeProtoJSON(w, http.StatusCreated, resp)
 }
 
 // GetProductByID godoc
 // @Summary Get product by ID
-// @Description Get product details by ID
+// @Description Get product details by ID. price stays in the base currency; pricing has it in the currency of X-Currency,
+// @Description formatted for Accept-Language, unless fields leaves out price.
 // @Tags products
 // @Produce json
 // @Param id path int true "Product ID"
-// @Success 200 {object} GetProductByIDResponse
+// @Param fields query string false "Comma-separated product fields to return, e.g. id,name,price"
+// @Param X-Currency header string false "ISO 4217 currency of pricing, e.g. EUR. Currencies without a rate fall back to the base currency"
+// @Param Accept-Language header string false "Language pricing.formatted is written for, e.g. de-DE; its region also picks the currency when X-Currency is absent"
+// @Param If-None-Match header string false "ETag of a copy already held"
+// @Param If-Modified-Since header string false "Last-Modified of a copy already held; ignored when If-None-Match is sent"
+// @Success 200 {object} productpb.GetProductByIDResponse
+// @Header 200 {string} X-Currency "Currency of pricing"
+// @Header 200 {string} Last-Modified "When the gateway first served this body"
+// @Success 304 "The copy held is current"
+// @Failure 400 {object} ErrorResponse "Unknown field name"
 // @Router /api/v1/products/{id} [get]
+// @DeprecatedRouter /api/v1/products/by-id [get]
 func (h *ProductHandler) GetProductByID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Query().Get("id")
+	idStr := resourceID(r)
 	if idStr == "" {
 		writeJSONError(w, http.StatusBadRequest, "missing product ID")
 		return
@@ -69,8 +197,15 @@ func (h *ProductHandler) GetProductByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	mask, fields, err := parseProductFields(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	resp, err := h.productClient.GetProductByID(r.Context(), &productpb.GetProductByIDRequest{
-		Id: id,
+		Id:     id,
+		Fields: mask,
 	})
 
 	if err != nil {
@@ -79,32 +214,147 @@ func (h *ProductHandler) GetProductByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	body, err := json.Marshal(map[string]localizedProduct{"product": localizeProduct(r, resp.GetProduct(), fields)})
+	if err != nil {
+		logger.Errorf("failed to marshal product: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+	// The body varies by its field mask, currency and language
+	key := fmt.Sprintf("product:%d?%s|%s|%s", id, r.URL.RawQuery, r.Header.Get("Accept-Language"), r.Header.Get("X-Currency"))
+	writeJSONWithETag(w, r, body, "no-cache", h.clock, key)
+}
+
+// GetProductsBatch godoc
+// @Summary Get products by IDs
+// @Description Get up to PRODUCT_BATCH_MAX_IDS products, 100 by default, in one call. Duplicate IDs are ignored. Results
+// @Description follow the order IDs were first requested; an ID with no product has found=false and a null product, and
+// @Description is listed in missing_ids. Each product has pricing as in GET /api/v1/products/{id}.
+// @Tags products
+// @Produce json
+// @Param ids query string true "Comma-separated product IDs, e.g. 1,2,3"
+// @Param fields query string false "Comma-separated product fields to return, e.g. id,name,price"
+// @Param X-Currency header string false "ISO 4217 currency of pricing, e.g. EUR. Currencies without a rate fall back to the base currency"
+// @Param Accept-Language header string false "Language pricing.formatted is written for, e.g. de-DE; its region also picks the currency when X-Currency is absent"
+// @Success 200 {object} ProductBatchResponse
+// @Header 200 {string} X-Currency "Currency of pricing"
+// @Failure 400 {object} ErrorResponse "Missing, invalid or too many IDs, or unknown field name"
+// @Router /api/v1/products/batch [get]
+func (h *ProductHandler) GetProductsBatch(w http.ResponseWriter, r *http.Request) {
+	ids, err := parseProductIDs(r.URL.Query().Get("ids"), h.maxBatchIDs)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.writeProductsBatch(w, r, ids)
+}
+
+// PostProductsBatch godoc
+// @Summary Get products by IDs
+// @Description GET /api/v1/products/batch with the IDs in the body, for lists too long for a URL such as a cart's
+// @Description products. The same limit, deduplication, ordering and missing_ids apply.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param request body ProductBatchRequest true "Product IDs"
+// @Param fields query string false "Comma-separated product fields to return, e.g. id,name,price"
+// @Param X-Currency header string false "ISO 4217 currency of pricing, e.g. EUR. Currencies without a rate fall back to the base currency"
+// @Param Accept-Language header string false "Language pricing.formatted is written for, e.g. de-DE; its region also picks the currency when X-Currency is absent"
+// @Success 200 {object} ProductBatchResponse
+// @Header 200 {string} X-Currency "Currency of pricing"
+// @Failure 400 {object} ErrorResponse "Missing, invalid or too many IDs, or unknown field name"
+// @Router /api/v1/products/batch [post]
+func (h *ProductHandler) PostProductsBatch(w http.ResponseWriter, r *http.Request) {
+	var req ProductBatchRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	ids, err := distinctProductIDs(req.IDs, h.maxBatchIDs)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.writeProductsBatch(w, r, ids)
+}
+
+// writeProductsBatch fetches ids with one GetProductsByIDs call and answers with an entry per ID
+func (h *ProductHandler) writeProductsBatch(w http.ResponseWriter, r *http.Request, ids []int64) {
+	mask, fields, err := parseProductFields(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.productClient.GetProductsByIDs(r.Context(), &productpb.GetProductsByIDsRequest{
+		Ids:    ids,
+		Fields: mask,
+	})
+	if err != nil {
+		logger.Errorf("failed to get products batch: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Products come back in request order, which lets them be matched up even when the mask leaves out id
+	missing := make(map[int64]bool, len(resp.GetMissingIds()))
+	for _, id := range resp.GetMissingIds() {
+		missing[id] = true
+	}
+	products := resp.GetProducts()
+	response := ProductBatchResponse{
+		Products:   make([]ProductBatchItem, len(ids)),
+		MissingIDs: make([]int64, 0, len(missing)),
+	}
+	for i, id := range ids {
+		response.Products[i] = ProductBatchItem{ID: id}
+		if missing[id] || len(products) == 0 {
+			response.MissingIDs = append(response.MissingIDs, id)
+			continue
+		}
+		response.Products[i].Found = true
+		product := localizeProduct(r, products[0], fields)
+		response.Products[i].Product = &product
+		products = products[1:]
+	}
+
+	writeJSON(w, http.StatusOK, response)
 }
 
 // ListProducts godoc
 // @Summary List products
-// @Description List all products with pagination
+// @Description List all products by page, or by cursor for large catalogs. Pass pagination.next_cursor
+// @Description back as cursor to fetch the following page; cursor takes precedence over page. Each product has
+// @Description pricing as in GET /api/v1/products/{id}.
 // @Tags products
 // @Produce json
 // @Param page query int false "Page number" default(1)
-// @Param per_page query int false "Items per page" default(10)
-// @Success 200 {object} ListProductsResponse
+// @Param per_page query int false "Items per page, capped at 100" default(10)
+// @Param cursor query string false "Opaque cursor from a previous response"
+// @Param fields query string false "Comma-separated product fields to return, e.g. id,name,price,image_url"
+// @Param X-Currency header string false "ISO 4217 currency of pricing, e.g. EUR. Currencies without a rate fall back to the base currency"
+// @Param Accept-Language header string false "Language pricing.formatted is written for, e.g. de-DE; its region also picks the currency when X-Currency is absent"
+// @Success 200 {object} PaginatedResponse
+// @Header 200 {string} X-Currency "Currency of pricing"
+// @Failure 400 {object} ErrorResponse "Invalid or tampered cursor, or unknown field name"
 // @Router /api/v1/products [get]
 func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
-	if perPage < 1 || perPage > 100 {
-		perPage = 10
+	page, perPage := parsePagination(r)
+	cursor := r.URL.Query().Get("cursor")
+	mask, fields, err := parseProductFields(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	resp, err := h.productClient.ListProducts(r.Context(), &productpb.ListProductsRequest{
 		Page:    int32(page),
 		PerPage: int32(perPage),
+		Cursor:  cursor,
+		Fields:  mask,
 	})
 
 	if err != nil {
@@ -113,7 +363,17 @@ func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	products := localizeProducts(r, resp.GetProducts(), fields)
+	if cursor != "" {
+		writeJSONPaginated(w, http.StatusOK, products, newCursorPaginationMeta(r, perPage, int(resp.GetTotalCount()), resp.GetNextCursor()))
+		return
+	}
+
+	meta := newPaginationMeta(r, page, perPage, int(resp.GetTotalCount()))
+	if nextCursor := resp.GetNextCursor(); nextCursor != "" {
+		meta.NextCursor = &nextCursor
+	}
+	writeJSONPaginated(w, http.StatusOK, products, meta)
 }
 
 // UpdateProduct godoc
@@ -123,24 +383,66 @@ func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "Product ID"
 // @Param request body UpdateProductRequest true "Product update details"
-// @Success 200 {object} UpdateProductResponse
+// @Success 200 {object} productpb.UpdateProductResponse
 // @Router /api/v1/products/{id} [put]
+// @DeprecatedRouter /api/v1/products/update [put]
 func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
-	var req productpb.UpdateProductRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+	var req UpdateProductRequest
+	if err := decodeRequestWithPathID(r, &req, &req.ID); err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
-	resp, err := h.productClient.UpdateProduct(r.Context(), &req)
+	resp, err := h.productClient.UpdateProduct(r.Context(), &productpb.UpdateProductRequest{
+		Id:                req.ID,
+		Name:              req.Name,
+		ShortDescription:  req.ShortDescription,
+		Description:       req.Description,
+		Price:             req.Price,
+		DiscountType:      discountTypeToProto(req.DiscountType),
+		DiscountValue:     req.DiscountValue,
+		ImageUrl:          req.ImageURL,
+		Quantity:          req.Quantity,
+		LowStockThreshold: req.LowStockThreshold,
+	})
 	if err != nil {
 		logger.Errorf("failed to update product: %v", err)
 		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeProtoJSON(w, http.StatusOK, resp)
+}
+
+// ListLowStockProducts godoc
+// @Summary List low stock products
+// @Description List the products whose stock is at or below their low stock threshold (admin only), the lowest
+// @Description stock first and then the furthest below the threshold. Products without a threshold are not listed.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page, capped at 100" default(10)
+// @Success 200 {object} PaginatedResponse
+// @Router /api/v1/admin/products/low-stock [get]
+func (h *ProductHandler) ListLowStockProducts(w http.ResponseWriter, r *http.Request) {
+	page, perPage := parsePagination(r)
+
+	resp, err := h.productClient.ListLowStockProducts(r.Context(), &productpb.ListLowStockProductsRequest{
+		Page:    int32(page),
+		PerPage: int32(perPage),
+	})
+	if err != nil {
+		logger.Errorf("failed to list low stock products: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONPaginated(w, http.StatusOK, protoJSONList(resp.GetProducts()), newPaginationMeta(r, page, perPage, int(resp.GetTotalCount())))
 }
 
 // DeleteProduct godoc
@@ -148,11 +450,13 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 // @Description Delete a product (admin only)
 // @Tags products
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path int true "Product ID"
-// @Success 200 {object} DeleteProductResponse
+// @Success 200 {object} productpb.DeleteProductResponse
 // @Router /api/v1/products/{id} [delete]
+// @DeprecatedRouter /api/v1/products/delete [delete]
 func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Query().Get("id")
+	idStr := resourceID(r)
 	if idStr == "" {
 		writeJSONError(w, http.StatusBadRequest, "missing product ID")
 		return
@@ -174,7 +478,310 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeProtoJSON(w, http.StatusOK, resp)
+}
+
+// GetImageUploadURL godoc
+// @Summary Get product image upload URL
+// @Description Pre-signed S3 URL to PUT a JPEG product image, valid for 15 minutes (admin only).
+// @Description Attach the uploaded image with PATCH /api/v1/products/{id}/image.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "Product ID"
+// @Success 200 {object} ImageUploadURLResponse
+// @Failure 503 {object} ErrorResponse "Image uploads are not configured"
+// @Router /api/v1/products/{id}/image-url [post]
+func (h *ProductHandler) GetImageUploadURL(c *gin.Context) {
+	if h.presigner == nil {
+		writeJSONError(c.Writer, http.StatusServiceUnavailable, "image uploads are not configured")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	key := fmt.Sprintf("%s%s.jpg", productImagePrefix(id), uuid.NewString())
+	req, err := h.presigner.PresignPutObject(c.Request.Context(), &s3.PutObjectInput{
+		Bucket: aws.String(h.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(imageUploadURLExpiry))
+	if err != nil {
+		logger.Errorf("failed to presign product image upload: %v", err)
+		writeJSONError(c.Writer, http.StatusInternalServerError, "failed to create upload URL")
+		return
+	}
+
+	writeJSON(c.Writer, http.StatusOK, ImageUploadURLResponse{
+		UploadURL: req.URL,
+		PublicURL: h.publicImageURL(key),
+	})
+}
+
+// UpdateProductImage godoc
+// @Summary Attach product image
+// @Description Point the product at an image uploaded through GetImageUploadURL (admin only)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "Product ID"
+// @Param request body UpdateProductImageRequest true "Key of the uploaded image"
+// @Success 200 {object} productpb.UpdateProductResponse
+// @Router /api/v1/products/{id}/image [patch]
+func (h *ProductHandler) UpdateProductImage(c *gin.Context) {
+	if h.presigner == nil {
+		writeJSONError(c.Writer, http.StatusServiceUnavailable, "image uploads are not configured")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req UpdateProductImageRequest
+	if err := decodeRequest(c.Request, &req); err != nil {
+		writeRequestError(c.Writer, err)
+		return
+	}
+	// Only keys handed out for this product are accepted, so one product can't point at another's images
+	if !strings.HasPrefix(req.ImageKey, productImagePrefix(id)) || strings.Contains(req.ImageKey, "..") {
+		writeJSONError(c.Writer, http.StatusBadRequest, "image_key does not belong to this product")
+		return
+	}
+
+	resp, err := h.productClient.UpdateProduct(c.Request.Context(), &productpb.UpdateProductRequest{
+		Id:       int32(id),
+		ImageUrl: h.publicImageURL(req.ImageKey),
+	})
+	if err != nil {
+		logger.Errorf("failed to update product image: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
+}
+
+// AdjustStock godoc
+// @Summary Adjust product stock
+// @Description Add a signed delta to a product's stock (admin only): positive to restock, negative to deduct.
+// @Description A deduction larger than the current stock is rejected and leaves the stock unchanged. The change is
+// @Description recorded in the stock movements as a correction; use stock-adjustments to give another reason.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "Product ID"
+// @Param request body AdjustStockRequest true "Stock change"
+// @Success 200 {object} AdjustStockResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Stock would go negative"
+// @Router /api/v1/products/{id}/stock [patch]
+func (h *ProductHandler) AdjustStock(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req AdjustStockRequest
+	if err := decodeRequest(c.Request, &req); err != nil {
+		writeRequestError(c.Writer, err)
+		return
+	}
+
+	resp, err := h.productClient.AdjustProductStock(c.Request.Context(), &productpb.AdjustProductStockRequest{
+		ProductId: id,
+		Delta:     req.Delta,
+	})
+	if err != nil {
+		logger.Errorf("failed to adjust product stock: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(c.Writer, http.StatusOK, AdjustStockResponse{
+		ProductID: resp.GetProductId(),
+		NewStock:  resp.GetStockLevel(),
+	})
+}
+
+// CreateStockAdjustment godoc
+// @Summary Adjust product stock with a reason
+// @Description Add a signed delta to a product's stock (admin only) and record it in the product's stock movements
+// @Description with its reason and the admin who made it. A deduction larger than the current stock is rejected
+// @Description and leaves the stock unchanged.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "Product ID"
+// @Param request body StockAdjustmentRequest true "Stock change"
+// @Success 201 {object} productpb.StockMovement
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Stock would go negative"
+// @Router /api/v1/admin/products/{id}/stock-adjustments [post]
+func (h *ProductHandler) CreateStockAdjustment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req StockAdjustmentRequest
+	if err := decodeRequest(c.Request, &req); err != nil {
+		writeRequestError(c.Writer, err)
+		return
+	}
+
+	resp, err := h.productClient.AdjustProductStock(c.Request.Context(), &productpb.AdjustProductStockRequest{
+		ProductId: id,
+		Delta:     req.Delta,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		logger.Errorf("failed to adjust product stock: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(c.Writer, http.StatusCreated, resp.GetMovement())
+}
+
+// ListStockMovements godoc
+// @Summary List product stock movements
+// @Description List every change to a product's stock (admin only), newest first: admin adjustments with their
+// @Description reason and user, and order movements with their order_id. stock_after is the stock the change left.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page, capped at 100" default(10)
+// @Success 200 {object} PaginatedResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/admin/products/{id}/stock-movements [get]
+func (h *ProductHandler) ListStockMovements(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	page, perPage := parsePagination(c.Request)
+	resp, err := h.productClient.ListStockMovements(c.Request.Context(), &productpb.ListStockMovementsRequest{
+		ProductId: id,
+		Page:      int32(page),
+		PerPage:   int32(perPage),
+	})
+	if err != nil {
+		logger.Errorf("failed to list stock movements: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONPaginated(c.Writer, http.StatusOK, protoJSONList(resp.GetMovements()), newPaginationMeta(c.Request, page, perPage, int(resp.GetTotalCount())))
+}
+
+// parseProductFields turns ?fields=id,name,price into a FieldMask over Product.
+// It returns a nil mask and no fields when the parameter is absent.
+func parseProductFields(r *http.Request) (*fieldmaskpb.FieldMask, []string, error) {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil, nil
+	}
+
+	descriptor := (&productpb.Product{}).ProtoReflect().Descriptor().Fields()
+	var fields, unknown []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if descriptor.ByName(protoreflect.Name(field)) == nil {
+			unknown = append(unknown, field)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(unknown) > 0 {
+		valid := make([]string, descriptor.Len())
+		for i := range valid {
+			valid[i] = string(descriptor.Get(i).Name())
+		}
+		return nil, nil, fmt.Errorf("unknown fields %q; valid fields are %s", unknown, strings.Join(valid, ", "))
+	}
+
+	if len(fields) == 0 {
+		return nil, nil, nil
+	}
+	return &fieldmaskpb.FieldMask{Paths: fields}, fields, nil
+}
+
+// parseProductIDs splits ?ids=1,2,3 into distinct positive IDs, keeping the order each was first seen
+func parseProductIDs(raw string, limit int) ([]int64, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, errors.New("missing product IDs")
+	}
+
+	var ids []int64
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid product ID %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return distinctProductIDs(ids, limit)
+}
+
+// distinctProductIDs drops repeated IDs, keeping the order each was first seen, and refuses more than limit
+// distinct ones
+func distinctProductIDs(ids []int64, limit int) ([]int64, error) {
+	seen := make(map[int64]bool)
+	var distinct []int64
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, fmt.Errorf("invalid product ID %d", id)
+		}
+		if seen[id] {
+			continue
+		}
+		if len(distinct) == limit {
+			return nil, fmt.Errorf("at most %d distinct product IDs are allowed", limit)
+		}
+		seen[id] = true
+		distinct = append(distinct, id)
+	}
+	if len(distinct) == 0 {
+		return nil, errors.New("missing product IDs")
+	}
+	return distinct, nil
+}
+
+func productImagePrefix(productID int64) string {
+	return fmt.Sprintf("products/%d/", productID)
+}
+
+func (h *ProductHandler) publicImageURL(key string) string {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", h.bucket, h.region, key)
 }
 
 // Category handlers
@@ -186,13 +793,15 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body CreateCategoryRequest true "Category details"
-// @Success 201 {object} CreateCategoryResponse
+// @Security ApiKeyAuth
+// @Param request body productpb.CreateCategoryRequest true "Category details"
+// @Success 201 {object} productpb.CreateCategoryResponse
 // @Router /api/v1/categories [post]
+// @DeprecatedRouter /api/v1/categories/create [post]
 func (h *ProductHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
 	var req productpb.CreateCategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+	if err := decodeJSON(r, &req); err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
@@ -203,7 +812,7 @@ func (h *ProductHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, resp)
+	writeProtoJSON(w, http.StatusCreated, resp)
 }
 
 // GetCategoryByID godoc
@@ -212,10 +821,15 @@ func (h *ProductHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 // @Tags categories
 // @Produce json
 // @Param id path int true "Category ID"
-// @Success 200 {object} GetCategoryByIDResponse
+// @Param If-None-Match header string false "ETag of a copy already held"
+// @Param If-Modified-Since header string false "Last-Modified of a copy already held; ignored when If-None-Match is sent"
+// @Success 200 {object} productpb.GetCategoryByIDResponse
+// @Header 200 {string} Last-Modified "When the gateway first served this body"
+// @Success 304 "The copy held is current"
 // @Router /api/v1/categories/{id} [get]
+// @DeprecatedRouter /api/v1/categories/by-id [get]
 func (h *ProductHandler) GetCategoryByID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Query().Get("id")
+	idStr := resourceID(r)
 	if idStr == "" {
 		writeJSONError(w, http.StatusBadRequest, "missing category ID")
 		return
@@ -237,7 +851,13 @@ func (h *ProductHandler) GetCategoryByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	body, err := protoJSON.Marshal(resp)
+	if err != nil {
+		logger.Errorf("failed to marshal category: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+	writeJSONWithETag(w, r, body, "no-cache", h.clock, fmt.Sprintf("category:%d", id))
 }
 
 // ListCategories godoc
@@ -246,19 +866,11 @@ func (h *ProductHandler) GetCategoryByID(w http.ResponseWriter, r *http.Request)
 // @Tags categories
 // @Produce json
 // @Param page query int false "Page number" default(1)
-// @Param per_page query int false "Items per page" default(10)
-// @Success 200 {object} ListCategoriesResponse
+// @Param per_page query int false "Items per page, capped at 100" default(10)
+// @Success 200 {object} PaginatedResponse
 // @Router /api/v1/categories [get]
 func (h *ProductHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
-	if perPage < 1 || perPage > 100 {
-		perPage = 10
-	}
+	page, perPage := parsePagination(r)
 
 	resp, err := h.productClient.ListCategories(r.Context(), &productpb.ListCategoriesRequest{
 		Page:    int32(page),
@@ -271,7 +883,7 @@ func (h *ProductHandler) ListCategories(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeJSONPaginated(w, http.StatusOK, protoJSONList(resp.GetCategories()), newPaginationMeta(r, page, perPage, int(resp.GetTotalCount())))
 }
 
 // UpdateCategory godoc
@@ -281,13 +893,20 @@ func (h *ProductHandler) ListCategories(w http.ResponseWriter, r *http.Request)
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body UpdateCategoryRequest true "Category update details"
-// @Success 200 {object} UpdateCategoryResponse
+// @Security ApiKeyAuth
+// @Param id path int true "Category ID"
+// @Param request body productpb.UpdateCategoryRequest true "Category update details"
+// @Success 200 {object} productpb.UpdateCategoryResponse
 // @Router /api/v1/categories/{id} [put]
+// @DeprecatedRouter /api/v1/categories/update [put]
 func (h *ProductHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 	var req productpb.UpdateCategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+	if err := decodeJSON(r, &req); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	if err := pathID(r, "id", &req.Id); err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
@@ -298,7 +917,7 @@ func (h *ProductHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeProtoJSON(w, http.StatusOK, resp)
 }
 
 // DeleteCategory godoc
@@ -306,11 +925,13 @@ func (h *ProductHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 // @Description Delete a category (admin only)
 // @Tags categories
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path int true "Category ID"
-// @Success 200 {object} DeleteCategoryResponse
+// @Success 200 {object} productpb.DeleteCategoryResponse
 // @Router /api/v1/categories/{id} [delete]
+// @DeprecatedRouter /api/v1/categories/delete [delete]
 func (h *ProductHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Query().Get("id")
+	idStr := resourceID(r)
 	if idStr == "" {
 		writeJSONError(w, http.StatusBadRequest, "missing category ID")
 		return
@@ -332,5 +953,5 @@ func (h *ProductHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	writeProtoJSON(w, http.StatusOK, resp)
 }
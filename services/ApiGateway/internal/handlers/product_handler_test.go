@@ -0,0 +1,587 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	"golang.org/x/text/currency"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetProductByIDConditionalGET(t *testing.T) {
+	product := &productpb.Product{Id: 1, Name: "Lamp", Price: 20}
+	products := &fakeProductClient{
+		getProductByID: func(*productpb.GetProductByIDRequest) (*productpb.GetProductByIDResponse, error) {
+			return &productpb.GetProductByIDResponse{Product: product}, nil
+		},
+	}
+	clock := NewModificationClock(nil, 24*time.Hour)
+	h := NewProductHandler(products, nil, "", "", 100, clock)
+	get := newTestEngine(http.MethodGet, "/api/v1/products/:id", wrap(h.GetProductByID))
+	request := func(header http.Header) testRequest {
+		return testRequest{method: http.MethodGet, target: "/api/v1/products/1", header: header}
+	}
+
+	w := get(t, request(nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("first request: status = %d, want 200: %s", w.Code, w.Body)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Last-Modified") == "" {
+		t.Fatalf("headers = %v, want an ETag and a Last-Modified date", w.Header())
+	}
+	// Serve the copy as if it were an hour old, so the change below is dated later at one second precision
+	backdate(clock)
+	lastModified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{name: "If-None-Match", header: http.Header{"If-None-Match": {etag}}, want: http.StatusNotModified},
+		{name: "If-Modified-Since", header: http.Header{"If-Modified-Since": {lastModified}}, want: http.StatusNotModified},
+		{name: "If-Modified-Since before the change", header: http.Header{"If-Modified-Since": {time.Now().Add(-2 * time.Hour).UTC().Format(http.TimeFormat)}}, want: http.StatusOK},
+		// If-None-Match decides on its own when both are sent
+		{name: "stale ETag with a current date", header: http.Header{"If-None-Match": {`"other"`}, "If-Modified-Since": {lastModified}}, want: http.StatusOK},
+		{name: "unparseable date", header: http.Header{"If-Modified-Since": {"yesterday"}}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(t, request(tt.header))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if w.Code == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("304 has a body: %s", w.Body)
+			}
+		})
+	}
+
+	product = &productpb.Product{Id: 1, Name: "Lamp", Price: 25}
+	for name, header := range map[string]http.Header{
+		"If-None-Match":     {"If-None-Match": {etag}},
+		"If-Modified-Since": {"If-Modified-Since": {lastModified}},
+	} {
+		w := get(t, request(header))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s after a price change: status = %d, want 200", name, w.Code)
+		}
+		if w.Header().Get("ETag") == etag || w.Header().Get("Last-Modified") == lastModified {
+			t.Errorf("%s after a price change: validators %v did not change", name, w.Header())
+		}
+	}
+}
+
+func TestGetProductByIDPricing(t *testing.T) {
+	products := &fakeProductClient{
+		getProductByID: func(*productpb.GetProductByIDRequest) (*productpb.GetProductByIDResponse, error) {
+			return &productpb.GetProductByIDResponse{Product: &productpb.Product{Id: 1, Name: "Lamp", Price: 20}}, nil
+		},
+	}
+	policy := middleware.NewCurrencyPolicy(middleware.CurrencyConfig{
+		Base:  currency.USD,
+		Rates: map[currency.Unit]float64{currency.EUR: 0.9},
+	})
+	h := NewProductHandler(products, nil, "", "", 100, nil)
+	get := newTestEngine(http.MethodGet, "/api/v1/products/:id", middleware.NegotiatePrices(policy), wrap(h.GetProductByID))
+
+	tests := []struct {
+		name   string
+		target string
+		header http.Header
+		want   *middleware.LocalPrice
+	}{
+		{
+			name:   "supported currency",
+			target: "/api/v1/products/1",
+			header: http.Header{"X-Currency": {"EUR"}},
+			want:   &middleware.LocalPrice{Currency: "EUR", Amount: 18, Formatted: "€ 18.00", Locale: "en", BaseCurrency: "USD", Rate: 0.9},
+		},
+		{
+			name:   "unsupported currency",
+			target: "/api/v1/products/1",
+			header: http.Header{"X-Currency": {"CHF"}},
+			want:   &middleware.LocalPrice{Currency: "USD", Amount: 20, Formatted: "$ 20.00", Locale: "en", BaseCurrency: "USD", Rate: 1},
+		},
+		{
+			name:   "fields without price",
+			target: "/api/v1/products/1?fields=id,name",
+			header: http.Header{"X-Currency": {"EUR"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(t, testRequest{method: http.MethodGet, target: tt.target, header: tt.header})
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+			}
+			var body struct {
+				Product struct {
+					Price   *float64               `json:"price"`
+					Pricing *middleware.LocalPrice `json:"pricing"`
+				} `json:"product"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == nil {
+				if body.Product.Pricing != nil {
+					t.Errorf("pricing = %+v, want none without the price", body.Product.Pricing)
+				}
+				return
+			}
+			// price stays in the base currency next to pricing
+			if body.Product.Price == nil || *body.Product.Price != 20 {
+				t.Errorf("price = %v, want the base 20: %s", body.Product.Price, w.Body)
+			}
+			if body.Product.Pricing == nil || *body.Product.Pricing != *tt.want {
+				t.Errorf("pricing = %+v, want %+v", body.Product.Pricing, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCategoryByIDConditionalGET(t *testing.T) {
+	category := &productpb.Category{Id: 3, Name: "Lighting"}
+	products := &fakeProductClient{
+		getCategory: func(in *productpb.GetCategoryByIDRequest) (*productpb.GetCategoryByIDResponse, error) {
+			if in.GetId() != 3 {
+				return nil, status.Error(codes.NotFound, "category not found")
+			}
+			return &productpb.GetCategoryByIDResponse{Category: category}, nil
+		},
+	}
+	h := NewProductHandler(products, nil, "", "", 100, nil)
+	get := newTestEngine(http.MethodGet, "/api/v1/categories/:id", wrap(h.GetCategoryByID))
+	request := func(header http.Header) testRequest {
+		return testRequest{method: http.MethodGet, target: "/api/v1/categories/3", header: header}
+	}
+
+	w := get(t, request(nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"Lighting"`) {
+		t.Fatalf("first request: got %d %s, want 200 with the category", w.Code, w.Body)
+	}
+	etag := w.Header().Get("ETag")
+	if !regexp.MustCompile(`^"[0-9a-f]{64}"$`).MatchString(etag) || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("headers = %v, want a SHA-256 ETag and Cache-Control: no-cache", w.Header())
+	}
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{name: "matching", header: http.Header{"If-None-Match": {etag}}, want: http.StatusNotModified},
+		{name: "weak", header: http.Header{"If-None-Match": {"W/" + etag}}, want: http.StatusNotModified},
+		{name: "one of a list", header: http.Header{"If-None-Match": {`"other", ` + etag}}, want: http.StatusNotModified},
+		{name: "any", header: http.Header{"If-None-Match": {"*"}}, want: http.StatusNotModified},
+		{name: "stale", header: http.Header{"If-None-Match": {`"other"`}}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(t, request(tt.header))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if w.Header().Get("ETag") != etag {
+				t.Errorf("ETag = %q, want %q", w.Header().Get("ETag"), etag)
+			}
+			if w.Code == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("304 has a body: %s", w.Body)
+			}
+		})
+	}
+
+	category = &productpb.Category{Id: 3, Name: "Lamps"}
+	w = get(t, request(http.Header{"If-None-Match": {etag}}))
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("after a rename: got %d with ETag %q, want 200 with a new ETag", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+// batchCatalog answers GetProductsByIDs from products 1 and 3, recording the IDs each call asked for
+func batchCatalog(requested *[][]int64) *fakeProductClient {
+	catalog := map[int64]*productpb.Product{1: {Id: 1, Name: "Lamp"}, 3: {Id: 3, Name: "Desk"}}
+	return &fakeProductClient{getProducts: func(in *productpb.GetProductsByIDsRequest) (*productpb.GetProductsByIDsResponse, error) {
+		*requested = append(*requested, in.GetIds())
+		resp := &productpb.GetProductsByIDsResponse{}
+		for _, id := range in.GetIds() {
+			if product, ok := catalog[id]; ok {
+				resp.Products = append(resp.Products, product)
+			} else {
+				resp.MissingIds = append(resp.MissingIds, id)
+			}
+		}
+		return resp, nil
+	}}
+}
+
+// batchHandler is the handler of the batch lookup by method
+func batchHandler(h *ProductHandler, method string) gin.HandlerFunc {
+	if method == http.MethodPost {
+		return wrap(h.PostProductsBatch)
+	}
+	return wrap(h.GetProductsBatch)
+}
+
+func TestProductsBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		request testRequest
+	}{
+		{name: "GET", request: testRequest{method: http.MethodGet, target: "/api/v1/products/batch?ids=3,2,3,1,2"}},
+		{name: "POST", request: testRequest{method: http.MethodPost, target: "/api/v1/products/batch", body: `{"ids": [3, 2, 3, 1, 2]}`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested [][]int64
+			h := NewProductHandler(batchCatalog(&requested), nil, "", "", 100, nil)
+			tt.request.route = "/api/v1/products/batch"
+
+			w := serve(t, tt.request, batchHandler(h, tt.request.method))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+			}
+			// Duplicates are dropped before the one call to the product service
+			if len(requested) != 1 || fmt.Sprint(requested[0]) != "[3 2 1]" {
+				t.Fatalf("requested %v, want [3 2 1] once", requested)
+			}
+
+			var resp struct {
+				Products []struct {
+					ID      int64 `json:"id"`
+					Found   bool  `json:"found"`
+					Product *struct {
+						Name string `json:"name"`
+					} `json:"product"`
+				} `json:"products"`
+				MissingIDs []int64 `json:"missing_ids"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(resp.Products))
+			for i, item := range resp.Products {
+				got[i] = fmt.Sprintf("%d:%t", item.ID, item.Found)
+				if item.Found != (item.Product != nil) {
+					t.Errorf("item %d has found=%t with product %v", item.ID, item.Found, item.Product)
+				}
+			}
+			if fmt.Sprint(got) != "[3:true 2:false 1:true]" || resp.Products[0].Product.Name != "Desk" || resp.Products[2].Product.Name != "Lamp" {
+				t.Errorf("products = %s, want the desk, a missing 2 and the lamp in request order", w.Body)
+			}
+			if fmt.Sprint(resp.MissingIDs) != "[2]" {
+				t.Errorf("missing_ids = %v, want [2]", resp.MissingIDs)
+			}
+		})
+	}
+}
+
+func TestProductsBatchRejectsInvalidIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		request testRequest
+		want    string
+	}{
+		{name: "no ids", request: testRequest{method: http.MethodGet, target: "/api/v1/products/batch"}, want: "missing product IDs"},
+		{name: "only commas", request: testRequest{method: http.MethodGet, target: "/api/v1/products/batch?ids=,,"}, want: "missing product IDs"},
+		{name: "empty list", request: testRequest{method: http.MethodPost, target: "/api/v1/products/batch", body: `{"ids": []}`}, want: "missing product IDs"},
+		{name: "not a number", request: testRequest{method: http.MethodGet, target: "/api/v1/products/batch?ids=1,lamp"}, want: `invalid product ID "lamp"`},
+		{name: "zero", request: testRequest{method: http.MethodGet, target: "/api/v1/products/batch?ids=1,0"}, want: `invalid product ID "0"`},
+		{name: "negative", request: testRequest{method: http.MethodPost, target: "/api/v1/products/batch", body: `{"ids": [1, -4]}`}, want: "invalid product ID -4"},
+		{name: "over the cap", request: testRequest{method: http.MethodGet, target: "/api/v1/products/batch?ids=1,2,3,4"}, want: "at most 3 distinct product IDs are allowed"},
+		{name: "over the cap by POST", request: testRequest{method: http.MethodPost, target: "/api/v1/products/batch", body: `{"ids": [1, 2, 3, 4]}`}, want: "at most 3 distinct product IDs are allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested [][]int64
+			h := NewProductHandler(batchCatalog(&requested), nil, "", "", 3, nil)
+			tt.request.route = "/api/v1/products/batch"
+
+			w := serve(t, tt.request, batchHandler(h, tt.request.method))
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", w.Code, w.Body)
+			}
+			if got := errorMessage(t, w); got != tt.want {
+				t.Errorf("message = %q, want %q", got, tt.want)
+			}
+			if len(requested) > 0 {
+				t.Errorf("the product service was asked for %v", requested)
+			}
+		})
+	}
+}
+
+func TestProductsBatchCapCountsDistinctIDs(t *testing.T) {
+	var requested [][]int64
+	h := NewProductHandler(batchCatalog(&requested), nil, "", "", 3, nil)
+
+	w := serve(t, testRequest{method: http.MethodGet, route: "/api/v1/products/batch", target: "/api/v1/products/batch?ids=1,2,1,3,2,3"}, wrap(h.GetProductsBatch))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 for three distinct IDs: %s", w.Code, w.Body)
+	}
+}
+
+func TestListProductsRejectsATamperedCursor(t *testing.T) {
+	const issued = "eyJrIjoiaWQiLCJpZCI6NX0.c2lnbmF0dXJl"
+	products := &fakeProductClient{
+		// The product service verifies the cursor's signature and answers InvalidArgument for any other value
+		listProducts: func(in *productpb.ListProductsRequest) (*productpb.ListProductsResponse, error) {
+			if in.GetCursor() != issued {
+				return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+			}
+			return &productpb.ListProductsResponse{Products: []*productpb.Product{{Id: 6}}, TotalCount: 10}, nil
+		},
+	}
+	h := NewProductHandler(products, nil, "", "", 0, nil)
+
+	w := serve(t, testRequest{method: http.MethodGet, route: "/api/v1/products", target: "/api/v1/products?cursor=" + issued}, wrap(h.ListProducts))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d for the issued cursor, want 200: %s", w.Code, w.Body)
+	}
+
+	tampered := strings.Replace(issued, "NX0", "OX0", 1)
+	w = serve(t, testRequest{method: http.MethodGet, route: "/api/v1/products", target: "/api/v1/products?cursor=" + tampered}, wrap(h.ListProducts))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d for a tampered cursor, want 400: %s", w.Code, w.Body)
+	}
+	if !strings.Contains(w.Body.String(), "invalid cursor") {
+		t.Errorf("body %s does not say the cursor is invalid", w.Body)
+	}
+}
+
+func TestUpdateProductTakesTheIDFromThePath(t *testing.T) {
+	tests := []struct {
+		name       string
+		route      string
+		target     string
+		body       string
+		wantStatus int
+		wantID     int32
+		wantError  string
+	}{
+		{name: "path", route: "/api/v1/products/:id", target: "/api/v1/products/5", body: `{"name": "Lamp"}`, wantStatus: http.StatusOK, wantID: 5},
+		{name: "path over body", route: "/api/v1/products/:id", target: "/api/v1/products/5", body: `{"id": 9, "name": "Lamp"}`, wantStatus: http.StatusOK, wantID: 5},
+		// The deprecated path has no ID of its own
+		{name: "deprecated path", route: "/api/v1/products/update", target: "/api/v1/products/update", body: `{"id": 9, "name": "Lamp"}`, wantStatus: http.StatusOK, wantID: 9},
+		{name: "deprecated path without an ID", route: "/api/v1/products/update", target: "/api/v1/products/update", body: `{"name": "Lamp"}`, wantStatus: http.StatusBadRequest, wantError: "id failed on required"},
+		{name: "non-numeric path", route: "/api/v1/products/:id", target: "/api/v1/products/lamp", body: `{"name": "Lamp"}`, wantStatus: http.StatusBadRequest, wantError: "id in the path must be a positive integer"},
+		{name: "zero in the path", route: "/api/v1/products/:id", target: "/api/v1/products/0", body: `{"id": 9}`, wantStatus: http.StatusBadRequest, wantError: "id in the path must be a positive integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated *productpb.UpdateProductRequest
+			products := &fakeProductClient{updateProduct: func(in *productpb.UpdateProductRequest) (*productpb.UpdateProductResponse, error) {
+				updated = in
+				return &productpb.UpdateProductResponse{}, nil
+			}}
+			h := NewProductHandler(products, nil, "", "", 100, nil)
+
+			w := serve(t, testRequest{method: http.MethodPut, route: tt.route, target: tt.target, body: tt.body}, wrap(h.UpdateProduct))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantError != "" {
+				if got := errorMessage(t, w); got != tt.wantError {
+					t.Errorf("message = %q, want %q", got, tt.wantError)
+				}
+				if updated != nil {
+					t.Errorf("the update was forwarded as %v", updated)
+				}
+				return
+			}
+			if updated.GetId() != tt.wantID || updated.GetName() != "Lamp" {
+				t.Errorf("forwarded %v, want product %d renamed Lamp", updated, tt.wantID)
+			}
+		})
+	}
+}
+
+// fakePresigner signs URLs the way S3 lays them out, without credentials, keeping the requests and the expiry
+// it was asked for
+type fakePresigner struct {
+	region  string
+	inputs  []*s3.PutObjectInput
+	expires time.Duration
+	err     error
+}
+
+func (f *fakePresigner) PresignPutObject(_ context.Context, params *s3.PutObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.inputs = append(f.inputs, params)
+	var options s3.PresignOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+	f.expires = options.Expires
+	return &v4.PresignedHTTPRequest{
+		URL:    fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s?X-Amz-Expires=%d&X-Amz-Signature=abc", aws.ToString(params.Bucket), f.region, aws.ToString(params.Key), int(options.Expires.Seconds())),
+		Method: http.MethodPut,
+	}, nil
+}
+
+func TestGetImageUploadURL(t *testing.T) {
+	presigner := &fakePresigner{region: "eu-west-1"}
+	h := NewProductHandler(&fakeProductClient{}, presigner, "shop-images", "eu-west-1", 100, nil)
+
+	w := serve(t, testRequest{method: http.MethodPost, route: "/api/v1/products/:id/image-url", target: "/api/v1/products/42/image-url"}, h.GetImageUploadURL)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var resp ImageUploadURLResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(presigner.inputs) != 1 || aws.ToString(presigner.inputs[0].Bucket) != "shop-images" {
+		t.Fatalf("presigned %+v, want one upload to shop-images", presigner.inputs)
+	}
+	key := aws.ToString(presigner.inputs[0].Key)
+	if !regexp.MustCompile(`^products/42/[0-9a-f-]{36}\.jpg$`).MatchString(key) {
+		t.Errorf("key = %q, want products/42/<uuid>.jpg", key)
+	}
+	if presigner.expires != 15*time.Minute {
+		t.Errorf("the URL expires after %v, want 15m", presigner.expires)
+	}
+	if !strings.HasPrefix(resp.UploadURL, "https://shop-images.s3.eu-west-1.amazonaws.com/"+key+"?") {
+		t.Errorf("upload_url = %q, want the signed URL of %s in shop-images", resp.UploadURL, key)
+	}
+	if want := "https://shop-images.s3.eu-west-1.amazonaws.com/" + key; resp.PublicURL != want {
+		t.Errorf("public_url = %q, want %q", resp.PublicURL, want)
+	}
+
+	// Every upload gets a key of its own
+	serve(t, testRequest{method: http.MethodPost, route: "/api/v1/products/:id/image-url", target: "/api/v1/products/42/image-url"}, h.GetImageUploadURL)
+	if len(presigner.inputs) != 2 || aws.ToString(presigner.inputs[1].Key) == key {
+		t.Errorf("second upload presigned %+v, want a new key", presigner.inputs[1:])
+	}
+}
+
+func TestGetImageUploadURLErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		presigner S3PresignClient
+		target    string
+		want      int
+	}{
+		{name: "not configured", target: "/products/42/image-url", want: http.StatusServiceUnavailable},
+		{name: "invalid ID", presigner: &fakePresigner{}, target: "/products/abc/image-url", want: http.StatusBadRequest},
+		{name: "signing fails", presigner: &fakePresigner{err: errors.New("no credentials")}, target: "/products/42/image-url", want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewProductHandler(&fakeProductClient{}, tt.presigner, "shop-images", "eu-west-1", 100, nil)
+			w := serve(t, testRequest{method: http.MethodPost, route: "/products/:id/image-url", target: tt.target}, h.GetImageUploadURL)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestUpdateProductImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    int
+		wantURL string
+	}{
+		{
+			name:    "key of the product",
+			body:    `{"image_key": "products/42/0b5e9d3c-4a8e-4d1b-9f5e-2c7a1e6b8d90.jpg"}`,
+			want:    http.StatusOK,
+			wantURL: "https://shop-images.s3.eu-west-1.amazonaws.com/products/42/0b5e9d3c-4a8e-4d1b-9f5e-2c7a1e6b8d90.jpg",
+		},
+		{name: "key of another product", body: `{"image_key": "products/43/0b5e9d3c.jpg"}`, want: http.StatusBadRequest},
+		{name: "key escaping the product", body: `{"image_key": "products/42/../43/0b5e9d3c.jpg"}`, want: http.StatusBadRequest},
+		{name: "missing key", body: `{}`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updates []*productpb.UpdateProductRequest
+			products := &fakeProductClient{updateProduct: func(in *productpb.UpdateProductRequest) (*productpb.UpdateProductResponse, error) {
+				updates = append(updates, in)
+				return &productpb.UpdateProductResponse{}, nil
+			}}
+			h := NewProductHandler(products, &fakePresigner{}, "shop-images", "eu-west-1", 100, nil)
+
+			w := serve(t, testRequest{method: http.MethodPatch, route: "/api/v1/products/:id/image", target: "/api/v1/products/42/image", body: tt.body}, h.UpdateProductImage)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want != http.StatusOK {
+				if len(updates) > 0 {
+					t.Fatalf("the product was updated with %v", updates)
+				}
+				return
+			}
+			if len(updates) != 1 || updates[0].GetId() != 42 || updates[0].GetImageUrl() != tt.wantURL {
+				t.Fatalf("updates = %v, want product 42 pointed at %s", updates, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestAdjustStock(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       string
+	}{
+		{name: "restock", body: `{"delta": 10}`, wantStatus: http.StatusOK, want: `{"product_id":1,"new_stock":45}`},
+		{name: "partial deduction", body: `{"delta": -30}`, wantStatus: http.StatusOK, want: `{"product_id":1,"new_stock":5}`},
+		{name: "over-deduction", body: `{"delta": -36}`, wantStatus: http.StatusConflict},
+		{name: "zero delta", body: `{"delta": 0}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The product service holds 35 and refuses to go below zero, as AdjustProductStock does
+			var requests []*productpb.AdjustProductStockRequest
+			products := &fakeProductClient{adjustStock: func(in *productpb.AdjustProductStockRequest) (*productpb.AdjustProductStockResponse, error) {
+				requests = append(requests, in)
+				if 35+in.GetDelta() < 0 {
+					return nil, status.Error(codes.FailedPrecondition, "insufficient stock")
+				}
+				return &productpb.AdjustProductStockResponse{ProductId: in.GetProductId(), StockLevel: 35 + in.GetDelta()}, nil
+			}}
+			h := NewProductHandler(products, nil, "", "", 100, nil)
+
+			w := serve(t, testRequest{method: http.MethodPatch, route: "/api/v1/products/:id/stock", target: "/api/v1/products/1/stock", body: tt.body}, h.AdjustStock)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			switch tt.wantStatus {
+			case http.StatusOK:
+				if strings.TrimSpace(w.Body.String()) != tt.want {
+					t.Errorf("body = %s, want %s", w.Body, tt.want)
+				}
+			case http.StatusConflict:
+				if message := errorMessage(t, w); message != "insufficient stock" {
+					t.Errorf("message = %q, want the product service's", message)
+				}
+			case http.StatusBadRequest:
+				if len(requests) > 0 {
+					t.Errorf("the product service was asked for %v", requests)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxImportRows       = 500
+	maxImportFileBytes  = 5 << 20
+	importConcurrency   = 10
+	importFormatCSV     = "csv"
+	importFormatJSON    = "json"
+	importFileFormField = "file"
+)
+
+var importRequiredColumns = []string{"name", "description", "price"}
+
+// BulkImportResponse summarises a product import. Rows are numbered from 1, excluding the CSV header.
+type BulkImportResponse struct {
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// ImportRowError explains why one row was not imported
+type ImportRowError struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// importRow is one parsed row, or the reason it could not be parsed
+type importRow struct {
+	number  int
+	product CreateProductRequest
+	err     error
+}
+
+// BulkImport godoc
+// @Summary Bulk import products
+// @Description Create up to 500 products from a JSON array or CSV file (admin only). The format is taken from the
+// @Description file's Content-Type, falling back to its .json or .csv extension. CSV files need a header row naming
+// @Description the columns, which are the JSON field names of CreateProductRequest. Invalid rows are skipped and reported.
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param file formData file true "JSON or CSV file"
+// @Success 200 {object} BulkImportResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/admin/products/import [post]
+func (h *ProductHandler) BulkImport(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileBytes)
+	file, header, err := r.FormFile(importFileFormField)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("multipart field %q must hold a file of at most %d bytes", importFileFormField, maxImportFileBytes))
+		return
+	}
+	defer file.Close()
+
+	format := detectImportFormat(header.Header.Get("Content-Type"), header.Filename)
+	var rows []importRow
+	switch format {
+	case importFormatCSV:
+		rows, err = parseCSVImport(file)
+	case importFormatJSON:
+		rows, err = parseJSONImport(file)
+	default:
+		err = errors.New("file must be JSON or CSV")
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.importRows(r, rows))
+}
+
+// importRows creates every valid row with at most importConcurrency CreateProduct calls in flight
+func (h *ProductHandler) importRows(r *http.Request, rows []importRow) BulkImportResponse {
+	response := BulkImportResponse{Errors: []ImportRowError{}}
+	var mu sync.Mutex
+	fail := func(row int, reason string) {
+		mu.Lock()
+		defer mu.Unlock()
+		response.Failed++
+		response.Errors = append(response.Errors, ImportRowError{Row: row, Reason: reason})
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, importConcurrency)
+	for _, row := range rows {
+		if row.err == nil {
+			row.err = validateRequest(&row.product)
+		}
+		if row.err != nil {
+			fail(row.number, row.err.Error())
+			continue
+		}
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(row importRow) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			_, err := h.productClient.CreateProduct(r.Context(), &productpb.CreateProductRequest{
+				Name:             row.product.Name,
+				ShortDescription: row.product.ShortDescription,
+				Description:      row.product.Description,
+				Price:            row.product.Price,
+				DiscountType:     discountTypeToProto(row.product.DiscountType),
+				DiscountValue:    row.product.DiscountValue,
+				ImageUrl:         row.product.ImageURL,
+				Quantity:         row.product.Quantity,
+			})
+			if err != nil {
+				logger.Warnf("event=product_import_row_failed row=%d error=%v", row.number, err)
+				if st, ok := status.FromError(err); ok {
+					fail(row.number, st.Message())
+				} else {
+					fail(row.number, err.Error())
+				}
+				return
+			}
+			mu.Lock()
+			response.Imported++
+			mu.Unlock()
+		}(row)
+	}
+	wg.Wait()
+
+	sort.Slice(response.Errors, func(i, j int) bool { return response.Errors[i].Row < response.Errors[j].Row })
+	return response
+}
+
+// detectImportFormat prefers the part's Content-Type and falls back to the file extension
+func detectImportFormat(contentType, filename string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "text/csv", "application/csv":
+			return importFormatCSV
+		case "application/json":
+			return importFormatJSON
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return importFormatCSV
+	case ".json":
+		return importFormatJSON
+	}
+	return ""
+}
+
+// parseJSONImport reads an array of CreateProductRequest objects. A malformed object only fails its own row.
+func parseJSONImport(file io.Reader) ([]importRow, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(file).Decode(&raw); err != nil {
+		return nil, errors.New("JSON file must hold an array of products")
+	}
+	if len(raw) > maxImportRows {
+		return nil, fmt.Errorf("file has %d rows, the limit is %d", len(raw), maxImportRows)
+	}
+
+	rows := make([]importRow, len(raw))
+	for i, item := range raw {
+		rows[i].number = i + 1
+		if err := json.Unmarshal(item, &rows[i].product); err != nil {
+			rows[i].err = errors.New("invalid product object")
+		}
+	}
+	return rows, nil
+}
+
+// parseCSVImport reads a header row of CreateProductRequest JSON field names followed by one product per row
+func parseCSVImport(file io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("CSV file must start with a header row")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %q column", name)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("file has more than %d rows", maxImportRows)
+		}
+
+		row := importRow{number: len(rows) + 1}
+		if err != nil {
+			row.err = errors.New("malformed CSV row")
+		} else {
+			row.product, row.err = csvProduct(columns, record)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func csvProduct(columns map[string]int, record []string) (CreateProductRequest, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	product := CreateProductRequest{
+		Name:             field("name"),
+		ShortDescription: field("short_description"),
+		Description:      field("description"),
+		DiscountType:     field("discount_type"),
+		ImageURL:         field("image_url"),
+	}
+
+	var err error
+	if product.Price, err = parseCSVFloat(field("price")); err != nil {
+		return product, errors.New("price must be a number")
+	}
+	if product.DiscountValue, err = parseCSVFloat(field("discount_value")); err != nil {
+		return product, errors.New("discount_value must be a number")
+	}
+	if value := field("quantity"); value != "" {
+		quantity, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return product, errors.New("quantity must be a whole number")
+		}
+		product.Quantity = int32(quantity)
+	}
+	return product, nil
+}
+
+// parseCSVFloat treats an empty cell as zero so optional columns may be left blank
+func parseCSVFloat(value string) (float32, error) {
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 32)
+	return float32(parsed), err
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// importRequest uploads content as the multipart file field BulkImport reads
+func importRequest(t *testing.T, filename, contentType, content string) testRequest {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part := textproto.MIMEHeader{}
+	part.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, importFileFormField, filename))
+	if contentType != "" {
+		part.Set("Content-Type", contentType)
+	}
+	w, err := form.CreatePart(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return testRequest{
+		method: http.MethodPost,
+		route:  "/api/v1/admin/products/import",
+		target: "/api/v1/admin/products/import",
+		body:   body.String(),
+		header: http.Header{"Content-Type": {form.FormDataContentType()}},
+	}
+}
+
+// importCatalog records the products it creates and refuses the name "Duplicate", as a unique index would
+func importCatalog() (*fakeProductClient, func() []string) {
+	var mu sync.Mutex
+	var created []string
+	products := &fakeProductClient{createProduct: func(in *productpb.CreateProductRequest) (*productpb.CreateProductResponse, error) {
+		if in.GetName() == "Duplicate" {
+			return nil, status.Error(codes.AlreadyExists, "product already exists")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		created = append(created, in.GetName())
+		return &productpb.CreateProductResponse{Product: &productpb.Product{Name: in.GetName()}}, nil
+	}}
+	return products, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Sorted(slices.Values(created))
+	}
+}
+
+func TestBulkImportReportsEachRow(t *testing.T) {
+	csvFile := strings.Join([]string{
+		"name,description,price,quantity",
+		"Desk,Oak desk,120.5,3",
+		"Lamp,Brass lamp,not-a-price,1",
+		"X,Too short a name,10,1",
+		"Duplicate,Already listed,15,1",
+		`Chair,"Chair, padded",45,`,
+	}, "\n")
+	jsonFile := `[
+		{"name": "Desk", "description": "Oak desk", "price": 120.5, "quantity": 3},
+		{"name": "Lamp", "description": "Brass lamp", "price": "cheap"},
+		{"name": "X", "description": "Too short a name", "price": 10},
+		{"name": "Duplicate", "description": "Already listed", "price": 15},
+		{"name": "Chair", "description": "Chair, padded", "price": 45}
+	]`
+	// Both files hold the same rows, so they get the same report
+	want := BulkImportResponse{Imported: 2, Failed: 3, Errors: []ImportRowError{
+		{Row: 2},
+		{Row: 3, Reason: "name failed on min"},
+		{Row: 4, Reason: "product already exists"},
+	}}
+
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		content     string
+		badRow      string
+	}{
+		{name: "csv", filename: "products.csv", contentType: "text/csv", content: csvFile, badRow: "price must be a number"},
+		{name: "json", filename: "products.json", contentType: "application/json", content: jsonFile, badRow: "invalid product object"},
+		{name: "csv by extension", filename: "products.CSV", contentType: "application/octet-stream", content: csvFile, badRow: "price must be a number"},
+		{name: "json by extension", filename: "products.json", content: jsonFile, badRow: "invalid product object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products, created := importCatalog()
+			h := NewProductHandler(products, nil, "", "", 100, nil)
+
+			w := serve(t, importRequest(t, tt.filename, tt.contentType, tt.content), wrap(h.BulkImport))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+			}
+			var got BulkImportResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("body %s: %v", w.Body, err)
+			}
+			want := want
+			want.Errors = slices.Clone(want.Errors)
+			want.Errors[0].Reason = tt.badRow
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("report = %+v, want %+v", got, want)
+			}
+			if names := created(); !reflect.DeepEqual(names, []string{"Chair", "Desk"}) {
+				t.Errorf("created %v, want only the valid rows", names)
+			}
+		})
+	}
+}
+
+func TestBulkImportRejectsTheWholeFile(t *testing.T) {
+	header := "name,description,price\n"
+	row := "Desk,Oak desk,120\n"
+	jsonRows := make([]string, maxImportRows+1)
+	for i := range jsonRows {
+		jsonRows[i] = `{"name": "Desk", "description": "Oak desk", "price": 120}`
+	}
+
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		content     string
+		want        string
+	}{
+		{name: "csv over the row limit", filename: "p.csv", content: header + strings.Repeat(row, maxImportRows+1), want: "file has more than 500 rows"},
+		{name: "json over the row limit", filename: "p.json", content: "[" + strings.Join(jsonRows, ",") + "]", want: "file has 501 rows, the limit is 500"},
+		{name: "over the size limit", filename: "p.csv", content: header + strings.Repeat("x", maxImportFileBytes), want: `multipart field "file" must hold a file`},
+		{name: "csv without required column", filename: "p.csv", content: "name,price\nDesk,120\n", want: `CSV header is missing the "description" column`},
+		{name: "empty csv", filename: "p.csv", content: "", want: "CSV file must start with a header row"},
+		{name: "json object", filename: "p.json", content: `{"name": "Desk"}`, want: "JSON file must hold an array of products"},
+		{name: "unknown format", filename: "p.xlsx", contentType: "application/vnd.ms-excel", content: "PK", want: "file must be JSON or CSV"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products, created := importCatalog()
+			h := NewProductHandler(products, nil, "", "", 100, nil)
+
+			w := serve(t, importRequest(t, tt.filename, tt.contentType, tt.content), wrap(h.BulkImport))
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", w.Code, w.Body)
+			}
+			if message := errorMessage(t, w); !strings.HasPrefix(message, tt.want) {
+				t.Errorf("message = %q, want %q", message, tt.want)
+			}
+			if names := created(); len(names) > 0 {
+				t.Errorf("created %v from a rejected file", names)
+			}
+		})
+	}
+}
+
+func TestBulkImportAcceptsTheRowLimit(t *testing.T) {
+	products, created := importCatalog()
+	h := NewProductHandler(products, nil, "", "", 100, nil)
+	content := "name,description,price\n" + strings.Repeat("Desk,Oak desk,120\n", maxImportRows)
+
+	w := serve(t, importRequest(t, "p.csv", "text/csv", content), wrap(h.BulkImport))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if names := created(); len(names) != maxImportRows {
+		t.Errorf("created %d products, want %d", len(names), maxImportRows)
+	}
+}
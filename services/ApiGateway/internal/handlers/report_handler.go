@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	redisClient "github.com/kareemhamed001/e-commerce/pkg/redis"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+)
+
+const (
+	reportDateLayout          = "2006-01-02"
+	reportCacheTTL            = 5 * time.Minute
+	revenueCacheKeyPrefix     = "reports:revenue:"
+	salesCacheKeyPrefix       = "reports:sales:"
+	topProductsCacheKeyPrefix = "reports:top-products:"
+	// defaultTopProducts and maxTopProducts bound the limit of the top products report
+	defaultTopProducts = 10
+	maxTopProducts     = 100
+)
+
+// ReportHandler handles admin reporting HTTP requests
+type ReportHandler struct {
+	orderClient orderpb.OrderServiceClient
+	cache       *redisClient.Client
+}
+
+// RevenuePeriod is one bucket of the revenue report
+type RevenuePeriod struct {
+	Start      string  `json:"start"`
+	End        string  `json:"end"`
+	Revenue    float64 `json:"revenue"`
+	OrderCount int64   `json:"order_count"`
+}
+
+// RevenueReportResponse is the revenue report payload
+type RevenueReportResponse struct {
+	Periods []RevenuePeriod `json:"periods"`
+}
+
+// SalesPeriod is one bucket of the sales report, or its whole range as the total. Revenue, order count and
+// average cover paid and delivered orders; canceled orders are counted apart.
+type SalesPeriod struct {
+	Start             string  `json:"start" example:"2026-01-05"`
+	End               string  `json:"end" example:"2026-01-11"`
+	Revenue           float64 `json:"revenue" example:"1520.5"`
+	OrderCount        int64   `json:"order_count" example:"12"`
+	AverageOrderValue float64 `json:"average_order_value" example:"126.71"`
+	CanceledCount     int64   `json:"canceled_count" example:"1"`
+	CanceledValue     float64 `json:"canceled_value" example:"89.99"`
+}
+
+// SalesReportResponse has a period for every day, week or month of the range, oldest first, including
+// those without sales
+type SalesReportResponse struct {
+	From    string        `json:"from" example:"2026-01-01"`
+	To      string        `json:"to" example:"2026-03-31"`
+	GroupBy string        `json:"group_by" example:"week"`
+	Periods []SalesPeriod `json:"periods"`
+	Total   SalesPeriod   `json:"total"`
+}
+
+// TopProduct is what one product sold in paid and delivered orders. Revenue is the price of its
+// items, before shipping and discounts.
+type TopProduct struct {
+	ProductID  int64   `json:"product_id" example:"42"`
+	Name       string  `json:"name,omitempty" example:"Wireless Mouse"`
+	Quantity   int64   `json:"quantity" example:"37"`
+	Revenue    float64 `json:"revenue" example:"924.63"`
+	OrderCount int64   `json:"order_count" example:"30"`
+}
+
+// TopProductsResponse ranks products by revenue, highest first
+type TopProductsResponse struct {
+	From     string       `json:"from" example:"2026-01-01"`
+	To       string       `json:"to" example:"2026-03-31"`
+	Products []TopProduct `json:"products"`
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(orderClient orderpb.OrderServiceClient, cache *redisClient.Client) *ReportHandler {
+	return &ReportHandler{
+		orderClient: orderClient,
+		cache:       cache,
+	}
+}
+
+// Revenue godoc
+// @Summary Revenue report
+// @Description Revenue and order counts grouped by day, week or month (admin only)
+// @Tags reports
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD), inclusive"
+// @Param granularity query string false "day, week or month" default(day)
+// @Success 200 {object} RevenueReportResponse
+// @Router /api/v1/admin/reports/revenue [get]
+func (h *ReportHandler) Revenue(w http.ResponseWriter, r *http.Request) {
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+	granularity := r.URL.Query().Get("granularity")
+	if granularity == "" {
+		granularity = "day"
+	}
+
+	if err := validateRevenueQuery(startDate, endDate, granularity); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cacheKey := fmt.Sprintf("%s%s:%s:%s", revenueCacheKeyPrefix, startDate, endDate, granularity)
+	var cached RevenueReportResponse
+	if h.getCachedReport(r.Context(), cacheKey, &cached) {
+		writeJSON(w, http.StatusOK, cached)
+		return
+	}
+
+	resp, err := h.orderClient.GetRevenueSummary(r.Context(), &orderpb.GetRevenueSummaryRequest{
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Granularity: granularity,
+	})
+	if err != nil {
+		logger.Errorf("failed to get revenue summary: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	report := RevenueReportResponse{Periods: make([]RevenuePeriod, 0, len(resp.GetPeriods()))}
+	for _, period := range resp.GetPeriods() {
+		report.Periods = append(report.Periods, RevenuePeriod{
+			Start:      period.GetStart(),
+			End:        period.GetEnd(),
+			Revenue:    period.GetRevenue(),
+			OrderCount: period.GetOrderCount(),
+		})
+	}
+
+	h.setCachedReport(r.Context(), cacheKey, &report)
+	writeJSON(w, http.StatusOK, report)
+}
+
+// Sales godoc
+// @Summary Sales report
+// @Description Revenue, order count and average order value of paid and delivered orders per day, week or month, with canceled
+// @Description orders counted apart (admin only). Every period of the range is listed, oldest first, with zeros where nothing sold, followed
+// @Description by the total. Weeks start on Monday; the first and last periods are cut to the range. Dates are UTC.
+// @Tags reports
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param from query string true "First day (YYYY-MM-DD)"
+// @Param to query string true "Last day (YYYY-MM-DD), inclusive and at most a year after from"
+// @Param group_by query string false "day, week or month" default(day)
+// @Success 200 {object} SalesReportResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/admin/reports/sales [get]
+func (h *ReportHandler) Sales(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = "day"
+	}
+
+	if err := validateReportRange(from, to); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if groupBy != "day" && groupBy != "week" && groupBy != "month" {
+		writeJSONError(w, http.StatusBadRequest, "group_by must be one of day, week, month")
+		return
+	}
+
+	cacheKey := fmt.Sprintf("%s%s:%s:%s", salesCacheKeyPrefix, from, to, groupBy)
+	var cached SalesReportResponse
+	if h.getCachedReport(r.Context(), cacheKey, &cached) {
+		writeJSON(w, http.StatusOK, cached)
+		return
+	}
+
+	resp, err := h.orderClient.GetSalesReport(r.Context(), &orderpb.GetSalesReportRequest{
+		From:    from,
+		To:      to,
+		GroupBy: groupBy,
+	})
+	if err != nil {
+		logger.Errorf("failed to get sales report: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	report := SalesReportResponse{
+		From:    from,
+		To:      to,
+		GroupBy: groupBy,
+		Periods: make([]SalesPeriod, 0, len(resp.GetPeriods())),
+		Total:   salesPeriod(resp.GetTotal()),
+	}
+	for _, period := range resp.GetPeriods() {
+		report.Periods = append(report.Periods, salesPeriod(period))
+	}
+
+	h.setCachedReport(r.Context(), cacheKey, &report)
+	writeJSON(w, http.StatusOK, report)
+}
+
+// TopProducts godoc
+// @Summary Top products report
+// @Description The products with the highest revenue in paid and delivered orders created in the range, with the quantity sold and
+// @Description the number of orders (admin only). Revenue is the price of the items, before shipping and discounts; ties are ordered by
+// @Description product ID. Names are left out when the product service cannot be reached. Dates are UTC.
+// @Tags reports
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param from query string true "First day (YYYY-MM-DD)"
+// @Param to query string true "Last day (YYYY-MM-DD), inclusive and at most a year after from"
+// @Param limit query int false "Number of products, at most 100" default(10)
+// @Success 200 {object} TopProductsResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/admin/reports/top-products [get]
+func (h *ReportHandler) TopProducts(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+
+	if err := validateReportRange(from, to); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := defaultTopProducts
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxTopProducts {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxTopProducts))
+			return
+		}
+		limit = parsed
+	}
+
+	cacheKey := fmt.Sprintf("%s%s:%s:%d", topProductsCacheKeyPrefix, from, to, limit)
+	var cached TopProductsResponse
+	if h.getCachedReport(r.Context(), cacheKey, &cached) {
+		writeJSON(w, http.StatusOK, cached)
+		return
+	}
+
+	resp, err := h.orderClient.GetTopProducts(r.Context(), &orderpb.GetTopProductsRequest{
+		From:  from,
+		To:    to,
+		Limit: int32(limit),
+	})
+	if err != nil {
+		logger.Errorf("failed to get top products: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	report := TopProductsResponse{From: from, To: to, Products: make([]TopProduct, 0, len(resp.GetProducts()))}
+	for _, product := range resp.GetProducts() {
+		report.Products = append(report.Products, TopProduct{
+			ProductID:  product.GetProductId(),
+			Name:       product.GetName(),
+			Quantity:   product.GetQuantity(),
+			Revenue:    product.GetRevenue(),
+			OrderCount: product.GetOrderCount(),
+		})
+	}
+
+	// A report missing names is not cached, so they appear once the product service is back
+	if !slices.ContainsFunc(report.Products, func(product TopProduct) bool { return product.Name == "" }) {
+		h.setCachedReport(r.Context(), cacheKey, &report)
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func salesPeriod(period *orderpb.SalesPeriod) SalesPeriod {
+	return SalesPeriod{
+		Start:             period.GetStart(),
+		End:               period.GetEnd(),
+		Revenue:           period.GetRevenue(),
+		OrderCount:        period.GetOrderCount(),
+		AverageOrderValue: period.GetAverageOrderValue(),
+		CanceledCount:     period.GetCanceledCount(),
+		CanceledValue:     period.GetCanceledValue(),
+	}
+}
+
+func validateRevenueQuery(startDate, endDate, granularity string) error {
+	if startDate == "" || endDate == "" {
+		return fmt.Errorf("start_date and end_date are required")
+	}
+
+	start, err := time.Parse(reportDateLayout, startDate)
+	if err != nil {
+		return fmt.Errorf("start_date must be in YYYY-MM-DD format")
+	}
+	end, err := time.Parse(reportDateLayout, endDate)
+	if err != nil {
+		return fmt.Errorf("end_date must be in YYYY-MM-DD format")
+	}
+	if !start.Before(end) {
+		return fmt.Errorf("start_date must be before end_date")
+	}
+
+	switch granularity {
+	case "day", "week", "month":
+		return nil
+	default:
+		return fmt.Errorf("granularity must be one of day, week, month")
+	}
+}
+
+// validateReportRange checks the from and to of the sales and top products reports, which cover at most a year
+func validateReportRange(from, to string) error {
+	if from == "" || to == "" {
+		return fmt.Errorf("from and to are required")
+	}
+
+	start, err := time.Parse(reportDateLayout, from)
+	if err != nil {
+		return fmt.Errorf("from must be in YYYY-MM-DD format")
+	}
+	end, err := time.Parse(reportDateLayout, to)
+	if err != nil {
+		return fmt.Errorf("to must be in YYYY-MM-DD format")
+	}
+	if end.Before(start) {
+		return fmt.Errorf("to must not be before from")
+	}
+	if !end.Before(start.AddDate(1, 0, 0)) {
+		return fmt.Errorf("the range must not be longer than a year")
+	}
+	return nil
+}
+
+// getCachedReport decodes the report cached under key into report, reporting whether there was one
+func (h *ReportHandler) getCachedReport(ctx context.Context, key string, report any) bool {
+	if h.cache == nil || !h.cache.IsEnabled() {
+		return false
+	}
+
+	data, err := h.cache.Get(ctx, key).Bytes()
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, report) == nil
+}
+
+func (h *ReportHandler) setCachedReport(ctx context.Context, key string, report any) {
+	if h.cache == nil || !h.cache.IsEnabled() {
+		return
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return
+	}
+
+	if err := h.cache.Set(ctx, key, data, reportCacheTTL).Err(); err != nil {
+		logger.Warnf("failed to cache report %s: %v", key, err)
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRevenueRejectsInvalidQueries(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "no dates", query: "", want: "start_date and end_date are required"},
+		{name: "no end date", query: "start_date=2026-01-01", want: "start_date and end_date are required"},
+		{name: "start date not a date", query: "start_date=01/01/2026&end_date=2026-01-31", want: "start_date must be in YYYY-MM-DD format"},
+		{name: "end date not a date", query: "start_date=2026-01-01&end_date=2026-01-32", want: "end_date must be in YYYY-MM-DD format"},
+		{name: "end date with a time", query: "start_date=2026-01-01&end_date=2026-01-31T00:00:00Z", want: "end_date must be in YYYY-MM-DD format"},
+		{name: "start after end", query: "start_date=2026-02-01&end_date=2026-01-31", want: "start_date must be before end_date"},
+		{name: "start equal to end", query: "start_date=2026-01-31&end_date=2026-01-31", want: "start_date must be before end_date"},
+		{name: "unknown granularity", query: "start_date=2026-01-01&end_date=2026-01-31&granularity=year", want: "granularity must be one of day, week, month"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderClient{}
+			h := NewReportHandler(orders, nil)
+			w := serve(t, testRequest{method: http.MethodGet, route: "/revenue", target: "/revenue?" + tt.query}, wrap(h.Revenue))
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("got %d %s, want 400", w.Code, w.Body)
+			}
+			if got := errorMessage(t, w); got != tt.want {
+				t.Errorf("message = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevenueReturnsThePeriods(t *testing.T) {
+	var requested *orderpb.GetRevenueSummaryRequest
+	orders := &fakeOrderClient{
+		getRevenueSummary: func(in *orderpb.GetRevenueSummaryRequest) (*orderpb.GetRevenueSummaryResponse, error) {
+			requested = in
+			return &orderpb.GetRevenueSummaryResponse{Periods: []*orderpb.RevenuePeriod{
+				{Start: "2026-01-01", End: "2026-01-01", Revenue: 1234.56, OrderCount: 42},
+				{Start: "2026-01-02", End: "2026-01-02"},
+			}}, nil
+		},
+	}
+	h := NewReportHandler(orders, nil)
+	w := serve(t, testRequest{method: http.MethodGet, route: "/revenue", target: "/revenue?start_date=2026-01-01&end_date=2026-01-02"}, wrap(h.Revenue))
+
+	want := `{"periods":[{"start":"2026-01-01","end":"2026-01-01","revenue":1234.56,"order_count":42},{"start":"2026-01-02","end":"2026-01-02","revenue":0,"order_count":0}]}` + "\n"
+	if w.Code != http.StatusOK || w.Body.String() != want {
+		t.Fatalf("got %d %s, want 200 %s", w.Code, w.Body, want)
+	}
+	if requested.GetStartDate() != "2026-01-01" || requested.GetEndDate() != "2026-01-02" || requested.GetGranularity() != "day" {
+		t.Errorf("request = %v, want the dates with the day granularity by default", requested)
+	}
+}
+
+func TestRevenueMapsServiceErrors(t *testing.T) {
+	orders := &fakeOrderClient{
+		getRevenueSummary: func(*orderpb.GetRevenueSummaryRequest) (*orderpb.GetRevenueSummaryResponse, error) {
+			return nil, status.Error(codes.Unavailable, "order service down")
+		},
+	}
+	h := NewReportHandler(orders, nil)
+	w := serve(t, testRequest{method: http.MethodGet, route: "/revenue", target: "/revenue?start_date=2026-01-01&end_date=2026-03-31&granularity=month"}, wrap(h.Revenue))
+
+	if w.Code != http.StatusServiceUnavailable || errorMessage(t, w) != "order service down" {
+		t.Fatalf("got %d %s, want 503 with the service's message", w.Code, w.Body)
+	}
+}
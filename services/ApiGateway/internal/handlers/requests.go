@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+)
+
+// Request bodies are decoded into these DTOs rather than straight into protobuf messages,
+// so clients can only set the fields listed here and the proto is always built server-side.
+
+var validate = newValidator()
+
+// newValidator reports fields by their JSON names so errors match what the client sent
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+type RegisterRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// EnableTwoFactorRequest confirms the secret from setup with a code from the authenticator
+type EnableTwoFactorRequest struct {
+	Code string `json:"code" validate:"required" example:"123456"`
+}
+
+// VerifyTwoFactorRequest completes a login that answered two_factor_required
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	// Code is a code from the authenticator or one of the recovery codes
+	Code string `json:"code" validate:"required" example:"123456"`
+}
+
+type UpdateUserRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type CreateAddressRequest struct {
+	Street     string `json:"street" validate:"required,min=2,max=100"`
+	City       string `json:"city" validate:"required,min=2,max=50"`
+	State      string `json:"state" validate:"required,min=2,max=50"`
+	Country    string `json:"country" validate:"required,min=2,max=50"`
+	PostalCode string `json:"postal_code" validate:"required,min=2,max=20"`
+	Label      string `json:"label" validate:"omitempty,max=50"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+type UpdateAddressRequest struct {
+	// ID is only read by the deprecated PUT /api/v1/addresses/update, which has no ID in its path
+	ID         int32  `json:"id" validate:"required,gt=0"`
+	Street     string `json:"street" validate:"omitempty,min=2,max=100"`
+	City       string `json:"city" validate:"omitempty,min=2,max=50"`
+	State      string `json:"state" validate:"omitempty,min=2,max=50"`
+	Country    string `json:"country" validate:"omitempty,min=2,max=50"`
+	PostalCode string `json:"postal_code" validate:"omitempty,min=2,max=20"`
+	Label      string `json:"label" validate:"omitempty,max=50"`
+}
+
+type CreateProductRequest struct {
+	Name             string  `json:"name" validate:"required,min=2,max=100"`
+	ShortDescription string  `json:"short_description" validate:"omitempty,min=2,max=150"`
+	Description      string  `json:"description" validate:"required,min=2"`
+	Price            float32 `json:"price" validate:"required,gt=0"`
+	DiscountType     string  `json:"discount_type" validate:"omitempty,oneof=none percent fixed"`
+	DiscountValue    float32 `json:"discount_value" validate:"omitempty,gt=0"`
+	ImageURL         string  `json:"image_url" validate:"omitempty,url"`
+	Quantity         int32   `json:"quantity" validate:"gte=0"`
+}
+
+type UpdateProductRequest struct {
+	// ID is only read by the deprecated PUT /api/v1/products/update, which has no ID in its path
+	ID               int32   `json:"id" validate:"required,gt=0"`
+	Name             string  `json:"name" validate:"omitempty,min=2,max=100"`
+	ShortDescription string  `json:"short_description" validate:"omitempty,min=2,max=150"`
+	Description      string  `json:"description" validate:"omitempty,min=2"`
+	Price            float32 `json:"price" validate:"omitempty,gt=0"`
+	DiscountType     string  `json:"discount_type" validate:"omitempty,oneof=none percent fixed"`
+	DiscountValue    float32 `json:"discount_value" validate:"omitempty,gt=0"`
+	ImageURL         string  `json:"image_url" validate:"omitempty,url"`
+	Quantity         int32   `json:"quantity" validate:"gte=0"`
+	// LowStockThreshold is the stock at or below which the product is listed as low; left unchanged when omitted
+	LowStockThreshold *int32 `json:"low_stock_threshold,omitempty" validate:"omitempty,gte=0"`
+}
+
+type UpdateProductImageRequest struct {
+	ImageKey string `json:"image_key" validate:"required,max=255"`
+}
+
+type CreateReviewRequest struct {
+	Rating  int32  `json:"rating" validate:"required,min=1,max=5"`
+	Comment string `json:"comment" validate:"max=2000"`
+}
+
+// AdjustStockRequest is a signed stock change; zero is rejected
+type AdjustStockRequest struct {
+	Delta int32 `json:"delta" validate:"required"`
+}
+
+// StockAdjustmentRequest is a signed stock change with why it was made; order movements are only recorded
+// by the order flow
+type StockAdjustmentRequest struct {
+	Delta  int32  `json:"delta" validate:"required"`
+	Reason string `json:"reason" validate:"required,oneof=recount damage return correction"`
+}
+
+type WishlistItemRequest struct {
+	ProductID int64 `json:"product_id" validate:"required,gt=0"`
+}
+
+// CartItemRequest is the body of both adding an item to the cart and changing its quantity
+type CartItemRequest struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int32 `json:"quantity"`
+}
+
+type RemoveCartItemRequest struct {
+	ProductID int64 `json:"product_id"`
+}
+
+// MarkNotificationsReadRequest marks IDs read, or every notification when IDs is empty
+type MarkNotificationsReadRequest struct {
+	IDs []int64 `json:"ids" validate:"max=100,dive,gt=0"`
+}
+
+// NotificationPreferencesRequest replaces every toggle, so each one must be sent
+type NotificationPreferencesRequest struct {
+	Email *bool `json:"email" validate:"required"`
+	SMS   *bool `json:"sms" validate:"required"`
+	Push  *bool `json:"push" validate:"required"`
+}
+
+// CreateOrderRequest ships to AddressID, or to the user's default address when it is omitted.
+// AddressID is capped at int32 because UserService address IDs are int32.
+// ShippingCost and ShippingDurationDays are only honoured for admins; everyone else sends ShippingOptionID.
+type CreateOrderRequest struct {
+	ShippingOptionID     string                   `json:"shipping_option_id" validate:"omitempty,max=64"`
+	ShippingCost         float32                  `json:"shipping_cost"`
+	ShippingDurationDays int32                    `json:"shipping_duration_days"`
+	Discount             float32                  `json:"discount"`
+	AddressID            int64                    `json:"address_id" validate:"omitempty,gt=0,lte=2147483647"`
+	Items                []CreateOrderItemRequest `json:"items"`
+}
+
+type CreateOrderItemRequest struct {
+	ProductID int64 `json:"product_id" validate:"required,gt=0"`
+	Quantity  int32 `json:"quantity" validate:"required,gt=0"`
+}
+
+// ShippingQuoteRequest quotes delivery of Items to AddressID, or to the user's default address when it is omitted
+type ShippingQuoteRequest struct {
+	AddressID int64                    `json:"address_id" validate:"omitempty,gt=0,lte=2147483647"`
+	Items     []CreateOrderItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type AddOrderItemRequest struct {
+	// OrderID is only read by the deprecated POST /api/v1/orders/items/add, which has no ID in its path
+	OrderID   int64 `json:"order_id" validate:"required,gt=0"`
+	ProductID int64 `json:"product_id" validate:"required,gt=0"`
+	Quantity  int32 `json:"quantity" validate:"required,gt=0"`
+}
+
+type UpdateOrderItemQuantityRequest struct {
+	Quantity int32 `json:"quantity" validate:"required,gt=0"`
+}
+
+// CreateAPIKeyRequest names a key and the permissions it grants, e.g. ["product:write"]
+type CreateAPIKeyRequest struct {
+	Label  string   `json:"label" validate:"required,min=2,max=100" example:"catalog sync"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required" example:"product:write,category:write"`
+	// UserID is the account the key acts as; it defaults to the admin creating the key
+	UserID uint `json:"user_id,omitempty"`
+}
+
+type UpdateOrderStatusRequest struct {
+	// OrderID is only read by the deprecated PATCH /api/v1/orders/status, which has no ID in its path
+	OrderID int64  `json:"order_id" validate:"required,gt=0"`
+	Status  string `json:"status" validate:"required,oneof=pending paid shipped delivered canceled"`
+}
+
+// decodeRequest decodes a JSON body into dst with decodeJSON and validates it. Fields dst doesn't declare
+// are ignored unless strict decoding is on. The returned error is safe to show to the client.
+func decodeRequest(r *http.Request, dst any) error {
+	if err := decodeJSON(r, dst); err != nil {
+		return err
+	}
+	return validateRequest(dst)
+}
+
+// decodeRequestWithPathID is decodeRequest for bodies naming the resource they change. On routes such as
+// PUT /api/v1/products/:id the path names it instead, replacing any ID in the body.
+func decodeRequestWithPathID[T int32 | int64](r *http.Request, dst any, id *T) error {
+	if err := decodeJSON(r, dst); err != nil {
+		return err
+	}
+	if err := pathID(r, "id", id); err != nil {
+		return err
+	}
+	return validateRequest(dst)
+}
+
+// pathID sets *id from the path parameter name, on routes that have it; elsewhere *id keeps the value the
+// body gave it
+func pathID[T int32 | int64](r *http.Request, name string, id *T) error {
+	raw, ok := middleware.GetPathParam(r.Context(), name)
+	if !ok {
+		return nil
+	}
+	parsed, err := strconv.ParseInt(raw, 10, reflect.TypeFor[T]().Bits())
+	if err != nil || parsed <= 0 {
+		return fmt.Errorf("%s in the path must be a positive integer", name)
+	}
+	*id = T(parsed)
+	return nil
+}
+
+// resourceID is the ID a request names: the :id of routes such as GET /api/v1/products/:id, or the id query
+// parameter of their deprecated aliases
+func resourceID(r *http.Request) string {
+	if id, ok := middleware.GetPathParam(r.Context(), "id"); ok {
+		return id
+	}
+	return r.URL.Query().Get("id")
+}
+
+// validateRequest runs the validate tags of dst, reporting every failed field by its JSON name
+func validateRequest(dst any) error {
+	if err := validate.Struct(dst); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			messages := make([]string, 0, len(validationErrors))
+			for _, fieldErr := range validationErrors {
+				messages = append(messages, fmt.Sprintf("%s failed on %s", fieldErr.Field(), fieldErr.Tag()))
+			}
+			return errors.New(strings.Join(messages, "; "))
+		}
+		return err
+	}
+	return nil
+}
+
+func discountTypeToProto(discountType string) productpb.DiscountType {
+	switch discountType {
+	case "percent":
+		return productpb.DiscountType_DISCOUNT_PERCENT
+	case "fixed":
+		return productpb.DiscountType_DISCOUNT_FIXED
+	default:
+		return productpb.DiscountType_DISCOUNT_NONE
+	}
+}
@@ -1,18 +1,30 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
+// protoJSON keeps proto field names and emits zero values, so response shapes never depend on the data
+var protoJSON = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 	Code    int    `json:"code"`
+	// Field is the request body field a 400 is about, when it is about one
+	Field string `json:"field,omitempty"`
 }
 
 // writeJSONError writes a JSON error response
@@ -36,6 +48,132 @@ func writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
+// writeJSONPaginated writes a page of a list in the PaginatedResponse envelope every list endpoint returns
+func writeJSONPaginated(w http.ResponseWriter, statusCode int, data interface{}, meta PaginationMeta) {
+	writeJSON(w, statusCode, PaginatedResponse{Data: data, Pagination: meta})
+}
+
+// writeProtoJSON writes a proto message with protojson instead of encoding/json,
+// so int64s, enums and oneofs follow the canonical proto JSON mapping
+func writeProtoJSON(w http.ResponseWriter, statusCode int, msg proto.Message) {
+	body, err := protoJSON.Marshal(msg)
+	if err != nil {
+		logger.Errorf("failed to marshal %s: %v", msg.ProtoReflect().Descriptor().FullName(), err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
+
+// generateETag returns a strong ETag for a response body
+func generateETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// writeJSONWithETag writes a 200 JSON response tagged with its ETag, and with a Last-Modified date from clock
+// when there is one, or 304 Not Modified without a body when the copy the client holds is current, so clients
+// revalidate it instead of downloading it again. key names the representation for clock: the resource and
+// everything else the body varies by.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, body []byte, cacheControl string, clock *ModificationClock, key string) {
+	etag := generateETag(body)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+
+	var lastModified time.Time
+	if clock != nil {
+		lastModified = clock.Since(r.Context(), key, etag)
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// notModified evaluates the request's preconditions as RFC 9110 orders them: If-None-Match decides when it
+// is sent, and If-Modified-Since is only consulted without it
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return etagMatches(header, etag)
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(since)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag or is *. The comparison is weak, as
+// RFC 9110 requires for If-None-Match, so a W/ prefix added by a proxy does not defeat it.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// protoJSONValue lets encoding/json embed a proto message, e.g. inside PaginatedResponse, using protoJSON
+type protoJSONValue struct {
+	proto.Message
+}
+
+func (v protoJSONValue) MarshalJSON() ([]byte, error) {
+	if v.Message == nil || !v.ProtoReflect().IsValid() {
+		return []byte("null"), nil
+	}
+	return protoJSON.Marshal(v.Message)
+}
+
+// protoJSONFields is a protoJSONValue limited to the named top-level fields, for sparse responses.
+// protoJSON emits unpopulated fields, so fields cleared by a FieldMask must also be dropped here.
+type protoJSONFields struct {
+	proto.Message
+	fields []string
+}
+
+func (v protoJSONFields) MarshalJSON() ([]byte, error) {
+	body, err := protoJSONValue{v.Message}.MarshalJSON()
+	if err != nil || len(v.fields) == 0 || string(body) == "null" {
+		return body, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(body, &all); err != nil {
+		return nil, err
+	}
+	selected := make(map[string]json.RawMessage, len(v.fields))
+	for _, field := range v.fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		}
+	}
+	return json.Marshal(selected)
+}
+
+// protoJSONList wraps each message of a repeated field for encoding/json
+func protoJSONList[T proto.Message](msgs []T) []protoJSONValue {
+	values := make([]protoJSONValue, len(msgs))
+	for i, msg := range msgs {
+		values[i] = protoJSONValue{msg}
+	}
+	return values
+}
+
 func writeJSONErrorFromGRPC(w http.ResponseWriter, err error, defaultStatus int) {
 	st, ok := status.FromError(err)
 	if !ok {
@@ -53,7 +191,7 @@ func grpcCodeToHTTP(code codes.Code) int {
 		return http.StatusBadRequest
 	case codes.NotFound:
 		return http.StatusNotFound
-	case codes.AlreadyExists:
+	case codes.AlreadyExists, codes.FailedPrecondition:
 		return http.StatusConflict
 	case codes.PermissionDenied:
 		return http.StatusForbidden
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	reviewpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/review"
+)
+
+// ReviewHandler handles product review HTTP requests
+type ReviewHandler struct {
+	reviewClient reviewpb.ReviewServiceClient
+	orderClient  orderpb.OrderServiceClient
+}
+
+// NewReviewHandler creates a new review handler
+func NewReviewHandler(reviewClient reviewpb.ReviewServiceClient, orderClient orderpb.OrderServiceClient) *ReviewHandler {
+	return &ReviewHandler{
+		reviewClient: reviewClient,
+		orderClient:  orderClient,
+	}
+}
+
+// ReviewListResponse is a page of reviews plus the product's mean rating over all of them
+type ReviewListResponse struct {
+	PaginatedResponse
+	AverageRating float32 `json:"average_rating"`
+}
+
+// ListReviews godoc
+// @Summary List product reviews
+// @Description List a product's reviews, newest first
+// @Tags reviews
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page, capped at 100" default(10)
+// @Success 200 {object} ReviewListResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/products/{id}/reviews [get]
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || productID <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	page, perPage := parsePagination(c.Request)
+	resp, err := h.reviewClient.ListProductReviews(c.Request.Context(), &reviewpb.ListProductReviewsRequest{
+		ProductId: productID,
+		Page:      int32(page),
+		PerPage:   int32(perPage),
+	})
+	if err != nil {
+		logger.Errorf("failed to list reviews: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(c.Writer, http.StatusOK, ReviewListResponse{
+		PaginatedResponse: PaginatedResponse{Data: protoJSONList(resp.GetReviews()), Pagination: newPaginationMeta(c.Request, page, perPage, int(resp.GetTotalCount()))},
+		AverageRating:     resp.GetAverageRating(),
+	})
+}
+
+// CreateReview godoc
+// @Summary Review a product
+// @Description Rate a product from 1 to 5 with an optional comment. Only users with a paid, shipped or
+// @Description delivered order containing the product may review it, and only once.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param request body CreateReviewRequest true "Review"
+// @Success 201 {object} reviewpb.CreateReviewResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse "Product not purchased"
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Already reviewed"
+// @Router /api/v1/products/{id}/reviews [post]
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c.Request.Context())
+	if !ok {
+		writeJSONError(c.Writer, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || productID <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req CreateReviewRequest
+	if err := decodeRequest(c.Request, &req); err != nil {
+		writeRequestError(c.Writer, err)
+		return
+	}
+
+	purchase, err := h.orderClient.HasPurchasedProduct(c.Request.Context(), &orderpb.HasPurchasedProductRequest{
+		UserId:    int64(userID),
+		ProductId: productID,
+	})
+	if err != nil {
+		logger.Errorf("failed to check product purchase: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+	if !purchase.GetPurchased() {
+		writeJSONError(c.Writer, http.StatusForbidden, "only customers who bought this product can review it")
+		return
+	}
+
+	resp, err := h.reviewClient.CreateReview(c.Request.Context(), &reviewpb.CreateReviewRequest{
+		ProductId: productID,
+		UserId:    int64(userID),
+		Rating:    req.Rating,
+		Comment:   req.Comment,
+	})
+	if err != nil {
+		logger.Errorf("failed to create review: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(c.Writer, http.StatusCreated, resp)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+)
+
+var updateSnapshots = flag.Bool("update", false, "rewrite the response snapshots in testdata/snapshots")
+
+// snapshotOrder has every field set except shipping_address, so the snapshots also show how unset
+// messages come out
+func snapshotOrder(id int64) *orderpb.Order {
+	return &orderpb.Order{
+		Id:                   id,
+		UserId:               7,
+		ShippingCost:         4.5,
+		ShippingDurationDays: 3,
+		Discount:             2,
+		Total:                42.5,
+		Status:               "paid",
+		Items:                []*orderpb.OrderItem{{Id: 1, OrderId: id, ProductId: 9007199254740993, Quantity: 2, UnitPrice: 20, TotalPrice: 40}},
+		CreatedAt:            "2026-01-02T03:04:05Z",
+		UpdatedAt:            "2026-01-02T03:04:05Z",
+	}
+}
+
+// TestResponseSnapshots locks in the JSON of the main endpoints, so a proto change that alters a response
+// shows up here. Run with -update to accept the new responses after checking the diff.
+func TestResponseSnapshots(t *testing.T) {
+	users := &fakeUserClient{
+		getUserByID: func(in *userpb.GetUserByIDRequest) (*userpb.User, error) {
+			return &userpb.User{Id: in.GetId(), Name: "Mona", Email: "mona@example.com", Role: "customer"}, nil
+		},
+	}
+	orders := &fakeOrderClient{
+		getOrderByID: func(in *orderpb.GetOrderByIDRequest) (*orderpb.GetOrderByIDResponse, error) {
+			return &orderpb.GetOrderByIDResponse{Order: snapshotOrder(in.GetId())}, nil
+		},
+		listOrders: func(*orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+			return &orderpb.ListOrdersResponse{Orders: []*orderpb.Order{snapshotOrder(1), {Id: 2}}, TotalCount: 12}, nil
+		},
+	}
+	carts := &fakeCartClient{
+		getCart: func(in *cartpb.GetCartRequest) (*cartpb.CartResponse, error) {
+			return &cartpb.CartResponse{UserId: in.GetUserId(), Items: []*cartpb.CartItem{{ProductId: 3, Quantity: 2}}, TotalQuantity: 2}, nil
+		},
+	}
+	products := &fakeProductClient{
+		getProductByID: func(in *productpb.GetProductByIDRequest) (*productpb.GetProductByIDResponse, error) {
+			return &productpb.GetProductByIDResponse{Product: &productpb.Product{
+				Id: int32(in.GetId()), Name: "Lamp", ShortDescription: "Desk lamp", Price: 20, DiscountType: "percentage",
+				DiscountValue: 10, ImageUrl: "https://cdn.example.com/lamp.png", Quantity: 5, AverageRating: 4.5, ReviewCount: 2,
+			}}, nil
+		},
+		listCategories: func(*productpb.ListCategoriesRequest) (*productpb.ListCategoriesResponse, error) {
+			return &productpb.ListCategoriesResponse{Categories: []*productpb.Category{{Id: 1, Name: "Lighting"}}, TotalCount: 1}, nil
+		},
+	}
+	userHandler := NewUserHandler(users, orders, carts, nil, nil, nil, nil, nil)
+	orderHandler := NewOrderHandler(orders, nil, users)
+	cartHandler := NewCartHandler(carts, nil)
+	productHandler := NewProductHandler(products, nil, "", "", 100, nil)
+
+	tests := []struct {
+		name    string
+		route   string
+		target  string
+		handler gin.HandlerFunc
+	}{
+		{name: "get_user", route: "/api/v1/users/:id", target: "/api/v1/users/7", handler: userHandler.GetUserByID},
+		{name: "get_order", route: "/api/v1/orders/:id", target: "/api/v1/orders/1", handler: wrap(orderHandler.GetOrderByID)},
+		{name: "list_orders", route: "/api/v1/orders", target: "/api/v1/orders?page=2", handler: wrap(orderHandler.ListOrders)},
+		{name: "get_cart", route: "/api/v1/cart", target: "/api/v1/cart", handler: wrap(cartHandler.GetCart)},
+		{name: "get_product", route: "/api/v1/products/:id", target: "/api/v1/products/5", handler: wrap(productHandler.GetProductByID)},
+		{name: "list_categories", route: "/api/v1/categories", target: "/api/v1/categories", handler: wrap(productHandler.ListCategories)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(t, testRequest{method: http.MethodGet, route: tt.route, target: tt.target, userID: 7}, tt.handler)
+			if w.Code != http.StatusOK {
+				t.Fatalf("got %d %s, want 200", w.Code, w.Body)
+			}
+
+			// protojson varies its whitespace on purpose, so responses are compared indented
+			var got bytes.Buffer
+			if err := json.Indent(&got, bytes.TrimSpace(w.Body.Bytes()), "", "  "); err != nil {
+				t.Fatalf("response is not JSON: %v: %s", err, w.Body)
+			}
+			got.WriteByte('\n')
+
+			path := filepath.Join("testdata", "snapshots", tt.name+".json")
+			if *updateSnapshots {
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, got.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v; run the test with -update to create the snapshot", err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("response changed; if that is intended, run the test with -update.\ngot:\n%s\nwant:\n%s", got.Bytes(), want)
+			}
+		})
+	}
+}
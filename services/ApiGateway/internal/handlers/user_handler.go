@@ -1,45 +1,121 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/audit"
 	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
 	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	wishlistpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/wishlist"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+const (
+	exportOrdersPageSize = 100
+	eraseConfirmation    = "DELETE MY ACCOUNT"
+)
+
+// EraseMyDataRequest must repeat the confirmation phrase exactly
+type EraseMyDataRequest struct {
+	Confirm string `json:"confirm" example:"DELETE MY ACCOUNT"`
+}
+
+type erasureStep struct {
+	name string
+	run  func(ctx context.Context, userID uint) error
+}
+
+// UserDataExport is everything the platform stores about a user
+type UserDataExport struct {
+	ExportedAt string                       `json:"exported_at"`
+	User       *userpb.User                 `json:"user"`
+	Addresses  []*userpb.Address            `json:"addresses"`
+	Orders     []*orderpb.Order             `json:"orders"`
+	Cart       *cartpb.CartResponse         `json:"cart"`
+	Wishlist   *wishlistpb.WishlistResponse `json:"wishlist"`
+}
+
+// MarshalJSON encodes the proto parts with protoJSON, like every other response
+func (e UserDataExport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ExportedAt string           `json:"exported_at"`
+		User       protoJSONValue   `json:"user"`
+		Addresses  []protoJSONValue `json:"addresses"`
+		Orders     []protoJSONValue `json:"orders"`
+		Cart       protoJSONValue   `json:"cart"`
+		Wishlist   protoJSONValue   `json:"wishlist"`
+	}{
+		ExportedAt: e.ExportedAt,
+		User:       protoJSONValue{e.User},
+		Addresses:  protoJSONList(e.Addresses),
+		Orders:     protoJSONList(e.Orders),
+		Cart:       protoJSONValue{e.Cart},
+		Wishlist:   protoJSONValue{e.Wishlist},
+	})
+}
+
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
-	userClient userpb.UserServiceClient
+	userClient     userpb.UserServiceClient
+	orderClient    orderpb.OrderServiceClient
+	cartClient     cartpb.CartServiceClient
+	wishlistClient wishlistpb.WishlistServiceClient
+	revoker        *middleware.TokenRevoker
+	auditLog       audit.Sink
+	guestCarts     *middleware.GuestCarts
+	// clock dates profile responses for Last-Modified
+	clock *ModificationClock
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userClient userpb.UserServiceClient) *UserHandler {
+func NewUserHandler(
+	userClient userpb.UserServiceClient,
+	orderClient orderpb.OrderServiceClient,
+	cartClient cartpb.CartServiceClient,
+	wishlistClient wishlistpb.WishlistServiceClient,
+	revoker *middleware.TokenRevoker,
+	auditLog audit.Sink,
+	guestCarts *middleware.GuestCarts,
+	clock *ModificationClock,
+) *UserHandler {
 	return &UserHandler{
-		userClient: userClient,
+		userClient:     userClient,
+		orderClient:    orderClient,
+		cartClient:     cartClient,
+		wishlistClient: wishlistClient,
+		revoker:        revoker,
+		auditLog:       auditLog,
+		guestCarts:     guestCarts,
+		clock:          clock,
 	}
 }
 
 // Register godoc
 // @Summary Register a new user
-// @Description Create a new user account
+// @Description Create a new user account. A guest cart named by X-Guest-Cart-Token or the guest_cart cookie is merged into the new user's cart
 // @Tags users
 // @Accept json
 // @Produce json
-// @Param request body CreateUserRequest true "User registration details"
-// @Success 201 {object} CreateUserResponse
+// @Param request body RegisterRequest true "User registration details"
+// @Param X-Guest-Cart-Token header string false "Guest cart to merge, for clients that do not keep cookies"
+// @Success 201 {object} userpb.CreateUserResponse
 // @Failure 400 {object} ErrorResponse
-// @Router /api/v1/users/register [post]
-
+// @Router /api/v1/users [post]
+// @DeprecatedRouter /api/v1/users/register [post]
 func (h *UserHandler) Register(c *gin.Context) {
-	var req struct {
-		Name     string `json:"name"`
-		Email    string `json:"email"`
-		Password string `json:"password"`
-		Role     string `json:"role"`
-	}
+	var req RegisterRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		writeJSONError(c.Writer, http.StatusBadRequest, "invalid request body")
@@ -64,24 +140,25 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, resp)
+	h.mergeGuestCart(c.Writer, c.Request, resp.GetUser().GetId())
+	writeProtoJSON(c.Writer, http.StatusCreated, resp)
 }
 
 // Login godoc
 // @Summary User login
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return JWT token. Users with two-factor authentication enabled get two_factor_required and a challenge_token instead, to exchange with a code at /api/v1/users/2fa/verify. A guest cart named by X-Guest-Cart-Token or the guest_cart cookie is merged into the user's cart once they are signed in
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "Login credentials"
-// @Success 200 {object} LoginResponse
+// @Param X-Guest-Cart-Token header string false "Guest cart to merge, for clients that do not keep cookies"
+// @Success 200 {object} userpb.LoginResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Router /api/v1/users/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
-	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
+	var req LoginRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		writeJSONError(c.Writer, http.StatusBadRequest, "invalid request body")
@@ -94,12 +171,173 @@ func (h *UserHandler) Login(c *gin.Context) {
 	})
 
 	if err != nil {
+		if writeLoginThrottleError(c.Writer, err) {
+			return
+		}
 		logger.Errorf("login failed: %v", err)
 		writeJSONErrorFromGRPC(c.Writer, err, http.StatusUnauthorized)
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	// Users with two-factor authentication are only signed in by VerifyTwoFactor
+	if !resp.GetTwoFactorRequired() {
+		h.mergeGuestCart(c.Writer, c.Request, resp.GetUser().GetId())
+	}
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
+}
+
+// SetupTwoFactor godoc
+// @Summary Set up two-factor authentication
+// @Description Generate a TOTP secret for the authenticated user, as base32 and as an otpauth URL to show as a QR code. It replaces a secret not enabled yet; two-factor authentication stays off until /api/v1/users/2fa/enable
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} userpb.SetupTwoFactorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/users/2fa/setup [post]
+func (h *UserHandler) SetupTwoFactor(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c.Request.Context())
+	if !ok {
+		writeJSONError(c.Writer, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	resp, err := h.userClient.SetupTwoFactor(c.Request.Context(), &userpb.SetupTwoFactorRequest{
+		UserId: int32(userID),
+	})
+	if err != nil {
+		logger.Errorf("failed to set up two-factor authentication: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
+}
+
+// EnableTwoFactor godoc
+// @Summary Enable two-factor authentication
+// @Description Turn two-factor authentication on with a code from the secret set up, returning single-use recovery codes. They are only returned in this response
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body EnableTwoFactorRequest true "Code from the authenticator"
+// @Success 200 {object} userpb.EnableTwoFactorResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/users/2fa/enable [post]
+func (h *UserHandler) EnableTwoFactor(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c.Request.Context())
+	if !ok {
+		writeJSONError(c.Writer, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req EnableTwoFactorRequest
+	if err := decodeRequest(c.Request, &req); err != nil {
+		writeRequestError(c.Writer, err)
+		return
+	}
+
+	resp, err := h.userClient.EnableTwoFactor(c.Request.Context(), &userpb.EnableTwoFactorRequest{
+		UserId: int32(userID),
+		Code:   req.Code,
+	})
+	if err != nil {
+		logger.Errorf("failed to enable two-factor authentication: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	err = h.auditLog.Record(audit.Entry{
+		Event:   "two_factor_enabled",
+		UserID:  userID,
+		IP:      middleware.GetClientIP(c.Request.Context()),
+		Outcome: "success",
+	})
+	if err != nil {
+		logger.Errorf("event=audit_write_failed user_id=%d outcome=success error=%v", userID, err)
+	}
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
+}
+
+// VerifyTwoFactor godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the challenge_token of a login that answered two_factor_required, within 5 minutes, and a code from the authenticator or a recovery code for a JWT token. A guest cart named by X-Guest-Cart-Token or the guest_cart cookie is merged into the user's cart
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body VerifyTwoFactorRequest true "Challenge token and code"
+// @Param X-Guest-Cart-Token header string false "Guest cart to merge, for clients that do not keep cookies"
+// @Success 200 {object} userpb.LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /api/v1/users/2fa/verify [post]
+func (h *UserHandler) VerifyTwoFactor(c *gin.Context) {
+	var req VerifyTwoFactorRequest
+	if err := decodeRequest(c.Request, &req); err != nil {
+		writeRequestError(c.Writer, err)
+		return
+	}
+
+	resp, err := h.userClient.VerifyTwoFactor(c.Request.Context(), &userpb.VerifyTwoFactorRequest{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+	})
+	if err != nil {
+		// Wrong codes count as failed logins, so they are throttled and locked like wrong passwords
+		if writeLoginThrottleError(c.Writer, err) {
+			return
+		}
+		logger.Errorf("two-factor verification failed: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.mergeGuestCart(c.Writer, c.Request, resp.GetUser().GetId())
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
+}
+
+// mergeGuestCart moves the cart the visitor built before signing in into the user's and deletes its cookie.
+// A failed merge does not fail the sign-in: the guest cart and its cookie are kept for the next one.
+func (h *UserHandler) mergeGuestCart(w http.ResponseWriter, r *http.Request, userID int32) {
+	guestCartID, ok := h.guestCarts.CartID(r)
+	if !ok || userID <= 0 {
+		return
+	}
+
+	_, err := h.cartClient.MergeCarts(r.Context(), &cartpb.MergeCartsRequest{
+		UserId:     int64(userID),
+		GuestToken: guestCartID,
+	})
+	if err != nil {
+		logger.Errorf("event=guest_cart_merge_failed user_id=%d error=%v", userID, err)
+		return
+	}
+	h.guestCarts.Clear(w)
+}
+
+// writeLoginThrottleError answers 429 while failed logins make the next ones wait and 423 while too many have
+// locked the account for a while, and reports whether err was one of those. On login routes
+// FailedPrecondition means the account is locked, so it gets 423 here rather than the usual 409.
+func writeLoginThrottleError(w http.ResponseWriter, err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	switch st.Code() {
+	case codes.Aborted:
+		writeJSONError(w, http.StatusTooManyRequests, st.Message())
+		return true
+	case codes.FailedPrecondition:
+		writeJSONError(w, http.StatusLocked, st.Message())
+		return true
+	}
+	return false
 }
 
 // GetProfile godoc
@@ -108,9 +346,14 @@ func (h *UserHandler) Login(c *gin.Context) {
 // @Tags users
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} User
+// @Param If-None-Match header string false "ETag of a copy already held"
+// @Param If-Modified-Since header string false "Last-Modified of a copy already held; ignored when If-None-Match is sent"
+// @Success 200 {object} userpb.User
+// @Header 200 {string} Last-Modified "When the gateway first served this body"
+// @Success 304 "The copy held is current"
 // @Failure 401 {object} ErrorResponse
-// @Router /api/v1/users/profile [get]
+// @Router /api/v1/users/me [get]
+// @DeprecatedRouter /api/v1/users/profile [get]
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c.Request.Context())
 	if !ok {
@@ -128,7 +371,14 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	body, err := protoJSON.Marshal(resp)
+	if err != nil {
+		logger.Errorf("failed to marshal user: %v", err)
+		writeJSONError(c.Writer, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+	// private keeps shared caches from handing one user's profile to another
+	writeJSONWithETag(c.Writer, c.Request, body, "private, no-cache", h.clock, fmt.Sprintf("user:%d", userID))
 }
 
 // GetUserByID godoc
@@ -137,12 +387,14 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 // @Tags users
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path int true "User ID"
-// @Success 200 {object} User
+// @Success 200 {object} userpb.User
 // @Failure 404 {object} ErrorResponse
 // @Router /api/v1/users/{id} [get]
+// @DeprecatedRouter /api/v1/users/by-id [get]
 func (h *UserHandler) GetUserByID(c *gin.Context) {
-	idStr := c.Query("id")
+	idStr := resourceID(c.Request)
 	if idStr == "" {
 		writeJSONError(c.Writer, http.StatusBadRequest, "missing user ID")
 		return
@@ -165,37 +417,38 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
 }
 
 // SearchUsers godoc
 // @Summary Search users
-// @Description Search users with pagination (admin only)
+// @Description Search users with pagination, optionally only those holding a role, whether as their primary role or a granted one (admin only)
 // @Tags users
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param query query string false "Case-insensitive match on name or email"
+// @Param role query string false "customer or admin"
+// @Param sort_by query string false "id or created_at" default(id)
+// @Param sort_order query string false "asc or desc" default(asc)
 // @Param page query int false "Page number" default(1)
-// @Param per_page query int false "Items per page" default(10)
-// @Success 200 {object} SearchUsersResponse
+// @Param per_page query int false "Items per page, capped at 100" default(10)
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse
 // @Router /api/v1/users [get]
+// @DeprecatedRouter /api/v1/users/search [get]
 func (h *UserHandler) SearchUsers(c *gin.Context) {
-	page, _ := strconv.Atoi(c.Query("page"))
-	if page < 1 {
-		page = 1
-	}
+	page, perPage := parsePagination(c.Request)
 
-	perPage, _ := strconv.Atoi(c.Query("per_page"))
-	if perPage < 1 || perPage > 100 {
-		perPage = 10
+	req, err := parseSearchUsersQuery(c.Request.URL.Query())
+	if err != nil {
+		writeJSONError(c.Writer, http.StatusBadRequest, err.Error())
+		return
 	}
+	req.PageNumber = int32(page)
+	req.PageSize = int32(perPage)
 
-	query := c.Query("query")
-
-	resp, err := h.userClient.SearchUsers(c.Request.Context(), &userpb.SearchUsersRequest{
-		Query:      query,
-		PageNumber: int32(page),
-		PageSize:   int32(perPage),
-	})
+	resp, err := h.userClient.SearchUsers(c.Request.Context(), req)
 
 	if err != nil {
 		logger.Errorf("failed to search users: %v", err)
@@ -203,7 +456,34 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	writeJSONPaginated(c.Writer, http.StatusOK, protoJSONList(resp.GetUsers()), newPaginationMeta(c.Request, page, perPage, int(resp.GetTotal())))
+}
+
+func parseSearchUsersQuery(query url.Values) (*userpb.SearchUsersRequest, error) {
+	req := &userpb.SearchUsersRequest{
+		Query:     query.Get("query"),
+		Role:      query.Get("role"),
+		SortBy:    query.Get("sort_by"),
+		SortOrder: query.Get("sort_order"),
+	}
+
+	switch req.Role {
+	case "", "customer", "admin":
+	default:
+		return nil, fmt.Errorf("role must be customer or admin")
+	}
+	switch req.SortBy {
+	case "", "id", "created_at":
+	default:
+		return nil, fmt.Errorf("sort_by must be id or created_at")
+	}
+	switch req.SortOrder {
+	case "", "asc", "desc":
+	default:
+		return nil, fmt.Errorf("sort_order must be asc or desc")
+	}
+
+	return req, nil
 }
 
 // UpdateUser godoc
@@ -214,8 +494,9 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param request body UpdateUserRequest true "User update details"
-// @Success 200 {object} User
-// @Router /api/v1/users [put]
+// @Success 200 {object} userpb.User
+// @Router /api/v1/users/me [put]
+// @DeprecatedRouter /api/v1/users/update [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c.Request.Context())
 	if !ok {
@@ -223,10 +504,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Name  string `json:"name"`
-		Email string `json:"email"`
-	}
+	var req UpdateUserRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		writeJSONError(c.Writer, http.StatusBadRequest, "invalid request body")
@@ -245,7 +523,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
 }
 
 // DeleteUser godoc
@@ -253,11 +531,13 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 // @Description Delete user account (admin only)
 // @Tags users
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path int true "User ID"
-// @Success 200 {object} DeleteUserResponse
+// @Success 200 {object} userpb.DeleteUserResponse
 // @Router /api/v1/users/{id} [delete]
+// @DeprecatedRouter /api/v1/users/delete [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
-	idStr := c.Query("id")
+	idStr := resourceID(c.Request)
 	if idStr == "" {
 		writeJSONError(c.Writer, http.StatusBadRequest, "missing user ID")
 		return
@@ -279,11 +559,102 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
 }
 
 // Address handlers
 
+// UnlockUser godoc
+// @Summary Unlock a user
+// @Description Clear the failed logins counted against a user, lifting a lockout or login backoff before it runs out (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} userpb.UnlockUserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	adminID, ok := middleware.GetUserID(c.Request.Context())
+	if !ok {
+		writeJSONError(c.Writer, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	resp, err := h.userClient.UnlockUser(c.Request.Context(), &userpb.UnlockUserRequest{Id: int32(id)})
+	if err != nil {
+		logger.Errorf("failed to unlock user: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	err = h.auditLog.Record(audit.Entry{
+		Event:   "user_unlocked",
+		UserID:  adminID,
+		IP:      middleware.GetClientIP(c.Request.Context()),
+		Outcome: "success",
+		Details: map[string]any{"unlocked_user_id": id},
+	})
+	if err != nil {
+		logger.Errorf("event=audit_write_failed user_id=%d outcome=success error=%v", adminID, err)
+	}
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
+}
+
+// ImpersonateUser godoc
+// @Summary Impersonate a user
+// @Description Issue a token that acts as the user for 15 minutes, for support staff to see their cart and orders as they do (admin only).
+// @Description The token carries the admin as impersonated_by, every request made with it is written to the audit log under the admin,
+// @Description and it is refused on account settings such as profile updates, two-factor setup, data export and account deletion. Admins cannot be impersonated.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param userId path int true "User ID"
+// @Success 200 {object} userpb.ImpersonateUserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/admin/impersonate/{userId} [post]
+func (h *UserHandler) ImpersonateUser(c *gin.Context) {
+	adminID, ok := middleware.GetUserID(c.Request.Context())
+	if !ok {
+		writeJSONError(c.Writer, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("userId"), 10, 32)
+	if err != nil || id <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	resp, err := h.userClient.ImpersonateUser(c.Request.Context(), &userpb.ImpersonateUserRequest{UserId: int32(id)})
+	if err != nil {
+		logger.Errorf("failed to impersonate user: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	err = h.auditLog.Record(audit.Entry{
+		Event:   "impersonation_started",
+		UserID:  adminID,
+		IP:      middleware.GetClientIP(c.Request.Context()),
+		Outcome: "success",
+		Details: map[string]any{"impersonated_user_id": id, "expires_at": resp.GetExpiresAt()},
+	})
+	if err != nil {
+		logger.Errorf("event=audit_write_failed user_id=%d outcome=success error=%v", adminID, err)
+	}
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
+}
+
 // CreateAddress godoc
 // @Summary Create address
 // @Description Create a new address for authenticated user
@@ -292,8 +663,9 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param request body CreateAddressRequest true "Address details"
-// @Success 201 {object} CreateAddressResponse
+// @Success 201 {object} userpb.CreateAddressResponse
 // @Router /api/v1/addresses [post]
+// @DeprecatedRouter /api/v1/addresses/create [post]
 func (h *UserHandler) CreateAddress(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c.Request.Context())
 	if !ok {
@@ -301,22 +673,29 @@ func (h *UserHandler) CreateAddress(c *gin.Context) {
 		return
 	}
 
-	var req userpb.CreateAddressRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		writeJSONError(c.Writer, http.StatusBadRequest, "invalid request body")
+	var req CreateAddressRequest
+	if err := decodeRequest(c.Request, &req); err != nil {
+		writeRequestError(c.Writer, err)
 		return
 	}
 
-	req.UserId = int32(userID)
-
-	resp, err := h.userClient.CreateAddress(c.Request.Context(), &req)
+	resp, err := h.userClient.CreateAddress(c.Request.Context(), &userpb.CreateAddressRequest{
+		UserId:    int32(userID),
+		Country:   req.Country,
+		City:      req.City,
+		State:     req.State,
+		Street:    req.Street,
+		ZipCode:   req.PostalCode,
+		Label:     req.Label,
+		IsDefault: req.IsDefault,
+	})
 	if err != nil {
 		logger.Errorf("failed to create address: %v", err)
 		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
 		return
 	}
 
-	c.JSON(http.StatusCreated, resp)
+	writeProtoJSON(c.Writer, http.StatusCreated, resp)
 }
 
 // ListAddresses godoc
@@ -325,8 +704,9 @@ func (h *UserHandler) CreateAddress(c *gin.Context) {
 // @Tags addresses
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} ListAddressesByUserIDResponse
+// @Success 200 {object} userpb.ListAddressesByUserIDResponse
 // @Router /api/v1/addresses [get]
+// @DeprecatedRouter /api/v1/addresses/list [get]
 func (h *UserHandler) ListAddresses(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c.Request.Context())
 	if !ok {
@@ -344,7 +724,7 @@ func (h *UserHandler) ListAddresses(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
 }
 
 // UpdateAddress godoc
@@ -354,24 +734,56 @@ func (h *UserHandler) ListAddresses(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param id path int true "Address ID"
 // @Param request body UpdateAddressRequest true "Address update details"
-// @Success 200 {object} UpdateAddressResponse
+// @Success 200 {object} userpb.UpdateAddressResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /api/v1/addresses/{id} [put]
+// @DeprecatedRouter /api/v1/addresses/update [put]
 func (h *UserHandler) UpdateAddress(c *gin.Context) {
-	var req userpb.UpdateAddressRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		writeJSONError(c.Writer, http.StatusBadRequest, "invalid request body")
+	userID, ok := middleware.GetUserID(c.Request.Context())
+	if !ok {
+		writeJSONError(c.Writer, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req UpdateAddressRequest
+	if err := decodeRequestWithPathID(c.Request, &req, &req.ID); err != nil {
+		writeRequestError(c.Writer, err)
+		return
+	}
+
+	// Refuse other users' addresses here, as DeleteAddress does; the user service checks the owner again
+	address, err := h.userClient.GetAddressByID(c.Request.Context(), &userpb.GetAddressByIDRequest{
+		Id: req.ID,
+	})
+	if err != nil {
+		logger.Errorf("failed to get address: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+	if address.GetAddress().GetUserId() != int32(userID) {
+		writeJSONError(c.Writer, http.StatusForbidden, "address does not belong to user")
 		return
 	}
 
-	resp, err := h.userClient.UpdateAddress(c.Request.Context(), &req)
+	resp, err := h.userClient.UpdateAddress(c.Request.Context(), &userpb.UpdateAddressRequest{
+		Id:               req.ID,
+		RequestingUserId: int32(userID),
+		Country:          req.Country,
+		City:             req.City,
+		State:            req.State,
+		Street:           req.Street,
+		ZipCode:          req.PostalCode,
+		Label:            req.Label,
+	})
 	if err != nil {
 		logger.Errorf("failed to update address: %v", err)
 		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
 }
 
 // DeleteAddress godoc
@@ -380,10 +792,11 @@ func (h *UserHandler) UpdateAddress(c *gin.Context) {
 // @Tags addresses
 // @Security BearerAuth
 // @Param id path int true "Address ID"
-// @Success 200 {object} DeleteAddressResponse
+// @Success 200 {object} userpb.DeleteAddressResponse
 // @Router /api/v1/addresses/{id} [delete]
+// @DeprecatedRouter /api/v1/addresses/delete [delete]
 func (h *UserHandler) DeleteAddress(c *gin.Context) {
-	idStr := c.Query("id")
+	idStr := resourceID(c.Request)
 	if idStr == "" {
 		writeJSONError(c.Writer, http.StatusBadRequest, "missing address ID")
 		return
@@ -419,5 +832,266 @@ func (h *UserHandler) DeleteAddress(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
+}
+
+// SetDefaultAddress godoc
+// @Summary Set default address
+// @Description Mark one of the authenticated user's addresses as the default, replacing the previous one
+// @Tags addresses
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Address ID"
+// @Success 200 {object} userpb.SetDefaultAddressResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/addresses/{id}/default [put]
+func (h *UserHandler) SetDefaultAddress(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c.Request.Context())
+	if !ok {
+		writeJSONError(c.Writer, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		writeJSONError(c.Writer, http.StatusBadRequest, "invalid address ID")
+		return
+	}
+
+	// The user service only matches addresses owned by user_id, so foreign addresses are reported as not found
+	resp, err := h.userClient.SetDefaultAddress(c.Request.Context(), &userpb.SetDefaultAddressRequest{
+		Id:     int32(id),
+		UserId: int32(userID),
+	})
+	if err != nil {
+		logger.Errorf("failed to set default address: %v", err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(c.Writer, http.StatusOK, resp)
+}
+
+// ExportMyData godoc
+// @Summary Export my data
+// @Description Download all data held about the authenticated user as a JSON file (1 successful export per hour)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UserDataExport
+// @Failure 429 {object} ErrorResponse
+// @Router /api/v1/users/me/export [get]
+func (h *UserHandler) ExportMyData(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c.Request.Context())
+	if !ok {
+		writeJSONError(c.Writer, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	export := UserDataExport{
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Addresses:  []*userpb.Address{},
+		Orders:     []*orderpb.Order{},
+	}
+
+	g, ctx := errgroup.WithContext(c.Request.Context())
+
+	g.Go(func() error {
+		user, err := h.userClient.GetUserByID(ctx, &userpb.GetUserByIDRequest{Id: int32(userID)})
+		if err != nil {
+			return fmt.Errorf("user: %w", err)
+		}
+		export.User = user
+		return nil
+	})
+
+	g.Go(func() error {
+		resp, err := h.userClient.ListAddressesByUserID(ctx, &userpb.ListAddressesByUserIDRequest{UserId: int32(userID)})
+		if err != nil {
+			return fmt.Errorf("addresses: %w", err)
+		}
+		if resp.GetAddresses() != nil {
+			export.Addresses = resp.GetAddresses()
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		orders, err := h.listAllOrders(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("orders: %w", err)
+		}
+		export.Orders = orders
+		return nil
+	})
+
+	g.Go(func() error {
+		cart, err := h.cartClient.GetCart(ctx, &cartpb.GetCartRequest{UserId: int64(userID)})
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return nil
+			}
+			return fmt.Errorf("cart: %w", err)
+		}
+		export.Cart = cart
+		return nil
+	})
+
+	g.Go(func() error {
+		wishlist, err := h.wishlistClient.GetWishlist(ctx, &wishlistpb.GetWishlistRequest{UserId: int64(userID)})
+		if err != nil {
+			return fmt.Errorf("wishlist: %w", err)
+		}
+		export.Wishlist = wishlist
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		logger.Errorf("failed to export user data: user_id=%d error=%v", userID, err)
+		writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="export-%d.json"`, userID))
+	c.JSON(http.StatusOK, export)
+}
+
+func (h *UserHandler) listAllOrders(ctx context.Context, userID uint) ([]*orderpb.Order, error) {
+	orders := make([]*orderpb.Order, 0)
+	for page := int32(1); ; page++ {
+		resp, err := h.orderClient.ListOrders(ctx, &orderpb.ListOrdersRequest{
+			Page:    page,
+			PerPage: exportOrdersPageSize,
+			UserId:  int64(userID),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, resp.GetOrders()...)
+		if len(resp.GetOrders()) < exportOrdersPageSize || len(orders) >= int(resp.GetTotalCount()) {
+			return orders, nil
+		}
+	}
+}
+
+// EraseMyData godoc
+// @Summary Erase my account
+// @Description Permanently delete the authenticated user's account, addresses, cart and wishlist and anonymise their orders
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body EraseMyDataRequest true "Confirmation, must be \"DELETE MY ACCOUNT\""
+// @Success 200 {object} map[string]bool
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/users/me [delete]
+func (h *UserHandler) EraseMyData(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c.Request.Context())
+	if !ok {
+		writeJSONError(c.Writer, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req EraseMyDataRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Confirm != eraseConfirmation {
+		writeJSONError(c.Writer, http.StatusBadRequest, fmt.Sprintf(`confirm must be "%s"`, eraseConfirmation))
+		return
+	}
+
+	// Orders are anonymised first because it is the only step that keeps data around. The user record is
+	// deleted after the data that hangs off it and the tokens are revoked last, so a failed erasure can
+	// simply be retried with the same token; on that retry deleteUser finds the user gone and moves on.
+	steps := []erasureStep{
+		{name: "anonymise_orders", run: h.anonymiseOrders},
+		{name: "clear_cart", run: h.clearCart},
+		{name: "clear_wishlist", run: h.clearWishlist},
+		{name: "delete_addresses", run: h.deleteAddresses},
+		{name: "delete_user", run: h.deleteUser},
+		{name: "revoke_tokens", run: h.revoker.RevokeUser},
+	}
+
+	ctx := c.Request.Context()
+	completed := make([]string, 0, len(steps))
+	for _, step := range steps {
+		if err := step.run(ctx, userID); err != nil {
+			logger.Errorf("event=erasure_failed user_id=%d step=%s completed=%v error=%v", userID, step.name, completed, err)
+			h.recordErasure(ctx, userID, "partial", map[string]any{
+				"failed_step": step.name,
+				"completed":   completed,
+				"error":       err.Error(),
+			})
+			writeJSONErrorFromGRPC(c.Writer, err, http.StatusInternalServerError)
+			return
+		}
+		completed = append(completed, step.name)
+	}
+
+	h.recordErasure(ctx, userID, "completed", map[string]any{"completed": completed})
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *UserHandler) anonymiseOrders(ctx context.Context, userID uint) error {
+	_, err := h.orderClient.AnonymiseUserOrders(ctx, &orderpb.AnonymiseUserOrdersRequest{UserId: int64(userID)})
+	return err
+}
+
+func (h *UserHandler) clearCart(ctx context.Context, userID uint) error {
+	_, err := h.cartClient.ClearCart(ctx, &cartpb.ClearCartRequest{UserId: int64(userID)})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+func (h *UserHandler) clearWishlist(ctx context.Context, userID uint) error {
+	resp, err := h.wishlistClient.GetWishlist(ctx, &wishlistpb.GetWishlistRequest{UserId: int64(userID)})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range resp.GetItems() {
+		_, err := h.wishlistClient.RemoveItem(ctx, &wishlistpb.RemoveWishlistItemRequest{UserId: int64(userID), ProductId: item.GetProductId()})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *UserHandler) deleteAddresses(ctx context.Context, userID uint) error {
+	resp, err := h.userClient.ListAddressesByUserID(ctx, &userpb.ListAddressesByUserIDRequest{UserId: int32(userID)})
+	if err != nil {
+		return err
+	}
+
+	for _, address := range resp.GetAddresses() {
+		_, err := h.userClient.DeleteAddress(ctx, &userpb.DeleteAddressRequest{Id: address.GetId()})
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *UserHandler) deleteUser(ctx context.Context, userID uint) error {
+	_, err := h.userClient.DeleteUser(ctx, &userpb.DeleteUserRequest{Id: int32(userID)})
+	// A retry after a failed token revocation finds the user already gone
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+func (h *UserHandler) recordErasure(ctx context.Context, userID uint, outcome string, details map[string]any) {
+	err := h.auditLog.Record(audit.Entry{
+		Event:   "user_erasure",
+		UserID:  userID,
+		IP:      middleware.GetClientIP(ctx),
+		Outcome: outcome,
+		Details: details,
+	})
+	if err != nil {
+		logger.Errorf("event=audit_write_failed user_id=%d outcome=%s error=%v", userID, outcome, err)
+	}
 }
@@ -0,0 +1,480 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
+	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	wishlistpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/wishlist"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func addressOwnedBy(userID int32) func(*userpb.GetAddressByIDRequest) (*userpb.GetAddressByIDResponse, error) {
+	return func(in *userpb.GetAddressByIDRequest) (*userpb.GetAddressByIDResponse, error) {
+		return &userpb.GetAddressByIDResponse{Address: &userpb.Address{Id: in.Id, UserId: userID}}, nil
+	}
+}
+
+func TestUpdateAddressOwnership(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     uint
+		lookup     func(*userpb.GetAddressByIDRequest) (*userpb.GetAddressByIDResponse, error)
+		wantStatus int
+		wantUpdate bool
+	}{
+		{name: "owner", userID: 7, lookup: addressOwnedBy(7), wantStatus: http.StatusOK, wantUpdate: true},
+		{name: "other user", userID: 8, lookup: addressOwnedBy(7), wantStatus: http.StatusForbidden},
+		{name: "anonymous", wantStatus: http.StatusUnauthorized},
+		{
+			name:   "missing address",
+			userID: 7,
+			lookup: func(*userpb.GetAddressByIDRequest) (*userpb.GetAddressByIDResponse, error) {
+				return nil, status.Error(codes.NotFound, "address not found")
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated *userpb.UpdateAddressRequest
+			users := &fakeUserClient{
+				getAddressByID: tt.lookup,
+				updateAddress: func(in *userpb.UpdateAddressRequest) (*userpb.UpdateAddressResponse, error) {
+					updated = in
+					return &userpb.UpdateAddressResponse{Address: &userpb.Address{Id: in.Id}}, nil
+				},
+			}
+			h := NewUserHandler(users, nil, nil, nil, nil, nil, nil, nil)
+
+			w := serve(t, testRequest{
+				method: http.MethodPut,
+				route:  "/api/v1/addresses/:id",
+				target: "/api/v1/addresses/42",
+				body:   `{"city":"Cairo","user_id":99}`,
+				userID: tt.userID,
+			}, h.UpdateAddress)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if got := updated != nil; got != tt.wantUpdate {
+				t.Fatalf("update forwarded = %v, want %v", got, tt.wantUpdate)
+			}
+			if updated != nil && (updated.Id != 42 || updated.RequestingUserId != int32(tt.userID)) {
+				t.Errorf("forwarded id %d for user %d, want 42 for user %d", updated.Id, updated.RequestingUserId, tt.userID)
+			}
+		})
+	}
+}
+
+// exportHandler returns a handler whose downstream services all hold data for user 7, with orders spread over
+// two pages
+func exportHandler() (*UserHandler, *fakeWishlistClient) {
+	users := &fakeUserClient{
+		getUserByID: func(in *userpb.GetUserByIDRequest) (*userpb.User, error) {
+			return &userpb.User{Id: in.Id, Email: "user@example.com"}, nil
+		},
+		listAddresses: func(in *userpb.ListAddressesByUserIDRequest) (*userpb.ListAddressesByUserIDResponse, error) {
+			return &userpb.ListAddressesByUserIDResponse{Addresses: []*userpb.Address{{Id: 1, UserId: in.UserId}}}, nil
+		},
+	}
+	orders := &fakeOrderClient{
+		listOrders: func(in *orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+			const total = exportOrdersPageSize + 1
+			count := exportOrdersPageSize
+			if in.Page == 2 {
+				count = total - exportOrdersPageSize
+			}
+			page := make([]*orderpb.Order, count)
+			for i := range page {
+				page[i] = &orderpb.Order{Id: int64(i + 1), UserId: in.UserId}
+			}
+			return &orderpb.ListOrdersResponse{Orders: page, TotalCount: total}, nil
+		},
+	}
+	carts := &fakeCartClient{
+		getCart: func(in *cartpb.GetCartRequest) (*cartpb.CartResponse, error) {
+			return &cartpb.CartResponse{UserId: in.UserId, TotalQuantity: 2}, nil
+		},
+	}
+	wishlists := &fakeWishlistClient{
+		getWishlist: func(in *wishlistpb.GetWishlistRequest) (*wishlistpb.WishlistResponse, error) {
+			return &wishlistpb.WishlistResponse{UserId: in.UserId, Items: []*wishlistpb.WishlistItem{{ProductId: 3}}}, nil
+		},
+	}
+	return NewUserHandler(users, orders, carts, wishlists, nil, nil, nil, nil), wishlists
+}
+
+var exportRequest = testRequest{method: http.MethodGet, route: "/api/v1/users/me/export", target: "/api/v1/users/me/export", userID: 7}
+
+func TestExportMyDataHasEverySection(t *testing.T) {
+	h, _ := exportHandler()
+
+	w := serve(t, exportRequest, h.ExportMyData)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="export-7.json"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	var export struct {
+		ExportedAt string            `json:"exported_at"`
+		User       map[string]any    `json:"user"`
+		Addresses  []json.RawMessage `json:"addresses"`
+		Orders     []json.RawMessage `json:"orders"`
+		Cart       map[string]any    `json:"cart"`
+		Wishlist   struct {
+			Items []json.RawMessage `json:"items"`
+		} `json:"wishlist"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &export); err != nil {
+		t.Fatal(err)
+	}
+	if export.ExportedAt == "" || export.User["email"] != "user@example.com" || export.Cart == nil {
+		t.Errorf("export = %s, want the export time, user and cart", w.Body)
+	}
+	if len(export.Addresses) != 1 || len(export.Orders) != exportOrdersPageSize+1 || len(export.Wishlist.Items) != 1 {
+		t.Errorf("got %d addresses, %d orders and %d wishlist items, want 1, %d and 1",
+			len(export.Addresses), len(export.Orders), len(export.Wishlist.Items), exportOrdersPageSize+1)
+	}
+}
+
+func TestExportMyDataFailsWhenAServiceFails(t *testing.T) {
+	h, wishlists := exportHandler()
+	wishlists.getWishlist = func(*wishlistpb.GetWishlistRequest) (*wishlistpb.WishlistResponse, error) {
+		return nil, status.Error(codes.Unavailable, "wishlist service down")
+	}
+
+	if w := serve(t, exportRequest, h.ExportMyData); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503: %s", w.Code, w.Body)
+	}
+}
+
+func TestExportMyDataRateLimit(t *testing.T) {
+	h, wishlists := exportHandler()
+	limiter := middleware.NewRateLimiter(1, time.Hour)
+	export := newTestEngine(exportRequest.method, exportRequest.route, limiter.PerUserSuccessMiddleware(), h.ExportMyData)
+
+	// A failed export does not use up the hour's export
+	working := wishlists.getWishlist
+	wishlists.getWishlist = func(*wishlistpb.GetWishlistRequest) (*wishlistpb.WishlistResponse, error) {
+		return nil, status.Error(codes.Unavailable, "wishlist service down")
+	}
+	if w := export(t, exportRequest); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("failed export: status = %d, want 503", w.Code)
+	}
+	wishlists.getWishlist = working
+
+	if w := export(t, exportRequest); w.Code != http.StatusOK {
+		t.Fatalf("first export: status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if w := export(t, exportRequest); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second export: status = %d, want 429", w.Code)
+	}
+
+	other := exportRequest
+	other.userID = 8
+	if w := export(t, other); w.Code != http.StatusOK {
+		t.Fatalf("another user's export: status = %d, want 200", w.Code)
+	}
+}
+
+// erasure fakes every service EraseMyData calls for user 7 and records the order the calls arrive in
+type erasure struct {
+	users     *fakeUserClient
+	orders    *fakeOrderClient
+	carts     *fakeCartClient
+	wishlists *fakeWishlistClient
+	revoker   *middleware.TokenRevoker
+	audit     *auditRecorder
+	calls     []string
+}
+
+func newErasure() *erasure {
+	e := &erasure{revoker: middleware.NewTokenRevoker(nil, time.Hour), audit: &auditRecorder{}}
+	e.users = &fakeUserClient{
+		listAddresses: func(*userpb.ListAddressesByUserIDRequest) (*userpb.ListAddressesByUserIDResponse, error) {
+			return &userpb.ListAddressesByUserIDResponse{Addresses: []*userpb.Address{{Id: 1}, {Id: 2}}}, nil
+		},
+		deleteAddress: func(in *userpb.DeleteAddressRequest) (*userpb.DeleteAddressResponse, error) {
+			e.calls = append(e.calls, "delete_address")
+			return &userpb.DeleteAddressResponse{}, nil
+		},
+		deleteUser: func(*userpb.DeleteUserRequest) (*userpb.DeleteUserResponse, error) {
+			e.calls = append(e.calls, "delete_user")
+			return &userpb.DeleteUserResponse{}, nil
+		},
+	}
+	e.orders = &fakeOrderClient{
+		anonymiseUserOrders: func(*orderpb.AnonymiseUserOrdersRequest) (*orderpb.AnonymiseUserOrdersResponse, error) {
+			e.calls = append(e.calls, "anonymise_orders")
+			return &orderpb.AnonymiseUserOrdersResponse{}, nil
+		},
+	}
+	e.carts = &fakeCartClient{
+		clearCart: func(*cartpb.ClearCartRequest) (*cartpb.ClearCartResponse, error) {
+			e.calls = append(e.calls, "clear_cart")
+			return &cartpb.ClearCartResponse{}, nil
+		},
+	}
+	e.wishlists, _ = wishlistOf(7, 3)
+	removeItem := e.wishlists.removeItem
+	e.wishlists.removeItem = func(in *wishlistpb.RemoveWishlistItemRequest) (*wishlistpb.WishlistResponse, error) {
+		e.calls = append(e.calls, "remove_wishlist_item")
+		return removeItem(in)
+	}
+	return e
+}
+
+func (e *erasure) erase(t *testing.T) *httptest.ResponseRecorder {
+	t.Helper()
+	h := NewUserHandler(e.users, e.orders, e.carts, e.wishlists, e.revoker, e.audit, nil, nil)
+	return serve(t, testRequest{
+		method: http.MethodDelete,
+		route:  "/api/v1/users/me",
+		target: "/api/v1/users/me",
+		body:   `{"confirm":"DELETE MY ACCOUNT"}`,
+		userID: 7,
+	}, h.EraseMyData)
+}
+
+// tokenRevoked reports whether a token user 7 was issued a minute ago is now rejected
+func (e *erasure) tokenRevoked() bool {
+	claims := &customJWT.UserClaims{UserID: 7}
+	claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	return e.revoker.IsRevoked(context.Background(), claims)
+}
+
+func TestEraseMyData(t *testing.T) {
+	e := newErasure()
+
+	if w := e.erase(t); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+
+	want := []string{"anonymise_orders", "clear_cart", "remove_wishlist_item", "delete_address", "delete_address", "delete_user"}
+	if !slices.Equal(e.calls, want) {
+		t.Errorf("calls = %v, want %v", e.calls, want)
+	}
+	if !e.tokenRevoked() {
+		t.Error("the user's tokens still work")
+	}
+	if len(e.audit.entries) != 1 || e.audit.entries[0].Outcome != "completed" {
+		t.Fatalf("audit = %+v, want one completed erasure", e.audit.entries)
+	}
+}
+
+func TestEraseMyDataStopsAtTheFailedStep(t *testing.T) {
+	e := newErasure()
+	e.carts.clearCart = func(*cartpb.ClearCartRequest) (*cartpb.ClearCartResponse, error) {
+		return nil, status.Error(codes.Unavailable, "cart service down")
+	}
+
+	if w := e.erase(t); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503: %s", w.Code, w.Body)
+	}
+
+	if !slices.Equal(e.calls, []string{"anonymise_orders"}) {
+		t.Errorf("calls = %v, want only the orders anonymised", e.calls)
+	}
+	// The user can still retry with their token
+	if e.tokenRevoked() {
+		t.Error("tokens were revoked by a failed erasure")
+	}
+	if len(e.audit.entries) != 1 {
+		t.Fatalf("audit = %+v, want one entry", e.audit.entries)
+	}
+	entry := e.audit.entries[0]
+	if entry.Outcome != "partial" || entry.Details["failed_step"] != "clear_cart" {
+		t.Errorf("audit entry = %+v, want a partial erasure that failed at clear_cart", entry)
+	}
+	if completed, _ := entry.Details["completed"].([]string); !slices.Equal(completed, []string{"anonymise_orders"}) {
+		t.Errorf("completed = %v, want [anonymise_orders]", entry.Details["completed"])
+	}
+}
+
+func TestEraseMyDataRetryAfterUserDeleted(t *testing.T) {
+	e := newErasure()
+	// A previous attempt deleted the user and then failed to revoke the tokens
+	e.users.deleteUser = func(*userpb.DeleteUserRequest) (*userpb.DeleteUserResponse, error) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	if w := e.erase(t); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if !e.tokenRevoked() {
+		t.Error("the retry did not revoke the user's tokens")
+	}
+}
+
+func TestEraseMyDataRequiresConfirmation(t *testing.T) {
+	e := newErasure()
+	h := NewUserHandler(e.users, e.orders, e.carts, e.wishlists, e.revoker, e.audit, nil, nil)
+
+	w := serve(t, testRequest{
+		method: http.MethodDelete,
+		route:  "/api/v1/users/me",
+		target: "/api/v1/users/me",
+		body:   `{"confirm":"yes"}`,
+		userID: 7,
+	}, h.EraseMyData)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if len(e.calls) != 0 {
+		t.Errorf("calls = %v, want none", e.calls)
+	}
+}
+
+func TestSearchUsers(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		want       *userpb.SearchUsersRequest
+		wantError  string
+	}{
+		{
+			name:       "no filter",
+			target:     "/api/v1/users/search?query=mona",
+			wantStatus: http.StatusOK,
+			want:       &userpb.SearchUsersRequest{Query: "mona", PageNumber: 1, PageSize: defaultPerPage},
+		},
+		{
+			name:       "admins newest first",
+			target:     "/api/v1/users/search?role=admin&sort_by=created_at&sort_order=desc&page=2&per_page=5",
+			wantStatus: http.StatusOK,
+			want:       &userpb.SearchUsersRequest{Role: "admin", SortBy: "created_at", SortOrder: "desc", PageNumber: 2, PageSize: 5},
+		},
+		{
+			name:       "customers",
+			target:     "/api/v1/users/search?role=customer",
+			wantStatus: http.StatusOK,
+			want:       &userpb.SearchUsersRequest{Role: "customer", PageNumber: 1, PageSize: defaultPerPage},
+		},
+		{name: "invalid role", target: "/api/v1/users/search?role=invalid", wantStatus: http.StatusBadRequest, wantError: "role must be customer or admin"},
+		{name: "role in another case", target: "/api/v1/users/search?role=Admin", wantStatus: http.StatusBadRequest, wantError: "role must be customer or admin"},
+		{name: "invalid sort field", target: "/api/v1/users/search?sort_by=password", wantStatus: http.StatusBadRequest, wantError: "sort_by must be id or created_at"},
+		{name: "invalid sort order", target: "/api/v1/users/search?sort_order=random", wantStatus: http.StatusBadRequest, wantError: "sort_order must be asc or desc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var searched *userpb.SearchUsersRequest
+			users := &fakeUserClient{searchUsers: func(in *userpb.SearchUsersRequest) (*userpb.SearchUsersResponse, error) {
+				searched = in
+				return &userpb.SearchUsersResponse{Users: []*userpb.User{{Id: 1, Role: "admin"}}, Total: 1}, nil
+			}}
+			h := NewUserHandler(users, nil, nil, nil, nil, nil, nil, nil)
+
+			w := serve(t, testRequest{method: http.MethodGet, route: "/api/v1/users/search", target: tt.target, userID: 1, roles: []string{"admin"}}, h.SearchUsers)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantError != "" {
+				if searched != nil {
+					t.Errorf("the search was forwarded as %v", searched)
+				}
+				if got := errorMessage(t, w); got != tt.wantError {
+					t.Errorf("message = %q, want %q", got, tt.wantError)
+				}
+				return
+			}
+			if !proto.Equal(searched, tt.want) {
+				t.Errorf("forwarded %v, want %v", searched, tt.want)
+			}
+		})
+	}
+}
+
+func TestImpersonateUserAuditsTheAdmin(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		err        error
+		wantStatus int
+	}{
+		{name: "started", target: "/api/v1/admin/impersonate/7", wantStatus: http.StatusOK},
+		{name: "invalid user id", target: "/api/v1/admin/impersonate/abc", wantStatus: http.StatusBadRequest},
+		{name: "refused", target: "/api/v1/admin/impersonate/2", err: status.Error(codes.PermissionDenied, "admins cannot be impersonated"), wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var impersonated *userpb.ImpersonateUserRequest
+			users := &fakeUserClient{impersonate: func(in *userpb.ImpersonateUserRequest) (*userpb.ImpersonateUserResponse, error) {
+				impersonated = in
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &userpb.ImpersonateUserResponse{Token: "token", ExpiresAt: "2026-10-16T12:15:00Z"}, nil
+			}}
+			auditLog := &auditRecorder{}
+			h := NewUserHandler(users, nil, nil, nil, nil, auditLog, nil, nil)
+
+			w := serve(t, testRequest{method: http.MethodPost, route: "/api/v1/admin/impersonate/:userId", target: tt.target, userID: 1, roles: []string{"admin"}}, h.ImpersonateUser)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if len(auditLog.entries) > 0 {
+					t.Errorf("audit = %+v, want no entry", auditLog.entries)
+				}
+				return
+			}
+			if impersonated.GetUserId() != 7 {
+				t.Errorf("impersonated user %d, want 7", impersonated.GetUserId())
+			}
+			if len(auditLog.entries) != 1 {
+				t.Fatalf("audit = %+v, want one entry", auditLog.entries)
+			}
+			entry := auditLog.entries[0]
+			if entry.Event != "impersonation_started" || entry.UserID != 1 ||
+				entry.Details["impersonated_user_id"] != int64(7) || entry.Details["expires_at"] != "2026-10-16T12:15:00Z" {
+				t.Errorf("entry = %+v, want impersonation_started by the admin 1 for user 7", entry)
+			}
+		})
+	}
+}
+
+func TestSetupTwoFactorConflicts(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "set up", wantStatus: http.StatusOK},
+		{name: "already enabled", err: status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled"), wantStatus: http.StatusConflict},
+		{name: "service down", err: status.Error(codes.Unavailable, "user service unavailable"), wantStatus: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserClient{setupTwoFactor: func(in *userpb.SetupTwoFactorRequest) (*userpb.SetupTwoFactorResponse, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &userpb.SetupTwoFactorResponse{Secret: "JBSWY3DPEHPK3PXP"}, nil
+			}}
+			h := NewUserHandler(users, nil, nil, nil, nil, nil, nil, nil)
+
+			w := serve(t, testRequest{method: http.MethodPost, route: "/api/v1/users/2fa/setup", target: "/api/v1/users/2fa/setup", userID: 7}, h.SetupTwoFactor)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
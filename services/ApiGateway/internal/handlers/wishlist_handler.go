@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	wishlistpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/wishlist"
+)
+
+// WishlistHandler handles wishlist-related HTTP requests
+type WishlistHandler struct {
+	wishlistClient wishlistpb.WishlistServiceClient
+}
+
+// NewWishlistHandler creates a new wishlist handler
+func NewWishlistHandler(wishlistClient wishlistpb.WishlistServiceClient) *WishlistHandler {
+	return &WishlistHandler{
+		wishlistClient: wishlistClient,
+	}
+}
+
+// GetWishlist godoc
+// @Summary Get user wishlist
+// @Description Get the current user's saved products, most recently added first
+// @Tags wishlist
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} wishlistpb.WishlistResponse
+// @Router /api/v1/wishlist [get]
+func (h *WishlistHandler) GetWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	resp, err := h.wishlistClient.GetWishlist(r.Context(), &wishlistpb.GetWishlistRequest{
+		UserId: int64(userID),
+	})
+
+	if err != nil {
+		logger.Errorf("failed to get wishlist: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(w, http.StatusOK, resp)
+}
+
+// AddItem godoc
+// @Summary Add product to wishlist
+// @Description Save a product to the user's wishlist. Adding a product that is already saved leaves the wishlist unchanged.
+// @Tags wishlist
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body WishlistItemRequest true "Product ID"
+// @Success 200 {object} wishlistpb.WishlistResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/wishlist/items [post]
+func (h *WishlistHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req WishlistItemRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	resp, err := h.wishlistClient.AddItem(r.Context(), &wishlistpb.AddWishlistItemRequest{
+		UserId:    int64(userID),
+		ProductId: req.ProductID,
+	})
+
+	if err != nil {
+		logger.Errorf("failed to add item to wishlist: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(w, http.StatusOK, resp)
+}
+
+// RemoveItem godoc
+// @Summary Remove product from wishlist
+// @Description Remove a product from the user's wishlist
+// @Tags wishlist
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body WishlistItemRequest true "Product ID"
+// @Success 200 {object} wishlistpb.WishlistResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/wishlist/items [delete]
+func (h *WishlistHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req WishlistItemRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	resp, err := h.wishlistClient.RemoveItem(r.Context(), &wishlistpb.RemoveWishlistItemRequest{
+		UserId:    int64(userID),
+		ProductId: req.ProductID,
+	})
+
+	if err != nil {
+		logger.Errorf("failed to remove item from wishlist: %v", err)
+		writeJSONErrorFromGRPC(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProtoJSON(w, http.StatusOK, resp)
+}
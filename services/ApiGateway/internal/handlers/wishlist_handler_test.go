@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	wishlistpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/wishlist"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// wishlistOf fakes a wishlist service holding productIDs for userID and records what is removed from it
+func wishlistOf(userID int64, productIDs ...int64) (*fakeWishlistClient, *[]int64) {
+	removed := []int64{}
+	wishlists := &fakeWishlistClient{
+		getWishlist: func(in *wishlistpb.GetWishlistRequest) (*wishlistpb.WishlistResponse, error) {
+			resp := &wishlistpb.WishlistResponse{UserId: in.UserId}
+			if in.UserId == userID {
+				for _, id := range productIDs {
+					resp.Items = append(resp.Items, &wishlistpb.WishlistItem{ProductId: id})
+				}
+			}
+			return resp, nil
+		},
+		removeItem: func(in *wishlistpb.RemoveWishlistItemRequest) (*wishlistpb.WishlistResponse, error) {
+			if in.UserId != userID {
+				return nil, status.Error(codes.PermissionDenied, "not your wishlist")
+			}
+			removed = append(removed, in.ProductId)
+			return &wishlistpb.WishlistResponse{UserId: in.UserId}, nil
+		},
+	}
+	return wishlists, &removed
+}
+
+func TestClearWishlistRemovesEveryItem(t *testing.T) {
+	wishlists, removed := wishlistOf(7, 3, 5)
+	h := NewUserHandler(nil, nil, nil, wishlists, nil, nil, nil, nil)
+
+	if err := h.clearWishlist(context.Background(), 7); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(*removed, []int64{3, 5}) {
+		t.Fatalf("removed %v, want [3 5]", *removed)
+	}
+}
+
+func TestClearWishlistStopsOnError(t *testing.T) {
+	wishlists, _ := wishlistOf(7, 3)
+	wishlists.removeItem = func(*wishlistpb.RemoveWishlistItemRequest) (*wishlistpb.WishlistResponse, error) {
+		return nil, status.Error(codes.Unavailable, "redis down")
+	}
+	h := NewUserHandler(nil, nil, nil, wishlists, nil, nil, nil, nil)
+
+	if err := h.clearWishlist(context.Background(), 7); status.Code(err) != codes.Unavailable {
+		t.Fatalf("clearWishlist = %v, want the service's Unavailable error", err)
+	}
+}
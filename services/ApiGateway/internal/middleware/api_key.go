@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// APIKeyHeader carries the key server-to-server consumers send instead of a JWT
+	APIKeyHeader = "X-API-Key"
+	// APIKeyRole is the role of every API key, so routes gated by role, such as the admin API, refuse keys
+	APIKeyRole = "api_key"
+)
+
+// ErrInvalidAPIKey is returned for a key the user service does not know or has revoked
+var ErrInvalidAPIKey = errors.New("invalid or revoked api key")
+
+// APIKeyVerifier resolves a key to the user it acts as and its scopes, as the user service's client does
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, in *userpb.VerifyAPIKeyRequest, opts ...grpc.CallOption) (*userpb.VerifyAPIKeyResponse, error)
+}
+
+// APIKeyPrincipal is who a verified API key acts as, with its scopes as the claims' permissions
+type APIKeyPrincipal struct {
+	KeyID  int32
+	Claims *customJWT.UserClaims
+}
+
+type apiKeyCacheEntry struct {
+	principal *APIKeyPrincipal
+	expires   time.Time
+}
+
+// APIKeyResolver verifies API keys with the user service and keeps each valid one for ttl, so most requests
+// skip the call. A key revoked elsewhere keeps working here until its entry expires; the user service records
+// its use once per ttl.
+type APIKeyResolver struct {
+	verifier APIKeyVerifier
+	ttl      time.Duration
+	mu       sync.Mutex
+	// cache is keyed by the key's SHA-256, so the keys themselves are not kept
+	cache map[string]apiKeyCacheEntry
+}
+
+func NewAPIKeyResolver(verifier APIKeyVerifier, ttl time.Duration) *APIKeyResolver {
+	return &APIKeyResolver{verifier: verifier, ttl: ttl, cache: make(map[string]apiKeyCacheEntry)}
+}
+
+// Resolve returns the principal key acts as, or ErrInvalidAPIKey. Only valid keys are cached, so a key
+// works as soon as it is created.
+func (r *APIKeyResolver) Resolve(ctx context.Context, key string) (*APIKeyPrincipal, error) {
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])
+
+	now := time.Now()
+	r.mu.Lock()
+	entry, ok := r.cache[hash]
+	r.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.principal, nil
+	}
+
+	resp, err := r.verifier.VerifyAPIKey(ctx, &userpb.VerifyAPIKeyRequest{Key: key})
+	if err != nil {
+		if status.Code(err) == codes.Unauthenticated {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	apiKey := resp.GetApiKey()
+	principal := &APIKeyPrincipal{
+		KeyID: apiKey.GetId(),
+		Claims: &customJWT.UserClaims{
+			UserID:      uint(apiKey.GetUserId()),
+			Role:        APIKeyRole,
+			Roles:       []string{APIKeyRole},
+			Permissions: apiKey.GetScopes(),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for h, e := range r.cache {
+		if now.After(e.expires) {
+			delete(r.cache, h)
+		}
+	}
+	r.cache[hash] = apiKeyCacheEntry{principal: principal, expires: now.Add(r.ttl)}
+	return principal, nil
+}
+
+// Forget drops the cached entry of the key with id, so a key revoked through this instance fails here at once
+func (r *APIKeyResolver) Forget(id int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for hash, entry := range r.cache {
+		if entry.principal.KeyID == id {
+			delete(r.cache, hash)
+		}
+	}
+}
+
+// APIKeyAuthMiddleware authenticates requests by their X-API-Key header. The key fills the same claims as a
+// JWT, so GetUserID, GetUserRole and RequirePermission work unchanged: the user is the one the key acts as,
+// the role is APIKeyRole and the permissions are the key's scopes.
+func APIKeyAuthMiddleware(resolver *APIKeyResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			writeJSONError(c, http.StatusUnauthorized, "missing api key")
+			c.Abort()
+			return
+		}
+
+		principal, err := resolver.Resolve(c.Request.Context(), key)
+		if err != nil {
+			if errors.Is(err, ErrInvalidAPIKey) {
+				writeJSONError(c, http.StatusUnauthorized, "invalid or revoked api key")
+			} else {
+				logger.FromContext(c.Request.Context()).Warn("api_key_verification_failed", slog.String("error", err.Error()))
+				writeJSONError(c, http.StatusServiceUnavailable, "api key verification unavailable")
+			}
+			c.Abort()
+			return
+		}
+
+		ctx := withClaims(c.Request.Context(), principal.Claims)
+		ctx = logger.IntoContext(ctx, logger.FromContext(ctx).With(
+			slog.Uint64("user_id", uint64(principal.Claims.UserID)),
+			slog.Int("api_key_id", int(principal.KeyID)),
+		))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// keyring verifies the keys it holds, answering err instead when set, and counts its calls
+type keyring struct {
+	keys  map[string]*userpb.APIKey
+	err   error
+	calls int
+}
+
+func (k *keyring) VerifyAPIKey(_ context.Context, in *userpb.VerifyAPIKeyRequest, _ ...grpc.CallOption) (*userpb.VerifyAPIKeyResponse, error) {
+	k.calls++
+	if k.err != nil {
+		return nil, k.err
+	}
+	key, ok := k.keys[in.GetKey()]
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid api key")
+	}
+	return &userpb.VerifyAPIKeyResponse{ApiKey: key}, nil
+}
+
+// apiKeyRoute serves GET / behind APIKeyAuthMiddleware and RequirePermission("product:write"), answering with
+// the user the key acts as. It returns a func that requests it with key.
+func apiKeyRoute(resolver *APIKeyResolver) func(key string) *httptest.ResponseRecorder {
+	engine := gin.New()
+	engine.GET("/", APIKeyAuthMiddleware(resolver), RequirePermission("product:write"), func(c *gin.Context) {
+		userID, _ := GetUserID(c.Request.Context())
+		role, _ := GetUserRole(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"user_id": userID, "role": role})
+	})
+
+	return func(key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if key != "" {
+			r.Header.Set(APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+}
+
+func TestAPIKeyAuthMiddleware(t *testing.T) {
+	verifier := &keyring{keys: map[string]*userpb.APIKey{
+		"writer": {Id: 1, UserId: 7, Scopes: []string{"product:write"}},
+		"reader": {Id: 2, UserId: 7, Scopes: []string{"product:read"}},
+	}}
+	get := apiKeyRoute(NewAPIKeyResolver(verifier, time.Minute))
+
+	tests := []struct {
+		name     string
+		key      string
+		wantCode int
+		wantBody string
+	}{
+		{name: "scoped key", key: "writer", wantCode: http.StatusOK, wantBody: `{"role":"api_key","user_id":7}`},
+		{name: "key without the scope", key: "reader", wantCode: http.StatusForbidden},
+		{name: "unknown key", key: "guess", wantCode: http.StatusUnauthorized},
+		{name: "no key", wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(tt.key)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %s, want %s", w.Body, tt.wantBody)
+			}
+		})
+	}
+
+	t.Run("user service down", func(t *testing.T) {
+		get := apiKeyRoute(NewAPIKeyResolver(&keyring{err: status.Error(codes.Unavailable, "connection refused")}, time.Minute))
+		if w := get("writer"); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503", w.Code)
+		}
+	})
+}
+
+func TestAPIKeyResolverCachesValidKeys(t *testing.T) {
+	verifier := &keyring{keys: map[string]*userpb.APIKey{"writer": {Id: 1, UserId: 7}}}
+	resolver := NewAPIKeyResolver(verifier, time.Minute)
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := resolver.Resolve(ctx, "writer"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if verifier.calls != 1 {
+		t.Errorf("a valid key was verified %d times, want once", verifier.calls)
+	}
+
+	// Invalid keys are not cached, so a key works as soon as it is created
+	for range 2 {
+		if _, err := resolver.Resolve(ctx, "new"); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Fatalf("Resolve = %v, want %v", err, ErrInvalidAPIKey)
+		}
+	}
+	verifier.keys["new"] = &userpb.APIKey{Id: 2, UserId: 8}
+	if principal, err := resolver.Resolve(ctx, "new"); err != nil || principal.Claims.UserID != 8 {
+		t.Errorf("the new key resolved to %+v, %v, want user 8", principal, err)
+	}
+
+	// A key revoked through this instance fails at once
+	delete(verifier.keys, "writer")
+	resolver.Forget(1)
+	if _, err := resolver.Resolve(ctx, "writer"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("a forgotten revoked key resolved: %v", err)
+	}
+}
+
+func TestAPIKeyResolverReverifiesAfterTheTTL(t *testing.T) {
+	verifier := &keyring{keys: map[string]*userpb.APIKey{"writer": {Id: 1, UserId: 7}}}
+	resolver := NewAPIKeyResolver(verifier, 20*time.Millisecond)
+	ctx := context.Background()
+
+	if _, err := resolver.Resolve(ctx, "writer"); err != nil {
+		t.Fatal(err)
+	}
+	// Revoked on another instance, the key keeps working here only until its entry expires
+	delete(verifier.keys, "writer")
+	if _, err := resolver.Resolve(ctx, "writer"); err != nil {
+		t.Fatalf("the cached key failed before the TTL: %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, err := resolver.Resolve(ctx, "writer"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Resolve after the TTL = %v, want %v", err, ErrInvalidAPIKey)
+	}
+}
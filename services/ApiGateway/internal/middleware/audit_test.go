@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+)
+
+// auditedEngine serves engine's routes behind RequestID, AuditLogger into sink and AuthMiddleware, as the
+// router does
+func auditedEngine(sink *recordingSink, manager *customJWT.JWTManager, redact ...string) *gin.Engine {
+	engine := gin.New()
+	engine.Use(RequestID(), AuditLogger(sink, redact))
+	engine.DELETE("/api/v1/products/:id", AuthMiddleware(manager, nil), RequirePermission(customJWT.PermissionProductWrite), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return engine
+}
+
+func TestAuditLoggerRecordsAProductDelete(t *testing.T) {
+	manager := customJWT.NewJWTManager("secret", time.Hour)
+	admin, err := manager.Generate(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer, err := manager.Generate(7, "mona@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		wantUser    uint
+		wantRole    string
+		wantStatus  int
+		wantOutcome string
+	}{
+		{name: "admin", token: admin, wantUser: 1, wantRole: "admin", wantStatus: http.StatusOK, wantOutcome: "success"},
+		{name: "customer", token: customer, wantUser: 7, wantRole: "customer", wantStatus: http.StatusForbidden, wantOutcome: "failure"},
+		// Refused before the user is known, so recorded without one
+		{name: "anonymous", wantStatus: http.StatusUnauthorized, wantOutcome: "failure"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			r := httptest.NewRequest(http.MethodDelete, "/api/v1/products/42", nil)
+			r.Header.Set("X-Request-ID", "req-42")
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			auditedEngine(sink, manager).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if len(sink.entries) != 1 {
+				t.Fatalf("entries = %+v, want one", sink.entries)
+			}
+			entry := sink.entries[0]
+			if entry.Event != "mutating_request" || entry.Action != "DELETE /api/v1/products/:id" || entry.Resource != "/api/v1/products/42" {
+				t.Errorf("entry = %+v, want the delete of product 42", entry)
+			}
+			if entry.UserID != tt.wantUser || entry.Role != tt.wantRole {
+				t.Errorf("actor = %d %q, want %d %q", entry.UserID, entry.Role, tt.wantUser, tt.wantRole)
+			}
+			if entry.RequestID != "req-42" || entry.Outcome != tt.wantOutcome || entry.Details["status"] != tt.wantStatus {
+				t.Errorf("request %q with outcome %s and status %v, want req-42 with %s and %d",
+					entry.RequestID, entry.Outcome, entry.Details["status"], tt.wantOutcome, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuditLoggerRedactsTheBody(t *testing.T) {
+	sink := &recordingSink{}
+	var received string
+	engine := gin.New()
+	engine.Use(AuditLogger(sink, []string{"password", "Token"}))
+	engine.POST("/api/v1/users/login", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		received = string(body)
+		c.Status(http.StatusUnauthorized)
+	})
+
+	const body = `{"email": "mona@example.com", "Password": "hunter2", "device": {"token": "abc", "name": "phone"}, "keys": [{"token": "def"}]}`
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/login", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	engine.ServeHTTP(httptest.NewRecorder(), r)
+
+	// The handler still gets the whole body
+	if received != body {
+		t.Errorf("the handler read %q, want %q", received, body)
+	}
+	if len(sink.entries) != 1 {
+		t.Fatalf("entries = %+v, want one", sink.entries)
+	}
+	want := map[string]any{
+		"email":    "mona@example.com",
+		"Password": redactedValue,
+		"device":   map[string]any{"token": redactedValue, "name": "phone"},
+		"keys":     []any{map[string]any{"token": redactedValue}},
+	}
+	if got := sink.entries[0].Details["body"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("body = %v, want %v", got, want)
+	}
+}
+
+func TestAuditLoggerDescribesBodiesItCannotRedact(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        any
+	}{
+		{name: "not JSON", contentType: "application/json", body: "password=hunter2", want: "[16 bytes, not JSON]"},
+		{name: "too large", contentType: "application/json", body: `{"name": "` + strings.Repeat("a", maxAuditedBody) + `"}`, want: "[more than 65536 bytes]"},
+		// Uploads are not read at all
+		{name: "other content type", contentType: "image/jpeg", body: "\xff\xd8\xff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			var received int
+			engine := gin.New()
+			engine.Use(AuditLogger(sink, []string{"password"}))
+			engine.POST("/api/v1/products", func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				received = len(body)
+				c.Status(http.StatusCreated)
+			})
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			engine.ServeHTTP(httptest.NewRecorder(), r)
+
+			if received != len(tt.body) {
+				t.Errorf("the handler read %d bytes, want %d", received, len(tt.body))
+			}
+			if len(sink.entries) != 1 {
+				t.Fatalf("entries = %+v, want one", sink.entries)
+			}
+			if got, ok := sink.entries[0].Details["body"]; got != tt.want || ok != (tt.want != nil) {
+				t.Errorf("body = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditLoggerSkipsReadsAndUnmatchedPaths(t *testing.T) {
+	sink := &recordingSink{}
+	engine := gin.New()
+	engine.Use(AuditLogger(sink, nil))
+	engine.GET("/api/v1/products/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.HEAD("/api/v1/products/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, r := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/products/42", nil),
+		httptest.NewRequest(http.MethodHead, "/api/v1/products/42", nil),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/nowhere", nil),
+	} {
+		engine.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	if len(sink.entries) != 0 {
+		t.Fatalf("entries = %+v, want none", sink.entries)
+	}
+}
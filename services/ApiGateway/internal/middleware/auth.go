@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 )
@@ -16,8 +18,8 @@ const (
 	UserClaimsKey contextKey = "userClaims"
 )
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware(jwtManager *customJWT.JWTManager) gin.HandlerFunc {
+// AuthMiddleware validates JWT tokens and, when revoker is set, rejects revoked ones
+func AuthMiddleware(jwtManager *customJWT.JWTManager, revoker *TokenRevoker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -36,14 +38,25 @@ func AuthMiddleware(jwtManager *customJWT.JWTManager) gin.HandlerFunc {
 		tokenString := parts[1]
 		claims, err := jwtManager.Verify(tokenString)
 		if err != nil {
-			logger.Errorf("JWT validation failed: %v", err)
+			logger.FromContext(c.Request.Context()).Warn("jwt_validation_failed", slog.String("error", err.Error()))
 			writeJSONError(c, http.StatusUnauthorized, "invalid or expired token")
 			c.Abort()
 			return
 		}
 
-		// Add claims to context
-		ctx := context.WithValue(c.Request.Context(), UserClaimsKey, claims)
+		if revoker != nil && revoker.IsRevoked(c.Request.Context(), claims) {
+			writeJSONError(c, http.StatusUnauthorized, "token has been revoked")
+			c.Abort()
+			return
+		}
+
+		// Add claims to context, forward the user to downstream services and tag the request logger with them
+		ctx := withClaims(c.Request.Context(), claims)
+		log := logger.FromContext(ctx).With(slog.Uint64("user_id", uint64(claims.UserID)))
+		if claims.Impersonated() {
+			log = log.With(slog.Uint64("impersonated_by", uint64(claims.ImpersonatedBy)))
+		}
+		ctx = logger.IntoContext(ctx, log)
 		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
@@ -59,8 +72,7 @@ func OptionalAuthMiddleware(jwtManager *customJWT.JWTManager) gin.HandlerFunc {
 				tokenString := parts[1]
 				claims, err := jwtManager.Verify(tokenString)
 				if err == nil {
-					ctx := context.WithValue(c.Request.Context(), UserClaimsKey, claims)
-					c.Request = c.Request.WithContext(ctx)
+					c.Request = c.Request.WithContext(withClaims(c.Request.Context(), claims))
 				}
 			}
 		}
@@ -68,8 +80,17 @@ func OptionalAuthMiddleware(jwtManager *customJWT.JWTManager) gin.HandlerFunc {
 	}
 }
 
-// RequireRole checks if user has required role
+// RequireRole checks that the user holds at least one of roles
 func RequireRole(roles ...string) gin.HandlerFunc {
+	return requireRole(roles, false)
+}
+
+// RequireRoleTwoFactor is RequireRole that also refuses tokens not issued after a two-factor verification
+func RequireRoleTwoFactor(roles ...string) gin.HandlerFunc {
+	return requireRole(roles, true)
+}
+
+func requireRole(roles []string, twoFactor bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims, ok := c.Request.Context().Value(UserClaimsKey).(*customJWT.UserClaims)
 		if !ok {
@@ -78,10 +99,9 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 			return
 		}
 
-		logger.Infof("User ID %d with role %s is accessing %s", claims.UserID, claims.Role, c.Request.URL.Path)
 		hasRole := false
 		for _, role := range roles {
-			if claims.Role == role {
+			if claims.HasRole(role) {
 				hasRole = true
 				break
 			}
@@ -89,15 +109,85 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 
 		if !hasRole {
 			writeJSONError(c, http.StatusForbidden, "insufficient permissions")
-			logger.Info("forbidden access attempt by user ID ", claims.UserID)
+			logger.FromContext(c.Request.Context()).Warn("forbidden_access",
+				slog.Any("roles", claims.Roles),
+				slog.String("path", c.Request.URL.Path),
+			)
+			c.Abort()
+			return
+		}
+
+		if twoFactor && !claims.TwoFactor {
+			refuseWithoutTwoFactor(c, claims)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission checks that the token grants every one of perms
+func RequirePermission(perms ...string) gin.HandlerFunc {
+	return requirePermission(perms, false)
+}
+
+// RequirePermissionTwoFactor is RequirePermission that also refuses admin tokens not issued after a two-factor
+// verification. Other roles, and API keys, are only checked for perms.
+func RequirePermissionTwoFactor(perms ...string) gin.HandlerFunc {
+	return requirePermission(perms, true)
+}
+
+func requirePermission(perms []string, twoFactor bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := c.Request.Context().Value(UserClaimsKey).(*customJWT.UserClaims)
+		if !ok {
+			writeJSONError(c, http.StatusUnauthorized, "unauthorized")
 			c.Abort()
 			return
 		}
 
+		for _, perm := range perms {
+			if !claims.HasPermission(perm) {
+				writeJSONError(c, http.StatusForbidden, "insufficient permissions")
+				logger.FromContext(c.Request.Context()).Warn("forbidden_access",
+					slog.Any("roles", claims.Roles),
+					slog.String("permission", perm),
+					slog.String("path", c.Request.URL.Path),
+				)
+				c.Abort()
+				return
+			}
+		}
+
+		if twoFactor && claims.HasRole("admin") && !claims.TwoFactor {
+			refuseWithoutTwoFactor(c, claims)
+			return
+		}
+
 		c.Next()
 	}
 }
 
+// refuseWithoutTwoFactor answers 403 to a token that needed a two-factor verification and aborts the request
+func refuseWithoutTwoFactor(c *gin.Context, claims *customJWT.UserClaims) {
+	writeJSONError(c, http.StatusForbidden, "two-factor authentication required")
+	logger.FromContext(c.Request.Context()).Warn("two_factor_required",
+		slog.Uint64("user_id", uint64(claims.UserID)),
+		slog.String("path", c.Request.URL.Path),
+	)
+	c.Abort()
+}
+
+// withClaims stores claims for the handlers and attaches the identity gRPC clients forward as metadata
+func withClaims(ctx context.Context, claims *customJWT.UserClaims) context.Context {
+	ctx = context.WithValue(ctx, UserClaimsKey, claims)
+	return grpcmiddleware.ContextWithIdentity(ctx, grpcmiddleware.Identity{
+		UserID:      claims.UserID,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+	})
+}
+
 // GetUserClaims retrieves user claims from context
 func GetUserClaims(ctx context.Context) (*customJWT.UserClaims, bool) {
 	claims, ok := ctx.Value(UserClaimsKey).(*customJWT.UserClaims)
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+)
+
+// authorizedRoute serves GET / behind AuthMiddleware and check, returning a func that requests it with token
+func authorizedRoute(manager *customJWT.JWTManager, check gin.HandlerFunc) func(token string) int {
+	engine := gin.New()
+	engine.GET("/", AuthMiddleware(manager, nil), check, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	return func(token string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w.Code
+	}
+}
+
+func TestCustomRoleHoldingOnlyProductWrite(t *testing.T) {
+	manager := customJWT.NewJWTManager("secret", time.Hour)
+	permissions, err := customJWT.ParseRolePermissions(`{"catalog_manager": ["product:write"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.SetRolePermissions(permissions)
+
+	catalogManager, err := manager.Generate(7, "editor@example.com", "catalog_manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := manager.Generate(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		check gin.HandlerFunc
+		token string
+		want  int
+	}{
+		{name: "product:write", check: RequirePermission(customJWT.PermissionProductWrite), token: catalogManager, want: http.StatusOK},
+		{name: "user:write", check: RequirePermission(customJWT.PermissionUserWrite), token: catalogManager, want: http.StatusForbidden},
+		{name: "product:write and category:write", check: RequirePermission(customJWT.PermissionProductWrite, customJWT.PermissionCategoryWrite), token: catalogManager, want: http.StatusForbidden},
+		{name: "admin role", check: RequireRole("admin"), token: catalogManager, want: http.StatusForbidden},
+		{name: "admin role held by an admin", check: RequireRole("admin"), token: admin, want: http.StatusOK},
+		{name: "user:write held by an admin", check: RequirePermission(customJWT.PermissionUserWrite), token: admin, want: http.StatusOK},
+		{name: "no token", check: RequirePermission(customJWT.PermissionProductWrite), want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := authorizedRoute(manager, tt.check)(tt.token); code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirePermissionWithoutClaims(t *testing.T) {
+	engine := gin.New()
+	engine.GET("/", RequirePermission(customJWT.PermissionProductWrite), func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401 when no auth middleware ran", w.Code)
+	}
+}
+
+func TestRequireRoleMatchesAnyOfTheUsersRoles(t *testing.T) {
+	manager := customJWT.NewJWTManager("secret", time.Hour)
+	supportAgent, err := manager.Generate(7, "agent@example.com", "customer", "support")
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer, err := manager.Generate(8, "customer@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := manager.Verify(supportAgent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Role != "customer" || len(claims.Roles) != 2 {
+		t.Errorf("role %q with roles %v, want customer first of customer and support", claims.Role, claims.Roles)
+	}
+
+	supportOnly := authorizedRoute(manager, RequireRole("support"))
+	if code := supportOnly(supportAgent); code != http.StatusOK {
+		t.Errorf("customer and support agent on a support route: status = %d, want 200", code)
+	}
+	if code := supportOnly(customer); code != http.StatusForbidden {
+		t.Errorf("customer on a support route: status = %d, want 403", code)
+	}
+	if code := authorizedRoute(manager, RequireRole("admin", "support"))(supportAgent); code != http.StatusOK {
+		t.Errorf("support agent on an admin or support route: status = %d, want 200", code)
+	}
+	if code := authorizedRoute(manager, RequireRole("admin"))(supportAgent); code != http.StatusForbidden {
+		t.Errorf("support agent on an admin route: status = %d, want 403", code)
+	}
+}
+
+func TestAuthMiddlewareChecksIssuerAndAudience(t *testing.T) {
+	issued := func(issuer, audience string) string {
+		t.Helper()
+		signer := customJWT.NewJWTManager("secret", time.Hour)
+		signer.SetIssuerAudience(issuer, audience)
+		token, err := signer.Generate(7, "mona@example.com", "customer")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	ok := func(*gin.Context) {}
+
+	tests := []struct {
+		name             string
+		issuer, audience string
+		token            string
+		want             int
+	}{
+		{name: "matching", issuer: "user-service", audience: "api-gateway", token: issued("user-service", "api-gateway"), want: http.StatusOK},
+		{name: "wrong issuer", issuer: "user-service", audience: "api-gateway", token: issued("staging-user-service", "api-gateway"), want: http.StatusUnauthorized},
+		{name: "wrong audience", issuer: "user-service", audience: "api-gateway", token: issued("user-service", "admin-panel"), want: http.StatusUnauthorized},
+		{name: "token without issuer and audience", issuer: "user-service", audience: "api-gateway", token: issued("", ""), want: http.StatusUnauthorized},
+		// Without configured values the claims are not checked, as before they existed
+		{name: "unconfigured", token: issued("staging-user-service", "admin-panel"), want: http.StatusOK},
+		{name: "unconfigured, token without issuer and audience", token: issued("", ""), want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := customJWT.NewJWTManager("secret", time.Hour)
+			verifier.SetIssuerAudience(tt.issuer, tt.audience)
+			if got := authorizedRoute(verifier, ok)(tt.token); got != tt.want {
+				t.Fatalf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseNetworks(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []netip.Prefix
+		wantErr bool
+	}{
+		{name: "IPv4 CIDR", values: []string{"203.0.113.0/24"}, want: []netip.Prefix{netip.MustParsePrefix("203.0.113.0/24")}},
+		{name: "host bits are masked", values: []string{"203.0.113.77/24"}, want: []netip.Prefix{netip.MustParsePrefix("203.0.113.0/24")}},
+		{name: "IPv6 CIDR", values: []string{"2001:db8::/32"}, want: []netip.Prefix{netip.MustParsePrefix("2001:db8::/32")}},
+		{name: "bare IPv4 address", values: []string{"198.51.100.7"}, want: []netip.Prefix{netip.MustParsePrefix("198.51.100.7/32")}},
+		{name: "bare IPv6 address", values: []string{"2001:db8::1"}, want: []netip.Prefix{netip.MustParsePrefix("2001:db8::1/128")}},
+		{name: "IPv4-mapped CIDR", values: []string{"::ffff:203.0.113.0/120"}, want: []netip.Prefix{netip.MustParsePrefix("203.0.113.0/24")}},
+		{name: "IPv4-mapped address", values: []string{"::ffff:198.51.100.7"}, want: []netip.Prefix{netip.MustParsePrefix("198.51.100.7/32")}},
+		{name: "invalid address", values: []string{"203.0.113"}, wantErr: true},
+		{name: "invalid CIDR", values: []string{"203.0.113.0/33"}, wantErr: true},
+		{name: "one invalid entry among valid ones", values: []string{"203.0.113.0/24", "nope/8"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNetworks(tt.values)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseNetworks = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) || got[0] != tt.want[0] {
+				t.Fatalf("ParseNetworks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlockListCIDRMatching(t *testing.T) {
+	networks, err := ParseNetworks([]string{"203.0.113.0/24", "198.51.100.7", "2001:db8::/32"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := NewBlockListPolicy(BlockListConfig{Networks: networks})
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "203.0.113.0", want: true},
+		{ip: "203.0.113.255", want: true},
+		{ip: "203.0.114.1", want: false},
+		{ip: "198.51.100.7", want: true},
+		{ip: "198.51.100.8", want: false},
+		{ip: "::ffff:203.0.113.9", want: true},
+		{ip: "2001:db8:1::5", want: true},
+		{ip: "2001:db9::5", want: false},
+		{ip: "not an ip", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			rule, blocked := policy.Blocks(tt.ip, "/api/v1/products")
+			if blocked != tt.want {
+				t.Fatalf("Blocks(%q) = %v, want %v", tt.ip, blocked, tt.want)
+			}
+			if blocked && rule != "ip" {
+				t.Fatalf("rule = %q, want ip", rule)
+			}
+		})
+	}
+}
+
+func TestBlockListPathPrefixMatching(t *testing.T) {
+	policy := NewBlockListPolicy(BlockListConfig{PathPrefixes: []string{"/api/v1/reports", " api/v1/admin/ ", ""}})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/api/v1/reports", want: true},
+		{path: "/api/v1/reports/", want: true},
+		{path: "/api/v1/reports/revenue", want: true},
+		// Prefixes match whole segments
+		{path: "/api/v1/reportsx", want: false},
+		{path: "/api/v1/report", want: false},
+		// Prefixes are cleaned and rooted when loaded
+		{path: "/api/v1/admin/users", want: true},
+		// Paths are cleaned before matching
+		{path: "//api/v1/reports", want: true},
+		{path: "/api/v1/x/../reports/revenue", want: true},
+		{path: "/api/v1/./reports", want: true},
+		{path: "/api/v1/products", want: false},
+		{path: "/", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rule, blocked := policy.Blocks("192.0.2.1", tt.path)
+			if blocked != tt.want {
+				t.Fatalf("Blocks(%q) = %v, want %v", tt.path, blocked, tt.want)
+			}
+			if blocked && rule != "path" {
+				t.Fatalf("rule = %q, want path", rule)
+			}
+		})
+	}
+
+	if _, blocked := NewBlockListPolicy(BlockListConfig{PathPrefixes: []string{"/"}}).Blocks("192.0.2.1", "/anything"); !blocked {
+		t.Error("the / prefix does not block every path")
+	}
+}
+
+// blockedGateway serves every path behind BlockList and a rate limiter of one request per hour, as the router
+// orders them. It returns a func requesting path from ip.
+func blockedGateway(policy *BlockListPolicy) func(ip, path string) *httptest.ResponseRecorder {
+	limiter := NewRateLimiter(1, time.Hour)
+	engine := gin.New()
+	engine.Use(BlockList(policy), limiter.Middleware())
+	engine.NoRoute(func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	return func(ip, path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.RemoteAddr = ip + ":40000"
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+}
+
+func TestBlockList(t *testing.T) {
+	networks, err := ParseNetworks([]string{"203.0.113.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := blockedGateway(NewBlockListPolicy(BlockListConfig{Networks: networks, PathPrefixes: []string{"/internal"}}))
+
+	want := `{"code":403,"error":"Forbidden","message":"request blocked"}`
+	for _, request := range []struct{ ip, path string }{{"203.0.113.9", "/api/v1/products"}, {"192.0.2.1", "/internal/debug"}} {
+		if w := get(request.ip, request.path); w.Code != http.StatusForbidden || w.Body.String() != want {
+			t.Fatalf("%s from %s: got %d %s, want 403 %s", request.path, request.ip, w.Code, w.Body, want)
+		}
+	}
+}
+
+func TestBlockedRequestsDoNotUseTheRateLimit(t *testing.T) {
+	get := blockedGateway(NewBlockListPolicy(BlockListConfig{PathPrefixes: []string{"/internal"}}))
+
+	for range 3 {
+		if w := get("192.0.2.1", "/internal/debug"); w.Code != http.StatusForbidden {
+			t.Fatalf("blocked path: status = %d, want 403", w.Code)
+		}
+	}
+	// The limit allows one request, which the blocked ones did not use up
+	if w := get("192.0.2.1", "/api/v1/products"); w.Code != http.StatusOK {
+		t.Fatalf("first allowed request: status = %d, want 200", w.Code)
+	}
+	if w := get("192.0.2.1", "/api/v1/products"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second allowed request: status = %d, want 429", w.Code)
+	}
+}
+
+func TestBlockListUpdate(t *testing.T) {
+	policy := NewBlockListPolicy(BlockListConfig{PathPrefixes: []string{"/internal"}})
+	if _, blocked := policy.Blocks("192.0.2.1", "/api/v1/reports"); blocked {
+		t.Fatal("/api/v1/reports is blocked before the update")
+	}
+
+	policy.Update(BlockListConfig{PathPrefixes: []string{"/api/v1/reports"}})
+	if _, blocked := policy.Blocks("192.0.2.1", "/api/v1/reports"); !blocked {
+		t.Error("/api/v1/reports is not blocked after the update")
+	}
+	if _, blocked := policy.Blocks("192.0.2.1", "/internal"); blocked {
+		t.Error("/internal is still blocked after the update replaced it")
+	}
+}
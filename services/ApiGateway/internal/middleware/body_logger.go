@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+)
+
+const (
+	redactedValue = "[REDACTED]"
+	// maxCapturedResponse bounds the response copy; bodies are only truncated for logging after
+	// redaction, since a JSON body cut short cannot be parsed and redacted
+	maxCapturedResponse = 1 << 20
+)
+
+// BodyLoggerConfig selects which requests have their bodies logged and how
+type BodyLoggerConfig struct {
+	// Paths are matched exactly against the request path; nothing else is ever logged
+	Paths []string
+	// MaxBytes truncates each logged body
+	MaxBytes int
+	// RedactFields are JSON keys, matched case-insensitively at any depth, whose values are hidden
+	RedactFields []string
+}
+
+// bodyCaptureWriter keeps a copy of the first maxBytes written to the response
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	maxBytes int
+}
+
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+	if remaining := w.maxBytes - w.body.Len(); remaining > 0 {
+		w.body.Write(data[:min(len(data), remaining)])
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// BodyLogger logs request and response bodies at debug level for the configured paths only.
+// It is meant for debugging and should stay disabled unless a problem is being investigated.
+func BodyLogger(cfg BodyLoggerConfig) gin.HandlerFunc {
+	paths := make(map[string]struct{}, len(cfg.Paths))
+	for _, path := range cfg.Paths {
+		paths[path] = struct{}{}
+	}
+	redact := make(map[string]struct{}, len(cfg.RedactFields))
+	for _, field := range cfg.RedactFields {
+		redact[strings.ToLower(field)] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if _, ok := paths[c.Request.URL.Path]; !ok {
+			c.Next()
+			return
+		}
+
+		var requestBody []byte
+		if c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				writeJSONError(c, http.StatusBadRequest, "failed to read request body")
+				return
+			}
+			requestBody = body
+			// Handlers read the body again, so hand them a fresh reader over the same bytes
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, maxBytes: maxCapturedResponse}
+		c.Writer = writer
+
+		c.Next()
+
+		requestID, _ := c.Get("requestID")
+		logger.Debugf(
+			"event=http_body request_id=%v method=%s path=%s status=%d request_body=%s response_body=%s",
+			requestID,
+			c.Request.Method,
+			c.Request.URL.Path,
+			writer.Status(),
+			formatBody(requestBody, redact, cfg.MaxBytes),
+			formatBody(writer.body.Bytes(), redact, cfg.MaxBytes),
+		)
+	}
+}
+
+// formatBody redacts JSON bodies and truncates them to maxBytes. Anything that isn't JSON is
+// summarised by size only, because fields in it cannot be redacted reliably.
+func formatBody(body []byte, redact map[string]struct{}, maxBytes int) string {
+	if len(body) == 0 {
+		return "-"
+	}
+
+	var parsed any
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return fmt.Sprintf("[%d bytes, not JSON or truncated]", len(body))
+	}
+
+	redacted, err := json.Marshal(redactFields(parsed, redact))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes]", len(body))
+	}
+
+	if len(redacted) > maxBytes {
+		return string(redacted[:maxBytes]) + "...(truncated)"
+	}
+	return string(redacted)
+}
+
+func redactFields(value any, redact map[string]struct{}) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, nested := range v {
+			if _, ok := redact[strings.ToLower(key)]; ok {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactFields(nested, redact)
+		}
+	case []any:
+		for i, nested := range v {
+			v[i] = redactFields(nested, redact)
+		}
+	}
+	return value
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+)
+
+const clientIPKey contextKey = "clientIP"
+
+// ClientIP puts the client IP gin resolves through the trusted proxies into the request context, for
+// handlers outside gin and the audit log, and tags the request-scoped logger with it. It must run after
+// RequestID, which creates that logger.
+func ClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		ctx := context.WithValue(c.Request.Context(), clientIPKey, ip)
+		ctx = logger.IntoContext(ctx, logger.FromContext(ctx).With(slog.String("client_ip", ip)))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// GetClientIP retrieves the client IP ClientIP stored, or "" outside a request
+func GetClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
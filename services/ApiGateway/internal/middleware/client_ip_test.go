@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/audit"
+)
+
+// proxiedRequest is a request arriving from peer with the forwarding headers in header
+type proxiedRequest struct {
+	peer   string
+	header http.Header
+}
+
+// trustingEngine returns an engine trusting the proxies at trusted, as main configures it from TRUSTED_PROXIES
+func trustingEngine(t *testing.T, trusted []string, handlers ...gin.HandlerFunc) func(proxiedRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	engine := gin.New()
+	if err := engine.SetTrustedProxies(trusted); err != nil {
+		t.Fatal(err)
+	}
+	engine.POST("/", handlers...)
+
+	return func(req proxiedRequest) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = req.peer + ":40000"
+		for name, values := range req.header {
+			r.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+}
+
+func forwardedFor(value string) http.Header {
+	return http.Header{"X-Forwarded-For": {value}}
+}
+
+func TestRateLimiterKeysOnTheResolvedClientIP(t *testing.T) {
+	proxies := []string{"10.0.0.0/8"}
+	tests := []struct {
+		name          string
+		trusted       []string
+		first, second proxiedRequest
+		want          int
+	}{
+		{
+			name:   "spoofed X-Forwarded-For without trusted proxies",
+			first:  proxiedRequest{peer: "203.0.113.5", header: forwardedFor("198.51.100.1")},
+			second: proxiedRequest{peer: "203.0.113.5", header: forwardedFor("198.51.100.2")},
+			want:   http.StatusTooManyRequests,
+		},
+		{
+			name:   "spoofed X-Real-IP without trusted proxies",
+			first:  proxiedRequest{peer: "203.0.113.5", header: http.Header{"X-Real-Ip": {"198.51.100.1"}}},
+			second: proxiedRequest{peer: "203.0.113.5", header: http.Header{"X-Real-Ip": {"198.51.100.2"}}},
+			want:   http.StatusTooManyRequests,
+		},
+		{
+			name:    "spoofed X-Forwarded-For from a peer that is not a trusted proxy",
+			trusted: proxies,
+			first:   proxiedRequest{peer: "203.0.113.5", header: forwardedFor("198.51.100.1")},
+			second:  proxiedRequest{peer: "203.0.113.5", header: forwardedFor("198.51.100.2")},
+			want:    http.StatusTooManyRequests,
+		},
+		{
+			name:    "two clients behind a trusted proxy",
+			trusted: proxies,
+			first:   proxiedRequest{peer: "10.0.0.2", header: forwardedFor("198.51.100.1")},
+			second:  proxiedRequest{peer: "10.0.0.2", header: forwardedFor("198.51.100.2")},
+			want:    http.StatusOK,
+		},
+		{
+			name:    "two clients behind a trusted proxy sending X-Real-IP",
+			trusted: proxies,
+			first:   proxiedRequest{peer: "10.0.0.2", header: http.Header{"X-Real-Ip": {"198.51.100.1"}}},
+			second:  proxiedRequest{peer: "10.0.0.2", header: http.Header{"X-Real-Ip": {"198.51.100.2"}}},
+			want:    http.StatusOK,
+		},
+		{
+			// The client spoofs the first hop; the proxy appends the address it saw, which is the one used
+			name:    "spoofed hop in front of a trusted proxy",
+			trusted: proxies,
+			first:   proxiedRequest{peer: "10.0.0.2", header: forwardedFor("192.0.2.1, 198.51.100.9")},
+			second:  proxiedRequest{peer: "10.0.0.2", header: forwardedFor("192.0.2.2, 198.51.100.9")},
+			want:    http.StatusTooManyRequests,
+		},
+		{
+			name:    "same client through two trusted hops",
+			trusted: proxies,
+			first:   proxiedRequest{peer: "10.0.0.2", header: forwardedFor("198.51.100.9, 10.0.0.7")},
+			second:  proxiedRequest{peer: "10.0.0.3", header: forwardedFor("198.51.100.9")},
+			want:    http.StatusTooManyRequests,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(1, time.Hour)
+			send := trustingEngine(t, tt.trusted, limiter.Middleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			if w := send(tt.first); w.Code != http.StatusOK {
+				t.Fatalf("first request: status = %d, want 200", w.Code)
+			}
+			if w := send(tt.second); w.Code != tt.want {
+				t.Fatalf("second request: status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+// recordingSink keeps the audit entries recorded
+type recordingSink struct {
+	entries []audit.Entry
+}
+
+func (s *recordingSink) Record(entry audit.Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func TestClientIPReachesTheContextLogsAndAudit(t *testing.T) {
+	var logs bytes.Buffer
+	sink := &recordingSink{}
+	var contextIP string
+	send := trustingEngine(t, []string{"10.0.0.0/8"},
+		// Stands in for RequestID, which creates the request-scoped logger
+		func(c *gin.Context) {
+			l := slog.New(slog.NewJSONHandler(&logs, nil))
+			c.Request = c.Request.WithContext(logger.IntoContext(c.Request.Context(), l))
+		},
+		ClientIP(),
+		AuditLogger(sink, nil),
+		func(c *gin.Context) {
+			contextIP = GetClientIP(c.Request.Context())
+			logger.FromContext(c.Request.Context()).Info("handled")
+			c.Status(http.StatusOK)
+		},
+	)
+
+	send(proxiedRequest{peer: "10.0.0.2", header: forwardedFor("192.0.2.1, 198.51.100.9")})
+
+	if contextIP != "198.51.100.9" {
+		t.Errorf("GetClientIP = %q, want 198.51.100.9", contextIP)
+	}
+	var entry struct {
+		ClientIP string `json:"client_ip"`
+	}
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil || entry.ClientIP != "198.51.100.9" {
+		t.Errorf("log %s, want client_ip 198.51.100.9", logs.Bytes())
+	}
+	if len(sink.entries) != 1 || sink.entries[0].IP != "198.51.100.9" {
+		t.Errorf("audit entries = %+v, want one with ip 198.51.100.9", sink.entries)
+	}
+
+	// Without a trusted proxy the peer's own address is recorded, whatever it forwards
+	send(proxiedRequest{peer: "203.0.113.5", header: forwardedFor("198.51.100.9")})
+	if contextIP != "203.0.113.5" || len(sink.entries) != 2 || sink.entries[1].IP != "203.0.113.5" {
+		t.Errorf("from an untrusted peer: GetClientIP = %q and audit entries %+v, want 203.0.113.5", contextIP, sink.entries)
+	}
+}
+
+func TestGetClientIPOutsideARequest(t *testing.T) {
+	if ip := GetClientIP(httptest.NewRequest(http.MethodGet, "/", nil).Context()); ip != "" {
+		t.Errorf("GetClientIP = %q, want empty", ip)
+	}
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type streamClosingKey struct{}
+
+// ConnectionTracker lets shutdown wait for long-lived SSE and WebSocket responses. http.Server.Shutdown
+// only waits for idle connections, and never for hijacked ones, so without it streams are cut mid-frame.
+type ConnectionTracker struct {
+	streams   sync.WaitGroup
+	closing   chan struct{}
+	closeOnce sync.Once
+	// shuttingDown fails readiness ahead of Drain, while requests are still served
+	shuttingDown atomic.Bool
+
+	mu       sync.Mutex
+	draining bool
+	active   int
+	conns    map[net.Conn]http.ConnState
+}
+
+func NewConnectionTracker() *ConnectionTracker {
+	return &ConnectionTracker{
+		closing: make(chan struct{}),
+		conns:   make(map[net.Conn]http.ConnState),
+	}
+}
+
+// ConnState is meant for http.Server.ConnState. It keeps the state of every open connection so shutdown
+// can report what it is waiting for; hijacked connections are no longer the server's and are dropped.
+func (t *ConnectionTracker) ConnState(conn net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch state {
+	case http.StateClosed, http.StateHijacked:
+		delete(t.conns, conn)
+	default:
+		t.conns[conn] = state
+	}
+}
+
+// Stream marks a route as long-lived. Its requests are counted until the handler returns and can watch
+// StreamClosing to end cleanly on shutdown. Once draining has begun new streams are refused with 503.
+func (t *ConnectionTracker) Stream() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t.mu.Lock()
+		if t.draining {
+			t.mu.Unlock()
+			c.Header("Connection", "close")
+			writeJSONError(c, http.StatusServiceUnavailable, "server is shutting down")
+			return
+		}
+		t.active++
+		t.streams.Add(1)
+		t.mu.Unlock()
+
+		defer func() {
+			t.mu.Lock()
+			t.active--
+			t.mu.Unlock()
+			t.streams.Done()
+		}()
+
+		ctx := context.WithValue(c.Request.Context(), streamClosingKey{}, (<-chan struct{})(t.closing))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// StreamClosing returns a channel closed when the server wants the stream to end, so the handler can send
+// a final frame and return. It is nil, and never ready, outside routes marked with Stream.
+func StreamClosing(ctx context.Context) <-chan struct{} {
+	closing, _ := ctx.Value(streamClosingKey{}).(<-chan struct{})
+	return closing
+}
+
+// BeginShutdown makes ShuttingDown report true, so the readiness check tells load balancers to stop
+// sending new requests. Requests, streams included, are served as before until Drain.
+func (t *ConnectionTracker) BeginShutdown() {
+	t.shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether BeginShutdown has been called
+func (t *ConnectionTracker) ShuttingDown() bool {
+	return t.shuttingDown.Load()
+}
+
+// ActiveStreams returns the number of stream handlers still running
+func (t *ConnectionTracker) ActiveStreams() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.active
+}
+
+// OpenConnections returns the number of connections the server still holds, whatever their state
+func (t *ConnectionTracker) OpenConnections() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.conns)
+}
+
+// Drain refuses new streams and waits up to drainPeriod for open ones to finish on their own. Streams still
+// running are then signalled through StreamClosing and get until ctx is done to flush and return.
+// It returns the number of streams that did not finish in time.
+func (t *ConnectionTracker) Drain(ctx context.Context, drainPeriod time.Duration) int {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	// No stream can be added once draining is set, so Wait does not race with Add
+	done := make(chan struct{})
+	go func() {
+		t.streams.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(drainPeriod)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return 0
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	t.closeOnce.Do(func() { close(t.closing) })
+	select {
+	case <-done:
+		return 0
+	case <-ctx.Done():
+		return t.ActiveStreams()
+	}
+}
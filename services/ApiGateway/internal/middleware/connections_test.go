@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamingServer serves GET /events as an SSE stream tracked by tracker. The handler sends a tick every
+// 10ms and answers StreamClosing with a final closed event. When finishAfter is set it ends with a done event
+// after that long, and when ignoreClosing is set it pays no heed to StreamClosing and runs until release.
+func streamingServer(t *testing.T, tracker *ConnectionTracker, finishAfter time.Duration, ignoreClosing bool, release <-chan struct{}) *httptest.Server {
+	t.Helper()
+	engine := gin.New()
+	engine.GET("/events", tracker.Stream(), func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+
+		closing := StreamClosing(c.Request.Context())
+		if ignoreClosing {
+			closing = nil
+		}
+		var finished <-chan time.Time
+		if finishAfter > 0 {
+			finished = time.After(finishAfter)
+		}
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprint(c.Writer, "event: tick\ndata: {}\n\n")
+				c.Writer.Flush()
+			case <-closing:
+				fmt.Fprint(c.Writer, "event: closed\ndata: {}\n\n")
+				c.Writer.Flush()
+				return
+			case <-finished:
+				fmt.Fprint(c.Writer, "event: done\ndata: {}\n\n")
+				c.Writer.Flush()
+				return
+			case <-release:
+				return
+			case <-c.Request.Context().Done():
+				return
+			}
+		}
+	})
+
+	server := httptest.NewUnstartedServer(engine)
+	server.Config.ConnState = tracker.ConnState
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// streamClient reads the events of one stream in the background; lastEvent waits for the stream to end and
+// returns the name of the last event it carried
+type streamClient struct {
+	resp *http.Response
+	last chan string
+}
+
+func openStream(t *testing.T, url string) *streamClient {
+	t.Helper()
+	resp, err := http.Get(url + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	client := &streamClient{resp: resp, last: make(chan string, 1)}
+	go func() {
+		var last string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if event, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+				last = event
+			}
+		}
+		client.last <- last
+	}()
+	return client
+}
+
+func (c *streamClient) lastEvent(t *testing.T) string {
+	t.Helper()
+	select {
+	case event := <-c.last:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream was still open 5s later")
+		return ""
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDrainSignalsConcurrentStreams(t *testing.T) {
+	tracker := NewConnectionTracker()
+	server := streamingServer(t, tracker, 0, false, nil)
+
+	// The clients stay connected together, each streaming until shutdown
+	const streams = 5
+	clients := make([]*streamClient, streams)
+	for i := range clients {
+		clients[i] = openStream(t, server.URL)
+	}
+	waitFor(t, "every stream to be tracked", func() bool { return tracker.ActiveStreams() == streams })
+	if open := tracker.OpenConnections(); open != streams {
+		t.Errorf("open connections = %d, want %d", open, streams)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	if remaining := tracker.Drain(ctx, 50*time.Millisecond); remaining != 0 {
+		t.Fatalf("%d streams did not finish", remaining)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("the streams were signalled after %v, before the 50ms drain period", elapsed)
+	}
+
+	// Every client got the final frame rather than a cut connection
+	for i, client := range clients {
+		if event := client.lastEvent(t); event != "closed" {
+			t.Errorf("client %d: last event %q, want closed", i, event)
+		}
+	}
+	if active := tracker.ActiveStreams(); active != 0 {
+		t.Errorf("active streams = %d after draining", active)
+	}
+
+	// New streams are refused while the server shuts down
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("a stream opened while draining: status = %d, want 503", resp.StatusCode)
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	if err := server.Config.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown after draining: %v", err)
+	}
+	waitFor(t, "every connection to close", func() bool { return tracker.OpenConnections() == 0 })
+}
+
+func TestDrainWaitsForStreamsEndingOnTheirOwn(t *testing.T) {
+	tracker := NewConnectionTracker()
+	server := streamingServer(t, tracker, 100*time.Millisecond, false, nil)
+	clients := []*streamClient{openStream(t, server.URL), openStream(t, server.URL)}
+	waitFor(t, "both streams to be tracked", func() bool { return tracker.ActiveStreams() == 2 })
+
+	if remaining := tracker.Drain(context.Background(), 5*time.Second); remaining != 0 {
+		t.Fatalf("%d streams did not finish", remaining)
+	}
+	// Drain returned as the streams ended, without signalling them
+	for i, client := range clients {
+		if event := client.lastEvent(t); event != "done" {
+			t.Errorf("client %d: last event %q, want done", i, event)
+		}
+	}
+}
+
+func TestDrainReportsStreamsIgnoringTheSignal(t *testing.T) {
+	tracker := NewConnectionTracker()
+	release := make(chan struct{})
+	server := streamingServer(t, tracker, 0, true, release)
+	defer close(release)
+	openStream(t, server.URL)
+	waitFor(t, "the stream to be tracked", func() bool { return tracker.ActiveStreams() == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if remaining := tracker.Drain(ctx, 10*time.Millisecond); remaining != 1 {
+		t.Fatalf("Drain reported %d streams left, want the one ignoring the signal", remaining)
+	}
+}
+
+func TestStreamClosingOutsideStreams(t *testing.T) {
+	select {
+	case <-StreamClosing(context.Background()):
+		t.Fatal("StreamClosing is ready outside a stream")
+	default:
+	}
+}
@@ -2,31 +2,142 @@ package middleware
 
 import (
 	"net/http"
+	"regexp"
+	"slices"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 )
 
-// CORS middleware handles Cross-Origin Resource Sharing
-func CORS(allowedOrigins, allowedMethods, allowedHeaders []string) gin.HandlerFunc {
+// OriginMatcher decides which browser origins may call the gateway: the exact origins listed, every origin
+// when "*" is listed, or origins matching one of the patterns
+type OriginMatcher struct {
+	origins  []string
+	patterns []*regexp.Regexp
+	any      bool
+}
+
+// NewOriginMatcher compiles patterns once. Each must match the whole origin, e.g. `https://store\d+\.example\.com`;
+// a pattern that does not compile is logged and skipped rather than failing startup.
+func NewOriginMatcher(origins, patterns []string) *OriginMatcher {
+	m := &OriginMatcher{origins: origins, any: slices.Contains(origins, "*")}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			logger.Warnf("event=cors_origin_pattern_invalid pattern=%q error=%v", pattern, err)
+			continue
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m
+}
+
+// Matches reports whether origin is listed exactly or matches a pattern
+func (m *OriginMatcher) Matches(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	if slices.Contains(m.origins, origin) {
+		return true
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether origin may call the gateway
+func (m *OriginMatcher) Allows(origin string) bool {
+	return m.any || m.Matches(origin)
+}
+
+// CORSConfig configures a CORSPolicy
+type CORSConfig struct {
+	Origins        *OriginMatcher
+	AllowedMethods []string
+	AllowedHeaders []string
+	// AllowCredentials lets browsers send cookies and read responses to credentialed requests. Origins
+	// must then be listed or matched, as browsers refuse credentials with "*".
+	AllowCredentials bool
+}
+
+// CORSPolicy holds the CORS settings. Update swaps them while requests are being served, so a config reload
+// applies from the next request on.
+type CORSPolicy struct {
+	settings atomic.Pointer[corsSettings]
+}
+
+type corsSettings struct {
+	origins     *OriginMatcher
+	methods     string
+	headers     string
+	credentials bool
+}
+
+// NewCORSPolicy creates a policy with the settings of cfg
+func NewCORSPolicy(cfg CORSConfig) *CORSPolicy {
+	p := &CORSPolicy{}
+	p.Update(cfg)
+	return p
+}
+
+// Update replaces every setting of the policy at once
+func (p *CORSPolicy) Update(cfg CORSConfig) {
+	p.settings.Store(&corsSettings{
+		origins:     cfg.Origins,
+		methods:     joinStrings(cfg.AllowedMethods, ", "),
+		headers:     joinStrings(cfg.AllowedHeaders, ", "),
+		credentials: cfg.AllowCredentials,
+	})
+}
+
+// Allows reports whether origin may currently call the gateway
+func (p *CORSPolicy) Allows(origin string) bool {
+	return p.settings.Load().origins.Allows(origin)
+}
+
+// CORS middleware handles Cross-Origin Resource Sharing. Listed and matching origins are echoed back, and
+// any other origin gets "*" when it is allowed. Disallowed origins get no CORS headers, so the browser keeps
+// the response from them, and their preflights are refused with 403.
+func CORS(policy *CORSPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		origin := c.GetHeader("Origin")
+		settings := policy.settings.Load()
 
-		// Check if origin is allowed
-		allowedOrigin := "*"
-		for _, allowed := range allowedOrigins {
-			if allowed == "*" || allowed == origin {
-				allowedOrigin = allowed
-				break
+		// The answer depends on the Origin, so caches must not share it between origins
+		c.Writer.Header().Add("Vary", "Origin")
+
+		// Without an Origin the request is not cross-origin and needs no CORS headers
+		if origin != "" {
+			var allowOrigin string
+			switch {
+			case settings.origins.Matches(origin):
+				allowOrigin = origin
+			case settings.origins.any:
+				allowOrigin = "*"
+			default:
+				if c.Request.Method == http.MethodOptions {
+					writeJSONError(c, http.StatusForbidden, "origin not allowed")
+					return
+				}
+				c.Next()
+				return
 			}
-		}
 
-		// Set CORS headers
-		c.Writer.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-		c.Writer.Header().Set("Access-Control-Allow-Methods", joinStrings(allowedMethods, ", "))
-		c.Writer.Header().Set("Access-Control-Allow-Headers", joinStrings(allowedHeaders, ", "))
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
+			c.Writer.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+			c.Writer.Header().Set("Access-Control-Allow-Methods", settings.methods)
+			c.Writer.Header().Set("Access-Control-Allow-Headers", settings.headers)
+			// Scripts on other origins can only read the response headers listed here
+			c.Writer.Header().Set("Access-Control-Expose-Headers", GuestCartHeader+", "+CurrencyHeader+", Deprecation, Link")
+			// Browsers refuse credentials with "*", which Config.Validate rules out
+			if settings.credentials && allowOrigin != "*" {
+				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			c.Writer.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
+		}
 
 		// Handle preflight requests
 		if c.Request.Method == http.MethodOptions {
@@ -38,20 +149,6 @@ func CORS(allowedOrigins, allowedMethods, allowedHeaders []string) gin.HandlerFu
 	}
 }
 
-// Recovery middleware recovers from panics
-func Recovery() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		defer func() {
-			if err := recover(); err != nil {
-				logger.Errorf("panic recovered: %v", err)
-				writeJSONError(c, http.StatusInternalServerError, "internal server error")
-			}
-		}()
-
-		c.Next()
-	}
-}
-
 func joinStrings(strs []string, sep string) string {
 	if len(strs) == 0 {
 		return ""
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOriginMatcher(t *testing.T) {
+	matcher := NewOriginMatcher(
+		[]string{"https://shop.example.com"},
+		[]string{`https://store\d+\.example\.com`, `https://(`, `https://[a-z]+\.tenant\.io`},
+	)
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{origin: "https://shop.example.com", want: true},
+		{origin: "https://store1.example.com", want: true},
+		{origin: "https://store42.example.com", want: true},
+		{origin: "https://acme.tenant.io", want: true},
+		// Patterns match the whole origin, not a part of it
+		{origin: "https://store1.example.com.attacker.net", want: false},
+		{origin: "https://evil.com/https://store1.example.com", want: false},
+		{origin: "http://store1.example.com", want: false},
+		{origin: "https://storex.example.com", want: false},
+		{origin: "https://admin.example.com", want: false},
+		{origin: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := matcher.Allows(tt.origin); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestOriginMatcherSkipsInvalidPatterns(t *testing.T) {
+	matcher := NewOriginMatcher(nil, []string{`https://(`, `[`, `https://store\d+\.example\.com`})
+	if len(matcher.patterns) != 1 {
+		t.Fatalf("compiled %d patterns, want only the valid one", len(matcher.patterns))
+	}
+	if !matcher.Allows("https://store7.example.com") {
+		t.Error("the valid pattern stopped matching next to invalid ones")
+	}
+	if matcher.Allows("https://(") {
+		t.Error("an invalid pattern was matched as a literal origin")
+	}
+}
+
+// corsRoute serves GET / behind CORS with cfg, returning a func that requests it with method from origin
+func corsRoute(cfg CORSConfig) func(method, origin string) *httptest.ResponseRecorder {
+	engine := gin.New()
+	engine.Use(CORS(NewCORSPolicy(cfg)))
+	engine.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	return func(method, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+}
+
+func TestCORS(t *testing.T) {
+	listed := CORSConfig{
+		Origins:          NewOriginMatcher([]string{"https://shop.example.com"}, []string{`https://store\d+\.example\.com`}),
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowCredentials: true,
+	}
+	wildcard := CORSConfig{Origins: NewOriginMatcher([]string{"*"}, nil), AllowedMethods: []string{"GET"}, AllowCredentials: true}
+
+	tests := []struct {
+		name            string
+		cfg             CORSConfig
+		method          string
+		origin          string
+		wantStatus      int
+		wantOrigin      string
+		wantCredentials bool
+	}{
+		{name: "exact origin", cfg: listed, method: http.MethodGet, origin: "https://shop.example.com", wantStatus: http.StatusOK, wantOrigin: "https://shop.example.com", wantCredentials: true},
+		{name: "matching origin", cfg: listed, method: http.MethodGet, origin: "https://store3.example.com", wantStatus: http.StatusOK, wantOrigin: "https://store3.example.com", wantCredentials: true},
+		{name: "matching preflight", cfg: listed, method: http.MethodOptions, origin: "https://store3.example.com", wantStatus: http.StatusNoContent, wantOrigin: "https://store3.example.com", wantCredentials: true},
+		// The browser keeps the response from the page, so the request itself is served
+		{name: "other origin", cfg: listed, method: http.MethodGet, origin: "https://attacker.net", wantStatus: http.StatusOK},
+		{name: "other origin preflight", cfg: listed, method: http.MethodOptions, origin: "https://attacker.net", wantStatus: http.StatusForbidden},
+		{name: "same origin", cfg: listed, method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "wildcard", cfg: wildcard, method: http.MethodGet, origin: "https://attacker.net", wantStatus: http.StatusOK, wantOrigin: "*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := corsRoute(tt.cfg)(tt.method, tt.origin)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			header := w.Header()
+			if got := header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := header.Get("Access-Control-Allow-Credentials") == "true"; got != tt.wantCredentials {
+				t.Errorf("credentials allowed: %v, want %v", got, tt.wantCredentials)
+			}
+			if header.Get("Vary") != "Origin" {
+				t.Errorf("Vary = %q, want Origin", header.Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestCORSCredentialsAreOptIn(t *testing.T) {
+	origins := NewOriginMatcher([]string{"https://shop.example.com"}, nil)
+	for _, credentials := range []bool{false, true} {
+		for _, method := range []string{http.MethodGet, http.MethodOptions} {
+			w := corsRoute(CORSConfig{Origins: origins, AllowCredentials: credentials})(method, "https://shop.example.com")
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); (got == "true") != credentials {
+				t.Errorf("ALLOW_CREDENTIALS=%v, %s: Access-Control-Allow-Credentials = %q", credentials, method, got)
+			}
+		}
+	}
+}
+
+func TestCORSPreflightHeaders(t *testing.T) {
+	w := corsRoute(CORSConfig{
+		Origins:        NewOriginMatcher([]string{"https://shop.example.com"}, nil),
+		AllowedMethods: []string{"GET", "POST", "DELETE"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+	})(http.MethodOptions, "https://shop.example.com")
+
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Fatalf("got %d %q, want an empty 204", w.Code, w.Body)
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":  "https://shop.example.com",
+		"Access-Control-Allow-Methods": "GET, POST, DELETE",
+		"Access-Control-Allow-Headers": "Authorization, Content-Type",
+		"Access-Control-Max-Age":       "86400",
+		"Vary":                         "Origin",
+	}
+	for header, value := range want {
+		if got := w.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+}
+
+func TestCORSSendsNoHeadersToDisallowedOrigins(t *testing.T) {
+	w := corsRoute(CORSConfig{
+		Origins:          NewOriginMatcher([]string{"https://shop.example.com"}, nil),
+		AllowedMethods:   []string{"GET"},
+		AllowCredentials: true,
+	})(http.MethodGet, "https://attacker.net")
+
+	for header := range w.Header() {
+		if strings.HasPrefix(header, "Access-Control-") {
+			t.Errorf("a disallowed origin got %s: %q", header, w.Header().Get(header))
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const (
+	// CurrencyHeader asks for prices in an ISO 4217 currency, e.g. EUR. Responses name the currency they used
+	// in the same header.
+	CurrencyHeader = "X-Currency"
+
+	priceLocaleKey contextKey = "priceLocale"
+)
+
+// CurrencyConfig holds the currencies prices can be shown in
+type CurrencyConfig struct {
+	// Base is the currency the catalog's prices are in
+	Base currency.Unit
+	// Rates maps each other currency to the amount of it one unit of Base buys
+	Rates map[currency.Unit]float64
+}
+
+// CurrencyPolicy holds the exchange rates. Update swaps them while requests are being served, so a config
+// reload applies from the next request on.
+type CurrencyPolicy struct {
+	settings atomic.Pointer[CurrencyConfig]
+}
+
+// NewCurrencyPolicy creates a policy with the rates of cfg
+func NewCurrencyPolicy(cfg CurrencyConfig) *CurrencyPolicy {
+	p := &CurrencyPolicy{}
+	p.Update(cfg)
+	return p
+}
+
+// Update replaces the base currency and every rate at once
+func (p *CurrencyPolicy) Update(cfg CurrencyConfig) {
+	p.settings.Store(&cfg)
+}
+
+// ParseCurrencyRates parses a JSON object of exchange rates from the base currency, e.g. {"EUR":0.92,"GBP":0.79}.
+// Codes are returned upper-cased.
+func ParseCurrencyRates(raw string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	if raw == "" {
+		return rates, nil
+	}
+	var parsed map[string]float64
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("must be a JSON object of exchange rates: %w", err)
+	}
+	for code, rate := range parsed {
+		unit, err := currency.ParseISO(code)
+		if err != nil {
+			return nil, fmt.Errorf("unknown currency %q", code)
+		}
+		if rate <= 0 || math.IsInf(rate, 0) {
+			return nil, fmt.Errorf("rate of %s must be positive, got %v", code, rate)
+		}
+		rates[unit.String()] = rate
+	}
+	return rates, nil
+}
+
+// PriceLocale is the currency and language a request's prices are shown in
+type PriceLocale struct {
+	Base     currency.Unit
+	Currency currency.Unit
+	// Rate is the amount of Currency one unit of Base buys, 1 when Currency is Base
+	Rate     float64
+	Language language.Tag
+}
+
+// LocalPrice is a price of the base currency converted into a request's currency
+type LocalPrice struct {
+	Currency string  `json:"currency" example:"EUR"`
+	Amount   float64 `json:"amount" example:"1234.5"`
+	// Formatted is Amount written for Locale
+	Formatted    string  `json:"formatted" example:"€ 1.234,50"`
+	Locale       string  `json:"locale" example:"de-DE"`
+	BaseCurrency string  `json:"base_currency" example:"USD"`
+	Rate         float64 `json:"rate" example:"0.92"`
+}
+
+// Negotiate picks the currency of prices from requested, an ISO 4217 code, or without one from the region of
+// the preferred language in acceptLanguage, e.g. EUR for de-DE. A currency without a rate falls back to the
+// base currency. Amounts are formatted for the preferred language, English by default.
+func (p *CurrencyPolicy) Negotiate(requested, acceptLanguage string) PriceLocale {
+	cfg := p.settings.Load()
+	locale := PriceLocale{Base: cfg.Base, Currency: cfg.Base, Rate: 1, Language: language.English}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err == nil && len(tags) > 0 && tags[0] != language.Und {
+		locale.Language = tags[0]
+	}
+
+	var unit currency.Unit
+	ok := false
+	if requested = strings.TrimSpace(requested); requested != "" {
+		unit, err = currency.ParseISO(requested)
+		ok = err == nil
+	} else if region, confidence := locale.Language.Region(); confidence == language.Exact {
+		unit, ok = currency.FromRegion(region)
+	}
+	if rate, found := cfg.Rates[unit]; ok && found {
+		locale.Currency = unit
+		locale.Rate = rate
+	}
+	return locale
+}
+
+// Convert converts a price of the base currency, rounded to the currency's minor unit, e.g. cents or whole yen
+func (l PriceLocale) Convert(base float64) LocalPrice {
+	scale, _ := currency.Standard.Rounding(l.Currency)
+	pow := math.Pow10(scale)
+	amount := math.Round(base*l.Rate*pow) / pow
+	return LocalPrice{
+		Currency:     l.Currency.String(),
+		Amount:       amount,
+		Formatted:    message.NewPrinter(l.Language).Sprint(currency.Symbol(l.Currency.Amount(amount))),
+		Locale:       l.Language.String(),
+		BaseCurrency: l.Base.String(),
+		Rate:         l.Rate,
+	}
+}
+
+// NegotiatePrices picks the currency and language of the request's prices from CurrencyHeader and
+// Accept-Language, for handlers to read with GetPriceLocale. The response names the currency in CurrencyHeader
+// and varies on both request headers, so caches keep a copy per currency and language.
+func NegotiatePrices(policy *CurrencyPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := policy.Negotiate(c.GetHeader(CurrencyHeader), c.GetHeader("Accept-Language"))
+		c.Header(CurrencyHeader, locale.Currency.String())
+		c.Writer.Header().Add("Vary", "Accept-Language, "+CurrencyHeader)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), priceLocaleKey, locale))
+		c.Next()
+	}
+}
+
+// GetPriceLocale returns the currency and language NegotiatePrices picked for the request
+func GetPriceLocale(ctx context.Context) (PriceLocale, bool) {
+	locale, ok := ctx.Value(priceLocaleKey).(PriceLocale)
+	return locale, ok
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/currency"
+)
+
+// testCurrencies prices in USD with rates for EUR, GBP and JPY
+func testCurrencies() *CurrencyPolicy {
+	return NewCurrencyPolicy(CurrencyConfig{
+		Base:  currency.USD,
+		Rates: map[currency.Unit]float64{currency.EUR: 0.92, currency.GBP: 0.79, currency.JPY: 151.237},
+	})
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		requested      string
+		acceptLanguage string
+		want           LocalPrice
+	}{
+		{
+			name:      "supported currency",
+			requested: "EUR",
+			want:      LocalPrice{Currency: "EUR", Amount: 1135.8, Formatted: "€ 1,135.80", Locale: "en", BaseCurrency: "USD", Rate: 0.92},
+		},
+		{
+			name:      "lower-case code",
+			requested: "eur",
+			want:      LocalPrice{Currency: "EUR", Amount: 1135.8, Formatted: "€ 1,135.80", Locale: "en", BaseCurrency: "USD", Rate: 0.92},
+		},
+		{
+			name:      "unsupported currency",
+			requested: "CHF",
+			want:      LocalPrice{Currency: "USD", Amount: 1234.57, Formatted: "$ 1,234.57", Locale: "en", BaseCurrency: "USD", Rate: 1},
+		},
+		{
+			name:      "unknown code",
+			requested: "ABCD",
+			want:      LocalPrice{Currency: "USD", Amount: 1234.57, Formatted: "$ 1,234.57", Locale: "en", BaseCurrency: "USD", Rate: 1},
+		},
+		{
+			name:           "currency of the language's region",
+			acceptLanguage: "de-DE,en;q=0.8",
+			want:           LocalPrice{Currency: "EUR", Amount: 1135.8, Formatted: "€ 1.135,80", Locale: "de-DE", BaseCurrency: "USD", Rate: 0.92},
+		},
+		{
+			name:           "requested currency over the language's",
+			requested:      "GBP",
+			acceptLanguage: "de-DE",
+			want:           LocalPrice{Currency: "GBP", Amount: 975.31, Formatted: "£ 975,31", Locale: "de-DE", BaseCurrency: "USD", Rate: 0.79},
+		},
+		{
+			name:           "currency without minor units",
+			requested:      "JPY",
+			acceptLanguage: "ja-JP",
+			want:           LocalPrice{Currency: "JPY", Amount: 186712, Formatted: "￥ 186,712", Locale: "ja-JP", BaseCurrency: "USD", Rate: 151.237},
+		},
+		{
+			name:           "region without a rate",
+			acceptLanguage: "de-CH",
+			want:           LocalPrice{Currency: "USD", Amount: 1234.57, Formatted: "$ 1’234.57", Locale: "de-CH", BaseCurrency: "USD", Rate: 1},
+		},
+		{
+			name:           "unparseable language",
+			acceptLanguage: "garbage;;",
+			want:           LocalPrice{Currency: "USD", Amount: 1234.57, Formatted: "$ 1,234.57", Locale: "en", BaseCurrency: "USD", Rate: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testCurrencies().Negotiate(tt.requested, tt.acceptLanguage).Convert(1234.567)
+			if got != tt.want {
+				t.Errorf("price = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNegotiatePrices(t *testing.T) {
+	var locale PriceLocale
+	var ok bool
+	engine := gin.New()
+	engine.GET("/api/v1/products/:id", NegotiatePrices(testCurrencies()), func(c *gin.Context) {
+		locale, ok = GetPriceLocale(c.Request.Context())
+	})
+
+	tests := []struct {
+		name      string
+		requested string
+		want      currency.Unit
+	}{
+		{name: "supported", requested: "EUR", want: currency.EUR},
+		{name: "unsupported", requested: "CHF", want: currency.USD},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/products/1", nil)
+			r.Header.Set(CurrencyHeader, tt.requested)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, r)
+
+			if !ok || locale.Currency != tt.want {
+				t.Fatalf("locale = %+v, %t, want %v", locale, ok, tt.want)
+			}
+			// The response names the currency used, which is not always the one asked for
+			if got := w.Header().Get(CurrencyHeader); got != tt.want.String() {
+				t.Errorf("%s = %q, want %v", CurrencyHeader, got, tt.want)
+			}
+			if got := w.Header().Get("Vary"); got != "Accept-Language, X-Currency" {
+				t.Errorf("Vary = %q, want Accept-Language and X-Currency", got)
+			}
+		})
+	}
+}
+
+func TestCurrencyPolicyUpdate(t *testing.T) {
+	policy := testCurrencies()
+	policy.Update(CurrencyConfig{Base: currency.EUR, Rates: map[currency.Unit]float64{currency.USD: 1.09}})
+
+	if got := policy.Negotiate("GBP", "").Currency; got != currency.EUR {
+		t.Errorf("a currency no longer rated: got %v, want the new base EUR", got)
+	}
+	if got := policy.Negotiate("USD", "").Rate; got != 1.09 {
+		t.Errorf("rate of USD = %v, want the new 1.09", got)
+	}
+}
+
+func TestParseCurrencyRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    map[string]float64
+		wantErr string
+	}{
+		{name: "empty", raw: "", want: map[string]float64{}},
+		{name: "rates", raw: `{"EUR": 0.92, "gbp": 0.79}`, want: map[string]float64{"EUR": 0.92, "GBP": 0.79}},
+		{name: "not JSON", raw: `EUR=0.92`, wantErr: "must be a JSON object of exchange rates"},
+		{name: "unknown currency", raw: `{"EURO": 0.92}`, wantErr: `unknown currency "EURO"`},
+		{name: "zero rate", raw: `{"EUR": 0}`, wantErr: "rate of EUR must be positive"},
+		{name: "negative rate", raw: `{"EUR": -1}`, wantErr: "rate of EUR must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := ParseCurrencyRates(tt.raw)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseCurrencyRates = %v, %v, want an error containing %q", rates, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rates, tt.want) {
+				t.Errorf("rates = %v, want %v", rates, tt.want)
+			}
+		})
+	}
+}
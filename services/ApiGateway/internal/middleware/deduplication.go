@@ -0,0 +1,259 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	redisClient "github.com/kareemhamed001/e-commerce/pkg/redis"
+	"github.com/redis/go-redis/v9"
+)
+
+const dedupKeyPrefix = "dedup:"
+
+// DeduplicatedResponse is a response kept to answer repeats of the request that produced it
+type DeduplicatedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// DeduplicationStore keeps the responses of recent requests by key
+type DeduplicationStore interface {
+	// Begin claims key for a request about to run, for at most ttl. When key is already claimed it returns
+	// false with the response stored under it, or a nil response while the request holding it still runs.
+	Begin(ctx context.Context, key string, ttl time.Duration) (claimed bool, response *DeduplicatedResponse, err error)
+	// Complete stores the response of the request that claimed key, replacing the claim, for ttl
+	Complete(ctx context.Context, key string, response *DeduplicatedResponse, ttl time.Duration) error
+	// Abandon drops the claim on key without a response, so the request may be sent again
+	Abandon(ctx context.Context, key string) error
+}
+
+type dedupEntry struct {
+	response *DeduplicatedResponse
+	expires  time.Time
+}
+
+// MemoryDeduplicationStore keeps responses in this instance only
+type MemoryDeduplicationStore struct {
+	mu      sync.Mutex
+	entries map[string]dedupEntry
+}
+
+var _ DeduplicationStore = (*MemoryDeduplicationStore)(nil)
+
+func NewMemoryDeduplicationStore() *MemoryDeduplicationStore {
+	return &MemoryDeduplicationStore{entries: make(map[string]dedupEntry)}
+}
+
+func (s *MemoryDeduplicationStore) Begin(_ context.Context, key string, ttl time.Duration) (bool, *DeduplicatedResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, k)
+		}
+	}
+	if entry, ok := s.entries[key]; ok {
+		return false, entry.response, nil
+	}
+	s.entries[key] = dedupEntry{expires: now.Add(ttl)}
+	return true, nil, nil
+}
+
+func (s *MemoryDeduplicationStore) Complete(_ context.Context, key string, response *DeduplicatedResponse, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = dedupEntry{response: response, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryDeduplicationStore) Abandon(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// RedisDeduplicationStore shares responses between gateway instances through Redis, so a repeat is caught
+// whichever instance it reaches. Without Redis it falls back to a MemoryDeduplicationStore.
+type RedisDeduplicationStore struct {
+	cache *redisClient.Client
+	local *MemoryDeduplicationStore
+}
+
+var _ DeduplicationStore = (*RedisDeduplicationStore)(nil)
+
+func NewRedisDeduplicationStore(cache *redisClient.Client) *RedisDeduplicationStore {
+	return &RedisDeduplicationStore{cache: cache, local: NewMemoryDeduplicationStore()}
+}
+
+func (s *RedisDeduplicationStore) enabled() bool {
+	return s.cache != nil && s.cache.IsEnabled()
+}
+
+func (s *RedisDeduplicationStore) Begin(ctx context.Context, key string, ttl time.Duration) (bool, *DeduplicatedResponse, error) {
+	if !s.enabled() {
+		return s.local.Begin(ctx, key, ttl)
+	}
+
+	// A claim is an empty value, which Complete replaces with the response
+	claimed, err := s.cache.SetNX(ctx, dedupKeyPrefix+key, "", ttl).Result()
+	if err != nil || claimed {
+		return claimed, nil, err
+	}
+	value, err := s.cache.Get(ctx, dedupKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) || (err == nil && len(value) == 0) {
+		// Still running, or it expired in between; either way this request must not run as well
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+	var response DeduplicatedResponse
+	if err := json.Unmarshal(value, &response); err != nil {
+		return false, nil, err
+	}
+	return false, &response, nil
+}
+
+func (s *RedisDeduplicationStore) Complete(ctx context.Context, key string, response *DeduplicatedResponse, ttl time.Duration) error {
+	if !s.enabled() {
+		return s.local.Complete(ctx, key, response, ttl)
+	}
+	value, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, dedupKeyPrefix+key, value, ttl).Err()
+}
+
+func (s *RedisDeduplicationStore) Abandon(ctx context.Context, key string) error {
+	if !s.enabled() {
+		return s.local.Abandon(ctx, key)
+	}
+	return s.cache.Del(ctx, dedupKeyPrefix+key).Err()
+}
+
+// maxDedupBodyBytes bounds the body DeduplicationKey reads into memory to hash
+const maxDedupBodyBytes = 1 << 20
+
+// DeduplicationKey returns a hasher identifying a request to route by method, caller and body: the user ID
+// on authenticated routes, else the client IP. route names the endpoint rather than its URL path, so a route
+// also served under an old path shares its keys with it. The hasher answers 413 for a body over
+// maxDedupBodyBytes, and returns "" when the body cannot be read.
+func DeduplicationKey(route string) func(*gin.Context) string {
+	return func(c *gin.Context) string {
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			if body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxDedupBodyBytes)); err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					writeJSONError(c, http.StatusRequestEntityTooLarge, "request body must be at most "+strconv.FormatInt(tooLarge.Limit, 10)+" bytes")
+				}
+				return ""
+			}
+			// The handler reads the body again, so hand it a fresh reader over the same bytes
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		caller := c.ClientIP()
+		if userID, ok := GetUserID(c.Request.Context()); ok {
+			caller = "user:" + strconv.FormatUint(uint64(userID), 10)
+		}
+		bodySum := sha256.Sum256(body)
+
+		key := sha256.New()
+		for _, part := range []string{c.Request.Method, route, caller, hex.EncodeToString(bodySum[:])} {
+			key.Write([]byte(part))
+			key.Write([]byte{0})
+		}
+		return hex.EncodeToString(key.Sum(nil))
+	}
+}
+
+// Deduplication answers a request repeated within ttl with the response to the first one instead of running
+// it again, e.g. an order a client resent before the first response reached it. Requests are told apart by
+// hasher, such as DeduplicationKey; an empty key skips deduplication, unless the hasher aborted the request. A repeat that arrives while the first
+// is still running gets 409. Only 2xx responses are kept, so a failed request may be retried. When the
+// store fails the request runs as usual. On authenticated routes it goes after withAuth.
+func Deduplication(store DeduplicationStore, ttl time.Duration, hasher func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := hasher(c)
+		if key == "" {
+			// The hasher may have answered the request itself, e.g. 413 for a body too large to hash
+			if !c.IsAborted() {
+				c.Next()
+			}
+			return
+		}
+
+		ctx := c.Request.Context()
+		claimed, response, err := store.Begin(ctx, key, ttl)
+		if err != nil {
+			logger.Warnf("event=dedup_check_failed path=%s error=%v", c.Request.URL.Path, err)
+			c.Next()
+			return
+		}
+		if !claimed {
+			if response == nil {
+				c.Header("Retry-After", "1")
+				writeJSONError(c, http.StatusConflict, "an identical request is still being processed")
+				return
+			}
+			logger.Infof("event=dedup_replay path=%s status=%d", c.Request.URL.Path, response.Status)
+			c.Header("X-Deduplicated", "true")
+			c.Data(response.Status, response.ContentType, response.Body)
+			c.Abort()
+			return
+		}
+
+		// The request context may be canceled by the time the claim is settled
+		ctx = context.WithoutCancel(ctx)
+		abandon := func() {
+			if err := store.Abandon(ctx, key); err != nil {
+				logger.Warnf("event=dedup_abandon_failed path=%s error=%v", c.Request.URL.Path, err)
+			}
+		}
+		finished := false
+		// A panicking handler must not leave repeats answered 409 until the claim expires
+		defer func() {
+			if !finished {
+				abandon()
+			}
+		}()
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, maxBytes: maxCapturedResponse}
+		c.Writer = writer
+
+		c.Next()
+		finished = true
+
+		status := writer.Status()
+		if status < 200 || status >= 300 || writer.body.Len() < writer.Size() {
+			abandon()
+			return
+		}
+		err = store.Complete(ctx, key, &DeduplicatedResponse{
+			Status:      status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        bytes.Clone(writer.body.Bytes()),
+		}, ttl)
+		if err != nil {
+			logger.Warnf("event=dedup_store_failed path=%s error=%v", c.Request.URL.Path, err)
+		}
+	}
+}
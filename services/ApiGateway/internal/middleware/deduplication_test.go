@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+)
+
+// dedupRoute serves POST /orders, under its old path /orders/create as well, and /carts behind Deduplication
+// with a memory store, answering with handle. It returns a func that posts body to path as userID, or
+// anonymously when userID is 0.
+func dedupRoute(ttl time.Duration, handle gin.HandlerFunc) func(path, body string, userID uint) *httptest.ResponseRecorder {
+	engine := gin.New()
+	engine.Use(gin.CustomRecovery(func(c *gin.Context, _ any) { c.AbortWithStatus(http.StatusInternalServerError) }))
+	store := NewMemoryDeduplicationStore()
+	createOrder := Deduplication(store, ttl, DeduplicationKey("create_order"))
+	engine.POST("/orders", createOrder, handle)
+	engine.POST("/orders/create", createOrder, handle)
+	engine.POST("/carts", Deduplication(store, ttl, DeduplicationKey("create_cart")), handle)
+
+	return func(path, body string, userID uint) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if userID != 0 {
+			r = r.WithContext(withClaims(r.Context(), &customJWT.UserClaims{UserID: userID}))
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+}
+
+// createdOrder answers 201 with the body it was sent and the number of its call, counted in calls
+func createdOrder(calls *atomic.Int32) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		n := calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{"call": n, "body": string(body)})
+	}
+}
+
+func TestDeduplication(t *testing.T) {
+	type request struct {
+		path   string
+		body   string
+		userID uint
+	}
+	tests := []struct {
+		name      string
+		second    request
+		wantCalls int32
+	}{
+		{name: "repeat", second: request{path: "/orders", body: `{"items":[1]}`, userID: 7}, wantCalls: 1},
+		{name: "other body", second: request{path: "/orders", body: `{"items":[2]}`, userID: 7}, wantCalls: 2},
+		{name: "other user", second: request{path: "/orders", body: `{"items":[1]}`, userID: 8}, wantCalls: 2},
+		{name: "old path of the route", second: request{path: "/orders/create", body: `{"items":[1]}`, userID: 7}, wantCalls: 1},
+		{name: "other route", second: request{path: "/carts", body: `{"items":[1]}`, userID: 7}, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			post := dedupRoute(time.Minute, createdOrder(&calls))
+
+			first := post("/orders", `{"items":[1]}`, 7)
+			second := post(tt.second.path, tt.second.body, tt.second.userID)
+			if first.Code != http.StatusCreated || second.Code != http.StatusCreated {
+				t.Fatalf("statuses %d and %d, want 201 twice", first.Code, second.Code)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Fatalf("the handler ran %d times, want %d", calls.Load(), tt.wantCalls)
+			}
+			// Hashing reads the body, which the handler must still get
+			if !strings.Contains(first.Body.String(), `"body":"{\"items\":[1]}"`) {
+				t.Errorf("first response %s, want the handler to have read the body", first.Body)
+			}
+
+			replayed := second.Header().Get("X-Deduplicated") == "true"
+			if replayed != (tt.wantCalls == 1) {
+				t.Errorf("X-Deduplicated = %q", second.Header().Get("X-Deduplicated"))
+			}
+			if replayed && (second.Body.String() != first.Body.String() || second.Header().Get("Content-Type") != first.Header().Get("Content-Type")) {
+				t.Errorf("replayed %q %s, want the first response %q %s",
+					second.Header().Get("Content-Type"), second.Body, first.Header().Get("Content-Type"), first.Body)
+			}
+		})
+	}
+}
+
+func TestDeduplicationRefusesABodyTooLargeToHash(t *testing.T) {
+	var calls atomic.Int32
+	post := dedupRoute(time.Minute, createdOrder(&calls))
+
+	w := post("/orders", `{"note":"`+strings.Repeat("x", maxDedupBodyBytes)+`"}`, 7)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", w.Code)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("the handler ran %d times for a body too large, want never", calls.Load())
+	}
+}
+
+func TestDeduplicationLetsFailedRequestsBeRetried(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle func(c *gin.Context, call int32)
+	}{
+		{name: "error response", handle: func(c *gin.Context, call int32) {
+			if call == 1 {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "order service unavailable"})
+				return
+			}
+			c.Status(http.StatusCreated)
+		}},
+		{name: "panic", handle: func(c *gin.Context, call int32) {
+			if call == 1 {
+				panic("order handler broke")
+			}
+			c.Status(http.StatusCreated)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			post := dedupRoute(time.Minute, func(c *gin.Context) { tt.handle(c, calls.Add(1)) })
+
+			if w := post("/orders", `{"items":[1]}`, 7); w.Code < 500 {
+				t.Fatalf("first attempt: status = %d, want a failure", w.Code)
+			}
+			if w := post("/orders", `{"items":[1]}`, 7); w.Code != http.StatusCreated || w.Header().Get("X-Deduplicated") != "" {
+				t.Fatalf("retry: status = %d, X-Deduplicated = %q, want the request run again", w.Code, w.Header().Get("X-Deduplicated"))
+			}
+			if calls.Load() != 2 {
+				t.Errorf("the handler ran %d times, want 2", calls.Load())
+			}
+		})
+	}
+}
+
+func TestDeduplicationRefusesARepeatWhileTheFirstRuns(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls atomic.Int32
+	post := dedupRoute(time.Minute, func(c *gin.Context) {
+		calls.Add(1)
+		close(started)
+		<-release
+		c.Status(http.StatusCreated)
+	})
+
+	first := make(chan int)
+	go func() { first <- post("/orders", `{"items":[1]}`, 7).Code }()
+	<-started
+
+	w := post("/orders", `{"items":[1]}`, 7)
+	if w.Code != http.StatusConflict || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("repeat while the first runs: status = %d, Retry-After = %q, want 409 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+	close(release)
+	if code := <-first; code != http.StatusCreated {
+		t.Errorf("first request: status = %d, want 201", code)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("the handler ran %d times, want once", calls.Load())
+	}
+}
+
+func TestDeduplicationExpires(t *testing.T) {
+	var calls atomic.Int32
+	post := dedupRoute(50*time.Millisecond, createdOrder(&calls))
+
+	post("/orders", `{"items":[1]}`, 7)
+	time.Sleep(100 * time.Millisecond)
+	if w := post("/orders", `{"items":[1]}`, 7); w.Header().Get("X-Deduplicated") != "" || calls.Load() != 2 {
+		t.Fatalf("after the TTL: the handler ran %d times, X-Deduplicated = %q; want the request run again", calls.Load(), w.Header().Get("X-Deduplicated"))
+	}
+}
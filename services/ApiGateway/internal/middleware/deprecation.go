@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecated marks the responses of a route's old path, kept for a release after the route moved to
+// successor, with a Deprecation header and a Link to successor. A :name segment of successor is filled in
+// from the request's name query parameter when it sends one, and otherwise left as the {name} template.
+func Deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successorURL(successor, c.Request.URL.Query())+">; rel=\"successor-version\"")
+		c.Next()
+	}
+}
+
+func successorURL(successor string, query url.Values) string {
+	segments := strings.Split(successor, "/")
+	for i, segment := range segments {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok {
+			continue
+		}
+		if value := query.Get(name); value != "" {
+			segments[i] = url.PathEscape(value)
+		} else {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDeprecated(t *testing.T) {
+	tests := []struct {
+		name      string
+		successor string
+		target    string
+		wantLink  string
+	}{
+		{name: "no parameters", successor: "/api/v1/users", target: "/old", wantLink: `</api/v1/users>; rel="successor-version"`},
+		{name: "ID in the query", successor: "/api/v1/products/:id", target: "/old?id=42", wantLink: `</api/v1/products/42>; rel="successor-version"`},
+		{name: "ID in the body", successor: "/api/v1/products/:id", target: "/old", wantLink: `</api/v1/products/{id}>; rel="successor-version"`},
+		{name: "ID escaped", successor: "/api/v1/products/:id", target: "/old?id=a%2Fb", wantLink: `</api/v1/products/a%2Fb>; rel="successor-version"`},
+		{name: "one of two IDs", successor: "/api/v1/orders/:id/items/:itemID", target: "/old?id=7", wantLink: `</api/v1/orders/7/items/{itemID}>; rel="successor-version"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.GET("/old", Deprecated(tt.successor), func(c *gin.Context) { c.Status(http.StatusOK) })
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want the handler's 200", w.Code)
+			}
+			if got := w.Header().Get("Deprecation"); got != "true" {
+				t.Errorf("Deprecation = %q, want true", got)
+			}
+			if got := w.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("Link = %s, want %s", got, tt.wantLink)
+			}
+		})
+	}
+}
+
+func TestPathParams(t *testing.T) {
+	var id, itemID, other string
+	var okID, okOther bool
+	engine := gin.New()
+	engine.DELETE("/api/v1/orders/:id/items/:itemID", PathParams(), gin.WrapF(func(_ http.ResponseWriter, r *http.Request) {
+		id, okID = GetPathParam(r.Context(), "id")
+		itemID, _ = GetPathParam(r.Context(), "itemID")
+		other, okOther = GetPathParam(r.Context(), "userId")
+	}))
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/api/v1/orders/7/items/9", nil))
+
+	if !okID || id != "7" || itemID != "9" {
+		t.Errorf("id = %q, %t and itemID = %q, want 7 and 9", id, okID, itemID)
+	}
+	if okOther {
+		t.Errorf("userId = %q, want none", other)
+	}
+	if _, ok := GetPathParam(httptest.NewRequest(http.MethodGet, "/", nil).Context(), "id"); ok {
+		t.Error("a request PathParams did not see has an id")
+	}
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+)
+
+// gatedRoute serves GET /api/v1/wishlist behind FeatureGate, returning a func that requests it as userID, or
+// anonymously from remoteIP when userID is 0
+func gatedRoute(store FeatureFlagStore) func(userID uint, remoteIP string) *httptest.ResponseRecorder {
+	engine := gin.New()
+	engine.GET("/api/v1/wishlist", FeatureGate(store, "wishlist"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	return func(userID uint, remoteIP string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/wishlist", nil)
+		r.RemoteAddr = remoteIP + ":40000"
+		if userID != 0 {
+			r = r.WithContext(withClaims(r.Context(), &customJWT.UserClaims{UserID: userID}))
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+}
+
+func TestFeatureGate(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    map[string]FeatureFlag
+		userID   uint
+		remoteIP string
+		want     int
+	}{
+		{name: "enabled", flags: map[string]FeatureFlag{"wishlist": {Enabled: true}}, userID: 7, want: http.StatusOK},
+		{name: "enabled for anonymous callers", flags: map[string]FeatureFlag{"wishlist": {Enabled: true}}, remoteIP: "203.0.113.9", want: http.StatusOK},
+		{name: "disabled", flags: map[string]FeatureFlag{"wishlist": {}}, userID: 7, want: http.StatusNotFound},
+		{name: "unknown flag", flags: map[string]FeatureFlag{"reviews_v2": {Enabled: true}}, userID: 7, want: http.StatusNotFound},
+		{name: "listed user", flags: map[string]FeatureFlag{"wishlist": {Users: []string{"42"}}}, userID: 42, want: http.StatusOK},
+		{name: "unlisted user", flags: map[string]FeatureFlag{"wishlist": {Users: []string{"42"}}}, userID: 7, want: http.StatusNotFound},
+		{name: "listed IP", flags: map[string]FeatureFlag{"wishlist": {Users: []string{"203.0.113.9"}}}, remoteIP: "203.0.113.9", want: http.StatusOK},
+		{name: "unlisted IP", flags: map[string]FeatureFlag{"wishlist": {Users: []string{"203.0.113.9"}}}, remoteIP: "198.51.100.1", want: http.StatusNotFound},
+		// Signed-in callers are identified by their user ID, not by IP
+		{name: "user on a listed IP", flags: map[string]FeatureFlag{"wishlist": {Users: []string{"203.0.113.9"}}}, userID: 7, remoteIP: "203.0.113.9", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remoteIP := tt.remoteIP
+			if remoteIP == "" {
+				remoteIP = "192.0.2.1"
+			}
+			w := gatedRoute(NewStaticFeatureFlagStore(tt.flags))(tt.userID, remoteIP)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want == http.StatusNotFound && w.Body.String() != "404 page not found" {
+				t.Errorf("body = %q, want gin's own 404", w.Body)
+			}
+		})
+	}
+}
+
+func TestFeatureFlagPercent(t *testing.T) {
+	flag := FeatureFlag{Percent: 25}
+	enabled := 0
+	for id := range 1000 {
+		userID := strconv.Itoa(id)
+		got := flag.EnabledFor("wishlist", userID)
+		if got != flag.EnabledFor("wishlist", userID) {
+			t.Fatalf("user %s was placed differently on a second call", userID)
+		}
+		if got {
+			enabled++
+		}
+	}
+	if enabled < 200 || enabled > 300 {
+		t.Errorf("%d of 1000 users got a 25%% feature", enabled)
+	}
+
+	if (FeatureFlag{Percent: 100}).EnabledFor("wishlist", "") {
+		t.Error("a caller without an ID got a percent rollout")
+	}
+	for _, id := range []string{"1", "7", "42"} {
+		if !(FeatureFlag{Percent: 100}).EnabledFor("wishlist", id) {
+			t.Errorf("user %s missed a 100%% rollout", id)
+		}
+	}
+}
+
+func TestParseFeatureFlags(t *testing.T) {
+	flags, err := ParseFeatureFlags(`{"wishlist": true, "gift_cards": false, "reviews_v2": {"users": ["42"], "percent": 10}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !flags["wishlist"].Enabled || flags["gift_cards"].Enabled {
+		t.Errorf("flags = %+v, want wishlist on and gift_cards off", flags)
+	}
+	if reviews := flags["reviews_v2"]; reviews.Enabled || len(reviews.Users) != 1 || reviews.Users[0] != "42" || reviews.Percent != 10 {
+		t.Errorf("reviews_v2 = %+v, want off but for user 42 and 10%%", reviews)
+	}
+
+	if flags, err := ParseFeatureFlags(""); err != nil || len(flags) != 0 {
+		t.Errorf("ParseFeatureFlags(\"\") = %v, %v, want no flags", flags, err)
+	}
+	for _, raw := range []string{`["wishlist"]`, `{"wishlist": "yes"}`, `{"wishlist": {"percent": 101}}`, `{"wishlist": {"percent": -1}}`} {
+		if _, err := ParseFeatureFlags(raw); err == nil {
+			t.Errorf("ParseFeatureFlags(%s) succeeded", raw)
+		}
+	}
+}
+
+func TestRedisFeatureFlagStoreFallsBackWithoutRedis(t *testing.T) {
+	store := NewRedisFeatureFlagStore(nil, NewStaticFeatureFlagStore(map[string]FeatureFlag{"wishlist": {Users: []string{"42"}}}))
+	if !store.IsEnabled("wishlist", "42") || store.IsEnabled("wishlist", "7") || store.IsEnabled("reviews_v2", "42") {
+		t.Error("without Redis the store does not answer as its configured flags")
+	}
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// GuestCartHeader carries an anonymous visitor's cart token, for clients that do not keep cookies
+	GuestCartHeader = "X-Guest-Cart-Token"
+	// GuestCartCookie carries the same token for browsers
+	GuestCartCookie = "guest_cart"
+	// guestCartCookiePath covers the cart routes and the sign-in routes that merge the cart
+	guestCartCookiePath = "/api/v1"
+)
+
+// GuestCarts issues and checks the tokens naming anonymous visitors' carts. A token is a random cart ID and
+// its HMAC-SHA256 under the gateway's secret, so visitors cannot make up IDs of their own; the cart service
+// only ever sees the ID.
+type GuestCarts struct {
+	secret       []byte
+	ttl          time.Duration
+	secureCookie bool
+}
+
+// NewGuestCarts signs tokens with secret. ttl is how long the cookie lasts, which should match how long the
+// cart service keeps guest carts; secureCookie limits the cookie to HTTPS.
+func NewGuestCarts(secret string, ttl time.Duration, secureCookie bool) *GuestCarts {
+	return &GuestCarts{
+		secret:       []byte(secret),
+		ttl:          ttl,
+		secureCookie: secureCookie,
+	}
+}
+
+// NewCartID creates the ID of a new guest cart
+func (g *GuestCarts) NewCartID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate guest cart ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CartID returns the cart ID of the token the request sends in GuestCartHeader or, without one, in
+// GuestCartCookie. Tokens with a bad signature are ignored.
+func (g *GuestCarts) CartID(r *http.Request) (string, bool) {
+	token := r.Header.Get(GuestCartHeader)
+	if token == "" {
+		cookie, err := r.Cookie(GuestCartCookie)
+		if err != nil {
+			return "", false
+		}
+		token = cookie.Value
+	}
+
+	cartID, signature, ok := strings.Cut(token, ".")
+	if !ok || cartID == "" {
+		return "", false
+	}
+	expected, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, g.sign(cartID)) {
+		return "", false
+	}
+	return cartID, true
+}
+
+// Set hands the visitor the token of cartID, in GuestCartHeader and in a cookie. Setting it again on every
+// change to the cart keeps the cookie alive as long as the cart.
+func (g *GuestCarts) Set(w http.ResponseWriter, cartID string) {
+	token := cartID + "." + base64.RawURLEncoding.EncodeToString(g.sign(cartID))
+	w.Header().Set(GuestCartHeader, token)
+	http.SetCookie(w, g.cookie(token, int(g.ttl.Seconds())))
+}
+
+// Clear deletes the cookie, once the cart is merged into a user's
+func (g *GuestCarts) Clear(w http.ResponseWriter) {
+	http.SetCookie(w, g.cookie("", -1))
+}
+
+func (g *GuestCarts) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     GuestCartCookie,
+		Value:    value,
+		Path:     guestCartCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   g.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func (g *GuestCarts) sign(cartID string) []byte {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(cartID))
+	return mac.Sum(nil)
+}
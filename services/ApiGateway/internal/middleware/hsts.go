@@ -0,0 +1,15 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// strictTransportSecurity tells browsers to reach the gateway's host over HTTPS only, for a year
+const strictTransportSecurity = "max-age=31536000"
+
+// HSTS sets Strict-Transport-Security on every response. It belongs only on a gateway that terminates TLS
+// itself: browsers ignore the header over plain HTTP, and behind a TLS proxy the proxy should send it.
+func HSTS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Strict-Transport-Security", strictTransportSecurity)
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/audit"
+)
+
+// ImpersonationAudit records every request made with an impersonation token in the audit log, under the
+// admin acting as the user. It goes before the routes' auth middleware and checks the claims it left once
+// the request is done, so refused requests are recorded too.
+func ImpersonationAudit(auditLog audit.Sink) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		claims, ok := GetUserClaims(c.Request.Context())
+		if !ok || !claims.Impersonated() {
+			return
+		}
+
+		status := c.Writer.Status()
+		outcome := "success"
+		if status >= http.StatusBadRequest {
+			outcome = "failure"
+		}
+		err := auditLog.Record(audit.Entry{
+			Event:   "impersonated_request",
+			UserID:  claims.ImpersonatedBy,
+			IP:      GetClientIP(c.Request.Context()),
+			Outcome: outcome,
+			Details: map[string]any{
+				"impersonated_user_id": claims.UserID,
+				"method":               c.Request.Method,
+				"path":                 c.Request.URL.Path,
+				"status":               status,
+			},
+		})
+		if err != nil {
+			logger.Errorf("event=audit_write_failed user_id=%d outcome=%s error=%v", claims.ImpersonatedBy, outcome, err)
+		}
+	}
+}
+
+// RejectImpersonation refuses impersonation tokens on routes support staff must not use as the user, such
+// as changing their email or deleting their account. It goes after AuthMiddleware.
+func RejectImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetUserClaims(c.Request.Context())
+		if ok && claims.Impersonated() {
+			writeJSONError(c, http.StatusForbidden, "not allowed while impersonating a user")
+			// The request logger already carries the user and the admin
+			logger.FromContext(c.Request.Context()).Warn("impersonation_refused", slog.String("path", c.Request.URL.Path))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+)
+
+// impersonationTokens returns a manager with a regular token for user 7 and one the admin 1 acts as them with
+func impersonationTokens(t *testing.T) (manager *customJWT.JWTManager, regular, impersonated string) {
+	t.Helper()
+	manager = customJWT.NewJWTManager("secret", time.Hour)
+	regular, err := manager.Generate(7, "mona@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	impersonated, err = manager.GenerateImpersonation(1, 7, "mona@example.com", []string{"customer"}, 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manager, regular, impersonated
+}
+
+func TestImpersonationAuditRecordsTheAdmin(t *testing.T) {
+	manager, regular, impersonated := impersonationTokens(t)
+	sink := &recordingSink{}
+	engine := gin.New()
+	engine.Use(ImpersonationAudit(sink))
+	engine.PUT("/api/v1/orders/:id", AuthMiddleware(manager, nil), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	engine.DELETE("/api/v1/users/me", AuthMiddleware(manager, nil), RejectImpersonation(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	send := func(method, target, token string) {
+		r := httptest.NewRequest(method, target, nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		engine.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	send(http.MethodPut, "/api/v1/orders/5", regular)
+	if len(sink.entries) != 0 {
+		t.Fatalf("a regular request was audited: %+v", sink.entries)
+	}
+
+	send(http.MethodPut, "/api/v1/orders/5", impersonated)
+	send(http.MethodDelete, "/api/v1/users/me", impersonated)
+	if len(sink.entries) != 2 {
+		t.Fatalf("entries = %+v, want both impersonated requests", sink.entries)
+	}
+	tests := []struct {
+		method, path string
+		status       int
+		outcome      string
+	}{
+		{method: http.MethodPut, path: "/api/v1/orders/5", status: http.StatusNoContent, outcome: "success"},
+		// Refused requests are recorded too
+		{method: http.MethodDelete, path: "/api/v1/users/me", status: http.StatusForbidden, outcome: "failure"},
+	}
+	for i, tt := range tests {
+		entry := sink.entries[i]
+		if entry.Event != "impersonated_request" || entry.UserID != 1 || entry.Outcome != tt.outcome {
+			t.Errorf("entry %d = %+v, want an impersonated_request by the admin 1 with outcome %s", i, entry, tt.outcome)
+		}
+		if entry.Details["impersonated_user_id"] != uint(7) || entry.Details["method"] != tt.method ||
+			entry.Details["path"] != tt.path || entry.Details["status"] != tt.status {
+			t.Errorf("entry %d details = %v, want user 7 on %s %s answered %d", i, entry.Details, tt.method, tt.path, tt.status)
+		}
+	}
+}
+
+func TestRejectImpersonation(t *testing.T) {
+	manager, regular, impersonated := impersonationTokens(t)
+	send := authorizedRoute(manager, RejectImpersonation())
+
+	if code := send(regular); code != http.StatusOK {
+		t.Errorf("regular token: status = %d, want 200", code)
+	}
+	if code := send(impersonated); code != http.StatusForbidden {
+		t.Errorf("impersonation token: status = %d, want 403", code)
+	}
+}
@@ -2,14 +2,17 @@ package middleware
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 )
 
-// Logger middleware logs HTTP requests
+// Logger middleware writes one structured access log entry per request. request_id, client_ip
+// and, for authenticated routes, user_id come from the request-scoped logger.
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -17,22 +20,13 @@ func Logger() gin.HandlerFunc {
 		// Process request
 		c.Next()
 
-		// Get request ID from context
-		requestID, ok := c.Get("requestID")
-		if !ok {
-			requestID = "unknown"
-		}
-
-		// Log request details
-		duration := time.Since(start)
-		logger.Infof(
-			"[%s] %s %s - Status: %d - Duration: %v - Size: %d bytes",
-			requestID,
-			c.Request.Method,
-			c.Request.URL.Path,
-			c.Writer.Status(),
-			duration,
-			c.Writer.Size(),
+		ctx := c.Request.Context()
+		logger.FromContext(ctx).LogAttrs(ctx, slog.LevelInfo, "http_request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
 		)
 	}
 }
@@ -48,8 +42,11 @@ func RequestID() gin.HandlerFunc {
 		// Add to response header
 		c.Writer.Header().Set("X-Request-ID", requestID)
 
-		// Add to context
+		// Add to context, along with a logger that tags every entry with the request ID
 		ctx := context.WithValue(c.Request.Context(), "requestID", requestID)
+		// Carried on to the services as metadata, so their logs share the ID
+		ctx = grpcmiddleware.ContextWithRequestID(ctx, requestID)
+		ctx = logger.IntoContext(ctx, logger.With(slog.String("request_id", requestID)))
 		c.Request = c.Request.WithContext(ctx)
 		c.Set("requestID", requestID)
 
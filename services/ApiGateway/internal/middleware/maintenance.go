@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	redisClient "github.com/kareemhamed001/e-commerce/pkg/redis"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	maintenanceKey         = "maintenance:enabled"
+	maintenanceAdminPrefix = "/api/v1/admin/"
+	// DefaultMaintenanceRetryAfter is advertised when maintenance was enabled without an end
+	DefaultMaintenanceRetryAfter = 5 * time.Minute
+)
+
+// MaintenanceFlagStore holds the maintenance flag shared by every gateway instance
+type MaintenanceFlagStore interface {
+	// Maintenance reports whether maintenance is on and, when it was enabled for a fixed time, how long is left
+	Maintenance(ctx context.Context) (enabled bool, remaining time.Duration, err error)
+	// SetMaintenance turns maintenance off, or on for duration. A zero duration lasts until it is turned off.
+	SetMaintenance(ctx context.Context, enabled bool, duration time.Duration) error
+}
+
+// RedisMaintenanceStore keeps the flag in Redis so every instance sees it. Without Redis it is kept
+// locally and only affects this instance.
+type RedisMaintenanceStore struct {
+	cache *redisClient.Client
+	mu    sync.RWMutex
+	on    bool
+	until time.Time
+}
+
+var _ MaintenanceFlagStore = (*RedisMaintenanceStore)(nil)
+
+func NewRedisMaintenanceStore(cache *redisClient.Client) *RedisMaintenanceStore {
+	return &RedisMaintenanceStore{cache: cache}
+}
+
+func (s *RedisMaintenanceStore) Maintenance(ctx context.Context) (bool, time.Duration, error) {
+	if s.cache == nil || !s.cache.IsEnabled() {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		if !s.on {
+			return false, 0, nil
+		}
+		if s.until.IsZero() {
+			return true, 0, nil
+		}
+		remaining := time.Until(s.until)
+		return remaining > 0, remaining, nil
+	}
+
+	// The key's TTL is the time left, so a timed maintenance window ends without anyone disabling it
+	pipe := s.cache.Pipeline()
+	get := pipe.Get(ctx, maintenanceKey)
+	ttl := pipe.TTL(ctx, maintenanceKey)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return false, 0, err
+	}
+	enabled, _ := get.Bool()
+	remaining := ttl.Val()
+	if remaining < 0 {
+		remaining = 0
+	}
+	return enabled, remaining, nil
+}
+
+func (s *RedisMaintenanceStore) SetMaintenance(ctx context.Context, enabled bool, duration time.Duration) error {
+	if s.cache == nil || !s.cache.IsEnabled() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.on = enabled
+		s.until = time.Time{}
+		if enabled && duration > 0 {
+			s.until = time.Now().Add(duration)
+		}
+		return nil
+	}
+
+	if !enabled {
+		return s.cache.Del(ctx, maintenanceKey).Err()
+	}
+	return s.cache.Set(ctx, maintenanceKey, true, duration).Err()
+}
+
+// MaintenanceMode answers 503 to every request while maintenance is on, except health checks, metrics and
+// admin routes called with an admin token, so operators can still work on the gateway and turn it off again.
+// With requireTwoFactor the admin token must have been issued after a two-factor verification. When the flag
+// cannot be read the request is let through rather than taking the API down.
+func MaintenanceMode(store MaintenanceFlagStore, jwtManager *customJWT.JWTManager, requireTwoFactor bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isMaintenanceExempt(c.Request) {
+			c.Next()
+			return
+		}
+
+		enabled, remaining, err := store.Maintenance(c.Request.Context())
+		if err != nil {
+			logger.Warnf("event=maintenance_check_failed error=%v", err)
+			c.Next()
+			return
+		}
+		if !enabled || (strings.HasPrefix(c.Request.URL.Path, maintenanceAdminPrefix) && isAdminRequest(c, jwtManager, requireTwoFactor)) {
+			c.Next()
+			return
+		}
+
+		if remaining <= 0 {
+			remaining = DefaultMaintenanceRetryAfter
+		}
+		remaining = remaining.Round(time.Second)
+		c.Header("Retry-After", strconv.Itoa(int(remaining.Seconds())))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":       http.StatusText(http.StatusServiceUnavailable),
+			"message":     "the API is down for maintenance, please try again later",
+			"code":        http.StatusServiceUnavailable,
+			"retry_after": time.Now().Add(remaining).UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+func isMaintenanceExempt(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	switch r.URL.Path {
+	case "/health", "/api/v1/health", "/ready", "/api/v1/health/ready":
+		return true
+	}
+	return false
+}
+
+// isAdminRequest checks the bearer token itself, as route authentication has not run yet
+func isAdminRequest(c *gin.Context, jwtManager *customJWT.JWTManager, requireTwoFactor bool) bool {
+	parts := strings.Split(c.GetHeader("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return false
+	}
+	claims, err := jwtManager.Verify(parts[1])
+	return err == nil && claims.HasRole("admin") && (claims.TwoFactor || !requireTwoFactor)
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+)
+
+// failingMaintenanceStore cannot reach the flag
+type failingMaintenanceStore struct{}
+
+func (failingMaintenanceStore) Maintenance(context.Context) (bool, time.Duration, error) {
+	return false, 0, errors.New("redis: connection refused")
+}
+
+func (failingMaintenanceStore) SetMaintenance(context.Context, bool, time.Duration) error {
+	return errors.New("redis: connection refused")
+}
+
+// maintainedGateway serves a public, a customer and an admin route and the health check behind
+// MaintenanceMode, returning a func that requests path with token
+func maintainedGateway(store MaintenanceFlagStore, manager *customJWT.JWTManager) func(method, path, token string) *httptest.ResponseRecorder {
+	engine := gin.New()
+	engine.Use(MaintenanceMode(store, manager, false))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	engine.GET("/health", ok)
+	engine.GET("/api/v1/products", ok)
+	engine.GET("/api/v1/orders", ok)
+	engine.POST("/api/v1/admin/maintenance/disable", ok)
+
+	return func(method, path, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+}
+
+func TestMaintenanceModeBlocksAllButAdmins(t *testing.T) {
+	manager := customJWT.NewJWTManager("secret", time.Hour)
+	customer, err := manager.Generate(7, "mona@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := manager.Generate(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := customJWT.NewJWTManager("other", time.Hour).Generate(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewRedisMaintenanceStore(nil)
+	if err := store.SetMaintenance(context.Background(), true, 0); err != nil {
+		t.Fatal(err)
+	}
+	get := maintainedGateway(store, manager)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{name: "anonymous", method: http.MethodGet, path: "/api/v1/products", want: http.StatusServiceUnavailable},
+		{name: "customer", method: http.MethodGet, path: "/api/v1/orders", token: customer, want: http.StatusServiceUnavailable},
+		{name: "customer on an admin route", method: http.MethodPost, path: "/api/v1/admin/maintenance/disable", token: customer, want: http.StatusServiceUnavailable},
+		{name: "admin role from another key", method: http.MethodPost, path: "/api/v1/admin/maintenance/disable", token: forged, want: http.StatusServiceUnavailable},
+		{name: "admin on a customer route", method: http.MethodGet, path: "/api/v1/orders", token: admin, want: http.StatusServiceUnavailable},
+		{name: "admin on an admin route", method: http.MethodPost, path: "/api/v1/admin/maintenance/disable", token: admin, want: http.StatusOK},
+		{name: "health check", method: http.MethodGet, path: "/health", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(tt.method, tt.path, tt.token)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if w.Code != http.StatusServiceUnavailable {
+				return
+			}
+
+			var body struct {
+				Message    string `json:"message"`
+				RetryAfter string `json:"retry_after"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Message == "" {
+				t.Fatalf("body = %s, want a message", w.Body)
+			}
+			retryAt, err := time.Parse(time.RFC3339, body.RetryAfter)
+			if err != nil || time.Until(retryAt) > DefaultMaintenanceRetryAfter || time.Until(retryAt) < DefaultMaintenanceRetryAfter-time.Minute {
+				t.Errorf("retry_after = %q, want about %v from now", body.RetryAfter, DefaultMaintenanceRetryAfter)
+			}
+			if w.Header().Get("Retry-After") != strconv.Itoa(int(DefaultMaintenanceRetryAfter.Seconds())) {
+				t.Errorf("Retry-After = %q, want %v in seconds", w.Header().Get("Retry-After"), DefaultMaintenanceRetryAfter)
+			}
+		})
+	}
+
+	if err := store.SetMaintenance(context.Background(), false, 0); err != nil {
+		t.Fatal(err)
+	}
+	if w := get(http.MethodGet, "/api/v1/orders", customer); w.Code != http.StatusOK {
+		t.Errorf("after disabling: status = %d, want 200", w.Code)
+	}
+}
+
+func TestMaintenanceModeForAFixedTime(t *testing.T) {
+	store := NewRedisMaintenanceStore(nil)
+	if err := store.SetMaintenance(context.Background(), true, 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	get := maintainedGateway(store, customJWT.NewJWTManager("secret", time.Hour))
+
+	w := get(http.MethodGet, "/api/v1/products", "")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", w.Code)
+	}
+	// The window started a moment ago, so rounding gives 10 minutes or a second less
+	if retry := w.Header().Get("Retry-After"); retry != "600" && retry != "599" {
+		t.Errorf("Retry-After = %q, want the 600s left of the window", retry)
+	}
+
+	// Once the window is over the API serves again without anyone disabling maintenance
+	store.mu.Lock()
+	store.until = time.Now().Add(-time.Second)
+	store.mu.Unlock()
+	if w := get(http.MethodGet, "/api/v1/products", ""); w.Code != http.StatusOK {
+		t.Errorf("after the window: status = %d, want 200", w.Code)
+	}
+}
+
+func TestMaintenanceModeLetsRequestsThroughWhenTheFlagCannotBeRead(t *testing.T) {
+	get := maintainedGateway(failingMaintenanceStore{}, customJWT.NewJWTManager("secret", time.Hour))
+	if w := get(http.MethodGet, "/api/v1/products", ""); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+const pathParamsKey contextKey = "pathParams"
+
+// PathParams hands the route's path parameters, such as the :id of /api/v1/products/:id, to handlers wrapped
+// with gin.WrapF, which only get the *http.Request. Read them back with GetPathParam.
+func PathParams() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(c.Params) > 0 {
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), pathParamsKey, c.Params))
+		}
+		c.Next()
+	}
+}
+
+// GetPathParam returns the path parameter name stored by PathParams
+func GetPathParam(ctx context.Context, name string) (string, bool) {
+	params, ok := ctx.Value(pathParamsKey).(gin.Params)
+	if !ok {
+		return "", false
+	}
+	return params.Get(name)
+}
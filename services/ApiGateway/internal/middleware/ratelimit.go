@@ -2,15 +2,19 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// visitor tracks the current window separately from the last request,
+// so cleanup never drops a counter that is still inside its window
 type visitor struct {
-	lastSeen time.Time
-	count    int
+	windowStart time.Time
+	lastSeen    time.Time
+	count       int
 }
 
 // RateLimiter implements a simple rate limiting middleware
@@ -35,57 +39,127 @@ func NewRateLimiter(requests int, window time.Duration) *RateLimiter {
 	return rl
 }
 
+// SetLimit changes the limit for every key from its next request on. Requests already counted in the
+// current window still count against the new limit.
+func (rl *RateLimiter) SetLimit(requests int, window time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.requests = requests
+	rl.window = window
+}
+
 func (rl *RateLimiter) cleanup() {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		rl.mu.Lock()
-		for ip, v := range rl.visitors {
-			if time.Since(v.lastSeen) > rl.window {
-				delete(rl.visitors, ip)
-			}
-		}
-		rl.mu.Unlock()
+	for now := range ticker.C {
+		rl.removeIdle(now)
 	}
 }
 
-func (rl *RateLimiter) getVisitor(ip string) *visitor {
+// removeIdle drops the visitors that sent nothing for a whole window before now
+func (rl *RateLimiter) removeIdle(now time.Time) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
+	for ip, v := range rl.visitors {
+		if now.Sub(v.lastSeen) > rl.window {
+			delete(rl.visitors, ip)
+		}
+	}
+}
 
-	v, exists := rl.visitors[ip]
+// getVisitor returns the visitor of key, adding it at now when there is none. rl.mu must be held, so cleanup
+// cannot drop the visitor before the caller counts the request on it.
+func (rl *RateLimiter) getVisitor(key string, now time.Time) *visitor {
+	v, exists := rl.visitors[key]
 	if !exists {
-		v = &visitor{lastSeen: time.Now(), count: 0}
-		rl.visitors[ip] = v
+		v = &visitor{windowStart: now, lastSeen: now, count: 0}
+		rl.visitors[key] = v
 	}
 
 	return v
 }
 
-// Middleware returns the rate limiting middleware
+// Middleware returns the rate limiting middleware, keyed by client IP. X-Forwarded-For and X-Real-IP only
+// count when the peer is one of TRUSTED_PROXIES, so clients cannot dodge the limit by sending them.
 func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return rl.MiddlewareWithKey(func(c *gin.Context) string {
+		return c.ClientIP()
+	})
+}
+
+// PerUserMiddleware limits authenticated users individually. It must run after AuthMiddleware.
+func (rl *RateLimiter) PerUserMiddleware() gin.HandlerFunc {
+	return rl.MiddlewareWithKey(userKey)
+}
+
+// PerUserSuccessMiddleware limits authenticated users like PerUserMiddleware, but only counts requests the
+// handler answers successfully. The slot is taken before the handler runs, so concurrent requests cannot
+// overrun the limit, and handed back when the response is an error.
+func (rl *RateLimiter) PerUserSuccessMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		v := rl.getVisitor(ip)
-
-		rl.mu.Lock()
-		// Reset counter if window has passed
-		if time.Since(v.lastSeen) > rl.window {
-			v.count = 0
-			v.lastSeen = time.Now()
+		v, windowStart, ok := rl.take(userKey(c))
+		if !ok {
+			writeJSONError(c, http.StatusTooManyRequests, "rate limit exceeded")
+			return
 		}
 
-		// Check if limit exceeded
-		if v.count >= rl.requests {
-			rl.mu.Unlock()
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusBadRequest {
+			rl.giveBack(v, windowStart)
+		}
+	}
+}
+
+// MiddlewareWithKey returns a rate limiting middleware that counts requests per key
+func (rl *RateLimiter) MiddlewareWithKey(keyFunc func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, _, ok := rl.take(keyFunc(c)); !ok {
 			writeJSONError(c, http.StatusTooManyRequests, "rate limit exceeded")
 			return
 		}
 
-		v.count++
-		rl.mu.Unlock()
-
 		c.Next()
 	}
 }
+
+// take counts a request against key, returning the visitor and the window it was counted in, or false
+// when the key is over its limit
+func (rl *RateLimiter) take(key string) (*visitor, time.Time, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := time.Now()
+	v := rl.getVisitor(key, now)
+	v.lastSeen = now
+
+	// Reset counter if window has passed
+	if now.Sub(v.windowStart) > rl.window {
+		v.count = 0
+		v.windowStart = now
+	}
+
+	// Check if limit exceeded
+	if v.count >= rl.requests {
+		return v, v.windowStart, false
+	}
+
+	v.count++
+	return v, v.windowStart, true
+}
+
+// giveBack uncounts a request taken in windowStart; a request from an earlier window no longer counts anyway
+func (rl *RateLimiter) giveBack(v *visitor, windowStart time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if v.windowStart.Equal(windowStart) && v.count > 0 {
+		v.count--
+	}
+}
+
+func userKey(c *gin.Context) string {
+	if userID, ok := GetUserID(c.Request.Context()); ok {
+		return "user:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	return c.ClientIP()
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// limitedRoute serves GET / as userID behind limit, answering each request with the next of statuses
+func limitedRoute(limit gin.HandlerFunc, userID uint, statuses ...int) func() int {
+	engine := gin.New()
+	engine.GET("/", func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), UserClaimsKey, &customJWT.UserClaims{UserID: userID})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}, limit, func(c *gin.Context) {
+		c.Status(statuses[0])
+		statuses = statuses[1:]
+	})
+
+	return func() int {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Code
+	}
+}
+
+func TestPerUserMiddlewareCountsEveryRequest(t *testing.T) {
+	limiter := NewRateLimiter(1, time.Hour)
+	get := limitedRoute(limiter.PerUserMiddleware(), 7, http.StatusInternalServerError)
+
+	if code := get(); code != http.StatusInternalServerError {
+		t.Fatalf("first request = %d, want the handler's 500", code)
+	}
+	if code := get(); code != http.StatusTooManyRequests {
+		t.Fatalf("second request = %d, want 429", code)
+	}
+}
+
+func TestPerUserSuccessMiddlewareCountsOnlySuccesses(t *testing.T) {
+	limiter := NewRateLimiter(1, time.Hour)
+	get := limitedRoute(limiter.PerUserSuccessMiddleware(), 7,
+		http.StatusBadGateway, http.StatusInternalServerError, http.StatusOK)
+
+	for i, want := range []int{http.StatusBadGateway, http.StatusInternalServerError, http.StatusOK, http.StatusTooManyRequests} {
+		if code := get(); code != want {
+			t.Fatalf("request %d = %d, want %d", i+1, code, want)
+		}
+	}
+
+	// Other users keep their own allowance
+	if code := limitedRoute(limiter.PerUserSuccessMiddleware(), 8, http.StatusOK)(); code != http.StatusOK {
+		t.Fatalf("another user = %d, want 200", code)
+	}
+}
+
+func TestPerUserSuccessMiddlewareKeepsNewWindowCount(t *testing.T) {
+	limiter := NewRateLimiter(1, time.Hour)
+	v, windowStart, ok := limiter.take("user:7")
+	if !ok {
+		t.Fatal("first request was refused")
+	}
+
+	// The window rolled over and a new request counted while the first one was in flight
+	v.windowStart = windowStart.Add(-2 * time.Hour)
+	if _, _, ok := limiter.take("user:7"); !ok {
+		t.Fatal("request in the new window was refused")
+	}
+	limiter.giveBack(v, windowStart)
+
+	if v.count != 1 {
+		t.Fatalf("count = %d, want the new window's request to still count", v.count)
+	}
+}
+
+func TestCleanupKeepsActiveVisitors(t *testing.T) {
+	limiter := NewRateLimiter(3, time.Hour)
+	get := limitedRoute(limiter.Middleware(), 0, http.StatusOK, http.StatusOK, http.StatusOK)
+	get()
+
+	// The window began 50 minutes ago and the client kept sending requests since
+	v := limiter.visitors["192.0.2.1"]
+	v.windowStart = v.windowStart.Add(-50 * time.Minute)
+	v.lastSeen = v.windowStart
+	get()
+	get()
+
+	// Cleanup running 20 minutes after the last request, 70 after the window began, keeps the counter
+	limiter.removeIdle(time.Now().Add(20 * time.Minute))
+	if code := get(); code != http.StatusTooManyRequests {
+		t.Fatalf("request after cleanup = %d, want the counter kept and 429", code)
+	}
+
+	limiter.removeIdle(time.Now().Add(2 * time.Hour))
+	if len(limiter.visitors) != 0 {
+		t.Fatalf("%d visitors left after a window without requests, want none", len(limiter.visitors))
+	}
+}
+
+func TestLimitHoldsForAContinuouslyActiveClient(t *testing.T) {
+	const (
+		limit  = 5
+		window = 100 * time.Millisecond
+	)
+	limiter := NewRateLimiter(limit, window)
+	engine := gin.New()
+	engine.GET("/", limiter.Middleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Cleanup runs throughout, as the ticker may fire at any point of a window
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				limiter.removeIdle(time.Now())
+			}
+		}
+	}()
+
+	start := time.Now()
+	allowed := 0
+	for time.Since(start) < 3*window+window/2 {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code == http.StatusOK {
+			allowed++
+		}
+	}
+	elapsed := time.Since(start)
+	close(done)
+	<-stopped
+
+	// Each window that started lets limit requests through, and a new one starts at most every window
+	if most := limit * int(elapsed/window+1); allowed < limit || allowed > most {
+		t.Fatalf("%d requests allowed in %v, want between %d and %d", allowed, elapsed, limit, most)
+	}
+}
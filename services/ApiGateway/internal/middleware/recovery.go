@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// unmatchedPath labels panics on requests that matched no route, so raw URLs never become label values
+const unmatchedPath = "unmatched"
+
+var httpPanics = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "http_panics_total",
+	Help: "Panics recovered while handling HTTP requests, by route.",
+}, []string{"path"})
+
+// PanicReporter sends a recovered panic to an error tracker, e.g. Sentry, so it opens an incident
+// instead of only sitting in the logs. ctx carries the request's logger and request ID.
+type PanicReporter interface {
+	ReportPanic(ctx context.Context, requestID string, p any, stack []byte)
+}
+
+// RecoveryConfig configures Recovery
+type RecoveryConfig struct {
+	// Reporter, when set, is called with every recovered panic
+	Reporter PanicReporter
+}
+
+// Recovery turns a panic in a later handler into a 500 and logs it with the stack. The response carries
+// the request ID, so a user's report can be matched to the stack trace. It must run before RequestID,
+// so that it also covers RequestID and sees the ID it sets.
+func Recovery(cfg RecoveryConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			stack := debug.Stack()
+			// Timeout runs handlers in their own goroutine and raises their panics again here
+			if hp, ok := p.(*handlerPanic); ok {
+				p, stack = hp.value, hp.stack
+			}
+			ctx := c.Request.Context()
+			requestID := c.GetString("requestID")
+			path := c.FullPath()
+			if path == "" {
+				path = unmatchedPath
+			}
+
+			logger.FromContext(ctx).LogAttrs(ctx, slog.LevelError, "http_panic",
+				slog.String("method", c.Request.Method),
+				slog.String("path", path),
+				slog.Any("panic", p),
+				slog.String("stack", string(stack)),
+			)
+			httpPanics.WithLabelValues(path).Inc()
+			if cfg.Reporter != nil {
+				cfg.Reporter.ReportPanic(ctx, requestID, p, stack)
+			}
+
+			// A response already under way, such as a 504 from Timeout, cannot be replaced
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":      http.StatusText(http.StatusInternalServerError),
+				"message":    "internal server error",
+				"code":       http.StatusInternalServerError,
+				"request_id": requestID,
+			})
+		}()
+
+		c.Next()
+	}
+}
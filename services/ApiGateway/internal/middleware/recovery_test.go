@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// recordingReporter keeps the panics reported to it
+type recordingReporter struct {
+	requestIDs []string
+	panics     []any
+}
+
+func (r *recordingReporter) ReportPanic(_ context.Context, requestID string, p any, _ []byte) {
+	r.requestIDs = append(r.requestIDs, requestID)
+	r.panics = append(r.panics, p)
+}
+
+func TestRecoveryLogsTheStackAndReturnsTheRequestID(t *testing.T) {
+	// RequestID derives the request's logger from the default one
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	reporter := &recordingReporter{}
+	engine := gin.New()
+	engine.Use(Recovery(RecoveryConfig{Reporter: reporter}), RequestID())
+	engine.GET("/api/v1/orders/:id", func(c *gin.Context) {
+		panic("nil order")
+	})
+	panics := httpPanics.WithLabelValues("/api/v1/orders/:id")
+	before := testutil.ToFloat64(panics)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/orders/7", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	requestID := w.Header().Get("X-Request-ID")
+	if requestID == "" {
+		t.Fatal("no X-Request-ID header")
+	}
+	var body struct {
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.RequestID != requestID {
+		t.Errorf("body %s, want request_id %q", w.Body, requestID)
+	}
+
+	var entry struct {
+		Msg       string `json:"msg"`
+		Path      string `json:"path"`
+		Panic     string `json:"panic"`
+		Stack     string `json:"stack"`
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("log %s: %v", logs.Bytes(), err)
+	}
+	if entry.Msg != "http_panic" || entry.Path != "/api/v1/orders/:id" || entry.Panic != "nil order" || entry.RequestID != requestID {
+		t.Errorf("log entry %+v, want http_panic on the route with the panic and request ID", entry)
+	}
+	// The stack names the handler that panicked, not only Recovery's own frames
+	if !strings.Contains(entry.Stack, "TestRecoveryLogsTheStackAndReturnsTheRequestID") {
+		t.Errorf("stack does not reach the handler:\n%s", entry.Stack)
+	}
+
+	if got := testutil.ToFloat64(panics) - before; got != 1 {
+		t.Errorf("http_panics_total{path} went up by %v, want 1", got)
+	}
+	if len(reporter.panics) != 1 || reporter.panics[0] != "nil order" || reporter.requestIDs[0] != requestID {
+		t.Errorf("reported %v with request IDs %v, want the panic once with %q", reporter.panics, reporter.requestIDs, requestID)
+	}
+}
+
+func TestRecoveryLabelsUnmatchedPaths(t *testing.T) {
+	engine := gin.New()
+	engine.Use(Recovery(RecoveryConfig{}), RequestID())
+	engine.NoRoute(func(c *gin.Context) {
+		panic("no route")
+	})
+	panics := httpPanics.WithLabelValues(unmatchedPath)
+	before := testutil.ToFloat64(panics)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/some/raw/url", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	if got := testutil.ToFloat64(panics) - before; got != 1 {
+		t.Errorf("http_panics_total{path=%q} went up by %v, want 1", unmatchedPath, got)
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SkipPrefix runs handler for every request except those whose path starts with prefix
+func SkipPrefix(prefix string, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, prefix) {
+			c.Next()
+			return
+		}
+		handler(c)
+	}
+}
@@ -1,20 +1,172 @@
 package middleware
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net"
 	"net/http"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Timeout middleware wraps requests with a timeout
-func Timeout(timeout time.Duration) gin.HandlerFunc {
+const (
+	// parentContextKey holds the request context as it was before Timeout applied the global deadline
+	parentContextKey = "timeoutParentContext"
+	// deadlineTimerKey holds the timer Timeout answers 504 on, which RouteTimeout moves
+	deadlineTimerKey = "timeoutDeadlineTimer"
+	// developerTimeoutKey holds the timeout DeveloperTimeout read from the request, which replaces the global
+	// and route timeouts
+	developerTimeoutKey = "developerTimeout"
+
+	// DeveloperTimeoutHeader sets a request's timeout in milliseconds, in development only
+	DeveloperTimeoutHeader = "X-Request-Timeout-Ms"
+)
+
+var errResponseCommitted = errors.New("response already sent")
+
+// handlerPanic carries a panic out of the goroutine Timeout runs handlers in, with the stack it was raised on
+type handlerPanic struct {
+	value any
+	stack []byte
+}
+
+// TimeoutConfig holds the request timeouts
+type TimeoutConfig struct {
+	// Request is the deadline of every request
+	Request time.Duration
+	// Routes replace Request, and a route's own default, for routes keyed as "METHOD /path"
+	Routes map[string]time.Duration
+}
+
+// TimeoutPolicy holds the request timeouts. Update swaps them while requests are being served, so a config
+// reload applies from the next request on.
+type TimeoutPolicy struct {
+	settings atomic.Pointer[TimeoutConfig]
+}
+
+// NewTimeoutPolicy creates a policy with the timeouts of cfg
+func NewTimeoutPolicy(cfg TimeoutConfig) *TimeoutPolicy {
+	p := &TimeoutPolicy{}
+	p.Update(cfg)
+	return p
+}
+
+// Update replaces every timeout of the policy at once
+func (p *TimeoutPolicy) Update(cfg TimeoutConfig) {
+	p.settings.Store(&cfg)
+}
+
+// Request returns the global request timeout
+func (p *TimeoutPolicy) Request() time.Duration {
+	return p.settings.Load().Request
+}
+
+// Route returns the timeout configured for route, or fallback when there is none
+func (p *TimeoutPolicy) Route(route string, fallback time.Duration) time.Duration {
+	if timeout, ok := p.settings.Load().Routes[route]; ok {
+		return timeout
+	}
+	return fallback
+}
+
+// Timeout middleware gives every request a deadline and answers 504 as soon as it passes, even when the
+// handler ignores its context and keeps running. Handlers run in their own goroutine and their response is
+// buffered until they finish, so the 504 can still take its place; whatever they write after the deadline is
+// discarded. Once a handler flushes, e.g. a stream, its response is sent as it goes and the deadline only
+// cancels the context. The middleware still waits for the handler to return before it does, as gin reuses
+// the Context afterwards, but the 504 has already reached the client by then.
+func Timeout(policy *TimeoutPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Set(parentContextKey, c.Request.Context())
+
+		timeout := policy.Request()
+		if override, ok := c.Get(developerTimeoutKey); ok {
+			timeout = override.(time.Duration)
+		}
+
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
-
 		c.Request = c.Request.WithContext(ctx)
+
+		deadline := time.NewTimer(timeout)
+		defer deadline.Stop()
+		c.Set(deadlineTimerKey, deadline)
+
+		w := newTimeoutWriter(c.Writer)
+		c.Writer = w
+
+		done := make(chan *handlerPanic, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					done <- &handlerPanic{value: p, stack: debug.Stack()}
+					return
+				}
+				done <- nil
+			}()
+			c.Next()
+		}()
+
+		var p *handlerPanic
+		select {
+		case p = <-done:
+		case <-deadline.C:
+			w.timeout()
+			p = <-done
+		}
+
+		c.Writer = w.ResponseWriter
+		if p != nil {
+			panic(p)
+		}
+		if w.timedOut {
+			c.Abort()
+			return
+		}
+		w.finish()
+	}
+}
+
+// RouteTimeout replaces the global Timeout for the route keyed as "METHOD /path", with the policy's timeout
+// for it or else fallback. The deadline is derived from the context Timeout started from, so it can be longer
+// as well as shorter than the global one.
+func RouteTimeout(policy *TimeoutPolicy, route string, fallback time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(developerTimeoutKey); ok {
+			c.Next()
+			return
+		}
+
+		timeout := policy.Route(route, fallback)
+
+		parent := c.Request.Context()
+		if value, ok := c.Get(parentContextKey); ok {
+			if parentCtx, ok := value.(context.Context); ok {
+				parent = parentCtx
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		// Timeout answers 504 on its own timer, which must follow this route's deadline instead
+		if value, ok := c.Get(deadlineTimerKey); ok {
+			if timer, ok := value.(*time.Timer); ok {
+				deadline, _ := ctx.Deadline()
+				timer.Reset(time.Until(deadline))
+			}
+		}
+
+		// Claims and other values added after Timeout ran must survive the context swap
+		c.Request = c.Request.WithContext(contextWithValuesFrom(ctx, c.Request.Context()))
 		c.Next()
 
 		if ctx.Err() == context.DeadlineExceeded && !c.Writer.Written() {
@@ -23,3 +175,202 @@ func Timeout(timeout time.Duration) gin.HandlerFunc {
 		}
 	}
 }
+
+// DeveloperTimeout lets a request set its own timeout with the X-Request-Timeout-Ms header, e.g. for an
+// integration test that needs a short or long deadline. It replaces both the global and the route timeout, so
+// it must run before Timeout, and only be registered in development. Values that are not a positive integer
+// are ignored.
+func DeveloperTimeout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ms, err := strconv.Atoi(c.GetHeader(DeveloperTimeoutHeader))
+		if err != nil || ms <= 0 {
+			c.Next()
+			return
+		}
+
+		timeout := time.Duration(ms) * time.Millisecond
+		c.Set(developerTimeoutKey, timeout)
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// valuesContext takes its deadline and cancellation from Context but looks values up in values
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (v valuesContext) Value(key any) any {
+	return v.values.Value(key)
+}
+
+func contextWithValuesFrom(ctx, values context.Context) context.Context {
+	return valuesContext{Context: ctx, values: values}
+}
+
+// timeoutWriter holds back the response of a handler run by Timeout until the handler finishes, flushes or
+// hijacks the connection, so that a 504 can still be sent instead
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu        sync.Mutex
+	header    http.Header
+	body      bytes.Buffer
+	status    int
+	size      int
+	committed bool
+	timedOut  bool
+}
+
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	// Headers set by earlier middleware, such as CORS and the request ID, belong to the buffered response too
+	return &timeoutWriter{ResponseWriter: w, header: w.Header().Clone(), status: http.StatusOK, size: -1}
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to extend a stream's write deadline
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.committed {
+		return w.ResponseWriter.Header()
+	}
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.committed || w.timedOut {
+		return
+	}
+	if code > 0 && w.size < 0 {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size < 0 {
+		w.size = 0
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.size < 0 {
+		w.size = 0
+	}
+	w.size += len(data)
+	if w.committed {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.size
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.size >= 0
+}
+
+// Flush sends what was written so far and everything after it straight to the client, giving up the 504
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return
+	}
+	w.commit()
+	w.ResponseWriter.Flush()
+}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	if w.committed {
+		return nil, nil, errResponseCommitted
+	}
+	w.committed = true
+	return w.ResponseWriter.Hijack()
+}
+
+// commit sends the buffered response; w.mu must be held
+func (w *timeoutWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+	header := w.ResponseWriter.Header()
+	clear(header)
+	maps.Copy(header, w.header)
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.size >= 0 {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+// finish sends the response of a handler that returned in time
+func (w *timeoutWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.commit()
+}
+
+// timeout answers 504 unless the response has started, and discards every later write of the handler
+func (w *timeoutWriter) timeout() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.committed {
+		return
+	}
+	w.timedOut = true
+
+	status := http.StatusGatewayTimeout
+	body, _ := json.Marshal(gin.H{
+		"error":   http.StatusText(status),
+		"message": "request timeout",
+		"code":    status,
+	})
+	header := w.ResponseWriter.Header()
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	// With its length known and the connection closing, the client has the whole response while the
+	// handler is still running
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	header.Set("Connection", "close")
+	w.ResponseWriter.WriteHeader(status)
+	w.ResponseWriter.Write(body)
+	w.ResponseWriter.Flush()
+}
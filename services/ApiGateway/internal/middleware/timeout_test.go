@@ -0,0 +1,303 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// slowBackend answers after delay, or gives up once the caller does
+func slowBackend(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+// proxiedGateway serves GET /slow behind Timeout and routeMiddleware, passing the request on to backend with
+// its context as handlers pass theirs on to the services. It returns a func making one request and
+// reporting its status and how long it took.
+func proxiedGateway(t *testing.T, policy *TimeoutPolicy, backend *httptest.Server, routeMiddleware ...gin.HandlerFunc) func() (int, time.Duration) {
+	t.Helper()
+	engine := gin.New()
+	engine.Use(Timeout(policy))
+	engine.GET("/slow", append(routeMiddleware, func(c *gin.Context) {
+		req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, backend.URL, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			// The deadline passed; the timeout middleware answers
+			return
+		}
+		resp.Body.Close()
+		c.Status(resp.StatusCode)
+	})...)
+	gateway := httptest.NewServer(engine)
+	t.Cleanup(gateway.Close)
+
+	return func() (int, time.Duration) {
+		start := time.Now()
+		resp, err := http.Get(gateway.URL + "/slow")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode, time.Since(start)
+	}
+}
+
+func TestRouteTimeoutFiresBeforeTheGlobalTimeout(t *testing.T) {
+	policy := NewTimeoutPolicy(TimeoutConfig{Request: 5 * time.Second})
+	get := proxiedGateway(t, policy, slowBackend(t, 10*time.Second), RouteTimeout(policy, "GET /slow", 50*time.Millisecond))
+
+	code, elapsed := get()
+	if code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504", code)
+	}
+	if elapsed >= time.Second {
+		t.Fatalf("the request took %v, want the route's 50ms timeout rather than the global 5s", elapsed)
+	}
+}
+
+func TestRouteTimeoutMayExceedTheGlobalTimeout(t *testing.T) {
+	policy := NewTimeoutPolicy(TimeoutConfig{Request: 50 * time.Millisecond})
+	backend := slowBackend(t, 200*time.Millisecond)
+
+	if code, _ := proxiedGateway(t, policy, backend)(); code != http.StatusGatewayTimeout {
+		t.Fatalf("without a route timeout: status = %d, want the global timeout's 504", code)
+	}
+	if code, _ := proxiedGateway(t, policy, backend, RouteTimeout(policy, "GET /slow", 5*time.Second))(); code != http.StatusOK {
+		t.Fatalf("with a 5s route timeout: status = %d, want the backend's 200", code)
+	}
+}
+
+func TestRouteTimeoutsOfThePolicyReplaceTheRouteDefault(t *testing.T) {
+	policy := NewTimeoutPolicy(TimeoutConfig{
+		Request: 5 * time.Second,
+		Routes:  map[string]time.Duration{"GET /slow": 50 * time.Millisecond},
+	})
+	get := proxiedGateway(t, policy, slowBackend(t, 10*time.Second), RouteTimeout(policy, "GET /slow", 5*time.Second))
+
+	if code, elapsed := get(); code != http.StatusGatewayTimeout || elapsed >= time.Second {
+		t.Fatalf("got %d after %v, want 504 after the configured 50ms", code, elapsed)
+	}
+
+	// A reload applies from the next request on
+	policy.Update(TimeoutConfig{Request: 5 * time.Second, Routes: map[string]time.Duration{"GET /slow": 20 * time.Second}})
+	fast := proxiedGateway(t, policy, slowBackend(t, 100*time.Millisecond), RouteTimeout(policy, "GET /slow", 50*time.Millisecond))
+	if code, _ := fast(); code != http.StatusOK {
+		t.Fatalf("after the reload: status = %d, want 200", code)
+	}
+}
+
+// stubbornGateway serves GET /stubborn behind Timeout with handler, returning the gateway's URL
+func stubbornGateway(t *testing.T, timeout time.Duration, handler gin.HandlerFunc) string {
+	t.Helper()
+	engine := gin.New()
+	engine.Use(Timeout(NewTimeoutPolicy(TimeoutConfig{Request: timeout})))
+	engine.GET("/stubborn", handler)
+	gateway := httptest.NewServer(engine)
+	t.Cleanup(gateway.Close)
+	return gateway.URL + "/stubborn"
+}
+
+func TestTimeoutAnswersWhileTheHandlerIgnoresItsDeadline(t *testing.T) {
+	lateWrite := make(chan error, 1)
+	url := stubbornGateway(t, 50*time.Millisecond, func(c *gin.Context) {
+		// Ignores c.Request.Context() and writes long after the deadline
+		time.Sleep(500 * time.Millisecond)
+		_, err := c.Writer.WriteString(`{"late":true}`)
+		c.Writer.WriteHeader(http.StatusOK)
+		lateWrite <- err
+	})
+
+	start := time.Now()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+
+	want := `{"code":504,"error":"Gateway Timeout","message":"request timeout"}`
+	if resp.StatusCode != http.StatusGatewayTimeout || string(body) != want {
+		t.Fatalf("got %d %s, want 504 %s", resp.StatusCode, body, want)
+	}
+	if elapsed >= 400*time.Millisecond {
+		t.Fatalf("the 504 took %v, want it at the 50ms deadline rather than when the handler returns", elapsed)
+	}
+	if err := <-lateWrite; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Fatalf("late write error = %v, want %v", err, http.ErrHandlerTimeout)
+	}
+}
+
+func TestTimeoutSendsTheResponseOfAHandlerThatFinishesInTime(t *testing.T) {
+	url := stubbornGateway(t, time.Second, func(c *gin.Context) {
+		c.Header("X-Handler", "yes")
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || string(body) != `{"id":1}` || resp.Header.Get("X-Handler") != "yes" {
+		t.Fatalf("got %d %v %s, want the handler's 201 {\"id\":1} with its header", resp.StatusCode, resp.Header, body)
+	}
+}
+
+func TestTimeoutLetsAFlushedStreamRunPastTheDeadline(t *testing.T) {
+	url := stubbornGateway(t, 50*time.Millisecond, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Writer.WriteString("first\n")
+		c.Writer.Flush()
+		time.Sleep(150 * time.Millisecond)
+		// The deadline has passed, but the stream already started and keeps going
+		c.Writer.WriteString("second\n")
+	})
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "first\nsecond\n" {
+		t.Fatalf("got %d %q, want 200 with both lines", resp.StatusCode, body)
+	}
+}
+
+func TestTimeoutPanicsReachRecovery(t *testing.T) {
+	engine := gin.New()
+	engine.Use(gin.CustomRecovery(func(c *gin.Context, err any) {
+		c.String(http.StatusInternalServerError, "recovered %v", err)
+	}))
+	engine.Use(Timeout(NewTimeoutPolicy(TimeoutConfig{Request: time.Second})))
+	engine.GET("/panic", func(*gin.Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "boom") {
+		t.Fatalf("got %d %s, want Recovery's 500 for the handler's panic", w.Code, w.Body)
+	}
+}
+
+// deadlineRoute serves GET / behind DeveloperTimeout, Timeout and routeMiddleware, answering with how long the
+// handler had left before its deadline. It returns a func making one request with header as
+// X-Request-Timeout-Ms, when not empty.
+func deadlineRoute(t *testing.T, policy *TimeoutPolicy, routeMiddleware ...gin.HandlerFunc) func(header string) time.Duration {
+	t.Helper()
+	engine := gin.New()
+	engine.Use(DeveloperTimeout(), Timeout(policy))
+	engine.GET("/", append(routeMiddleware, func(c *gin.Context) {
+		deadline, _ := c.Request.Context().Deadline()
+		c.String(http.StatusOK, time.Until(deadline).String())
+	})...)
+
+	return func(header string) time.Duration {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			r.Header.Set(DeveloperTimeoutHeader, header)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		left, err := time.ParseDuration(w.Body.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return left
+	}
+}
+
+func TestDeveloperTimeout(t *testing.T) {
+	policy := NewTimeoutPolicy(TimeoutConfig{Request: 30 * time.Second})
+	tests := []struct {
+		name            string
+		routeMiddleware []gin.HandlerFunc
+		header          string
+		want            time.Duration
+	}{
+		{name: "no header", want: 30 * time.Second},
+		{name: "replaces the global timeout", header: "5000", want: 5 * time.Second},
+		{name: "may exceed the global timeout", header: "60000", want: time.Minute},
+		{
+			name:            "replaces the route timeout",
+			routeMiddleware: []gin.HandlerFunc{RouteTimeout(policy, "GET /", 10*time.Second)},
+			header:          "5000",
+			want:            5 * time.Second,
+		},
+		{
+			name:            "route timeout without the header",
+			routeMiddleware: []gin.HandlerFunc{RouteTimeout(policy, "GET /", 10*time.Second)},
+			want:            10 * time.Second,
+		},
+		{name: "not a number", header: "soon", want: 30 * time.Second},
+		{name: "zero", header: "0", want: 30 * time.Second},
+		{name: "negative", header: "-100", want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left := deadlineRoute(t, policy, tt.routeMiddleware...)(tt.header)
+			if left > tt.want || left < tt.want-time.Second {
+				t.Fatalf("the handler had %v left, want about %v", left, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeveloperTimeoutAnswers504AtItsDeadline(t *testing.T) {
+	engine := gin.New()
+	engine.Use(DeveloperTimeout(), Timeout(NewTimeoutPolicy(TimeoutConfig{Request: 30 * time.Second})))
+	engine.GET("/", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(DeveloperTimeoutHeader, "50")
+	w := httptest.NewRecorder()
+	start := time.Now()
+	engine.ServeHTTP(w, r)
+	if w.Code != http.StatusGatewayTimeout || time.Since(start) >= time.Second {
+		t.Fatalf("got %d after %v, want 504 after the header's 50ms", w.Code, time.Since(start))
+	}
+}
+
+func TestTimeoutFollowsPolicyUpdates(t *testing.T) {
+	policy := NewTimeoutPolicy(TimeoutConfig{Request: 30 * time.Second})
+	get := deadlineRoute(t, policy, RouteTimeout(policy, "GET /", 10*time.Second))
+
+	if left := get(""); left > 10*time.Second || left < 9*time.Second {
+		t.Fatalf("before the update: the handler had %v left, want about the route default of 10s", left)
+	}
+	policy.Update(TimeoutConfig{Request: 30 * time.Second, Routes: map[string]time.Duration{"GET /": 2 * time.Second}})
+	if left := get(""); left > 2*time.Second || left < time.Second {
+		t.Fatalf("after the update: the handler had %v left, want about the configured 2s", left)
+	}
+
+	global := deadlineRoute(t, policy)
+	policy.Update(TimeoutConfig{Request: 3 * time.Second})
+	if left := global(""); left > 3*time.Second || left < 2*time.Second {
+		t.Fatalf("after updating the global timeout: the handler had %v left, want about 3s", left)
+	}
+}
@@ -0,0 +1,84 @@
+package router
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/docs"
+)
+
+// undocumentedPrefixes are mounted for tooling rather than API clients and are not part of the spec
+var undocumentedPrefixes = []string{"/swagger/", "/grpc/", "/debug/pprof"}
+
+// TestRoutesMatchSwaggerDocs fails when the route table and the Swagger docs disagree. The router is the
+// source of truth: every registered route must be documented, and every documented path must be registered.
+func TestRoutesMatchSwaggerDocs(t *testing.T) {
+	routed := make(map[string]bool)
+	for _, route := range newTestEngine(t).Routes() {
+		if isUndocumented(route.Path) {
+			continue
+		}
+		routed[operation(route.Method, swaggerPath(route.Path))] = true
+	}
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &spec); err != nil {
+		t.Fatalf("read swagger docs: %v", err)
+	}
+	documented := make(map[string]bool)
+	for path, methods := range spec.Paths {
+		for method := range methods {
+			documented[operation(method, path)] = true
+		}
+	}
+
+	for _, op := range difference(documented, routed) {
+		t.Errorf("documented but not routed: %s", op)
+	}
+	for _, op := range difference(routed, documented) {
+		t.Errorf("routed but not documented: %s", op)
+	}
+	if t.Failed() {
+		t.Log("fix the @Router annotations and run 'make swagger'")
+	}
+}
+
+// swaggerPath rewrites gin's :name and *name segments into swagger's {name}
+func swaggerPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func operation(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+func isUndocumented(path string) bool {
+	for _, prefix := range undocumentedPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// difference returns the sorted keys of a that are not in b
+func difference(a, b map[string]bool) []string {
+	var out []string
+	for key := range a {
+		if !b[key] {
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -1,110 +1,331 @@
 package router
 
 import (
+	"context"
 	"net/http"
+	"net/http/pprof"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	"github.com/kareemhamed001/e-commerce/services/ApiGateway/config"
+	_ "github.com/kareemhamed001/e-commerce/services/ApiGateway/docs" // registers the spec served under /swagger/
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/audit"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/grpcweb"
 	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/handlers"
 	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"google.golang.org/grpc/connectivity"
 )
 
+const (
+	pprofPrefix   = "/debug/pprof"
+	grpcWebPrefix = "/grpc/"
+	// readinessProbeTimeout bounds the RPC readinessCheck sends each service
+	readinessProbeTimeout = 2 * time.Second
+)
+
+// ServiceHealth reports on each backend service, keyed by service name
+type ServiceHealth interface {
+	// HealthStatus reports the state of each service connection
+	HealthStatus() map[string]connectivity.State
+	// Probe calls each service, each call bounded by timeout, and returns the errors of those that failed
+	Probe(ctx context.Context, timeout time.Duration) map[string]error
+}
+
 // Router manages all HTTP routes and middlewares
 type Router struct {
-	engine         *gin.Engine
-	cfg            *config.Config
-	jwtManager     *customJWT.JWTManager
-	userHandler    *handlers.UserHandler
-	productHandler *handlers.ProductHandler
-	cartHandler    *handlers.CartHandler
-	orderHandler   *handlers.OrderHandler
+	engine              *gin.Engine
+	cfg                 *config.Config
+	jwtManager          *customJWT.JWTManager
+	userHandler         *handlers.UserHandler
+	productHandler      *handlers.ProductHandler
+	cartHandler         *handlers.CartHandler
+	wishlistHandler     *handlers.WishlistHandler
+	reviewHandler       *handlers.ReviewHandler
+	orderHandler        *handlers.OrderHandler
+	reportHandler       *handlers.ReportHandler
+	adminHandler        *handlers.AdminHandler
+	apiKeyHandler       *handlers.APIKeyHandler
+	notificationHandler *handlers.NotificationHandler
+	graphqlHandler      *handlers.GraphQLHandler
+	grpcWebProxy        *grpcweb.Proxy
+	cors                *middleware.CORSPolicy
+	blockList           *middleware.BlockListPolicy
+	timeouts            *middleware.TimeoutPolicy
+	rateLimiter         *middleware.RateLimiter
+	currencies          *middleware.CurrencyPolicy
+	exportLimiter       *middleware.RateLimiter
+	revoker             *middleware.TokenRevoker
+	apiKeys             *middleware.APIKeyResolver
+	auditLog            audit.Sink
+	maintenance         middleware.MaintenanceFlagStore
+	connections         *middleware.ConnectionTracker
+	services            ServiceHealth
+	features            middleware.FeatureFlagStore
+	dedup               middleware.DeduplicationStore
+	timedRoutes         map[string]struct{}
+}
+
+// Deps holds the handlers and shared state the router serves. Handlers may be left nil by tools that only
+// read the route table, such as the route check.
+type Deps struct {
+	UserHandler         *handlers.UserHandler
+	ProductHandler      *handlers.ProductHandler
+	CartHandler         *handlers.CartHandler
+	WishlistHandler     *handlers.WishlistHandler
+	ReviewHandler       *handlers.ReviewHandler
+	OrderHandler        *handlers.OrderHandler
+	ReportHandler       *handlers.ReportHandler
+	AdminHandler        *handlers.AdminHandler
+	APIKeyHandler       *handlers.APIKeyHandler
+	NotificationHandler *handlers.NotificationHandler
+	GraphQLHandler      *handlers.GraphQLHandler
+	GRPCWebProxy        *grpcweb.Proxy
+	Revoker             *middleware.TokenRevoker
+	APIKeys             *middleware.APIKeyResolver
+	AuditLog            audit.Sink
+	Maintenance         middleware.MaintenanceFlagStore
+	Connections         *middleware.ConnectionTracker
+	Services            ServiceHealth
+	Features            middleware.FeatureFlagStore
+	Dedup               middleware.DeduplicationStore
+
+	// Callers that reload the configuration pass the policies and limiter they update; without them the
+	// settings stay as in the config
+	CORS        *middleware.CORSPolicy
+	BlockList   *middleware.BlockListPolicy
+	Timeouts    *middleware.TimeoutPolicy
+	RateLimiter *middleware.RateLimiter
+	Currencies  *middleware.CurrencyPolicy
 }
 
 // NewRouter creates a new router with all routes configured
-func NewRouter(
-	router *gin.Engine,
-	cfg *config.Config,
-	userHandler *handlers.UserHandler,
-	productHandler *handlers.ProductHandler,
-	cartHandler *handlers.CartHandler,
-	orderHandler *handlers.OrderHandler,
-) *Router {
+func NewRouter(router *gin.Engine, cfg *config.Config, deps Deps) *Router {
+	if deps.CORS == nil {
+		deps.CORS = middleware.NewCORSPolicy(cfg.CORS())
+	}
+	if deps.BlockList == nil {
+		deps.BlockList = middleware.NewBlockListPolicy(cfg.BlockList())
+	}
+	if deps.Timeouts == nil {
+		deps.Timeouts = middleware.NewTimeoutPolicy(cfg.Timeouts())
+	}
+	if deps.RateLimiter == nil {
+		deps.RateLimiter = middleware.NewRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	}
+	if deps.Currencies == nil {
+		deps.Currencies = middleware.NewCurrencyPolicy(cfg.Currencies())
+	}
+
 	r := &Router{
-		engine:         router,
-		cfg:            cfg,
-		jwtManager:     customJWT.NewJWTManager(cfg.JWTSecret, 24*time.Hour),
-		userHandler:    userHandler,
-		productHandler: productHandler,
-		cartHandler:    cartHandler,
-		orderHandler:   orderHandler,
+		engine:              router,
+		cfg:                 cfg,
+		jwtManager:          customJWT.NewJWTManager(cfg.JWTSecret, cfg.JWTDuration),
+		userHandler:         deps.UserHandler,
+		productHandler:      deps.ProductHandler,
+		cartHandler:         deps.CartHandler,
+		wishlistHandler:     deps.WishlistHandler,
+		reviewHandler:       deps.ReviewHandler,
+		orderHandler:        deps.OrderHandler,
+		reportHandler:       deps.ReportHandler,
+		adminHandler:        deps.AdminHandler,
+		apiKeyHandler:       deps.APIKeyHandler,
+		notificationHandler: deps.NotificationHandler,
+		graphqlHandler:      deps.GraphQLHandler,
+		grpcWebProxy:        deps.GRPCWebProxy,
+		cors:                deps.CORS,
+		blockList:           deps.BlockList,
+		timeouts:            deps.Timeouts,
+		rateLimiter:         deps.RateLimiter,
+		currencies:          deps.Currencies,
+		exportLimiter:       middleware.NewRateLimiter(1, time.Hour),
+		revoker:             deps.Revoker,
+		apiKeys:             deps.APIKeys,
+		auditLog:            deps.AuditLog,
+		maintenance:         deps.Maintenance,
+		connections:         deps.Connections,
+		services:            deps.Services,
+		features:            deps.Features,
+		dedup:               deps.Dedup,
+		timedRoutes:         make(map[string]struct{}),
+	}
+
+	r.jwtManager.SetKeys(cfg.JWTKeys)
+	if cfg.JWTKeySet != nil {
+		// config.Load has already matched the keys to the algorithm, so this cannot fail on a loaded config
+		if err := r.jwtManager.SetKeySet(cfg.JWTAlgorithm, cfg.JWTKeySet); err != nil {
+			panic(err)
+		}
 	}
+	r.jwtManager.SetRolePermissions(cfg.RolePermissions)
+	r.jwtManager.SetIssuerAudience(cfg.JWTIssuer, cfg.JWTAudience)
+	r.jwtManager.SetLeeway(cfg.JWTLeeway)
 
 	r.setupMiddleware()
 	r.setupRoutes()
+	if cfg.EnablePprof {
+		r.setupPprofRoutes()
+	}
+	for route := range cfg.RouteTimeouts {
+		if _, ok := r.timedRoutes[route]; !ok {
+			logger.Warnf("event=route_timeout_ignored route=%q reason=route does not support a custom timeout", route)
+		}
+	}
 	return r
 }
 
 // setupRoutes configures all routes
 func (r *Router) setupRoutes() {
 	// Health check
-	r.engine.GET("/health", r.healthCheck)
-	r.engine.GET("/api/v1/health", r.healthCheck)
+	r.engine.GET("/health", r.withTimeout(http.MethodGet, "/health", time.Second), r.healthCheck)
+	r.engine.GET("/api/v1/health", r.withTimeout(http.MethodGet, "/api/v1/health", time.Second), r.healthCheck)
+	r.engine.GET("/ready", r.withTimeout(http.MethodGet, "/ready", 3*time.Second), r.readinessCheck)
+	r.engine.GET("/api/v1/health/ready", r.withTimeout(http.MethodGet, "/api/v1/health/ready", 3*time.Second), r.readinessCheck)
+	r.engine.GET("/.well-known/jwks.json", r.withTimeout(http.MethodGet, "/.well-known/jwks.json", 10*time.Second), r.jwks)
+
+	// Routes registered with handleMoved also answer on their old verb-suffixed paths until the next release
 
 	// User routes - Public
-	r.engine.POST("/api/v1/users/register", r.userHandler.Register)
+	r.handleMoved(http.MethodPost, "/api/v1/users", "/api/v1/users/register", r.userHandler.Register)
 	r.engine.POST("/api/v1/users/login", r.userHandler.Login)
+	r.engine.POST("/api/v1/users/2fa/verify", r.userHandler.VerifyTwoFactor)
 
 	// User routes - Authenticated
-	r.engine.GET("/api/v1/users/profile", r.withAuth(), r.userHandler.GetProfile)
-	r.engine.PUT("/api/v1/users/update", r.withAuth(), r.userHandler.UpdateUser)
+	r.handleMoved(http.MethodGet, "/api/v1/users/me", "/api/v1/users/profile", r.withAuth(), r.userHandler.GetProfile)
+	r.handleMoved(http.MethodPut, "/api/v1/users/me", "/api/v1/users/update", r.withAuth(), r.withoutImpersonation(), r.userHandler.UpdateUser)
+	r.engine.DELETE("/api/v1/users/me", r.withAuth(), r.withoutImpersonation(), r.userHandler.EraseMyData)
+	r.engine.POST("/api/v1/users/2fa/setup", r.withAuth(), r.withoutImpersonation(), r.userHandler.SetupTwoFactor)
+	r.engine.POST("/api/v1/users/2fa/enable", r.withAuth(), r.withoutImpersonation(), r.userHandler.EnableTwoFactor)
+	r.engine.GET("/api/v1/users/me/export", r.withTimeout(http.MethodGet, "/api/v1/users/me/export", 120*time.Second), r.withAuth(), r.withoutImpersonation(), r.exportLimiter.PerUserSuccessMiddleware(), r.userHandler.ExportMyData)
 
 	// User routes - Admin only
-	r.engine.GET("/api/v1/users/search", r.withAuth(), r.withRole("admin"), r.userHandler.SearchUsers)
-	r.engine.GET("/api/v1/users/by-id", r.withAuth(), r.withRole("admin"), r.userHandler.GetUserByID)
-	r.engine.DELETE("/api/v1/users/delete", r.withAuth(), r.withRole("admin"), r.userHandler.DeleteUser)
+	r.handleMoved(http.MethodGet, "/api/v1/users", "/api/v1/users/search", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionUserRead), r.userHandler.SearchUsers)
+	r.handleMoved(http.MethodGet, "/api/v1/users/:id", "/api/v1/users/by-id", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionUserRead), r.withPathParams(), r.userHandler.GetUserByID)
+	r.handleMoved(http.MethodDelete, "/api/v1/users/:id", "/api/v1/users/delete", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionUserWrite), r.withPathParams(), r.userHandler.DeleteUser)
 
 	// Address routes - Authenticated
-	r.engine.POST("/api/v1/addresses/create", r.withAuth(), r.userHandler.CreateAddress)
-	r.engine.GET("/api/v1/addresses/list", r.withAuth(), r.userHandler.ListAddresses)
-	r.engine.PUT("/api/v1/addresses/update", r.withAuth(), r.userHandler.UpdateAddress)
-	r.engine.DELETE("/api/v1/addresses/delete", r.withAuth(), r.userHandler.DeleteAddress)
+	r.handleMoved(http.MethodPost, "/api/v1/addresses", "/api/v1/addresses/create", r.withAuth(), r.userHandler.CreateAddress)
+	r.handleMoved(http.MethodGet, "/api/v1/addresses", "/api/v1/addresses/list", r.withAuth(), r.userHandler.ListAddresses)
+	r.handleMoved(http.MethodPut, "/api/v1/addresses/:id", "/api/v1/addresses/update", r.withAuth(), r.withPathParams(), r.userHandler.UpdateAddress)
+	r.handleMoved(http.MethodDelete, "/api/v1/addresses/:id", "/api/v1/addresses/delete", r.withAuth(), r.withPathParams(), r.userHandler.DeleteAddress)
+	r.engine.PUT("/api/v1/addresses/:id/default", r.withAuth(), r.userHandler.SetDefaultAddress)
 
 	// Product routes - Public
-	r.engine.GET("/api/v1/products", gin.WrapF(r.productHandler.ListProducts))
-	r.engine.GET("/api/v1/products/by-id", gin.WrapF(r.productHandler.GetProductByID))
+	r.engine.GET("/api/v1/products", r.withPrices(), gin.WrapF(r.productHandler.ListProducts))
+	r.handleMoved(http.MethodGet, "/api/v1/products/:id", "/api/v1/products/by-id", r.withPrices(), r.withPathParams(), gin.WrapF(r.productHandler.GetProductByID))
+	r.engine.GET("/api/v1/products/batch", r.withPrices(), gin.WrapF(r.productHandler.GetProductsBatch))
+	r.engine.POST("/api/v1/products/batch", r.withPrices(), gin.WrapF(r.productHandler.PostProductsBatch))
 
 	// Product routes - Admin only
-	r.engine.POST("/api/v1/products/create", r.withAuth(), r.withRole("admin"), gin.WrapF(r.productHandler.CreateProduct))
-	r.engine.PUT("/api/v1/products/update", r.withAuth(), r.withRole("admin"), gin.WrapF(r.productHandler.UpdateProduct))
-	r.engine.DELETE("/api/v1/products/delete", r.withAuth(), r.withRole("admin"), gin.WrapF(r.productHandler.DeleteProduct))
+	r.handleMoved(http.MethodPost, "/api/v1/products", "/api/v1/products/create", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionProductWrite), gin.WrapF(r.productHandler.CreateProduct))
+	r.handleMoved(http.MethodPut, "/api/v1/products/:id", "/api/v1/products/update", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionProductWrite), r.withPathParams(), gin.WrapF(r.productHandler.UpdateProduct))
+	r.handleMoved(http.MethodDelete, "/api/v1/products/:id", "/api/v1/products/delete", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionProductWrite), r.withPathParams(), gin.WrapF(r.productHandler.DeleteProduct))
+	r.engine.POST("/api/v1/products/:id/image-url", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionProductWrite), r.productHandler.GetImageUploadURL)
+	r.engine.PATCH("/api/v1/products/:id/image", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionProductWrite), r.productHandler.UpdateProductImage)
+	r.engine.GET("/api/v1/products/:id/reviews", r.reviewHandler.ListReviews)
+	r.engine.POST("/api/v1/products/:id/reviews", r.withAuth(), r.reviewHandler.CreateReview)
+	r.engine.PATCH("/api/v1/products/:id/stock", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionProductWrite), r.productHandler.AdjustStock)
+
+	r.engine.POST("/api/v1/admin/products/:id/stock-adjustments", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionProductWrite), r.productHandler.CreateStockAdjustment)
+	r.engine.GET("/api/v1/admin/products/:id/stock-movements", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionProductWrite), r.productHandler.ListStockMovements)
+	r.engine.GET("/api/v1/admin/products/low-stock", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionProductWrite), gin.WrapF(r.productHandler.ListLowStockProducts))
+	r.engine.POST("/api/v1/admin/products/import", r.withTimeout(http.MethodPost, "/api/v1/admin/products/import", 120*time.Second), r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionProductWrite), gin.WrapF(r.productHandler.BulkImport))
 
 	// Category routes - Public
 	r.engine.GET("/api/v1/categories", gin.WrapF(r.productHandler.ListCategories))
-	r.engine.GET("/api/v1/categories/by-id", gin.WrapF(r.productHandler.GetCategoryByID))
+	r.handleMoved(http.MethodGet, "/api/v1/categories/:id", "/api/v1/categories/by-id", r.withPathParams(), gin.WrapF(r.productHandler.GetCategoryByID))
 
 	// Category routes - Admin only
-	r.engine.POST("/api/v1/categories/create", r.withAuth(), r.withRole("admin"), gin.WrapF(r.productHandler.CreateCategory))
-	r.engine.PUT("/api/v1/categories/update", r.withAuth(), r.withRole("admin"), gin.WrapF(r.productHandler.UpdateCategory))
-	r.engine.DELETE("/api/v1/categories/delete", r.withAuth(), r.withRole("admin"), gin.WrapF(r.productHandler.DeleteCategory))
+	r.handleMoved(http.MethodPost, "/api/v1/categories", "/api/v1/categories/create", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionCategoryWrite), gin.WrapF(r.productHandler.CreateCategory))
+	r.handleMoved(http.MethodPut, "/api/v1/categories/:id", "/api/v1/categories/update", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionCategoryWrite), r.withPathParams(), gin.WrapF(r.productHandler.UpdateCategory))
+	r.handleMoved(http.MethodDelete, "/api/v1/categories/:id", "/api/v1/categories/delete", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionCategoryWrite), r.withPathParams(), gin.WrapF(r.productHandler.DeleteCategory))
+
+	// Cart routes - Authenticated, or anonymous with a guest cart
+	r.engine.GET("/api/v1/cart", r.withOptionalAuth(), gin.WrapF(r.cartHandler.GetCart))
+	r.handleMoved(http.MethodPost, "/api/v1/cart/items", "/api/v1/cart/items/add", r.withOptionalAuth(), gin.WrapF(r.cartHandler.AddItem))
+	r.handleMoved(http.MethodPut, "/api/v1/cart/items", "/api/v1/cart/items/update", r.withOptionalAuth(), gin.WrapF(r.cartHandler.UpdateItem))
+	r.handleMoved(http.MethodDelete, "/api/v1/cart/items", "/api/v1/cart/items/remove", r.withOptionalAuth(), gin.WrapF(r.cartHandler.RemoveItem))
+	r.handleMoved(http.MethodDelete, "/api/v1/cart", "/api/v1/cart/clear", r.withOptionalAuth(), gin.WrapF(r.cartHandler.ClearCart))
 
-	// Cart routes - Authenticated
-	r.engine.GET("/api/v1/cart", r.withAuth(), gin.WrapF(r.cartHandler.GetCart))
-	r.engine.POST("/api/v1/cart/items/add", r.withAuth(), gin.WrapF(r.cartHandler.AddItem))
-	r.engine.PUT("/api/v1/cart/items/update", r.withAuth(), gin.WrapF(r.cartHandler.UpdateItem))
-	r.engine.DELETE("/api/v1/cart/items/remove", r.withAuth(), gin.WrapF(r.cartHandler.RemoveItem))
-	r.engine.DELETE("/api/v1/cart/clear", r.withAuth(), gin.WrapF(r.cartHandler.ClearCart))
+	// Wishlist routes - Authenticated
+	r.engine.GET("/api/v1/wishlist", r.withAuth(), r.withFeature("wishlist"), gin.WrapF(r.wishlistHandler.GetWishlist))
+	r.engine.POST("/api/v1/wishlist/items", r.withAuth(), r.withFeature("wishlist"), gin.WrapF(r.wishlistHandler.AddItem))
+	r.engine.DELETE("/api/v1/wishlist/items", r.withAuth(), r.withFeature("wishlist"), gin.WrapF(r.wishlistHandler.RemoveItem))
+
+	// Notification routes - Authenticated
+	r.engine.GET("/api/v1/notifications", r.withAuth(), gin.WrapF(r.notificationHandler.ListNotifications))
+	r.engine.POST("/api/v1/notifications/read", r.withAuth(), gin.WrapF(r.notificationHandler.MarkNotificationsRead))
+	r.engine.PUT("/api/v1/notifications/preferences", r.withAuth(), gin.WrapF(r.notificationHandler.UpdatePreferences))
 
 	// Order routes - Authenticated
-	r.engine.POST("/api/v1/orders/create", r.withAuth(), gin.WrapF(r.orderHandler.CreateOrder))
+	r.handleMoved(http.MethodPost, "/api/v1/orders", "/api/v1/orders/create", r.withAuth(), r.withDeduplication("create_order"), gin.WrapF(r.orderHandler.CreateOrder))
+	r.engine.POST("/api/v1/orders/shipping-quote", r.withAuth(), gin.WrapF(r.orderHandler.GetShippingQuote))
 	r.engine.GET("/api/v1/orders", r.withAuth(), gin.WrapF(r.orderHandler.ListOrders))
-	r.engine.GET("/api/v1/orders/by-id", r.withAuth(), gin.WrapF(r.orderHandler.GetOrderByID))
-	r.engine.POST("/api/v1/orders/items/add", r.withAuth(), gin.WrapF(r.orderHandler.AddOrderItem))
-	r.engine.DELETE("/api/v1/orders/items/remove", r.withAuth(), gin.WrapF(r.orderHandler.RemoveOrderItem))
+	r.handleMoved(http.MethodGet, "/api/v1/orders/:id", "/api/v1/orders/by-id", r.withAuth(), r.withPathParams(), gin.WrapF(r.orderHandler.GetOrderByID))
+	r.handleMoved(http.MethodPost, "/api/v1/orders/:id/items", "/api/v1/orders/items/add", r.withAuth(), r.withPathParams(), gin.WrapF(r.orderHandler.AddOrderItem))
+	r.handleMoved(http.MethodDelete, "/api/v1/orders/:id/items/:itemID", "/api/v1/orders/items/remove", r.withAuth(), r.withPathParams(), gin.WrapF(r.orderHandler.RemoveOrderItem))
+	r.engine.PATCH("/api/v1/orders/:id/items/:itemID", r.withAuth(), r.orderHandler.UpdateOrderItemQuantity)
+	r.engine.GET("/api/v1/orders/:id/invoice", r.withAuth(), r.orderHandler.GetOrderInvoice)
+	// Status streams stay open until the order is delivered or canceled, far beyond the global RequestTimeout
+	r.engine.GET("/api/v1/orders/:id/status/stream", r.withTimeout(http.MethodGet, "/api/v1/orders/:id/status/stream", time.Hour), r.withAuth(), r.withStream(), r.orderHandler.WatchOrderStatus)
 
 	// Order routes - Admin only
-	r.engine.PATCH("/api/v1/orders/status", r.withAuth(), r.withRole("admin"), gin.WrapF(r.orderHandler.UpdateOrderStatus))
+	r.handleMoved(http.MethodPatch, "/api/v1/orders/:id/status", "/api/v1/orders/status", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionOrderWrite), r.withPathParams(), gin.WrapF(r.orderHandler.UpdateOrderStatus))
+	r.engine.GET("/api/v1/admin/orders", r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionOrderRead), gin.WrapF(r.orderHandler.AdminListOrders))
+	// Exports run as long as there are orders to page through, far beyond the global RequestTimeout
+	r.engine.GET("/api/v1/admin/orders/export", r.withTimeout(http.MethodGet, "/api/v1/admin/orders/export", time.Hour), r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionOrderRead), r.withStream(), r.orderHandler.ExportOrders)
+
+	// Report routes - Admin only
+	r.engine.GET("/api/v1/admin/reports/revenue", r.withTimeout(http.MethodGet, "/api/v1/admin/reports/revenue", 120*time.Second), r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionReportRead), gin.WrapF(r.reportHandler.Revenue))
+	r.engine.GET("/api/v1/admin/reports/sales", r.withTimeout(http.MethodGet, "/api/v1/admin/reports/sales", 120*time.Second), r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionReportRead), gin.WrapF(r.reportHandler.Sales))
+	r.engine.GET("/api/v1/admin/reports/top-products", r.withTimeout(http.MethodGet, "/api/v1/admin/reports/top-products", 120*time.Second), r.withAuthOrAPIKey(), r.withPermission(customJWT.PermissionReportRead), gin.WrapF(r.reportHandler.TopProducts))
+
+	// Operational routes - Admin only
+	r.engine.GET("/api/v1/admin/log-level", r.withAuth(), r.withRole("admin"), gin.WrapF(r.adminHandler.GetLogLevel))
+	r.engine.PUT("/api/v1/admin/log-level", r.withAuth(), r.withRole("admin"), gin.WrapF(r.adminHandler.SetLogLevel))
+	r.engine.POST("/api/v1/admin/maintenance/enable", r.withAuth(), r.withRole("admin"), gin.WrapF(r.adminHandler.EnableMaintenance))
+	r.engine.POST("/api/v1/admin/maintenance/disable", r.withAuth(), r.withRole("admin"), gin.WrapF(r.adminHandler.DisableMaintenance))
+	r.engine.GET("/api/v1/admin/services/status", r.withAuth(), r.withRole("admin"), gin.WrapF(r.adminHandler.GetServiceConnectivity))
+	r.engine.POST("/api/v1/admin/services/:name/reconnect", r.withAuth(), r.withRole("admin"), r.adminHandler.ReconnectService)
+	r.engine.POST("/api/v1/admin/users/:id/unlock", r.withAuth(), r.withRole("admin"), r.userHandler.UnlockUser)
+	r.engine.POST("/api/v1/admin/impersonate/:userId", r.withAuth(), r.withRole("admin"), r.userHandler.ImpersonateUser)
+	r.engine.POST("/api/v1/admin/config/reload", r.withAuth(), r.withRole("admin"), gin.WrapF(r.adminHandler.ReloadConfig))
+	r.engine.POST("/api/v1/admin/api-keys", r.withAuth(), r.withRole("admin"), gin.WrapF(r.apiKeyHandler.CreateAPIKey))
+	r.engine.GET("/api/v1/admin/api-keys", r.withAuth(), r.withRole("admin"), gin.WrapF(r.apiKeyHandler.ListAPIKeys))
+	r.engine.DELETE("/api/v1/admin/api-keys/:id", r.withAuth(), r.withRole("admin"), r.apiKeyHandler.RevokeAPIKey)
+
+	// GraphQL - Public; fields acting for a user need a token
+	r.engine.POST("/graphql", r.withOptionalAuth(), gin.WrapF(r.graphqlHandler.Query))
+
+	// gRPC-Web - Public catalog reads, only when GRPC_WEB_ENABLED is set
+	if r.grpcWebProxy != nil {
+		r.engine.Any(grpcWebPrefix+"*method", gin.WrapH(r.grpcWebProxy))
+	}
+
+	// Swagger UI and the generated spec, only when ENABLE_SWAGGER is set
+	if r.cfg.EnableSwagger {
+		r.engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+}
+
+// setupPprofRoutes mounts the net/http/pprof handlers for admins or holders of the debug token
+func (r *Router) setupPprofRoutes() {
+	debug := r.engine.Group(pprofPrefix,
+		middleware.SkipDebugToken(r.cfg.PprofToken, r.withAuth()),
+		middleware.SkipDebugToken(r.cfg.PprofToken, r.withRole("admin")),
+	)
+	debug.GET("/", gin.WrapF(pprof.Index))
+	debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("/profile", gin.WrapF(pprof.Profile))
+	debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+	debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/trace", gin.WrapF(pprof.Trace))
+	// heap, goroutine, allocs, block, mutex and threadcreate are served by Index
+	debug.GET("/:profile", gin.WrapF(pprof.Index))
 }
 
 // Handler returns the configured HTTP handler with all middlewares
@@ -118,24 +339,228 @@ func (r *Router) Engine() *gin.Engine {
 }
 
 func (r *Router) setupMiddleware() {
-	r.engine.Use(middleware.CORS(r.cfg.AllowedOrigins, r.cfg.AllowedMethods, r.cfg.AllowedHeaders))
-	r.engine.Use(middleware.Recovery())
+	// First, so that every response over TLS carries it, refusals and errors included
+	if r.cfg.TLSEnabled {
+		r.engine.Use(middleware.HSTS())
+	}
+	// The gRPC-Web proxy answers its own preflights, which must allow the gRPC-Web headers
+	r.engine.Use(middleware.SkipPrefix(grpcWebPrefix, middleware.CORS(r.cors)))
+	r.engine.Use(middleware.Recovery(middleware.RecoveryConfig{Reporter: r.cfg.PanicReporter}))
 	r.engine.Use(middleware.RequestID())
-	r.engine.Use(middleware.Logger())
+	r.engine.Use(middleware.ClientIP())
+	// Before the routes' auth, which they rely on to find the user once the request is done. gRPC-Web calls
+	// are all POSTs, reads included, with bodies that cannot be redacted, so only their impersonation is audited.
+	if r.auditLog != nil {
+		r.engine.Use(middleware.ImpersonationAudit(r.auditLog))
+		r.engine.Use(middleware.SkipPrefix(grpcWebPrefix, middleware.AuditLogger(r.auditLog, r.cfg.AuditRedactFields)))
+	}
+	r.engine.Use(middleware.SkipPrefix(pprofPrefix, middleware.Logger()))
+	// Blocked traffic is refused before it reaches the handlers or the rate limiter's quota
+	r.engine.Use(middleware.BlockList(r.blockList))
+	// Bodies are only logged for explicitly listed paths, never for every route
+	if r.cfg.BodyLogEnabled && len(r.cfg.BodyLogPaths) > 0 {
+		r.engine.Use(middleware.BodyLogger(middleware.BodyLoggerConfig{
+			Paths:        r.cfg.BodyLogPaths,
+			MaxBytes:     r.cfg.BodyLogMaxBytes,
+			RedactFields: r.cfg.BodyLogRedactFields,
+		}))
+	}
 	r.engine.Use(middleware.Cancellation())
-	r.engine.Use(middleware.Timeout(r.cfg.RequestTimeout))
-	r.engine.Use(middleware.NewRateLimiter(r.cfg.RateLimitRequests, r.cfg.RateLimitWindow).Middleware())
+	if r.maintenance != nil {
+		r.engine.Use(middleware.MaintenanceMode(r.maintenance, r.jwtManager, r.cfg.AdminRequireTwoFactor))
+	}
+	// Integration tests can pick their own timeout; production ignores the header
+	if r.cfg.AppEnv == "development" {
+		r.engine.Use(middleware.DeveloperTimeout())
+	}
+	// Profiles can run longer than regular requests and must not be cut by the request timeout or rate limit
+	r.engine.Use(middleware.SkipPrefix(pprofPrefix, middleware.Timeout(r.timeouts)))
+	r.engine.Use(middleware.SkipPrefix(pprofPrefix, r.rateLimiter.Middleware()))
+}
+
+// handleMoved registers a route at path and, for one release, at the oldPath it moved from. Responses on
+// oldPath carry a Deprecation header and a Link to path.
+func (r *Router) handleMoved(method, path, oldPath string, handlers ...gin.HandlerFunc) {
+	r.engine.Handle(method, path, handlers...)
+	r.engine.Handle(method, oldPath, append([]gin.HandlerFunc{middleware.Deprecated(path)}, handlers...)...)
+}
+
+// withPrices converts the route's prices into the currency the request asks for, shown in its language
+func (r *Router) withPrices() gin.HandlerFunc {
+	return middleware.NegotiatePrices(r.currencies)
+}
+
+// withPathParams hands the route's path parameters to its handler, for handlers that only get the
+// *http.Request; it goes right before the handler
+func (r *Router) withPathParams() gin.HandlerFunc {
+	return middleware.PathParams()
 }
 
 func (r *Router) withAuth() gin.HandlerFunc {
-	return middleware.AuthMiddleware(r.jwtManager)
+	return middleware.AuthMiddleware(r.jwtManager, r.revoker)
+}
+
+// withOptionalAuth authenticates requests that send a token, with the same checks as withAuth, and lets
+// anonymous ones through
+func (r *Router) withOptionalAuth() gin.HandlerFunc {
+	auth := r.withAuth()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// withAuthOrAPIKey accepts a JWT or, from server-to-server consumers, an X-API-Key. A key's scopes are its
+// permissions, so the route goes on to check them with withPermission.
+func (r *Router) withAuthOrAPIKey() gin.HandlerFunc {
+	auth := r.withAuth()
+	if r.apiKeys == nil {
+		return auth
+	}
+	apiKeyAuth := middleware.APIKeyAuthMiddleware(r.apiKeys)
+	return func(c *gin.Context) {
+		if c.GetHeader(middleware.APIKeyHeader) != "" {
+			apiKeyAuth(c)
+			return
+		}
+		auth(c)
+	}
+}
+
+// withTimeout gives a route its own deadline instead of the global RequestTimeout.
+// ROUTE_TIMEOUTS_JSON entries keyed "METHOD /path" take precedence over the default given here.
+func (r *Router) withTimeout(method, path string, defaultTimeout time.Duration) gin.HandlerFunc {
+	r.timedRoutes[method+" "+path] = struct{}{}
+	return middleware.RouteTimeout(r.timeouts, method+" "+path, defaultTimeout)
+}
+
+// withStream tracks a long-lived SSE or WebSocket route so shutdown drains it instead of cutting it off
+func (r *Router) withStream() gin.HandlerFunc {
+	if r.connections == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return r.connections.Stream()
+}
+
+// withoutImpersonation refuses impersonation tokens on routes support staff must not use as the user; it
+// goes after withAuth
+func (r *Router) withoutImpersonation() gin.HandlerFunc {
+	return middleware.RejectImpersonation()
 }
 
+// withDeduplication answers repeats of a request to the route named route with its first response, whichever
+// of the route's paths they use; it goes after withAuth
+func (r *Router) withDeduplication(route string) gin.HandlerFunc {
+	if r.dedup == nil || r.cfg.DedupTTL == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return middleware.Deduplication(r.dedup, r.cfg.DedupTTL, middleware.DeduplicationKey(route))
+}
+
+// withFeature hides the route behind a feature flag; on authenticated routes it goes after withAuth
+func (r *Router) withFeature(flagName string) gin.HandlerFunc {
+	return middleware.FeatureGate(r.features, flagName)
+}
+
+// withRole checks the user's role; with ADMIN_REQUIRE_2FA, admin routes also need a two-factor token
 func (r *Router) withRole(roles ...string) gin.HandlerFunc {
+	if r.cfg.AdminRequireTwoFactor && slices.Contains(roles, "admin") {
+		return middleware.RequireRoleTwoFactor(roles...)
+	}
 	return middleware.RequireRole(roles...)
 }
 
+// withPermission checks the token's permissions; with ADMIN_REQUIRE_2FA, admins also need a two-factor token
+func (r *Router) withPermission(perms ...string) gin.HandlerFunc {
+	if r.cfg.AdminRequireTwoFactor {
+		return middleware.RequirePermissionTwoFactor(perms...)
+	}
+	return middleware.RequirePermission(perms...)
+}
+
+// jwks publishes the public keys that verify RS256 and EdDSA tokens, so other services can verify without
+// holding a secret. There is nothing to publish under HS256.
+// @Summary Public signing keys
+// @Description Returns the JWKS that verifies RS256 and EdDSA tokens. Responds 404 under HS256.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} customJWT.JWKS
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /.well-known/jwks.json [get]
+func (r *Router) jwks(c *gin.Context) {
+	keys := r.jwtManager.PublicKeys()
+	if len(keys) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": http.StatusText(http.StatusNotFound), "message": "no public keys are configured", "code": http.StatusNotFound})
+		return
+	}
+
+	jwks, err := customJWT.NewJWKS(keys)
+	if err != nil {
+		logger.Errorf("event=jwks_encode_failed error=%v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": http.StatusText(http.StatusInternalServerError), "message": "failed to encode keys", "code": http.StatusInternalServerError})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
+
 // healthCheck endpoint
+// @Summary Gateway health
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /health [get]
+// @Router /api/v1/health [get]
 func (r *Router) healthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy", "service": "api-gateway"})
 }
+
+// readinessCheck reports each service connection's state and calls every service with a cheap read-only RPC.
+// It answers 503 once shutdown has begun, so load balancers stop sending traffic, and while any service is
+// unreachable or fails its call within 2s, naming the error in errors. Idle connections count as ready, as
+// they reconnect on the next call. Unlike /health, which only shows the gateway is alive, this is the
+// readiness probe.
+// @Summary Backend readiness
+// @Tags health
+// @Produce json
+// @Success 200 {object} ReadinessResponse
+// @Failure 503 {object} ReadinessResponse
+// @Router /ready [get]
+// @Router /api/v1/health/ready [get]
+func (r *Router) readinessCheck(c *gin.Context) {
+	resp := ReadinessResponse{Status: "ready", Services: make(map[string]string)}
+	for service, state := range r.services.HealthStatus() {
+		resp.Services[service] = state.String()
+		if state != connectivity.Ready && state != connectivity.Idle {
+			resp.Status = "not_ready"
+		}
+	}
+	for service, err := range r.services.Probe(c.Request.Context(), readinessProbeTimeout) {
+		if resp.Errors == nil {
+			resp.Errors = make(map[string]string)
+		}
+		resp.Errors[service] = err.Error()
+		resp.Status = "not_ready"
+	}
+	if r.connections != nil && r.connections.ShuttingDown() {
+		resp.Status = "draining"
+	}
+
+	if resp.Status != "ready" {
+		c.JSON(http.StatusServiceUnavailable, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ReadinessResponse is ready, not_ready or draining, with service names mapped to connection states such as
+// READY or TRANSIENT_FAILURE, and to the error of each service that failed its probe
+type ReadinessResponse struct {
+	Status   string            `json:"status"`
+	Services map[string]string `json:"services"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
@@ -0,0 +1,665 @@
+package router
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/config"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/audit"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/handlers"
+	"github.com/kareemhamed001/e-commerce/services/ApiGateway/internal/middleware"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// fakeServices reports fixed connection states and probe errors
+type fakeServices struct {
+	states map[string]connectivity.State
+	errors map[string]error
+}
+
+func (f fakeServices) HealthStatus() map[string]connectivity.State {
+	return f.states
+}
+
+func (f fakeServices) Probe(context.Context, time.Duration) map[string]error {
+	return f.errors
+}
+
+func TestReadinessCheck(t *testing.T) {
+	draining := middleware.NewConnectionTracker()
+	draining.BeginShutdown()
+
+	ready := fakeServices{states: map[string]connectivity.State{"user": connectivity.Ready, "cart": connectivity.Idle}}
+	tests := []struct {
+		name        string
+		services    fakeServices
+		connections *middleware.ConnectionTracker
+		wantStatus  int
+		want        string
+	}{
+		{name: "ready without a connection tracker", services: ready, wantStatus: http.StatusOK, want: "ready"},
+		{name: "ready", services: ready, connections: middleware.NewConnectionTracker(), wantStatus: http.StatusOK, want: "ready"},
+		{name: "draining", services: ready, connections: draining, wantStatus: http.StatusServiceUnavailable, want: "draining"},
+		{
+			name:       "service down",
+			services:   fakeServices{states: map[string]connectivity.State{"user": connectivity.TransientFailure}},
+			wantStatus: http.StatusServiceUnavailable,
+			want:       "not_ready",
+		},
+		{
+			name:       "probe failed",
+			services:   fakeServices{states: ready.states, errors: map[string]error{"user": errors.New("deadline exceeded")}},
+			wantStatus: http.StatusServiceUnavailable,
+			want:       "not_ready",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Router{services: tt.services, connections: tt.connections}
+			engine := gin.New()
+			engine.GET("/ready", r.readinessCheck)
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+			var resp ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.wantStatus || resp.Status != tt.want {
+				t.Fatalf("got %d %q, want %d %q", w.Code, resp.Status, tt.wantStatus, tt.want)
+			}
+			for service, err := range tt.services.errors {
+				if resp.Errors[service] != err.Error() {
+					t.Errorf("errors = %v, want %s to fail with %q", resp.Errors, service, err)
+				}
+			}
+		})
+	}
+}
+
+// TestHealthIsLivenessAndReadyIsReadiness checks both probes as routed, with the order service timing out
+func TestHealthIsLivenessAndReadyIsReadiness(t *testing.T) {
+	engine := newTestEngineWith(t, Deps{Services: fakeServices{
+		states: map[string]connectivity.State{"user": connectivity.Ready, "order": connectivity.Ready},
+		errors: map[string]error{"order": context.DeadlineExceeded},
+	}})
+
+	tests := []struct {
+		target     string
+		wantStatus int
+		want       string
+	}{
+		{target: "/health", wantStatus: http.StatusOK, want: `{"service":"api-gateway","status":"healthy"}`},
+		{target: "/api/v1/health", wantStatus: http.StatusOK, want: `{"service":"api-gateway","status":"healthy"}`},
+		{
+			target:     "/api/v1/health/ready",
+			wantStatus: http.StatusServiceUnavailable,
+			want:       `{"status":"not_ready","services":{"order":"READY","user":"READY"},"errors":{"order":"context deadline exceeded"}}`,
+		},
+		{
+			target:     "/ready",
+			wantStatus: http.StatusServiceUnavailable,
+			want:       `{"status":"not_ready","services":{"order":"READY","user":"READY"},"errors":{"order":"context deadline exceeded"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			// No credentials: neither probe requires authentication
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if w.Code != tt.wantStatus || w.Body.String() != tt.want {
+				t.Fatalf("got %d %s, want %d %s", w.Code, w.Body, tt.wantStatus, tt.want)
+			}
+		})
+	}
+}
+
+// newTestEngine registers every route on an engine, without clients behind them, for tests of the route table
+func newTestEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	return newTestEngineWith(t, Deps{})
+}
+
+// newTestEngineWith registers every route on an engine with deps
+func newTestEngineWith(t *testing.T, deps Deps) *gin.Engine {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("INTERNAL_AUTH_TOKEN", "internal-token")
+	t.Setenv("REDIS_ENABLED", "false")
+	t.Chdir(t.TempDir())
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := gin.New()
+	NewRouter(engine, cfg, deps)
+	return engine
+}
+
+func TestMetricsAreNotServedOnTheAPI(t *testing.T) {
+	for _, route := range newTestEngine(t).Routes() {
+		if route.Path == "/metrics" {
+			t.Fatalf("%s %s is routed on the public listener", route.Method, route.Path)
+		}
+	}
+}
+
+func TestAuthAcceptsEveryConfiguredKey(t *testing.T) {
+	t.Setenv("JWT_SECRETS", "2026-06:secretB,2026-01:secretA")
+	engine := newTestEngine(t)
+	tokenFor := func(keys ...customJWT.Key) string {
+		t.Helper()
+		signer := customJWT.NewJWTManager("", time.Hour)
+		signer.SetKeys(keys)
+		signer.SetIssuerAudience("user-service", "api-gateway")
+		token, err := signer.Generate(7, "mona@example.com", "customer")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		// The admin route refuses a customer after authentication, so 403 shows the token was accepted
+		{name: "current key", token: tokenFor(customJWT.Key{ID: "2026-06", Secret: "secretB"}), want: http.StatusForbidden},
+		{name: "retired key", token: tokenFor(customJWT.Key{ID: "2026-01", Secret: "secretA"}), want: http.StatusForbidden},
+		{name: "unknown kid", token: tokenFor(customJWT.Key{ID: "2025-07", Secret: "secretA"}), want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/services/status", nil)
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestJWKSPublishesThePublicKeys(t *testing.T) {
+	getJWKS := func(engine *gin.Engine) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+		return w
+	}
+
+	// HS256 has no public key to publish
+	if w := getJWKS(newTestEngine(t)); w.Code != http.StatusNotFound {
+		t.Fatalf("HS256: status = %d, want 404", w.Code)
+	}
+
+	key, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwt.pub.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JWT_ALG", customJWT.AlgorithmEdDSA)
+	t.Setenv("JWT_PUBLIC_KEY_FILES", path)
+
+	w := getJWKS(newTestEngine(t))
+	var jwks customJWT.JWKS
+	if err := json.Unmarshal(w.Body.Bytes(), &jwks); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("EdDSA: got %d %s", w.Code, w.Body)
+	}
+	kid, _ := customJWT.Thumbprint(key)
+	if keys := jwks.PublicKeys(); len(keys) != 1 || keys[0].ID != kid || !key.Equal(keys[0].Key) {
+		t.Errorf("published %+v, want the configured key with kid %s", keys, kid)
+	}
+	if cacheControl := w.Header().Get("Cache-Control"); cacheControl != "public, max-age=300" {
+		t.Errorf("Cache-Control = %q, want the keys cached for 5 minutes", cacheControl)
+	}
+}
+
+func TestDeveloperTimeoutHeaderOnlyAppliesInDevelopment(t *testing.T) {
+	tests := []struct {
+		appEnv string
+		want   time.Duration
+	}{
+		{appEnv: "development", want: 5 * time.Second},
+		// The header is ignored, leaving the 30s default request timeout
+		{appEnv: "production", want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.appEnv, func(t *testing.T) {
+			t.Setenv("APP_ENV", tt.appEnv)
+			t.Setenv("GUEST_CART_SECRET", "guest-secret")
+			engine := newTestEngine(t)
+			// Registered after the router's middleware, which runs ahead of it as for every route
+			engine.GET("/deadline", func(c *gin.Context) {
+				deadline, _ := c.Request.Context().Deadline()
+				c.String(http.StatusOK, time.Until(deadline).String())
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/deadline", nil)
+			r.Header.Set(middleware.DeveloperTimeoutHeader, "5000")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, r)
+			left, err := time.ParseDuration(w.Body.String())
+			if err != nil {
+				t.Fatalf("got %d %s, want the time left", w.Code, w.Body)
+			}
+			if left > tt.want || left < tt.want-time.Second {
+				t.Fatalf("the handler had %v left, want about %v", left, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserSearchIsAdminOnly(t *testing.T) {
+	// The handler answers 400 to the invalid role without calling the user service, which shows it was reached
+	engine := newTestEngineWith(t, Deps{UserHandler: handlers.NewUserHandler(nil, nil, nil, nil, nil, nil, nil, nil)})
+	tokenFor := func(role string) string {
+		t.Helper()
+		signer := customJWT.NewJWTManager("secret", time.Hour)
+		signer.SetIssuerAudience("user-service", "api-gateway")
+		token, err := signer.Generate(7, "mona@example.com", role)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{name: "anonymous", want: http.StatusUnauthorized},
+		{name: "customer", token: tokenFor("customer"), want: http.StatusForbidden},
+		{name: "admin", token: tokenFor("admin"), want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		for _, target := range []string{"/api/v1/users?role=invalid", "/api/v1/users/search?role=invalid"} {
+			t.Run(tt.name+" "+target, func(t *testing.T) {
+				r := httptest.NewRequest(http.MethodGet, target, nil)
+				if tt.token != "" {
+					r.Header.Set("Authorization", "Bearer "+tt.token)
+				}
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, r)
+				if w.Code != tt.want {
+					t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+				}
+			})
+		}
+	}
+}
+
+// deletingProducts finds and deletes any product; the other product service methods panic through the nil
+// interface
+type deletingProducts struct {
+	productpb.ProductServiceClient
+}
+
+func (deletingProducts) GetProductByID(_ context.Context, in *productpb.GetProductByIDRequest, _ ...grpc.CallOption) (*productpb.GetProductByIDResponse, error) {
+	return &productpb.GetProductByIDResponse{Product: &productpb.Product{Id: int32(in.GetId()), Name: "Lamp"}}, nil
+}
+
+func (deletingProducts) DeleteProduct(context.Context, *productpb.DeleteProductRequest, ...grpc.CallOption) (*productpb.DeleteProductResponse, error) {
+	return &productpb.DeleteProductResponse{Success: true}, nil
+}
+
+// auditEntries keeps the audit entries recorded
+type auditEntries []audit.Entry
+
+func (a *auditEntries) Record(entry audit.Entry) error {
+	*a = append(*a, entry)
+	return nil
+}
+
+func TestProductDeleteIsAudited(t *testing.T) {
+	var entries auditEntries
+	engine := newTestEngineWith(t, Deps{
+		ProductHandler: handlers.NewProductHandler(deletingProducts{}, nil, "", "", 0, nil),
+		AuditLog:       &entries,
+	})
+	signer := customJWT.NewJWTManager("secret", time.Hour)
+	signer.SetIssuerAudience("user-service", "api-gateway")
+	token, err := signer.Generate(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodDelete, "/api/v1/products/42", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	r.Header.Set("X-Request-ID", "req-42")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("entries = %+v, want one", entries)
+	}
+	entry := entries[0]
+	if entry.Event != "mutating_request" || entry.Action != "DELETE /api/v1/products/:id" || entry.Resource != "/api/v1/products/42" ||
+		entry.UserID != 1 || entry.Role != "admin" || entry.RequestID != "req-42" || entry.Outcome != "success" {
+		t.Errorf("entry = %+v, want the admin's successful delete of product 42", entry)
+	}
+}
+
+func TestMovedRoutesAnswerOnBothPaths(t *testing.T) {
+	engine := newTestEngineWith(t, Deps{ProductHandler: handlers.NewProductHandler(deletingProducts{}, nil, "", "", 100, nil)})
+	signer := customJWT.NewJWTManager("secret", time.Hour)
+	signer.SetIssuerAudience("user-service", "api-gateway")
+	admin, err := signer.Generate(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		token    string
+		wantBody string
+		wantLink string
+	}{
+		{name: "product", method: http.MethodGet, target: "/api/v1/products/42", wantBody: `"name":"Lamp"`},
+		{name: "deprecated product", method: http.MethodGet, target: "/api/v1/products/by-id?id=42", wantBody: `"name":"Lamp"`, wantLink: `</api/v1/products/42>; rel="successor-version"`},
+		{name: "product delete", method: http.MethodDelete, target: "/api/v1/products/42", token: admin, wantBody: `"success":true`},
+		{name: "deprecated product delete", method: http.MethodDelete, target: "/api/v1/products/delete?id=42", token: admin, wantBody: `"success":true`, wantLink: `</api/v1/products/42>; rel="successor-version"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Fatalf("got %d %s, want 200 with %s", w.Code, w.Body, tt.wantBody)
+			}
+			if got := w.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("Link = %q, want %q", got, tt.wantLink)
+			}
+			// Only the old paths are deprecated, and those link to their successor
+			wantDeprecation := ""
+			if tt.wantLink != "" {
+				wantDeprecation = "true"
+			}
+			if got := w.Header().Get("Deprecation"); got != wantDeprecation {
+				t.Errorf("Deprecation = %q, want %q", got, wantDeprecation)
+			}
+		})
+	}
+}
+
+func TestMovedRoutesKeepTheirAuth(t *testing.T) {
+	engine := newTestEngineWith(t, Deps{ProductHandler: handlers.NewProductHandler(deletingProducts{}, nil, "", "", 100, nil)})
+
+	for _, target := range []string{"/api/v1/products/42", "/api/v1/products/delete?id=42"} {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, target, nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("anonymous DELETE %s: status = %d, want 401", target, w.Code)
+		}
+	}
+}
+
+func TestAdminRequireTwoFactorCoversEveryAdminRoute(t *testing.T) {
+	t.Setenv("ADMIN_REQUIRE_2FA", "true")
+	t.Setenv("ENABLE_PPROF", "true")
+	maintenance := middleware.NewRedisMaintenanceStore(nil)
+	engine := newTestEngineWith(t, Deps{
+		ProductHandler: handlers.NewProductHandler(deletingProducts{}, nil, "", "", 0, nil),
+		AdminHandler:   handlers.NewAdminHandler(maintenance, nil, nil),
+		Maintenance:    maintenance,
+	})
+	signer := customJWT.NewJWTManager("secret", time.Hour)
+	signer.SetIssuerAudience("user-service", "api-gateway")
+	passwordOnly, err := signer.Generate(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	twoFactor, err := signer.GenerateTwoFactor(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serve := func(method, target, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+
+	routes := []struct {
+		method string
+		target string
+	}{
+		{method: http.MethodDelete, target: "/api/v1/users/7"},
+		{method: http.MethodPost, target: "/api/v1/products"},
+		{method: http.MethodDelete, target: "/api/v1/products/42"},
+		{method: http.MethodPatch, target: "/api/v1/products/42/stock"},
+		{method: http.MethodGet, target: "/api/v1/admin/products/42/stock-movements"},
+		{method: http.MethodPost, target: "/api/v1/admin/products/import"},
+		{method: http.MethodPut, target: "/api/v1/categories/3"},
+		{method: http.MethodPatch, target: "/api/v1/orders/9/status"},
+		{method: http.MethodGet, target: "/api/v1/admin/orders"},
+		{method: http.MethodGet, target: "/api/v1/admin/orders/export"},
+		{method: http.MethodGet, target: "/api/v1/admin/reports/revenue"},
+		{method: http.MethodGet, target: "/debug/pprof/"},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" "+route.target, func(t *testing.T) {
+			w := serve(route.method, route.target, passwordOnly)
+			if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "two-factor") {
+				t.Fatalf("got %d %s, want 403 asking for two-factor authentication", w.Code, w.Body)
+			}
+		})
+	}
+
+	// The same admin passes once verified, which the product delete shows by reaching the product service
+	if w := serve(http.MethodDelete, "/api/v1/products/42", twoFactor); w.Code != http.StatusOK {
+		t.Errorf("two-factor admin: got %d %s, want 200", w.Code, w.Body)
+	}
+
+	// During maintenance only the verified admin gets through to the admin API
+	if err := maintenance.SetMaintenance(context.Background(), true, 0); err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(http.MethodGet, "/api/v1/admin/log-level", passwordOnly); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("password-only admin during maintenance: got %d %s, want 503", w.Code, w.Body)
+	}
+	if w := serve(http.MethodGet, "/api/v1/admin/log-level", twoFactor); w.Code != http.StatusOK {
+		t.Errorf("two-factor admin during maintenance: got %d %s, want 200", w.Code, w.Body)
+	}
+}
+
+func TestPprofUsesTheGatewayAuthChain(t *testing.T) {
+	t.Setenv("ENABLE_PPROF", "true")
+	t.Setenv("PPROF_TOKEN", "debug-secret")
+	revoker := middleware.NewTokenRevoker(nil, time.Hour)
+	engine := newTestEngineWith(t, Deps{Revoker: revoker})
+	signer := customJWT.NewJWTManager("secret", time.Hour)
+	signer.SetIssuerAudience("user-service", "api-gateway")
+	admin, err := signer.Generate(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer, err := signer.Generate(2, "customer@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serve := func(header, value string) int {
+		r := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := serve("", ""); code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d, want 401", code)
+	}
+	if code := serve(middleware.DebugTokenHeader, "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("wrong debug token: status = %d, want 401", code)
+	}
+	if code := serve(middleware.DebugTokenHeader, "debug-secret"); code != http.StatusOK {
+		t.Errorf("debug token: status = %d, want 200", code)
+	}
+	if code := serve("Authorization", "Bearer "+customer); code != http.StatusForbidden {
+		t.Errorf("customer: status = %d, want 403", code)
+	}
+	if code := serve("Authorization", "Bearer "+admin); code != http.StatusOK {
+		t.Errorf("admin: status = %d, want 200", code)
+	}
+
+	// A revoked admin token is refused here as on every other route
+	if err := revoker.RevokeUser(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if code := serve("Authorization", "Bearer "+admin); code != http.StatusUnauthorized {
+		t.Errorf("revoked admin: status = %d, want 401", code)
+	}
+}
+
+// stockProducts adjusts the stock of any product, starting from 10
+type stockProducts struct {
+	deletingProducts
+}
+
+func (stockProducts) AdjustProductStock(_ context.Context, in *productpb.AdjustProductStockRequest, _ ...grpc.CallOption) (*productpb.AdjustProductStockResponse, error) {
+	return &productpb.AdjustProductStockResponse{ProductId: in.GetProductId(), StockLevel: 10 + in.GetDelta()}, nil
+}
+
+func TestStockRoutesNeedProductWrite(t *testing.T) {
+	engine := newTestEngineWith(t, Deps{ProductHandler: handlers.NewProductHandler(stockProducts{}, nil, "", "", 0, nil)})
+	signer := customJWT.NewJWTManager("secret", time.Hour)
+	signer.SetIssuerAudience("user-service", "api-gateway")
+	// A role granted product:write is enough; the admin role is not required
+	signer.SetRolePermissions(map[string][]string{"catalog_manager": {customJWT.PermissionProductWrite}})
+	catalogManager, err := signer.Generate(1, "catalog@example.com", "catalog_manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer, err := signer.Generate(2, "customer@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		token string
+		want  int
+	}{
+		{token: catalogManager, want: http.StatusOK},
+		{token: customer, want: http.StatusForbidden},
+	} {
+		r := httptest.NewRequest(http.MethodPatch, "/api/v1/products/1/stock", strings.NewReader(`{"delta": 5}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Authorization", "Bearer "+tt.token)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("got %d %s, want %d", w.Code, w.Body, tt.want)
+		}
+	}
+}
+
+// writeCertificate writes a self-signed certificate for localhost and its key, returning their paths
+func writeCertificate(t *testing.T) (certPath, keyPath string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), DNSNames: []string{"localhost"}, NotAfter: time.Now().Add(time.Hour)}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, public, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certPath, keyPath = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+func TestHSTSIsOnlySentOverTLS(t *testing.T) {
+	requests := []struct {
+		path     string
+		wantCode int
+	}{
+		{path: "/health", wantCode: http.StatusOK},
+		// A refusal carries it too
+		{path: "/api/v1/orders", wantCode: http.StatusUnauthorized},
+	}
+	get := func(engine *gin.Engine, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	plain := newTestEngine(t)
+	for _, r := range requests {
+		if hsts := get(plain, r.path).Header().Get("Strict-Transport-Security"); hsts != "" {
+			t.Errorf("plain HTTP: %s sent Strict-Transport-Security %q", r.path, hsts)
+		}
+	}
+
+	certPath, keyPath := writeCertificate(t)
+	t.Setenv("TLS_ENABLED", "true")
+	t.Setenv("TLS_CERT_PATH", certPath)
+	t.Setenv("TLS_KEY_PATH", keyPath)
+	tls := newTestEngine(t)
+	for _, r := range requests {
+		w := get(tls, r.path)
+		if w.Code != r.wantCode {
+			t.Errorf("TLS: %s answered %d, want %d", r.path, w.Code, r.wantCode)
+		}
+		if hsts := w.Header().Get("Strict-Transport-Security"); hsts != "max-age=31536000" {
+			t.Errorf("TLS: %s sent Strict-Transport-Security %q, want max-age=31536000", r.path, hsts)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	redisClient "github.com/kareemhamed001/e-commerce/pkg/redis"
+	"github.com/kareemhamed001/e-commerce/pkg/spiffeauth"
 	"github.com/kareemhamed001/e-commerce/pkg/tracer"
 	"github.com/kareemhamed001/e-commerce/services/CartService/config"
 	"github.com/kareemhamed001/e-commerce/services/CartService/internal/delivery/grpc/handler"
@@ -19,7 +20,6 @@ import (
 	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
 	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
@@ -37,6 +37,13 @@ func main() {
 	shutdownTracer := initTracing(ctx)
 	defer shutdownTracer()
 
+	spiffeSource, err := spiffeauth.NewSource(ctx, config.SPIFFEEndpointSocket)
+	if err != nil {
+		close(done)
+		panic(err)
+	}
+	defer spiffeSource.Close()
+
 	redisCfg := &redisClient.Settings{
 		RedisEnabled:  config.RedisEnabled,
 		RedisHost:     config.RedisHost,
@@ -53,9 +60,10 @@ func main() {
 
 	productConn, err := grpc.NewClient(
 		config.ProductServiceGRPCAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(spiffeSource.ClientCredentials()),
 		grpc.WithChainUnaryInterceptor(
-			grpcmiddleware.InternalAuthUnaryClientInterceptor(config.InternalAuthToken),
+			grpcmiddleware.InternalAuthUnaryClientInterceptor(config.InternalAuthToken, config.ServiceName),
+			grpcmiddleware.RequestIDUnaryClientInterceptor(),
 			grpcmiddleware.CircuitBreakerUnaryClientInterceptor(
 				"cart-service->"+config.ProductServiceGRPCAddr,
 				grpcmiddleware.CircuitBreakerConfig{
@@ -79,9 +87,10 @@ func main() {
 
 	userConn, err := grpc.NewClient(
 		config.UserServiceGRPCAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(spiffeSource.ClientCredentials()),
 		grpc.WithChainUnaryInterceptor(
-			grpcmiddleware.InternalAuthUnaryClientInterceptor(config.InternalAuthToken),
+			grpcmiddleware.InternalAuthUnaryClientInterceptor(config.InternalAuthToken, config.ServiceName),
+			grpcmiddleware.RequestIDUnaryClientInterceptor(),
 			grpcmiddleware.CircuitBreakerUnaryClientInterceptor(
 				"cart-service->"+config.UserServiceGRPCAddr,
 				grpcmiddleware.CircuitBreakerConfig{
@@ -106,17 +115,23 @@ func main() {
 	productClient := productpb.NewProductServiceClient(productConn)
 	userClient := userpb.NewUserServiceClient(userConn)
 
-	cartRepo := redis.NewCartRepository(redisConn)
+	cartRepo := redis.NewCartRepository(redisConn, config.GuestCartTTL)
 	cartUsecase := usecase.NewCartUsecase(cartRepo, productClient, userClient, config.DownstreamTimeout)
 
+	wishlistRepo := redis.NewWishlistRepository(redisConn)
+	wishlistUsecase := usecase.NewWishlistUsecase(wishlistRepo, productClient, userClient, config.DownstreamTimeout)
+
 	validate := validator.New()
-	grpcHandler := handler.NewCartGRPCHandler(cartUsecase, validate, config.InternalAuthToken)
+	wishlistHandler := handler.NewWishlistGRPCHandler(wishlistUsecase, validate)
+	grpcHandler := handler.NewCartGRPCHandler(cartUsecase, wishlistHandler, validate)
 
-	if err := grpcHandler.Run(done, config.GRPCPort); err != nil {
+	serverOpts := append(grpcmiddleware.DefaultServerOptions(logger.NewStructured(config.AppEnv), config.InternalAuthToken, config.InternalAuthPolicy), spiffeSource.ServerOptions()...)
+	if err := grpcHandler.Run(done, config.GRPCPort, serverOpts...); err != nil {
 		logger.Errorf("failed to start gRPC server: %v", err)
 		close(done)
 		panic(err)
 	}
+	grpcmiddleware.ServeMetrics(done, config.MetricsPort)
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
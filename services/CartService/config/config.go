@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 )
 
@@ -24,6 +25,8 @@ type Config struct {
 
 	// gRPC
 	GRPCPort string
+	// MetricsPort serves GET /metrics for Prometheus
+	MetricsPort string
 
 	// Downstream gRPC services
 	ProductServiceGRPCAddr string
@@ -33,11 +36,18 @@ type Config struct {
 	ServiceName string
 
 	// Internal service auth
-	InternalAuthToken string
+	InternalAuthToken  string
+	InternalAuthPolicy grpcmiddleware.InternalAuthPolicy
+
+	// SPIFFEEndpointSocket is the SPIFFE Workload API address; when set, gRPC connections use mTLS with its SVID
+	SPIFFEEndpointSocket string
 
 	// Timeouts
 	DownstreamTimeout time.Duration
 
+	// GuestCartTTL is how long a guest's cart is kept after its last change
+	GuestCartTTL time.Duration
+
 	// Circuit breaker
 	CircuitBreakerEnabled      bool
 	CircuitBreakerMaxRequests  uint32
@@ -77,15 +87,18 @@ func Load() (*Config, error) {
 		RedisPassword: GetEnv("REDIS_PASSWORD", ""),
 		RedisDB:       getEnvInt("REDIS_DB", 0),
 
-		GRPCPort: GetEnv("GRPC_PORT", "50057"),
+		GRPCPort:    GetEnv("GRPC_PORT", "50057"),
+		MetricsPort: GetEnv("METRICS_PORT", "9090"),
 
 		ProductServiceGRPCAddr: GetEnv("PRODUCT_SERVICE_GRPC_ADDR", "localhost:50053"),
 		UserServiceGRPCAddr:    GetEnv("USER_SERVICE_GRPC_ADDR", "localhost:50051"),
 
 		ServiceName:       GetEnv("SERVICE_NAME", "cart-service"),
 		DownstreamTimeout: time.Duration(getEnvInt("DOWNSTREAM_TIMEOUT_SECONDS", 3)) * time.Second,
+		GuestCartTTL:      time.Duration(getEnvInt("GUEST_CART_TTL_HOURS", 168)) * time.Hour,
 
-		InternalAuthToken: GetEnv("INTERNAL_AUTH_TOKEN", ""),
+		InternalAuthToken:    GetEnv("INTERNAL_AUTH_TOKEN", ""),
+		SPIFFEEndpointSocket: GetEnv("SPIFFE_ENDPOINT_SOCKET", ""),
 
 		CircuitBreakerEnabled:      getEnvBool("CB_ENABLED", true),
 		CircuitBreakerMaxRequests:  uint32(getEnvInt("CB_MAX_REQUESTS", 5)),
@@ -95,6 +108,11 @@ func Load() (*Config, error) {
 		CircuitBreakerMinRequests:  uint32(getEnvInt("CB_MIN_REQUESTS", 20)),
 	}
 
+	cfg.InternalAuthPolicy, err = grpcmiddleware.ParseInternalAuthPolicy(os.Getenv("INTERNAL_AUTH_POLICY_JSON"), os.Getenv("INTERNAL_AUTH_DEFAULT"))
+	if err != nil {
+		return nil, err
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
@@ -127,6 +145,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("INTERNAL_AUTH_TOKEN is required")
 	}
 
+	if c.GuestCartTTL <= 0 {
+		return fmt.Errorf("GUEST_CART_TTL_HOURS must be positive")
+	}
+
 	return nil
 }
 
@@ -1,18 +1,33 @@
 package dto
 
+// Each request names a user's cart by UserID or a guest's by GuestToken, never both
+
+type CartOwnerRequest struct {
+	UserID     uint   `json:"user_id" validate:"required_without=GuestToken,excluded_with=GuestToken"`
+	GuestToken string `json:"guest_token" validate:"omitempty,alphanum,max=64"`
+}
+
 type AddItemRequest struct {
-	UserID    uint `json:"user_id" validate:"required,gt=0"`
-	ProductID uint `json:"product_id" validate:"required,gt=0"`
-	Quantity  int  `json:"quantity" validate:"required,gt=0"`
+	UserID     uint   `json:"user_id" validate:"required_without=GuestToken,excluded_with=GuestToken"`
+	GuestToken string `json:"guest_token" validate:"omitempty,alphanum,max=64"`
+	ProductID  uint   `json:"product_id" validate:"required,gt=0"`
+	Quantity   int    `json:"quantity" validate:"required,gt=0"`
 }
 
 type UpdateItemRequest struct {
-	UserID    uint `json:"user_id" validate:"required,gt=0"`
-	ProductID uint `json:"product_id" validate:"required,gt=0"`
-	Quantity  int  `json:"quantity" validate:"required,gt=0"`
+	UserID     uint   `json:"user_id" validate:"required_without=GuestToken,excluded_with=GuestToken"`
+	GuestToken string `json:"guest_token" validate:"omitempty,alphanum,max=64"`
+	ProductID  uint   `json:"product_id" validate:"required,gt=0"`
+	Quantity   int    `json:"quantity" validate:"required,gt=0"`
 }
 
 type RemoveItemRequest struct {
-	UserID    uint `json:"user_id" validate:"required,gt=0"`
-	ProductID uint `json:"product_id" validate:"required,gt=0"`
+	UserID     uint   `json:"user_id" validate:"required_without=GuestToken,excluded_with=GuestToken"`
+	GuestToken string `json:"guest_token" validate:"omitempty,alphanum,max=64"`
+	ProductID  uint   `json:"product_id" validate:"required,gt=0"`
+}
+
+type MergeCartsRequest struct {
+	UserID     uint   `json:"user_id" validate:"required,gt=0"`
+	GuestToken string `json:"guest_token" validate:"required,alphanum,max=64"`
 }
@@ -0,0 +1,6 @@
+package dto
+
+type WishlistItemRequest struct {
+	UserID    uint `json:"user_id" validate:"required,gt=0"`
+	ProductID uint `json:"product_id" validate:"required,gt=0"`
+}
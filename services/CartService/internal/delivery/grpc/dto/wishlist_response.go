@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+type WishlistItemResponse struct {
+	ProductID uint      `json:"product_id"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+type WishlistResponse struct {
+	UserID uint                   `json:"user_id"`
+	Items  []WishlistItemResponse `json:"items"`
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/kareemhamed001/e-commerce/services/CartService/internal/delivery/grpc/dto"
 	"github.com/kareemhamed001/e-commerce/services/CartService/internal/domain"
 	cartpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/cart"
+	wishlistpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/wishlist"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -19,19 +20,20 @@ import (
 type CartGRPCHandler struct {
 	cartpb.UnimplementedCartServiceServer
 	usecase  domain.CartUsecase
+	wishlist *WishlistGRPCHandler
 	validate *validator.Validate
 	tracer   trace.Tracer
-	internalAuthToken string
 }
 
 var _ cartpb.CartServiceServer = (*CartGRPCHandler)(nil)
 
-func NewCartGRPCHandler(usecase domain.CartUsecase, validate *validator.Validate, internalAuthToken string) *CartGRPCHandler {
+// NewCartGRPCHandler creates the cart handler; Run also serves wishlist on the same server
+func NewCartGRPCHandler(usecase domain.CartUsecase, wishlist *WishlistGRPCHandler, validate *validator.Validate) *CartGRPCHandler {
 	return &CartGRPCHandler{
 		usecase:  usecase,
+		wishlist: wishlist,
 		validate: validate,
 		tracer:   otel.Tracer("cart_GRPC_handler"),
-		internalAuthToken: internalAuthToken,
 	}
 }
 
@@ -39,8 +41,24 @@ func (h *CartGRPCHandler) GetCart(ctx context.Context, req *cartpb.GetCartReques
 	ctx, span := h.tracer.Start(ctx, "CartHandler.GetCart")
 	defer span.End()
 
-	userID := uint(req.GetUserId())
-	response, err := h.usecase.GetCart(ctx, userID)
+	ownerReq := dto.CartOwnerRequest{
+		UserID:     uint(req.GetUserId()),
+		GuestToken: req.GetGuestToken(),
+	}
+
+	if err := authorizeCart(ctx, ownerReq.UserID, ownerReq.GuestToken); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	if err := h.validate.Struct(&ownerReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, err
+	}
+
+	response, err := h.usecase.GetCart(ctx, domain.CartOwner{UserID: ownerReq.UserID, GuestToken: ownerReq.GuestToken})
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -54,10 +72,17 @@ func (h *CartGRPCHandler) AddItem(ctx context.Context, req *cartpb.AddItemReques
 	ctx, span := h.tracer.Start(ctx, "CartHandler.AddItem")
 	defer span.End()
 
+	if err := authorizeCart(ctx, uint(req.GetUserId()), req.GetGuestToken()); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
 	addReq := dto.AddItemRequest{
-		UserID:    uint(req.GetUserId()),
-		ProductID: uint(req.GetProductId()),
-		Quantity:  int(req.GetQuantity()),
+		UserID:     uint(req.GetUserId()),
+		GuestToken: req.GetGuestToken(),
+		ProductID:  uint(req.GetProductId()),
+		Quantity:   int(req.GetQuantity()),
 	}
 
 	if err := h.validate.Struct(&addReq); err != nil {
@@ -80,10 +105,17 @@ func (h *CartGRPCHandler) UpdateItem(ctx context.Context, req *cartpb.UpdateItem
 	ctx, span := h.tracer.Start(ctx, "CartHandler.UpdateItem")
 	defer span.End()
 
+	if err := authorizeCart(ctx, uint(req.GetUserId()), req.GetGuestToken()); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
 	updateReq := dto.UpdateItemRequest{
-		UserID:    uint(req.GetUserId()),
-		ProductID: uint(req.GetProductId()),
-		Quantity:  int(req.GetQuantity()),
+		UserID:     uint(req.GetUserId()),
+		GuestToken: req.GetGuestToken(),
+		ProductID:  uint(req.GetProductId()),
+		Quantity:   int(req.GetQuantity()),
 	}
 
 	if err := h.validate.Struct(&updateReq); err != nil {
@@ -106,9 +138,16 @@ func (h *CartGRPCHandler) RemoveItem(ctx context.Context, req *cartpb.RemoveItem
 	ctx, span := h.tracer.Start(ctx, "CartHandler.RemoveItem")
 	defer span.End()
 
+	if err := authorizeCart(ctx, uint(req.GetUserId()), req.GetGuestToken()); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
 	removeReq := dto.RemoveItemRequest{
-		UserID:    uint(req.GetUserId()),
-		ProductID: uint(req.GetProductId()),
+		UserID:     uint(req.GetUserId()),
+		GuestToken: req.GetGuestToken(),
+		ProductID:  uint(req.GetProductId()),
 	}
 
 	if err := h.validate.Struct(&removeReq); err != nil {
@@ -131,7 +170,24 @@ func (h *CartGRPCHandler) ClearCart(ctx context.Context, req *cartpb.ClearCartRe
 	ctx, span := h.tracer.Start(ctx, "CartHandler.ClearCart")
 	defer span.End()
 
-	if err := h.usecase.ClearCart(ctx, uint(req.GetUserId())); err != nil {
+	ownerReq := dto.CartOwnerRequest{
+		UserID:     uint(req.GetUserId()),
+		GuestToken: req.GetGuestToken(),
+	}
+
+	if err := authorizeCart(ctx, ownerReq.UserID, ownerReq.GuestToken); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	if err := h.validate.Struct(&ownerReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, err
+	}
+
+	if err := h.usecase.ClearCart(ctx, domain.CartOwner{UserID: ownerReq.UserID, GuestToken: ownerReq.GuestToken}); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
@@ -140,15 +196,47 @@ func (h *CartGRPCHandler) ClearCart(ctx context.Context, req *cartpb.ClearCartRe
 	return &cartpb.ClearCartResponse{Success: true}, nil
 }
 
-func (h *CartGRPCHandler) Run(done <-chan any, port string) error {
+func (h *CartGRPCHandler) MergeCarts(ctx context.Context, req *cartpb.MergeCartsRequest) (*cartpb.CartResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "CartHandler.MergeCarts")
+	defer span.End()
+
+	if err := grpcmiddleware.AuthorizeUser(ctx, uint(req.GetUserId())); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	mergeReq := dto.MergeCartsRequest{
+		UserID:     uint(req.GetUserId()),
+		GuestToken: req.GetGuestToken(),
+	}
+
+	if err := h.validate.Struct(&mergeReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, err
+	}
+
+	response, err := h.usecase.MergeCarts(ctx, &mergeReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return mapCartResponse(response), nil
+}
+
+func (h *CartGRPCHandler) Run(done <-chan any, port string, opts ...grpc.ServerOption) error {
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		logger.Errorf("Error while starting cart grpc server: %v", err)
 		return err
 	}
 
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(grpcmiddleware.InternalAuthUnaryServerInterceptor(h.internalAuthToken)))
+	grpcServer := grpc.NewServer(opts...)
 	cartpb.RegisterCartServiceServer(grpcServer, h)
+	wishlistpb.RegisterWishlistServiceServer(grpcServer, h.wishlist)
 
 	go func() {
 		logger.Infof("Cart gRPC server is running on port %s", port)
@@ -166,6 +254,15 @@ func (h *CartGRPCHandler) Run(done <-chan any, port string) error {
 	return nil
 }
 
+// authorizeCart checks the caller may use the user's cart. A guest's cart has no user to check; holding its
+// token is what grants access.
+func authorizeCart(ctx context.Context, userID uint, guestToken string) error {
+	if guestToken != "" {
+		return nil
+	}
+	return grpcmiddleware.AuthorizeUser(ctx, userID)
+}
+
 func mapCartResponse(response *dto.CartResponse) *cartpb.CartResponse {
 	if response == nil {
 		return &cartpb.CartResponse{}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
+	"github.com/kareemhamed001/e-commerce/services/CartService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/CartService/internal/domain"
+	wishlistpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/wishlist"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WishlistGRPCHandler serves WishlistService on the cart service's gRPC server
+type WishlistGRPCHandler struct {
+	wishlistpb.UnimplementedWishlistServiceServer
+	usecase  domain.WishlistUsecase
+	validate *validator.Validate
+	tracer   trace.Tracer
+}
+
+var _ wishlistpb.WishlistServiceServer = (*WishlistGRPCHandler)(nil)
+
+func NewWishlistGRPCHandler(usecase domain.WishlistUsecase, validate *validator.Validate) *WishlistGRPCHandler {
+	return &WishlistGRPCHandler{
+		usecase:  usecase,
+		validate: validate,
+		tracer:   otel.Tracer("wishlist_GRPC_handler"),
+	}
+}
+
+func (h *WishlistGRPCHandler) GetWishlist(ctx context.Context, req *wishlistpb.GetWishlistRequest) (*wishlistpb.WishlistResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "WishlistHandler.GetWishlist")
+	defer span.End()
+
+	if err := grpcmiddleware.AuthorizeUser(ctx, uint(req.GetUserId())); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	response, err := h.usecase.GetWishlist(ctx, uint(req.GetUserId()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return mapWishlistResponse(response), nil
+}
+
+func (h *WishlistGRPCHandler) AddItem(ctx context.Context, req *wishlistpb.AddWishlistItemRequest) (*wishlistpb.WishlistResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "WishlistHandler.AddItem")
+	defer span.End()
+
+	if err := grpcmiddleware.AuthorizeUser(ctx, uint(req.GetUserId())); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	addReq := dto.WishlistItemRequest{
+		UserID:    uint(req.GetUserId()),
+		ProductID: uint(req.GetProductId()),
+	}
+
+	if err := h.validate.Struct(&addReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, err
+	}
+
+	response, err := h.usecase.AddItem(ctx, &addReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return mapWishlistResponse(response), nil
+}
+
+func (h *WishlistGRPCHandler) RemoveItem(ctx context.Context, req *wishlistpb.RemoveWishlistItemRequest) (*wishlistpb.WishlistResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "WishlistHandler.RemoveItem")
+	defer span.End()
+
+	if err := grpcmiddleware.AuthorizeUser(ctx, uint(req.GetUserId())); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	removeReq := dto.WishlistItemRequest{
+		UserID:    uint(req.GetUserId()),
+		ProductID: uint(req.GetProductId()),
+	}
+
+	if err := h.validate.Struct(&removeReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, err
+	}
+
+	response, err := h.usecase.RemoveItem(ctx, &removeReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return mapWishlistResponse(response), nil
+}
+
+func mapWishlistResponse(response *dto.WishlistResponse) *wishlistpb.WishlistResponse {
+	if response == nil {
+		return &wishlistpb.WishlistResponse{}
+	}
+
+	items := make([]*wishlistpb.WishlistItem, 0, len(response.Items))
+	for _, item := range response.Items {
+		items = append(items, &wishlistpb.WishlistItem{
+			ProductId: int64(item.ProductID),
+			AddedAt:   item.AddedAt.Unix(),
+		})
+	}
+
+	return &wishlistpb.WishlistResponse{
+		UserId: int64(response.UserID),
+		Items:  items,
+	}
+}
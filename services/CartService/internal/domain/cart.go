@@ -6,7 +6,20 @@ type CartItem struct {
 }
 
 type Cart struct {
-	UserID        uint
+	UserID uint
+	// GuestToken is set instead of UserID on a guest's cart
+	GuestToken    string
 	Items         []CartItem
 	TotalQuantity int
 }
+
+// CartOwner names a cart: a user's by UserID, or an anonymous visitor's by the GuestToken the gateway
+// issued them
+type CartOwner struct {
+	UserID     uint
+	GuestToken string
+}
+
+func (o CartOwner) IsGuest() bool {
+	return o.GuestToken != ""
+}
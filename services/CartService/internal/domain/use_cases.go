@@ -2,22 +2,40 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/kareemhamed001/e-commerce/services/CartService/internal/delivery/grpc/dto"
 )
 
 type CartUsecase interface {
-	GetCart(ctx context.Context, userID uint) (*dto.CartResponse, error)
+	GetCart(ctx context.Context, owner CartOwner) (*dto.CartResponse, error)
 	AddItem(ctx context.Context, req *dto.AddItemRequest) (*dto.CartResponse, error)
 	UpdateItem(ctx context.Context, req *dto.UpdateItemRequest) (*dto.CartResponse, error)
 	RemoveItem(ctx context.Context, req *dto.RemoveItemRequest) (*dto.CartResponse, error)
-	ClearCart(ctx context.Context, userID uint) error
+	ClearCart(ctx context.Context, owner CartOwner) error
+	MergeCarts(ctx context.Context, req *dto.MergeCartsRequest) (*dto.CartResponse, error)
 }
 
+// CartRepository stores carts; writes to a guest's cart restart its expiry
 type CartRepository interface {
-	GetCart(ctx context.Context, userID uint) (Cart, error)
-	AddItem(ctx context.Context, userID, productID uint, quantity int) error
-	UpdateItem(ctx context.Context, userID, productID uint, quantity int) error
+	GetCart(ctx context.Context, owner CartOwner) (Cart, error)
+	AddItem(ctx context.Context, owner CartOwner, productID uint, quantity int) error
+	UpdateItem(ctx context.Context, owner CartOwner, productID uint, quantity int) error
+	RemoveItem(ctx context.Context, owner CartOwner, productID uint) error
+	ClearCart(ctx context.Context, owner CartOwner) error
+	// MoveGuestItems sets items on the user's cart and deletes the guest's cart, both or neither
+	MoveGuestItems(ctx context.Context, guestToken string, userID uint, items []CartItem) error
+}
+
+type WishlistUsecase interface {
+	GetWishlist(ctx context.Context, userID uint) (*dto.WishlistResponse, error)
+	AddItem(ctx context.Context, req *dto.WishlistItemRequest) (*dto.WishlistResponse, error)
+	RemoveItem(ctx context.Context, req *dto.WishlistItemRequest) (*dto.WishlistResponse, error)
+}
+
+type WishlistRepository interface {
+	GetWishlist(ctx context.Context, userID uint) (Wishlist, error)
+	// AddItem leaves an existing entry, and its added time, untouched
+	AddItem(ctx context.Context, userID, productID uint, addedAt time.Time) error
 	RemoveItem(ctx context.Context, userID, productID uint) error
-	ClearCart(ctx context.Context, userID uint) error
 }
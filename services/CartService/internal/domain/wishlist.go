@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+type WishlistItem struct {
+	ProductID uint
+	AddedAt   time.Time
+}
+
+type Wishlist struct {
+	UserID uint
+	Items  []WishlistItem
+}
@@ -4,29 +4,37 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	redisClient "github.com/kareemhamed001/e-commerce/pkg/redis"
 	"github.com/kareemhamed001/e-commerce/services/CartService/internal/domain"
+	goredis "github.com/redis/go-redis/v9"
 )
 
-const cartKeyPrefix = "cart:"
+const (
+	cartKeyPrefix      = "cart:"
+	guestCartKeyPrefix = "cart:guest:"
+)
 
+// CartRepository stores each cart as a hash of product ID to quantity. Guest carts expire guestTTL after
+// their last change; user carts are kept.
 type CartRepository struct {
-	client *redisClient.Client
+	client   *redisClient.Client
+	guestTTL time.Duration
 }
 
 var _ domain.CartRepository = (*CartRepository)(nil)
 
-func NewCartRepository(client *redisClient.Client) *CartRepository {
-	return &CartRepository{client: client}
+func NewCartRepository(client *redisClient.Client, guestTTL time.Duration) *CartRepository {
+	return &CartRepository{client: client, guestTTL: guestTTL}
 }
 
-func (r *CartRepository) GetCart(ctx context.Context, userID uint) (domain.Cart, error) {
+func (r *CartRepository) GetCart(ctx context.Context, owner domain.CartOwner) (domain.Cart, error) {
 	if !r.client.IsEnabled() {
 		return domain.Cart{}, fmt.Errorf("redis disabled")
 	}
 
-	key := cartKey(userID)
+	key := cartKey(owner)
 	values, err := r.client.HGetAll(ctx, key).Result()
 	if err != nil {
 		return domain.Cart{}, err
@@ -51,48 +59,77 @@ func (r *CartRepository) GetCart(ctx context.Context, userID uint) (domain.Cart,
 	}
 
 	return domain.Cart{
-		UserID:        userID,
+		UserID:        owner.UserID,
+		GuestToken:    owner.GuestToken,
 		Items:         items,
 		TotalQuantity: totalQty,
 	}, nil
 }
 
-func (r *CartRepository) AddItem(ctx context.Context, userID, productID uint, quantity int) error {
-	if !r.client.IsEnabled() {
-		return fmt.Errorf("redis disabled")
-	}
+func (r *CartRepository) AddItem(ctx context.Context, owner domain.CartOwner, productID uint, quantity int) error {
+	return r.write(ctx, owner, func(pipe goredis.Pipeliner, key string) {
+		pipe.HIncrBy(ctx, key, fmt.Sprintf("%d", productID), int64(quantity))
+	})
+}
+
+func (r *CartRepository) UpdateItem(ctx context.Context, owner domain.CartOwner, productID uint, quantity int) error {
+	return r.write(ctx, owner, func(pipe goredis.Pipeliner, key string) {
+		pipe.HSet(ctx, key, fmt.Sprintf("%d", productID), quantity)
+	})
+}
 
-	key := cartKey(userID)
-	return r.client.HIncrBy(ctx, key, fmt.Sprintf("%d", productID), int64(quantity)).Err()
+func (r *CartRepository) RemoveItem(ctx context.Context, owner domain.CartOwner, productID uint) error {
+	return r.write(ctx, owner, func(pipe goredis.Pipeliner, key string) {
+		pipe.HDel(ctx, key, fmt.Sprintf("%d", productID))
+	})
 }
 
-func (r *CartRepository) UpdateItem(ctx context.Context, userID, productID uint, quantity int) error {
+func (r *CartRepository) ClearCart(ctx context.Context, owner domain.CartOwner) error {
 	if !r.client.IsEnabled() {
 		return fmt.Errorf("redis disabled")
 	}
 
-	key := cartKey(userID)
-	return r.client.HSet(ctx, key, fmt.Sprintf("%d", productID), quantity).Err()
+	key := cartKey(owner)
+	return r.client.Del(ctx, key).Err()
 }
 
-func (r *CartRepository) RemoveItem(ctx context.Context, userID, productID uint) error {
+func (r *CartRepository) MoveGuestItems(ctx context.Context, guestToken string, userID uint, items []domain.CartItem) error {
 	if !r.client.IsEnabled() {
 		return fmt.Errorf("redis disabled")
 	}
 
-	key := cartKey(userID)
-	return r.client.HDel(ctx, key, fmt.Sprintf("%d", productID)).Err()
+	userKey := cartKey(domain.CartOwner{UserID: userID})
+	_, err := r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, item := range items {
+			pipe.HSet(ctx, userKey, fmt.Sprintf("%d", item.ProductID), item.Quantity)
+		}
+		pipe.Del(ctx, cartKey(domain.CartOwner{GuestToken: guestToken}))
+		return nil
+	})
+	return err
 }
 
-func (r *CartRepository) ClearCart(ctx context.Context, userID uint) error {
+// write runs the commands fn queues on owner's cart in one transaction, restarting a guest cart's expiry
+// along with them
+func (r *CartRepository) write(ctx context.Context, owner domain.CartOwner, fn func(pipe goredis.Pipeliner, key string)) error {
 	if !r.client.IsEnabled() {
 		return fmt.Errorf("redis disabled")
 	}
 
-	key := cartKey(userID)
-	return r.client.Del(ctx, key).Err()
+	key := cartKey(owner)
+	_, err := r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		fn(pipe, key)
+		if owner.IsGuest() {
+			pipe.Expire(ctx, key, r.guestTTL)
+		}
+		return nil
+	})
+	return err
 }
 
-func cartKey(userID uint) string {
-	return fmt.Sprintf("%s%d", cartKeyPrefix, userID)
+func cartKey(owner domain.CartOwner) string {
+	if owner.IsGuest() {
+		return guestCartKeyPrefix + owner.GuestToken
+	}
+	return fmt.Sprintf("%s%d", cartKeyPrefix, owner.UserID)
 }
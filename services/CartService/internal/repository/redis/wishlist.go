@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	redisClient "github.com/kareemhamed001/e-commerce/pkg/redis"
+	"github.com/kareemhamed001/e-commerce/services/CartService/internal/domain"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const wishlistKeyPrefix = "wishlist:"
+
+// WishlistRepository stores each wishlist as a sorted set of product IDs scored by the time they were added
+type WishlistRepository struct {
+	client *redisClient.Client
+}
+
+var _ domain.WishlistRepository = (*WishlistRepository)(nil)
+
+func NewWishlistRepository(client *redisClient.Client) *WishlistRepository {
+	return &WishlistRepository{client: client}
+}
+
+func (r *WishlistRepository) GetWishlist(ctx context.Context, userID uint) (domain.Wishlist, error) {
+	if !r.client.IsEnabled() {
+		return domain.Wishlist{}, fmt.Errorf("redis disabled")
+	}
+
+	entries, err := r.client.ZRevRangeWithScores(ctx, wishlistKey(userID), 0, -1).Result()
+	if err != nil {
+		return domain.Wishlist{}, err
+	}
+
+	items := make([]domain.WishlistItem, 0, len(entries))
+	for _, entry := range entries {
+		member, ok := entry.Member.(string)
+		if !ok {
+			continue
+		}
+		productID, err := strconv.ParseUint(member, 10, 32)
+		if err != nil {
+			continue
+		}
+		items = append(items, domain.WishlistItem{
+			ProductID: uint(productID),
+			AddedAt:   time.Unix(int64(entry.Score), 0).UTC(),
+		})
+	}
+
+	return domain.Wishlist{
+		UserID: userID,
+		Items:  items,
+	}, nil
+}
+
+func (r *WishlistRepository) AddItem(ctx context.Context, userID, productID uint, addedAt time.Time) error {
+	if !r.client.IsEnabled() {
+		return fmt.Errorf("redis disabled")
+	}
+
+	// NX keeps a single entry per product and preserves when it was first added
+	return r.client.ZAddNX(ctx, wishlistKey(userID), goredis.Z{
+		Score:  float64(addedAt.Unix()),
+		Member: fmt.Sprintf("%d", productID),
+	}).Err()
+}
+
+func (r *WishlistRepository) RemoveItem(ctx context.Context, userID, productID uint) error {
+	if !r.client.IsEnabled() {
+		return fmt.Errorf("redis disabled")
+	}
+
+	return r.client.ZRem(ctx, wishlistKey(userID), fmt.Sprintf("%d", productID)).Err()
+}
+
+func wishlistKey(userID uint) string {
+	return fmt.Sprintf("%s%d", wishlistKeyPrefix, userID)
+}
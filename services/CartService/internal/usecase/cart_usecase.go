@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/kareemhamed001/e-commerce/services/CartService/internal/delivery/grpc/dto"
@@ -13,6 +12,8 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type CartUsecase struct {
@@ -39,17 +40,17 @@ func NewCartUsecase(repo domain.CartRepository, productClient productpb.ProductS
 	}
 }
 
-func (u *CartUsecase) GetCart(ctx context.Context, userID uint) (*dto.CartResponse, error) {
+func (u *CartUsecase) GetCart(ctx context.Context, owner domain.CartOwner) (*dto.CartResponse, error) {
 	ctx, span := u.tracer.Start(ctx, "CartUsecase.GetCart")
 	defer span.End()
 
-	if err := u.ensureUserExists(ctx, userID); err != nil {
+	if err := u.ensureOwnerExists(ctx, owner); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	cart, err := u.repo.GetCart(ctx, userID)
+	cart, err := u.repo.GetCart(ctx, owner)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -65,10 +66,12 @@ func (u *CartUsecase) AddItem(ctx context.Context, req *dto.AddItemRequest) (*dt
 
 	span.SetAttributes(
 		attribute.Int("cart.user_id", int(req.UserID)),
+		attribute.Bool("cart.guest", req.GuestToken != ""),
 		attribute.Int("cart.product_id", int(req.ProductID)),
 	)
 
-	if err := u.ensureUserExists(ctx, req.UserID); err != nil {
+	owner := domain.CartOwner{UserID: req.UserID, GuestToken: req.GuestToken}
+	if err := u.ensureOwnerExists(ctx, owner); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
@@ -80,13 +83,13 @@ func (u *CartUsecase) AddItem(ctx context.Context, req *dto.AddItemRequest) (*dt
 		return nil, err
 	}
 
-	if err := u.repo.AddItem(ctx, req.UserID, req.ProductID, req.Quantity); err != nil {
+	if err := u.repo.AddItem(ctx, owner, req.ProductID, req.Quantity); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	cart, err := u.repo.GetCart(ctx, req.UserID)
+	cart, err := u.repo.GetCart(ctx, owner)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -100,7 +103,8 @@ func (u *CartUsecase) UpdateItem(ctx context.Context, req *dto.UpdateItemRequest
 	ctx, span := u.tracer.Start(ctx, "CartUsecase.UpdateItem")
 	defer span.End()
 
-	if err := u.ensureUserExists(ctx, req.UserID); err != nil {
+	owner := domain.CartOwner{UserID: req.UserID, GuestToken: req.GuestToken}
+	if err := u.ensureOwnerExists(ctx, owner); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
@@ -112,13 +116,13 @@ func (u *CartUsecase) UpdateItem(ctx context.Context, req *dto.UpdateItemRequest
 		return nil, err
 	}
 
-	if err := u.repo.UpdateItem(ctx, req.UserID, req.ProductID, req.Quantity); err != nil {
+	if err := u.repo.UpdateItem(ctx, owner, req.ProductID, req.Quantity); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	cart, err := u.repo.GetCart(ctx, req.UserID)
+	cart, err := u.repo.GetCart(ctx, owner)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -132,19 +136,20 @@ func (u *CartUsecase) RemoveItem(ctx context.Context, req *dto.RemoveItemRequest
 	ctx, span := u.tracer.Start(ctx, "CartUsecase.RemoveItem")
 	defer span.End()
 
-	if err := u.ensureUserExists(ctx, req.UserID); err != nil {
+	owner := domain.CartOwner{UserID: req.UserID, GuestToken: req.GuestToken}
+	if err := u.ensureOwnerExists(ctx, owner); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	if err := u.repo.RemoveItem(ctx, req.UserID, req.ProductID); err != nil {
+	if err := u.repo.RemoveItem(ctx, owner, req.ProductID); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	cart, err := u.repo.GetCart(ctx, req.UserID)
+	cart, err := u.repo.GetCart(ctx, owner)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -154,17 +159,17 @@ func (u *CartUsecase) RemoveItem(ctx context.Context, req *dto.RemoveItemRequest
 	return mapCartToResponse(cart), nil
 }
 
-func (u *CartUsecase) ClearCart(ctx context.Context, userID uint) error {
+func (u *CartUsecase) ClearCart(ctx context.Context, owner domain.CartOwner) error {
 	ctx, span := u.tracer.Start(ctx, "CartUsecase.ClearCart")
 	defer span.End()
 
-	if err := u.ensureUserExists(ctx, userID); err != nil {
+	if err := u.ensureOwnerExists(ctx, owner); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
 
-	if err := u.repo.ClearCart(ctx, userID); err != nil {
+	if err := u.repo.ClearCart(ctx, owner); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return err
@@ -173,29 +178,91 @@ func (u *CartUsecase) ClearCart(ctx context.Context, userID uint) error {
 	return nil
 }
 
-func (u *CartUsecase) ensureUserExists(ctx context.Context, userID uint) error {
-	ctx, cancel := context.WithTimeout(ctx, u.downstreamTimeout)
-	defer cancel()
+// MergeCarts adds the guest's items to the user's cart, up to each product's stock. A quantity the user
+// already had above the stock is left as it was, and products deleted since the guest added them are dropped
+// with the guest's cart.
+func (u *CartUsecase) MergeCarts(ctx context.Context, req *dto.MergeCartsRequest) (*dto.CartResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "CartUsecase.MergeCarts")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("cart.user_id", int(req.UserID)))
+
+	if err := u.ensureUserExists(ctx, req.UserID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
 
-	_, err := u.userClient.GetUserByID(ctx, &userpb.GetUserByIDRequest{Id: int32(userID)})
+	user := domain.CartOwner{UserID: req.UserID}
+	guestCart, err := u.repo.GetCart(ctx, domain.CartOwner{GuestToken: req.GuestToken})
 	if err != nil {
-		return fmt.Errorf("user not found: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
-	return nil
-}
 
-func (u *CartUsecase) ensureProductExists(ctx context.Context, productID uint) (*productpb.Product, error) {
-	ctx, cancel := context.WithTimeout(ctx, u.downstreamTimeout)
-	defer cancel()
+	userCart, err := u.repo.GetCart(ctx, user)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
 
-	response, err := u.productClient.GetProductByID(ctx, &productpb.GetProductByIDRequest{Id: int64(productID)})
+	had := make(map[uint]int, len(userCart.Items))
+	for _, item := range userCart.Items {
+		had[item.ProductID] = item.Quantity
+	}
+
+	merged := make([]domain.CartItem, 0, len(guestCart.Items))
+	for _, item := range guestCart.Items {
+		product, err := u.ensureProductExists(ctx, item.ProductID)
+		if status.Code(err) == grpccodes.NotFound {
+			continue
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		quantity := min(had[item.ProductID]+item.Quantity, int(product.GetQuantity()))
+		if quantity <= had[item.ProductID] {
+			continue
+		}
+		merged = append(merged, domain.CartItem{ProductID: item.ProductID, Quantity: quantity})
+	}
+	span.SetAttributes(attribute.Int("cart.merged_items", len(merged)))
+
+	if err := u.repo.MoveGuestItems(ctx, req.GuestToken, req.UserID, merged); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	cart, err := u.repo.GetCart(ctx, user)
 	if err != nil {
-		return nil, fmt.Errorf("product not found: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
-	if response.GetProduct() == nil {
-		return nil, fmt.Errorf("product not found: empty response")
+
+	return mapCartToResponse(cart), nil
+}
+
+// ensureOwnerExists checks the user of a user's cart; guests have no account to check
+func (u *CartUsecase) ensureOwnerExists(ctx context.Context, owner domain.CartOwner) error {
+	if owner.IsGuest() {
+		return nil
 	}
-	return response.GetProduct(), nil
+	return u.ensureUserExists(ctx, owner.UserID)
+}
+
+func (u *CartUsecase) ensureUserExists(ctx context.Context, userID uint) error {
+	return ensureUserExists(ctx, u.userClient, u.downstreamTimeout, userID)
+}
+
+func (u *CartUsecase) ensureProductExists(ctx context.Context, productID uint) (*productpb.Product, error) {
+	return ensureProductExists(ctx, u.productClient, u.downstreamTimeout, productID)
 }
 
 func mapCartToResponse(cart domain.Cart) *dto.CartResponse {
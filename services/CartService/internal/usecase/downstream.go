@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+)
+
+// Cart and wishlist both only accept existing users and products
+
+func ensureUserExists(ctx context.Context, userClient userpb.UserServiceClient, timeout time.Duration, userID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	_, err := userClient.GetUserByID(ctx, &userpb.GetUserByIDRequest{Id: int32(userID)})
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+	return nil
+}
+
+func ensureProductExists(ctx context.Context, productClient productpb.ProductServiceClient, timeout time.Duration, productID uint) (*productpb.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	response, err := productClient.GetProductByID(ctx, &productpb.GetProductByIDRequest{Id: int64(productID)})
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+	if response.GetProduct() == nil {
+		return nil, fmt.Errorf("product not found: empty response")
+	}
+	return response.GetProduct(), nil
+}
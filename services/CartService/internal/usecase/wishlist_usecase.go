@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/CartService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/CartService/internal/domain"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type WishlistUsecase struct {
+	repo              domain.WishlistRepository
+	productClient     productpb.ProductServiceClient
+	userClient        userpb.UserServiceClient
+	downstreamTimeout time.Duration
+	tracer            trace.Tracer
+}
+
+var _ domain.WishlistUsecase = (*WishlistUsecase)(nil)
+
+func NewWishlistUsecase(repo domain.WishlistRepository, productClient productpb.ProductServiceClient, userClient userpb.UserServiceClient, downstreamTimeout time.Duration) *WishlistUsecase {
+	if downstreamTimeout <= 0 {
+		downstreamTimeout = 3 * time.Second
+	}
+
+	return &WishlistUsecase{
+		repo:              repo,
+		productClient:     productClient,
+		userClient:        userClient,
+		downstreamTimeout: downstreamTimeout,
+		tracer:            otel.Tracer("wishlist-usecase"),
+	}
+}
+
+func (u *WishlistUsecase) GetWishlist(ctx context.Context, userID uint) (*dto.WishlistResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "WishlistUsecase.GetWishlist")
+	defer span.End()
+
+	if err := ensureUserExists(ctx, u.userClient, u.downstreamTimeout, userID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return u.currentWishlist(ctx, span, userID)
+}
+
+func (u *WishlistUsecase) AddItem(ctx context.Context, req *dto.WishlistItemRequest) (*dto.WishlistResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "WishlistUsecase.AddItem")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("wishlist.user_id", int(req.UserID)),
+		attribute.Int("wishlist.product_id", int(req.ProductID)),
+	)
+
+	if err := ensureUserExists(ctx, u.userClient, u.downstreamTimeout, req.UserID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if _, err := ensureProductExists(ctx, u.productClient, u.downstreamTimeout, req.ProductID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := u.repo.AddItem(ctx, req.UserID, req.ProductID, time.Now()); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return u.currentWishlist(ctx, span, req.UserID)
+}
+
+func (u *WishlistUsecase) RemoveItem(ctx context.Context, req *dto.WishlistItemRequest) (*dto.WishlistResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "WishlistUsecase.RemoveItem")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("wishlist.user_id", int(req.UserID)),
+		attribute.Int("wishlist.product_id", int(req.ProductID)),
+	)
+
+	if err := ensureUserExists(ctx, u.userClient, u.downstreamTimeout, req.UserID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// A product that was deleted from the catalog can still be removed, so it is not looked up
+	if err := u.repo.RemoveItem(ctx, req.UserID, req.ProductID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return u.currentWishlist(ctx, span, req.UserID)
+}
+
+func (u *WishlistUsecase) currentWishlist(ctx context.Context, span trace.Span, userID uint) (*dto.WishlistResponse, error) {
+	wishlist, err := u.repo.GetWishlist(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	items := make([]dto.WishlistItemResponse, 0, len(wishlist.Items))
+	for _, item := range wishlist.Items {
+		items = append(items, dto.WishlistItemResponse{
+			ProductID: item.ProductID,
+			AddedAt:   item.AddedAt,
+		})
+	}
+
+	return &dto.WishlistResponse{
+		UserID: wishlist.UserID,
+		Items:  items,
+	}, nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kareemhamed001/e-commerce/pkg/db"
+	"github.com/kareemhamed001/e-commerce/pkg/events"
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/pkg/spiffeauth"
+	"github.com/kareemhamed001/e-commerce/pkg/tracer"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/config"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/consumer"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/delivery/grpc/handler"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/mailer"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/repository/postgresql"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/templates"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/usecase"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	done := make(chan interface{})
+	config, err := config.Load()
+	if err != nil {
+		close(done)
+		panic(err)
+	}
+
+	logger.InitGlobal(config.AppEnv, "logs/notification/system.log")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	shutdownTracer := initTracing(ctx)
+	defer shutdownTracer()
+
+	spiffeSource, err := spiffeauth.NewSource(ctx, config.SPIFFEEndpointSocket)
+	if err != nil {
+		close(done)
+		panic(err)
+	}
+	defer spiffeSource.Close()
+
+	dbConfig := &db.Config{
+		DBDriver:              config.DBDriver,
+		DSN:                   config.DBDSN,
+		MigrationAutoRun:      config.DBMigrationAutoRun,
+		MigrationDir:          "services/NotificationService/internal/migrations",
+		ConnectionMaxIdle:     config.DBConnectionMaxIdle,
+		ConnectionMaxOpen:     config.DBConnectionMaxOpen,
+		ConnectionMaxLifeTime: config.DBConnectionMaxLife,
+	}
+
+	notificationDB, err := db.InitDB(dbConfig)
+	if err != nil {
+		close(done)
+		panic("failed to connect database")
+	}
+
+	notificationDB.AutoMigrate(&domain.SentNotification{}, &domain.Preferences{})
+
+	renderer, err := templates.NewRenderer(config.TemplatesDir)
+	if err != nil {
+		close(done)
+		panic("failed to load templates: " + err.Error())
+	}
+
+	deadLetter, err := mailer.NewDeadLetterLog(config.DeadLetterPath)
+	if err != nil {
+		close(done)
+		panic(err)
+	}
+
+	var transport domain.Mailer
+	if config.MailDriver == "smtp" {
+		transport = mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     config.SMTPHost,
+			Port:     config.SMTPPort,
+			Username: config.SMTPUsername,
+			Password: config.SMTPPassword,
+			From:     config.MailFrom,
+			FromName: config.MailFromName,
+		})
+	} else {
+		transport = mailer.NewLogMailer()
+	}
+	logger.Infof("event=mailer_configured driver=%s", config.MailDriver)
+
+	eventBus, err := events.NewBus(events.Config{
+		Driver:      config.EventsDriver,
+		RabbitMQURL: config.RabbitMQURL,
+		Exchange:    config.EventsExchange,
+	})
+	if err != nil {
+		close(done)
+		panic("failed to initialize event bus: " + err.Error())
+	}
+	defer func() {
+		_ = eventBus.Close()
+	}()
+
+	userConn, err := grpc.NewClient(
+		config.UserServiceGRPCAddr,
+		grpc.WithTransportCredentials(spiffeSource.ClientCredentials()),
+		grpc.WithChainUnaryInterceptor(
+			grpcmiddleware.InternalAuthUnaryClientInterceptor(config.InternalAuthToken, config.ServiceName),
+			grpcmiddleware.RequestIDUnaryClientInterceptor(),
+			grpcmiddleware.CircuitBreakerUnaryClientInterceptor(
+				"notification-service->"+config.UserServiceGRPCAddr,
+				grpcmiddleware.CircuitBreakerConfig{
+					Enabled:      config.CircuitBreakerEnabled,
+					MaxRequests:  config.CircuitBreakerMaxRequests,
+					Interval:     config.CircuitBreakerInterval,
+					Timeout:      config.CircuitBreakerTimeout,
+					FailureRatio: config.CircuitBreakerFailureRatio,
+					MinRequests:  config.CircuitBreakerMinRequests,
+				},
+			),
+		),
+	)
+	if err != nil {
+		close(done)
+		panic("failed to connect to user service")
+	}
+	defer func() {
+		_ = userConn.Close()
+	}()
+
+	userClient := userpb.NewUserServiceClient(userConn)
+	notificationRepo := postgresql.NewNotificationRepository(notificationDB)
+	notificationUsecase := usecase.NewNotificationUsecase(renderer, mailer.NewRetryMailer(transport, config.MailMaxRetries, config.MailRetryDelay, deadLetter), notificationRepo)
+
+	orderConsumer := consumer.NewOrderConsumer(notificationUsecase, userClient)
+	consumerCtx, stopConsumer := context.WithCancel(ctx)
+	defer stopConsumer()
+	go func() {
+		if err := eventBus.Subscribe(consumerCtx, config.EventsQueue, consumer.OrderEventTypes, orderConsumer.Handle); err != nil {
+			logger.Errorf("event=order_consumer_stopped error=%v", err)
+		}
+	}()
+
+	validate := validator.New()
+	grpcHandler := handler.NewNotificationGRPCHandler(notificationUsecase, validate)
+
+	serverOpts := append(grpcmiddleware.DefaultServerOptions(logger.NewStructured(config.AppEnv), config.InternalAuthToken, config.InternalAuthPolicy), spiffeSource.ServerOptions()...)
+	if err := grpcHandler.Run(done, config.GRPCPort, serverOpts...); err != nil {
+		logger.Errorf("failed to start gRPC server: %v", err)
+		close(done)
+		panic(err)
+	}
+	grpcmiddleware.ServeMetrics(done, config.MetricsPort)
+
+	// graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	<-sigChan
+	stopConsumer()
+	close(done)
+	time.Sleep(200 * time.Millisecond)
+}
+
+func initTracing(ctx context.Context) func() {
+	jaegerEndpoint := config.GetEnv("JAEGER_ENDPOINT", "ecommece_jaeger:4317")
+	tp, err := tracer.InitTracer(ctx, "notification-service-grpc", jaegerEndpoint)
+	if err != nil {
+		logger.Warnf("Failed to initialize tracer: %v. Continuing without tracing.", err)
+		return func() {}
+	}
+
+	logger.Info("OpenTelemetry tracer initialized successfully")
+	return func() {
+		if err := tracer.Shutdown(ctx, tp); err != nil {
+			logger.Errorf("Failed to shutdown tracer: %v", err)
+		}
+	}
+}
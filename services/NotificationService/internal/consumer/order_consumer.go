@@ -0,0 +1,121 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/events"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/domain"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const userLookupTimeout = 3 * time.Second
+
+// OrderEventTypes are the order events that produce customer emails.
+// order.cancelled is skipped because every cancellation also emits order.status_changed.
+var OrderEventTypes = []string{
+	events.OrderCreated,
+	events.OrderStatusChanged,
+}
+
+// OrderConsumer turns order events into customer emails
+type OrderConsumer struct {
+	notificationUsecase domain.NotificationUsecase
+	userClient          userpb.UserServiceClient
+}
+
+func NewOrderConsumer(notificationUsecase domain.NotificationUsecase, userClient userpb.UserServiceClient) *OrderConsumer {
+	return &OrderConsumer{
+		notificationUsecase: notificationUsecase,
+		userClient:          userClient,
+	}
+}
+
+// Handle implements events.Handler. Returning an error requeues the event, so only
+// failures that may succeed on redelivery (user lookup outages) are returned.
+func (c *OrderConsumer) Handle(ctx context.Context, event events.Event) error {
+	if event.Version != events.OrderEventsVersion {
+		logger.Warnf("event=order_event_skipped id=%s type=%s reason=unsupported_version version=%d", event.ID, event.Type, event.Version)
+		return nil
+	}
+
+	var (
+		userID       uint
+		notification *domain.Notification
+	)
+
+	switch event.Type {
+	case events.OrderCreated:
+		var payload events.OrderCreatedV1
+		if err := event.Decode(&payload); err != nil {
+			logger.Errorf("event=order_event_malformed id=%s type=%s error=%v", event.ID, event.Type, err)
+			return nil
+		}
+		userID = payload.UserID
+		notification = &domain.Notification{
+			Template: domain.TemplateOrderConfirmation,
+			Data: map[string]any{
+				"order_id":      payload.OrderID,
+				"items":         payload.Items,
+				"shipping_cost": formatAmount(payload.ShippingCost),
+				"discount":      formatAmount(payload.Discount),
+				"total":         formatAmount(payload.Total),
+			},
+		}
+	case events.OrderStatusChanged:
+		var payload events.OrderStatusChangedV1
+		if err := event.Decode(&payload); err != nil {
+			logger.Errorf("event=order_event_malformed id=%s type=%s error=%v", event.ID, event.Type, err)
+			return nil
+		}
+		userID = payload.UserID
+		notification = &domain.Notification{
+			Template: domain.TemplateOrderStatusChanged,
+			Data: map[string]any{
+				"order_id":        payload.OrderID,
+				"previous_status": payload.OldStatus,
+				"status":          payload.NewStatus,
+			},
+		}
+	default:
+		return nil
+	}
+
+	recipient, err := c.lookupRecipient(ctx, userID)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			logger.Warnf("event=order_event_skipped id=%s type=%s reason=user_not_found user_id=%d", event.ID, event.Type, userID)
+			return nil
+		}
+		return err
+	}
+	notification.Recipient = *recipient
+	notification.UserID = userID
+
+	// Delivery failures are already retried and dead-lettered by the mailer, so they are not requeued
+	if err := c.notificationUsecase.Send(ctx, notification); err != nil && !errors.Is(err, domain.ErrDeliveryFailed) {
+		logger.Errorf("event=order_notification_failed id=%s type=%s error=%v", event.ID, event.Type, err)
+	}
+	return nil
+}
+
+func (c *OrderConsumer) lookupRecipient(ctx context.Context, userID uint) (*domain.Recipient, error) {
+	ctx, cancel := context.WithTimeout(ctx, userLookupTimeout)
+	defer cancel()
+
+	user, err := c.userClient.GetUserByID(ctx, &userpb.GetUserByIDRequest{Id: int32(userID)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Recipient{Email: user.GetEmail(), Name: user.GetName()}, nil
+}
+
+func formatAmount(amount float32) string {
+	return fmt.Sprintf("%.2f", amount)
+}
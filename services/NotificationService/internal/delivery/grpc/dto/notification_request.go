@@ -0,0 +1,27 @@
+package dto
+
+type SendNotificationRequest struct {
+	Template       string            `json:"template" validate:"required,oneof=welcome password_reset order_confirmation order_status_changed"`
+	RecipientEmail string            `json:"recipient_email" validate:"required,email"`
+	RecipientName  string            `json:"recipient_name"`
+	Data           map[string]string `json:"data"`
+	UserID         uint              `json:"user_id"`
+}
+
+type ListNotificationsRequest struct {
+	UserID  uint `json:"user_id" validate:"required,gt=0"`
+	Page    int  `json:"page" validate:"gte=1"`
+	PerPage int  `json:"per_page" validate:"gte=1,lte=100"`
+}
+
+type MarkNotificationsReadRequest struct {
+	UserID uint   `json:"user_id" validate:"required,gt=0"`
+	IDs    []uint `json:"ids" validate:"max=100,dive,gt=0"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	UserID uint `json:"user_id" validate:"required,gt=0"`
+	Email  bool `json:"email"`
+	SMS    bool `json:"sms"`
+	Push   bool `json:"push"`
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/domain"
+	notificationpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/notification"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type NotificationGRPCHandler struct {
+	notificationpb.UnimplementedNotificationServiceServer
+	notificationUsecase domain.NotificationUsecase
+	validate            *validator.Validate
+	tracer              trace.Tracer
+}
+
+var _ notificationpb.NotificationServiceServer = (*NotificationGRPCHandler)(nil)
+
+func NewNotificationGRPCHandler(notificationUsecase domain.NotificationUsecase, validate *validator.Validate) *NotificationGRPCHandler {
+	return &NotificationGRPCHandler{
+		notificationUsecase: notificationUsecase,
+		validate:            validate,
+		tracer:              otel.Tracer("notification_GRPC_handler"),
+	}
+}
+
+func (h *NotificationGRPCHandler) SendNotification(ctx context.Context, req *notificationpb.SendNotificationRequest) (*notificationpb.SendNotificationResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "NotificationHandler.SendNotification")
+	defer span.End()
+
+	sendReq := dto.SendNotificationRequest{
+		Template:       req.GetTemplate(),
+		RecipientEmail: req.GetRecipientEmail(),
+		RecipientName:  req.GetRecipientName(),
+		Data:           req.GetData(),
+		UserID:         uint(req.GetUserId()),
+	}
+
+	if err := h.validate.Struct(&sendReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	span.SetAttributes(attribute.String("notification.template", sendReq.Template))
+
+	data := make(map[string]any, len(sendReq.Data))
+	for key, value := range sendReq.Data {
+		data[key] = value
+	}
+
+	err := h.notificationUsecase.Send(reqCtx, &domain.Notification{
+		Template: domain.Template(sendReq.Template),
+		Recipient: domain.Recipient{
+			Email: sendReq.RecipientEmail,
+			Name:  sendReq.RecipientName,
+		},
+		Data:   data,
+		UserID: sendReq.UserID,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, status.Error(grpccodes.Unavailable, err.Error())
+	}
+
+	return &notificationpb.SendNotificationResponse{Delivered: true}, nil
+}
+
+func (h *NotificationGRPCHandler) ListNotifications(ctx context.Context, req *notificationpb.ListNotificationsRequest) (*notificationpb.ListNotificationsResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "NotificationHandler.ListNotifications")
+	defer span.End()
+
+	page := int(req.GetPage())
+	if page == 0 {
+		page = 1
+	}
+	perPage := int(req.GetPerPage())
+	if perPage == 0 {
+		perPage = 10
+	}
+
+	listReq := dto.ListNotificationsRequest{
+		UserID:  uint(req.GetUserId()),
+		Page:    page,
+		PerPage: perPage,
+	}
+	if err := h.validate.Struct(&listReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	notifications, total, unread, err := h.notificationUsecase.ListNotifications(reqCtx, listReq.UserID, listReq.Page, listReq.PerPage)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, status.Error(grpccodes.Internal, err.Error())
+	}
+
+	response := &notificationpb.ListNotificationsResponse{
+		Notifications: make([]*notificationpb.Notification, 0, len(notifications)),
+		TotalCount:    int32(total),
+		UnreadCount:   int32(unread),
+	}
+	for _, notification := range notifications {
+		response.Notifications = append(response.Notifications, mapSentNotificationToPB(&notification))
+	}
+	return response, nil
+}
+
+func (h *NotificationGRPCHandler) MarkNotificationsRead(ctx context.Context, req *notificationpb.MarkNotificationsReadRequest) (*notificationpb.MarkNotificationsReadResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "NotificationHandler.MarkNotificationsRead")
+	defer span.End()
+
+	markReq := dto.MarkNotificationsReadRequest{
+		UserID: uint(req.GetUserId()),
+		IDs:    make([]uint, 0, len(req.GetIds())),
+	}
+	for _, id := range req.GetIds() {
+		markReq.IDs = append(markReq.IDs, uint(id))
+	}
+	if err := h.validate.Struct(&markReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	updated, err := h.notificationUsecase.MarkRead(reqCtx, markReq.UserID, markReq.IDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, status.Error(grpccodes.Internal, err.Error())
+	}
+
+	return &notificationpb.MarkNotificationsReadResponse{UpdatedCount: int32(updated)}, nil
+}
+
+func (h *NotificationGRPCHandler) UpdateNotificationPreferences(ctx context.Context, req *notificationpb.UpdateNotificationPreferencesRequest) (*notificationpb.UpdateNotificationPreferencesResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "NotificationHandler.UpdateNotificationPreferences")
+	defer span.End()
+
+	if req.GetPreferences() == nil {
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, "preferences are required")
+	}
+	updateReq := dto.UpdateNotificationPreferencesRequest{
+		UserID: uint(req.GetUserId()),
+		Email:  req.GetPreferences().GetEmail(),
+		SMS:    req.GetPreferences().GetSms(),
+		Push:   req.GetPreferences().GetPush(),
+	}
+	if err := h.validate.Struct(&updateReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	preferences := &domain.Preferences{
+		UserID: updateReq.UserID,
+		Email:  updateReq.Email,
+		SMS:    updateReq.SMS,
+		Push:   updateReq.Push,
+	}
+	if err := h.notificationUsecase.UpdatePreferences(reqCtx, preferences); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, status.Error(grpccodes.Internal, err.Error())
+	}
+
+	return &notificationpb.UpdateNotificationPreferencesResponse{
+		Preferences: &notificationpb.NotificationPreferences{
+			Email: preferences.Email,
+			Sms:   preferences.SMS,
+			Push:  preferences.Push,
+		},
+	}, nil
+}
+
+func mapSentNotificationToPB(notification *domain.SentNotification) *notificationpb.Notification {
+	response := &notificationpb.Notification{
+		Id:        int64(notification.ID),
+		Template:  string(notification.Template),
+		Subject:   notification.Subject,
+		Read:      notification.ReadAt != nil,
+		CreatedAt: notification.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if notification.ReadAt != nil {
+		response.ReadAt = notification.ReadAt.UTC().Format(time.RFC3339)
+	}
+	return response
+}
+
+func (h *NotificationGRPCHandler) Run(done <-chan any, port string, opts ...grpc.ServerOption) error {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		logger.Errorf("Error while starting notification grpc server: %v", err)
+		return err
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+	notificationpb.RegisterNotificationServiceServer(grpcServer, h)
+
+	go func() {
+		logger.Infof("Notification gRPC server is running on port %s", port)
+		if err := grpcServer.Serve(lis); err != nil {
+			logger.Errorf("Error while serving notification grpc server: %v", err)
+		}
+	}()
+
+	go func() {
+		<-done
+		logger.Info("Shutting down notification gRPC server...")
+		grpcServer.GracefulStop()
+	}()
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type Template string
+
+const (
+	TemplateWelcome            Template = "welcome"
+	TemplatePasswordReset      Template = "password_reset"
+	TemplateOrderConfirmation  Template = "order_confirmation"
+	TemplateOrderStatusChanged Template = "order_status_changed"
+)
+
+// Templates lists every template that must be present at startup
+var Templates = []Template{
+	TemplateWelcome,
+	TemplatePasswordReset,
+	TemplateOrderConfirmation,
+	TemplateOrderStatusChanged,
+}
+
+// IgnoresPreferences reports whether the template is a security notification that is emailed even
+// when the user has turned email off
+func (t Template) IgnoresPreferences() bool {
+	return t == TemplatePasswordReset
+}
+
+var (
+	ErrUnknownTemplate = errors.New("unknown notification template")
+	ErrDeliveryFailed  = errors.New("notification delivery failed")
+)
+
+type Recipient struct {
+	Email string
+	Name  string
+}
+
+// Notification is a request to render Template with Data and deliver it to Recipient
+type Notification struct {
+	Template  Template
+	Recipient Recipient
+	Data      map[string]any
+	// UserID records the notification in the user's history and applies their Preferences; 0 for neither
+	UserID uint
+}
+
+// SentNotification is one entry in a user's notification history
+type SentNotification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null" json:"user_id"`
+	Template  Template   `gorm:"type:varchar(50);not null" json:"template"`
+	Subject   string     `gorm:"type:varchar(255);not null" json:"subject"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (SentNotification) TableName() string {
+	return "notifications"
+}
+
+// Preferences are the channels a user accepts notifications on. Only email is delivered today;
+// SMS and push are stored so those channels can honour them when they are added.
+type Preferences struct {
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	Email     bool      `gorm:"not null" json:"email"`
+	SMS       bool      `gorm:"not null" json:"sms"`
+	Push      bool      `gorm:"not null" json:"push"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Preferences) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultPreferences applies to users who never saved any: every channel is on
+func DefaultPreferences(userID uint) Preferences {
+	return Preferences{UserID: userID, Email: true, SMS: true, Push: true}
+}
+
+// Email is a rendered notification ready to be sent
+type Email struct {
+	To       string
+	Subject  string
+	HTMLBody string
+}
+
+type Renderer interface {
+	Render(notification *Notification) (*Email, error)
+}
+
+type Mailer interface {
+	Send(ctx context.Context, email *Email) error
+}
+
+type NotificationRepository interface {
+	CreateSentNotification(ctx context.Context, notification *SentNotification) error
+	// ListSentNotifications returns a page of the user's history, newest first, with the total and unread counts
+	ListSentNotifications(ctx context.Context, userID uint, page, perPage int) ([]SentNotification, int, int, error)
+	// MarkRead marks the user's unread notifications in ids read, or all of them when ids is empty
+	MarkRead(ctx context.Context, userID uint, ids []uint) (int, error)
+	// GetPreferences returns DefaultPreferences when the user has not saved any
+	GetPreferences(ctx context.Context, userID uint) (Preferences, error)
+	SavePreferences(ctx context.Context, preferences *Preferences) error
+}
+
+type NotificationUsecase interface {
+	Send(ctx context.Context, notification *Notification) error
+	ListNotifications(ctx context.Context, userID uint, page, perPage int) ([]SentNotification, int, int, error)
+	MarkRead(ctx context.Context, userID uint, ids []uint) (int, error)
+	UpdatePreferences(ctx context.Context, preferences *Preferences) error
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/domain"
+)
+
+// DeadLetterLog appends permanently failed emails as JSON lines so they can be inspected or replayed
+type DeadLetterLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+type deadLetterEntry struct {
+	FailedAt time.Time `json:"failed_at"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	To       string    `json:"to"`
+	Subject  string    `json:"subject"`
+	HTMLBody string    `json:"html_body"`
+}
+
+func NewDeadLetterLog(path string) (*DeadLetterLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create dead letter directory: %w", err)
+	}
+	return &DeadLetterLog{path: path}, nil
+}
+
+func (d *DeadLetterLog) Write(email *domain.Email, attempts int, sendErr error) error {
+	entry, err := json.Marshal(deadLetterEntry{
+		FailedAt: time.Now().UTC(),
+		Attempts: attempts,
+		Error:    sendErr.Error(),
+		To:       email.To,
+		Subject:  email.Subject,
+		HTMLBody: email.HTMLBody,
+	})
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	file, err := os.OpenFile(d.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(entry, '\n'))
+	return err
+}
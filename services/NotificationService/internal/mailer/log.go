@@ -0,0 +1,22 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/domain"
+)
+
+// LogMailer writes rendered emails to the log instead of sending them, for local development
+type LogMailer struct{}
+
+var _ domain.Mailer = (*LogMailer)(nil)
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(_ context.Context, email *domain.Email) error {
+	logger.Infof("event=email_logged to=%s subject=%q body=%q", email.To, email.Subject, email.HTMLBody)
+	return nil
+}
@@ -0,0 +1,85 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/domain"
+)
+
+// RetryMailer retries transient failures of the wrapped mailer with exponential backoff.
+// Emails that still fail, or fail permanently, are written to the dead letter log.
+type RetryMailer struct {
+	next        domain.Mailer
+	maxAttempts int
+	baseDelay   time.Duration
+	deadLetter  *DeadLetterLog
+}
+
+var _ domain.Mailer = (*RetryMailer)(nil)
+
+func NewRetryMailer(next domain.Mailer, maxRetries int, baseDelay time.Duration, deadLetter *DeadLetterLog) *RetryMailer {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if baseDelay <= 0 {
+		baseDelay = 500 * time.Millisecond
+	}
+
+	return &RetryMailer{
+		next:        next,
+		maxAttempts: maxRetries + 1,
+		baseDelay:   baseDelay,
+		deadLetter:  deadLetter,
+	}
+}
+
+func (m *RetryMailer) Send(ctx context.Context, email *domain.Email) error {
+	var err error
+	attempt := 0
+
+retry:
+	for attempt < m.maxAttempts {
+		attempt++
+		if err = m.next.Send(ctx, email); err == nil {
+			return nil
+		}
+		if !isTransient(err) || attempt == m.maxAttempts {
+			break
+		}
+
+		delay := m.baseDelay * time.Duration(1<<(attempt-1))
+		logger.Warnf("event=email_send_retry to=%s attempt=%d delay=%s error=%v", email.To, attempt, delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			err = ctx.Err()
+			break retry
+		}
+	}
+
+	logger.Errorf("event=email_send_failed to=%s attempts=%d error=%v", email.To, attempt, err)
+	if m.deadLetter != nil {
+		if dlErr := m.deadLetter.Write(email, attempt, err); dlErr != nil {
+			logger.Errorf("event=dead_letter_write_failed to=%s error=%v", email.To, dlErr)
+		}
+	}
+
+	return fmt.Errorf("%w: %v", domain.ErrDeliveryFailed, err)
+}
+
+// isTransient reports whether an SMTP error is worth retrying.
+// 5xx replies are permanent (bad recipient, rejected content), everything else
+// (4xx replies, network errors, timeouts) may succeed later.
+func isTransient(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code < 500
+	}
+	return !errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+create table notifications (
+    id serial primary key,
+    user_id int not null,
+    template varchar(50) not null,
+    subject varchar(255) not null,
+    read_at timestamp with time zone,
+    created_at timestamp with time zone not null default current_timestamp
+);
+
+create index idx_notifications_user on notifications (user_id, id desc);
+create index idx_notifications_unread on notifications (user_id) where read_at is null;
+
+create table notification_preferences (
+    user_id int primary key,
+    email boolean not null,
+    sms boolean not null,
+    push boolean not null,
+    updated_at timestamp with time zone not null default current_timestamp
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table notification_preferences;
+drop table notifications;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+package repository
+
+import "errors"
+
+var (
+	ErrDatabaseConnection = errors.New("database connection error")
+	ErrDatabaseQuery      = errors.New("database query failed")
+	ErrInvalidData        = errors.New("invalid data provided")
+)
@@ -0,0 +1,26 @@
+package postgresql
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/repository"
+)
+
+// mapPostgresError maps Postgres-specific errors to readable repository errors
+func mapPostgresError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505", "23502", "23514": // unique, not_null and check violations
+			return repository.ErrInvalidData
+		case "08000", "08003", "08006": // connection errors
+			return repository.ErrDatabaseConnection
+		}
+	}
+	return repository.ErrDatabaseQuery
+}
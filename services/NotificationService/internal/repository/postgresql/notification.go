@@ -0,0 +1,130 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository struct {
+	db     *gorm.DB
+	tracer trace.Tracer
+}
+
+var _ domain.NotificationRepository = (*NotificationRepository)(nil)
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db, tracer: otel.Tracer("notification-repo")}
+}
+
+func (r *NotificationRepository) CreateSentNotification(ctx context.Context, notification *domain.SentNotification) error {
+	ctx, span := r.tracer.Start(ctx, "NotificationRepository.CreateSentNotification")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Create(notification).Error; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return mapPostgresError(err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) ListSentNotifications(ctx context.Context, userID uint, page, perPage int) ([]domain.SentNotification, int, int, error) {
+	ctx, span := r.tracer.Start(ctx, "NotificationRepository.ListSentNotifications")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("user.id", int(userID)))
+
+	var counts struct {
+		Total  int64
+		Unread int64
+	}
+	err := r.db.WithContext(ctx).Model(&domain.SentNotification{}).
+		Select("count(*) as total, count(*) filter (where read_at is null) as unread").
+		Where("user_id = ?", userID).
+		Scan(&counts).Error
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, 0, mapPostgresError(err)
+	}
+
+	var notifications []domain.SentNotification
+	err = r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id desc").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&notifications).Error
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, 0, mapPostgresError(err)
+	}
+
+	span.SetAttributes(attribute.Int("notifications.count", len(notifications)))
+	return notifications, int(counts.Total), int(counts.Unread), nil
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID uint, ids []uint) (int, error) {
+	ctx, span := r.tracer.Start(ctx, "NotificationRepository.MarkRead")
+	defer span.End()
+
+	// Scoping by user_id is what keeps one user from marking another's notifications
+	query := r.db.WithContext(ctx).Model(&domain.SentNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	result := query.Update("read_at", time.Now().UTC())
+	if result.Error != nil {
+		span.RecordError(result.Error)
+		span.SetStatus(codes.Error, result.Error.Error())
+		return 0, mapPostgresError(result.Error)
+	}
+
+	span.SetAttributes(attribute.Int64("notifications.marked_read", result.RowsAffected))
+	return int(result.RowsAffected), nil
+}
+
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID uint) (domain.Preferences, error) {
+	ctx, span := r.tracer.Start(ctx, "NotificationRepository.GetPreferences")
+	defer span.End()
+
+	var preferences domain.Preferences
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&preferences).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.DefaultPreferences(userID), nil
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return domain.Preferences{}, mapPostgresError(err)
+	}
+	return preferences, nil
+}
+
+func (r *NotificationRepository) SavePreferences(ctx context.Context, preferences *domain.Preferences) error {
+	ctx, span := r.tracer.Start(ctx, "NotificationRepository.SavePreferences")
+	defer span.End()
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email", "sms", "push", "updated_at"}),
+	}).Create(preferences).Error
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return mapPostgresError(err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package postgresql
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/domain"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens an empty SQLite database with the notification tables, for queries SQLite runs as Postgres does
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&domain.SentNotification{}, &domain.Preferences{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// seedHistory records a notification for each of userIDs in turn, with IDs from 1
+func seedHistory(t *testing.T, repo *NotificationRepository, userIDs ...uint) {
+	t.Helper()
+	for _, userID := range userIDs {
+		if err := repo.CreateSentNotification(context.Background(), &domain.SentNotification{UserID: userID, Template: domain.TemplateWelcome, Subject: "Welcome"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func notificationIDs(notifications []domain.SentNotification) []uint {
+	ids := make([]uint, len(notifications))
+	for i, notification := range notifications {
+		ids[i] = notification.ID
+	}
+	return ids
+}
+
+func TestListSentNotificationsOnlyListsTheUsersOwn(t *testing.T) {
+	repo := NewNotificationRepository(newTestDB(t))
+	seedHistory(t, repo, 7, 8, 7, 7, 8)
+	ctx := context.Background()
+
+	if _, err := repo.MarkRead(ctx, 7, []uint{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	page, total, unread, err := repo.ListSentNotifications(ctx, 7, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := notificationIDs(page); !reflect.DeepEqual(got, []uint{4, 3}) || total != 3 || unread != 2 {
+		t.Errorf("page 1 = %v of %d with %d unread, want [4 3] of 3 with 2 unread", got, total, unread)
+	}
+	page, _, _, err = repo.ListSentNotifications(ctx, 7, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := notificationIDs(page); !reflect.DeepEqual(got, []uint{1}) {
+		t.Errorf("page 2 = %v, want [1]", got)
+	}
+}
+
+func TestMarkReadOnlyMarksTheUsersOwn(t *testing.T) {
+	tests := []struct {
+		name        string
+		ids         []uint
+		wantUpdated int
+		wantUnread  int
+	}{
+		{name: "given ids", ids: []uint{1, 3}, wantUpdated: 1, wantUnread: 1},
+		{name: "all", wantUpdated: 2, wantUnread: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewNotificationRepository(newTestDB(t))
+			// Notification 3 is user 8's, so user 7 cannot mark it
+			seedHistory(t, repo, 7, 7, 8)
+			ctx := context.Background()
+
+			updated, err := repo.MarkRead(ctx, 7, tt.ids)
+			if err != nil || updated != tt.wantUpdated {
+				t.Fatalf("MarkRead = %d, %v, want %d", updated, err, tt.wantUpdated)
+			}
+			if _, _, unread, _ := repo.ListSentNotifications(ctx, 7, 1, 10); unread != tt.wantUnread {
+				t.Errorf("user 7 has %d unread, want %d", unread, tt.wantUnread)
+			}
+			if _, _, unread, _ := repo.ListSentNotifications(ctx, 8, 1, 10); unread != 1 {
+				t.Errorf("user 8 has %d unread, want their notification left unread", unread)
+			}
+		})
+	}
+}
+
+func TestPreferences(t *testing.T) {
+	repo := NewNotificationRepository(newTestDB(t))
+	ctx := context.Background()
+
+	preferences, err := repo.GetPreferences(ctx, 7)
+	if err != nil || preferences != domain.DefaultPreferences(7) {
+		t.Fatalf("unsaved preferences = %+v, %v, want the defaults", preferences, err)
+	}
+
+	// Saving twice replaces the first preferences
+	for _, saved := range []domain.Preferences{{UserID: 7, SMS: true}, {UserID: 7, Push: true}} {
+		if err := repo.SavePreferences(ctx, &saved); err != nil {
+			t.Fatal(err)
+		}
+	}
+	preferences, err = repo.GetPreferences(ctx, 7)
+	if err != nil || preferences.Email || preferences.SMS || !preferences.Push {
+		t.Errorf("preferences = %+v, %v, want only push on", preferences, err)
+	}
+}
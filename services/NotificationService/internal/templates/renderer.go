@@ -0,0 +1,70 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"path/filepath"
+	"strings"
+
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/domain"
+)
+
+// Renderer renders notifications from html/template files.
+// Every file defines a "subject" and a "body" template and is named after its domain.Template.
+type Renderer struct {
+	templates map[domain.Template]*template.Template
+}
+
+var _ domain.Renderer = (*Renderer)(nil)
+
+// NewRenderer parses every known template from dir, failing fast if one is missing or invalid
+func NewRenderer(dir string) (*Renderer, error) {
+	templates := make(map[domain.Template]*template.Template, len(domain.Templates))
+	for _, name := range domain.Templates {
+		path := filepath.Join(dir, string(name)+".html")
+		tmpl, err := template.ParseFiles(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+		if tmpl.Lookup("subject") == nil || tmpl.Lookup("body") == nil {
+			return nil, fmt.Errorf("template %s must define subject and body", name)
+		}
+		templates[name] = tmpl
+	}
+
+	return &Renderer{templates: templates}, nil
+}
+
+func (r *Renderer) Render(notification *domain.Notification) (*domain.Email, error) {
+	tmpl, ok := r.templates[notification.Template]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownTemplate, notification.Template)
+	}
+
+	data := struct {
+		Recipient domain.Recipient
+		Data      map[string]any
+	}{
+		Recipient: notification.Recipient,
+		Data:      notification.Data,
+	}
+
+	var subject bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("failed to render subject for %s: %w", notification.Template, err)
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return nil, fmt.Errorf("failed to render body for %s: %w", notification.Template, err)
+	}
+
+	return &domain.Email{
+		To: notification.Recipient.Email,
+		// Subjects are plain text headers, so undo the HTML escaping applied by html/template
+		Subject:  strings.TrimSpace(html.UnescapeString(subject.String())),
+		HTMLBody: body.String(),
+	}, nil
+}
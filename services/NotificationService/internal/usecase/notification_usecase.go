@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type NotificationUsecase struct {
+	renderer domain.Renderer
+	mailer   domain.Mailer
+	repo     domain.NotificationRepository
+	tracer   trace.Tracer
+}
+
+var _ domain.NotificationUsecase = (*NotificationUsecase)(nil)
+
+func NewNotificationUsecase(renderer domain.Renderer, mailer domain.Mailer, repo domain.NotificationRepository) *NotificationUsecase {
+	return &NotificationUsecase{
+		renderer: renderer,
+		mailer:   mailer,
+		repo:     repo,
+		tracer:   otel.Tracer("notification-usecase"),
+	}
+}
+
+// Send renders the notification and emails it. A notification for a user is also recorded in their
+// history, and is only emailed when their preferences allow it.
+func (u *NotificationUsecase) Send(ctx context.Context, notification *domain.Notification) error {
+	ctx, span := u.tracer.Start(ctx, "NotificationUsecase.Send")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("notification.template", string(notification.Template)))
+
+	email, err := u.renderer.Render(notification)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if notification.UserID != 0 && !u.recordForUser(ctx, notification, email) {
+		span.SetStatus(codes.Ok, "email disabled by user preferences")
+		return nil
+	}
+
+	if err := u.mailer.Send(ctx, email); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "notification sent")
+	return nil
+}
+
+// recordForUser adds the notification to the user's history and reports whether it should be emailed.
+// Database failures are logged and the email goes out, so an outage never costs the user the email itself.
+func (u *NotificationUsecase) recordForUser(ctx context.Context, notification *domain.Notification, email *domain.Email) bool {
+	sent := &domain.SentNotification{
+		UserID:   notification.UserID,
+		Template: notification.Template,
+		Subject:  email.Subject,
+	}
+	if err := u.repo.CreateSentNotification(ctx, sent); err != nil {
+		logger.Warnf("event=notification_history_failed user_id=%d template=%s error=%v", notification.UserID, notification.Template, err)
+	}
+
+	if notification.Template.IgnoresPreferences() {
+		return true
+	}
+	preferences, err := u.repo.GetPreferences(ctx, notification.UserID)
+	if err != nil {
+		logger.Warnf("event=notification_preferences_failed user_id=%d error=%v", notification.UserID, err)
+		return true
+	}
+	return preferences.Email
+}
+
+func (u *NotificationUsecase) ListNotifications(ctx context.Context, userID uint, page, perPage int) ([]domain.SentNotification, int, int, error) {
+	ctx, span := u.tracer.Start(ctx, "NotificationUsecase.ListNotifications")
+	defer span.End()
+
+	notifications, total, unread, err := u.repo.ListSentNotifications(ctx, userID, page, perPage)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, 0, err
+	}
+	return notifications, total, unread, nil
+}
+
+func (u *NotificationUsecase) MarkRead(ctx context.Context, userID uint, ids []uint) (int, error) {
+	ctx, span := u.tracer.Start(ctx, "NotificationUsecase.MarkRead")
+	defer span.End()
+
+	updated, err := u.repo.MarkRead(ctx, userID, ids)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+	return updated, nil
+}
+
+func (u *NotificationUsecase) UpdatePreferences(ctx context.Context, preferences *domain.Preferences) error {
+	ctx, span := u.tracer.Start(ctx, "NotificationUsecase.UpdatePreferences")
+	defer span.End()
+
+	if err := u.repo.SavePreferences(ctx, preferences); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
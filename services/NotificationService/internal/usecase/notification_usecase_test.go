@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/NotificationService/internal/domain"
+)
+
+// subjectRenderer renders every notification as an email with the template as its subject
+type subjectRenderer struct{}
+
+func (subjectRenderer) Render(notification *domain.Notification) (*domain.Email, error) {
+	return &domain.Email{To: notification.Recipient.Email, Subject: string(notification.Template)}, nil
+}
+
+// outbox keeps the emails sent through it
+type outbox struct {
+	sent []*domain.Email
+}
+
+func (o *outbox) Send(_ context.Context, email *domain.Email) error {
+	o.sent = append(o.sent, email)
+	return nil
+}
+
+// history keeps notifications and preferences in memory, or fails every call when err is set; the methods it
+// does not implement panic through the nil interface
+type history struct {
+	domain.NotificationRepository
+	recorded    []domain.SentNotification
+	preferences map[uint]domain.Preferences
+	err         error
+}
+
+func (h *history) CreateSentNotification(_ context.Context, notification *domain.SentNotification) error {
+	if h.err != nil {
+		return h.err
+	}
+	h.recorded = append(h.recorded, *notification)
+	return nil
+}
+
+func (h *history) GetPreferences(_ context.Context, userID uint) (domain.Preferences, error) {
+	if h.err != nil {
+		return domain.Preferences{}, h.err
+	}
+	if preferences, ok := h.preferences[userID]; ok {
+		return preferences, nil
+	}
+	return domain.DefaultPreferences(userID), nil
+}
+
+func TestSendHonoursEmailPreferences(t *testing.T) {
+	emailOff := map[uint]domain.Preferences{7: {UserID: 7, SMS: true, Push: true}}
+	tests := []struct {
+		name         string
+		template     domain.Template
+		userID       uint
+		history      *history
+		wantEmailed  bool
+		wantRecorded bool
+	}{
+		{name: "no user", template: domain.TemplateOrderConfirmation, history: &history{}, wantEmailed: true},
+		{name: "email on", template: domain.TemplateOrderConfirmation, userID: 8, history: &history{preferences: emailOff}, wantEmailed: true, wantRecorded: true},
+		{name: "email off", template: domain.TemplateOrderConfirmation, userID: 7, history: &history{preferences: emailOff}, wantRecorded: true},
+		{name: "password reset with email off", template: domain.TemplatePasswordReset, userID: 7, history: &history{preferences: emailOff}, wantEmailed: true, wantRecorded: true},
+		// An outage of the notification database must not cost the user the email
+		{name: "database down", template: domain.TemplateOrderConfirmation, userID: 7, history: &history{err: errors.New("connection refused")}, wantEmailed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer := &outbox{}
+			u := NewNotificationUsecase(subjectRenderer{}, mailer, tt.history)
+
+			err := u.Send(context.Background(), &domain.Notification{
+				Template:  tt.template,
+				Recipient: domain.Recipient{Email: "user@example.com"},
+				UserID:    tt.userID,
+			})
+			if err != nil {
+				t.Fatalf("Send = %v", err)
+			}
+			if emailed := len(mailer.sent) == 1; emailed != tt.wantEmailed {
+				t.Errorf("emailed %d times, want emailed = %v", len(mailer.sent), tt.wantEmailed)
+			}
+			if recorded := len(tt.history.recorded) == 1; recorded != tt.wantRecorded {
+				t.Fatalf("recorded %+v, want recorded = %v", tt.history.recorded, tt.wantRecorded)
+			}
+			if tt.wantRecorded {
+				if entry := tt.history.recorded[0]; entry.UserID != tt.userID || entry.Template != tt.template || entry.Subject != string(tt.template) {
+					t.Errorf("recorded %+v, want the rendered %s for user %d", entry, tt.template, tt.userID)
+				}
+			}
+		})
+	}
+}
@@ -9,18 +9,20 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/kareemhamed001/e-commerce/pkg/db"
+	"github.com/kareemhamed001/e-commerce/pkg/events"
 	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/pkg/spiffeauth"
 	"github.com/kareemhamed001/e-commerce/pkg/tracer"
 	"github.com/kareemhamed001/e-commerce/services/OrderService/config"
 	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/delivery/grpc/handler"
 	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/outbox"
 	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/repository/postgresql"
 	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/usecase"
 	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
 	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
@@ -39,6 +41,13 @@ func main() {
 	shutdownTracer := initTracing(ctx)
 	defer shutdownTracer()
 
+	spiffeSource, err := spiffeauth.NewSource(ctx, config.SPIFFEEndpointSocket)
+	if err != nil {
+		close(done)
+		panic(err)
+	}
+	defer spiffeSource.Close()
+
 	dbConfig := &db.Config{
 		DBDriver:              config.DBDriver,
 		DSN:                   config.DBDSN,
@@ -55,13 +64,27 @@ func main() {
 		panic("failed to connect database")
 	}
 
-	orderDB.AutoMigrate(&domain.Order{}, &domain.OrderItem{})
+	orderDB.AutoMigrate(&domain.Order{}, &domain.OrderItem{}, &domain.OutboxEvent{}, &domain.Invoice{}, &domain.InvoiceCounter{})
+
+	eventBus, err := events.NewBus(events.Config{
+		Driver:      config.EventsDriver,
+		RabbitMQURL: config.RabbitMQURL,
+		Exchange:    config.EventsExchange,
+	})
+	if err != nil {
+		close(done)
+		panic("failed to initialize event bus: " + err.Error())
+	}
+	defer func() {
+		_ = eventBus.Close()
+	}()
 
 	productConn, err := grpc.NewClient(
 		config.ProductServiceGRPCAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(spiffeSource.ClientCredentials()),
 		grpc.WithChainUnaryInterceptor(
-			grpcmiddleware.InternalAuthUnaryClientInterceptor(config.InternalAuthToken),
+			grpcmiddleware.InternalAuthUnaryClientInterceptor(config.InternalAuthToken, config.ServiceName),
+			grpcmiddleware.RequestIDUnaryClientInterceptor(),
 			grpcmiddleware.CircuitBreakerUnaryClientInterceptor(
 				"order-service->"+config.ProductServiceGRPCAddr,
 				grpcmiddleware.CircuitBreakerConfig{
@@ -85,9 +108,10 @@ func main() {
 
 	userConn, err := grpc.Dial(
 		config.UserServiceGRPCAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(spiffeSource.ClientCredentials()),
 		grpc.WithChainUnaryInterceptor(
-			grpcmiddleware.InternalAuthUnaryClientInterceptor(config.InternalAuthToken),
+			grpcmiddleware.InternalAuthUnaryClientInterceptor(config.InternalAuthToken, config.ServiceName),
+			grpcmiddleware.RequestIDUnaryClientInterceptor(),
 			grpcmiddleware.CircuitBreakerUnaryClientInterceptor(
 				"order-service->"+config.UserServiceGRPCAddr,
 				grpcmiddleware.CircuitBreakerConfig{
@@ -110,18 +134,24 @@ func main() {
 	}()
 
 	orderRepo := postgresql.NewOrderRepository(orderDB)
+	outboxRelay := outbox.NewRelay(postgresql.NewOutboxRepository(orderDB), eventBus, config.OutboxPollInterval, config.OutboxBatchSize, config.OutboxMaxAttempts)
+	go outboxRelay.Run(done)
 	productClient := productpb.NewProductServiceClient(productConn)
 	userClient := userpb.NewUserServiceClient(userConn)
-	orderUsecase := usecase.NewOrderUsecase(orderRepo, productClient, userClient)
+	shippingUsecase := usecase.NewShippingUsecase(config.ShippingRates, userClient)
+	orderUsecase := usecase.NewOrderUsecase(orderRepo, productClient, userClient, shippingUsecase)
 
 	validate := validator.New()
-	grpcHandler := handler.NewOrderGRPCHandler(orderUsecase, validate, config.InternalAuthToken)
+	shippingHandler := handler.NewShippingGRPCHandler(shippingUsecase, validate)
+	grpcHandler := handler.NewOrderGRPCHandler(orderUsecase, shippingHandler, validate)
 
-	if err := grpcHandler.Run(done, config.GRPCPort); err != nil {
+	serverOpts := append(grpcmiddleware.DefaultServerOptions(logger.NewStructured(config.AppEnv), config.InternalAuthToken, config.InternalAuthPolicy), spiffeSource.ServerOptions()...)
+	if err := grpcHandler.Run(done, config.GRPCPort, serverOpts...); err != nil {
 		logger.Errorf("failed to start gRPC server: %v", err)
 		close(done)
 		panic(err)
 	}
+	grpcmiddleware.ServeMetrics(done, config.MetricsPort)
 
 	// graceful shutdown
 	sigChan := make(chan os.Signal, 1)
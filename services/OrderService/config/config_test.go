@@ -0,0 +1,34 @@
+package config
+
+import "testing"
+
+func TestEventsDriverDefaultsToRabbitMQOutsideDevelopment(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "development", env: map[string]string{"APP_ENV": "development"}, want: "memory"},
+		{name: "production", env: map[string]string{"APP_ENV": "production"}, want: "rabbitmq"},
+		{name: "staging", env: map[string]string{"APP_ENV": "staging"}, want: "rabbitmq"},
+		{name: "set explicitly", env: map[string]string{"APP_ENV": "production", "EVENTS_DRIVER": "memory"}, want: "memory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// An empty directory, so no .env file is found
+			t.Chdir(t.TempDir())
+			t.Setenv("INTERNAL_AUTH_TOKEN", "internal-token")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.EventsDriver != tt.want {
+				t.Errorf("EventsDriver = %q, want %q", cfg.EventsDriver, tt.want)
+			}
+		})
+	}
+}
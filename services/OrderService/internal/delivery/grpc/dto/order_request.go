@@ -11,6 +11,15 @@ type CreateOrderRequest struct {
 	ShippingDurationDays int              `json:"shipping_duration_days" validate:"gte=0"`
 	Discount             float32          `json:"discount" validate:"gte=0"`
 	Items                []OrderItemInput `json:"items" validate:"required,min=1,dive"`
+	AddressID            uint             `json:"address_id" validate:"required,gt=0"`
+	// ShippingOptionID, when set, replaces ShippingCost and ShippingDurationDays with the option's quote
+	ShippingOptionID string `json:"shipping_option_id" validate:"omitempty,max=64"`
+}
+
+type ShippingQuoteRequest struct {
+	UserID    uint             `json:"user_id" validate:"required,gt=0"`
+	AddressID uint             `json:"address_id" validate:"required,gt=0"`
+	Items     []OrderItemInput `json:"items" validate:"required,min=1,dive"`
 }
 
 type AddOrderItemRequest struct {
@@ -19,7 +28,61 @@ type AddOrderItemRequest struct {
 	Quantity  int  `json:"quantity" validate:"required,gt=0"`
 }
 
+type UpdateOrderItemQuantityRequest struct {
+	OrderID  uint `json:"order_id" validate:"required,gt=0"`
+	ItemID   uint `json:"item_id" validate:"required,gt=0"`
+	Quantity int  `json:"quantity" validate:"required,gt=0"`
+}
+
+type ListOrdersRequest struct {
+	Page      int    `json:"page" validate:"gte=1"`
+	PerPage   int    `json:"per_page" validate:"gte=1,lte=100"`
+	UserID    uint   `json:"user_id"`
+	Status    string `json:"status" validate:"omitempty,oneof=pending paid shipped delivered canceled"`
+	StartDate string `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	SortBy    string `json:"sort_by" validate:"omitempty,oneof=id created_at total"`
+	SortOrder string `json:"sort_order" validate:"omitempty,oneof=asc desc"`
+}
+
+type ExportOrdersRequest struct {
+	Status    string `json:"status" validate:"omitempty,oneof=pending paid shipped delivered canceled"`
+	StartDate string `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
 type UpdateOrderStatusRequest struct {
 	OrderID uint   `json:"order_id" validate:"required,gt=0"`
 	Status  string `json:"status" validate:"required,oneof=pending paid shipped delivered canceled"`
-}
\ No newline at end of file
+}
+
+type RevenueSummaryRequest struct {
+	StartDate   string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate     string `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Granularity string `json:"granularity" validate:"required,oneof=day week month"`
+}
+
+type SalesReportRequest struct {
+	From    string `json:"from" validate:"required,datetime=2006-01-02"`
+	To      string `json:"to" validate:"required,datetime=2006-01-02"`
+	GroupBy string `json:"group_by" validate:"required,oneof=day week month"`
+}
+
+type TopProductsRequest struct {
+	From  string `json:"from" validate:"required,datetime=2006-01-02"`
+	To    string `json:"to" validate:"required,datetime=2006-01-02"`
+	Limit int    `json:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type HasPurchasedProductRequest struct {
+	UserID    uint `json:"user_id" validate:"required,gt=0"`
+	ProductID uint `json:"product_id" validate:"required,gt=0"`
+}
+
+type AnonymiseUserOrdersRequest struct {
+	UserID uint `json:"user_id" validate:"required,gt=0"`
+}
+
+type WatchOrderStatusRequest struct {
+	OrderID uint `json:"order_id" validate:"required,gt=0"`
+}
@@ -11,6 +11,15 @@ type OrderItemResponse struct {
 	TotalPrice float32 `json:"total_price"`
 }
 
+type ShippingAddress struct {
+	AddressID  uint   `json:"address_id"`
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	Country    string `json:"country"`
+	PostalCode string `json:"postal_code"`
+}
+
 type OrderResponse struct {
 	ID               uint                `json:"id"`
 	UserID           uint                `json:"user_id"`
@@ -20,6 +29,52 @@ type OrderResponse struct {
 	Total            float32             `json:"total"`
 	Status           string              `json:"status"`
 	Items            []OrderItemResponse `json:"items"`
+	ShippingAddress  *ShippingAddress    `json:"shipping_address,omitempty"`
 	CreatedAt        time.Time           `json:"created_at"`
 	UpdatedAt        time.Time           `json:"updated_at"`
 }
+
+type InvoiceResponse struct {
+	Number   string    `json:"number"`
+	OrderID  uint      `json:"order_id"`
+	IssuedAt time.Time `json:"issued_at"`
+	PDF      []byte    `json:"-"`
+}
+
+type RevenuePeriodResponse struct {
+	Start      string  `json:"start"`
+	End        string  `json:"end"`
+	Revenue    float64 `json:"revenue"`
+	OrderCount int     `json:"order_count"`
+}
+
+type SalesPeriodResponse struct {
+	Start             string  `json:"start"`
+	End               string  `json:"end"`
+	Revenue           float64 `json:"revenue"`
+	OrderCount        int     `json:"order_count"`
+	AverageOrderValue float64 `json:"average_order_value"`
+	CanceledCount     int     `json:"canceled_count"`
+	CanceledValue     float64 `json:"canceled_value"`
+}
+
+type SalesReportResponse struct {
+	Periods []SalesPeriodResponse `json:"periods"`
+	Total   SalesPeriodResponse   `json:"total"`
+}
+
+type ProductSalesResponse struct {
+	ProductID  uint    `json:"product_id"`
+	Name       string  `json:"name"`
+	Quantity   int     `json:"quantity"`
+	Revenue    float64 `json:"revenue"`
+	OrderCount int     `json:"order_count"`
+}
+
+type ShippingOptionResponse struct {
+	ID                string    `json:"id"`
+	Name              string    `json:"name"`
+	Cost              float32   `json:"cost"`
+	DurationDays      int       `json:"duration_days"`
+	EstimatedDelivery time.Time `json:"estimated_delivery"`
+}
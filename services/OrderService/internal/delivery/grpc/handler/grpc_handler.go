@@ -2,38 +2,46 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
+	customJWT "github.com/kareemhamed001/e-commerce/pkg/jwt"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/delivery/grpc/dto"
 	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/repository"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/usecase"
 	orderpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/order"
+	shippingpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/shipping"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type OrderGRPCHandler struct {
 	orderpb.UnimplementedOrderServiceServer
 	orderUsecase domain.OrderUsecase
+	shipping     *ShippingGRPCHandler
 	validate     *validator.Validate
 	tracer       trace.Tracer
-	internalAuthToken string
 }
 
 var _ orderpb.OrderServiceServer = (*OrderGRPCHandler)(nil)
 
-func NewOrderGRPCHandler(orderUsecase domain.OrderUsecase, validate *validator.Validate, internalAuthToken string) *OrderGRPCHandler {
+// NewOrderGRPCHandler creates the order handler; Run also serves shipping on the same server
+func NewOrderGRPCHandler(orderUsecase domain.OrderUsecase, shipping *ShippingGRPCHandler, validate *validator.Validate) *OrderGRPCHandler {
 	return &OrderGRPCHandler{
 		orderUsecase: orderUsecase,
+		shipping:     shipping,
 		validate:     validate,
 		tracer:       otel.Tracer("order_GRPC_handler"),
-		internalAuthToken: internalAuthToken,
 	}
 }
 
@@ -41,6 +49,12 @@ func (h *OrderGRPCHandler) CreateOrder(ctx context.Context, req *orderpb.CreateO
 	reqCtx, span := h.tracer.Start(ctx, "OrderHandler.CreateOrder")
 	defer span.End()
 
+	if err := grpcmiddleware.AuthorizeUser(ctx, uint(req.GetUserId()), customJWT.PermissionOrderWrite); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
 	items := make([]dto.OrderItemInput, 0, len(req.GetItems()))
 	for _, item := range req.GetItems() {
 		items = append(items, dto.OrderItemInput{
@@ -55,6 +69,8 @@ func (h *OrderGRPCHandler) CreateOrder(ctx context.Context, req *orderpb.CreateO
 		ShippingDurationDays: int(req.GetShippingDurationDays()),
 		Discount:             req.GetDiscount(),
 		Items:                items,
+		AddressID:            uint(req.GetAddressId()),
+		ShippingOptionID:     req.GetShippingOptionId(),
 	}
 
 	if err := h.validate.Struct(&createReq); err != nil {
@@ -67,6 +83,12 @@ func (h *OrderGRPCHandler) CreateOrder(ctx context.Context, req *orderpb.CreateO
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		switch {
+		case errors.Is(err, usecase.ErrShippingOptionUnavailable):
+			return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+		case errors.Is(err, usecase.ErrInsufficientStock):
+			return nil, status.Error(grpccodes.FailedPrecondition, err.Error())
+		}
 		return nil, err
 	}
 
@@ -84,6 +106,11 @@ func (h *OrderGRPCHandler) GetOrderByID(ctx context.Context, req *orderpb.GetOrd
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
+	if err := grpcmiddleware.AuthorizeUser(ctx, order.UserID, customJWT.PermissionOrderRead); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
 
 	return &orderpb.GetOrderByIDResponse{Order: mapOrderToPB(order)}, nil
 }
@@ -101,13 +128,30 @@ func (h *OrderGRPCHandler) ListOrders(ctx context.Context, req *orderpb.ListOrde
 		perPage = 10
 	}
 
-	var userID *uint
-	if req.GetUserId() > 0 {
-		id := uint(req.GetUserId())
-		userID = &id
+	// user_id 0 lists every user's orders, which only order readers may do
+	if err := grpcmiddleware.AuthorizeUser(ctx, uint(req.GetUserId()), customJWT.PermissionOrderRead); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	listReq := dto.ListOrdersRequest{
+		Page:      page,
+		PerPage:   perPage,
+		UserID:    uint(req.GetUserId()),
+		Status:    req.GetStatus(),
+		StartDate: req.GetStartDate(),
+		EndDate:   req.GetEndDate(),
+		SortBy:    req.GetSortBy(),
+		SortOrder: req.GetSortOrder(),
+	}
+	if err := h.validate.Struct(&listReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, err
 	}
 
-	orders, total, err := h.orderUsecase.ListOrders(reqCtx, userID, page, perPage)
+	orders, total, err := h.orderUsecase.ListOrders(reqCtx, &listReq)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -141,10 +185,19 @@ func (h *OrderGRPCHandler) AddOrderItem(ctx context.Context, req *orderpb.AddOrd
 		return nil, err
 	}
 
+	if err := h.authorizeOrder(reqCtx, addReq.OrderID, customJWT.PermissionOrderWrite); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
 	order, err := h.orderUsecase.AddOrderItem(reqCtx, &addReq)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, usecase.ErrInsufficientStock) {
+			return nil, status.Error(grpccodes.FailedPrecondition, err.Error())
+		}
 		return nil, err
 	}
 
@@ -155,6 +208,12 @@ func (h *OrderGRPCHandler) RemoveOrderItem(ctx context.Context, req *orderpb.Rem
 	reqCtx, span := h.tracer.Start(ctx, "OrderHandler.RemoveOrderItem")
 	defer span.End()
 
+	if err := h.authorizeOrder(reqCtx, uint(req.GetOrderId()), customJWT.PermissionOrderWrite); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
 	order, err := h.orderUsecase.RemoveOrderItem(reqCtx, uint(req.GetOrderId()), uint(req.GetItemId()))
 	if err != nil {
 		span.RecordError(err)
@@ -165,10 +224,58 @@ func (h *OrderGRPCHandler) RemoveOrderItem(ctx context.Context, req *orderpb.Rem
 	return &orderpb.RemoveOrderItemResponse{Order: mapOrderToPB(order)}, nil
 }
 
+func (h *OrderGRPCHandler) UpdateOrderItemQuantity(ctx context.Context, req *orderpb.UpdateOrderItemQuantityRequest) (*orderpb.UpdateOrderItemQuantityResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "OrderHandler.UpdateOrderItemQuantity")
+	defer span.End()
+
+	updateReq := dto.UpdateOrderItemQuantityRequest{
+		OrderID:  uint(req.GetOrderId()),
+		ItemID:   uint(req.GetItemId()),
+		Quantity: int(req.GetQuantity()),
+	}
+
+	if err := h.validate.Struct(&updateReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	if err := h.authorizeOrder(reqCtx, updateReq.OrderID, customJWT.PermissionOrderWrite); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	order, err := h.orderUsecase.UpdateOrderItemQuantity(reqCtx, &updateReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		switch {
+		case errors.Is(err, repository.ErrOrderNotFound), errors.Is(err, repository.ErrOrderItemNotFound):
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		case errors.Is(err, repository.ErrOrderNotPending), errors.Is(err, usecase.ErrInsufficientStock):
+			return nil, status.Error(grpccodes.FailedPrecondition, err.Error())
+		}
+		return nil, err
+	}
+
+	return &orderpb.UpdateOrderItemQuantityResponse{Order: mapOrderToPB(order)}, nil
+}
+
 func (h *OrderGRPCHandler) UpdateOrderStatus(ctx context.Context, req *orderpb.UpdateOrderStatusRequest) (*orderpb.UpdateOrderStatusResponse, error) {
 	reqCtx, span := h.tracer.Start(ctx, "OrderHandler.UpdateOrderStatus")
 	defer span.End()
 
+	if err := authorizePermission(ctx, customJWT.PermissionOrderWrite); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
 	updateReq := dto.UpdateOrderStatusRequest{
 		OrderID: uint(req.GetOrderId()),
 		Status:  req.GetStatus(),
@@ -184,21 +291,318 @@ func (h *OrderGRPCHandler) UpdateOrderStatus(ctx context.Context, req *orderpb.U
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, repository.ErrOrderCanceled) {
+			return nil, status.Error(grpccodes.FailedPrecondition, err.Error())
+		}
 		return nil, err
 	}
 
 	return &orderpb.UpdateOrderStatusResponse{Order: mapOrderToPB(order)}, nil
 }
 
-func (h *OrderGRPCHandler) Run(done <-chan any, port string) error {
+func (h *OrderGRPCHandler) GetRevenueSummary(ctx context.Context, req *orderpb.GetRevenueSummaryRequest) (*orderpb.GetRevenueSummaryResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "OrderHandler.GetRevenueSummary")
+	defer span.End()
+
+	if err := authorizePermission(ctx, customJWT.PermissionReportRead); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	summaryReq := dto.RevenueSummaryRequest{
+		StartDate:   req.GetStartDate(),
+		EndDate:     req.GetEndDate(),
+		Granularity: req.GetGranularity(),
+	}
+
+	if err := h.validate.Struct(&summaryReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, err
+	}
+
+	periods, err := h.orderUsecase.GetRevenueSummary(reqCtx, &summaryReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	responsePeriods := make([]*orderpb.RevenuePeriod, 0, len(periods))
+	for _, period := range periods {
+		responsePeriods = append(responsePeriods, &orderpb.RevenuePeriod{
+			Start:      period.Start,
+			End:        period.End,
+			Revenue:    period.Revenue,
+			OrderCount: int64(period.OrderCount),
+		})
+	}
+
+	return &orderpb.GetRevenueSummaryResponse{Periods: responsePeriods}, nil
+}
+
+func (h *OrderGRPCHandler) GetSalesReport(ctx context.Context, req *orderpb.GetSalesReportRequest) (*orderpb.GetSalesReportResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "OrderHandler.GetSalesReport")
+	defer span.End()
+
+	if err := authorizePermission(ctx, customJWT.PermissionReportRead); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	reportReq := dto.SalesReportRequest{
+		From:    req.GetFrom(),
+		To:      req.GetTo(),
+		GroupBy: req.GetGroupBy(),
+	}
+	if err := h.validate.Struct(&reportReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	report, err := h.orderUsecase.GetSalesReport(reqCtx, &reportReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, usecase.ErrInvalidReportRange) {
+			return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	periods := make([]*orderpb.SalesPeriod, 0, len(report.Periods))
+	for i := range report.Periods {
+		periods = append(periods, mapSalesPeriodToPB(&report.Periods[i]))
+	}
+	return &orderpb.GetSalesReportResponse{Periods: periods, Total: mapSalesPeriodToPB(&report.Total)}, nil
+}
+
+func (h *OrderGRPCHandler) GetTopProducts(ctx context.Context, req *orderpb.GetTopProductsRequest) (*orderpb.GetTopProductsResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "OrderHandler.GetTopProducts")
+	defer span.End()
+
+	if err := authorizePermission(ctx, customJWT.PermissionReportRead); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	topReq := dto.TopProductsRequest{
+		From:  req.GetFrom(),
+		To:    req.GetTo(),
+		Limit: int(req.GetLimit()),
+	}
+	if err := h.validate.Struct(&topReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	products, err := h.orderUsecase.GetTopProducts(reqCtx, &topReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, usecase.ErrInvalidReportRange) {
+			return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	response := make([]*orderpb.ProductSales, 0, len(products))
+	for _, product := range products {
+		response = append(response, &orderpb.ProductSales{
+			ProductId:  int64(product.ProductID),
+			Name:       product.Name,
+			Quantity:   int64(product.Quantity),
+			Revenue:    product.Revenue,
+			OrderCount: int64(product.OrderCount),
+		})
+	}
+	return &orderpb.GetTopProductsResponse{Products: response}, nil
+}
+
+func (h *OrderGRPCHandler) AnonymiseUserOrders(ctx context.Context, req *orderpb.AnonymiseUserOrdersRequest) (*orderpb.AnonymiseUserOrdersResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "OrderHandler.AnonymiseUserOrders")
+	defer span.End()
+
+	if err := grpcmiddleware.AuthorizeUser(ctx, uint(req.GetUserId()), customJWT.PermissionUserWrite); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	anonymiseReq := dto.AnonymiseUserOrdersRequest{UserID: uint(req.GetUserId())}
+	if err := h.validate.Struct(&anonymiseReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, err
+	}
+
+	count, err := h.orderUsecase.AnonymiseUserOrders(reqCtx, anonymiseReq.UserID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &orderpb.AnonymiseUserOrdersResponse{AnonymisedCount: count}, nil
+}
+
+func (h *OrderGRPCHandler) HasPurchasedProduct(ctx context.Context, req *orderpb.HasPurchasedProductRequest) (*orderpb.HasPurchasedProductResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "OrderHandler.HasPurchasedProduct")
+	defer span.End()
+
+	if err := grpcmiddleware.AuthorizeUser(ctx, uint(req.GetUserId()), customJWT.PermissionOrderRead); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	purchaseReq := dto.HasPurchasedProductRequest{
+		UserID:    uint(req.GetUserId()),
+		ProductID: uint(req.GetProductId()),
+	}
+	if err := h.validate.Struct(&purchaseReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, err
+	}
+
+	purchased, err := h.orderUsecase.HasPurchasedProduct(reqCtx, purchaseReq.UserID, purchaseReq.ProductID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &orderpb.HasPurchasedProductResponse{Purchased: purchased}, nil
+}
+
+// GetOrderInvoice returns the invoice to the order's owner or anyone holding order:read
+func (h *OrderGRPCHandler) GetOrderInvoice(ctx context.Context, req *orderpb.GetOrderInvoiceRequest) (*orderpb.GetOrderInvoiceResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "OrderHandler.GetOrderInvoice")
+	defer span.End()
+
+	orderID := uint(req.GetOrderId())
+	if req.GetOrderId() <= 0 {
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, "order_id is required")
+	}
+
+	if err := h.authorizeOrder(reqCtx, orderID, customJWT.PermissionOrderRead); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	invoice, err := h.orderUsecase.GetOrderInvoice(reqCtx, orderID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		switch {
+		case errors.Is(err, repository.ErrOrderNotFound):
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		case errors.Is(err, usecase.ErrInvoiceUnavailable):
+			return nil, status.Error(grpccodes.FailedPrecondition, err.Error())
+		}
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("invoice.number", invoice.Number))
+	return &orderpb.GetOrderInvoiceResponse{
+		InvoiceNumber: invoice.Number,
+		OrderId:       int64(invoice.OrderID),
+		IssuedAt:      formatTime(invoice.IssuedAt),
+		Pdf:           invoice.PDF,
+	}, nil
+}
+
+func (h *OrderGRPCHandler) WatchOrderStatus(req *orderpb.WatchOrderStatusRequest, stream grpc.ServerStreamingServer[orderpb.OrderStatusEvent]) error {
+	reqCtx, span := h.tracer.Start(stream.Context(), "OrderHandler.WatchOrderStatus")
+	defer span.End()
+
+	watchReq := dto.WatchOrderStatusRequest{OrderID: uint(req.GetOrderId())}
+	if err := h.validate.Struct(&watchReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return err
+	}
+	if err := h.authorizeOrder(reqCtx, watchReq.OrderID, customJWT.PermissionOrderRead); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return err
+	}
+
+	err := h.orderUsecase.WatchOrderStatus(reqCtx, watchReq.OrderID, func(order *dto.OrderResponse) error {
+		return stream.Send(&orderpb.OrderStatusEvent{
+			OrderId:   int64(order.ID),
+			Status:    order.Status,
+			UpdatedAt: formatTime(order.UpdatedAt),
+		})
+	})
+	if err != nil && reqCtx.Err() == nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (h *OrderGRPCHandler) ExportOrders(req *orderpb.ExportOrdersRequest, stream grpc.ServerStreamingServer[orderpb.ExportOrdersResponse]) error {
+	reqCtx, span := h.tracer.Start(stream.Context(), "OrderHandler.ExportOrders")
+	defer span.End()
+
+	// Exports cover every user's orders
+	if err := grpcmiddleware.AuthorizeUser(reqCtx, 0, customJWT.PermissionOrderRead); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return err
+	}
+
+	exportReq := dto.ExportOrdersRequest{
+		Status:    req.GetStatus(),
+		StartDate: req.GetStartDate(),
+		EndDate:   req.GetEndDate(),
+	}
+	if err := h.validate.Struct(&exportReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	err := h.orderUsecase.ExportOrders(reqCtx, &exportReq, func(orders []dto.OrderResponse) error {
+		batch := make([]*orderpb.Order, 0, len(orders))
+		for i := range orders {
+			batch = append(batch, mapOrderToPB(&orders[i]))
+		}
+		// Send blocks while the gateway is behind, so a slow download never holds more than a few batches
+		return stream.Send(&orderpb.ExportOrdersResponse{Orders: batch})
+	})
+	if err != nil && reqCtx.Err() == nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (h *OrderGRPCHandler) Run(done <-chan any, port string, opts ...grpc.ServerOption) error {
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		logger.Errorf("Error while starting order grpc server: %v", err)
 		return err
 	}
 
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(grpcmiddleware.InternalAuthUnaryServerInterceptor(h.internalAuthToken)))
+	grpcServer := grpc.NewServer(opts...)
 	orderpb.RegisterOrderServiceServer(grpcServer, h)
+	shippingpb.RegisterShippingServiceServer(grpcServer, h.shipping)
 
 	go func() {
 		logger.Infof("Order gRPC server is running on port %s", port)
@@ -216,6 +620,26 @@ func (h *OrderGRPCHandler) Run(done <-chan any, port string) error {
 	return nil
 }
 
+// authorizeOrder loads orderID to check the caller owns it or holds permission. Service calls skip the lookup.
+func (h *OrderGRPCHandler) authorizeOrder(ctx context.Context, orderID uint, permission string) error {
+	if _, ok := grpcmiddleware.IdentityFromContext(ctx); !ok {
+		return nil
+	}
+	order, err := h.orderUsecase.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return grpcmiddleware.AuthorizeUser(ctx, order.UserID, permission)
+}
+
+// authorizePermission requires a forwarded user to hold permission; service calls are allowed
+func authorizePermission(ctx context.Context, permission string) error {
+	if identity, ok := grpcmiddleware.IdentityFromContext(ctx); ok && !identity.HasPermission(permission) {
+		return status.Error(grpccodes.PermissionDenied, "missing permission "+permission)
+	}
+	return nil
+}
+
 func mapOrderToPB(order *dto.OrderResponse) *orderpb.Order {
 	if order == nil {
 		return nil
@@ -233,6 +657,18 @@ func mapOrderToPB(order *dto.OrderResponse) *orderpb.Order {
 		})
 	}
 
+	var shippingAddress *orderpb.ShippingAddress
+	if order.ShippingAddress != nil {
+		shippingAddress = &orderpb.ShippingAddress{
+			AddressId:  int64(order.ShippingAddress.AddressID),
+			Street:     order.ShippingAddress.Street,
+			City:       order.ShippingAddress.City,
+			State:      order.ShippingAddress.State,
+			Country:    order.ShippingAddress.Country,
+			PostalCode: order.ShippingAddress.PostalCode,
+		}
+	}
+
 	return &orderpb.Order{
 		Id:                   int64(order.ID),
 		UserId:               int64(order.UserID),
@@ -242,6 +678,7 @@ func mapOrderToPB(order *dto.OrderResponse) *orderpb.Order {
 		Total:                order.Total,
 		Status:               order.Status,
 		Items:                items,
+		ShippingAddress:      shippingAddress,
 		CreatedAt:            formatTime(order.CreatedAt),
 		UpdatedAt:            formatTime(order.UpdatedAt),
 	}
@@ -253,3 +690,15 @@ func formatTime(t time.Time) string {
 	}
 	return t.UTC().Format(time.RFC3339)
 }
+
+func mapSalesPeriodToPB(period *dto.SalesPeriodResponse) *orderpb.SalesPeriod {
+	return &orderpb.SalesPeriod{
+		Start:             period.Start,
+		End:               period.End,
+		Revenue:           period.Revenue,
+		OrderCount:        int64(period.OrderCount),
+		AverageOrderValue: period.AverageOrderValue,
+		CanceledCount:     int64(period.CanceledCount),
+		CanceledValue:     period.CanceledValue,
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/usecase"
+	shippingpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/shipping"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const shippingDateLayout = "2006-01-02"
+
+// ShippingGRPCHandler serves ShippingService on the order service's gRPC server
+type ShippingGRPCHandler struct {
+	shippingpb.UnimplementedShippingServiceServer
+	usecase  domain.ShippingUsecase
+	validate *validator.Validate
+	tracer   trace.Tracer
+}
+
+var _ shippingpb.ShippingServiceServer = (*ShippingGRPCHandler)(nil)
+
+func NewShippingGRPCHandler(usecase domain.ShippingUsecase, validate *validator.Validate) *ShippingGRPCHandler {
+	return &ShippingGRPCHandler{
+		usecase:  usecase,
+		validate: validate,
+		tracer:   otel.Tracer("shipping_GRPC_handler"),
+	}
+}
+
+func (h *ShippingGRPCHandler) GetShippingQuote(ctx context.Context, req *shippingpb.GetShippingQuoteRequest) (*shippingpb.GetShippingQuoteResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "ShippingHandler.GetShippingQuote")
+	defer span.End()
+
+	if err := grpcmiddleware.AuthorizeUser(ctx, uint(req.GetUserId())); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	items := make([]dto.OrderItemInput, 0, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		items = append(items, dto.OrderItemInput{
+			ProductID: uint(item.GetProductId()),
+			Quantity:  int(item.GetQuantity()),
+		})
+	}
+
+	quoteReq := dto.ShippingQuoteRequest{
+		UserID:    uint(req.GetUserId()),
+		AddressID: uint(req.GetAddressId()),
+		Items:     items,
+	}
+
+	if err := h.validate.Struct(&quoteReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	options, err := h.usecase.QuoteShipping(ctx, &quoteReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, usecase.ErrAddressNotOwned) {
+			return nil, status.Error(grpccodes.PermissionDenied, err.Error())
+		}
+		return nil, err
+	}
+
+	response := &shippingpb.GetShippingQuoteResponse{Options: make([]*shippingpb.ShippingOption, 0, len(options))}
+	for _, option := range options {
+		response.Options = append(response.Options, &shippingpb.ShippingOption{
+			Id:                option.ID,
+			Name:              option.Name,
+			Cost:              option.Cost,
+			DurationDays:      int32(option.DurationDays),
+			EstimatedDelivery: option.EstimatedDelivery.Format(shippingDateLayout),
+		})
+	}
+	return response, nil
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Invoice is issued the first time an order's invoice is asked for and stored, so every later download
+// returns the same file. Numbers come from InvoiceCounter in the transaction that stores the invoice, so
+// they run without gaps.
+type Invoice struct {
+	Number   uint      `gorm:"primaryKey;autoIncrement:false"`
+	OrderID  uint      `gorm:"uniqueIndex;not null"`
+	IssuedAt time.Time `gorm:"not null"`
+	// PDF is cleared when the order is anonymised, and rendered again from the anonymised order
+	PDF []byte `gorm:"column:pdf"`
+}
+
+// DisplayNumber is the number as printed on the invoice, e.g. INV-000042
+func (i Invoice) DisplayNumber() string {
+	return fmt.Sprintf("INV-%06d", i.Number)
+}
+
+// InvoiceCounter is the single row holding the last invoice number issued
+type InvoiceCounter struct {
+	ID         uint `gorm:"primaryKey"`
+	LastNumber uint `gorm:"not null;default:0"`
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type OrderStatus string
 
@@ -12,15 +16,32 @@ const (
 	OrderStatusCanceled  OrderStatus = "canceled"
 )
 
+// IsFinal reports whether the order can no longer change status
+func (s OrderStatus) IsFinal() bool {
+	return s == OrderStatusDelivered || s == OrderStatusCanceled
+}
+
 type Order struct {
 	gorm.Model
-	UserID               uint        `json:"user_id"`
-	ShippingCost         float32     `json:"shipping_cost"`
-	ShippingDurationDays int         `json:"shipping_duration_days"`
-	Discount             float32     `json:"discount"`
-	Total                float32     `json:"total"`
-	Status               OrderStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
-	Items                []OrderItem `gorm:"foreignKey:OrderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID               uint            `json:"user_id"`
+	ShippingCost         float32         `json:"shipping_cost"`
+	ShippingDurationDays int             `json:"shipping_duration_days"`
+	Discount             float32         `json:"discount"`
+	Total                float32         `json:"total"`
+	Status               OrderStatus     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	ShippingAddress      ShippingAddress `gorm:"embedded;embeddedPrefix:shipping_" json:"shipping_address"`
+	Items                []OrderItem     `gorm:"foreignKey:OrderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// ShippingAddress is copied from the user's address when the order is placed,
+// so later edits or deletions of the address don't rewrite order history
+type ShippingAddress struct {
+	AddressID  *uint  `json:"address_id"`
+	Street     string `gorm:"type:varchar(100)" json:"street"`
+	City       string `gorm:"type:varchar(50)" json:"city"`
+	State      string `gorm:"type:varchar(50)" json:"state"`
+	Country    string `gorm:"type:varchar(50)" json:"country"`
+	PostalCode string `gorm:"type:varchar(20)" json:"postal_code"`
 }
 
 type OrderItem struct {
@@ -30,4 +51,62 @@ type OrderItem struct {
 	Quantity   int     `json:"quantity"`
 	UnitPrice  float32 `json:"unit_price"`
 	TotalPrice float32 `json:"total_price"`
-}
\ No newline at end of file
+}
+
+// OrderListFilter narrows ListOrders; zero values leave that dimension unfiltered
+type OrderListFilter struct {
+	UserID *uint
+	Status OrderStatus
+	// CreatedFrom is inclusive and CreatedTo exclusive
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	SortBy      OrderSortField
+	SortDesc    bool
+}
+
+type OrderSortField string
+
+const (
+	OrderSortByID        OrderSortField = "id"
+	OrderSortByCreatedAt OrderSortField = "created_at"
+	OrderSortByTotal     OrderSortField = "total"
+)
+
+type RevenueGranularity string
+
+const (
+	RevenueGranularityDay   RevenueGranularity = "day"
+	RevenueGranularityWeek  RevenueGranularity = "week"
+	RevenueGranularityMonth RevenueGranularity = "month"
+)
+
+// RevenuePeriod is the aggregated revenue of non-canceled orders created in one period
+type RevenuePeriod struct {
+	Start      time.Time
+	Revenue    float64
+	OrderCount int
+}
+
+// SoldStatuses are the statuses of orders that were paid for, which count as a purchase of their products
+var SoldStatuses = []OrderStatus{OrderStatusPaid, OrderStatusShipped, OrderStatusDelivered}
+
+// RevenueStatuses are the statuses of orders whose totals sales reports count as revenue. Shipped orders are
+// left out until they are delivered.
+var RevenueStatuses = []OrderStatus{OrderStatusPaid, OrderStatusDelivered}
+
+// SalesPeriod is the aggregated sales of orders created in one period: sold orders, and canceled ones apart
+type SalesPeriod struct {
+	Start         time.Time
+	Revenue       float64
+	OrderCount    int
+	CanceledValue float64
+	CanceledCount int
+}
+
+// ProductSales is what one product sold in sold orders
+type ProductSales struct {
+	ProductID  uint
+	Quantity   int
+	Revenue    float64
+	OrderCount int
+}
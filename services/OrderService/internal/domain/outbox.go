@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxEvent is an event persisted in the same transaction as the order change that produced it.
+// The outbox relay publishes pending rows after commit, so events are never lost on a crash.
+type OutboxEvent struct {
+	ID          uint       `gorm:"primaryKey"`
+	EventID     string     `gorm:"type:varchar(36);uniqueIndex;not null"`
+	EventType   string     `gorm:"type:varchar(100);not null"`
+	Version     int        `gorm:"not null"`
+	Payload     []byte     `gorm:"type:jsonb;not null"`
+	Attempts    int        `gorm:"not null;default:0"`
+	LastError   string     `gorm:"type:text"`
+	CreatedAt   time.Time  `gorm:"not null"`
+	PublishedAt *time.Time `gorm:"index"`
+	// DeadLetteredAt is set once the relay gives up on the event, which is then no longer pending
+	DeadLetteredAt *time.Time
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+type OutboxRepository interface {
+	// LockPending runs fn in a transaction holding up to limit pending events, oldest first, with a repository
+	// that works within it. Events another transaction holds are skipped rather than waited for, so relays on
+	// several replicas never publish the same event. The transaction commits when fn returns nil.
+	LockPending(ctx context.Context, limit int, fn func(repo OutboxRepository, pending []OutboxEvent) error) error
+	MarkPublished(ctx context.Context, id uint) error
+	MarkFailed(ctx context.Context, id uint, reason string) error
+	// MarkDeadLettered counts a last failed attempt and takes the event out of the pending ones for good
+	MarkDeadLettered(ctx context.Context, id uint, reason string) error
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ShippingRate prices one shipping option as BaseCost plus PerItemCost for every unit ordered
+type ShippingRate struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	BaseCost     float32 `json:"base_cost"`
+	PerItemCost  float32 `json:"per_item_cost"`
+	DurationDays int     `json:"duration_days"`
+	// Countries limits the option to these destination countries; empty means everywhere
+	Countries []string `json:"countries,omitempty"`
+}
+
+var DefaultShippingRates = []ShippingRate{
+	{ID: "standard", Name: "Standard", BaseCost: 5, PerItemCost: 0.5, DurationDays: 5},
+	{ID: "express", Name: "Express", BaseCost: 15, PerItemCost: 1, DurationDays: 2},
+}
+
+// ParseShippingRates reads a JSON array of ShippingRate, or returns DefaultShippingRates when value is empty
+func ParseShippingRates(value string) ([]ShippingRate, error) {
+	if value == "" {
+		return DefaultShippingRates, nil
+	}
+
+	var rates []ShippingRate
+	if err := json.Unmarshal([]byte(value), &rates); err != nil {
+		return nil, fmt.Errorf("shipping rates must be a JSON array of rates: %w", err)
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("at least one shipping rate is required")
+	}
+
+	seen := make(map[string]bool, len(rates))
+	for _, rate := range rates {
+		if rate.ID == "" || seen[rate.ID] {
+			return nil, fmt.Errorf("every shipping rate needs a unique id, got %q", rate.ID)
+		}
+		if rate.BaseCost < 0 || rate.PerItemCost < 0 || rate.DurationDays < 0 {
+			return nil, fmt.Errorf("shipping rate %q has a negative cost or duration", rate.ID)
+		}
+		seen[rate.ID] = true
+	}
+	return rates, nil
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseShippingRates(t *testing.T) {
+	rates, err := ParseShippingRates("")
+	if err != nil || !reflect.DeepEqual(rates, DefaultShippingRates) {
+		t.Errorf("empty value = %v, %v, want the default rates", rates, err)
+	}
+
+	rates, err = ParseShippingRates(`[{"id":"pickup","name":"Pickup","duration_days":1,"countries":["EG"]}]`)
+	if want := []ShippingRate{{ID: "pickup", Name: "Pickup", DurationDays: 1, Countries: []string{"EG"}}}; err != nil || !reflect.DeepEqual(rates, want) {
+		t.Errorf("rates = %v, %v, want %v", rates, err, want)
+	}
+
+	for name, value := range map[string]string{
+		"not JSON":          `standard`,
+		"no rates":          `[]`,
+		"missing id":        `[{"name":"Standard"}]`,
+		"duplicate id":      `[{"id":"standard"},{"id":"standard"}]`,
+		"negative cost":     `[{"id":"standard","base_cost":-1}]`,
+		"negative duration": `[{"id":"standard","duration_days":-1}]`,
+	} {
+		if _, err := ParseShippingRates(value); err == nil {
+			t.Errorf("%s: %s was accepted", name, value)
+		}
+	}
+}
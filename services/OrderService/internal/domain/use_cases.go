@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/delivery/grpc/dto"
 )
@@ -9,18 +10,50 @@ import (
 type OrderUsecase interface {
 	CreateOrder(ctx context.Context, req *dto.CreateOrderRequest) (*dto.OrderResponse, error)
 	GetOrderByID(ctx context.Context, id uint) (*dto.OrderResponse, error)
-	ListOrders(ctx context.Context, userID *uint, page, perPage int) ([]dto.OrderResponse, int, error)
+	ListOrders(ctx context.Context, req *dto.ListOrdersRequest) ([]dto.OrderResponse, int, error)
+	ExportOrders(ctx context.Context, req *dto.ExportOrdersRequest, send func([]dto.OrderResponse) error) error
 	AddOrderItem(ctx context.Context, req *dto.AddOrderItemRequest) (*dto.OrderResponse, error)
 	RemoveOrderItem(ctx context.Context, orderID, itemID uint) (*dto.OrderResponse, error)
+	UpdateOrderItemQuantity(ctx context.Context, req *dto.UpdateOrderItemQuantityRequest) (*dto.OrderResponse, error)
 	UpdateOrderStatus(ctx context.Context, orderID uint, status string) (*dto.OrderResponse, error)
+	GetRevenueSummary(ctx context.Context, req *dto.RevenueSummaryRequest) ([]dto.RevenuePeriodResponse, error)
+	GetSalesReport(ctx context.Context, req *dto.SalesReportRequest) (*dto.SalesReportResponse, error)
+	GetTopProducts(ctx context.Context, req *dto.TopProductsRequest) ([]dto.ProductSalesResponse, error)
+	AnonymiseUserOrders(ctx context.Context, userID uint) (int64, error)
+	WatchOrderStatus(ctx context.Context, orderID uint, send func(*dto.OrderResponse) error) error
+	HasPurchasedProduct(ctx context.Context, userID, productID uint) (bool, error)
+	GetOrderInvoice(ctx context.Context, orderID uint) (*dto.InvoiceResponse, error)
+}
+
+type ShippingUsecase interface {
+	QuoteShipping(ctx context.Context, req *dto.ShippingQuoteRequest) ([]dto.ShippingOptionResponse, error)
+	// ResolveOption prices optionID for an order of quantity units shipped to country
+	ResolveOption(optionID, country string, quantity int) (*dto.ShippingOptionResponse, error)
 }
 
 type OrderRepository interface {
 	CreateOrder(ctx context.Context, order *Order) error
 	GetOrderByID(ctx context.Context, id uint) (*Order, error)
-	ListOrders(ctx context.Context, userID *uint, page, perPage int) ([]Order, int, error)
+	ListOrders(ctx context.Context, filter OrderListFilter, page, perPage int) ([]Order, int, error)
+	// ListOrdersAfter returns up to limit orders matching filter with an id above afterID, in id order and
+	// without their items. Sorting in filter is ignored.
+	ListOrdersAfter(ctx context.Context, filter OrderListFilter, afterID uint, limit int) ([]Order, error)
 	AddOrderItem(ctx context.Context, item *OrderItem) error
 	RemoveOrderItem(ctx context.Context, orderID, itemID uint) error
-	UpdateOrderStatus(ctx context.Context, orderID uint, status OrderStatus) error
+	UpdateOrderItemQuantity(ctx context.Context, orderID, itemID uint, quantity int) error
+	// UpdateOrderStatus returns the status the order had before
+	UpdateOrderStatus(ctx context.Context, orderID uint, status OrderStatus) (OrderStatus, error)
 	UpdateOrderTotal(ctx context.Context, orderID uint, total float32) error
-}
\ No newline at end of file
+	GetRevenueByPeriod(ctx context.Context, start, end time.Time, granularity RevenueGranularity) ([]RevenuePeriod, error)
+	// GetSalesByPeriod aggregates the orders created from start up to end per period, only periods with orders
+	GetSalesByPeriod(ctx context.Context, start, end time.Time, granularity RevenueGranularity) ([]SalesPeriod, error)
+	// GetTopProducts returns the limit products with the highest item revenue in sold orders created from start
+	// up to end, ties broken by product id
+	GetTopProducts(ctx context.Context, start, end time.Time, limit int) ([]ProductSales, error)
+	AnonymiseUserOrders(ctx context.Context, userID uint) (int64, error)
+	HasPurchasedProduct(ctx context.Context, userID, productID uint) (bool, error)
+	GetInvoice(ctx context.Context, orderID uint) (*Invoice, error)
+	// IssueInvoice returns the invoice of orderID, issuing the next number and storing render's file when
+	// there is none
+	IssueInvoice(ctx context.Context, orderID uint, render func(Invoice) ([]byte, error)) (*Invoice, error)
+}
@@ -0,0 +1,158 @@
+// Package invoice renders order invoices as PDF files. It draws with the standard Helvetica fonts every PDF
+// reader ships, so it needs no font files, and renders the same document to the same bytes.
+package invoice
+
+import (
+	"fmt"
+	"time"
+)
+
+// Document is what an invoice shows
+type Document struct {
+	Number    string
+	IssuedAt  time.Time
+	OrderID   uint
+	OrderedAt time.Time
+	// ShipTo holds the lines of the order's shipping address
+	ShipTo   []string
+	Lines    []Line
+	Subtotal float32
+	Shipping float32
+	Discount float32
+	Total    float32
+}
+
+// Line is one order item
+type Line struct {
+	Description string
+	Quantity    int
+	UnitPrice   float32
+	Amount      float32
+}
+
+const (
+	margin     = 50
+	right      = pageWidth - margin
+	bodySize   = 10
+	rowHeight  = 18
+	footerY    = 36
+	dateLayout = "2006-01-02"
+
+	// Right edges of the quantity, unit price and amount columns; descriptions are cut to fit before quantity
+	quantityX  = 370
+	unitPriceX = 460
+	amountX    = right
+	itemWidth  = 260
+
+	// bottom is the lowest a row may sit, above the footer
+	bottom = 72
+	// totalsHeight is the room the subtotal, shipping, discount and total lines take
+	totalsHeight = 4*rowHeight + 12
+)
+
+// Render lays d out on as many A4 pages as its lines need, each numbered in the footer
+func Render(d Document) ([]byte, error) {
+	var pages []*page
+	var current *page
+	var y float64
+
+	newPage := func() {
+		current = &page{}
+		pages = append(pages, current)
+		if len(pages) == 1 {
+			y = d.header(current)
+			return
+		}
+		current.text(margin, pageHeight-60, fontBold, 12, fmt.Sprintf("Invoice %s (continued)", d.Number))
+		y = pageHeight - 90
+	}
+
+	newPage()
+	y = tableHeader(current, y)
+	for _, line := range d.Lines {
+		if y < bottom {
+			newPage()
+			y = tableHeader(current, y)
+		}
+		current.text(margin, y, fontRegular, bodySize, truncate(line.Description, bodySize, itemWidth))
+		current.textRight(quantityX, y, fontRegular, bodySize, fmt.Sprint(line.Quantity))
+		current.textRight(unitPriceX, y, fontRegular, bodySize, amount(line.UnitPrice))
+		current.textRight(amountX, y, fontRegular, bodySize, amount(line.Amount))
+		y -= rowHeight
+	}
+
+	if y-totalsHeight < bottom {
+		newPage()
+	}
+	d.totals(current, y)
+
+	for i, p := range pages {
+		p.text(margin, footerY, fontRegular, 8, d.Number)
+		p.textRight(right, footerY, fontRegular, 8, fmt.Sprintf("Page %d of %d", i+1, len(pages)))
+	}
+
+	return writePDF(pages, "Invoice "+d.Number, d.IssuedAt)
+}
+
+// header draws the title, invoice details and shipping address, returning where the item table starts
+func (d Document) header(p *page) float64 {
+	p.text(margin, pageHeight-70, fontBold, 22, "INVOICE")
+
+	details := [][2]string{
+		{"Invoice number", d.Number},
+		{"Issue date", d.IssuedAt.UTC().Format(dateLayout)},
+		{"Order", fmt.Sprintf("#%d", d.OrderID)},
+		{"Order date", d.OrderedAt.UTC().Format(dateLayout)},
+	}
+	y := float64(pageHeight - 70)
+	for _, detail := range details {
+		p.text(360, y, fontRegular, bodySize, detail[0])
+		p.textRight(right, y, fontBold, bodySize, detail[1])
+		y -= 15
+	}
+
+	y = pageHeight - 150
+	p.text(margin, y, fontBold, bodySize, "Ship to")
+	for _, line := range d.ShipTo {
+		y -= 14
+		p.text(margin, y, fontRegular, bodySize, truncate(line, bodySize, 300))
+	}
+	return min(y, pageHeight-150-4*14) - 40
+}
+
+// tableHeader draws the item table's column titles at y, returning where the first row goes
+func tableHeader(p *page, y float64) float64 {
+	p.text(margin, y, fontBold, bodySize, "Item")
+	p.textRight(quantityX, y, fontBold, bodySize, "Qty")
+	p.textRight(unitPriceX, y, fontBold, bodySize, "Unit price")
+	p.textRight(amountX, y, fontBold, bodySize, "Amount")
+	p.line(margin, y-6, right, y-6)
+	return y - 6 - rowHeight
+}
+
+// totals draws the subtotal, shipping, discount and total below the last row at y
+func (d Document) totals(p *page, y float64) {
+	p.line(unitPriceX-100, y+rowHeight-6, right, y+rowHeight-6)
+	y -= 6
+
+	discount := amount(d.Discount)
+	if d.Discount > 0 {
+		discount = "-" + discount
+	}
+	rows := [][2]string{
+		{"Subtotal", amount(d.Subtotal)},
+		{"Shipping", amount(d.Shipping)},
+		{"Discount", discount},
+	}
+	for _, row := range rows {
+		p.text(unitPriceX-100, y, fontRegular, bodySize, row[0])
+		p.textRight(amountX, y, fontRegular, bodySize, row[1])
+		y -= rowHeight
+	}
+	p.text(unitPriceX-100, y, fontBold, 12, "Total")
+	p.textRight(amountX, y, fontBold, 12, amount(d.Total))
+}
+
+func amount(v float32) string {
+	return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,124 @@
+package invoice
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// streamPattern finds the content streams writePDF emits, each right after its length
+var streamPattern = regexp.MustCompile(`(?s)<< /Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+
+// contents inflates the content stream of every page of pdf, in page order
+func contents(t *testing.T, pdf []byte) []string {
+	t.Helper()
+	var pages []string
+	for _, match := range streamPattern.FindAllSubmatchIndex(pdf, -1) {
+		var length int
+		fmt.Sscan(string(pdf[match[2]:match[3]]), &length)
+		zr, err := zlib.NewReader(bytes.NewReader(pdf[match[1] : match[1]+length]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, string(content))
+	}
+	return pages
+}
+
+func testDocument(lines int) Document {
+	d := Document{
+		Number:    "INV-000042",
+		IssuedAt:  time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+		OrderID:   7,
+		OrderedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		ShipTo:    []string{"1 Nile St", "Cairo, 11511", "Egypt"},
+		Subtotal:  20,
+		Shipping:  5,
+		Discount:  2.5,
+		Total:     22.5,
+	}
+	for i := range lines {
+		d.Lines = append(d.Lines, Line{Description: fmt.Sprintf("Lamp %d", i+1), Quantity: 2, UnitPrice: 5, Amount: 10})
+	}
+	return d
+}
+
+func TestRender(t *testing.T) {
+	pdf, err := Render(testDocument(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF file: %.40q", pdf)
+	}
+	again, _ := Render(testDocument(2))
+	if !bytes.Equal(pdf, again) {
+		t.Error("the same document rendered to different bytes")
+	}
+
+	pages := contents(t, pdf)
+	if len(pages) != 1 {
+		t.Fatalf("rendered %d pages, want 1", len(pages))
+	}
+	for _, want := range []string{"(INV-000042)", "(2026-03-02)", "(#7)", "(Cairo, 11511)", "(Lamp 2)", "(-2.50)", "(22.50)", "(Page 1 of 1)"} {
+		if !strings.Contains(pages[0], want) {
+			t.Errorf("the page does not show %s", want)
+		}
+	}
+}
+
+func TestRenderSpreadsLinesOverPages(t *testing.T) {
+	pdf, err := Render(testDocument(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := contents(t, pdf)
+	if len(pages) < 2 || !bytes.Contains(pdf, []byte(fmt.Sprintf("/Count %d ", len(pages)))) {
+		t.Fatalf("100 lines rendered on %d pages", len(pages))
+	}
+
+	shown := 0
+	for i, content := range pages {
+		if footer := fmt.Sprintf("(Page %d of %d)", i+1, len(pages)); !strings.Contains(content, footer) {
+			t.Errorf("page %d has no footer %s", i+1, footer)
+		}
+		if i > 0 && !strings.Contains(content, "(Invoice INV-000042 \\(continued\\))") {
+			t.Errorf("page %d has no continuation title", i+1)
+		}
+		shown += strings.Count(content, "(Lamp ")
+	}
+	if shown != 100 {
+		t.Errorf("the pages show %d lines, want 100", shown)
+	}
+	if !strings.Contains(pages[len(pages)-1], "(Total)") {
+		t.Error("the totals are not on the last page")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("Desk lamp ", 20)
+	short := truncate(long, bodySize, itemWidth)
+	if !strings.HasSuffix(short, "...") || textWidth(short, fontRegular, bodySize) > itemWidth {
+		t.Errorf("truncate = %q, %.1f wide; want it cut with an ellipsis to fit %d", short, textWidth(short, fontRegular, bodySize), itemWidth)
+	}
+	if got := truncate("Desk lamp", bodySize, itemWidth); got != "Desk lamp" {
+		t.Errorf("truncate = %q, want a fitting description kept whole", got)
+	}
+}
+
+func TestWriteString(t *testing.T) {
+	var buf bytes.Buffer
+	writeString(&buf, `Café (red) \ 5€ 灯`)
+	if want := "(Caf\xe9 \\(red\\) \\\\ 5\x80 ?)"; buf.String() != want {
+		t.Errorf("writeString = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+create table outbox_events (
+    id serial primary key,
+    event_id varchar(36) not null unique,
+    event_type varchar(100) not null,
+    version int not null,
+    payload jsonb not null,
+    attempts int not null default 0,
+    last_error text,
+    created_at timestamp with time zone not null default current_timestamp,
+    published_at timestamp with time zone
+);
+
+create index idx_outbox_events_pending on outbox_events (id) where published_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table outbox_events;
+-- +goose StatementEnd
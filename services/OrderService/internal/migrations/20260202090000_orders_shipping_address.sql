@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table orders
+    add column shipping_address_id int,
+    add column shipping_street varchar(100),
+    add column shipping_city varchar(50),
+    add column shipping_state varchar(50),
+    add column shipping_country varchar(50),
+    add column shipping_postal_code varchar(20);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table orders
+    drop column shipping_address_id,
+    drop column shipping_street,
+    drop column shipping_city,
+    drop column shipping_state,
+    drop column shipping_country,
+    drop column shipping_postal_code;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+create table invoice_counters (
+    id int primary key,
+    last_number int not null default 0
+);
+
+insert into invoice_counters (id, last_number) values (1, 0);
+
+create table invoices (
+    number int primary key,
+    order_id int not null unique references orders (id),
+    issued_at timestamp with time zone not null,
+    pdf bytea
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table invoices;
+drop table invoice_counters;
+-- +goose StatementEnd
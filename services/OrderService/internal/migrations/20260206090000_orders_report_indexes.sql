@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+create index orders_created_at_idx on orders (created_at);
+create index order_items_order_id_idx on order_items (order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index order_items_order_id_idx;
+drop index orders_created_at_idx;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table outbox_events add column dead_lettered_at timestamp with time zone;
+
+drop index idx_outbox_events_pending;
+create index idx_outbox_events_pending on outbox_events (id) where published_at is null and dead_lettered_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index idx_outbox_events_pending;
+create index idx_outbox_events_pending on outbox_events (id) where published_at is null;
+
+alter table outbox_events drop column dead_lettered_at;
+-- +goose StatementEnd
@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/events"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+)
+
+// Relay polls the outbox table and publishes committed events in insertion order.
+// An event is marked published only after the broker confirms it, so a crash between
+// publish and mark results in a duplicate rather than a lost event. Relays on several
+// replicas share the table through row locks, so each event is published by one of them.
+// An event that fails maxAttempts times is dead-lettered, so it cannot hold back the events after it.
+type Relay struct {
+	repo        domain.OutboxRepository
+	publisher   events.Publisher
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+}
+
+func NewRelay(repo domain.OutboxRepository, publisher events.Publisher, interval time.Duration, batchSize, maxAttempts int) *Relay {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+
+	return &Relay{
+		repo:        repo,
+		publisher:   publisher,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Run publishes pending events until done is closed
+func (r *Relay) Run(done <-chan any) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	logger.Infof("event=outbox_relay_start interval=%s batch_size=%d max_attempts=%d", r.interval, r.batchSize, r.maxAttempts)
+	for {
+		select {
+		case <-done:
+			logger.Info("event=outbox_relay_stop")
+			return
+		case <-ticker.C:
+			r.flush(context.Background())
+		}
+	}
+}
+
+// flush publishes a batch within the transaction that holds it. A failed publish stops the batch to preserve
+// ordering, and still commits, so the events published before it stay marked and the failure is counted; the
+// rest is retried on the next tick. The failure that uses up an event's last attempt dead-letters it instead,
+// and the batch goes on without it.
+func (r *Relay) flush(ctx context.Context) {
+	err := r.repo.LockPending(ctx, r.batchSize, func(repo domain.OutboxRepository, pending []domain.OutboxEvent) error {
+		for _, row := range pending {
+			event := events.Event{
+				ID:         row.EventID,
+				Type:       row.EventType,
+				Version:    row.Version,
+				Source:     "order-service",
+				OccurredAt: row.CreatedAt,
+				Payload:    row.Payload,
+			}
+
+			publishCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			err := r.publisher.Publish(publishCtx, event)
+			cancel()
+			if err != nil && row.Attempts+1 >= r.maxAttempts {
+				logger.Errorf("event=outbox_dead_lettered id=%s type=%s attempts=%d error=%v", row.EventID, row.EventType, row.Attempts+1, err)
+				if markErr := repo.MarkDeadLettered(ctx, row.ID, err.Error()); markErr != nil {
+					logger.Errorf("event=outbox_mark_dead_lettered_error id=%s error=%v", row.EventID, markErr)
+					return nil
+				}
+				continue
+			}
+			if err != nil {
+				logger.Warnf("event=outbox_publish_failed id=%s type=%s error=%v", row.EventID, row.EventType, err)
+				if markErr := repo.MarkFailed(ctx, row.ID, err.Error()); markErr != nil {
+					logger.Errorf("event=outbox_mark_failed_error id=%s error=%v", row.EventID, markErr)
+				}
+				return nil
+			}
+
+			// Rolling back leaves the whole batch pending, so its events are published again
+			if err := repo.MarkPublished(ctx, row.ID); err != nil {
+				logger.Errorf("event=outbox_mark_published_failed id=%s error=%v", row.EventID, err)
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Errorf("event=outbox_flush_failed error=%v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/events"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+)
+
+// fakeOutbox keeps the outbox in memory; LockPending restores it when fn fails, as a rollback would
+type fakeOutbox struct {
+	events []domain.OutboxEvent
+}
+
+func (f *fakeOutbox) LockPending(_ context.Context, limit int, fn func(domain.OutboxRepository, []domain.OutboxEvent) error) error {
+	var pending []domain.OutboxEvent
+	for _, event := range f.events {
+		if event.PublishedAt == nil && event.DeadLetteredAt == nil && len(pending) < limit {
+			pending = append(pending, event)
+		}
+	}
+
+	saved := slices.Clone(f.events)
+	if err := fn(f, pending); err != nil {
+		f.events = saved
+		return err
+	}
+	return nil
+}
+
+func (f *fakeOutbox) MarkPublished(_ context.Context, id uint) error {
+	now := time.Now()
+	f.events[id-1].PublishedAt = &now
+	return nil
+}
+
+func (f *fakeOutbox) MarkFailed(_ context.Context, id uint, reason string) error {
+	f.events[id-1].Attempts++
+	f.events[id-1].LastError = reason
+	return nil
+}
+
+func (f *fakeOutbox) MarkDeadLettered(_ context.Context, id uint, reason string) error {
+	now := time.Now()
+	f.events[id-1].Attempts++
+	f.events[id-1].LastError = reason
+	f.events[id-1].DeadLetteredAt = &now
+	return nil
+}
+
+// newFakeOutbox holds a pending event for each of eventIDs, with IDs from 1
+func newFakeOutbox(eventIDs ...string) *fakeOutbox {
+	f := &fakeOutbox{}
+	for i, eventID := range eventIDs {
+		f.events = append(f.events, domain.OutboxEvent{ID: uint(i + 1), EventID: eventID, EventType: "order.created", Version: 1, Payload: []byte(`{}`)})
+	}
+	return f
+}
+
+// flakyPublisher refuses the first failures events it is given, then publishes to the bus
+type flakyPublisher struct {
+	*events.MemoryBus
+	failures int
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, event events.Event) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker unreachable")
+	}
+	return p.MemoryBus.Publish(ctx, event)
+}
+
+func publishedIDs(bus *events.MemoryBus) []string {
+	var ids []string
+	for _, event := range bus.Published() {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestFlushPublishesThenMarksInOrder(t *testing.T) {
+	repo := newFakeOutbox("e1", "e2", "e3")
+	bus := events.NewMemoryBus()
+	relay := NewRelay(repo, bus, time.Second, 2, 3)
+
+	relay.flush(context.Background())
+	if got := publishedIDs(bus); !reflect.DeepEqual(got, []string{"e1", "e2"}) {
+		t.Fatalf("published %v, want the first batch of 2", got)
+	}
+	relay.flush(context.Background())
+	if got := publishedIDs(bus); !reflect.DeepEqual(got, []string{"e1", "e2", "e3"}) {
+		t.Fatalf("published %v, want every event in order", got)
+	}
+
+	for _, event := range repo.events {
+		if event.PublishedAt == nil {
+			t.Errorf("event %s is still pending", event.EventID)
+		}
+		if published := bus.Published()[event.ID-1]; published.Type != event.EventType || published.Source != "order-service" {
+			t.Errorf("published %+v for event %s", published, event.EventID)
+		}
+	}
+}
+
+func TestFlushLeavesAFailedEventPending(t *testing.T) {
+	repo := newFakeOutbox("e1", "e2")
+	bus := events.NewMemoryBus()
+	relay := NewRelay(repo, &flakyPublisher{MemoryBus: bus, failures: 1}, time.Second, 10, 3)
+
+	relay.flush(context.Background())
+	if got := publishedIDs(bus); len(got) != 0 {
+		t.Fatalf("published %v, want nothing after the first event failed", got)
+	}
+	first, second := repo.events[0], repo.events[1]
+	if first.PublishedAt != nil || first.Attempts != 1 || first.LastError != "broker unreachable" {
+		t.Errorf("failed event = %+v, want it pending with the failure counted", first)
+	}
+	// The batch stops at the failure so that e2 is not published ahead of e1
+	if second.PublishedAt != nil || second.Attempts != 0 {
+		t.Errorf("next event = %+v, want it untouched", second)
+	}
+}
+
+func TestFlushRetriesAFailedEvent(t *testing.T) {
+	repo := newFakeOutbox("e1", "e2")
+	bus := events.NewMemoryBus()
+	relay := NewRelay(repo, &flakyPublisher{MemoryBus: bus, failures: 1}, time.Second, 10, 3)
+
+	relay.flush(context.Background())
+	relay.flush(context.Background())
+
+	if got := publishedIDs(bus); !reflect.DeepEqual(got, []string{"e1", "e2"}) {
+		t.Fatalf("published %v, want both events in order on the retry", got)
+	}
+	if first := repo.events[0]; first.PublishedAt == nil || first.Attempts != 1 {
+		t.Errorf("retried event = %+v, want it published after 1 failed attempt", first)
+	}
+}
+
+// poisonPublisher refuses every event with the ID poison and publishes the others to the bus
+type poisonPublisher struct {
+	*events.MemoryBus
+	poison string
+}
+
+func (p *poisonPublisher) Publish(ctx context.Context, event events.Event) error {
+	if event.ID == p.poison {
+		return errors.New("message rejected")
+	}
+	return p.MemoryBus.Publish(ctx, event)
+}
+
+func TestFlushDeadLettersAnEventThatKeepsFailing(t *testing.T) {
+	repo := newFakeOutbox("e1", "e2", "e3")
+	bus := events.NewMemoryBus()
+	relay := NewRelay(repo, &poisonPublisher{MemoryBus: bus, poison: "e1"}, time.Second, 10, 3)
+
+	// The first two failures hold the later events back to keep them in order
+	for range 2 {
+		relay.flush(context.Background())
+		if got := publishedIDs(bus); len(got) != 0 {
+			t.Fatalf("published %v while e1 had attempts left, want nothing", got)
+		}
+	}
+
+	relay.flush(context.Background())
+	if got := publishedIDs(bus); !reflect.DeepEqual(got, []string{"e2", "e3"}) {
+		t.Fatalf("published %v, want the events after the dead-lettered one", got)
+	}
+	poison := repo.events[0]
+	if poison.DeadLetteredAt == nil || poison.PublishedAt != nil || poison.Attempts != 3 || poison.LastError != "message rejected" {
+		t.Errorf("poison event = %+v, want it dead-lettered after 3 attempts", poison)
+	}
+
+	// A dead-lettered event is not tried again
+	relay.flush(context.Background())
+	if poison := repo.events[0]; poison.Attempts != 3 || len(publishedIDs(bus)) != 2 {
+		t.Errorf("the dead-lettered event was tried again: %+v", poison)
+	}
+}
@@ -5,6 +5,9 @@ import "errors"
 var (
 	ErrOrderNotFound       = errors.New("order not found")
 	ErrOrderItemNotFound   = errors.New("order item not found")
+	ErrOrderNotPending     = errors.New("order is no longer pending")
+	ErrOrderCanceled       = errors.New("order is canceled")
+	ErrInvoiceNotFound     = errors.New("invoice not found")
 	ErrDatabaseConnection  = errors.New("database connection error")
 	ErrDatabaseQuery       = errors.New("database query failed")
 	ErrForeignKeyViolation = errors.New("related record not found")
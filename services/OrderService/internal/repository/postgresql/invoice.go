@@ -0,0 +1,121 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"gorm.io/gorm"
+)
+
+// invoiceCounterID is the id of the one invoice_counters row
+const invoiceCounterID = 1
+
+func (r *OrderRepository) GetInvoice(ctx context.Context, orderID uint) (*domain.Invoice, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetInvoice")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("order.id", int(orderID)))
+
+	var invoice domain.Invoice
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Take(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			span.SetStatus(codes.Error, repository.ErrInvoiceNotFound.Error())
+			return nil, repository.ErrInvoiceNotFound
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, mapPostgresError(err)
+	}
+
+	span.SetStatus(codes.Ok, "invoice retrieved")
+	return &invoice, nil
+}
+
+// IssueInvoice returns the invoice of orderID, issuing it with the next number when the order has none and
+// storing the file render draws for it. An invoice whose file was cleared is drawn again under its old
+// number. render runs while the counter is locked, so it should only draw.
+func (r *OrderRepository) IssueInvoice(ctx context.Context, orderID uint, render func(domain.Invoice) ([]byte, error)) (*domain.Invoice, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.IssueInvoice")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("order.id", int(orderID)))
+
+	var invoice domain.Invoice
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The no-op upsert locks the counter row until commit, creating it if need be. Concurrent issues
+		// queue behind it, so each sees the invoices committed before it, and a rolled back issue hands its
+		// number back instead of leaving a gap.
+		var lastNumber uint
+		if err := tx.Raw(
+			"INSERT INTO invoice_counters (id, last_number) VALUES (?, 0) ON CONFLICT (id) DO UPDATE SET last_number = invoice_counters.last_number RETURNING last_number",
+			invoiceCounterID,
+		).Scan(&lastNumber).Error; err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return mapPostgresError(err)
+		}
+
+		err := tx.Where("order_id = ?", orderID).Take(&invoice).Error
+		switch {
+		case err == nil && invoice.PDF != nil:
+			span.SetStatus(codes.Ok, "invoice already issued")
+			return nil
+		case err == nil:
+			pdf, err := render(invoice)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return err
+			}
+			invoice.PDF = pdf
+			if err := tx.Model(&domain.Invoice{}).Where("number = ?", invoice.Number).Update("pdf", pdf).Error; err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return mapPostgresError(err)
+			}
+			span.SetStatus(codes.Ok, "invoice rendered again")
+			return nil
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return mapPostgresError(err)
+		}
+
+		invoice = domain.Invoice{
+			Number:   lastNumber + 1,
+			OrderID:  orderID,
+			IssuedAt: time.Now().UTC().Truncate(time.Second),
+		}
+		pdf, err := render(invoice)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+		invoice.PDF = pdf
+
+		if err := tx.Create(&invoice).Error; err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return mapPostgresError(err)
+		}
+		if err := tx.Model(&domain.InvoiceCounter{}).Where("id = ?", invoiceCounterID).Update("last_number", invoice.Number).Error; err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return mapPostgresError(err)
+		}
+
+		span.SetAttributes(attribute.Int("invoice.number", int(invoice.Number)))
+		span.SetStatus(codes.Ok, "invoice issued")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
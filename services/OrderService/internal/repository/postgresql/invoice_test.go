@@ -0,0 +1,85 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/repository"
+)
+
+// renderNumber draws an invoice as its display number, counting the draws
+func renderNumber(renders *int) func(domain.Invoice) ([]byte, error) {
+	return func(issue domain.Invoice) ([]byte, error) {
+		*renders++
+		return []byte(issue.DisplayNumber()), nil
+	}
+}
+
+func TestIssueInvoiceNumbersWithoutGaps(t *testing.T) {
+	db := newTestDB(t, &domain.Invoice{}, &domain.InvoiceCounter{})
+	repo := NewOrderRepository(db)
+	ctx := context.Background()
+
+	if _, err := repo.GetInvoice(ctx, 10); !errors.Is(err, repository.ErrInvoiceNotFound) {
+		t.Fatalf("GetInvoice before any issue = %v, want %v", err, repository.ErrInvoiceNotFound)
+	}
+
+	var renders int
+	first, err := repo.IssueInvoice(ctx, 10, renderNumber(&renders))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A failed render rolls back, handing its number to the next invoice
+	failed := errors.New("render failed")
+	if _, err := repo.IssueInvoice(ctx, 11, func(domain.Invoice) ([]byte, error) { return nil, failed }); !errors.Is(err, failed) {
+		t.Fatalf("IssueInvoice with a failing render = %v, want %v", err, failed)
+	}
+	second, err := repo.IssueInvoice(ctx, 12, renderNumber(&renders))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Number != 1 || second.Number != 2 || string(second.PDF) != "INV-000002" {
+		t.Fatalf("issued %d and %d (%s), want 1 and 2", first.Number, second.Number, second.PDF)
+	}
+
+	// Asking again returns the stored invoice without drawing it
+	again, err := repo.IssueInvoice(ctx, 10, renderNumber(&renders))
+	if err != nil || again.Number != 1 || renders != 2 {
+		t.Errorf("issued order 10 again as %+v, %v after %d draws; want invoice 1 drawn once", again, err, renders)
+	}
+	stored, err := repo.GetInvoice(ctx, 12)
+	if err != nil || stored.Number != 2 || string(stored.PDF) != "INV-000002" {
+		t.Errorf("GetInvoice = %+v, %v, want invoice 2", stored, err)
+	}
+}
+
+func TestIssueInvoiceRedrawsAClearedFile(t *testing.T) {
+	db := newTestDB(t, &domain.Invoice{}, &domain.InvoiceCounter{})
+	repo := NewOrderRepository(db)
+	ctx := context.Background()
+
+	var renders int
+	for orderID := uint(1); orderID <= 3; orderID++ {
+		if _, err := repo.IssueInvoice(ctx, orderID, renderNumber(&renders)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// As anonymisation leaves it
+	if err := db.Model(&domain.Invoice{}).Where("order_id = ?", 2).Update("pdf", nil).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	redrawn, err := repo.IssueInvoice(ctx, 2, func(issue domain.Invoice) ([]byte, error) {
+		return []byte(fmt.Sprintf("%s redrawn", issue.DisplayNumber())), nil
+	})
+	if err != nil || redrawn.Number != 2 || string(redrawn.PDF) != "INV-000002 redrawn" {
+		t.Fatalf("redrew %+v, %v; want invoice 2 under its old number", redrawn, err)
+	}
+	next, err := repo.IssueInvoice(ctx, 4, renderNumber(&renders))
+	if err != nil || next.Number != 4 {
+		t.Errorf("the next invoice is %+v, %v; want number 4", next, err)
+	}
+}
@@ -3,7 +3,10 @@ package postgresql
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/kareemhamed001/e-commerce/pkg/events"
 	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
 	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/repository"
 	"go.opentelemetry.io/otel"
@@ -11,6 +14,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepository struct {
@@ -47,6 +51,12 @@ func (r *OrderRepository) CreateOrder(ctx context.Context, order *domain.Order)
 			}
 		}
 
+		if err := enqueueEvent(ctx, tx, events.OrderCreated, orderCreatedPayload(order)); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return mapPostgresError(err)
+		}
+
 		span.SetAttributes(attribute.Int("order.id", int(order.ID)))
 		span.SetStatus(codes.Ok, "order created")
 		return nil
@@ -74,14 +84,11 @@ func (r *OrderRepository) GetOrderByID(ctx context.Context, id uint) (*domain.Or
 	return &order, nil
 }
 
-func (r *OrderRepository) ListOrders(ctx context.Context, userID *uint, page, perPage int) ([]domain.Order, int, error) {
+func (r *OrderRepository) ListOrders(ctx context.Context, filter domain.OrderListFilter, page, perPage int) ([]domain.Order, int, error) {
 	ctx, span := r.tracer.Start(ctx, "OrderRepository.ListOrders")
 	defer span.End()
 
-	query := r.db.WithContext(ctx).Model(&domain.Order{})
-	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
-	}
+	query := filterOrders(r.db.WithContext(ctx).Model(&domain.Order{}), filter)
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -91,7 +98,7 @@ func (r *OrderRepository) ListOrders(ctx context.Context, userID *uint, page, pe
 	}
 
 	var orders []domain.Order
-	if err := query.Preload("Items").Offset((page - 1) * perPage).Limit(perPage).Order("id desc").Find(&orders).Error; err != nil {
+	if err := query.Preload("Items").Offset((page - 1) * perPage).Limit(perPage).Order(listOrdersOrder(filter)).Find(&orders).Error; err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, 0, mapPostgresError(err)
@@ -102,6 +109,60 @@ func (r *OrderRepository) ListOrders(ctx context.Context, userID *uint, page, pe
 	return orders, int(total), nil
 }
 
+// ListOrdersAfter pages by id rather than offset, so an export stays cheap deep into the table and orders
+// created while it runs neither shift nor repeat rows
+func (r *OrderRepository) ListOrdersAfter(ctx context.Context, filter domain.OrderListFilter, afterID uint, limit int) ([]domain.Order, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ListOrdersAfter")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("order.after_id", int(afterID)))
+
+	var orders []domain.Order
+	query := filterOrders(r.db.WithContext(ctx).Model(&domain.Order{}), filter)
+	if err := query.Where("id > ?", afterID).Order("id asc").Limit(limit).Find(&orders).Error; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, mapPostgresError(err)
+	}
+
+	span.SetAttributes(attribute.Int("orders.count", len(orders)))
+	span.SetStatus(codes.Ok, "orders listed")
+	return orders, nil
+}
+
+// filterOrders narrows query to the orders filter matches
+func filterOrders(query *gorm.DB, filter domain.OrderListFilter) *gorm.DB {
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	return query
+}
+
+// listOrdersOrder builds the ORDER BY clause from a whitelisted column, with id as the tie-breaker
+// so pages stay stable when many orders share a created_at or total
+func listOrdersOrder(filter domain.OrderListFilter) string {
+	direction := "asc"
+	if filter.SortDesc {
+		direction = "desc"
+	}
+
+	switch filter.SortBy {
+	case domain.OrderSortByCreatedAt, domain.OrderSortByTotal:
+		return fmt.Sprintf("%s %s, id %s", filter.SortBy, direction, direction)
+	default:
+		return "id " + direction
+	}
+}
+
 func (r *OrderRepository) AddOrderItem(ctx context.Context, item *domain.OrderItem) error {
 	ctx, span := r.tracer.Start(ctx, "OrderRepository.AddOrderItem")
 	defer span.End()
@@ -136,23 +197,112 @@ func (r *OrderRepository) RemoveOrderItem(ctx context.Context, orderID, itemID u
 	return nil
 }
 
-func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID uint, status domain.OrderStatus) error {
+// UpdateOrderItemQuantity locks the order so a concurrent status change cannot slip in between
+// the pending check and the item update
+func (r *OrderRepository) UpdateOrderItemQuantity(ctx context.Context, orderID, itemID uint, quantity int) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.UpdateOrderItemQuantity")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order domain.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").First(&order, orderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				span.SetStatus(codes.Error, repository.ErrOrderNotFound.Error())
+				return repository.ErrOrderNotFound
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return mapPostgresError(err)
+		}
+		if order.Status != domain.OrderStatusPending {
+			span.SetStatus(codes.Error, repository.ErrOrderNotPending.Error())
+			return repository.ErrOrderNotPending
+		}
+
+		result := tx.Model(&domain.OrderItem{}).
+			Where("id = ? AND order_id = ?", itemID, orderID).
+			Updates(map[string]any{
+				"quantity":    quantity,
+				"total_price": gorm.Expr("unit_price * ?", quantity),
+			})
+		if result.Error != nil {
+			span.RecordError(result.Error)
+			span.SetStatus(codes.Error, result.Error.Error())
+			return mapPostgresError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			span.SetStatus(codes.Error, repository.ErrOrderItemNotFound.Error())
+			return repository.ErrOrderItemNotFound
+		}
+
+		span.SetStatus(codes.Ok, "order item quantity updated")
+		return nil
+	})
+}
+
+// UpdateOrderStatus returns the status the order had before. A canceled order's stock has been put back, so it
+// cannot move to another status.
+func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID uint, status domain.OrderStatus) (domain.OrderStatus, error) {
 	ctx, span := r.tracer.Start(ctx, "OrderRepository.UpdateOrderStatus")
 	defer span.End()
 
-	result := r.db.WithContext(ctx).Model(&domain.Order{}).Where("id = ?", orderID).Update("status", status)
-	if result.Error != nil {
-		span.RecordError(result.Error)
-		span.SetStatus(codes.Error, result.Error.Error())
-		return mapPostgresError(result.Error)
-	}
-	if result.RowsAffected == 0 {
-		span.SetStatus(codes.Error, repository.ErrOrderNotFound.Error())
-		return repository.ErrOrderNotFound
-	}
+	var order domain.Order
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				span.SetStatus(codes.Error, repository.ErrOrderNotFound.Error())
+				return repository.ErrOrderNotFound
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return mapPostgresError(err)
+		}
+		if order.Status == domain.OrderStatusCanceled && status != domain.OrderStatusCanceled {
+			span.SetStatus(codes.Error, repository.ErrOrderCanceled.Error())
+			return repository.ErrOrderCanceled
+		}
 
-	span.SetStatus(codes.Ok, "order status updated")
-	return nil
+		if err := tx.Model(&domain.Order{}).Where("id = ?", orderID).Update("status", status).Error; err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return mapPostgresError(err)
+		}
+
+		if order.Status == status {
+			span.SetStatus(codes.Ok, "order status unchanged")
+			return nil
+		}
+
+		now := time.Now().UTC()
+		if err := enqueueEvent(ctx, tx, events.OrderStatusChanged, events.OrderStatusChangedV1{
+			OrderID:   order.ID,
+			UserID:    order.UserID,
+			OldStatus: string(order.Status),
+			NewStatus: string(status),
+			ChangedAt: now,
+		}); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return mapPostgresError(err)
+		}
+
+		if status == domain.OrderStatusCanceled {
+			if err := enqueueEvent(ctx, tx, events.OrderCancelled, events.OrderCancelledV1{
+				OrderID:     order.ID,
+				UserID:      order.UserID,
+				Total:       order.Total,
+				CancelledAt: now,
+			}); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return mapPostgresError(err)
+			}
+		}
+
+		span.SetStatus(codes.Ok, "order status updated")
+		return nil
+	})
+	return order.Status, err
 }
 
 func (r *OrderRepository) UpdateOrderTotal(ctx context.Context, orderID uint, total float32) error {
@@ -173,3 +323,112 @@ func (r *OrderRepository) UpdateOrderTotal(ctx context.Context, orderID uint, to
 	span.SetStatus(codes.Ok, "order total updated")
 	return nil
 }
+
+func (r *OrderRepository) GetRevenueByPeriod(ctx context.Context, start, end time.Time, granularity domain.RevenueGranularity) ([]domain.RevenuePeriod, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetRevenueByPeriod")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("revenue.granularity", string(granularity)))
+
+	var rows []struct {
+		PeriodStart time.Time
+		Revenue     float64
+		OrderCount  int
+	}
+	if err := r.db.WithContext(ctx).Model(&domain.Order{}).
+		Select("date_trunc(?, created_at AT TIME ZONE 'UTC') AS period_start, COALESCE(SUM(total), 0) AS revenue, COUNT(*) AS order_count", string(granularity)).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Where("status <> ?", domain.OrderStatusCanceled).
+		Group("period_start").
+		Order("period_start asc").
+		Scan(&rows).Error; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, mapPostgresError(err)
+	}
+
+	periods := make([]domain.RevenuePeriod, 0, len(rows))
+	for _, row := range rows {
+		periods = append(periods, domain.RevenuePeriod{
+			Start:      time.Date(row.PeriodStart.Year(), row.PeriodStart.Month(), row.PeriodStart.Day(), 0, 0, 0, 0, time.UTC),
+			Revenue:    row.Revenue,
+			OrderCount: row.OrderCount,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("revenue.periods", len(periods)))
+	span.SetStatus(codes.Ok, "revenue aggregated")
+	return periods, nil
+}
+
+// AnonymiseUserOrders detaches every order of userID, including soft-deleted ones, and clears the
+// shipping address down to the country so revenue reporting keeps working. Stored invoice files print the
+// address too, so they are dropped, keeping their numbers, to be drawn again from the anonymised order.
+func (r *OrderRepository) AnonymiseUserOrders(ctx context.Context, userID uint) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.AnonymiseUserOrders")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("user.id", int(userID)))
+
+	var anonymised int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userOrders := tx.Unscoped().Model(&domain.Order{}).Select("id").Where("user_id = ?", userID)
+		if err := tx.Model(&domain.Invoice{}).Where("order_id IN (?)", userOrders).Update("pdf", nil).Error; err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return mapPostgresError(err)
+		}
+
+		result := tx.Unscoped().Model(&domain.Order{}).
+			Where("user_id = ?", userID).
+			Updates(map[string]any{
+				"user_id":              0,
+				"shipping_address_id":  nil,
+				"shipping_street":      "",
+				"shipping_city":        "",
+				"shipping_state":       "",
+				"shipping_postal_code": "",
+			})
+		if result.Error != nil {
+			span.RecordError(result.Error)
+			span.SetStatus(codes.Error, result.Error.Error())
+			return mapPostgresError(result.Error)
+		}
+		anonymised = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	span.SetAttributes(attribute.Int64("orders.anonymised", anonymised))
+	span.SetStatus(codes.Ok, "orders anonymised")
+	return anonymised, nil
+}
+
+// HasPurchasedProduct reports whether any paid, shipped or delivered order of userID contains productID
+func (r *OrderRepository) HasPurchasedProduct(ctx context.Context, userID, productID uint) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.HasPurchasedProduct")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("user.id", int(userID)),
+		attribute.Int("product.id", int(productID)),
+	)
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&domain.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("orders.user_id = ? AND order_items.product_id = ?", userID, productID).
+		Where("orders.status IN ?", domain.SoldStatuses).
+		Limit(1).
+		Count(&count).Error; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, mapPostgresError(err)
+	}
+
+	span.SetAttributes(attribute.Bool("product.purchased", count > 0))
+	span.SetStatus(codes.Ok, "purchase checked")
+	return count > 0, nil
+}
@@ -0,0 +1,167 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/repository"
+)
+
+func TestListOrdersFiltersAndSorts(t *testing.T) {
+	db := newTestDB(t, &domain.Order{}, &domain.OrderItem{})
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, order := range []struct {
+		userID    uint
+		status    domain.OrderStatus
+		total     float32
+		createdAt time.Time
+	}{
+		{userID: 7, status: domain.OrderStatusPaid, total: 30, createdAt: jan},
+		{userID: 8, status: domain.OrderStatusPaid, total: 10, createdAt: jan.AddDate(0, 0, 1)},
+		{userID: 7, status: domain.OrderStatusShipped, total: 20, createdAt: jan.AddDate(0, 0, 2)},
+		{userID: 8, status: domain.OrderStatusPaid, total: 40, createdAt: jan.AddDate(0, 1, 0)},
+	} {
+		row := domain.Order{UserID: order.userID, Status: order.status, Total: order.total}
+		row.CreatedAt = order.createdAt
+		if err := db.Create(&row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	user := func(id uint) *uint { return &id }
+	feb := jan.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name      string
+		filter    domain.OrderListFilter
+		wantIDs   []uint
+		wantTotal int
+	}{
+		{name: "everyone", filter: domain.OrderListFilter{}, wantIDs: []uint{1, 2, 3, 4}, wantTotal: 4},
+		{name: "newest first", filter: domain.OrderListFilter{SortDesc: true}, wantIDs: []uint{4, 3, 2, 1}, wantTotal: 4},
+		{name: "one user", filter: domain.OrderListFilter{UserID: user(7)}, wantIDs: []uint{1, 3}, wantTotal: 2},
+		{name: "status", filter: domain.OrderListFilter{Status: domain.OrderStatusPaid}, wantIDs: []uint{1, 2, 4}, wantTotal: 3},
+		{name: "created in January", filter: domain.OrderListFilter{CreatedFrom: &jan, CreatedTo: &feb}, wantIDs: []uint{1, 2, 3}, wantTotal: 3},
+		{
+			name:      "by total",
+			filter:    domain.OrderListFilter{Status: domain.OrderStatusPaid, SortBy: domain.OrderSortByTotal, SortDesc: true},
+			wantIDs:   []uint{4, 1, 2},
+			wantTotal: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders, total, err := NewOrderRepository(db).ListOrders(context.Background(), tt.filter, 1, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []uint
+			for _, order := range orders {
+				ids = append(ids, order.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || total != tt.wantTotal {
+				t.Errorf("got orders %v of %d, want %v of %d", ids, total, tt.wantIDs, tt.wantTotal)
+			}
+		})
+	}
+
+	t.Run("second page", func(t *testing.T) {
+		orders, total, err := NewOrderRepository(db).ListOrders(context.Background(), domain.OrderListFilter{SortBy: domain.OrderSortByCreatedAt}, 2, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(orders) != 1 || orders[0].ID != 4 || total != 4 {
+			t.Errorf("got %d orders of %d, want order 4 of 4", len(orders), total)
+		}
+	})
+}
+
+func TestUpdateOrderItemQuantity(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       domain.OrderStatus
+		orderID      uint
+		itemID       uint
+		wantErr      error
+		wantQuantity int
+	}{
+		{name: "pending order", status: domain.OrderStatusPending, orderID: 1, itemID: 1, wantQuantity: 3},
+		{name: "paid order", status: domain.OrderStatusPaid, orderID: 1, itemID: 1, wantErr: repository.ErrOrderNotPending, wantQuantity: 1},
+		{name: "item of another order", status: domain.OrderStatusPending, orderID: 1, itemID: 2, wantErr: repository.ErrOrderItemNotFound, wantQuantity: 1},
+		{name: "unknown order", status: domain.OrderStatusPending, orderID: 9, itemID: 1, wantErr: repository.ErrOrderNotFound, wantQuantity: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &domain.Order{}, &domain.OrderItem{})
+			for _, order := range []domain.Order{
+				{UserID: 7, Status: tt.status, Items: []domain.OrderItem{{ProductID: 3, Quantity: 1, UnitPrice: 10, TotalPrice: 10}}},
+				{UserID: 8, Status: domain.OrderStatusPending, Items: []domain.OrderItem{{ProductID: 3, Quantity: 1, UnitPrice: 10, TotalPrice: 10}}},
+			} {
+				if err := db.Create(&order).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := NewOrderRepository(db).UpdateOrderItemQuantity(context.Background(), tt.orderID, tt.itemID, 3)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateOrderItemQuantity = %v, want %v", err, tt.wantErr)
+			}
+			var item domain.OrderItem
+			if err := db.First(&item, 1).Error; err != nil {
+				t.Fatal(err)
+			}
+			if item.Quantity != tt.wantQuantity || item.TotalPrice != 10*float32(tt.wantQuantity) {
+				t.Errorf("item 1 holds %d for %v, want %d", item.Quantity, item.TotalPrice, tt.wantQuantity)
+			}
+		})
+	}
+}
+
+func TestUpdateOrderStatusKeepsCanceledOrdersCanceled(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       domain.OrderStatus
+		to         domain.OrderStatus
+		wantErr    error
+		wantStatus domain.OrderStatus
+		wantEvents int
+	}{
+		{name: "cancel a pending order", from: domain.OrderStatusPending, to: domain.OrderStatusCanceled, wantStatus: domain.OrderStatusCanceled, wantEvents: 2},
+		{name: "cancel twice", from: domain.OrderStatusCanceled, to: domain.OrderStatusCanceled, wantStatus: domain.OrderStatusCanceled},
+		{name: "reopen a canceled order", from: domain.OrderStatusCanceled, to: domain.OrderStatusPending, wantErr: repository.ErrOrderCanceled, wantStatus: domain.OrderStatusCanceled},
+		{name: "ship a paid order", from: domain.OrderStatusPaid, to: domain.OrderStatusShipped, wantStatus: domain.OrderStatusShipped, wantEvents: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &domain.Order{}, &domain.OutboxEvent{})
+			if err := db.Create(&domain.Order{UserID: 7, Status: tt.from}).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			previous, err := NewOrderRepository(db).UpdateOrderStatus(context.Background(), 1, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateOrderStatus = %v, want %v", err, tt.wantErr)
+			}
+			if previous != tt.from {
+				t.Errorf("previous status = %s, want %s", previous, tt.from)
+			}
+			var order domain.Order
+			if err := db.First(&order, 1).Error; err != nil {
+				t.Fatal(err)
+			}
+			if order.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", order.Status, tt.wantStatus)
+			}
+			var events int64
+			if err := db.Model(&domain.OutboxEvent{}).Count(&events).Error; err != nil {
+				t.Fatal(err)
+			}
+			if int(events) != tt.wantEvents {
+				t.Errorf("%d events were queued, want %d", events, tt.wantEvents)
+			}
+		})
+	}
+}
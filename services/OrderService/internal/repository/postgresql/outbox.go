@@ -0,0 +1,157 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/events"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const eventSource = "order-service"
+
+type OutboxRepository struct {
+	db     *gorm.DB
+	tracer trace.Tracer
+}
+
+var _ domain.OutboxRepository = (*OutboxRepository)(nil)
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db, tracer: otel.Tracer("outbox-repo")}
+}
+
+// LockPending selects the pending events FOR UPDATE SKIP LOCKED. While another relay holds events older than
+// the ones it got, fn gets none, so that a replica never publishes an event ahead of an older one.
+func (r *OutboxRepository) LockPending(ctx context.Context, limit int, fn func(repo domain.OutboxRepository, pending []domain.OutboxEvent) error) error {
+	ctx, span := r.tracer.Start(ctx, "OutboxRepository.LockPending")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var pending []domain.OutboxEvent
+		if err := lockPendingQuery(tx, limit).Find(&pending).Error; err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return mapPostgresError(err)
+		}
+
+		if len(pending) > 0 {
+			var older int64
+			if err := tx.Model(&domain.OutboxEvent{}).
+				Where("published_at IS NULL AND dead_lettered_at IS NULL AND id < ?", pending[0].ID).
+				Count(&older).Error; err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return mapPostgresError(err)
+			}
+			if older > 0 {
+				pending = nil
+			}
+		}
+
+		span.SetAttributes(attribute.Int("outbox.pending", len(pending)))
+		return fn(&OutboxRepository{db: tx, tracer: r.tracer}, pending)
+	})
+}
+
+// lockPendingQuery selects up to limit pending events, oldest first, locking them and skipping locked ones
+func lockPendingQuery(tx *gorm.DB, limit int) *gorm.DB {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("published_at IS NULL AND dead_lettered_at IS NULL").
+		Order("id asc").
+		Limit(limit)
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id uint) error {
+	ctx, span := r.tracer.Start(ctx, "OutboxRepository.MarkPublished")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Model(&domain.OutboxEvent{}).
+		Where("id = ?", id).
+		Update("published_at", time.Now().UTC()).Error; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return mapPostgresError(err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uint, reason string) error {
+	ctx, span := r.tracer.Start(ctx, "OutboxRepository.MarkFailed")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Model(&domain.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": reason,
+		}).Error; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return mapPostgresError(err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) MarkDeadLettered(ctx context.Context, id uint, reason string) error {
+	ctx, span := r.tracer.Start(ctx, "OutboxRepository.MarkDeadLettered")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Model(&domain.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":         gorm.Expr("attempts + 1"),
+			"last_error":       reason,
+			"dead_lettered_at": time.Now().UTC(),
+		}).Error; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return mapPostgresError(err)
+	}
+	return nil
+}
+
+// enqueueEvent writes an event to the outbox using tx, so it commits or rolls back with the order change
+func enqueueEvent(ctx context.Context, tx *gorm.DB, eventType string, payload interface{}) error {
+	event, err := events.NewEvent(eventType, events.OrderEventsVersion, eventSource, payload)
+	if err != nil {
+		return err
+	}
+
+	return tx.WithContext(ctx).Create(&domain.OutboxEvent{
+		EventID:   event.ID,
+		EventType: event.Type,
+		Version:   event.Version,
+		Payload:   event.Payload,
+		CreatedAt: event.OccurredAt,
+	}).Error
+}
+
+func orderCreatedPayload(order *domain.Order) events.OrderCreatedV1 {
+	items := make([]events.OrderItemV1, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, events.OrderItemV1{
+			ProductID:  item.ProductID,
+			Quantity:   item.Quantity,
+			UnitPrice:  item.UnitPrice,
+			TotalPrice: item.TotalPrice,
+		})
+	}
+
+	return events.OrderCreatedV1{
+		OrderID:      order.ID,
+		UserID:       order.UserID,
+		Status:       string(order.Status),
+		ShippingCost: order.ShippingCost,
+		Discount:     order.Discount,
+		Total:        order.Total,
+		Items:        items,
+		CreatedAt:    order.CreatedAt,
+	}
+}
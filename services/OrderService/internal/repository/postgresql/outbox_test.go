@@ -0,0 +1,134 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+)
+
+func TestLockPendingSkipsRowsOtherRelaysHold(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+
+	var pending []domain.OutboxEvent
+	_ = lockPendingQuery(db.WithContext(context.Background()), 10).Find(&pending)
+	if len(recorder.statements) != 1 {
+		t.Fatalf("ran %d statements, want 1", len(recorder.statements))
+	}
+	want := `SELECT * FROM "outbox_events" WHERE published_at IS NULL AND dead_lettered_at IS NULL ORDER BY id asc LIMIT 10 FOR UPDATE SKIP LOCKED`
+	if sql := recorder.statements[0]; sql != want {
+		t.Errorf("pending events are selected with\n%s\nwant\n%s", sql, want)
+	}
+}
+
+func TestLockPendingCommitsTheMarks(t *testing.T) {
+	db := newTestDB(t, &domain.OutboxEvent{})
+	repo := NewOutboxRepository(db)
+	for _, id := range []string{"e1", "e2", "e3"} {
+		if err := db.Create(&domain.OutboxEvent{EventID: id, EventType: "order.created", Version: 1, Payload: []byte(`{}`), CreatedAt: time.Now()}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+
+	// The first event is published and the second fails, which stops the batch
+	err := repo.LockPending(ctx, 10, func(tx domain.OutboxRepository, pending []domain.OutboxEvent) error {
+		if len(pending) != 3 {
+			t.Fatalf("got %d pending events, want 3", len(pending))
+		}
+		if err := tx.MarkPublished(ctx, pending[0].ID); err != nil {
+			return err
+		}
+		return tx.MarkFailed(ctx, pending[1].ID, "broker unreachable")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var left []string
+	err = repo.LockPending(ctx, 10, func(_ domain.OutboxRepository, pending []domain.OutboxEvent) error {
+		for _, event := range pending {
+			left = append(left, fmt.Sprintf("%s:%d:%s", event.EventID, event.Attempts, event.LastError))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"e2:1:broker unreachable", "e3:0:"}; !reflect.DeepEqual(left, want) {
+		t.Errorf("pending = %v, want %v", left, want)
+	}
+}
+
+func TestLockPendingRollsBackWhenFnFails(t *testing.T) {
+	db := newTestDB(t, &domain.OutboxEvent{})
+	repo := NewOutboxRepository(db)
+	if err := db.Create(&domain.OutboxEvent{EventID: "e1", EventType: "order.created", Version: 1, Payload: []byte(`{}`), CreatedAt: time.Now()}).Error; err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	failed := errors.New("mark failed")
+	err := repo.LockPending(ctx, 10, func(tx domain.OutboxRepository, pending []domain.OutboxEvent) error {
+		if err := tx.MarkPublished(ctx, pending[0].ID); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("err = %v, want %v", err, failed)
+	}
+
+	var event domain.OutboxEvent
+	if err := db.First(&event).Error; err != nil {
+		t.Fatal(err)
+	}
+	if event.PublishedAt != nil {
+		t.Error("the event stayed marked published after the rollback")
+	}
+}
+
+func TestLockPendingSkipsDeadLetteredEvents(t *testing.T) {
+	db := newTestDB(t, &domain.OutboxEvent{})
+	repo := NewOutboxRepository(db)
+	for _, id := range []string{"e1", "e2"} {
+		if err := db.Create(&domain.OutboxEvent{EventID: id, EventType: "order.created", Version: 1, Payload: []byte(`{}`), CreatedAt: time.Now()}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+
+	err := repo.LockPending(ctx, 10, func(tx domain.OutboxRepository, pending []domain.OutboxEvent) error {
+		return tx.MarkDeadLettered(ctx, pending[0].ID, "message rejected")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A dead-lettered event neither comes back nor counts as an older event holding the rest back
+	var left []string
+	err = repo.LockPending(ctx, 10, func(_ domain.OutboxRepository, pending []domain.OutboxEvent) error {
+		for _, event := range pending {
+			left = append(left, event.EventID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"e2"}; !reflect.DeepEqual(left, want) {
+		t.Errorf("pending = %v, want %v", left, want)
+	}
+
+	var dead domain.OutboxEvent
+	if err := db.Where("event_id = ?", "e1").First(&dead).Error; err != nil {
+		t.Fatal(err)
+	}
+	if dead.DeadLetteredAt == nil || dead.Attempts != 1 || dead.LastError != "message rejected" {
+		t.Errorf("dead-lettered event = %+v, want it marked with the failure counted", dead)
+	}
+}
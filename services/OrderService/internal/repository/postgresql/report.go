@@ -0,0 +1,99 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// GetSalesByPeriod sums revenue and canceled orders in one pass; orders in any other status are left out
+func (r *OrderRepository) GetSalesByPeriod(ctx context.Context, start, end time.Time, granularity domain.RevenueGranularity) ([]domain.SalesPeriod, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetSalesByPeriod")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("sales.granularity", string(granularity)))
+
+	var rows []struct {
+		PeriodStart   time.Time
+		Revenue       float64
+		OrderCount    int
+		CanceledValue float64
+		CanceledCount int
+	}
+	if err := r.db.WithContext(ctx).Model(&domain.Order{}).
+		Select(`date_trunc(?, created_at AT TIME ZONE 'UTC') AS period_start,
+			COALESCE(SUM(total) FILTER (WHERE status IN ?), 0) AS revenue,
+			COUNT(*) FILTER (WHERE status IN ?) AS order_count,
+			COALESCE(SUM(total) FILTER (WHERE status = ?), 0) AS canceled_value,
+			COUNT(*) FILTER (WHERE status = ?) AS canceled_count`,
+			string(granularity), domain.RevenueStatuses, domain.RevenueStatuses, domain.OrderStatusCanceled, domain.OrderStatusCanceled).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Where("status IN ? OR status = ?", domain.RevenueStatuses, domain.OrderStatusCanceled).
+		Group("period_start").
+		Order("period_start asc").
+		Scan(&rows).Error; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, mapPostgresError(err)
+	}
+
+	periods := make([]domain.SalesPeriod, 0, len(rows))
+	for _, row := range rows {
+		periods = append(periods, domain.SalesPeriod{
+			Start:         time.Date(row.PeriodStart.Year(), row.PeriodStart.Month(), row.PeriodStart.Day(), 0, 0, 0, 0, time.UTC),
+			Revenue:       row.Revenue,
+			OrderCount:    row.OrderCount,
+			CanceledValue: row.CanceledValue,
+			CanceledCount: row.CanceledCount,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("sales.periods", len(periods)))
+	span.SetStatus(codes.Ok, "sales aggregated")
+	return periods, nil
+}
+
+// GetTopProducts ranks products by the total price of their items, before the order's shipping and discount
+func (r *OrderRepository) GetTopProducts(ctx context.Context, start, end time.Time, limit int) ([]domain.ProductSales, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetTopProducts")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("products.limit", limit))
+
+	var rows []struct {
+		ProductID  uint
+		Quantity   int
+		Revenue    float64
+		OrderCount int
+	}
+	if err := r.db.WithContext(ctx).Model(&domain.OrderItem{}).
+		Select("order_items.product_id, SUM(order_items.quantity) AS quantity, SUM(order_items.total_price) AS revenue, COUNT(DISTINCT order_items.order_id) AS order_count").
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("orders.created_at >= ? AND orders.created_at < ?", start, end).
+		Where("orders.status IN ?", domain.RevenueStatuses).
+		Group("order_items.product_id").
+		Order("revenue desc, order_items.product_id asc").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, mapPostgresError(err)
+	}
+
+	products := make([]domain.ProductSales, 0, len(rows))
+	for _, row := range rows {
+		products = append(products, domain.ProductSales{
+			ProductID:  row.ProductID,
+			Quantity:   row.Quantity,
+			Revenue:    row.Revenue,
+			OrderCount: row.OrderCount,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("products.count", len(products)))
+	span.SetStatus(codes.Ok, "top products ranked")
+	return products, nil
+}
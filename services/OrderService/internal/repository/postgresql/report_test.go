@@ -0,0 +1,53 @@
+package postgresql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+)
+
+func TestGetSalesByPeriodCountsRevenueOfPaidAndDeliveredOrders(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// A dry run has no rows to scan, so the call fails once the statement is built
+	_, _ = NewOrderRepository(db).GetSalesByPeriod(context.Background(), start, start.AddDate(0, 1, 0), domain.RevenueGranularityWeek)
+	if len(recorder.statements) != 1 {
+		t.Fatalf("ran %d statements, want 1", len(recorder.statements))
+	}
+	sql := recorder.statements[0]
+	if want := `SUM(total) FILTER (WHERE status IN ('paid','delivered'))`; !strings.Contains(sql, want) {
+		t.Errorf("revenue is not limited to paid and delivered orders: %s", sql)
+	}
+	if strings.Contains(sql, "'shipped'") {
+		t.Errorf("shipped orders are counted: %s", sql)
+	}
+}
+
+func TestGetTopProductsCountsPaidAndDeliveredOrders(t *testing.T) {
+	db := newTestDB(t, &domain.Order{}, &domain.OrderItem{})
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, status := range []domain.OrderStatus{
+		domain.OrderStatusPending, domain.OrderStatusPaid, domain.OrderStatusShipped, domain.OrderStatusDelivered, domain.OrderStatusCanceled,
+	} {
+		order := domain.Order{
+			Status: status,
+			Items:  []domain.OrderItem{{ProductID: 7, Quantity: 1, UnitPrice: 10, TotalPrice: 10}},
+		}
+		order.CreatedAt = start.Add(time.Hour)
+		if err := db.Create(&order).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	products, err := NewOrderRepository(db).GetTopProducts(context.Background(), start, start.AddDate(0, 0, 1), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 1 || products[0].OrderCount != 2 || products[0].Quantity != 2 || products[0].Revenue != 20 {
+		t.Errorf("products = %+v, want product 7 sold twice for 20 by the paid and delivered orders", products)
+	}
+}
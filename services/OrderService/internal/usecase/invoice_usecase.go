@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/invoice"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/repository"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// ErrInvoiceUnavailable is returned for an order without an invoice whose items and totals may still change
+// or that will never be charged
+var ErrInvoiceUnavailable = errors.New("invoices are only issued for paid, shipped or delivered orders")
+
+// productBatchSize is the most ids GetProductsByIDs accepts at once
+const productBatchSize = 100
+
+// GetOrderInvoice returns the order's invoice, issuing it on the first request. Later requests return the
+// stored file, even once the order is canceled, until anonymisation drops it.
+func (u *OrderUsecase) GetOrderInvoice(ctx context.Context, orderID uint) (*dto.InvoiceResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "OrderUsecase.GetOrderInvoice")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("order.id", int(orderID)))
+
+	stored, err := u.orderRepo.GetInvoice(ctx, orderID)
+	if err == nil && stored.PDF != nil {
+		span.SetStatus(codes.Ok, "invoice fetched")
+		return mapInvoiceToResponse(stored), nil
+	}
+	if err != nil && !errors.Is(err, repository.ErrInvoiceNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	order, err := u.orderRepo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if stored == nil && !invoiceable(order.Status) {
+		span.SetStatus(codes.Error, ErrInvoiceUnavailable.Error())
+		return nil, ErrInvoiceUnavailable
+	}
+
+	productIDs := make([]uint, 0, len(order.Items))
+	for _, item := range order.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	names, err := u.productNames(ctx, productIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	issued, err := u.orderRepo.IssueInvoice(ctx, orderID, func(issue domain.Invoice) ([]byte, error) {
+		return invoice.Render(invoiceDocument(issue, order, names))
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("invoice.number", issued.DisplayNumber()))
+	span.SetStatus(codes.Ok, "invoice issued")
+	return mapInvoiceToResponse(issued), nil
+}
+
+// invoiceable reports whether an order in status is charged and can no longer change
+func invoiceable(status domain.OrderStatus) bool {
+	return status == domain.OrderStatusPaid || status == domain.OrderStatusShipped || status == domain.OrderStatusDelivered
+}
+
+// productNames looks up the names of productIDs, leaving out products deleted since. Invoices treat a failed
+// lookup as an error: the invoice is stored, so a placeholder name would stay on it for good.
+func (u *OrderUsecase) productNames(ctx context.Context, productIDs []uint) (map[uint]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, downstreamTimeout)
+	defer cancel()
+
+	ids := make([]int64, 0, len(productIDs))
+	for _, id := range productIDs {
+		ids = append(ids, int64(id))
+	}
+
+	names := make(map[uint]string, len(ids))
+	for start := 0; start < len(ids); start += productBatchSize {
+		end := min(start+productBatchSize, len(ids))
+		response, err := u.productClient.GetProductsByIDs(ctx, &productpb.GetProductsByIDsRequest{
+			Ids:    ids[start:end],
+			Fields: &fieldmaskpb.FieldMask{Paths: []string{"id", "name"}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("product names: %w", err)
+		}
+		for _, product := range response.GetProducts() {
+			names[uint(product.GetId())] = product.GetName()
+		}
+	}
+	return names, nil
+}
+
+func invoiceDocument(issue domain.Invoice, order *domain.Order, names map[uint]string) invoice.Document {
+	lines := make([]invoice.Line, 0, len(order.Items))
+	for _, item := range order.Items {
+		description, ok := names[item.ProductID]
+		if !ok || description == "" {
+			description = fmt.Sprintf("Product #%d", item.ProductID)
+		}
+		lines = append(lines, invoice.Line{
+			Description: description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.TotalPrice,
+		})
+	}
+
+	address := order.ShippingAddress
+	shipTo := nonEmpty(
+		address.Street,
+		strings.Join(nonEmpty(address.City, strings.TrimSpace(address.State+" "+address.PostalCode)), ", "),
+		address.Country,
+	)
+	if len(shipTo) == 0 {
+		shipTo = []string{"No address on record"}
+	}
+
+	return invoice.Document{
+		Number:    issue.DisplayNumber(),
+		IssuedAt:  issue.IssuedAt,
+		OrderID:   order.ID,
+		OrderedAt: order.CreatedAt,
+		ShipTo:    shipTo,
+		Lines:     lines,
+		Subtotal:  sumItemsTotal(order.Items),
+		Shipping:  order.ShippingCost,
+		Discount:  order.Discount,
+		Total:     order.Total,
+	}
+}
+
+func nonEmpty(values ...string) []string {
+	kept := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
+
+func mapInvoiceToResponse(issued *domain.Invoice) *dto.InvoiceResponse {
+	return &dto.InvoiceResponse{
+		Number:   issued.DisplayNumber(),
+		OrderID:  issued.OrderID,
+		IssuedAt: issued.IssuedAt,
+		PDF:      issued.PDF,
+	}
+}
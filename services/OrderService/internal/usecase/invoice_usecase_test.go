@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/invoice"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/repository"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// invoiceBook adds the invoices of fakeOrders, numbered in the order they are issued
+type invoiceBook struct {
+	*fakeOrders
+	invoices map[uint]*domain.Invoice
+}
+
+func (b *invoiceBook) GetInvoice(_ context.Context, orderID uint) (*domain.Invoice, error) {
+	issued, ok := b.invoices[orderID]
+	if !ok {
+		return nil, repository.ErrInvoiceNotFound
+	}
+	return issued, nil
+}
+
+func (b *invoiceBook) IssueInvoice(_ context.Context, orderID uint, render func(domain.Invoice) ([]byte, error)) (*domain.Invoice, error) {
+	issued, ok := b.invoices[orderID]
+	if !ok {
+		issued = &domain.Invoice{Number: uint(len(b.invoices) + 1), OrderID: orderID, IssuedAt: time.Now()}
+	}
+	pdf, err := render(*issued)
+	if err != nil {
+		return nil, err
+	}
+	issued.PDF = pdf
+	b.invoices[orderID] = issued
+	return issued, nil
+}
+
+// namedCatalog names the products it holds, or fails every lookup when err is set
+type namedCatalog struct {
+	productpb.ProductServiceClient
+	names map[int64]string
+	err   error
+}
+
+func (c namedCatalog) GetProductsByIDs(_ context.Context, in *productpb.GetProductsByIDsRequest, _ ...grpc.CallOption) (*productpb.GetProductsByIDsResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	var products []*productpb.Product
+	for _, id := range in.GetIds() {
+		if name, ok := c.names[id]; ok {
+			products = append(products, &productpb.Product{Id: int32(id), Name: name})
+		}
+	}
+	return &productpb.GetProductsByIDsResponse{Products: products}, nil
+}
+
+func TestGetOrderInvoice(t *testing.T) {
+	book := &invoiceBook{fakeOrders: newFakeOrders(), invoices: map[uint]*domain.Invoice{}}
+	place := func(orderStatus domain.OrderStatus) uint {
+		order := &domain.Order{UserID: 1, Status: orderStatus, Items: []domain.OrderItem{{ProductID: 1, Quantity: 1, UnitPrice: 5, TotalPrice: 5}}}
+		if err := book.CreateOrder(context.Background(), order); err != nil {
+			t.Fatal(err)
+		}
+		return order.ID
+	}
+	pending, paid, canceled := place(domain.OrderStatusPending), place(domain.OrderStatusPaid), place(domain.OrderStatusCanceled)
+	catalog := namedCatalog{names: map[int64]string{1: "Lamp"}}
+	u := NewOrderUsecase(book, catalog, fakeUsers{}, nil)
+	ctx := context.Background()
+
+	for _, orderID := range []uint{pending, canceled} {
+		if _, err := u.GetOrderInvoice(ctx, orderID); !errors.Is(err, ErrInvoiceUnavailable) {
+			t.Errorf("invoice of order %d = %v, want %v", orderID, err, ErrInvoiceUnavailable)
+		}
+	}
+
+	issued, err := u.GetOrderInvoice(ctx, paid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issued.Number != "INV-000001" || issued.OrderID != paid || len(issued.PDF) == 0 {
+		t.Fatalf("issued %s for order %d with %d bytes, want INV-000001 for order %d", issued.Number, issued.OrderID, len(issued.PDF), paid)
+	}
+
+	// Once issued the invoice stays available, canceled or not, without looking the products up again
+	book.orders[paid].Status = domain.OrderStatusCanceled
+	u = NewOrderUsecase(book, namedCatalog{err: status.Error(grpccodes.Unavailable, "down")}, fakeUsers{}, nil)
+	again, err := u.GetOrderInvoice(ctx, paid)
+	if err != nil || again.Number != issued.Number {
+		t.Errorf("invoice of the canceled order = %+v, %v, want %s", again, err, issued.Number)
+	}
+}
+
+func TestGetOrderInvoiceNeedsTheProductNames(t *testing.T) {
+	book := &invoiceBook{fakeOrders: newFakeOrders(), invoices: map[uint]*domain.Invoice{}}
+	order := &domain.Order{UserID: 1, Status: domain.OrderStatusPaid, Items: []domain.OrderItem{{ProductID: 1, Quantity: 1}}}
+	if err := book.CreateOrder(context.Background(), order); err != nil {
+		t.Fatal(err)
+	}
+	u := NewOrderUsecase(book, namedCatalog{err: status.Error(grpccodes.Unavailable, "down")}, fakeUsers{}, nil)
+
+	if _, err := u.GetOrderInvoice(context.Background(), order.ID); status.Code(errors.Unwrap(err)) != grpccodes.Unavailable {
+		t.Fatalf("GetOrderInvoice = %v, want the product lookup's error", err)
+	}
+	if len(book.invoices) != 0 {
+		t.Errorf("issued %+v without the product names", book.invoices)
+	}
+}
+
+func TestInvoiceDocument(t *testing.T) {
+	order := &domain.Order{
+		ShippingCost: 5,
+		Discount:     2,
+		Total:        23,
+		ShippingAddress: domain.ShippingAddress{
+			Street: "1 Nile St", City: "Cairo", PostalCode: "11511", Country: "Egypt",
+		},
+		Items: []domain.OrderItem{
+			{ProductID: 1, Quantity: 2, UnitPrice: 5, TotalPrice: 10},
+			{ProductID: 2, Quantity: 1, UnitPrice: 10, TotalPrice: 10},
+		},
+	}
+	order.ID = 7
+
+	d := invoiceDocument(domain.Invoice{Number: 42}, order, map[uint]string{1: "Lamp"})
+	if d.Number != "INV-000042" || d.OrderID != 7 || d.Subtotal != 20 || d.Total != 23 {
+		t.Errorf("document %+v, want INV-000042 for order 7 totalling 20 + 5 - 2", d)
+	}
+	if want := []string{"1 Nile St", "Cairo, 11511", "Egypt"}; !reflect.DeepEqual(d.ShipTo, want) {
+		t.Errorf("ShipTo = %q, want %q", d.ShipTo, want)
+	}
+	// A product deleted since the order keeps a line under its id
+	want := []invoice.Line{
+		{Description: "Lamp", Quantity: 2, UnitPrice: 5, Amount: 10},
+		{Description: "Product #2", Quantity: 1, UnitPrice: 10, Amount: 10},
+	}
+	if !reflect.DeepEqual(d.Lines, want) {
+		t.Errorf("Lines = %+v, want %+v", d.Lines, want)
+	}
+
+	order.ShippingAddress = domain.ShippingAddress{}
+	if d := invoiceDocument(domain.Invoice{}, order, nil); !reflect.DeepEqual(d.ShipTo, []string{"No address on record"}) {
+		t.Errorf("ShipTo without an address = %q", d.ShipTo)
+	}
+}
@@ -2,37 +2,57 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/delivery/grpc/dto"
 	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/repository"
 	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
 	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrAddressNotOwned   = errors.New("address does not belong to user")
+	ErrInsufficientStock = errors.New("insufficient stock")
 )
 
 const (
 	downstreamTimeout = 3 * time.Second
+	dateLayout        = "2006-01-02"
+	// exportBatchSize is how many orders ExportOrders reads and sends at a time
+	exportBatchSize = 500
+	// statusPollInterval is how often WatchOrderStatus checks for a change. Status updates can
+	// come from any replica, so polling the database is the one source every replica sees.
+	statusPollInterval = 2 * time.Second
+	// stockReasonOrder is the product service's reason for stock an order takes or puts back
+	stockReasonOrder = "order"
 )
 
 type OrderUsecase struct {
 	orderRepo     domain.OrderRepository
 	productClient productpb.ProductServiceClient
 	userClient    userpb.UserServiceClient
+	shipping      domain.ShippingUsecase
 	tracer        trace.Tracer
 }
 
 var _ domain.OrderUsecase = (*OrderUsecase)(nil)
 
-func NewOrderUsecase(orderRepo domain.OrderRepository, productClient productpb.ProductServiceClient, userClient userpb.UserServiceClient) *OrderUsecase {
+func NewOrderUsecase(orderRepo domain.OrderRepository, productClient productpb.ProductServiceClient, userClient userpb.UserServiceClient, shipping domain.ShippingUsecase) *OrderUsecase {
 	return &OrderUsecase{
 		orderRepo:     orderRepo,
 		productClient: productClient,
 		userClient:    userClient,
+		shipping:      shipping,
 		tracer:        otel.Tracer("order-usecase"),
 	}
 }
@@ -49,6 +69,25 @@ func (u *OrderUsecase) CreateOrder(ctx context.Context, req *dto.CreateOrderRequ
 		return nil, err
 	}
 
+	shippingAddress, err := snapshotAddress(ctx, u.userClient, req.UserID, req.AddressID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if req.ShippingOptionID != "" {
+		option, err := u.shipping.ResolveOption(req.ShippingOptionID, shippingAddress.Country, totalQuantity(req.Items))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		req.ShippingCost = option.Cost
+		req.ShippingDurationDays = option.DurationDays
+		span.SetAttributes(attribute.String("order.shipping_option_id", option.ID))
+	}
+
 	items := make([]domain.OrderItem, 0, len(req.Items))
 	var itemsTotal float32
 
@@ -81,6 +120,7 @@ func (u *OrderUsecase) CreateOrder(ctx context.Context, req *dto.CreateOrderRequ
 		Discount:             req.Discount,
 		Total:                total,
 		Status:               domain.OrderStatusPending,
+		ShippingAddress:      *shippingAddress,
 		Items:                items,
 	}
 
@@ -90,6 +130,17 @@ func (u *OrderUsecase) CreateOrder(ctx context.Context, req *dto.CreateOrderRequ
 		return nil, err
 	}
 
+	// The stock movements need the order's id, so the order is stored first and canceled when its stock
+	// cannot be taken
+	if err := u.takeStock(ctx, order.ID, order.Items); err != nil {
+		if _, cancelErr := u.orderRepo.UpdateOrderStatus(ctx, order.ID, domain.OrderStatusCanceled); cancelErr != nil {
+			logger.Errorf("event=order_cancel_failed order_id=%d error=%v", order.ID, cancelErr)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	span.SetAttributes(attribute.Int("order.id", int(order.ID)))
 	span.SetStatus(codes.Ok, "order created")
 	return mapOrderToResponse(order), nil
@@ -110,11 +161,25 @@ func (u *OrderUsecase) GetOrderByID(ctx context.Context, id uint) (*dto.OrderRes
 	return mapOrderToResponse(order), nil
 }
 
-func (u *OrderUsecase) ListOrders(ctx context.Context, userID *uint, page, perPage int) ([]dto.OrderResponse, int, error) {
+func (u *OrderUsecase) ListOrders(ctx context.Context, req *dto.ListOrdersRequest) ([]dto.OrderResponse, int, error) {
 	ctx, span := u.tracer.Start(ctx, "OrderUsecase.ListOrders")
 	defer span.End()
 
-	orders, total, err := u.orderRepo.ListOrders(ctx, userID, page, perPage)
+	filter := domain.OrderListFilter{
+		Status:   domain.OrderStatus(req.Status),
+		SortBy:   domain.OrderSortField(req.SortBy),
+		SortDesc: req.SortOrder != "asc",
+	}
+	if req.UserID > 0 {
+		filter.UserID = &req.UserID
+	}
+	if err := setCreatedRange(&filter, req.StartDate, req.EndDate); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, err
+	}
+
+	orders, total, err := u.orderRepo.ListOrders(ctx, filter, req.Page, req.PerPage)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -130,6 +195,79 @@ func (u *OrderUsecase) ListOrders(ctx context.Context, userID *uint, page, perPa
 	return response, total, nil
 }
 
+// ExportOrders passes every order matching req to send, exportBatchSize at a time in id order, until they
+// run out, send fails or ctx ends
+func (u *OrderUsecase) ExportOrders(ctx context.Context, req *dto.ExportOrdersRequest, send func([]dto.OrderResponse) error) error {
+	ctx, span := u.tracer.Start(ctx, "OrderUsecase.ExportOrders")
+	defer span.End()
+
+	filter := domain.OrderListFilter{Status: domain.OrderStatus(req.Status)}
+	if err := setCreatedRange(&filter, req.StartDate, req.EndDate); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	exported := 0
+	var afterID uint
+	for {
+		orders, err := u.orderRepo.ListOrdersAfter(ctx, filter, afterID, exportBatchSize)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+		if len(orders) == 0 {
+			break
+		}
+
+		batch := make([]dto.OrderResponse, 0, len(orders))
+		for i := range orders {
+			batch = append(batch, *mapOrderToResponse(&orders[i]))
+		}
+		if err := send(batch); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+		exported += len(orders)
+
+		if len(orders) < exportBatchSize {
+			break
+		}
+		afterID = orders[len(orders)-1].ID
+	}
+
+	span.SetAttributes(attribute.Int("orders.count", exported))
+	span.SetStatus(codes.Ok, "orders exported")
+	return nil
+}
+
+// setCreatedRange sets the creation date bounds of filter from inclusive YYYY-MM-DD dates, either of which
+// may be empty
+func setCreatedRange(filter *domain.OrderListFilter, startDate, endDate string) error {
+	if startDate != "" {
+		start, err := time.Parse(dateLayout, startDate)
+		if err != nil {
+			return fmt.Errorf("invalid start_date: %w", err)
+		}
+		filter.CreatedFrom = &start
+	}
+	if endDate != "" {
+		end, err := time.Parse(dateLayout, endDate)
+		if err != nil {
+			return fmt.Errorf("invalid end_date: %w", err)
+		}
+		// end_date is inclusive, so the filter runs up to the start of the following day
+		end = end.AddDate(0, 0, 1)
+		filter.CreatedTo = &end
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return fmt.Errorf("start_date must not be after end_date")
+	}
+	return nil
+}
+
 func (u *OrderUsecase) AddOrderItem(ctx context.Context, req *dto.AddOrderItemRequest) (*dto.OrderResponse, error) {
 	ctx, span := u.tracer.Start(ctx, "OrderUsecase.AddOrderItem")
 	defer span.End()
@@ -141,6 +279,13 @@ func (u *OrderUsecase) AddOrderItem(ctx context.Context, req *dto.AddOrderItemRe
 		return nil, err
 	}
 
+	order, err := u.orderRepo.GetOrderByID(ctx, req.OrderID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	unitPrice := product.GetPrice()
 	item := &domain.OrderItem{
 		OrderID:    req.OrderID,
@@ -150,13 +295,26 @@ func (u *OrderUsecase) AddOrderItem(ctx context.Context, req *dto.AddOrderItemRe
 		TotalPrice: unitPrice * float32(req.Quantity),
 	}
 
+	// A canceled order has put its stock back, so its items no longer hold any
+	holdsStock := order.Status != domain.OrderStatusCanceled
+	if holdsStock {
+		if err := u.takeStock(ctx, order.ID, []domain.OrderItem{*item}); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+	}
+
 	if err := u.orderRepo.AddOrderItem(ctx, item); err != nil {
+		if holdsStock {
+			u.putBackStock(ctx, order.ID, []domain.OrderItem{*item})
+		}
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	order, err := u.orderRepo.GetOrderByID(ctx, req.OrderID)
+	order, err = u.orderRepo.GetOrderByID(ctx, req.OrderID)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -179,13 +337,94 @@ func (u *OrderUsecase) RemoveOrderItem(ctx context.Context, orderID, itemID uint
 	ctx, span := u.tracer.Start(ctx, "OrderUsecase.RemoveOrderItem")
 	defer span.End()
 
+	order, err := u.orderRepo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	item, ok := findItem(order.Items, itemID)
+	if !ok {
+		span.SetStatus(codes.Error, "order item not found")
+		return nil, repository.ErrOrderItemNotFound
+	}
+
 	if err := u.orderRepo.RemoveOrderItem(ctx, orderID, itemID); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
+	if order.Status != domain.OrderStatusCanceled {
+		u.putBackStock(ctx, orderID, []domain.OrderItem{item})
+	}
 
-	order, err := u.orderRepo.GetOrderByID(ctx, orderID)
+	order, err = u.orderRepo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	itemsTotal := sumItemsTotal(order.Items)
+	updatedTotal := calculateOrderTotal(itemsTotal, order.ShippingCost, order.Discount)
+	if err := u.orderRepo.UpdateOrderTotal(ctx, order.ID, updatedTotal); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	order.Total = updatedTotal
+
+	return mapOrderToResponse(order), nil
+}
+
+func (u *OrderUsecase) UpdateOrderItemQuantity(ctx context.Context, req *dto.UpdateOrderItemQuantityRequest) (*dto.OrderResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "OrderUsecase.UpdateOrderItemQuantity")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("order.id", int(req.OrderID)),
+		attribute.Int("order.item_id", int(req.ItemID)),
+		attribute.Int("order.item_quantity", req.Quantity),
+	)
+
+	order, err := u.orderRepo.GetOrderByID(ctx, req.OrderID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	item, ok := findItem(order.Items, req.ItemID)
+	if !ok {
+		span.SetStatus(codes.Error, "order item not found")
+		return nil, repository.ErrOrderItemNotFound
+	}
+
+	// Only pending orders take the update, and they hold their stock. More units are taken before the
+	// update and fewer put back after it, so stock is never promised twice.
+	change := item
+	change.Quantity = req.Quantity - item.Quantity
+	if change.Quantity > 0 && order.Status == domain.OrderStatusPending {
+		if err := u.takeStock(ctx, order.ID, []domain.OrderItem{change}); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+	}
+
+	if err := u.orderRepo.UpdateOrderItemQuantity(ctx, req.OrderID, req.ItemID, req.Quantity); err != nil {
+		if change.Quantity > 0 && order.Status == domain.OrderStatusPending {
+			u.putBackStock(ctx, order.ID, []domain.OrderItem{change})
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if change.Quantity < 0 {
+		change.Quantity = -change.Quantity
+		u.putBackStock(ctx, order.ID, []domain.OrderItem{change})
+	}
+
+	order, err = u.orderRepo.GetOrderByID(ctx, req.OrderID)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -209,7 +448,8 @@ func (u *OrderUsecase) UpdateOrderStatus(ctx context.Context, orderID uint, stat
 	defer span.End()
 
 	orderStatus := domain.OrderStatus(status)
-	if err := u.orderRepo.UpdateOrderStatus(ctx, orderID, orderStatus); err != nil {
+	previous, err := u.orderRepo.UpdateOrderStatus(ctx, orderID, orderStatus)
+	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
@@ -222,9 +462,140 @@ func (u *OrderUsecase) UpdateOrderStatus(ctx context.Context, orderID uint, stat
 		return nil, err
 	}
 
+	if orderStatus == domain.OrderStatusCanceled && previous != domain.OrderStatusCanceled {
+		u.putBackStock(ctx, orderID, order.Items)
+	}
+
 	return mapOrderToResponse(order), nil
 }
 
+func (u *OrderUsecase) GetRevenueSummary(ctx context.Context, req *dto.RevenueSummaryRequest) ([]dto.RevenuePeriodResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "OrderUsecase.GetRevenueSummary")
+	defer span.End()
+
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("invalid start_date: %w", err)
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("invalid end_date: %w", err)
+	}
+	if !start.Before(end) {
+		err := fmt.Errorf("start_date must be before end_date")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	granularity := domain.RevenueGranularity(req.Granularity)
+
+	// end_date is inclusive, so the query runs up to the start of the following day
+	periods, err := u.orderRepo.GetRevenueByPeriod(ctx, start, end.AddDate(0, 0, 1), granularity)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	byStart := make(map[time.Time]domain.RevenuePeriod, len(periods))
+	for _, period := range periods {
+		byStart[period.Start] = period
+	}
+
+	// Emit every period in the range so gaps show up as zero revenue instead of missing rows
+	response := make([]dto.RevenuePeriodResponse, 0)
+	for _, period := range reportPeriods(start, end, granularity) {
+		revenue := byStart[period.bucket]
+		response = append(response, dto.RevenuePeriodResponse{
+			Start:      period.start.Format(dateLayout),
+			End:        period.end.Format(dateLayout),
+			Revenue:    revenue.Revenue,
+			OrderCount: revenue.OrderCount,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("revenue.periods", len(response)))
+	span.SetStatus(codes.Ok, "revenue summarized")
+	return response, nil
+}
+
+func (u *OrderUsecase) AnonymiseUserOrders(ctx context.Context, userID uint) (int64, error) {
+	ctx, span := u.tracer.Start(ctx, "OrderUsecase.AnonymiseUserOrders")
+	defer span.End()
+
+	// The user may already be gone, so unlike CreateOrder this must not call ensureUserExists
+	count, err := u.orderRepo.AnonymiseUserOrders(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	span.SetStatus(codes.Ok, "orders anonymised")
+	return count, nil
+}
+
+func (u *OrderUsecase) HasPurchasedProduct(ctx context.Context, userID, productID uint) (bool, error) {
+	ctx, span := u.tracer.Start(ctx, "OrderUsecase.HasPurchasedProduct")
+	defer span.End()
+
+	purchased, err := u.orderRepo.HasPurchasedProduct(ctx, userID, productID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, err
+	}
+
+	span.SetStatus(codes.Ok, "purchase checked")
+	return purchased, nil
+}
+
+// WatchOrderStatus calls send with the order as it is now and again after every status change,
+// returning nil once the order reaches a final status and ctx.Err() when the watcher goes away
+func (u *OrderUsecase) WatchOrderStatus(ctx context.Context, orderID uint, send func(*dto.OrderResponse) error) error {
+	ctx, span := u.tracer.Start(ctx, "OrderUsecase.WatchOrderStatus")
+	defer span.End()
+	span.SetAttributes(attribute.Int("order.id", int(orderID)))
+
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+
+	var lastStatus domain.OrderStatus
+	for {
+		order, err := u.orderRepo.GetOrderByID(ctx, orderID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+
+		if order.Status != lastStatus {
+			lastStatus = order.Status
+			if err := send(mapOrderToResponse(order)); err != nil {
+				return err
+			}
+		}
+		if order.Status.IsFinal() {
+			span.SetStatus(codes.Ok, "order reached final status")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
 func (u *OrderUsecase) ensureUserExists(ctx context.Context, userID uint) error {
 	ctx, cancel := context.WithTimeout(ctx, downstreamTimeout)
 	defer cancel()
@@ -236,6 +607,35 @@ func (u *OrderUsecase) ensureUserExists(ctx context.Context, userID uint) error
 	return nil
 }
 
+// snapshotAddress copies the user's address so the order keeps it even if the address is later edited
+func snapshotAddress(ctx context.Context, userClient userpb.UserServiceClient, userID, addressID uint) (*domain.ShippingAddress, error) {
+	ctx, cancel := context.WithTimeout(ctx, downstreamTimeout)
+	defer cancel()
+
+	response, err := userClient.GetAddressByID(ctx, &userpb.GetAddressByIDRequest{Id: int32(addressID)})
+	if err != nil {
+		return nil, fmt.Errorf("address not found: %w", err)
+	}
+
+	address := response.GetAddress()
+	if address == nil {
+		return nil, fmt.Errorf("address not found: empty response")
+	}
+	if uint(address.GetUserId()) != userID {
+		return nil, ErrAddressNotOwned
+	}
+
+	id := uint(address.GetId())
+	return &domain.ShippingAddress{
+		AddressID:  &id,
+		Street:     address.GetStreet(),
+		City:       address.GetCity(),
+		State:      address.GetState(),
+		Country:    address.GetCountry(),
+		PostalCode: address.GetZipCode(),
+	}, nil
+}
+
 func (u *OrderUsecase) ensureProductExists(ctx context.Context, productID uint) (*productpb.Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, downstreamTimeout)
 	defer cancel()
@@ -250,6 +650,53 @@ func (u *OrderUsecase) ensureProductExists(ctx context.Context, productID uint)
 	return response.GetProduct(), nil
 }
 
+// takeStock deducts the quantity of every item from the product service's stock for orderID. It takes all
+// of them or none: when one product is short, what was already taken is put back.
+func (u *OrderUsecase) takeStock(ctx context.Context, orderID uint, items []domain.OrderItem) error {
+	for i, item := range items {
+		if err := u.adjustStock(ctx, orderID, item.ProductID, -item.Quantity); err != nil {
+			u.putBackStock(ctx, orderID, items[:i])
+			if status.Code(err) == grpccodes.FailedPrecondition {
+				return fmt.Errorf("%w for product %d", ErrInsufficientStock, item.ProductID)
+			}
+			return fmt.Errorf("take stock of product %d: %w", item.ProductID, err)
+		}
+	}
+	return nil
+}
+
+// putBackStock returns the quantity of every item to stock. The order change behind it is already stored,
+// so a failure is logged for someone to correct the stock rather than returned.
+func (u *OrderUsecase) putBackStock(ctx context.Context, orderID uint, items []domain.OrderItem) {
+	for _, item := range items {
+		if err := u.adjustStock(ctx, orderID, item.ProductID, item.Quantity); err != nil {
+			logger.Errorf("event=stock_put_back_failed order_id=%d product_id=%d quantity=%d error=%v", orderID, item.ProductID, item.Quantity, err)
+		}
+	}
+}
+
+func (u *OrderUsecase) adjustStock(ctx context.Context, orderID, productID uint, delta int) error {
+	ctx, cancel := context.WithTimeout(ctx, downstreamTimeout)
+	defer cancel()
+
+	_, err := u.productClient.AdjustProductStock(ctx, &productpb.AdjustProductStockRequest{
+		ProductId: int64(productID),
+		Delta:     int32(delta),
+		Reason:    stockReasonOrder,
+		OrderId:   int64(orderID),
+	})
+	return err
+}
+
+func findItem(items []domain.OrderItem, itemID uint) (domain.OrderItem, bool) {
+	for _, item := range items {
+		if item.ID == itemID {
+			return item, true
+		}
+	}
+	return domain.OrderItem{}, false
+}
+
 func mapOrderToResponse(order *domain.Order) *dto.OrderResponse {
 	items := make([]dto.OrderItemResponse, 0, len(order.Items))
 	for _, item := range order.Items {
@@ -263,6 +710,18 @@ func mapOrderToResponse(order *domain.Order) *dto.OrderResponse {
 		})
 	}
 
+	var shippingAddress *dto.ShippingAddress
+	if order.ShippingAddress.AddressID != nil {
+		shippingAddress = &dto.ShippingAddress{
+			AddressID:  *order.ShippingAddress.AddressID,
+			Street:     order.ShippingAddress.Street,
+			City:       order.ShippingAddress.City,
+			State:      order.ShippingAddress.State,
+			Country:    order.ShippingAddress.Country,
+			PostalCode: order.ShippingAddress.PostalCode,
+		}
+	}
+
 	return &dto.OrderResponse{
 		ID:               order.ID,
 		UserID:           order.UserID,
@@ -272,6 +731,7 @@ func mapOrderToResponse(order *domain.Order) *dto.OrderResponse {
 		Total:            order.Total,
 		Status:           string(order.Status),
 		Items:            items,
+		ShippingAddress:  shippingAddress,
 		CreatedAt:        order.CreatedAt,
 		UpdatedAt:        order.UpdatedAt,
 	}
@@ -302,3 +762,28 @@ func calculateOrderTotal(itemsTotal, shippingCost, discount float32) float32 {
 	}
 	return total
 }
+
+// truncatePeriod mirrors postgres date_trunc: weeks start on Monday
+func truncatePeriod(t time.Time, granularity domain.RevenueGranularity) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case domain.RevenueGranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case domain.RevenueGranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+func nextPeriod(t time.Time, granularity domain.RevenueGranularity) time.Time {
+	switch granularity {
+	case domain.RevenueGranularityWeek:
+		return t.AddDate(0, 0, 7)
+	case domain.RevenueGranularityMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
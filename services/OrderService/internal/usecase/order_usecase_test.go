@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/repository"
+	productpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeOrders stores orders in memory; the methods a test does not need panic through the nil interface
+type fakeOrders struct {
+	domain.OrderRepository
+	orders map[uint]*domain.Order
+	nextID uint
+}
+
+func newFakeOrders() *fakeOrders {
+	return &fakeOrders{orders: map[uint]*domain.Order{}}
+}
+
+func (f *fakeOrders) id() uint {
+	f.nextID++
+	return f.nextID
+}
+
+func (f *fakeOrders) CreateOrder(_ context.Context, order *domain.Order) error {
+	order.ID = f.id()
+	for i := range order.Items {
+		order.Items[i].ID = f.id()
+		order.Items[i].OrderID = order.ID
+	}
+	stored := *order
+	stored.Items = slices.Clone(order.Items)
+	f.orders[order.ID] = &stored
+	return nil
+}
+
+func (f *fakeOrders) GetOrderByID(_ context.Context, id uint) (*domain.Order, error) {
+	order, ok := f.orders[id]
+	if !ok {
+		return nil, repository.ErrOrderNotFound
+	}
+	found := *order
+	found.Items = slices.Clone(order.Items)
+	return &found, nil
+}
+
+func (f *fakeOrders) AddOrderItem(_ context.Context, item *domain.OrderItem) error {
+	item.ID = f.id()
+	f.orders[item.OrderID].Items = append(f.orders[item.OrderID].Items, *item)
+	return nil
+}
+
+func (f *fakeOrders) RemoveOrderItem(_ context.Context, orderID, itemID uint) error {
+	order := f.orders[orderID]
+	order.Items = slices.DeleteFunc(order.Items, func(item domain.OrderItem) bool { return item.ID == itemID })
+	return nil
+}
+
+func (f *fakeOrders) UpdateOrderItemQuantity(_ context.Context, orderID, itemID uint, quantity int) error {
+	order := f.orders[orderID]
+	if order.Status != domain.OrderStatusPending {
+		return repository.ErrOrderNotPending
+	}
+	for i := range order.Items {
+		if order.Items[i].ID == itemID {
+			order.Items[i].Quantity = quantity
+		}
+	}
+	return nil
+}
+
+func (f *fakeOrders) UpdateOrderStatus(_ context.Context, orderID uint, status domain.OrderStatus) (domain.OrderStatus, error) {
+	order := f.orders[orderID]
+	previous := order.Status
+	if previous == domain.OrderStatusCanceled && status != domain.OrderStatusCanceled {
+		return previous, repository.ErrOrderCanceled
+	}
+	order.Status = status
+	return previous, nil
+}
+
+func (f *fakeOrders) UpdateOrderTotal(context.Context, uint, float32) error {
+	return nil
+}
+
+// fakeCatalog keeps stock per product and refuses to take it below zero, as AdjustProductStock does
+type fakeCatalog struct {
+	productpb.ProductServiceClient
+	stock       map[int64]int32
+	adjustments []*productpb.AdjustProductStockRequest
+}
+
+func (f *fakeCatalog) GetProductByID(_ context.Context, in *productpb.GetProductByIDRequest, _ ...grpc.CallOption) (*productpb.GetProductByIDResponse, error) {
+	if _, ok := f.stock[in.GetId()]; !ok {
+		return nil, status.Error(grpccodes.NotFound, "product not found")
+	}
+	return &productpb.GetProductByIDResponse{Product: &productpb.Product{Id: int32(in.GetId()), Price: 10}}, nil
+}
+
+func (f *fakeCatalog) AdjustProductStock(_ context.Context, in *productpb.AdjustProductStockRequest, _ ...grpc.CallOption) (*productpb.AdjustProductStockResponse, error) {
+	if f.stock[in.GetProductId()]+in.GetDelta() < 0 {
+		return nil, status.Error(grpccodes.FailedPrecondition, "insufficient stock")
+	}
+	f.adjustments = append(f.adjustments, in)
+	f.stock[in.GetProductId()] += in.GetDelta()
+	return &productpb.AdjustProductStockResponse{ProductId: in.GetProductId(), StockLevel: f.stock[in.GetProductId()]}, nil
+}
+
+// fakeUsers knows every user and gives each the address with their own id
+type fakeUsers struct {
+	userpb.UserServiceClient
+}
+
+func (fakeUsers) GetUserByID(_ context.Context, in *userpb.GetUserByIDRequest, _ ...grpc.CallOption) (*userpb.User, error) {
+	return &userpb.User{Id: in.GetId()}, nil
+}
+
+func (fakeUsers) GetAddressByID(_ context.Context, in *userpb.GetAddressByIDRequest, _ ...grpc.CallOption) (*userpb.GetAddressByIDResponse, error) {
+	return &userpb.GetAddressByIDResponse{Address: &userpb.Address{Id: in.GetId(), UserId: int32(in.GetId())}}, nil
+}
+
+func newStockUsecase(stock map[int64]int32) (*OrderUsecase, *fakeOrders, *fakeCatalog) {
+	orders := newFakeOrders()
+	catalog := &fakeCatalog{stock: stock}
+	return NewOrderUsecase(orders, catalog, fakeUsers{}, nil), orders, catalog
+}
+
+func placeOrder(t *testing.T, u *OrderUsecase, items ...dto.OrderItemInput) (*dto.OrderResponse, error) {
+	t.Helper()
+	return u.CreateOrder(context.Background(), &dto.CreateOrderRequest{UserID: 1, AddressID: 1, Items: items})
+}
+
+func TestCreateOrderTakesStockForTheOrder(t *testing.T) {
+	u, _, catalog := newStockUsecase(map[int64]int32{1: 5, 2: 3})
+
+	order, err := placeOrder(t, u, dto.OrderItemInput{ProductID: 1, Quantity: 2}, dto.OrderItemInput{ProductID: 2, Quantity: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := map[int64]int32{1: 3, 2: 0}; !reflect.DeepEqual(catalog.stock, want) {
+		t.Errorf("stock = %v, want %v", catalog.stock, want)
+	}
+	for _, adjustment := range catalog.adjustments {
+		if adjustment.GetReason() != "order" || adjustment.GetOrderId() != int64(order.ID) {
+			t.Errorf("adjustment %v, want reason order for order %d", adjustment, order.ID)
+		}
+	}
+}
+
+func TestCreateOrderWithoutStockTakesNone(t *testing.T) {
+	u, orders, catalog := newStockUsecase(map[int64]int32{1: 5, 2: 2})
+
+	_, err := placeOrder(t, u, dto.OrderItemInput{ProductID: 1, Quantity: 2}, dto.OrderItemInput{ProductID: 2, Quantity: 3})
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("err = %v, want %v", err, ErrInsufficientStock)
+	}
+
+	// The units taken for product 1 went back, and the stored order was canceled
+	if want := map[int64]int32{1: 5, 2: 2}; !reflect.DeepEqual(catalog.stock, want) {
+		t.Errorf("stock = %v, want it untouched at %v", catalog.stock, want)
+	}
+	if status := orders.orders[1].Status; status != domain.OrderStatusCanceled {
+		t.Errorf("order status = %s, want canceled", status)
+	}
+}
+
+func TestCancelingAnOrderPutsItsStockBackOnce(t *testing.T) {
+	u, _, catalog := newStockUsecase(map[int64]int32{1: 5, 2: 3})
+	order, err := placeOrder(t, u, dto.OrderItemInput{ProductID: 1, Quantity: 2}, dto.OrderItemInput{ProductID: 2, Quantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if _, err := u.UpdateOrderStatus(context.Background(), order.ID, string(domain.OrderStatusCanceled)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := map[int64]int32{1: 5, 2: 3}; !reflect.DeepEqual(catalog.stock, want) {
+		t.Errorf("stock = %v, want %v", catalog.stock, want)
+	}
+
+	if _, err := u.UpdateOrderStatus(context.Background(), order.ID, string(domain.OrderStatusPending)); !errors.Is(err, repository.ErrOrderCanceled) {
+		t.Errorf("reopening: err = %v, want %v", err, repository.ErrOrderCanceled)
+	}
+}
+
+func TestOrderItemChangesMoveStock(t *testing.T) {
+	u, _, catalog := newStockUsecase(map[int64]int32{1: 10, 2: 4})
+	order, err := placeOrder(t, u, dto.OrderItemInput{ProductID: 1, Quantity: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	order, err = u.AddOrderItem(ctx, &dto.AddOrderItemRequest{OrderID: order.ID, ProductID: 2, Quantity: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.AddOrderItem(ctx, &dto.AddOrderItemRequest{OrderID: order.ID, ProductID: 2, Quantity: 2}); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("adding past the stock: err = %v, want %v", err, ErrInsufficientStock)
+	}
+	if want := map[int64]int32{1: 8, 2: 1}; !reflect.DeepEqual(catalog.stock, want) {
+		t.Fatalf("after adding: stock = %v, want %v", catalog.stock, want)
+	}
+
+	first, second := order.Items[0], order.Items[1]
+	if _, err := u.UpdateOrderItemQuantity(ctx, &dto.UpdateOrderItemQuantityRequest{OrderID: order.ID, ItemID: first.ID, Quantity: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.UpdateOrderItemQuantity(ctx, &dto.UpdateOrderItemQuantityRequest{OrderID: order.ID, ItemID: second.ID, Quantity: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int64]int32{1: 5, 2: 3}; !reflect.DeepEqual(catalog.stock, want) {
+		t.Fatalf("after updating quantities: stock = %v, want %v", catalog.stock, want)
+	}
+
+	if _, err := u.RemoveOrderItem(ctx, order.ID, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int64]int32{1: 10, 2: 3}; !reflect.DeepEqual(catalog.stock, want) {
+		t.Errorf("after removing: stock = %v, want %v", catalog.stock, want)
+	}
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ErrInvalidReportRange is returned for report dates that are malformed, out of order or too far apart
+var ErrInvalidReportRange = errors.New("invalid report range")
+
+// defaultTopProducts is how many products GetTopProducts returns when the request sets no limit
+const defaultTopProducts = 10
+
+// GetSalesReport sums the sales of every period from req.From to req.To, both inclusive, and of the whole range.
+// Revenue is the order totals, shipping and discounts included.
+func (u *OrderUsecase) GetSalesReport(ctx context.Context, req *dto.SalesReportRequest) (*dto.SalesReportResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "OrderUsecase.GetSalesReport")
+	defer span.End()
+
+	start, end, err := reportRange(req.From, req.To)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	granularity := domain.RevenueGranularity(req.GroupBy)
+
+	sales, err := u.orderRepo.GetSalesByPeriod(ctx, start, end.AddDate(0, 0, 1), granularity)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	byStart := make(map[time.Time]domain.SalesPeriod, len(sales))
+	for _, period := range sales {
+		byStart[period.Start] = period
+	}
+
+	report := &dto.SalesReportResponse{Periods: make([]dto.SalesPeriodResponse, 0)}
+	var total domain.SalesPeriod
+	for _, period := range reportPeriods(start, end, granularity) {
+		sold := byStart[period.bucket]
+		report.Periods = append(report.Periods, salesPeriodResponse(period.start, period.end, sold))
+
+		total.Revenue += sold.Revenue
+		total.OrderCount += sold.OrderCount
+		total.CanceledValue += sold.CanceledValue
+		total.CanceledCount += sold.CanceledCount
+	}
+	report.Total = salesPeriodResponse(start, end, total)
+
+	span.SetAttributes(attribute.Int("sales.periods", len(report.Periods)))
+	span.SetStatus(codes.Ok, "sales reported")
+	return report, nil
+}
+
+// GetTopProducts ranks the products sold from req.From to req.To, both inclusive, by the revenue of their
+// items. Names come from the product service; the ranking is still returned without them when it fails.
+func (u *OrderUsecase) GetTopProducts(ctx context.Context, req *dto.TopProductsRequest) ([]dto.ProductSalesResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "OrderUsecase.GetTopProducts")
+	defer span.End()
+
+	start, end, err := reportRange(req.From, req.To)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultTopProducts
+	}
+
+	products, err := u.orderRepo.GetTopProducts(ctx, start, end.AddDate(0, 0, 1), limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ProductID)
+	}
+	names, err := u.productNames(ctx, ids)
+	if err != nil {
+		span.RecordError(err)
+		logger.Warnf("event=top_products_names_failed error=%v", err)
+	}
+
+	response := make([]dto.ProductSalesResponse, 0, len(products))
+	for _, product := range products {
+		response = append(response, dto.ProductSalesResponse{
+			ProductID:  product.ProductID,
+			Name:       names[product.ProductID],
+			Quantity:   product.Quantity,
+			Revenue:    roundCents(product.Revenue),
+			OrderCount: product.OrderCount,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("products.count", len(response)))
+	span.SetStatus(codes.Ok, "top products reported")
+	return response, nil
+}
+
+// reportRange parses the inclusive YYYY-MM-DD dates of a report, which may cover at most a year
+func reportRange(from, to string) (time.Time, time.Time, error) {
+	start, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be in YYYY-MM-DD format", ErrInvalidReportRange)
+	}
+	end, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be in YYYY-MM-DD format", ErrInvalidReportRange)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to must not be before from", ErrInvalidReportRange)
+	}
+	if !end.Before(start.AddDate(1, 0, 0)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: the range must not be longer than a year", ErrInvalidReportRange)
+	}
+	return start, end, nil
+}
+
+// reportPeriod is one row of a report: bucket is the period's start as date_trunc gives it, and start and end
+// are the days it covers within the report's range
+type reportPeriod struct {
+	bucket, start, end time.Time
+}
+
+// reportPeriods lists every period from start to end, so gaps show up as empty rows instead of missing ones
+func reportPeriods(start, end time.Time, granularity domain.RevenueGranularity) []reportPeriod {
+	var periods []reportPeriod
+	for cursor := truncatePeriod(start, granularity); !cursor.After(end); cursor = nextPeriod(cursor, granularity) {
+		period := reportPeriod{bucket: cursor, start: cursor, end: nextPeriod(cursor, granularity).AddDate(0, 0, -1)}
+		if period.start.Before(start) {
+			period.start = start
+		}
+		if period.end.After(end) {
+			period.end = end
+		}
+		periods = append(periods, period)
+	}
+	return periods
+}
+
+func salesPeriodResponse(start, end time.Time, sales domain.SalesPeriod) dto.SalesPeriodResponse {
+	response := dto.SalesPeriodResponse{
+		Start:         start.Format(dateLayout),
+		End:           end.Format(dateLayout),
+		Revenue:       roundCents(sales.Revenue),
+		OrderCount:    sales.OrderCount,
+		CanceledCount: sales.CanceledCount,
+		CanceledValue: roundCents(sales.CanceledValue),
+	}
+	if sales.OrderCount > 0 {
+		response.AverageOrderValue = roundCents(sales.Revenue / float64(sales.OrderCount))
+	}
+	return response
+}
+
+// roundCents rounds away the float error sums of single-precision totals pick up
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
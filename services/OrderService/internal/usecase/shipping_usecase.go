@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrShippingOptionUnavailable = errors.New("shipping option is not available for this address")
+
+type ShippingUsecase struct {
+	rates      []domain.ShippingRate
+	userClient userpb.UserServiceClient
+	tracer     trace.Tracer
+}
+
+var _ domain.ShippingUsecase = (*ShippingUsecase)(nil)
+
+func NewShippingUsecase(rates []domain.ShippingRate, userClient userpb.UserServiceClient) *ShippingUsecase {
+	return &ShippingUsecase{
+		rates:      rates,
+		userClient: userClient,
+		tracer:     otel.Tracer("shipping-usecase"),
+	}
+}
+
+func (u *ShippingUsecase) QuoteShipping(ctx context.Context, req *dto.ShippingQuoteRequest) ([]dto.ShippingOptionResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "ShippingUsecase.QuoteShipping")
+	defer span.End()
+
+	address, err := snapshotAddress(ctx, u.userClient, req.UserID, req.AddressID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	quantity := totalQuantity(req.Items)
+	now := time.Now().UTC()
+	options := make([]dto.ShippingOptionResponse, 0, len(u.rates))
+	for _, rate := range u.rates {
+		if servesCountry(rate, address.Country) {
+			options = append(options, quoteRate(rate, quantity, now))
+		}
+	}
+
+	span.SetAttributes(attribute.Int("shipping.options", len(options)))
+	return options, nil
+}
+
+func (u *ShippingUsecase) ResolveOption(optionID, country string, quantity int) (*dto.ShippingOptionResponse, error) {
+	i := slices.IndexFunc(u.rates, func(rate domain.ShippingRate) bool { return rate.ID == optionID })
+	if i < 0 || !servesCountry(u.rates[i], country) {
+		return nil, ErrShippingOptionUnavailable
+	}
+
+	option := quoteRate(u.rates[i], quantity, time.Now().UTC())
+	return &option, nil
+}
+
+func servesCountry(rate domain.ShippingRate, country string) bool {
+	if len(rate.Countries) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(rate.Countries, func(c string) bool { return strings.EqualFold(c, country) })
+}
+
+func quoteRate(rate domain.ShippingRate, quantity int, now time.Time) dto.ShippingOptionResponse {
+	return dto.ShippingOptionResponse{
+		ID:                rate.ID,
+		Name:              rate.Name,
+		Cost:              rate.BaseCost + rate.PerItemCost*float32(quantity),
+		DurationDays:      rate.DurationDays,
+		EstimatedDelivery: now.AddDate(0, 0, rate.DurationDays),
+	}
+}
+
+func totalQuantity(items []dto.OrderItemInput) int {
+	var quantity int
+	for _, item := range items {
+		quantity += item.Quantity
+	}
+	return quantity
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/OrderService/internal/domain"
+	userpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc"
+)
+
+// addressBook answers GetAddressByID from the addresses it holds by ID
+type addressBook struct {
+	userpb.UserServiceClient
+	addresses map[int32]*userpb.Address
+}
+
+func (b addressBook) GetAddressByID(_ context.Context, in *userpb.GetAddressByIDRequest, _ ...grpc.CallOption) (*userpb.GetAddressByIDResponse, error) {
+	return &userpb.GetAddressByIDResponse{Address: b.addresses[in.GetId()]}, nil
+}
+
+var testShippingRates = []domain.ShippingRate{
+	{ID: "standard", Name: "Standard", BaseCost: 5, PerItemCost: 0.5, DurationDays: 5},
+	{ID: "express", Name: "Express", BaseCost: 15, PerItemCost: 1, DurationDays: 2, Countries: []string{"EG"}},
+}
+
+func TestQuoteShipping(t *testing.T) {
+	users := addressBook{addresses: map[int32]*userpb.Address{
+		1: {Id: 1, UserId: 7, Country: "eg"},
+		2: {Id: 2, UserId: 7, Country: "FR"},
+		3: {Id: 3, UserId: 8, Country: "EG"},
+	}}
+	u := NewShippingUsecase(testShippingRates, users)
+	items := []dto.OrderItemInput{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 2}}
+
+	tests := []struct {
+		name      string
+		addressID uint
+		wantCosts map[string]float32
+		wantErr   error
+	}{
+		// Countries match regardless of case
+		{name: "every option", addressID: 1, wantCosts: map[string]float32{"standard": 7, "express": 19}},
+		{name: "country not served by express", addressID: 2, wantCosts: map[string]float32{"standard": 7}},
+		{name: "another user's address", addressID: 3, wantErr: ErrAddressNotOwned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now().UTC()
+			options, err := u.QuoteShipping(context.Background(), &dto.ShippingQuoteRequest{UserID: 7, AddressID: tt.addressID, Items: items})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("QuoteShipping = %v, want %v", err, tt.wantErr)
+			}
+
+			costs := make(map[string]float32, len(options))
+			for _, option := range options {
+				costs[option.ID] = option.Cost
+				if want := before.AddDate(0, 0, option.DurationDays); option.EstimatedDelivery.Before(want) {
+					t.Errorf("%s delivers by %s, want %d days from now", option.ID, option.EstimatedDelivery, option.DurationDays)
+				}
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(costs, tt.wantCosts) {
+				t.Errorf("costs = %v, want %v", costs, tt.wantCosts)
+			}
+		})
+	}
+}
+
+func TestResolveOption(t *testing.T) {
+	u := NewShippingUsecase(testShippingRates, nil)
+
+	option, err := u.ResolveOption("express", "EG", 3)
+	if err != nil {
+		t.Fatalf("ResolveOption = %v", err)
+	}
+	if option.Cost != 18 || option.DurationDays != 2 {
+		t.Errorf("express for 3 items = %+v, want cost 18 in 2 days", option)
+	}
+
+	for _, tt := range []struct{ optionID, country string }{{"express", "FR"}, {"overnight", "EG"}} {
+		if _, err := u.ResolveOption(tt.optionID, tt.country, 1); !errors.Is(err, ErrShippingOptionUnavailable) {
+			t.Errorf("ResolveOption(%q, %q) = %v, want %v", tt.optionID, tt.country, err, ErrShippingOptionUnavailable)
+		}
+	}
+}
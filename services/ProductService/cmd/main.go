@@ -8,8 +8,10 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/kareemhamed001/e-commerce/pkg/db"
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	"github.com/kareemhamed001/e-commerce/pkg/redis"
+	"github.com/kareemhamed001/e-commerce/pkg/spiffeauth"
 	"github.com/kareemhamed001/e-commerce/pkg/tracer"
 	"github.com/kareemhamed001/e-commerce/services/ProductService/config"
 	redisCache "github.com/kareemhamed001/e-commerce/services/ProductService/internal/cache/redis"
@@ -35,6 +37,13 @@ func main() {
 	shutdownTracer := initTracing(ctx)
 	defer shutdownTracer()
 
+	spiffeSource, err := spiffeauth.NewSource(ctx, config.SPIFFEEndpointSocket)
+	if err != nil {
+		close(done)
+		panic(err)
+	}
+	defer spiffeSource.Close()
+
 	dbConfig := &db.Config{
 		DBDriver:              config.DBDriver,
 		DSN:                   config.DBDSN,
@@ -63,21 +72,28 @@ func main() {
 	}
 
 	productCache := redisCache.NewProductCache(redisClient)
-	productUseCase := usecase.NewProductUsecase(productRepo, productCache)
+	inventoryPublisher := redisCache.NewInventoryPublisher(redisClient)
+	productUseCase := usecase.NewProductUsecase(productRepo, productCache, inventoryPublisher, config.CursorSecret)
 
 	categoryRepo := postgresql.NewCategoryRepository(db)
 	categoryUseCase := usecase.NewCategoryUsecase(categoryRepo)
 
+	reviewRepo := postgresql.NewReviewRepository(db)
+	reviewUseCase := usecase.NewReviewUsecase(reviewRepo, productRepo)
+
 	validate := validator.New()
 
-	grpcHandler := handler.NewProductGRPCHandler(productUseCase, categoryUseCase, validate, config.InternalAuthToken)
+	reviewHandler := handler.NewReviewGRPCHandler(reviewUseCase, validate)
+	grpcHandler := handler.NewProductGRPCHandler(productUseCase, categoryUseCase, reviewHandler, validate)
 
-	err = grpcHandler.Run(done, config.GRPCPort)
+	serverOpts := append(grpcmiddleware.DefaultServerOptions(logger.NewStructured(config.AppEnv), config.InternalAuthToken, config.InternalAuthPolicy), spiffeSource.ServerOptions()...)
+	err = grpcHandler.Run(done, config.GRPCPort, serverOpts...)
 	if err != nil {
 		logger.Errorf("failed to start gRPC server: %v", err)
 		close(done)
 		panic(err)
 	}
+	grpcmiddleware.ServeMetrics(done, config.MetricsPort)
 
 	//gracful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 )
 
@@ -29,12 +30,21 @@ type Config struct {
 
 	// gRPC
 	GRPCPort string
+	// MetricsPort serves GET /metrics for Prometheus
+	MetricsPort string
 
 	// Service name
 	ServiceName string
 
 	// Internal service auth
-	InternalAuthToken string
+	InternalAuthToken  string
+	InternalAuthPolicy grpcmiddleware.InternalAuthPolicy
+
+	// SPIFFEEndpointSocket is the SPIFFE Workload API address; when set, gRPC connections use mTLS with its SVID
+	SPIFFEEndpointSocket string
+
+	// CursorSecret signs ListProducts cursors so clients can't forge positions
+	CursorSecret string
 
 	RedisEnabled  bool
 	RedisHost     string
@@ -82,7 +92,8 @@ func Load() (*Config, error) {
 		JWTDuration: getEnvInt("JWT_DURATION_HOURS", 24),
 
 		// gRPC
-		GRPCPort: GetEnv("GRPC_PORT", "50051"),
+		GRPCPort:    GetEnv("GRPC_PORT", "50051"),
+		MetricsPort: GetEnv("METRICS_PORT", "9090"),
 
 		// Service
 		ServiceName:   GetEnv("SERVICE_NAME", "produc-service"),
@@ -93,7 +104,15 @@ func Load() (*Config, error) {
 		RedisDB:       getEnvInt("REDIS_DB", 0),
 
 		// Internal service auth
-		InternalAuthToken: GetEnv("INTERNAL_AUTH_TOKEN", ""),
+		InternalAuthToken:    GetEnv("INTERNAL_AUTH_TOKEN", ""),
+		SPIFFEEndpointSocket: GetEnv("SPIFFE_ENDPOINT_SOCKET", ""),
+
+		CursorSecret: GetEnv("CURSOR_SECRET", "your-cursor-secret-change-in-production"),
+	}
+
+	cfg.InternalAuthPolicy, err = grpcmiddleware.ParseInternalAuthPolicy(os.Getenv("INTERNAL_AUTH_POLICY_JSON"), os.Getenv("INTERNAL_AUTH_DEFAULT"))
+	if err != nil {
+		return nil, err
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -115,6 +134,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("JWT_SECRET is required")
 	}
 
+	if c.CursorSecret == "" {
+		return fmt.Errorf("CURSOR_SECRET is required")
+	}
+
 	if c.AppPort == "" {
 		return fmt.Errorf("APP_PORT is required")
 	}
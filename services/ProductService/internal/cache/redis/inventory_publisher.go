@@ -0,0 +1,67 @@
+package redisCache
+
+import (
+	"context"
+	"encoding/json"
+
+	redisClient "github.com/kareemhamed001/e-commerce/pkg/redis"
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/domain"
+)
+
+const (
+	// InventoryUpdateChannel is the Redis pub/sub channel stock changes are announced on
+	InventoryUpdateChannel = "inventory:update"
+	// LowStockChannel is the Redis pub/sub channel products falling to their low stock threshold are announced on
+	LowStockChannel = "inventory:low-stock"
+)
+
+var _ domain.InventoryPublisher = (*InventoryPublisher)(nil)
+
+type InventoryPublisher struct {
+	client *redisClient.Client
+}
+
+// InventoryUpdate is the JSON payload published on InventoryUpdateChannel
+type InventoryUpdate struct {
+	ProductID uint `json:"product_id"`
+	NewStock  int  `json:"new_stock"`
+}
+
+// LowStockAlert is the JSON payload published on LowStockChannel
+type LowStockAlert struct {
+	ProductID uint `json:"product_id"`
+	Stock     int  `json:"stock"`
+	Threshold int  `json:"threshold"`
+}
+
+func NewInventoryPublisher(client *redisClient.Client) *InventoryPublisher {
+	return &InventoryPublisher{client: client}
+}
+
+// PublishStockUpdate announces a product's new stock level to every subscriber
+func (p *InventoryPublisher) PublishStockUpdate(ctx context.Context, productID uint, stockLevel int) error {
+	if !p.client.IsEnabled() {
+		return nil
+	}
+
+	data, err := json.Marshal(InventoryUpdate{ProductID: productID, NewStock: stockLevel})
+	if err != nil {
+		return err
+	}
+
+	return p.client.Publish(ctx, InventoryUpdateChannel, data).Err()
+}
+
+// PublishLowStock announces that a product's stock fell to or below its threshold
+func (p *InventoryPublisher) PublishLowStock(ctx context.Context, productID uint, stockLevel, threshold int) error {
+	if !p.client.IsEnabled() {
+		return nil
+	}
+
+	data, err := json.Marshal(LowStockAlert{ProductID: productID, Stock: stockLevel, Threshold: threshold})
+	if err != nil {
+		return err
+	}
+
+	return p.client.Publish(ctx, LowStockChannel, data).Err()
+}
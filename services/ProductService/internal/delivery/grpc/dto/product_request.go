@@ -13,6 +13,12 @@ type CreateProductRequest struct {
 	Quantity          int     `json:"quantity" validate:"required,gte=0"`
 }
 
+type ListProductsRequest struct {
+	Page    int
+	PerPage int
+	Cursor  string
+}
+
 type UpdateProductRequest struct {
 	Name              *string  `json:"name" validate:"omitempty,min=2,max=100"`
 	ShortDescription  *string  `json:"short_description" validate:"omitempty,min=2,max=150"`
@@ -24,4 +30,5 @@ type UpdateProductRequest struct {
 	DiscountEndDate   *string  `json:"discount_end_date" validate:"omitempty,datetime=2006-01-02"`
 	ImageUrl          *string  `json:"image_url" validate:"omitempty,url"`
 	Quantity          *int     `json:"quantity" validate:"omitempty,gte=0"`
+	LowStockThreshold *int     `json:"low_stock_threshold" validate:"omitempty,gte=0"`
 }
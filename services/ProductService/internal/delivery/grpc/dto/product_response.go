@@ -1,13 +1,26 @@
 package dto
 
 type ProductResponse struct {
-	Id               uint    `json:"id"`
-	Name             string  `json:"name"`
-	ShortDescription *string `json:"short_description,omitempty"`
-	Description      string  `json:"description"`
-	Price            float32 `json:"price"`
-	DiscountType     string  `json:"discount_type"`
-	DiscountValue    float32 `json:"discount_value"`
-	ImageUrl         *string `json:"image_url,omitempty"`
-	Quantity         int     `json:"quantity"`
+	Id                uint    `json:"id"`
+	Name              string  `json:"name"`
+	ShortDescription  *string `json:"short_description,omitempty"`
+	Description       string  `json:"description"`
+	Price             float32 `json:"price"`
+	DiscountType      string  `json:"discount_type"`
+	DiscountValue     float32 `json:"discount_value"`
+	ImageUrl          *string `json:"image_url,omitempty"`
+	Quantity          int     `json:"quantity"`
+	LowStockThreshold int     `json:"low_stock_threshold"`
+}
+
+// ProductBatchResponse keeps the order ids were first requested in
+type ProductBatchResponse struct {
+	Products   []ProductResponse
+	MissingIDs []uint
+}
+
+type ProductListResponse struct {
+	Products   []ProductResponse
+	Total      int
+	NextCursor string
 }
@@ -0,0 +1,14 @@
+package dto
+
+type CreateReviewRequest struct {
+	ProductID uint   `json:"product_id" validate:"required,gt=0"`
+	UserID    uint   `json:"user_id" validate:"required,gt=0"`
+	Rating    int    `json:"rating" validate:"required,min=1,max=5"`
+	Comment   string `json:"comment" validate:"max=2000"`
+}
+
+type ListProductReviewsRequest struct {
+	ProductID uint `validate:"required,gt=0"`
+	Page      int
+	PerPage   int
+}
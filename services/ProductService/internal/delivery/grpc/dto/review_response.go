@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+type ReviewResponse struct {
+	Id        uint      `json:"id"`
+	ProductID uint      `json:"product_id"`
+	UserID    uint      `json:"user_id"`
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReviewListResponse struct {
+	Reviews       []ReviewResponse
+	Total         int
+	AverageRating float32
+}
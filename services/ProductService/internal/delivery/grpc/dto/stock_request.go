@@ -0,0 +1,17 @@
+package dto
+
+type AdjustStockRequest struct {
+	ProductID uint   `validate:"required,gt=0"`
+	Delta     int    `validate:"required"`
+	Reason    string `validate:"required,oneof=recount damage return correction order"`
+	// OrderID is required for order movements and refused for the others
+	OrderID uint `validate:"required_if=Reason order,excluded_unless=Reason order"`
+	// UserID is the user the call is made for, nil for a service acting on its own
+	UserID *uint
+}
+
+type ListStockMovementsRequest struct {
+	ProductID uint `validate:"required,gt=0"`
+	Page      int
+	PerPage   int
+}
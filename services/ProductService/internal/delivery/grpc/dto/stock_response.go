@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type StockMovementResponse struct {
+	Id         uint      `json:"id"`
+	ProductID  uint      `json:"product_id"`
+	Delta      int       `json:"delta"`
+	Reason     string    `json:"reason"`
+	OrderID    *uint     `json:"order_id,omitempty"`
+	UserID     *uint     `json:"user_id,omitempty"`
+	StockAfter int       `json:"stock_after"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type StockMovementListResponse struct {
+	Movements []StockMovementResponse
+	Total     int
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"fmt"
+
+	pb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// applyProductMask clears every product field the mask doesn't name. An empty mask keeps all fields.
+func applyProductMask(mask *fieldmaskpb.FieldMask, products ...*pb.Product) error {
+	if len(mask.GetPaths()) == 0 {
+		return nil
+	}
+	if !mask.IsValid(&pb.Product{}) {
+		return status.Error(grpccodes.InvalidArgument, fmt.Sprintf("invalid product field mask %v", mask.GetPaths()))
+	}
+
+	// Product has no nested messages, so every valid path is a top-level field name
+	keep := make(map[protoreflect.Name]bool, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		keep[protoreflect.Name(path)] = true
+	}
+	for _, product := range products {
+		message := product.ProtoReflect()
+		message.Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			if !keep[field.Name()] {
+				message.Clear(field)
+			}
+			return true
+		})
+	}
+	return nil
+}
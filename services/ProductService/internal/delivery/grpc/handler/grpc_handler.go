@@ -2,39 +2,49 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/delivery/grpc/dto"
 	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/repository"
 	pb "github.com/kareemhamed001/e-commerce/shared/proto/v1/product"
+	reviewpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/review"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// maxBatchProductIDs caps GetProductsByIDs so one call stays a single bounded IN query
+const maxBatchProductIDs = 100
+
 type ProductGRPCHandler struct {
 	pb.UnimplementedProductServiceServer
 	productUsecase  domain.ProductUsecase
 	categoryUsecase domain.CategoryUsecase
+	reviews         *ReviewGRPCHandler
 	validate        *validator.Validate
 	tracer          trace.Tracer
-	internalAuthToken string
 }
 
 var _ pb.ProductServiceServer = (*ProductGRPCHandler)(nil)
 
-func NewProductGRPCHandler(productUsecase domain.ProductUsecase, categoryUsecase domain.CategoryUsecase, validate *validator.Validate, internalAuthToken string) *ProductGRPCHandler {
+// NewProductGRPCHandler creates the product handler; Run also serves reviews on the same server
+func NewProductGRPCHandler(productUsecase domain.ProductUsecase, categoryUsecase domain.CategoryUsecase, reviews *ReviewGRPCHandler, validate *validator.Validate) *ProductGRPCHandler {
 	return &ProductGRPCHandler{
 		productUsecase:  productUsecase,
 		categoryUsecase: categoryUsecase,
+		reviews:         reviews,
 		validate:        validate,
 		tracer:          otel.Tracer("product_GRPC_handler"),
-		internalAuthToken: internalAuthToken,
 	}
 }
 
@@ -140,8 +150,24 @@ func (h *ProductGRPCHandler) GetProductByID(ctx context.Context, req *pb.GetProd
 		Quantity:         int32(product.Quantity),
 	}
 
+	// Ratings change with every review, so they are read fresh rather than cached with the product
+	rating, err := h.reviews.reviewUsecase.GetProductRating(reqCtx, uint(id))
+	if err != nil {
+		span.RecordError(err)
+		logger.Warnf("Failed to load rating for product %d: %v", id, err)
+	} else {
+		productResponse.AverageRating = rating.Average
+		productResponse.ReviewCount = int32(rating.Count)
+	}
+
 	span.SetAttributes(attribute.String("product.response", productResponse.String()))
 
+	if err := applyProductMask(req.GetFields(), productResponse); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	span.SetStatus(codes.Ok, "Product retrieved successfully")
 
 	return &pb.GetProductByIDResponse{
@@ -149,6 +175,72 @@ func (h *ProductGRPCHandler) GetProductByID(ctx context.Context, req *pb.GetProd
 	}, nil
 }
 
+func (h *ProductGRPCHandler) GetProductsByIDs(ctx context.Context, req *pb.GetProductsByIDsRequest) (*pb.GetProductsByIDsResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "ProductHandler.GetProductsByIDs")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("product.ids.count", len(req.GetIds())))
+
+	if len(req.GetIds()) == 0 || len(req.GetIds()) > maxBatchProductIDs {
+		err := status.Errorf(grpccodes.InvalidArgument, "between 1 and %d ids are required", maxBatchProductIDs)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(req.GetIds()))
+	for _, id := range req.GetIds() {
+		if id <= 0 {
+			err := status.Errorf(grpccodes.InvalidArgument, "invalid product id %d", id)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		ids = append(ids, uint(id))
+	}
+
+	batch, err := h.productUsecase.GetProductsByIDs(reqCtx, ids)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	products := make([]*pb.Product, 0, len(batch.Products))
+	for _, p := range batch.Products {
+		products = append(products, &pb.Product{
+			Id:               int32(p.Id),
+			Name:             p.Name,
+			ShortDescription: *p.ShortDescription,
+			Description:      p.Description,
+			Price:            p.Price,
+			DiscountType:     string(p.DiscountType),
+			DiscountValue:    p.DiscountValue,
+			ImageUrl:         *p.ImageUrl,
+			Quantity:         int32(p.Quantity),
+		})
+	}
+
+	if err := applyProductMask(req.GetFields(), products...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	missingIDs := make([]int64, 0, len(batch.MissingIDs))
+	for _, id := range batch.MissingIDs {
+		missingIDs = append(missingIDs, int64(id))
+	}
+
+	span.SetAttributes(attribute.Int("products.missing", len(missingIDs)))
+	span.SetStatus(codes.Ok, "Products retrieved successfully")
+
+	return &pb.GetProductsByIDsResponse{
+		Products:   products,
+		MissingIds: missingIDs,
+	}, nil
+}
+
 func (h *ProductGRPCHandler) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
 	// Implementation here
 
@@ -169,20 +261,27 @@ func (h *ProductGRPCHandler) ListProducts(ctx context.Context, req *pb.ListProdu
 		attribute.Int("pagination.limit", limit),
 	)
 
-	products, total, err := h.productUsecase.ListProducts(reqCtx, page, limit)
+	list, err := h.productUsecase.ListProducts(reqCtx, &dto.ListProductsRequest{
+		Page:    page,
+		PerPage: limit,
+		Cursor:  req.GetCursor(),
+	})
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+		}
 		return nil, err
 	}
 
-	span.SetAttributes(attribute.Int("products.count", len(products)))
-	span.SetAttributes(attribute.Int("products.total", total))
+	span.SetAttributes(attribute.Int("products.count", len(list.Products)))
+	span.SetAttributes(attribute.Int("products.total", list.Total))
 
-	productResponse := make([]*pb.Product, 0, len(products))
+	productResponse := make([]*pb.Product, 0, len(list.Products))
 
-	for _, p := range products {
+	for _, p := range list.Products {
 		productResponse = append(productResponse, &pb.Product{
 			Id:               int32(p.Id),
 			Name:             p.Name,
@@ -196,11 +295,18 @@ func (h *ProductGRPCHandler) ListProducts(ctx context.Context, req *pb.ListProdu
 		})
 	}
 
+	if err := applyProductMask(req.GetFields(), productResponse...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	span.SetStatus(codes.Ok, "Products retrieved successfully")
 
 	return &pb.ListProductsResponse{
 		Products:   productResponse,
-		TotalCount: int32(total),
+		TotalCount: int32(list.Total),
+		NextCursor: list.NextCursor,
 	}, nil
 }
 
@@ -239,6 +345,10 @@ func (h *ProductGRPCHandler) UpdateProduct(ctx context.Context, req *pb.UpdatePr
 		ImageUrl:         &imageUrl,
 		Quantity:         &quantity,
 	}
+	if req.LowStockThreshold != nil {
+		threshold := int(req.GetLowStockThreshold())
+		productRequest.LowStockThreshold = &threshold
+	}
 
 	_, validationSpan := h.tracer.Start(reqCtx, "ProductHandler.ValidateUpdateProduct")
 	if err := h.validate.Struct(&productRequest); err != nil {
@@ -274,15 +384,16 @@ func (h *ProductGRPCHandler) UpdateProduct(ctx context.Context, req *pb.UpdatePr
 	span.SetStatus(codes.Ok, "Product updated successfully")
 	return &pb.UpdateProductResponse{
 		Product: &pb.Product{
-			Id:               int32(productResponse.Id),
-			Name:             productResponse.Name,
-			ShortDescription: *productResponse.ShortDescription,
-			Description:      productResponse.Description,
-			Price:            productResponse.Price,
-			DiscountType:     string(productResponse.DiscountType),
-			DiscountValue:    productResponse.DiscountValue,
-			ImageUrl:         *productResponse.ImageUrl,
-			Quantity:         int32(productResponse.Quantity),
+			Id:                int32(productResponse.Id),
+			Name:              productResponse.Name,
+			ShortDescription:  *productResponse.ShortDescription,
+			Description:       productResponse.Description,
+			Price:             productResponse.Price,
+			DiscountType:      string(productResponse.DiscountType),
+			DiscountValue:     productResponse.DiscountValue,
+			ImageUrl:          *productResponse.ImageUrl,
+			Quantity:          int32(productResponse.Quantity),
+			LowStockThreshold: int32(productResponse.LowStockThreshold),
 		},
 	}, nil
 }
@@ -306,7 +417,190 @@ func (h *ProductGRPCHandler) DeleteProduct(ctx context.Context, req *pb.DeletePr
 	}, nil
 }
 
-// CreateCategory(context.Context, *CreateCategoryRequest) (*CreateCategoryResponse, error)
+// AdjustProductStock records the user the call is made for as the one who made the movement. Calls
+// without a reason predate reasons and are recorded as corrections.
+func (h *ProductGRPCHandler) AdjustProductStock(ctx context.Context, req *pb.AdjustProductStockRequest) (*pb.AdjustProductStockResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "ProductHandler.AdjustProductStock")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("product.id", int(req.GetProductId())),
+		attribute.Int("product.stock_delta", int(req.GetDelta())),
+	)
+
+	adjustReq := dto.AdjustStockRequest{
+		ProductID: uint(req.GetProductId()),
+		Delta:     int(req.GetDelta()),
+		Reason:    req.GetReason(),
+		OrderID:   uint(req.GetOrderId()),
+	}
+	if adjustReq.Reason == "" {
+		adjustReq.Reason = string(domain.StockReasonCorrection)
+	}
+	if userID, ok := grpcmiddleware.UserIDFromContext(ctx); ok {
+		adjustReq.UserID = &userID
+	}
+	if req.GetProductId() <= 0 || req.GetOrderId() < 0 {
+		err := status.Error(grpccodes.InvalidArgument, "product_id must be positive and order_id must not be negative")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if err := h.validate.Struct(&adjustReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	movement, err := h.productUsecase.AdjustProductStock(reqCtx, &adjustReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		case errors.Is(err, repository.ErrInsufficientStock):
+			return nil, status.Error(grpccodes.FailedPrecondition, err.Error())
+		}
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("product.quantity", movement.StockAfter))
+	span.SetStatus(codes.Ok, "Product stock adjusted successfully")
+
+	return &pb.AdjustProductStockResponse{
+		ProductId:  req.GetProductId(),
+		StockLevel: int32(movement.StockAfter),
+		Movement:   mapStockMovementToPB(movement),
+	}, nil
+}
+
+func (h *ProductGRPCHandler) ListStockMovements(ctx context.Context, req *pb.ListStockMovementsRequest) (*pb.ListStockMovementsResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "ProductHandler.ListStockMovements")
+	defer span.End()
+
+	page := int(req.GetPage())
+	if page == 0 {
+		page = 1
+	}
+	limit := int(req.GetPerPage())
+	if limit == 0 {
+		limit = 10
+	}
+	if req.GetProductId() <= 0 || page < 0 || limit < 0 || limit > 100 {
+		err := status.Error(grpccodes.InvalidArgument, "product_id and page must be positive and per_page between 1 and 100")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("product.id", int(req.GetProductId())),
+		attribute.Int("pagination.page", page),
+		attribute.Int("pagination.limit", limit),
+	)
+
+	list, err := h.productUsecase.ListStockMovements(reqCtx, &dto.ListStockMovementsRequest{
+		ProductID: uint(req.GetProductId()),
+		Page:      page,
+		PerPage:   limit,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	movements := make([]*pb.StockMovement, 0, len(list.Movements))
+	for i := range list.Movements {
+		movements = append(movements, mapStockMovementToPB(&list.Movements[i]))
+	}
+
+	span.SetAttributes(attribute.Int("stock_movements.count", len(movements)))
+	span.SetStatus(codes.Ok, "Stock movements listed")
+	return &pb.ListStockMovementsResponse{
+		Movements:  movements,
+		TotalCount: int32(list.Total),
+	}, nil
+}
+
+func mapStockMovementToPB(movement *dto.StockMovementResponse) *pb.StockMovement {
+	response := &pb.StockMovement{
+		Id:         int64(movement.Id),
+		ProductId:  int64(movement.ProductID),
+		Delta:      int32(movement.Delta),
+		Reason:     movement.Reason,
+		StockAfter: int32(movement.StockAfter),
+		CreatedAt:  movement.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if movement.OrderID != nil {
+		response.OrderId = int64(*movement.OrderID)
+	}
+	if movement.UserID != nil {
+		response.UserId = int64(*movement.UserID)
+	}
+	return response
+}
+
+func (h *ProductGRPCHandler) ListLowStockProducts(ctx context.Context, req *pb.ListLowStockProductsRequest) (*pb.ListLowStockProductsResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "ProductHandler.ListLowStockProducts")
+	defer span.End()
+
+	page := int(req.GetPage())
+	if page == 0 {
+		page = 1
+	}
+	limit := int(req.GetPerPage())
+	if limit == 0 {
+		limit = 10
+	}
+	if page < 0 || limit < 0 || limit > 100 {
+		err := status.Error(grpccodes.InvalidArgument, "page must be positive and per_page between 1 and 100")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("pagination.page", page),
+		attribute.Int("pagination.limit", limit),
+	)
+
+	list, err := h.productUsecase.ListLowStockProducts(reqCtx, page, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	products := make([]*pb.Product, 0, len(list.Products))
+	for _, p := range list.Products {
+		products = append(products, &pb.Product{
+			Id:                int32(p.Id),
+			Name:              p.Name,
+			ShortDescription:  *p.ShortDescription,
+			Description:       p.Description,
+			Price:             p.Price,
+			DiscountType:      p.DiscountType,
+			DiscountValue:     p.DiscountValue,
+			ImageUrl:          *p.ImageUrl,
+			Quantity:          int32(p.Quantity),
+			LowStockThreshold: int32(p.LowStockThreshold),
+		})
+	}
+
+	span.SetAttributes(attribute.Int("products.count", len(products)))
+	span.SetStatus(codes.Ok, "Low stock products listed")
+	return &pb.ListLowStockProductsResponse{
+		Products:   products,
+		TotalCount: int32(list.Total),
+	}, nil
+}
+
 func (h *ProductGRPCHandler) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.CreateCategoryResponse, error) {
 	ctx, span := h.tracer.Start(ctx, "ProductHandler.CreateCategory")
 	defer span.End()
@@ -485,15 +779,16 @@ func (h *ProductGRPCHandler) DeleteCategory(ctx context.Context, req *pb.DeleteC
 	}, nil
 }
 
-func (h *ProductGRPCHandler) Run(done <-chan any, port string) error {
+func (h *ProductGRPCHandler) Run(done <-chan any, port string, opts ...grpc.ServerOption) error {
 	// Implementation here
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		logger.Errorf("Error while starting product grpc server: %v", err)
 		return err
 	}
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(grpcmiddleware.InternalAuthUnaryServerInterceptor(h.internalAuthToken)))
+	grpcServer := grpc.NewServer(opts...)
 	pb.RegisterProductServiceServer(grpcServer, h)
+	reviewpb.RegisterReviewServiceServer(grpcServer, h.reviews)
 
 	go func() {
 		logger.Infof("Product gRPC server is running on port %s", port)
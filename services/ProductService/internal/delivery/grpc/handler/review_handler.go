@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/repository"
+	reviewpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/review"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReviewGRPCHandler serves ReviewService on the product service's gRPC server
+type ReviewGRPCHandler struct {
+	reviewpb.UnimplementedReviewServiceServer
+	reviewUsecase domain.ReviewUsecase
+	validate      *validator.Validate
+	tracer        trace.Tracer
+}
+
+var _ reviewpb.ReviewServiceServer = (*ReviewGRPCHandler)(nil)
+
+func NewReviewGRPCHandler(reviewUsecase domain.ReviewUsecase, validate *validator.Validate) *ReviewGRPCHandler {
+	return &ReviewGRPCHandler{
+		reviewUsecase: reviewUsecase,
+		validate:      validate,
+		tracer:        otel.Tracer("review_GRPC_handler"),
+	}
+}
+
+func (h *ReviewGRPCHandler) CreateReview(ctx context.Context, req *reviewpb.CreateReviewRequest) (*reviewpb.CreateReviewResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "ReviewHandler.CreateReview")
+	defer span.End()
+
+	reviewDto := dto.CreateReviewRequest{
+		ProductID: uint(req.GetProductId()),
+		UserID:    uint(req.GetUserId()),
+		Rating:    int(req.GetRating()),
+		Comment:   req.GetComment(),
+	}
+	if err := h.validate.Struct(&reviewDto); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	review, err := h.reviewUsecase.CreateReview(reqCtx, &reviewDto)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, reviewError(err)
+	}
+
+	span.SetAttributes(attribute.Int("review.id", int(review.Id)))
+	span.SetStatus(codes.Ok, "Review created successfully")
+	return &reviewpb.CreateReviewResponse{Review: mapReviewToPB(review)}, nil
+}
+
+func (h *ReviewGRPCHandler) ListProductReviews(ctx context.Context, req *reviewpb.ListProductReviewsRequest) (*reviewpb.ListProductReviewsResponse, error) {
+	reqCtx, span := h.tracer.Start(ctx, "ReviewHandler.ListProductReviews")
+	defer span.End()
+
+	page := int(req.GetPage())
+	if page == 0 {
+		page = 1
+	}
+	perPage := int(req.GetPerPage())
+	if perPage == 0 {
+		perPage = 10
+	}
+
+	listReq := dto.ListProductReviewsRequest{
+		ProductID: uint(req.GetProductId()),
+		Page:      page,
+		PerPage:   perPage,
+	}
+	if err := h.validate.Struct(&listReq); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	list, err := h.reviewUsecase.ListProductReviews(reqCtx, &listReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, reviewError(err)
+	}
+
+	reviews := make([]*reviewpb.Review, 0, len(list.Reviews))
+	for i := range list.Reviews {
+		reviews = append(reviews, mapReviewToPB(&list.Reviews[i]))
+	}
+
+	span.SetAttributes(attribute.Int("reviews.count", len(reviews)))
+	span.SetStatus(codes.Ok, "Reviews retrieved successfully")
+	return &reviewpb.ListProductReviewsResponse{
+		Reviews:       reviews,
+		TotalCount:    int32(list.Total),
+		AverageRating: list.AverageRating,
+	}, nil
+}
+
+// reviewError gives the gateway a status code it can map to 404 or 409
+func reviewError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrProductNotFound):
+		return status.Error(grpccodes.NotFound, err.Error())
+	case errors.Is(err, repository.ErrReviewExists):
+		return status.Error(grpccodes.AlreadyExists, err.Error())
+	}
+	return err
+}
+
+func mapReviewToPB(review *dto.ReviewResponse) *reviewpb.Review {
+	return &reviewpb.Review{
+		Id:        int64(review.Id),
+		ProductId: int64(review.ProductID),
+		UserId:    int64(review.UserID),
+		Rating:    int32(review.Rating),
+		Comment:   review.Comment,
+		CreatedAt: review.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
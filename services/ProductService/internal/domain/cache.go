@@ -12,3 +12,8 @@ type ProductCache interface {
 	SetProduct(ctx context.Context, product *dto.ProductResponse, ttl time.Duration) error
 	DeleteProduct(ctx context.Context, id uint) error
 }
+
+type InventoryPublisher interface {
+	PublishStockUpdate(ctx context.Context, productID uint, stockLevel int) error
+	PublishLowStock(ctx context.Context, productID uint, stockLevel, threshold int) error
+}
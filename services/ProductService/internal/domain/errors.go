@@ -6,4 +6,5 @@ var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrHashingPassword    = errors.New("error hashing password")
+	ErrInvalidCursor      = errors.New("invalid cursor")
 )
@@ -18,4 +18,12 @@ type Product struct {
 	DiscountEndDate   *time.Time   `json:"discount_end_date"`
 	ImageUrl          *string      `json:"image_url"`
 	Quantity          int          `json:"quantity"`
+	// LowStockThreshold is the quantity at or below which the product is low on stock
+	LowStockThreshold int `json:"low_stock_threshold" gorm:"not null;default:0"`
+}
+
+// StockLevel is a product's quantity right after a stock adjustment, with its low stock threshold
+type StockLevel struct {
+	Quantity          int
+	LowStockThreshold int
 }
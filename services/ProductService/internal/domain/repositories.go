@@ -10,7 +10,12 @@ type ProductRepository interface {
 	GetProductsByIDs(ctx context.Context, ids []uint) ([]Product, error)
 	UpdateProduct(ctx context.Context, id uint, product *Product) error
 	ListProducts(ctx context.Context, page, perPage int) ([]Product, int, error)
+	ListProductsAfter(ctx context.Context, afterID uint, limit int) ([]Product, int, error)
 	DeleteProduct(ctx context.Context, id uint) error
+	AdjustProductStock(ctx context.Context, movement *StockMovement) (*StockLevel, error)
+	ListStockMovements(ctx context.Context, productID uint, page, perPage int) ([]StockMovement, int, error)
+	SetLowStockThreshold(ctx context.Context, id uint, threshold int) error
+	ListLowStockProducts(ctx context.Context, page, perPage int) ([]Product, int, error)
 }
 
 type CategoryRepository interface {
@@ -20,3 +25,9 @@ type CategoryRepository interface {
 	ListCategories(ctx context.Context, page, perPage int) ([]Category, int, error)
 	DeleteCategory(ctx context.Context, id uint) error
 }
+
+type ReviewRepository interface {
+	CreateReview(ctx context.Context, review *Review) error
+	ListProductReviews(ctx context.Context, productID uint, page, perPage int) ([]Review, int, error)
+	GetProductRating(ctx context.Context, productID uint) (*ProductRating, error)
+}
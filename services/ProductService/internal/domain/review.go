@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+type Review struct {
+	ID        uint   `gorm:"primarykey"`
+	ProductID uint   `json:"product_id"`
+	UserID    uint   `json:"user_id"`
+	Rating    int    `json:"rating"`
+	Comment   string `json:"comment"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ProductRating summarises every review of one product
+type ProductRating struct {
+	Average float32
+	Count   int
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+type StockMovementReason string
+
+const (
+	StockReasonRecount    StockMovementReason = "recount"
+	StockReasonDamage     StockMovementReason = "damage"
+	StockReasonReturn     StockMovementReason = "return"
+	StockReasonCorrection StockMovementReason = "correction"
+	// StockReasonOrder is stock sold or released by an order, recorded with its OrderID
+	StockReasonOrder StockMovementReason = "order"
+)
+
+func ValidStockMovementReasons() []StockMovementReason {
+	return []StockMovementReason{
+		StockReasonRecount,
+		StockReasonDamage,
+		StockReasonReturn,
+		StockReasonCorrection,
+		StockReasonOrder,
+	}
+}
+
+func (r StockMovementReason) IsValid() bool {
+	for _, valid := range ValidStockMovementReasons() {
+		if r == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// StockMovement is one change to a product's stock, kept as its history
+type StockMovement struct {
+	ID        uint                `gorm:"primarykey"`
+	ProductID uint                `json:"product_id"`
+	Delta     int                 `json:"delta"`
+	Reason    StockMovementReason `json:"reason"`
+	// OrderID is only set for StockReasonOrder
+	OrderID *uint `json:"order_id"`
+	// UserID is the user who made the change, nil when a service made it on its own
+	UserID *uint `json:"user_id"`
+	// StockAfter is the product's quantity right after the change
+	StockAfter int `json:"stock_after"`
+	CreatedAt  time.Time
+}
@@ -9,10 +9,14 @@ import (
 type ProductUsecase interface {
 	CreateProduct(ctx context.Context, product *dto.CreateProductRequest) (*dto.ProductResponse, error)
 	GetProductByID(ctx context.Context, id uint) (*dto.ProductResponse, error)
-	ListProducts(ctx context.Context, page, perPage int) ([]dto.ProductResponse, int, error)
+	GetProductsByIDs(ctx context.Context, ids []uint) (*dto.ProductBatchResponse, error)
+	ListProducts(ctx context.Context, req *dto.ListProductsRequest) (*dto.ProductListResponse, error)
 	UpdateProduct(ctx context.Context, id uint, product *dto.UpdateProductRequest) (*dto.ProductResponse, error)
 	DeleteProduct(ctx context.Context, id uint) error
 	RestockProduct(ctx context.Context, id uint, quantity int) error
+	AdjustProductStock(ctx context.Context, req *dto.AdjustStockRequest) (*dto.StockMovementResponse, error)
+	ListStockMovements(ctx context.Context, req *dto.ListStockMovementsRequest) (*dto.StockMovementListResponse, error)
+	ListLowStockProducts(ctx context.Context, page, perPage int) (*dto.ProductListResponse, error)
 }
 
 type CategoryUsecase interface {
@@ -22,3 +26,9 @@ type CategoryUsecase interface {
 	UpdateCategory(ctx context.Context, id uint, category *dto.UpdateCategoryRequest) error
 	DeleteCategory(ctx context.Context, id uint) error
 }
+
+type ReviewUsecase interface {
+	CreateReview(ctx context.Context, review *dto.CreateReviewRequest) (*dto.ReviewResponse, error)
+	ListProductReviews(ctx context.Context, req *dto.ListProductReviewsRequest) (*dto.ReviewListResponse, error)
+	GetProductRating(ctx context.Context, productID uint) (*ProductRating, error)
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+create table reviews (
+    id serial primary key,
+    product_id int not null references products(id) on delete cascade,
+    user_id int not null,
+    rating smallint not null check (rating between 1 and 5),
+    comment text not null default '',
+    created_at timestamp with time zone default current_timestamp,
+    updated_at timestamp with time zone default current_timestamp,
+    unique (product_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table reviews;
+-- +goose StatementEnd
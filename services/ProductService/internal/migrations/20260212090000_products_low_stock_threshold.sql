@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table products add column low_stock_threshold int not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table products drop column low_stock_threshold;
+-- +goose StatementEnd
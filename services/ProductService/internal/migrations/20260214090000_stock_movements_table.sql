@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+create table stock_movements (
+    id serial primary key,
+    product_id int not null references products(id) on delete cascade,
+    delta int not null check (delta <> 0),
+    reason varchar(20) not null,
+    order_id int,
+    user_id int,
+    stock_after int not null check (stock_after >= 0),
+    created_at timestamp with time zone default current_timestamp
+);
+create index idx_stock_movements_product_id on stock_movements (product_id, created_at desc, id desc);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table stock_movements;
+-- +goose StatementEnd
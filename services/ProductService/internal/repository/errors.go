@@ -9,4 +9,6 @@ var (
 	ErrDatabaseQuery       = errors.New("database query failed")
 	ErrForeignKeyViolation = errors.New("related record not found")
 	ErrInvalidData         = errors.New("invalid data provided")
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrReviewExists        = errors.New("user has already reviewed this product")
 )
@@ -119,7 +119,37 @@ func (r *ProductRepository) ListProducts(ctx context.Context, page, perPage int)
 		attribute.Int("query.per_page", perPage),
 	)
 
-	products, err := gorm.G[domain.Product](r.db).Offset((page - 1) * perPage).Limit(perPage).Find(ctx)
+	products, err := gorm.G[domain.Product](r.db).Order("id asc").Offset((page - 1) * perPage).Limit(perPage).Find(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, mapPostgresError(err)
+	}
+
+	totalCount, err := gorm.G[domain.Product](r.db).Count(ctx, "*")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, mapPostgresError(err)
+	}
+
+	span.SetAttributes(attribute.Int("products.count", len(products)))
+	span.SetStatus(codes.Ok, "products listed")
+	return products, int(totalCount), nil
+}
+
+// ListProductsAfter returns up to limit products with an ID greater than afterID, in ID order.
+// Unlike ListProducts it seeks on the primary key, so deep pages cost the same as the first.
+func (r *ProductRepository) ListProductsAfter(ctx context.Context, afterID uint, limit int) ([]domain.Product, int, error) {
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.ListProductsAfter")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("query.after_id", int(afterID)),
+		attribute.Int("query.limit", limit),
+	)
+
+	products, err := gorm.G[domain.Product](r.db).Where("id > ?", afterID).Order("id asc").Limit(limit).Find(ctx)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -158,3 +188,144 @@ func (r *ProductRepository) DeleteProduct(ctx context.Context, id uint) error {
 	span.SetStatus(codes.Ok, "product deleted")
 	return nil
 }
+
+// AdjustProductStock adds movement.Delta to the product's quantity in a single conditional UPDATE, so
+// concurrent adjustments can't drive stock below zero, and records movement in the same transaction with
+// the quantity it left. It returns the new quantity.
+func (r *ProductRepository) AdjustProductStock(ctx context.Context, movement *domain.StockMovement) (*domain.StockLevel, error) {
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.AdjustProductStock")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("product.id", int(movement.ProductID)),
+		attribute.Int("product.stock_delta", movement.Delta),
+		attribute.String("product.stock_reason", string(movement.Reason)),
+	)
+
+	var updated domain.StockLevel
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Raw(
+			"UPDATE products SET quantity = quantity + ?, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL AND quantity + ? >= 0 RETURNING quantity, low_stock_threshold",
+			movement.Delta, movement.ProductID, movement.Delta,
+		).Scan(&updated)
+		if result.Error != nil {
+			return mapPostgresError(result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			// Nothing matched: either the product is gone or the delta would take stock negative
+			if _, err := r.GetProductByID(ctx, movement.ProductID); err != nil {
+				return err
+			}
+			return repository.ErrInsufficientStock
+		}
+
+		movement.StockAfter = updated.Quantity
+		if err := tx.Create(movement).Error; err != nil {
+			return mapPostgresError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("product.quantity", updated.Quantity),
+		attribute.Int("stock_movement.id", int(movement.ID)),
+	)
+	span.SetStatus(codes.Ok, "product stock adjusted")
+	return &updated, nil
+}
+
+// ListStockMovements pages through a product's stock movements, the newest first
+func (r *ProductRepository) ListStockMovements(ctx context.Context, productID uint, page, perPage int) ([]domain.StockMovement, int, error) {
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.ListStockMovements")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("product.id", int(productID)),
+		attribute.Int("query.page", page),
+		attribute.Int("query.per_page", perPage),
+	)
+
+	movements, err := gorm.G[domain.StockMovement](r.db).Where("product_id = ?", productID).Order("created_at desc, id desc").Offset((page - 1) * perPage).Limit(perPage).Find(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, mapPostgresError(err)
+	}
+
+	totalCount, err := gorm.G[domain.StockMovement](r.db).Where("product_id = ?", productID).Count(ctx, "*")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, mapPostgresError(err)
+	}
+
+	span.SetAttributes(attribute.Int("stock_movements.count", len(movements)))
+	span.SetStatus(codes.Ok, "stock movements listed")
+	return movements, int(totalCount), nil
+}
+
+// SetLowStockThreshold is apart from UpdateProduct, whose Updates skips zero fields and so could not clear
+// a threshold
+func (r *ProductRepository) SetLowStockThreshold(ctx context.Context, id uint, threshold int) error {
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.SetLowStockThreshold")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("product.id", int(id)),
+		attribute.Int("product.low_stock_threshold", threshold),
+	)
+
+	result := r.db.WithContext(ctx).Model(&domain.Product{}).Where("id = ?", id).Update("low_stock_threshold", threshold)
+	if result.Error != nil {
+		span.RecordError(result.Error)
+		span.SetStatus(codes.Error, result.Error.Error())
+		return mapPostgresError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		span.SetStatus(codes.Error, repository.ErrProductNotFound.Error())
+		return repository.ErrProductNotFound
+	}
+
+	span.SetStatus(codes.Ok, "low stock threshold set")
+	return nil
+}
+
+// ListLowStockProducts pages through the products at or below their low stock threshold, the lowest stock
+// first and, among equal stock, the furthest below the threshold
+func (r *ProductRepository) ListLowStockProducts(ctx context.Context, page, perPage int) ([]domain.Product, int, error) {
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.ListLowStockProducts")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("query.page", page),
+		attribute.Int("query.per_page", perPage),
+	)
+
+	products, err := gorm.G[domain.Product](r.db).
+		Where("quantity <= low_stock_threshold").
+		Order("quantity asc, low_stock_threshold - quantity desc, id asc").
+		Offset((page - 1) * perPage).Limit(perPage).
+		Find(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, mapPostgresError(err)
+	}
+
+	totalCount, err := gorm.G[domain.Product](r.db).Where("quantity <= low_stock_threshold").Count(ctx, "*")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, mapPostgresError(err)
+	}
+
+	span.SetAttributes(attribute.Int("products.count", len(products)))
+	span.SetStatus(codes.Ok, "low stock products listed")
+	return products, int(totalCount), nil
+}
@@ -0,0 +1,106 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/repository"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+type ReviewRepository struct {
+	db     *gorm.DB
+	tracer trace.Tracer
+}
+
+var _ domain.ReviewRepository = (*ReviewRepository)(nil)
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{
+		db:     db,
+		tracer: otel.Tracer("review-repo"),
+	}
+}
+
+// CreateReview relies on the (product_id, user_id) unique index to reject a second review,
+// so two concurrent submissions can't both succeed
+func (r *ReviewRepository) CreateReview(ctx context.Context, review *domain.Review) error {
+	ctx, span := r.tracer.Start(ctx, "ReviewRepository.CreateReview")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("review.product_id", int(review.ProductID)),
+		attribute.Int("review.user_id", int(review.UserID)),
+	)
+
+	if err := gorm.G[domain.Review](r.db).Create(ctx, review); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return repository.ErrReviewExists
+		}
+		return mapPostgresError(err)
+	}
+
+	span.SetAttributes(attribute.Int("review.id", int(review.ID)))
+	span.SetStatus(codes.Ok, "review created")
+	return nil
+}
+
+func (r *ReviewRepository) ListProductReviews(ctx context.Context, productID uint, page, perPage int) ([]domain.Review, int, error) {
+	ctx, span := r.tracer.Start(ctx, "ReviewRepository.ListProductReviews")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("review.product_id", int(productID)),
+		attribute.Int("query.page", page),
+		attribute.Int("query.per_page", perPage),
+	)
+
+	reviews, err := gorm.G[domain.Review](r.db).Where("product_id = ?", productID).Order("created_at desc, id desc").Offset((page - 1) * perPage).Limit(perPage).Find(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, mapPostgresError(err)
+	}
+
+	totalCount, err := gorm.G[domain.Review](r.db).Where("product_id = ?", productID).Count(ctx, "*")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, mapPostgresError(err)
+	}
+
+	span.SetAttributes(attribute.Int("reviews.count", len(reviews)))
+	span.SetStatus(codes.Ok, "reviews listed")
+	return reviews, int(totalCount), nil
+}
+
+func (r *ReviewRepository) GetProductRating(ctx context.Context, productID uint) (*domain.ProductRating, error) {
+	ctx, span := r.tracer.Start(ctx, "ReviewRepository.GetProductRating")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("review.product_id", int(productID)))
+
+	var rating domain.ProductRating
+	if err := r.db.WithContext(ctx).Model(&domain.Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("product_id = ?", productID).
+		Scan(&rating).Error; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, mapPostgresError(err)
+	}
+
+	span.SetAttributes(attribute.Int("reviews.count", rating.Count))
+	span.SetStatus(codes.Ok, "rating aggregated")
+	return &rating, nil
+}
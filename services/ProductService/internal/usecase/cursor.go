@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/domain"
+)
+
+// productSortKey is the only ordering ListProducts supports. It is embedded in every cursor so
+// cursors issued today are rejected, not misread, if another ordering is added later.
+const productSortKey = "id"
+
+// productCursor marks the position just after the last product of a page
+type productCursor struct {
+	SortKey string `json:"k"`
+	LastID  uint   `json:"id"`
+}
+
+// encodeCursor returns base64url(payload) + "." + base64url(HMAC-SHA256(payload)).
+// The signature is what lets decodeCursor reject cursors a client has edited.
+func (u *ProductUsecase) encodeCursor(lastID uint) string {
+	payload, _ := json.Marshal(productCursor{SortKey: productSortKey, LastID: lastID})
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(u.signCursor(payload))
+}
+
+// decodeCursor verifies a cursor from encodeCursor and returns the last-seen product ID
+func (u *ProductUsecase) decodeCursor(cursor string) (uint, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return 0, domain.ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return 0, domain.ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, u.signCursor(payload)) {
+		return 0, domain.ErrInvalidCursor
+	}
+
+	var decoded productCursor
+	if err := json.Unmarshal(payload, &decoded); err != nil || decoded.SortKey != productSortKey || decoded.LastID == 0 {
+		return 0, domain.ErrInvalidCursor
+	}
+	return decoded.LastID, nil
+}
+
+func (u *ProductUsecase) signCursor(payload []byte) []byte {
+	mac := hmac.New(sha256.New, u.cursorSecret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/domain"
+)
+
+// pagedRepo serves the page after a cursor and records where it was asked to start; the methods it does
+// not implement panic through the nil interface, so a rejected cursor must never reach it
+type pagedRepo struct {
+	domain.ProductRepository
+	afterIDs []uint
+}
+
+func (r *pagedRepo) ListProductsAfter(_ context.Context, afterID uint, limit int) ([]domain.Product, int, error) {
+	r.afterIDs = append(r.afterIDs, afterID)
+	products := make([]domain.Product, limit)
+	for i := range products {
+		products[i].ID = afterID + uint(i) + 1
+	}
+	return products, 100, nil
+}
+
+func TestListProductsRejectsTamperedCursors(t *testing.T) {
+	u := NewProductUsecase(&pagedRepo{}, noCache{}, &inventoryEvents{}, "secret")
+	valid := u.encodeCursor(5)
+	encodedPayload, encodedSignature, _ := strings.Cut(valid, ".")
+
+	payload, _ := base64.RawURLEncoding.DecodeString(encodedPayload)
+	flipped := []byte(strings.Replace(string(payload), `"id":5`, `"id":9`, 1))
+	otherSortKey := []byte(`{"k":"price","id":5}`)
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "flipped payload byte", cursor: base64.RawURLEncoding.EncodeToString(flipped) + "." + encodedSignature},
+		{name: "signed with another secret", cursor: NewProductUsecase(nil, nil, nil, "other-secret").encodeCursor(5)},
+		{name: "wrong sort key", cursor: base64.RawURLEncoding.EncodeToString(otherSortKey) + "." + base64.RawURLEncoding.EncodeToString(u.signCursor(otherSortKey))},
+		{name: "no separator", cursor: encodedPayload + encodedSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := &pagedRepo{}
+			u := NewProductUsecase(products, noCache{}, &inventoryEvents{}, "secret")
+
+			if _, err := u.ListProducts(context.Background(), &dto.ListProductsRequest{Cursor: tt.cursor, PerPage: 10}); !errors.Is(err, domain.ErrInvalidCursor) {
+				t.Fatalf("ListProducts = %v, want %v", err, domain.ErrInvalidCursor)
+			}
+			if len(products.afterIDs) > 0 {
+				t.Errorf("a rejected cursor read the page after %v", products.afterIDs)
+			}
+		})
+	}
+
+	t.Run("untouched cursor", func(t *testing.T) {
+		products := &pagedRepo{}
+		u := NewProductUsecase(products, noCache{}, &inventoryEvents{}, "secret")
+
+		page, err := u.ListProducts(context.Background(), &dto.ListProductsRequest{Cursor: valid, PerPage: 10})
+		if err != nil {
+			t.Fatalf("ListProducts = %v", err)
+		}
+		if len(products.afterIDs) != 1 || products.afterIDs[0] != 5 || len(page.Products) != 10 {
+			t.Fatalf("read after %v and returned %d products, want 10 after 5", products.afterIDs, len(page.Products))
+		}
+		if next, err := u.decodeCursor(page.NextCursor); err != nil || next != 15 {
+			t.Errorf("next cursor decodes to %d, %v, want 15", next, err)
+		}
+	})
+}
@@ -22,15 +22,19 @@ const (
 type ProductUsecase struct {
 	productRepo  domain.ProductRepository
 	productCache domain.ProductCache
+	inventory    domain.InventoryPublisher
+	cursorSecret []byte
 	tracer       trace.Tracer
 }
 
 var _ domain.ProductUsecase = (*ProductUsecase)(nil)
 
-func NewProductUsecase(productRepo domain.ProductRepository, productCache domain.ProductCache) *ProductUsecase {
+func NewProductUsecase(productRepo domain.ProductRepository, productCache domain.ProductCache, inventory domain.InventoryPublisher, cursorSecret string) *ProductUsecase {
 	return &ProductUsecase{
 		productRepo:  productRepo,
 		productCache: productCache,
+		inventory:    inventory,
+		cursorSecret: []byte(cursorSecret),
 		tracer:       otel.Tracer("product-usecase"),
 	}
 }
@@ -70,15 +74,16 @@ func (u *ProductUsecase) CreateProduct(ctx context.Context, productDto *dto.Crea
 
 	span.SetStatus(codes.Ok, "Product created successfully")
 	return &dto.ProductResponse{
-		Id:               newProduct.ID,
-		Name:             newProduct.Name,
-		ShortDescription: newProduct.ShortDescription,
-		Description:      newProduct.Description,
-		Price:            newProduct.Price,
-		DiscountType:     string(newProduct.DiscountType),
-		DiscountValue:    newProduct.DiscountValue,
-		ImageUrl:         newProduct.ImageUrl,
-		Quantity:         newProduct.Quantity,
+		Id:                newProduct.ID,
+		Name:              newProduct.Name,
+		ShortDescription:  newProduct.ShortDescription,
+		Description:       newProduct.Description,
+		Price:             newProduct.Price,
+		DiscountType:      string(newProduct.DiscountType),
+		DiscountValue:     newProduct.DiscountValue,
+		ImageUrl:          newProduct.ImageUrl,
+		Quantity:          newProduct.Quantity,
+		LowStockThreshold: newProduct.LowStockThreshold,
 	}, nil
 }
 
@@ -118,15 +123,16 @@ func (u *ProductUsecase) GetProductByID(ctx context.Context, id uint) (*dto.Prod
 	dbSpan.End()
 
 	newProduct := &dto.ProductResponse{
-		Id:               productObj.ID,
-		Name:             productObj.Name,
-		ShortDescription: productObj.ShortDescription,
-		Description:      productObj.Description,
-		Price:            productObj.Price,
-		DiscountType:     string(productObj.DiscountType),
-		DiscountValue:    productObj.DiscountValue,
-		ImageUrl:         productObj.ImageUrl,
-		Quantity:         productObj.Quantity,
+		Id:                productObj.ID,
+		Name:              productObj.Name,
+		ShortDescription:  productObj.ShortDescription,
+		Description:       productObj.Description,
+		Price:             productObj.Price,
+		DiscountType:      string(productObj.DiscountType),
+		DiscountValue:     productObj.DiscountValue,
+		ImageUrl:          productObj.ImageUrl,
+		Quantity:          productObj.Quantity,
+		LowStockThreshold: productObj.LowStockThreshold,
 	}
 
 	_, setCacheSpan := u.tracer.Start(ctx, "Cache.SetProduct")
@@ -144,42 +150,139 @@ func (u *ProductUsecase) GetProductByID(ctx context.Context, id uint) (*dto.Prod
 	return newProduct, nil
 }
 
-func (u *ProductUsecase) ListProducts(ctx context.Context, page, perPage int) ([]dto.ProductResponse, int, error) {
+// GetProductsByIDs loads every distinct id with one query, bypassing the per-product cache
+func (u *ProductUsecase) GetProductsByIDs(ctx context.Context, ids []uint) (*dto.ProductBatchResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "ProductUsecase.GetProductsByIDs")
+	defer span.End()
+
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	span.SetAttributes(attribute.Int("product.ids.count", len(unique)))
+
+	_, dbSpan := u.tracer.Start(ctx, "Database.GetProductsByIDs")
+	products, err := u.productRepo.GetProductsByIDs(ctx, unique)
+	if err != nil {
+		dbSpan.RecordError(err)
+		dbSpan.SetStatus(codes.Error, err.Error())
+		dbSpan.End()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	dbSpan.End()
+
+	byID := make(map[uint]domain.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	response := &dto.ProductBatchResponse{
+		Products:   make([]dto.ProductResponse, 0, len(products)),
+		MissingIDs: []uint{},
+	}
+	for _, id := range unique {
+		p, ok := byID[id]
+		if !ok {
+			response.MissingIDs = append(response.MissingIDs, id)
+			continue
+		}
+		response.Products = append(response.Products, dto.ProductResponse{
+			Id:                p.ID,
+			Name:              p.Name,
+			ShortDescription:  p.ShortDescription,
+			Description:       p.Description,
+			Price:             p.Price,
+			DiscountType:      string(p.DiscountType),
+			DiscountValue:     p.DiscountValue,
+			ImageUrl:          p.ImageUrl,
+			Quantity:          p.Quantity,
+			LowStockThreshold: p.LowStockThreshold,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("products.missing", len(response.MissingIDs)))
+	span.SetStatus(codes.Ok, "Products retrieved from database")
+	return response, nil
+}
+
+// ListProducts pages by offset, or by keyset when req.Cursor is set. Either way NextCursor points past
+// the last product returned, so a client can switch to cursors from any offset page.
+func (u *ProductUsecase) ListProducts(ctx context.Context, req *dto.ListProductsRequest) (*dto.ProductListResponse, error) {
 	ctx, span := u.tracer.Start(ctx, "ProductUsecase.ListProducts")
 	defer span.End()
 
+	var (
+		products []domain.Product
+		total    int
+		hasMore  bool
+		err      error
+	)
+
 	_, dbSpan := u.tracer.Start(ctx, "Database.ListProducts")
-	products, total, err := u.productRepo.ListProducts(ctx, page, perPage)
+	if req.Cursor != "" {
+		var afterID uint
+		afterID, err = u.decodeCursor(req.Cursor)
+		if err != nil {
+			dbSpan.End()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		// One extra row tells whether another page follows without a second query
+		products, total, err = u.productRepo.ListProductsAfter(ctx, afterID, req.PerPage+1)
+		if err == nil && len(products) > req.PerPage {
+			products = products[:req.PerPage]
+			hasMore = true
+		}
+	} else {
+		products, total, err = u.productRepo.ListProducts(ctx, req.Page, req.PerPage)
+		hasMore = req.Page*req.PerPage < total
+	}
 	if err != nil {
 		dbSpan.RecordError(err)
 		dbSpan.SetStatus(codes.Error, err.Error())
 		dbSpan.End()
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, 0, err
+		return nil, err
 	}
 	dbSpan.SetAttributes(attribute.Int("products.count", len(products)))
 	dbSpan.End()
 
-	span.SetAttributes(attribute.Int("products.count", len(products)))
+	span.SetAttributes(
+		attribute.Int("products.count", len(products)),
+		attribute.Bool("pagination.cursor", req.Cursor != ""),
+	)
 	span.SetStatus(codes.Ok, "Products retrieved from database")
 
 	productsMapped := make([]dto.ProductResponse, len(products))
 	for i, p := range products {
 		productsMapped[i] = dto.ProductResponse{
-			Id:               p.ID,
-			Name:             p.Name,
-			ShortDescription: p.ShortDescription,
-			Description:      p.Description,
-			Price:            p.Price,
-			DiscountType:     string(p.DiscountType),
-			DiscountValue:    p.DiscountValue,
-			ImageUrl:         p.ImageUrl,
-			Quantity:         p.Quantity,
+			Id:                p.ID,
+			Name:              p.Name,
+			ShortDescription:  p.ShortDescription,
+			Description:       p.Description,
+			Price:             p.Price,
+			DiscountType:      string(p.DiscountType),
+			DiscountValue:     p.DiscountValue,
+			ImageUrl:          p.ImageUrl,
+			Quantity:          p.Quantity,
+			LowStockThreshold: p.LowStockThreshold,
 		}
 	}
 
-	return productsMapped, total, nil
+	response := &dto.ProductListResponse{Products: productsMapped, Total: total}
+	if hasMore && len(products) > 0 {
+		response.NextCursor = u.encodeCursor(products[len(products)-1].ID)
+	}
+	return response, nil
 }
 
 func (u *ProductUsecase) UpdateProduct(ctx context.Context, id uint, product *dto.UpdateProductRequest) (*dto.ProductResponse, error) {
@@ -212,6 +315,16 @@ func (u *ProductUsecase) UpdateProduct(ctx context.Context, id uint, product *dt
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
+	if product.LowStockThreshold != nil {
+		if err := u.productRepo.SetLowStockThreshold(ctx, id, *product.LowStockThreshold); err != nil {
+			dbSpan.RecordError(err)
+			dbSpan.SetStatus(codes.Error, err.Error())
+			dbSpan.End()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+	}
 	dbSpan.End()
 
 	_, deleteSpan := u.tracer.Start(ctx, "Cache.DeleteProduct")
@@ -229,7 +342,8 @@ func (u *ProductUsecase) UpdateProduct(ctx context.Context, id uint, product *dt
 	invalidateSpan.End()
 
 	span.SetStatus(codes.Ok, "Product updated successfully")
-	return nil, nil
+	// Updates skips zero fields, so the stored row is reloaded instead of echoing the request
+	return u.GetProductByID(ctx, id)
 }
 
 func (u *ProductUsecase) RestockProduct(ctx context.Context, id uint, quantity int) error {
@@ -266,6 +380,169 @@ func (u *ProductUsecase) RestockProduct(ctx context.Context, id uint, quantity i
 	return nil
 }
 
+// AdjustProductStock applies a signed stock change, records it as a stock movement and announces the new
+// level on the inventory channel. A deduction that takes stock from above the product's low stock threshold
+// to at or below it also sends a low stock alert. Only crossing the threshold alerts, so later sales stay
+// quiet until a restock lifts stock above it again.
+func (u *ProductUsecase) AdjustProductStock(ctx context.Context, req *dto.AdjustStockRequest) (*dto.StockMovementResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "ProductUsecase.AdjustProductStock")
+	defer span.End()
+
+	id, delta := req.ProductID, req.Delta
+	span.SetAttributes(
+		attribute.Int("product.id", int(id)),
+		attribute.Int("product.stock_delta", delta),
+		attribute.String("product.stock_reason", req.Reason),
+	)
+
+	if delta == 0 {
+		err := errors.New("delta must not be zero")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	movement := &domain.StockMovement{
+		ProductID: id,
+		Delta:     delta,
+		Reason:    domain.StockMovementReason(req.Reason),
+		UserID:    req.UserID,
+	}
+	if req.OrderID != 0 {
+		movement.OrderID = &req.OrderID
+	}
+
+	_, dbSpan := u.tracer.Start(ctx, "Database.AdjustProductStock")
+	level, err := u.productRepo.AdjustProductStock(ctx, movement)
+	if err != nil {
+		dbSpan.RecordError(err)
+		dbSpan.SetStatus(codes.Error, err.Error())
+		dbSpan.End()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	dbSpan.End()
+
+	_, deleteSpan := u.tracer.Start(ctx, "Cache.DeleteProduct")
+	if err := u.productCache.DeleteProduct(ctx, id); err != nil {
+		deleteSpan.RecordError(err)
+		logger.Warnf("Failed to delete product from cache: %v", err)
+	}
+	deleteSpan.End()
+
+	// The stock change is already committed, so a lost event is logged rather than failing the call
+	_, publishSpan := u.tracer.Start(ctx, "Events.PublishStockUpdate")
+	if err := u.inventory.PublishStockUpdate(ctx, id, level.Quantity); err != nil {
+		publishSpan.RecordError(err)
+		logger.Warnf("Failed to publish inventory update for product %d: %v", id, err)
+	}
+	publishSpan.End()
+
+	if delta < 0 && level.Quantity <= level.LowStockThreshold && level.Quantity-delta > level.LowStockThreshold {
+		_, alertSpan := u.tracer.Start(ctx, "Events.PublishLowStock")
+		if err := u.inventory.PublishLowStock(ctx, id, level.Quantity, level.LowStockThreshold); err != nil {
+			alertSpan.RecordError(err)
+			logger.Warnf("Failed to publish low stock alert for product %d: %v", id, err)
+		}
+		alertSpan.End()
+		logger.Infof("event=low_stock product_id=%d stock=%d threshold=%d", id, level.Quantity, level.LowStockThreshold)
+	}
+
+	span.SetAttributes(attribute.Int("product.quantity", level.Quantity))
+	span.SetStatus(codes.Ok, "Product stock adjusted")
+	return mapStockMovementToResponse(movement), nil
+}
+
+// ListStockMovements pages through a product's stock history, the newest first
+func (u *ProductUsecase) ListStockMovements(ctx context.Context, req *dto.ListStockMovementsRequest) (*dto.StockMovementListResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "ProductUsecase.ListStockMovements")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("product.id", int(req.ProductID)))
+
+	if _, err := u.productRepo.GetProductByID(ctx, req.ProductID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	_, dbSpan := u.tracer.Start(ctx, "Database.ListStockMovements")
+	movements, total, err := u.productRepo.ListStockMovements(ctx, req.ProductID, req.Page, req.PerPage)
+	if err != nil {
+		dbSpan.RecordError(err)
+		dbSpan.SetStatus(codes.Error, err.Error())
+		dbSpan.End()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	dbSpan.End()
+
+	response := &dto.StockMovementListResponse{Movements: make([]dto.StockMovementResponse, 0, len(movements)), Total: total}
+	for i := range movements {
+		response.Movements = append(response.Movements, *mapStockMovementToResponse(&movements[i]))
+	}
+
+	span.SetAttributes(attribute.Int("stock_movements.count", len(movements)))
+	span.SetStatus(codes.Ok, "Stock movements retrieved successfully")
+	return response, nil
+}
+
+func mapStockMovementToResponse(movement *domain.StockMovement) *dto.StockMovementResponse {
+	return &dto.StockMovementResponse{
+		Id:         movement.ID,
+		ProductID:  movement.ProductID,
+		Delta:      movement.Delta,
+		Reason:     string(movement.Reason),
+		OrderID:    movement.OrderID,
+		UserID:     movement.UserID,
+		StockAfter: movement.StockAfter,
+		CreatedAt:  movement.CreatedAt,
+	}
+}
+
+// ListLowStockProducts pages through the products at or below their low stock threshold, the lowest stock first
+func (u *ProductUsecase) ListLowStockProducts(ctx context.Context, page, perPage int) (*dto.ProductListResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "ProductUsecase.ListLowStockProducts")
+	defer span.End()
+
+	_, dbSpan := u.tracer.Start(ctx, "Database.ListLowStockProducts")
+	products, total, err := u.productRepo.ListLowStockProducts(ctx, page, perPage)
+	if err != nil {
+		dbSpan.RecordError(err)
+		dbSpan.SetStatus(codes.Error, err.Error())
+		dbSpan.End()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	dbSpan.End()
+
+	response := &dto.ProductListResponse{Products: make([]dto.ProductResponse, len(products)), Total: total}
+	for i, p := range products {
+		response.Products[i] = dto.ProductResponse{
+			Id:                p.ID,
+			Name:              p.Name,
+			ShortDescription:  p.ShortDescription,
+			Description:       p.Description,
+			Price:             p.Price,
+			DiscountType:      string(p.DiscountType),
+			DiscountValue:     p.DiscountValue,
+			ImageUrl:          p.ImageUrl,
+			Quantity:          p.Quantity,
+			LowStockThreshold: p.LowStockThreshold,
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("products.count", len(products)),
+		attribute.Int("products.total", total),
+	)
+	span.SetStatus(codes.Ok, "Low stock products listed")
+	return response, nil
+}
+
 func (u *ProductUsecase) DeleteProduct(ctx context.Context, id uint) error {
 	ctx, span := u.tracer.Start(ctx, "ProductUsecase.DeleteProduct")
 	defer span.End()
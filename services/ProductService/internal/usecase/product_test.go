@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeStockRepo holds the stock of products in memory and refuses changes taking it below zero, as the
+// conditional UPDATE does; the methods it does not implement panic through the nil interface
+type fakeStockRepo struct {
+	domain.ProductRepository
+	stock     map[uint]int
+	threshold int
+	movements []domain.StockMovement
+}
+
+func (f *fakeStockRepo) AdjustProductStock(_ context.Context, movement *domain.StockMovement) (*domain.StockLevel, error) {
+	quantity, ok := f.stock[movement.ProductID]
+	if !ok {
+		return nil, repository.ErrProductNotFound
+	}
+	if quantity+movement.Delta < 0 {
+		return nil, repository.ErrInsufficientStock
+	}
+	f.stock[movement.ProductID] = quantity + movement.Delta
+	movement.StockAfter = quantity + movement.Delta
+	f.movements = append(f.movements, *movement)
+	return &domain.StockLevel{Quantity: movement.StockAfter, LowStockThreshold: f.threshold}, nil
+}
+
+// noCache is a product cache holding nothing
+type noCache struct {
+	domain.ProductCache
+}
+
+func (noCache) DeleteProduct(context.Context, uint) error {
+	return nil
+}
+
+// inventoryEvents records what would be published on the inventory channels
+type inventoryEvents struct {
+	updates  []int
+	lowStock []int
+}
+
+func (e *inventoryEvents) PublishStockUpdate(_ context.Context, _ uint, stockLevel int) error {
+	e.updates = append(e.updates, stockLevel)
+	return nil
+}
+
+func (e *inventoryEvents) PublishLowStock(_ context.Context, _ uint, stockLevel, _ int) error {
+	e.lowStock = append(e.lowStock, stockLevel)
+	return nil
+}
+
+func TestAdjustProductStock(t *testing.T) {
+	tests := []struct {
+		name         string
+		delta        int
+		wantErr      error
+		wantStock    int
+		wantLowStock []int
+	}{
+		{name: "restock", delta: 10, wantStock: 45},
+		{name: "partial deduction", delta: -30, wantStock: 5, wantLowStock: []int{5}},
+		{name: "deduction of all the stock", delta: -35, wantStock: 0, wantLowStock: []int{0}},
+		{name: "over-deduction", delta: -36, wantErr: repository.ErrInsufficientStock, wantStock: 35},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := &fakeStockRepo{stock: map[uint]int{1: 35}, threshold: 5}
+			events := &inventoryEvents{}
+			u := NewProductUsecase(products, noCache{}, events, "")
+
+			movement, err := u.AdjustProductStock(context.Background(), &dto.AdjustStockRequest{ProductID: 1, Delta: tt.delta, Reason: "correction"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AdjustProductStock = %v, want %v", err, tt.wantErr)
+			}
+			if products.stock[1] != tt.wantStock {
+				t.Errorf("stock = %d, want %d", products.stock[1], tt.wantStock)
+			}
+			if !reflect.DeepEqual(events.lowStock, tt.wantLowStock) {
+				t.Errorf("low stock alerts = %v, want %v", events.lowStock, tt.wantLowStock)
+			}
+			if tt.wantErr != nil {
+				if len(products.movements) > 0 || len(events.updates) > 0 {
+					t.Errorf("a refused change recorded %v and published %v", products.movements, events.updates)
+				}
+				return
+			}
+			if movement.StockAfter != tt.wantStock || !reflect.DeepEqual(events.updates, []int{tt.wantStock}) {
+				t.Errorf("movement left %d and published %v, want %d", movement.StockAfter, events.updates, tt.wantStock)
+			}
+		})
+	}
+
+	t.Run("zero delta", func(t *testing.T) {
+		products := &fakeStockRepo{stock: map[uint]int{1: 35}}
+		if _, err := NewProductUsecase(products, noCache{}, &inventoryEvents{}, "").AdjustProductStock(context.Background(), &dto.AdjustStockRequest{ProductID: 1, Reason: "correction"}); err == nil {
+			t.Fatal("a zero delta was accepted")
+		}
+	})
+}
+
+// TestOrderSalesAlertLowStockOnce follows the deductions the order service makes: the sale that crosses the
+// threshold alerts, later sales below it stay quiet, and a canceled order lifting stock above it rearms the alert
+func TestOrderSalesAlertLowStockOnce(t *testing.T) {
+	products := &fakeStockRepo{stock: map[uint]int{1: 8}, threshold: 5}
+	events := &inventoryEvents{}
+	u := NewProductUsecase(products, noCache{}, events, "")
+
+	steps := []struct {
+		name         string
+		delta        int
+		orderID      uint
+		wantLowStock []int
+	}{
+		{name: "sale above the threshold", delta: -2, orderID: 1},
+		{name: "sale crossing the threshold", delta: -2, orderID: 2, wantLowStock: []int{4}},
+		{name: "sale below the threshold", delta: -1, orderID: 3, wantLowStock: []int{4}},
+		{name: "another sale below the threshold", delta: -3, orderID: 4, wantLowStock: []int{4}},
+		{name: "canceled order put back", delta: 2, orderID: 2, wantLowStock: []int{4}},
+		{name: "canceled order lifting stock above the threshold", delta: 4, orderID: 4, wantLowStock: []int{4}},
+		{name: "sale crossing the threshold again", delta: -1, orderID: 5, wantLowStock: []int{4, 5}},
+	}
+	for _, step := range steps {
+		movement, err := u.AdjustProductStock(context.Background(), &dto.AdjustStockRequest{ProductID: 1, Delta: step.delta, Reason: "order", OrderID: step.orderID})
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if movement.Reason != string(domain.StockReasonOrder) || movement.OrderID == nil || *movement.OrderID != step.orderID {
+			t.Errorf("%s: movement %+v, want an order movement of order %d", step.name, movement, step.orderID)
+		}
+		if !slices.Equal(events.lowStock, step.wantLowStock) {
+			t.Fatalf("%s: low stock alerts = %v, want %v", step.name, events.lowStock, step.wantLowStock)
+		}
+	}
+}
+
+// batchRepo serves products 1 and 3, in its own order as an IN query would, recording the IDs it was asked for
+type batchRepo struct {
+	domain.ProductRepository
+	requested [][]uint
+}
+
+func (r *batchRepo) GetProductsByIDs(_ context.Context, ids []uint) ([]domain.Product, error) {
+	r.requested = append(r.requested, ids)
+	var found []domain.Product
+	for _, product := range []domain.Product{{Model: gorm.Model{ID: 1}, Name: "Lamp"}, {Model: gorm.Model{ID: 3}, Name: "Desk"}} {
+		if slices.Contains(ids, product.ID) {
+			found = append(found, product)
+		}
+	}
+	return found, nil
+}
+
+func TestGetProductsByIDs(t *testing.T) {
+	products := &batchRepo{}
+	u := NewProductUsecase(products, noCache{}, &inventoryEvents{}, "")
+
+	batch, err := u.GetProductsByIDs(context.Background(), []uint{3, 2, 3, 1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Duplicates are dropped before the one query
+	if len(products.requested) != 1 || !reflect.DeepEqual(products.requested[0], []uint{3, 2, 1}) {
+		t.Fatalf("queried %v, want [3 2 1] once", products.requested)
+	}
+	var names []string
+	for _, product := range batch.Products {
+		names = append(names, product.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Desk", "Lamp"}) {
+		t.Errorf("products = %v, want the desk and the lamp in request order", names)
+	}
+	if !reflect.DeepEqual(batch.MissingIDs, []uint{2}) {
+		t.Errorf("missing = %v, want [2]", batch.MissingIDs)
+	}
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/ProductService/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ domain.ReviewUsecase = (*ReviewUsecase)(nil)
+
+type ReviewUsecase struct {
+	reviewRepo  domain.ReviewRepository
+	productRepo domain.ProductRepository
+	tracer      trace.Tracer
+}
+
+func NewReviewUsecase(reviewRepo domain.ReviewRepository, productRepo domain.ProductRepository) *ReviewUsecase {
+	return &ReviewUsecase{
+		reviewRepo:  reviewRepo,
+		productRepo: productRepo,
+		tracer:      otel.Tracer("review-usecase"),
+	}
+}
+
+// CreateReview stores a review of an existing product. Whether the user bought the product is
+// checked by the caller against the order service.
+func (u *ReviewUsecase) CreateReview(ctx context.Context, req *dto.CreateReviewRequest) (*dto.ReviewResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "ReviewUsecase.CreateReview")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("review.product_id", int(req.ProductID)),
+		attribute.Int("review.user_id", int(req.UserID)),
+		attribute.Int("review.rating", req.Rating),
+	)
+
+	if _, err := u.productRepo.GetProductByID(ctx, req.ProductID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	review := &domain.Review{
+		ProductID: req.ProductID,
+		UserID:    req.UserID,
+		Rating:    req.Rating,
+		Comment:   req.Comment,
+	}
+	if err := u.reviewRepo.CreateReview(ctx, review); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "Review created successfully")
+	return mapReviewToResponse(review), nil
+}
+
+func (u *ReviewUsecase) ListProductReviews(ctx context.Context, req *dto.ListProductReviewsRequest) (*dto.ReviewListResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "ReviewUsecase.ListProductReviews")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("review.product_id", int(req.ProductID)))
+
+	if _, err := u.productRepo.GetProductByID(ctx, req.ProductID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	reviews, total, err := u.reviewRepo.ListProductReviews(ctx, req.ProductID, req.Page, req.PerPage)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	rating, err := u.reviewRepo.GetProductRating(ctx, req.ProductID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	response := &dto.ReviewListResponse{
+		Reviews:       make([]dto.ReviewResponse, 0, len(reviews)),
+		Total:         total,
+		AverageRating: rating.Average,
+	}
+	for i := range reviews {
+		response.Reviews = append(response.Reviews, *mapReviewToResponse(&reviews[i]))
+	}
+
+	span.SetAttributes(attribute.Int("reviews.count", len(reviews)))
+	span.SetStatus(codes.Ok, "Reviews retrieved successfully")
+	return response, nil
+}
+
+func (u *ReviewUsecase) GetProductRating(ctx context.Context, productID uint) (*domain.ProductRating, error) {
+	ctx, span := u.tracer.Start(ctx, "ReviewUsecase.GetProductRating")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("review.product_id", int(productID)))
+
+	rating, err := u.reviewRepo.GetProductRating(ctx, productID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "Rating retrieved successfully")
+	return rating, nil
+}
+
+func mapReviewToResponse(review *domain.Review) *dto.ReviewResponse {
+	return &dto.ReviewResponse{
+		Id:        review.ID,
+		ProductID: review.ProductID,
+		UserID:    review.UserID,
+		Rating:    review.Rating,
+		Comment:   review.Comment,
+		CreatedAt: review.CreatedAt,
+	}
+}
@@ -5,18 +5,22 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/kareemhamed001/e-commerce/pkg/db"
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	"github.com/kareemhamed001/e-commerce/pkg/jwt"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/pkg/spiffeauth"
 	"github.com/kareemhamed001/e-commerce/pkg/tracer"
 	"github.com/kareemhamed001/e-commerce/services/UserService/config"
 	"github.com/kareemhamed001/e-commerce/services/UserService/internal/delivery/grpc/handler"
 	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/notifier"
 	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository/postgresql"
 	"github.com/kareemhamed001/e-commerce/services/UserService/internal/usecase"
+	notificationpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/notification"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -35,6 +39,13 @@ func main() {
 	shutdownTracer := initTracing(ctx)
 	defer shutdownTracer()
 
+	spiffeSource, err := spiffeauth.NewSource(ctx, config.SPIFFEEndpointSocket)
+	if err != nil {
+		close(done)
+		panic(err)
+	}
+	defer spiffeSource.Close()
+
 	dbConfig := &db.Config{
 		DBDriver:              config.DBDriver,
 		DSN:                   config.DBDSN,
@@ -55,18 +66,63 @@ func main() {
 
 	useRepo := postgresql.NewUserRepository(db)
 	addressRepo := postgresql.NewAddressRepository(db)
-	userUseCase := usecase.NewUserUsecase(useRepo)
+	apiKeyRepo := postgresql.NewAPIKeyRepository(db)
+	twoFactorRepo := postgresql.NewTwoFactorRepository(db)
+	loginAttemptRepo := postgresql.NewLoginAttemptRepository(db)
+	var userNotifier domain.NotifierInterface = notifier.NoopNotifier{}
+	if config.NotificationServiceGRPCAddr != "" {
+		notificationConn, err := grpc.NewClient(
+			config.NotificationServiceGRPCAddr,
+			grpc.WithTransportCredentials(spiffeSource.ClientCredentials()),
+			grpc.WithChainUnaryInterceptor(
+				grpcmiddleware.InternalAuthUnaryClientInterceptor(config.InternalAuthToken, config.ServiceName),
+				grpcmiddleware.RequestIDUnaryClientInterceptor(),
+			),
+		)
+		if err != nil {
+			close(done)
+			panic("failed to connect to notification service")
+		}
+		defer func() {
+			_ = notificationConn.Close()
+		}()
+		userNotifier = notifier.NewGRPCNotifier(notificationpb.NewNotificationServiceClient(notificationConn))
+	}
+
+	loginPolicy := usecase.LoginPolicy{
+		BackoffAfter: config.LoginBackoffAfter,
+		BackoffBase:  config.LoginBackoffBase,
+		LockAfter:    config.LoginLockoutAfter,
+		LockFor:      config.LoginLockoutDuration,
+	}
+	userUseCase := usecase.NewUserUsecase(useRepo, loginAttemptRepo, twoFactorRepo, userNotifier, loginPolicy)
 	addressUsecase := usecase.NewAddressUsecase(addressRepo, useRepo)
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo, useRepo)
+	twoFactorUsecase, err := usecase.NewTwoFactorUsecase(twoFactorRepo, useRepo, loginAttemptRepo, loginPolicy, config.TOTPEncryptionKey, config.TOTPIssuer)
+	if err != nil {
+		close(done)
+		panic(err)
+	}
 
 	validate := validator.New()
-	jwtManager := jwt.NewJWTManager(config.JWTSecret, time.Duration(config.JWTDuration)*time.Hour)
+	jwtManager := jwt.NewJWTManager(config.JWTSecret, config.JWTDuration)
+	jwtManager.SetKeys(config.JWTKeys)
+	if config.JWTSigningKey != nil {
+		if err := jwtManager.SetSigningKey(config.JWTSigningKey); err != nil {
+			panic(err)
+		}
+	}
+	jwtManager.SetRolePermissions(config.RolePermissions)
+	jwtManager.SetIssuerAudience(config.JWTIssuer, config.JWTAudience)
 
-	grpcHandler := handler.NewUserGRPCHandler(userUseCase, addressUsecase, validate, jwtManager, config.InternalAuthToken)
+	grpcHandler := handler.NewUserGRPCHandler(userUseCase, addressUsecase, apiKeyUsecase, twoFactorUsecase, validate, jwtManager)
 
-	err = grpcHandler.Run(done, config.GRPCPort)
+	serverOpts := append(grpcmiddleware.DefaultServerOptions(logger.NewStructured(config.AppEnv), config.InternalAuthToken, config.InternalAuthPolicy), spiffeSource.ServerOptions()...)
+	err = grpcHandler.Run(done, config.GRPCPort, serverOpts...)
 	if err != nil {
 		panic(err)
 	}
+	grpcmiddleware.ServeMetrics(done, config.MetricsPort)
 
 	//gracful shutdown
 	sigChan := make(chan os.Signal, 1)
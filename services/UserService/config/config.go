@@ -1,12 +1,17 @@
 package config
 
 import (
+	"crypto"
+	"encoding/base64"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/joho/godotenv"
+	envconfig "github.com/kareemhamed001/e-commerce/pkg/config"
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
+	"github.com/kareemhamed001/e-commerce/pkg/jwt"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 )
 
@@ -24,20 +29,60 @@ type Config struct {
 	DBMigrationAutoRun  bool
 
 	// JWT
-	JWTSecret   string
-	JWTDuration int
+	JWTSecret string
+	// JWTKeys sign tokens with the first key: JWT_SECRET_FILES, else JWT_SECRETS, else JWT_SECRET alone
+	JWTKeys []jwt.Key
+	// JWTAlgorithm is JWT_ALG, falling back to its older name JWT_ALGORITHM: HS256 signs with JWTKeys, RS256 or
+	// EdDSA with JWTSigningKey
+	JWTAlgorithm string
+	// JWTSigningKey is loaded from JWT_PRIVATE_KEY_FILE; nil under HS256
+	JWTSigningKey crypto.Signer
+	// JWTDuration is JWT_TTL, e.g. "24h", falling back to JWT_EXPIRY and JWT_DURATION_HOURS
+	JWTDuration time.Duration
+	// JWTIssuer and JWTAudience are written to the iss and aud claims. JWTIssuer defaults to ServiceName.
+	JWTIssuer   string
+	JWTAudience string
+	// RolePermissions is embedded into issued tokens
+	RolePermissions map[string][]string
+
+	// TOTPEncryptionKey is TOTP_ENCRYPTION_KEY decoded from base64, the AES-256 key two-factor secrets are
+	// stored under. Two-factor authentication cannot be set up while it is empty.
+	TOTPEncryptionKey []byte
+	// TOTPIssuer names the service in authenticator apps
+	TOTPIssuer string
+
+	// Login throttling, per email: after LoginBackoffAfter failures each attempt waits LoginBackoffBase, doubling
+	// with every further failure, and LoginLockoutAfter failures lock the email for LoginLockoutDuration
+	LoginBackoffAfter    int
+	LoginBackoffBase     time.Duration
+	LoginLockoutAfter    int
+	LoginLockoutDuration time.Duration
 
 	// gRPC
 	GRPCPort string
+	// MetricsPort serves GET /metrics for Prometheus
+	MetricsPort string
 
 	// Service name
 	ServiceName string
 
 	// Internal service auth
-	InternalAuthToken string
+	InternalAuthToken  string
+	InternalAuthPolicy grpcmiddleware.InternalAuthPolicy
+
+	// SPIFFEEndpointSocket is the SPIFFE Workload API address; when set, gRPC connections use mTLS with its SVID
+	SPIFFEEndpointSocket string
+
+	// Notification service, optional; welcome emails are skipped when empty
+	NotificationServiceGRPCAddr string
 }
 
 func Load() (*Config, error) {
+	// Loaded first so the secret wins over the .env file
+	if err := envconfig.LoadSecrets(); err != nil {
+		return nil, err
+	}
+
 	// Try multiple paths for .env file
 	envPaths := []string{
 		filepath.Join("services/UserService/config/.env"),
@@ -72,17 +117,76 @@ func Load() (*Config, error) {
 		DBMigrationAutoRun:  getEnvBool("DB_MIGRATION_AUTO_RUN", true),
 
 		// JWT
-		JWTSecret:   GetEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-		JWTDuration: getEnvInt("JWT_DURATION_HOURS", 24),
+		JWTSecret:    GetEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+		JWTAudience:  GetEnv("JWT_AUDIENCE", "api-gateway"),
+		JWTAlgorithm: GetEnv("JWT_ALG", GetEnv("JWT_ALGORITHM", jwt.AlgorithmHS256)),
+
+		// Two-factor authentication
+		TOTPIssuer: GetEnv("TOTP_ISSUER", "E-Commerce"),
+
+		// Login throttling
+		LoginBackoffAfter: getEnvInt("LOGIN_BACKOFF_AFTER", 3),
+		LoginLockoutAfter: getEnvInt("LOGIN_LOCKOUT_AFTER", 10),
 
 		// gRPC
-		GRPCPort: GetEnv("GRPC_PORT", "50051"),
+		GRPCPort:    GetEnv("GRPC_PORT", "50051"),
+		MetricsPort: GetEnv("METRICS_PORT", "9090"),
 
 		// Service
 		ServiceName: GetEnv("SERVICE_NAME", "user-service"),
 
 		// Internal service auth
-		InternalAuthToken: GetEnv("INTERNAL_AUTH_TOKEN", ""),
+		InternalAuthToken:    GetEnv("INTERNAL_AUTH_TOKEN", ""),
+		SPIFFEEndpointSocket: GetEnv("SPIFFE_ENDPOINT_SOCKET", ""),
+
+		// Notification service
+		NotificationServiceGRPCAddr: GetEnv("NOTIFICATION_SERVICE_GRPC_ADDR", ""),
+	}
+
+	cfg.JWTIssuer = GetEnv("JWT_ISSUER", cfg.ServiceName)
+
+	// JWT_TTL took over from JWT_EXPIRY, which took over from JWT_DURATION_HOURS; both are still read
+	ttlKey := "JWT_TTL"
+	if os.Getenv(ttlKey) == "" {
+		ttlKey = "JWT_EXPIRY"
+	}
+	cfg.JWTDuration, err = getEnvDuration(ttlKey, time.Duration(getEnvInt("JWT_DURATION_HOURS", 24))*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.JWTKeys, err = jwt.ResolveKeys(os.Getenv("JWT_SECRET_FILES"), os.Getenv("JWT_SECRETS"), cfg.JWTSecret)
+	if err != nil {
+		return nil, fmt.Errorf("JWT keys: %w", err)
+	}
+
+	cfg.JWTSigningKey, err = jwt.LoadSigningKey(cfg.JWTAlgorithm, os.Getenv("JWT_PRIVATE_KEY_FILE"))
+	if err != nil {
+		return nil, fmt.Errorf("JWT_ALG: %w", err)
+	}
+
+	cfg.RolePermissions, err = jwt.ParseRolePermissions(os.Getenv("ROLE_PERMISSIONS_JSON"))
+	if err != nil {
+		return nil, fmt.Errorf("ROLE_PERMISSIONS_JSON: %w", err)
+	}
+
+	cfg.LoginBackoffBase, err = getEnvDuration("LOGIN_BACKOFF_BASE", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LoginLockoutDuration, err = getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.TOTPEncryptionKey, err = base64.StdEncoding.DecodeString(os.Getenv("TOTP_ENCRYPTION_KEY"))
+	if err != nil || (len(cfg.TOTPEncryptionKey) != 0 && len(cfg.TOTPEncryptionKey) != 32) {
+		return nil, fmt.Errorf("TOTP_ENCRYPTION_KEY must be 32 bytes encoded as base64")
+	}
+
+	cfg.InternalAuthPolicy, err = grpcmiddleware.ParseInternalAuthPolicy(os.Getenv("INTERNAL_AUTH_POLICY_JSON"), os.Getenv("INTERNAL_AUTH_DEFAULT"))
+	if err != nil {
+		return nil, err
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -105,10 +209,22 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("JWT_SECRET is required")
 	}
 
+	if c.JWTDuration <= 0 {
+		return fmt.Errorf("JWT_TTL must be positive")
+	}
+
 	if c.AppPort == "" {
 		return fmt.Errorf("APP_PORT is required")
 	}
 
+	if c.LoginBackoffAfter < 0 || c.LoginLockoutAfter < 0 {
+		return fmt.Errorf("LOGIN_BACKOFF_AFTER and LOGIN_LOCKOUT_AFTER must not be negative")
+	}
+
+	if c.LoginLockoutDuration <= 0 {
+		return fmt.Errorf("LOGIN_LOCKOUT_DURATION must be positive")
+	}
+
 	if c.InternalAuthToken == "" {
 		return fmt.Errorf("INTERNAL_AUTH_TOKEN is required")
 	}
@@ -141,3 +257,16 @@ func getEnvBool(key string, fallback bool) bool {
 	}
 	return fallback
 }
+
+// getEnvDuration parses a Go duration such as "15m" or "24h"
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("%s: invalid duration %q", key, value)
+	}
+	return duration, nil
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	envconfig "github.com/kareemhamed001/e-commerce/pkg/config"
+)
+
+// loadWith runs Load in an empty directory, so no .env file is found, with env set on top of the settings
+// Validate requires
+func loadWith(t *testing.T, env map[string]string) (*Config, error) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Setenv("INTERNAL_AUTH_TOKEN", "internal-token")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	return Load()
+}
+
+func TestJWTClaimsSettings(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantIssuer   string
+		wantAudience string
+		wantTTL      time.Duration
+	}{
+		{name: "defaults", wantIssuer: "user-service", wantAudience: "api-gateway", wantTTL: 24 * time.Hour},
+		{name: "issuer follows SERVICE_NAME", env: map[string]string{"SERVICE_NAME": "staging-user-service"}, wantIssuer: "staging-user-service", wantAudience: "api-gateway", wantTTL: 24 * time.Hour},
+		{
+			name:         "configured",
+			env:          map[string]string{"JWT_ISSUER": "auth.example.com", "JWT_AUDIENCE": "staging-gateway", "JWT_TTL": "15m", "JWT_EXPIRY": "1h"},
+			wantIssuer:   "auth.example.com",
+			wantAudience: "staging-gateway",
+			wantTTL:      15 * time.Minute,
+		},
+		{name: "JWT_DURATION_HOURS", env: map[string]string{"JWT_DURATION_HOURS": "2"}, wantIssuer: "user-service", wantAudience: "api-gateway", wantTTL: 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWith(t, tt.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.JWTIssuer != tt.wantIssuer || cfg.JWTAudience != tt.wantAudience || cfg.JWTDuration != tt.wantTTL {
+				t.Errorf("issuer %q, audience %q and TTL %v, want %q, %q and %v",
+					cfg.JWTIssuer, cfg.JWTAudience, cfg.JWTDuration, tt.wantIssuer, tt.wantAudience, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestJWTSecretFromTheSecretStore(t *testing.T) {
+	envconfig.SetSecretsLoader(envconfig.MockSecretsLoader{"user-service": {"JWT_SECRET": "from-the-secret-store"}})
+	t.Cleanup(func() { envconfig.SetSecretsLoader(nil) })
+	// Unset with its value restored afterwards, since LoadSecrets only sets what is unset
+	t.Setenv("JWT_SECRET", "")
+	os.Unsetenv("JWT_SECRET")
+
+	cfg, err := loadWith(t, map[string]string{"CONFIG_SECRETS_PROVIDER": "aws-secrets-manager", "CONFIG_SECRET_ARN": "user-service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.JWTSecret != "from-the-secret-store" {
+		t.Errorf("JWTSecret = %q, want the secret's", cfg.JWTSecret)
+	}
+}
+
+func TestJWTSecretFromTheEnvironmentWinsOverTheSecret(t *testing.T) {
+	envconfig.SetSecretsLoader(envconfig.MockSecretsLoader{"user-service": {"JWT_SECRET": "from-the-secret-store"}})
+	t.Cleanup(func() { envconfig.SetSecretsLoader(nil) })
+
+	cfg, err := loadWith(t, map[string]string{"CONFIG_SECRETS_PROVIDER": "aws-secrets-manager", "CONFIG_SECRET_ARN": "user-service", "JWT_SECRET": "from-the-environment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.JWTSecret != "from-the-environment" {
+		t.Errorf("JWTSecret = %q, want the environment's", cfg.JWTSecret)
+	}
+}
+
+func TestJWTAlgorithmIsReadUnderItsOldName(t *testing.T) {
+	// RS256 needs JWT_PRIVATE_KEY_FILE, so failing without it shows JWT_ALGORITHM was read
+	if _, err := loadWith(t, map[string]string{"JWT_ALGORITHM": "RS256"}); err == nil || !strings.HasPrefix(err.Error(), "JWT_ALG:") {
+		t.Errorf("JWT_ALGORITHM=RS256: Load = %v, want a JWT_ALG error", err)
+	}
+
+	cfg, err := loadWith(t, map[string]string{"JWT_ALG": "HS256", "JWT_ALGORITHM": "RS256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.JWTAlgorithm != "HS256" {
+		t.Errorf("algorithm %q, want JWT_ALG's HS256", cfg.JWTAlgorithm)
+	}
+}
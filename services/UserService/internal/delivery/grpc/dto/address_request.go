@@ -7,13 +7,23 @@ type CreateAddressRequest struct {
 	State   string `json:"state" validate:"required"`
 	Street  string `json:"street" validate:"required"`
 	ZipCode string `json:"zip_code" validate:"required,len=5"`
+	Label   string `json:"label" validate:"omitempty,max=50"`
+	// IsDefault makes the new address the default; a user's first address is always the default
+	IsDefault bool `json:"is_default"`
 }
 
 type UpdateAddressRequest struct {
-	Id      int32  `json:"id" validate:"required"`
-	Country string `json:"country" validate:"omitempty"`
-	City    string `json:"city" validate:"omitempty"`
-	State   string `json:"state" validate:"omitempty"`
-	Street  string `json:"street" validate:"omitempty"`
-	ZipCode string `json:"zip_code" validate:"omitempty,len=5"`
+	Id               int32  `json:"id" validate:"required"`
+	RequestingUserID int32  `json:"requesting_user_id" validate:"required,gt=0"`
+	Country          string `json:"country" validate:"omitempty"`
+	City             string `json:"city" validate:"omitempty"`
+	State            string `json:"state" validate:"omitempty"`
+	Street           string `json:"street" validate:"omitempty"`
+	ZipCode          string `json:"zip_code" validate:"omitempty,len=5"`
+	Label            string `json:"label" validate:"omitempty,max=50"`
+}
+
+type SetDefaultAddressRequest struct {
+	ID     int32 `json:"id" validate:"required,gt=0"`
+	UserID int32 `json:"user_id" validate:"required,gt=0"`
 }
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Label  string   `json:"label" validate:"required,min=2,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required,excludes=0x2C"`
+	// UserID is the account the key acts as
+	UserID uint `json:"user_id" validate:"required"`
+}
+
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Label      string     `json:"label"`
+	Scopes     []string   `json:"scopes"`
+	Prefix     string     `json:"prefix"`
+	UserID     uint       `json:"user_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
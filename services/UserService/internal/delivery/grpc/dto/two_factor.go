@@ -0,0 +1,8 @@
+package dto
+
+type TwoFactorSetupResponse struct {
+	// Secret is the base32 secret, for users who type it into their authenticator
+	Secret string `json:"secret"`
+	// URL is the otpauth URL, for authenticators that scan it from a QR code
+	URL string `json:"otpauth_url"`
+}
@@ -17,3 +17,10 @@ type UpdateUserRequest struct {
 	Email    string ` json:"email" validate:"omitempty,email"`
 	Password string ` json:"password" validate:"omitempty,min=6"`
 }
+
+type SearchUsersRequest struct {
+	Query     string `json:"query"`
+	Role      string `json:"role" validate:"omitempty,oneof=customer admin"`
+	SortBy    string `json:"sort_by" validate:"omitempty,oneof=id created_at"`
+	SortOrder string `json:"sort_order" validate:"omitempty,oneof=asc desc"`
+}
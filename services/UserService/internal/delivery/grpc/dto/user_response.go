@@ -5,4 +5,6 @@ type UserResponse struct {
 	Name  string ` json:"name"`
 	Email string ` json:"email"`
 	Role  string ` json:"role"`
+	// Roles is the primary role followed by any granted ones, only filled on login
+	Roles []string `json:"roles,omitempty"`
 }
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+	pb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"go.opentelemetry.io/otel/codes"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+func (h *UserGRPCHandler) CreateAPIKey(ctx context.Context, in *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.CreateAPIKey")
+	defer span.End()
+
+	request := dto.CreateAPIKeyRequest{
+		Label:  in.GetLabel(),
+		Scopes: in.GetScopes(),
+		UserID: uint(in.GetUserId()),
+	}
+	if err := h.validate.Struct(request); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	apiKey, key, err := h.apiKeyUsecase.CreateAPIKey(ctx, &request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	return &pb.CreateAPIKeyResponse{ApiKey: toPBAPIKey(apiKey), Key: key}, nil
+}
+
+func (h *UserGRPCHandler) RevokeAPIKey(ctx context.Context, in *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.RevokeAPIKey")
+	defer span.End()
+
+	if in.GetId() <= 0 {
+		return nil, status.Error(grpccodes.InvalidArgument, "id is required")
+	}
+
+	apiKey, err := h.apiKeyUsecase.RevokeAPIKey(ctx, uint(in.GetId()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	return &pb.RevokeAPIKeyResponse{ApiKey: toPBAPIKey(apiKey)}, nil
+}
+
+func (h *UserGRPCHandler) ListAPIKeys(ctx context.Context, in *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.ListAPIKeys")
+	defer span.End()
+
+	page, perPage := pageBounds(in.GetPage(), in.GetPerPage())
+
+	apiKeys, total, err := h.apiKeyUsecase.ListAPIKeys(ctx, perPage, (page-1)*perPage)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	response := make([]*pb.APIKey, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		response[i] = toPBAPIKey(apiKey)
+	}
+	return &pb.ListAPIKeysResponse{ApiKeys: response, Total: int32(total)}, nil
+}
+
+// pageBounds applies the default and cap to a requested page and page size
+func pageBounds(page, perPage int32) (int, int) {
+	if perPage <= 0 {
+		perPage = defaultPageSize
+	}
+	return max(int(page), 1), min(int(perPage), maxPageSize)
+}
+
+func (h *UserGRPCHandler) VerifyAPIKey(ctx context.Context, in *pb.VerifyAPIKeyRequest) (*pb.VerifyAPIKeyResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.VerifyAPIKey")
+	defer span.End()
+
+	if in.GetKey() == "" {
+		return nil, status.Error(grpccodes.Unauthenticated, domain.ErrInvalidAPIKey.Error())
+	}
+
+	apiKey, err := h.apiKeyUsecase.VerifyAPIKey(ctx, in.GetKey())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, domain.ErrInvalidAPIKey) {
+			return nil, status.Error(grpccodes.Unauthenticated, err.Error())
+		}
+		return nil, err
+	}
+
+	return &pb.VerifyAPIKeyResponse{ApiKey: toPBAPIKey(apiKey)}, nil
+}
+
+func toPBAPIKey(apiKey *dto.APIKeyResponse) *pb.APIKey {
+	response := &pb.APIKey{
+		Id:        int32(apiKey.ID),
+		Label:     apiKey.Label,
+		Scopes:    apiKey.Scopes,
+		Prefix:    apiKey.Prefix,
+		UserId:    int32(apiKey.UserID),
+		CreatedAt: apiKey.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if apiKey.LastUsedAt != nil {
+		response.LastUsedAt = apiKey.LastUsedAt.UTC().Format(time.RFC3339)
+	}
+	if apiKey.RevokedAt != nil {
+		response.RevokedAt = apiKey.RevokedAt.UTC().Format(time.RFC3339)
+	}
+	return response
+}
@@ -2,39 +2,44 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
 	"github.com/kareemhamed001/e-commerce/pkg/jwt"
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	"github.com/kareemhamed001/e-commerce/services/UserService/internal/delivery/grpc/dto"
 	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
 	pb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type UserGRPCHandler struct {
 	pb.UnimplementedUserServiceServer
-	userUsecase    domain.UserUsecaseInterface
-	addressUsecase domain.AddressUsecaseInterface
-	validate       *validator.Validate
-	jwtManager     *jwt.JWTManager
-	tracer         trace.Tracer
-	internalAuthToken string
+	userUsecase      domain.UserUsecaseInterface
+	addressUsecase   domain.AddressUsecaseInterface
+	apiKeyUsecase    domain.APIKeyUsecaseInterface
+	twoFactorUsecase domain.TwoFactorUsecaseInterface
+	validate         *validator.Validate
+	jwtManager       *jwt.JWTManager
+	tracer           trace.Tracer
 }
 
-func NewUserGRPCHandler(userUsecase domain.UserUsecaseInterface, addressUsecase domain.AddressUsecaseInterface, validate *validator.Validate, jwtManager *jwt.JWTManager, internalAuthToken string) *UserGRPCHandler {
+func NewUserGRPCHandler(userUsecase domain.UserUsecaseInterface, addressUsecase domain.AddressUsecaseInterface, apiKeyUsecase domain.APIKeyUsecaseInterface, twoFactorUsecase domain.TwoFactorUsecaseInterface, validate *validator.Validate, jwtManager *jwt.JWTManager) *UserGRPCHandler {
 	return &UserGRPCHandler{
-		userUsecase:    userUsecase,
-		addressUsecase: addressUsecase,
-		validate:       validate,
-		jwtManager:     jwtManager,
-		tracer:         otel.Tracer("user_GRPC_handler"),
-		internalAuthToken: internalAuthToken,
+		userUsecase:      userUsecase,
+		addressUsecase:   addressUsecase,
+		apiKeyUsecase:    apiKeyUsecase,
+		twoFactorUsecase: twoFactorUsecase,
+		validate:         validate,
+		jwtManager:       jwtManager,
+		tracer:           otel.Tracer("user_GRPC_handler"),
 	}
 }
 
@@ -104,7 +109,7 @@ func (h *UserGRPCHandler) Login(ctx context.Context, in *pb.LoginRequest) (*pb.L
 
 	userResponse, err := h.userUsecase.Login(loginCtx, loginRequestDto.Email, loginRequestDto.Password)
 	if err != nil {
-		err = domain.ErrInvalidCredentials
+		err = loginStatus(err)
 		loginSpan.RecordError(err)
 		loginSpan.SetStatus(codes.Error, err.Error())
 		loginSpan.End()
@@ -112,8 +117,18 @@ func (h *UserGRPCHandler) Login(ctx context.Context, in *pb.LoginRequest) (*pb.L
 	}
 	loginSpan.End()
 
+	twoFactor, err := h.twoFactorUsecase.TwoFactorEnabled(ctx, userResponse.ID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if twoFactor {
+		return h.twoFactorChallenge(ctx, userResponse.ID)
+	}
+
 	_, jwtSpan := h.tracer.Start(ctx, "Generate JWT Token")
-	token, err := h.jwtManager.Generate(userResponse.ID, userResponse.Email, userResponse.Role)
+	token, err := h.jwtManager.Generate(userResponse.ID, userResponse.Email, userResponse.Roles...)
 	if err != nil {
 		jwtSpan.RecordError(err)
 		jwtSpan.SetStatus(codes.Error, err.Error())
@@ -127,6 +142,19 @@ func (h *UserGRPCHandler) Login(ctx context.Context, in *pb.LoginRequest) (*pb.L
 	}, nil
 }
 
+// loginStatus hides why a login failed behind invalid credentials, except for throttling and lockouts, which
+// read the same whether the email has an account or not. Throttling is Aborted rather than ResourceExhausted,
+// which callers' circuit breakers count against this service.
+func loginStatus(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrAccountLocked):
+		return status.Error(grpccodes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrLoginThrottled):
+		return status.Error(grpccodes.Aborted, err.Error())
+	}
+	return status.Error(grpccodes.Unauthenticated, domain.ErrInvalidCredentials.Error())
+}
+
 func (h *UserGRPCHandler) GetUserByID(ctx context.Context, in *pb.GetUserByIDRequest) (*pb.User, error) {
 	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.GetUserByID")
 	defer span.End()
@@ -152,13 +180,28 @@ func (h *UserGRPCHandler) SearchUsers(ctx context.Context, in *pb.SearchUsersReq
 	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.SearchUsers")
 	defer span.End()
 
-	query := in.GetQuery()
-	page := in.GetPageNumber()
-	limit := in.GetPageSize()
+	searchUsersRequest := dto.SearchUsersRequest{
+		Query:     in.GetQuery(),
+		Role:      in.GetRole(),
+		SortBy:    in.GetSortBy(),
+		SortOrder: in.GetSortOrder(),
+	}
+	page, perPage := pageBounds(in.GetPageNumber(), in.GetPageSize())
+
+	_, validationSpan := h.tracer.Start(ctx, "Validate SearchUsersRequest")
+
+	err := h.validate.Struct(searchUsersRequest)
+	if err != nil {
+		validationSpan.RecordError(err)
+		validationSpan.SetStatus(codes.Error, err.Error())
+		validationSpan.End()
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+	validationSpan.End()
 
 	_, searchUsersSpan := h.tracer.Start(ctx, "Usecase SearchUsers")
 
-	usersResponse, err := h.userUsecase.SearchUsers(ctx, query, int(page), int(limit))
+	usersResponse, total, err := h.userUsecase.SearchUsers(ctx, &searchUsersRequest, perPage, (page-1)*perPage)
 	if err != nil {
 		searchUsersSpan.RecordError(err)
 		searchUsersSpan.SetStatus(codes.Error, err.Error())
@@ -182,6 +225,7 @@ func (h *UserGRPCHandler) SearchUsers(ctx context.Context, in *pb.SearchUsersReq
 
 	return &pb.SearchUsersResponse{
 		Users: pbUsers,
+		Total: int32(total),
 	}, nil
 }
 
@@ -236,22 +280,47 @@ func (h *UserGRPCHandler) DeleteUser(ctx context.Context, in *pb.DeleteUserReque
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return &pb.DeleteUserResponse{Success: false}, status.Error(grpccodes.NotFound, err.Error())
+		}
 		return &pb.DeleteUserResponse{Success: false}, err
 	}
 	return &pb.DeleteUserResponse{Success: true}, nil
 }
 
+func (h *UserGRPCHandler) UnlockUser(ctx context.Context, in *pb.UnlockUserRequest) (*pb.UnlockUserResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.UnlockUser")
+	defer span.End()
+
+	if in.GetId() <= 0 {
+		return nil, status.Error(grpccodes.InvalidArgument, "id is required")
+	}
+
+	err := h.userUsecase.UnlockUser(ctx, uint(in.GetId()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+	return &pb.UnlockUserResponse{Success: true}, nil
+}
+
 func (h *UserGRPCHandler) CreateAddress(ctx context.Context, in *pb.CreateAddressRequest) (*pb.CreateAddressResponse, error) {
 	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.CreateAddress")
 	defer span.End()
 
 	addressRequest := dto.CreateAddressRequest{
-		UserID:  in.GetUserId(),
-		Country: in.GetCountry(),
-		City:    in.GetCity(),
-		State:   in.GetState(),
-		Street:  in.GetStreet(),
-		ZipCode: in.GetZipCode(),
+		UserID:    in.GetUserId(),
+		Country:   in.GetCountry(),
+		City:      in.GetCity(),
+		State:     in.GetState(),
+		Street:    in.GetStreet(),
+		ZipCode:   in.GetZipCode(),
+		Label:     in.GetLabel(),
+		IsDefault: in.GetIsDefault(),
 	}
 
 	_, validationSpan := h.tracer.Start(ctx, "Validate CreateAddressRequest")
@@ -291,18 +360,23 @@ func (h *UserGRPCHandler) GetAddressByID(ctx context.Context, in *pb.GetAddressB
 		getAddressSpan.RecordError(err)
 		getAddressSpan.SetStatus(codes.Error, err.Error())
 		getAddressSpan.End()
+		if errors.Is(err, repository.ErrAddressNotFound) {
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		}
 		return nil, err
 	}
 	getAddressSpan.End()
 
 	response := &pb.Address{
-		Id:      address.ID,
-		UserId:  address.UserID,
-		Country: address.Country,
-		City:    address.City,
-		State:   address.State,
-		Street:  address.Street,
-		ZipCode: address.ZipCode,
+		Id:        address.ID,
+		UserId:    address.UserID,
+		Country:   address.Country,
+		City:      address.City,
+		State:     address.State,
+		Street:    address.Street,
+		ZipCode:   address.ZipCode,
+		Label:     address.Label,
+		IsDefault: address.IsDefault,
 	}
 
 	return &pb.GetAddressByIDResponse{Address: response}, nil
@@ -329,13 +403,15 @@ func (h *UserGRPCHandler) ListAddressesByUserID(ctx context.Context, in *pb.List
 	response := make([]*pb.Address, len(addresses))
 	for i, address := range addresses {
 		response[i] = &pb.Address{
-			Id:      address.ID,
-			UserId:  address.UserID,
-			Country: address.Country,
-			City:    address.City,
-			State:   address.State,
-			Street:  address.Street,
-			ZipCode: address.ZipCode,
+			Id:        address.ID,
+			UserId:    address.UserID,
+			Country:   address.Country,
+			City:      address.City,
+			State:     address.State,
+			Street:    address.Street,
+			ZipCode:   address.ZipCode,
+			Label:     address.Label,
+			IsDefault: address.IsDefault,
 		}
 	}
 
@@ -349,12 +425,14 @@ func (h *UserGRPCHandler) UpdateAddress(ctx context.Context, in *pb.UpdateAddres
 	_, validateAddressSpan := h.tracer.Start(ctx, "Validate UpdateAddressRequest")
 
 	updateAddressRequest := dto.UpdateAddressRequest{
-		Id:      in.GetId(),
-		Country: in.GetCountry(),
-		City:    in.GetCity(),
-		State:   in.GetState(),
-		Street:  in.GetStreet(),
-		ZipCode: in.GetZipCode(),
+		Id:               in.GetId(),
+		RequestingUserID: in.GetRequestingUserId(),
+		Country:          in.GetCountry(),
+		City:             in.GetCity(),
+		State:            in.GetState(),
+		Street:           in.GetStreet(),
+		ZipCode:          in.GetZipCode(),
+		Label:            in.GetLabel(),
 	}
 
 	err := h.validate.Struct(updateAddressRequest)
@@ -373,6 +451,12 @@ func (h *UserGRPCHandler) UpdateAddress(ctx context.Context, in *pb.UpdateAddres
 		updateAddressSpan.RecordError(err)
 		updateAddressSpan.SetStatus(codes.Error, err.Error())
 		updateAddressSpan.End()
+		switch {
+		case errors.Is(err, repository.ErrAddressNotFound):
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		case errors.Is(err, domain.ErrAddressNotOwned):
+			return nil, status.Error(grpccodes.PermissionDenied, err.Error())
+		}
 		return nil, err
 	}
 	updateAddressSpan.End()
@@ -399,7 +483,44 @@ func (h *UserGRPCHandler) DeleteAddress(ctx context.Context, in *pb.DeleteAddres
 	return &pb.DeleteAddressResponse{}, nil
 }
 
-func (h *UserGRPCHandler) Run(done <-chan any, port string) error {
+func (h *UserGRPCHandler) SetDefaultAddress(ctx context.Context, in *pb.SetDefaultAddressRequest) (*pb.SetDefaultAddressResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.SetDefaultAddress")
+	defer span.End()
+
+	request := dto.SetDefaultAddressRequest{
+		ID:     in.GetId(),
+		UserID: in.GetUserId(),
+	}
+	if err := h.validate.Struct(request); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+
+	address, err := h.addressUsecase.SetDefaultAddress(ctx, request.UserID, request.ID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, repository.ErrAddressNotFound) {
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	return &pb.SetDefaultAddressResponse{Address: &pb.Address{
+		Id:        address.ID,
+		UserId:    address.UserID,
+		Country:   address.Country,
+		City:      address.City,
+		State:     address.State,
+		Street:    address.Street,
+		ZipCode:   address.ZipCode,
+		Label:     address.Label,
+		IsDefault: address.IsDefault,
+	}}, nil
+}
+
+func (h *UserGRPCHandler) Run(done <-chan any, port string, opts ...grpc.ServerOption) error {
 	// Implementation here
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
@@ -407,7 +528,7 @@ func (h *UserGRPCHandler) Run(done <-chan any, port string) error {
 		return err
 	}
 
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(grpcmiddleware.InternalAuthUnaryServerInterceptor(h.internalAuthToken)))
+	grpcServer := grpc.NewServer(opts...)
 	pb.RegisterUserServiceServer(grpcServer, h)
 
 	go func() {
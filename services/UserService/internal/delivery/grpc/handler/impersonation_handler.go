@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+	pb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"go.opentelemetry.io/otel/codes"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// impersonationTTL is how long support staff may act as a user on one impersonation token
+const impersonationTTL = 15 * time.Minute
+
+// ImpersonateUser issues the admin behind the call a token acting as the user. The admin is taken from the
+// forwarded identity rather than the request, so only a call made for an admin can get one.
+func (h *UserGRPCHandler) ImpersonateUser(ctx context.Context, in *pb.ImpersonateUserRequest) (*pb.ImpersonateUserResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.ImpersonateUser")
+	defer span.End()
+
+	admin, ok := grpcmiddleware.IdentityFromContext(ctx)
+	if !ok || !slices.Contains(admin.Roles, string(domain.AdminRole)) {
+		return nil, status.Error(grpccodes.PermissionDenied, "only admins can impersonate users")
+	}
+	if in.GetUserId() <= 0 {
+		return nil, status.Error(grpccodes.InvalidArgument, "user_id is required")
+	}
+	if uint(in.GetUserId()) == admin.UserID {
+		return nil, status.Error(grpccodes.InvalidArgument, "admins cannot impersonate themselves")
+	}
+
+	userResponse, err := h.userUsecase.ImpersonateUser(ctx, uint(in.GetUserId()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		switch {
+		case errors.Is(err, repository.ErrUserNotFound):
+			return nil, status.Error(grpccodes.NotFound, err.Error())
+		case errors.Is(err, domain.ErrImpersonateAdmin):
+			return nil, status.Error(grpccodes.PermissionDenied, err.Error())
+		}
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(impersonationTTL)
+	token, err := h.jwtManager.GenerateImpersonation(admin.UserID, userResponse.ID, userResponse.Email, userResponse.Roles, impersonationTTL)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	logger.Infof("event=impersonation_token_issued admin_id=%d user_id=%d", admin.UserID, userResponse.ID)
+	return &pb.ImpersonateUserResponse{Token: token, ExpiresAt: expiresAt.UTC().Format(time.RFC3339)}, nil
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/grpcmiddleware"
+	"github.com/kareemhamed001/e-commerce/pkg/jwt"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+	pb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeImpersonation answers ImpersonateUser for user 7 and refuses the admin 2; the methods it does not implement
+// panic through the nil interface
+type fakeImpersonation struct {
+	domain.UserUsecaseInterface
+}
+
+func (fakeImpersonation) ImpersonateUser(_ context.Context, id uint) (*dto.UserResponse, error) {
+	switch id {
+	case 7:
+		return &dto.UserResponse{ID: 7, Email: "mona@example.com", Role: "customer", Roles: []string{"customer"}}, nil
+	case 2:
+		return nil, domain.ErrImpersonateAdmin
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func TestImpersonateUserTokenCarriesTheAdmin(t *testing.T) {
+	manager := jwt.NewJWTManager("secret", 24*time.Hour)
+	h := NewUserGRPCHandler(fakeImpersonation{}, nil, nil, nil, nil, manager)
+	ctx := grpcmiddleware.ContextWithIdentity(context.Background(), grpcmiddleware.Identity{UserID: 1, Roles: []string{"admin"}})
+
+	resp, err := h.ImpersonateUser(ctx, &pb.ImpersonateUserRequest{UserId: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := manager.Verify(resp.GetToken())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 7 || claims.ImpersonatedBy != 1 {
+		t.Errorf("token for user %d impersonated by %d, want user 7 impersonated by the admin 1", claims.UserID, claims.ImpersonatedBy)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, resp.GetExpiresAt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claims.ExpiresAt.Time.Truncate(time.Second).Equal(expiresAt) {
+		t.Errorf("expires_at = %v, want the token's %v", expiresAt, claims.ExpiresAt)
+	}
+}
+
+func TestImpersonateUserErrors(t *testing.T) {
+	admin := grpcmiddleware.Identity{UserID: 1, Roles: []string{"customer", "admin"}}
+	tests := []struct {
+		name     string
+		identity *grpcmiddleware.Identity
+		userID   int32
+		want     codes.Code
+	}{
+		{name: "no identity", userID: 7, want: codes.PermissionDenied},
+		{name: "not an admin", identity: &grpcmiddleware.Identity{UserID: 1, Roles: []string{"customer", "support"}}, userID: 7, want: codes.PermissionDenied},
+		{name: "no user", identity: &admin, want: codes.InvalidArgument},
+		{name: "themselves", identity: &admin, userID: 1, want: codes.InvalidArgument},
+		{name: "another admin", identity: &admin, userID: 2, want: codes.PermissionDenied},
+		{name: "missing user", identity: &admin, userID: 9, want: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewUserGRPCHandler(fakeImpersonation{}, nil, nil, nil, nil, jwt.NewJWTManager("secret", time.Hour))
+			ctx := context.Background()
+			if tt.identity != nil {
+				ctx = grpcmiddleware.ContextWithIdentity(ctx, *tt.identity)
+			}
+
+			resp, err := h.ImpersonateUser(ctx, &pb.ImpersonateUserRequest{UserId: tt.userID})
+			if status.Code(err) != tt.want {
+				t.Fatalf("ImpersonateUser = %v, %v, want %v", resp, err, tt.want)
+			}
+		})
+	}
+}
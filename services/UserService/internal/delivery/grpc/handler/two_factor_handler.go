@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+	pb "github.com/kareemhamed001/e-commerce/shared/proto/v1/user"
+	"go.opentelemetry.io/otel/codes"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// challengeTTL is how long a user who passed the password check has to enter a two-factor code
+const challengeTTL = 5 * time.Minute
+
+func (h *UserGRPCHandler) SetupTwoFactor(ctx context.Context, in *pb.SetupTwoFactorRequest) (*pb.SetupTwoFactorResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.SetupTwoFactor")
+	defer span.End()
+
+	if in.GetUserId() <= 0 {
+		return nil, status.Error(grpccodes.InvalidArgument, "user_id is required")
+	}
+
+	setup, err := h.twoFactorUsecase.SetupTwoFactor(ctx, uint(in.GetUserId()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, twoFactorStatus(err)
+	}
+
+	return &pb.SetupTwoFactorResponse{Secret: setup.Secret, OtpauthUrl: setup.URL}, nil
+}
+
+func (h *UserGRPCHandler) EnableTwoFactor(ctx context.Context, in *pb.EnableTwoFactorRequest) (*pb.EnableTwoFactorResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.EnableTwoFactor")
+	defer span.End()
+
+	if in.GetUserId() <= 0 {
+		return nil, status.Error(grpccodes.InvalidArgument, "user_id is required")
+	}
+	if in.GetCode() == "" {
+		return nil, status.Error(grpccodes.InvalidArgument, "code is required")
+	}
+
+	recoveryCodes, err := h.twoFactorUsecase.EnableTwoFactor(ctx, uint(in.GetUserId()), in.GetCode())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, twoFactorStatus(err)
+	}
+
+	return &pb.EnableTwoFactorResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (h *UserGRPCHandler) VerifyTwoFactor(ctx context.Context, in *pb.VerifyTwoFactorRequest) (*pb.LoginResponse, error) {
+	ctx, span := h.tracer.Start(ctx, "UserGRPCHandler.VerifyTwoFactor")
+	defer span.End()
+
+	if in.GetChallengeToken() == "" || in.GetCode() == "" {
+		return nil, status.Error(grpccodes.InvalidArgument, "challenge_token and code are required")
+	}
+
+	claims, err := h.jwtManager.VerifyChallenge(in.GetChallengeToken())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, status.Error(grpccodes.Unauthenticated, "invalid or expired challenge token")
+	}
+
+	userResponse, err := h.twoFactorUsecase.VerifyTwoFactor(ctx, claims.UserID, claims.ID, in.GetCode())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, twoFactorStatus(err)
+	}
+
+	token, err := h.jwtManager.GenerateTwoFactor(userResponse.ID, userResponse.Email, userResponse.Roles...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &pb.LoginResponse{Token: token}, nil
+}
+
+// twoFactorChallenge answers a login of a user with two-factor authentication enabled: instead of a token, a
+// challenge token good only for VerifyTwoFactor, until a code is accepted or too many are refused
+func (h *UserGRPCHandler) twoFactorChallenge(ctx context.Context, userID uint) (*pb.LoginResponse, error) {
+	challengeID, err := h.twoFactorUsecase.StartChallenge(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := h.jwtManager.GenerateChallenge(userID, challengeID, challengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LoginResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+}
+
+func twoFactorStatus(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTwoFactorCode), errors.Is(err, domain.ErrInvalidChallenge):
+		return status.Error(grpccodes.Unauthenticated, err.Error())
+	case errors.Is(err, domain.ErrAccountLocked), errors.Is(err, domain.ErrLoginThrottled):
+		return loginStatus(err)
+	case errors.Is(err, domain.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, domain.ErrTwoFactorNotSetUp),
+		errors.Is(err, domain.ErrTwoFactorNotConfigured):
+		return status.Error(grpccodes.FailedPrecondition, err.Error())
+	case errors.Is(err, repository.ErrUserNotFound):
+		return status.Error(grpccodes.NotFound, err.Error())
+	}
+	return err
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type Address struct {
 	ID      uint   `gorm:"primaryKey;autoIncrement" json:"id" validate:"-"`
 	UserID  uint   `gorm:"not null;index" json:"user_id" validate:"required"`
@@ -8,4 +10,8 @@ type Address struct {
 	State   string `gorm:"type:varchar(50);not null" json:"state" validate:"required,min=2,max=50"`
 	Street  string `gorm:"type:varchar(100);not null" json:"street" validate:"required,min=2,max=100"`
 	ZipCode string `gorm:"type:varchar(20);null" json:"zip_code" validate:"omitempty,min=2,max=20"`
+	Label   string `gorm:"type:varchar(50);null" json:"label" validate:"omitempty,max=50"`
+	// IsDefault is true for at most one address per user, enforced by a partial unique index
+	IsDefault bool      `gorm:"not null;default:false" json:"is_default" validate:"-"`
+	UpdatedAt time.Time `json:"updated_at" validate:"-"`
 }
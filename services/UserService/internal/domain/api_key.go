@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey lets a server-to-server consumer call the API as UserID, limited to Scopes. Only the SHA-256 hash
+// of the key is stored; the key itself is shown once, when it is created.
+type APIKey struct {
+	ID      uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Label   string `gorm:"type:varchar(100);not null" json:"label"`
+	KeyHash string `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	Prefix  string `gorm:"type:varchar(16);not null" json:"prefix"`
+	// Scopes is a comma-separated list of permissions, e.g. "product:write,category:write"
+	Scopes     string     `gorm:"type:varchar(255);not null" json:"scopes"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList splits Scopes into its permissions
+func (k APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
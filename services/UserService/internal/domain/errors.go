@@ -6,4 +6,17 @@ var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrHashingPassword    = errors.New("error hashing password")
+	ErrAddressNotOwned    = errors.New("address does not belong to user")
+	ErrInvalidAPIKey      = errors.New("invalid or revoked api key")
+	ErrImpersonateAdmin   = errors.New("admins cannot be impersonated")
+
+	// The login errors read the same for every email, known or not
+	ErrLoginThrottled = errors.New("too many failed login attempts")
+	ErrAccountLocked  = errors.New("account temporarily locked after too many failed login attempts, try again later")
+
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrInvalidChallenge        = errors.New("invalid or expired challenge token")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotSetUp       = errors.New("two-factor authentication has not been set up")
+	ErrTwoFactorNotConfigured  = errors.New("two-factor authentication is not configured")
 )
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// LoginAttempt counts the failed logins for an email since its last successful one. It is kept by email rather
+// than by user, so unknown emails are throttled and locked exactly like real accounts.
+type LoginAttempt struct {
+	Email        string     `gorm:"primaryKey" json:"email"`
+	Failures     int        `gorm:"not null;default:0" json:"failures"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
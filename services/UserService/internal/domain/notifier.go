@@ -0,0 +1,8 @@
+package domain
+
+import "context"
+
+// NotifierInterface sends account emails through the notification service
+type NotifierInterface interface {
+	SendWelcome(ctx context.Context, userID uint, email, name string) error
+}
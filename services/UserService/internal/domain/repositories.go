@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type UserRepositoryInterface interface {
@@ -10,9 +11,11 @@ type UserRepositoryInterface interface {
 	GetUserByEmail(context.Context, string) (User, error)
 	ListUsers(context.Context, int, int) ([]User, error)
 	ListUsersByRole(context.Context, UserRole, int, int) ([]User, error)
-	SearchUsers(context.Context, string, int, int) ([]User, error)
+	// SearchUsers returns a page of the users matching the filter and how many match in total
+	SearchUsers(context.Context, UserSearchFilter, int, int) ([]User, int, error)
 	UpdateUser(context.Context, uint, User) (User, error)
 	DeleteUser(context.Context, uint) error
+	ListRoleGrants(context.Context, uint) ([]UserRole, error)
 }
 
 type AddressRepositoryInterface interface {
@@ -21,4 +24,48 @@ type AddressRepositoryInterface interface {
 	ListAddressesByUserID(context.Context, uint, int, int) ([]Address, error)
 	UpdateAddress(context.Context, uint, Address) (Address, error)
 	DeleteAddress(context.Context, uint) error
+	SetDefaultAddress(ctx context.Context, userID, addressID uint) (Address, error)
+}
+
+type APIKeyRepositoryInterface interface {
+	CreateAPIKey(context.Context, *APIKey) (APIKey, error)
+	GetAPIKeyByHash(context.Context, string) (APIKey, error)
+	// ListAPIKeys returns a page of the keys, newest first, and how many there are in total
+	ListAPIKeys(context.Context, int, int) ([]APIKey, int, error)
+	// RevokeAPIKey marks the key revoked at the given time, keeping the first time for a key already revoked
+	RevokeAPIKey(context.Context, uint, time.Time) (APIKey, error)
+	// TouchAPIKey records the time the key was last used
+	TouchAPIKey(context.Context, uint, time.Time) error
+}
+
+type TwoFactorRepositoryInterface interface {
+	// SaveTwoFactor stores a new secret for the user, replacing one not enabled yet. It returns false, and
+	// stores nothing, when the user already has two-factor authentication enabled.
+	SaveTwoFactor(context.Context, *TwoFactor) (bool, error)
+	GetTwoFactor(context.Context, uint) (TwoFactor, error)
+	// EnableTwoFactor enables the user's secret and replaces their recovery codes with the given hashes
+	EnableTwoFactor(ctx context.Context, userID uint, enabledAt time.Time, codeHashes []string) error
+	// UseTwoFactorStep records step as the last one a code was accepted for. It returns false when step is
+	// not later than the last one, i.e. the code was used before.
+	UseTwoFactorStep(ctx context.Context, userID uint, step int64) (bool, error)
+	// UseRecoveryCode marks the user's unused recovery code with the given hash used. It returns false when
+	// there is no such code.
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string, usedAt time.Time) (bool, error)
+	// StartChallenge makes challengeID the user's pending two-factor challenge, replacing any earlier one
+	StartChallenge(ctx context.Context, userID uint, challengeID string) error
+	// FailChallenge counts a wrong code against the user's challenge challengeID, ending the challenge once
+	// maxFailures have been counted
+	FailChallenge(ctx context.Context, userID uint, challengeID string, maxFailures int) error
+	// EndChallenge ends the user's challenge challengeID. It returns false when that challenge is not pending,
+	// i.e. it was used up or replaced.
+	EndChallenge(ctx context.Context, userID uint, challengeID string) (bool, error)
+}
+
+type LoginAttemptRepositoryInterface interface {
+	GetLoginAttempt(ctx context.Context, email string) (LoginAttempt, error)
+	// RecordLoginFailure counts a failed login for email and returns the updated attempt. The failure that
+	// brings the count to lockAfter sets LockedUntil to lockedUntil; a lockAfter of 0 never locks.
+	RecordLoginFailure(ctx context.Context, email string, failedAt time.Time, lockAfter int, lockedUntil time.Time) (LoginAttempt, error)
+	// DeleteLoginAttempt forgets the failed logins for email, unlocking it
+	DeleteLoginAttempt(ctx context.Context, email string) error
 }
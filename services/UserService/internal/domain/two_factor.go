@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// TwoFactor holds a user's TOTP secret, encrypted with TOTP_ENCRYPTION_KEY. Login only asks for a code once
+// EnabledAt is set, after the user has shown their authenticator produces codes for it.
+type TwoFactor struct {
+	UserID    uint       `gorm:"primaryKey" json:"user_id"`
+	Secret    string     `gorm:"type:text;not null" json:"-"`
+	EnabledAt *time.Time `json:"enabled_at"`
+	// LastUsedStep is the time step of the last code accepted, so that no code is accepted twice
+	LastUsedStep int64 `gorm:"not null;default:0" json:"-"`
+	// ChallengeID is the jti of the challenge token of the user's pending login, empty once it is used up
+	ChallengeID *string `gorm:"type:varchar(64)" json:"-"`
+	// ChallengeFailures counts the wrong codes sent with ChallengeID
+	ChallengeFailures int       `gorm:"not null;default:0" json:"-"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (TwoFactor) TableName() string {
+	return "user_two_factor"
+}
+
+// RecoveryCode stands in for a TOTP code once, for a user who lost their authenticator. Only the SHA-256 hash
+// of the code is stored.
+type RecoveryCode struct {
+	UserID   uint       `gorm:"primaryKey" json:"user_id"`
+	CodeHash string     `gorm:"primaryKey;type:char(64)" json:"-"`
+	UsedAt   *time.Time `json:"used_at"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
@@ -12,6 +12,7 @@ type AddressUsecaseInterface interface {
 	ListAddressesByUserID(ctx context.Context, userID int32) ([]dto.AddressResponse, error)
 	UpdateAddress(ctx context.Context, req *dto.UpdateAddressRequest) error
 	DeleteAddress(ctx context.Context, addressID int32) error
+	SetDefaultAddress(ctx context.Context, userID, addressID int32) (*dto.AddressResponse, error)
 }
 
 type UserUsecaseInterface interface {
@@ -21,7 +22,37 @@ type UserUsecaseInterface interface {
 	GetUserByEmail(context.Context, string) (*dto.UserResponse, error)
 	ListUsers(context.Context, int, int) ([]*dto.UserResponse, error)
 	ListUsersByRole(context.Context, string, int, int) ([]*dto.UserResponse, error)
-	SearchUsers(context.Context, string, int, int) ([]*dto.UserResponse, error)
+	SearchUsers(context.Context, *dto.SearchUsersRequest, int, int) ([]*dto.UserResponse, int, error)
 	UpdateUser(context.Context, *dto.UpdateUserRequest) (*dto.UserResponse, error)
 	DeleteUser(context.Context, uint) error
+	// UnlockUser clears the failed logins counted against the user, lifting a lockout
+	UnlockUser(context.Context, uint) error
+	// ImpersonateUser returns the user as Login does, or ErrImpersonateAdmin for an admin
+	ImpersonateUser(context.Context, uint) (*dto.UserResponse, error)
+}
+
+type APIKeyUsecaseInterface interface {
+	// CreateAPIKey returns the new key's details and the key itself, which is not stored
+	CreateAPIKey(context.Context, *dto.CreateAPIKeyRequest) (*dto.APIKeyResponse, string, error)
+	RevokeAPIKey(context.Context, uint) (*dto.APIKeyResponse, error)
+	ListAPIKeys(context.Context, int, int) ([]*dto.APIKeyResponse, int, error)
+	// VerifyAPIKey returns the key's details, or ErrInvalidAPIKey for an unknown or revoked key
+	VerifyAPIKey(context.Context, string) (*dto.APIKeyResponse, error)
+}
+
+type TwoFactorUsecaseInterface interface {
+	// SetupTwoFactor creates a new secret for the user, which EnableTwoFactor must confirm
+	SetupTwoFactor(ctx context.Context, userID uint) (*dto.TwoFactorSetupResponse, error)
+	// EnableTwoFactor turns two-factor authentication on once code matches the new secret, and returns the
+	// user's recovery codes, which are not stored
+	EnableTwoFactor(ctx context.Context, userID uint, code string) ([]string, error)
+	// TwoFactorEnabled reports whether Login must ask the user for a code
+	TwoFactorEnabled(ctx context.Context, userID uint) (bool, error)
+	// StartChallenge begins the second step of a login that passed the password check, and returns the ID of
+	// its challenge, which replaces any earlier one of the user
+	StartChallenge(ctx context.Context, userID uint) (string, error)
+	// VerifyTwoFactor checks a code from the user's authenticator or one of their recovery codes, sent with
+	// the challenge challengeID, and returns the user as Login does. Wrong codes count as failed logins, and
+	// the challenge is used up by a right code or after a few wrong ones, answering ErrInvalidChallenge.
+	VerifyTwoFactor(ctx context.Context, userID uint, challengeID, code string) (*dto.UserResponse, error)
 }
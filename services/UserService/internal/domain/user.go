@@ -14,3 +14,30 @@ type User struct {
 	Password string   `gorm:"type:varchar(255);not null" json:"password" validate:"required,min=6"`
 	Role     UserRole `gorm:"type:varchar(50);not null" json:"role" validate:"required,oneof=admin customer"`
 }
+
+// UserRoleGrant gives a user a role on top of their primary User.Role
+type UserRoleGrant struct {
+	UserID uint     `gorm:"primaryKey" json:"user_id"`
+	Role   UserRole `gorm:"primaryKey;type:varchar(20)" json:"role"`
+}
+
+func (UserRoleGrant) TableName() string {
+	return "user_roles"
+}
+
+// UserSearchFilter narrows SearchUsers; zero values leave that dimension unfiltered
+type UserSearchFilter struct {
+	// Query matches anywhere in the name or email
+	Query string
+	// Role matches the primary role or a granted one
+	Role     UserRole
+	SortBy   UserSortField
+	SortDesc bool
+}
+
+type UserSortField string
+
+const (
+	UserSortByID        UserSortField = "id"
+	UserSortByCreatedAt UserSortField = "created_at"
+)
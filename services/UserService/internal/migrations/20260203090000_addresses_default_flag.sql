@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table addresses add column is_default boolean not null default false;
+
+-- existing users get their most recently updated address as the default
+update addresses set is_default = true
+where id in (
+    select distinct on (user_id) id
+    from addresses
+    order by user_id, updated_at desc, id desc
+);
+
+create unique index addresses_one_default_per_user on addresses(user_id) where is_default;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists addresses_one_default_per_user;
+alter table addresses drop column is_default;
+-- +goose StatementEnd
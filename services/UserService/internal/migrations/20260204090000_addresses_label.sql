@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table addresses add column label varchar(50) null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table addresses drop column label;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+create table user_roles (
+    user_id integer not null references users(id) on delete cascade,
+    role varchar(20) not null,
+    created_at timestamp with time zone default current_timestamp,
+    primary key (user_id, role)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table user_roles;
+-- +goose StatementEnd
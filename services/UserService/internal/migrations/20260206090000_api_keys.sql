@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+create table api_keys (
+    id serial primary key,
+    label varchar(100) not null,
+    key_hash char(64) not null unique,
+    prefix varchar(16) not null,
+    scopes varchar(255) not null,
+    user_id integer not null references users(id) on delete cascade,
+    created_at timestamp with time zone not null default current_timestamp,
+    last_used_at timestamp with time zone,
+    revoked_at timestamp with time zone
+);
+
+create index idx_api_keys_user on api_keys (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table api_keys;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+create table user_two_factor (
+    user_id integer primary key references users(id) on delete cascade,
+    secret text not null,
+    enabled_at timestamp with time zone,
+    last_used_step bigint not null default 0,
+    created_at timestamp with time zone not null default current_timestamp
+);
+
+create table user_recovery_codes (
+    user_id integer not null references users(id) on delete cascade,
+    code_hash char(64) not null,
+    used_at timestamp with time zone,
+    primary key (user_id, code_hash)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table user_recovery_codes;
+drop table user_two_factor;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+create table login_attempts (
+    email varchar(100) primary key,
+    failures integer not null default 0,
+    last_failed_at timestamp with time zone not null,
+    locked_until timestamp with time zone
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table login_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table user_two_factor
+    add column challenge_id varchar(64),
+    add column challenge_failures integer not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table user_two_factor
+    drop column challenge_failures,
+    drop column challenge_id;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	notificationpb "github.com/kareemhamed001/e-commerce/shared/proto/v1/notification"
+)
+
+// GRPCNotifier forwards account emails to the notification service
+type GRPCNotifier struct {
+	client notificationpb.NotificationServiceClient
+}
+
+var _ domain.NotifierInterface = (*GRPCNotifier)(nil)
+
+func NewGRPCNotifier(client notificationpb.NotificationServiceClient) *GRPCNotifier {
+	return &GRPCNotifier{client: client}
+}
+
+func (n *GRPCNotifier) SendWelcome(ctx context.Context, userID uint, email, name string) error {
+	_, err := n.client.SendNotification(ctx, &notificationpb.SendNotificationRequest{
+		Template:       "welcome",
+		RecipientEmail: email,
+		RecipientName:  name,
+		UserId:         int64(userID),
+	})
+	return err
+}
+
+// NoopNotifier is used when no notification service is configured
+type NoopNotifier struct{}
+
+var _ domain.NotifierInterface = (*NoopNotifier)(nil)
+
+func (NoopNotifier) SendWelcome(context.Context, uint, string, string) error {
+	return nil
+}
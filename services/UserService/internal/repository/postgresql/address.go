@@ -9,6 +9,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var _ domain.AddressRepositoryInterface = (*AddressRepository)(nil)
@@ -27,8 +28,34 @@ func (r *AddressRepository) CreateAddress(ctx context.Context, address *domain.A
 	_, span := r.tracer.Start(ctx, "CreateAddress")
 	defer span.End()
 
-	err := gorm.G[domain.Address](r.db).Create(ctx, address)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockDefaultAddress(ctx, tx, address.UserID); err != nil {
+			return err
+		}
+		existing, err := gorm.G[domain.Address](tx).
+			Where("user_id = ?", address.UserID).
+			Find(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(existing) == 0 {
+			address.IsDefault = true
+		}
+		if address.IsDefault && len(existing) > 0 {
+			if _, err := gorm.G[domain.Address](tx).
+				Where("user_id = ? AND is_default", address.UserID).
+				Update(ctx, "is_default", false); err != nil {
+				return err
+			}
+		}
+
+		return gorm.G[domain.Address](tx).Create(ctx, address)
+	})
 	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return domain.Address{}, err
+		}
 		return domain.Address{}, mapPostgresError(err)
 	}
 	return *address, nil
@@ -58,6 +85,7 @@ func (r *AddressRepository) ListAddressesByUserID(ctx context.Context, userID ui
 
 	addresses, err := gorm.G[domain.Address](r.db).
 		Where("user_id = ?", userID).
+		Order("is_default desc, id asc").
 		Limit(limit).
 		Offset(offset).
 		Find(ctx)
@@ -88,14 +116,115 @@ func (r *AddressRepository) UpdateAddress(ctx context.Context, id uint, address
 func (r *AddressRepository) DeleteAddress(ctx context.Context, id uint) error {
 	_, span := r.tracer.Start(ctx, "DeleteAddress")
 	defer span.End()
-	rowsAffected, err := gorm.G[domain.Address](r.db).
-		Where("id = ?", id).
-		Delete(ctx)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		owner, err := gorm.G[domain.Address](tx).Select("user_id").Where("id = ?", id).First(ctx)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return repository.ErrAddressNotFound
+			}
+			return err
+		}
+		if err := lockDefaultAddress(ctx, tx, owner.UserID); err != nil {
+			return err
+		}
+
+		// Read the flag again now that no one else can move the default
+		address, err := gorm.G[domain.Address](tx).Where("id = ?", id).First(ctx)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return repository.ErrAddressNotFound
+			}
+			return err
+		}
+		if _, err := gorm.G[domain.Address](tx).Where("id = ?", id).Delete(ctx); err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+
+		// Promote the most recently used address, approximated by the latest update
+		_, err = gorm.G[domain.Address](tx).
+			Where("id = (?)", tx.Model(&domain.Address{}).
+				Select("id").
+				Where("user_id = ?", address.UserID).
+				Order("updated_at desc, id desc").
+				Limit(1)).
+			Update(ctx, "is_default", true)
+		return err
+	})
 	if err != nil {
+		if errors.Is(err, repository.ErrAddressNotFound) {
+			return err
+		}
 		return mapPostgresError(err)
 	}
-	if rowsAffected == 0 {
-		return repository.ErrAddressNotFound
-	}
 	return nil
 }
+
+// SetDefaultAddress clears the user's current default and marks addressID instead, atomically
+func (r *AddressRepository) SetDefaultAddress(ctx context.Context, userID, addressID uint) (domain.Address, error) {
+	_, span := r.tracer.Start(ctx, "SetDefaultAddress")
+	defer span.End()
+
+	var address domain.Address
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockDefaultAddress(ctx, tx, userID); err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return repository.ErrAddressNotFound
+			}
+			return err
+		}
+
+		var err error
+		address, err = gorm.G[domain.Address](tx).
+			Where("id = ? AND user_id = ?", addressID, userID).
+			First(ctx)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return repository.ErrAddressNotFound
+			}
+			return err
+		}
+
+		if address.IsDefault {
+			return nil
+		}
+
+		if _, err := gorm.G[domain.Address](tx).
+			Where("user_id = ? AND is_default", userID).
+			Update(ctx, "is_default", false); err != nil {
+			return err
+		}
+
+		if _, err := gorm.G[domain.Address](tx).
+			Where("id = ?", addressID).
+			Update(ctx, "is_default", true); err != nil {
+			return err
+		}
+		address.IsDefault = true
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrAddressNotFound) {
+			return domain.Address{}, err
+		}
+		return domain.Address{}, mapPostgresError(err)
+	}
+	return address, nil
+}
+
+// lockDefaultAddress serializes the transactions that change which of a user's addresses is the default by
+// locking the user's row. Locking the address rows is not enough: a user's first address has none to lock,
+// and two transactions locking different addresses could each clear the old default and set their own.
+func lockDefaultAddress(ctx context.Context, tx *gorm.DB, userID uint) error {
+	_, err := gorm.G[domain.User](tx, clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", userID).
+		First(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return repository.ErrUserNotFound
+	}
+	return err
+}
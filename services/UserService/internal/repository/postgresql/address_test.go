@@ -0,0 +1,183 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+	"gorm.io/gorm"
+)
+
+func newAddressRepository(t *testing.T) (*AddressRepository, *gorm.DB) {
+	t.Helper()
+	db := newTestDB(t, &domain.User{}, &domain.Address{})
+	// The partial unique index of the migrations, the last line of defence for the invariant
+	if err := db.Exec("create unique index addresses_one_default_per_user on addresses(user_id) where is_default").Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewAddressRepository(db), db
+}
+
+func addAddress(t *testing.T, repo *AddressRepository, userID uint, city string, isDefault bool) domain.Address {
+	t.Helper()
+	address, err := repo.CreateAddress(context.Background(), &domain.Address{
+		UserID:    userID,
+		Country:   "Egypt",
+		City:      city,
+		State:     "Cairo",
+		Street:    "Main Street",
+		IsDefault: isDefault,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+// assertDefault checks that wantID is the user's only default address, or that they have none when it is 0
+func assertDefault(t *testing.T, db *gorm.DB, userID, wantID uint) {
+	t.Helper()
+	var defaults []domain.Address
+	if err := db.Where("user_id = ? AND is_default", userID).Find(&defaults).Error; err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case wantID == 0 && len(defaults) != 0:
+		t.Fatalf("user %d has default addresses %+v, want none", userID, defaults)
+	case wantID != 0 && (len(defaults) != 1 || defaults[0].ID != wantID):
+		t.Fatalf("user %d has default addresses %+v, want only %d", userID, defaults, wantID)
+	}
+}
+
+func TestFirstAddressBecomesDefault(t *testing.T) {
+	repo, db := newAddressRepository(t)
+	user := createTestUser(t, db, "first@example.com")
+
+	first := addAddress(t, repo, user.ID, "Cairo", false)
+	second := addAddress(t, repo, user.ID, "Giza", false)
+
+	if !first.IsDefault || second.IsDefault {
+		t.Errorf("first default = %v, second default = %v; want only the first", first.IsDefault, second.IsDefault)
+	}
+	assertDefault(t, db, user.ID, first.ID)
+}
+
+func TestCreateDefaultAddressReplacesDefault(t *testing.T) {
+	repo, db := newAddressRepository(t)
+	user := createTestUser(t, db, "create@example.com")
+
+	addAddress(t, repo, user.ID, "Cairo", false)
+	replacement := addAddress(t, repo, user.ID, "Giza", true)
+
+	assertDefault(t, db, user.ID, replacement.ID)
+}
+
+func TestSetDefaultAddressKeepsOneDefault(t *testing.T) {
+	repo, db := newAddressRepository(t)
+	user := createTestUser(t, db, "set@example.com")
+	other := createTestUser(t, db, "other@example.com")
+
+	first := addAddress(t, repo, user.ID, "Cairo", false)
+	second := addAddress(t, repo, user.ID, "Giza", false)
+	third := addAddress(t, repo, user.ID, "Alexandria", false)
+	otherDefault := addAddress(t, repo, other.ID, "Luxor", false)
+
+	for _, id := range []uint{second.ID, third.ID, third.ID, first.ID} {
+		address, err := repo.SetDefaultAddress(context.Background(), user.ID, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if address.ID != id || !address.IsDefault {
+			t.Fatalf("SetDefaultAddress returned %+v, want address %d marked default", address, id)
+		}
+		assertDefault(t, db, user.ID, id)
+	}
+	// Other users' defaults are left alone
+	assertDefault(t, db, other.ID, otherDefault.ID)
+}
+
+func TestSetDefaultAddressOfAnotherUser(t *testing.T) {
+	repo, db := newAddressRepository(t)
+	user := createTestUser(t, db, "owner@example.com")
+	other := createTestUser(t, db, "intruder@example.com")
+
+	owned := addAddress(t, repo, user.ID, "Cairo", false)
+	otherAddress := addAddress(t, repo, other.ID, "Giza", false)
+
+	for _, tt := range []struct {
+		name      string
+		userID    uint
+		addressID uint
+	}{
+		{name: "address of another user", userID: other.ID, addressID: owned.ID},
+		{name: "unknown address", userID: user.ID, addressID: owned.ID + 100},
+		{name: "unknown user", userID: other.ID + 100, addressID: owned.ID},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repo.SetDefaultAddress(context.Background(), tt.userID, tt.addressID)
+			if !errors.Is(err, repository.ErrAddressNotFound) {
+				t.Fatalf("SetDefaultAddress = %v, want ErrAddressNotFound", err)
+			}
+		})
+	}
+	assertDefault(t, db, user.ID, owned.ID)
+	assertDefault(t, db, other.ID, otherAddress.ID)
+}
+
+func TestDeleteDefaultAddressPromotesAnother(t *testing.T) {
+	repo, db := newAddressRepository(t)
+	user := createTestUser(t, db, "delete@example.com")
+
+	first := addAddress(t, repo, user.ID, "Cairo", false)
+	second := addAddress(t, repo, user.ID, "Giza", false)
+	third := addAddress(t, repo, user.ID, "Alexandria", false)
+
+	// Deleting an address that is not the default leaves the default alone
+	if err := repo.DeleteAddress(context.Background(), second.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertDefault(t, db, user.ID, first.ID)
+
+	if err := repo.DeleteAddress(context.Background(), first.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertDefault(t, db, user.ID, third.ID)
+
+	// Deleting the last address leaves no default
+	if err := repo.DeleteAddress(context.Background(), third.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertDefault(t, db, user.ID, 0)
+
+	if err := repo.DeleteAddress(context.Background(), third.ID); !errors.Is(err, repository.ErrAddressNotFound) {
+		t.Fatalf("deleting a deleted address = %v, want ErrAddressNotFound", err)
+	}
+}
+
+func TestListAddressesPutsDefaultFirst(t *testing.T) {
+	repo, db := newAddressRepository(t)
+	user := createTestUser(t, db, "list@example.com")
+
+	addAddress(t, repo, user.ID, "Cairo", false)
+	addAddress(t, repo, user.ID, "Giza", false)
+	last := addAddress(t, repo, user.ID, "Alexandria", true)
+
+	addresses, err := repo.ListAddressesByUserID(context.Background(), user.ID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addresses) != 3 || addresses[0].ID != last.ID {
+		t.Fatalf("addresses = %+v, want the default %d first", addresses, last.ID)
+	}
+}
+
+func TestCreateAddressForUnknownUser(t *testing.T) {
+	repo, _ := newAddressRepository(t)
+
+	_, err := repo.CreateAddress(context.Background(), &domain.Address{UserID: 404, Country: "Egypt", City: "Cairo", State: "Cairo", Street: "Main Street"})
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		t.Fatalf("CreateAddress = %v, want ErrUserNotFound", err)
+	}
+}
@@ -0,0 +1,108 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ domain.APIKeyRepositoryInterface = (*APIKeyRepository)(nil)
+
+type APIKeyRepository struct {
+	db     *gorm.DB
+	tracer trace.Tracer
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db, tracer: otel.Tracer("api-key-repo")}
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) (domain.APIKey, error) {
+	ctx, span := r.tracer.Start(ctx, "APIKeyRepository.CreateAPIKey")
+	defer span.End()
+
+	if err := gorm.G[domain.APIKey](r.db).Create(ctx, key); err != nil {
+		return domain.APIKey{}, mapPostgresError(err)
+	}
+	return *key, nil
+}
+
+func (r *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, hash string) (domain.APIKey, error) {
+	ctx, span := r.tracer.Start(ctx, "APIKeyRepository.GetAPIKeyByHash")
+	defer span.End()
+
+	key, err := gorm.G[domain.APIKey](r.db).Where("key_hash = ?", hash).First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.APIKey{}, repository.ErrAPIKeyNotFound
+		}
+		return domain.APIKey{}, mapPostgresError(err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context, limit, offset int) ([]domain.APIKey, int, error) {
+	ctx, span := r.tracer.Start(ctx, "APIKeyRepository.ListAPIKeys")
+	defer span.End()
+
+	keys, err := gorm.G[domain.APIKey](r.db).Order("id desc").Limit(limit).Offset(offset).Find(ctx)
+	if err != nil {
+		return nil, 0, mapPostgresError(err)
+	}
+
+	total, err := gorm.G[domain.APIKey](r.db).Count(ctx, "*")
+	if err != nil {
+		return nil, 0, mapPostgresError(err)
+	}
+	return keys, int(total), nil
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) (domain.APIKey, error) {
+	ctx, span := r.tracer.Start(ctx, "APIKeyRepository.RevokeAPIKey")
+	defer span.End()
+
+	var key domain.APIKey
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		key, err = gorm.G[domain.APIKey](tx, clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(ctx)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return repository.ErrAPIKeyNotFound
+			}
+			return err
+		}
+		if key.RevokedAt != nil {
+			return nil
+		}
+
+		if _, err := gorm.G[domain.APIKey](tx).Where("id = ?", id).Update(ctx, "revoked_at", revokedAt); err != nil {
+			return err
+		}
+		key.RevokedAt = &revokedAt
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return domain.APIKey{}, err
+		}
+		return domain.APIKey{}, mapPostgresError(err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	ctx, span := r.tracer.Start(ctx, "APIKeyRepository.TouchAPIKey")
+	defer span.End()
+
+	if _, err := gorm.G[domain.APIKey](r.db).Where("id = ?", id).Update(ctx, "last_used_at", usedAt); err != nil {
+		return mapPostgresError(err)
+	}
+	return nil
+}
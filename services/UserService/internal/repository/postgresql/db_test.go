@@ -0,0 +1,36 @@
+package postgresql
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens an empty SQLite database with the tables of models. SQLite ignores row locks, so tests
+// check what each transaction leaves behind rather than how concurrent ones interleave.
+func newTestDB(t *testing.T, models ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func createTestUser(t *testing.T, db *gorm.DB, email string) domain.User {
+	t.Helper()
+	user := domain.User{Name: "Test User", Email: email, Password: "hashed", Role: domain.CustomerRole}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
@@ -0,0 +1,78 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ domain.LoginAttemptRepositoryInterface = (*LoginAttemptRepository)(nil)
+
+type LoginAttemptRepository struct {
+	db     *gorm.DB
+	tracer trace.Tracer
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db, tracer: otel.Tracer("login-attempt-repo")}
+}
+
+func (r *LoginAttemptRepository) GetLoginAttempt(ctx context.Context, email string) (domain.LoginAttempt, error) {
+	ctx, span := r.tracer.Start(ctx, "LoginAttemptRepository.GetLoginAttempt")
+	defer span.End()
+
+	attempt, err := gorm.G[domain.LoginAttempt](r.db).Where("email = ?", email).First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.LoginAttempt{}, repository.ErrLoginAttemptNotFound
+		}
+		return domain.LoginAttempt{}, mapPostgresError(err)
+	}
+	return attempt, nil
+}
+
+func (r *LoginAttemptRepository) RecordLoginFailure(ctx context.Context, email string, failedAt time.Time, lockAfter int, lockedUntil time.Time) (domain.LoginAttempt, error) {
+	ctx, span := r.tracer.Start(ctx, "LoginAttemptRepository.RecordLoginFailure")
+	defer span.End()
+
+	attempt := domain.LoginAttempt{Email: email, Failures: 1, LastFailedAt: failedAt}
+	if lockAfter == 1 {
+		attempt.LockedUntil = &lockedUntil
+	}
+
+	// Counted in the database, so concurrent failures all add up
+	err := r.db.WithContext(ctx).Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "email"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"failures":       gorm.Expr("login_attempts.failures + 1"),
+				"last_failed_at": failedAt,
+				"locked_until": gorm.Expr("CASE WHEN login_attempts.failures + 1 = ? THEN ?::timestamptz ELSE login_attempts.locked_until END",
+					lockAfter, lockedUntil),
+			}),
+		},
+		clause.Returning{},
+	).Create(&attempt).Error
+	if err != nil {
+		return domain.LoginAttempt{}, mapPostgresError(err)
+	}
+	return attempt, nil
+}
+
+func (r *LoginAttemptRepository) DeleteLoginAttempt(ctx context.Context, email string) error {
+	ctx, span := r.tracer.Start(ctx, "LoginAttemptRepository.DeleteLoginAttempt")
+	defer span.End()
+
+	_, err := gorm.G[domain.LoginAttempt](r.db).Where("email = ?", email).Delete(ctx)
+	if err != nil {
+		return mapPostgresError(err)
+	}
+	return nil
+}
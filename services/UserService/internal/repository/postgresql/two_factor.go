@@ -0,0 +1,158 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ domain.TwoFactorRepositoryInterface = (*TwoFactorRepository)(nil)
+
+type TwoFactorRepository struct {
+	db     *gorm.DB
+	tracer trace.Tracer
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db, tracer: otel.Tracer("two-factor-repo")}
+}
+
+func (r *TwoFactorRepository) SaveTwoFactor(ctx context.Context, twoFactor *domain.TwoFactor) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "TwoFactorRepository.SaveTwoFactor")
+	defer span.End()
+
+	// A secret that was set up but never enabled is replaced; an enabled one is kept
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "last_used_step", "created_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_two_factor.enabled_at IS NULL"}}},
+	}).Create(twoFactor)
+	if result.Error != nil {
+		return false, mapPostgresError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *TwoFactorRepository) GetTwoFactor(ctx context.Context, userID uint) (domain.TwoFactor, error) {
+	ctx, span := r.tracer.Start(ctx, "TwoFactorRepository.GetTwoFactor")
+	defer span.End()
+
+	twoFactor, err := gorm.G[domain.TwoFactor](r.db).Where("user_id = ?", userID).First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.TwoFactor{}, repository.ErrTwoFactorNotFound
+		}
+		return domain.TwoFactor{}, mapPostgresError(err)
+	}
+	return twoFactor, nil
+}
+
+func (r *TwoFactorRepository) EnableTwoFactor(ctx context.Context, userID uint, enabledAt time.Time, codeHashes []string) error {
+	ctx, span := r.tracer.Start(ctx, "TwoFactorRepository.EnableTwoFactor")
+	defer span.End()
+
+	codes := make([]domain.RecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = domain.RecoveryCode{UserID: userID, CodeHash: hash}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		rowsAffected, err := gorm.G[domain.TwoFactor](tx).Where("user_id = ?", userID).Update(ctx, "enabled_at", enabledAt)
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return repository.ErrTwoFactorNotFound
+		}
+
+		if _, err := gorm.G[domain.RecoveryCode](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+		return gorm.G[domain.RecoveryCode](tx).CreateInBatches(ctx, &codes, len(codes))
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return err
+		}
+		return mapPostgresError(err)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) UseTwoFactorStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "TwoFactorRepository.UseTwoFactorStep")
+	defer span.End()
+
+	// The condition makes two requests racing with the same code accept it only once
+	rowsAffected, err := gorm.G[domain.TwoFactor](r.db).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update(ctx, "last_used_step", step)
+	if err != nil {
+		return false, mapPostgresError(err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, usedAt time.Time) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "TwoFactorRepository.UseRecoveryCode")
+	defer span.End()
+
+	rowsAffected, err := gorm.G[domain.RecoveryCode](r.db).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update(ctx, "used_at", usedAt)
+	if err != nil {
+		return false, mapPostgresError(err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *TwoFactorRepository) StartChallenge(ctx context.Context, userID uint, challengeID string) error {
+	ctx, span := r.tracer.Start(ctx, "TwoFactorRepository.StartChallenge")
+	defer span.End()
+
+	err := r.db.WithContext(ctx).Model(&domain.TwoFactor{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{"challenge_id": challengeID, "challenge_failures": 0}).Error
+	if err != nil {
+		return mapPostgresError(err)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) FailChallenge(ctx context.Context, userID uint, challengeID string, maxFailures int) error {
+	ctx, span := r.tracer.Start(ctx, "TwoFactorRepository.FailChallenge")
+	defer span.End()
+
+	// Counted in the database, so concurrent wrong codes all add up
+	err := r.db.WithContext(ctx).Model(&domain.TwoFactor{}).
+		Where("user_id = ? AND challenge_id = ?", userID, challengeID).
+		Updates(map[string]any{
+			"challenge_failures": gorm.Expr("challenge_failures + 1"),
+			"challenge_id":       gorm.Expr("CASE WHEN challenge_failures + 1 >= ? THEN NULL ELSE challenge_id END", maxFailures),
+		}).Error
+	if err != nil {
+		return mapPostgresError(err)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) EndChallenge(ctx context.Context, userID uint, challengeID string) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "TwoFactorRepository.EndChallenge")
+	defer span.End()
+
+	// The condition makes two requests racing with the same challenge end it only once
+	rowsAffected, err := gorm.G[domain.TwoFactor](r.db).
+		Where("user_id = ? AND challenge_id = ?", userID, challengeID).
+		Update(ctx, "challenge_id", nil)
+	if err != nil {
+		return false, mapPostgresError(err)
+	}
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,65 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+)
+
+func TestChallengeEndsAfterMaxFailuresOrOnce(t *testing.T) {
+	db := newTestDB(t, &domain.User{}, &domain.TwoFactor{})
+	repo := NewTwoFactorRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "mona@example.com")
+	if _, err := repo.SaveTwoFactor(ctx, &domain.TwoFactor{UserID: user.ID, Secret: "sealed", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	pending := func() (string, int) {
+		t.Helper()
+		twoFactor, err := repo.GetTwoFactor(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if twoFactor.ChallengeID == nil {
+			return "", twoFactor.ChallengeFailures
+		}
+		return *twoFactor.ChallengeID, twoFactor.ChallengeFailures
+	}
+
+	if err := repo.StartChallenge(ctx, user.ID, "first"); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := repo.FailChallenge(ctx, user.ID, "first", 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if id, failures := pending(); id != "first" || failures != 2 {
+		t.Fatalf("after 2 failures: challenge %q with %d failures, want first with 2", id, failures)
+	}
+	if err := repo.FailChallenge(ctx, user.ID, "first", 3); err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := pending(); id != "" {
+		t.Fatalf("after 3 failures: challenge %q, want none", id)
+	}
+
+	// A new challenge starts its count over, and ends on its first success only
+	if err := repo.StartChallenge(ctx, user.ID, "second"); err != nil {
+		t.Fatal(err)
+	}
+	if id, failures := pending(); id != "second" || failures != 0 {
+		t.Fatalf("new challenge %q with %d failures, want second with none", id, failures)
+	}
+	if ended, err := repo.EndChallenge(ctx, user.ID, "first"); err != nil || ended {
+		t.Fatalf("ending the replaced challenge = %v, %v, want false", ended, err)
+	}
+	if ended, err := repo.EndChallenge(ctx, user.ID, "second"); err != nil || !ended {
+		t.Fatalf("ending the challenge = %v, %v, want true", ended, err)
+	}
+	if ended, err := repo.EndChallenge(ctx, user.ID, "second"); err != nil || ended {
+		t.Fatalf("ending it again = %v, %v, want false", ended, err)
+	}
+}
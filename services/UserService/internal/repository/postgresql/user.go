@@ -3,6 +3,7 @@ package postgresql
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
@@ -90,17 +91,62 @@ func (r *UserRepository) ListUsersByRole(ctx context.Context, role domain.UserRo
 	return users, nil
 }
 
-func (r *UserRepository) SearchUsers(ctx context.Context, query string, limit, offset int) ([]domain.User, error) {
-	users, err := gorm.G[domain.User](r.db).
-		Where("name ILIKE ? OR email ILIKE ?", "%"+query+"%", "%"+query+"%").
+// ListRoleGrants returns the roles granted to a user besides their primary role
+func (r *UserRepository) ListRoleGrants(ctx context.Context, userID uint) ([]domain.UserRole, error) {
+	grants, err := gorm.G[domain.UserRoleGrant](r.db).Where("user_id = ?", userID).Order("role asc").Find(ctx)
+	if err != nil {
+		return nil, mapPostgresError(err)
+	}
+
+	roles := make([]domain.UserRole, 0, len(grants))
+	for _, grant := range grants {
+		roles = append(roles, grant.Role)
+	}
+	return roles, nil
+}
+
+func (r *UserRepository) SearchUsers(ctx context.Context, filter domain.UserSearchFilter, limit, offset int) ([]domain.User, int, error) {
+	users, err := searchUsersQuery(r.db, filter).
+		Order(searchUsersOrder(filter)).
 		Limit(limit).
 		Offset(offset).
 		Find(ctx)
 	if err != nil {
-		return nil, mapPostgresError(err)
+		return nil, 0, mapPostgresError(err)
+	}
+
+	total, err := searchUsersQuery(r.db, filter).Count(ctx, "*")
+	if err != nil {
+		return nil, 0, mapPostgresError(err)
+	}
+	return users, int(total), nil
+}
+
+func searchUsersQuery(db *gorm.DB, filter domain.UserSearchFilter) gorm.ChainInterface[domain.User] {
+	pattern := "%" + filter.Query + "%"
+	query := gorm.G[domain.User](db).Where("name ILIKE ? OR email ILIKE ?", pattern, pattern)
+	if filter.Role != "" {
+		query = query.Where("role = ? OR id IN (SELECT user_id FROM user_roles WHERE role = ?)", filter.Role, filter.Role)
+	}
+	return query
+}
+
+// searchUsersOrder builds the ORDER BY clause from a whitelisted column, with id as the tie-breaker
+// so pages stay stable when many users share a created_at
+func searchUsersOrder(filter domain.UserSearchFilter) string {
+	direction := "asc"
+	if filter.SortDesc {
+		direction = "desc"
+	}
+
+	switch filter.SortBy {
+	case domain.UserSortByCreatedAt:
+		return fmt.Sprintf("%s %s, id %s", filter.SortBy, direction, direction)
+	default:
+		return "id " + direction
 	}
-	return users, nil
 }
+
 func (r *UserRepository) UpdateUser(ctx context.Context, id uint, user domain.User) (domain.User, error) {
 	rowsAffected, err := gorm.G[domain.User](r.db).
 		Where("id = ?", id).
@@ -0,0 +1,60 @@
+package postgresql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+)
+
+func TestListRoleGrants(t *testing.T) {
+	db := newTestDB(t, &domain.User{}, &domain.UserRoleGrant{})
+	repo := NewUserRepository(db)
+	agent := createTestUser(t, db, "agent@example.com")
+	other := createTestUser(t, db, "other@example.com")
+	for _, grant := range []domain.UserRoleGrant{
+		{UserID: agent.ID, Role: "support"},
+		{UserID: agent.ID, Role: "catalog_manager"},
+		{UserID: other.ID, Role: domain.AdminRole},
+	} {
+		if err := db.Create(&grant).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	roles, err := repo.ListRoleGrants(context.Background(), agent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []domain.UserRole{"catalog_manager", "support"}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("roles = %v, want %v", roles, want)
+	}
+
+	none, err := repo.ListRoleGrants(context.Background(), agent.ID+other.ID)
+	if err != nil || none == nil || len(none) != 0 {
+		t.Errorf("roles of a user without grants = %v, %v, want an empty list", none, err)
+	}
+}
+
+func TestSearchUsersOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter domain.UserSearchFilter
+		want   string
+	}{
+		{name: "default", want: "id asc"},
+		{name: "id descending", filter: domain.UserSearchFilter{SortBy: domain.UserSortByID, SortDesc: true}, want: "id desc"},
+		{name: "created_at", filter: domain.UserSearchFilter{SortBy: domain.UserSortByCreatedAt}, want: "created_at asc, id asc"},
+		{name: "created_at descending", filter: domain.UserSearchFilter{SortBy: domain.UserSortByCreatedAt, SortDesc: true}, want: "created_at desc, id desc"},
+		// Only whitelisted columns reach the SQL
+		{name: "unknown column", filter: domain.UserSearchFilter{SortBy: "password; DROP TABLE users"}, want: "id asc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := searchUsersOrder(tt.filter); got != tt.want {
+				t.Errorf("searchUsersOrder = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
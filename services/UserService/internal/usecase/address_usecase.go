@@ -61,12 +61,14 @@ func (a *AddressUsecase) CreateAddress(ctx context.Context, req *dto.CreateAddre
 	createAddressCtx, createAddressSpan := a.tracer.Start(ctx, "addressRepo.CreateAddress")
 
 	address, err := a.addressRepo.CreateAddress(createAddressCtx, &domain.Address{
-		UserID:  uint(req.UserID),
-		Country: req.Country,
-		City:    req.City,
-		State:   req.State,
-		Street:  req.Street,
-		ZipCode: req.ZipCode,
+		UserID:    uint(req.UserID),
+		Country:   req.Country,
+		City:      req.City,
+		State:     req.State,
+		Street:    req.Street,
+		ZipCode:   req.ZipCode,
+		Label:     req.Label,
+		IsDefault: req.IsDefault,
 	})
 	if err != nil {
 		createAddressSpan.RecordError(err)
@@ -98,13 +100,15 @@ func (a *AddressUsecase) GetAddressByID(ctx context.Context, addressID int32) (*
 	}
 
 	response := dto.AddressResponse{
-		ID:      int32(address.ID),
-		UserID:  int32(address.UserID),
-		Country: address.Country,
-		City:    address.City,
-		State:   address.State,
-		Street:  address.Street,
-		ZipCode: address.ZipCode,
+		ID:        int32(address.ID),
+		UserID:    int32(address.UserID),
+		Country:   address.Country,
+		City:      address.City,
+		State:     address.State,
+		Street:    address.Street,
+		ZipCode:   address.ZipCode,
+		Label:     address.Label,
+		IsDefault: address.IsDefault,
 	}
 
 	return &response, nil
@@ -128,13 +132,15 @@ func (a *AddressUsecase) ListAddressesByUserID(ctx context.Context, userID int32
 	response := make([]dto.AddressResponse, len(addresses))
 	for i, address := range addresses {
 		response[i] = dto.AddressResponse{
-			ID:      int32(address.ID),
-			UserID:  int32(address.UserID),
-			Country: address.Country,
-			City:    address.City,
-			State:   address.State,
-			Street:  address.Street,
-			ZipCode: address.ZipCode,
+			ID:        int32(address.ID),
+			UserID:    int32(address.UserID),
+			Country:   address.Country,
+			City:      address.City,
+			State:     address.State,
+			Street:    address.Street,
+			ZipCode:   address.ZipCode,
+			Label:     address.Label,
+			IsDefault: address.IsDefault,
 		}
 	}
 
@@ -145,17 +151,36 @@ func (a *AddressUsecase) UpdateAddress(ctx context.Context, req *dto.UpdateAddre
 	ctx, span := a.tracer.Start(ctx, "AddressUsecase.UpdateAddress")
 	defer span.End()
 
+	span.SetAttributes(
+		attribute.Int("address_id", int(req.Id)),
+		attribute.Int("requesting_user_id", int(req.RequestingUserID)),
+	)
+
+	existing, err := a.addressRepo.GetAddressByID(ctx, uint(req.Id))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	if existing.UserID != uint(req.RequestingUserID) {
+		err := domain.ErrAddressNotOwned
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
 	addressToUpdate := domain.Address{
 		Country: req.Country,
 		City:    req.City,
 		State:   req.State,
 		Street:  req.Street,
 		ZipCode: req.ZipCode,
+		Label:   req.Label,
 	}
 
 	updateAddressCtx, updateAddressSpan := a.tracer.Start(ctx, "addressRepo.UpdateAddress")
 
-	_, err := a.addressRepo.UpdateAddress(updateAddressCtx, uint(req.Id), addressToUpdate)
+	_, err = a.addressRepo.UpdateAddress(updateAddressCtx, uint(req.Id), addressToUpdate)
 	if err != nil {
 		updateAddressSpan.RecordError(err)
 		updateAddressSpan.SetStatus(codes.Error, err.Error())
@@ -187,3 +212,32 @@ func (a *AddressUsecase) DeleteAddress(ctx context.Context, addressID int32) err
 
 	return nil
 }
+
+func (a *AddressUsecase) SetDefaultAddress(ctx context.Context, userID, addressID int32) (*dto.AddressResponse, error) {
+	ctx, span := a.tracer.Start(ctx, "AddressUsecase.SetDefaultAddress")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("user_id", int(userID)),
+		attribute.Int("address_id", int(addressID)),
+	)
+
+	address, err := a.addressRepo.SetDefaultAddress(ctx, uint(userID), uint(addressID))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &dto.AddressResponse{
+		ID:        int32(address.ID),
+		UserID:    int32(address.UserID),
+		Country:   address.Country,
+		City:      address.City,
+		State:     address.State,
+		Street:    address.Street,
+		ZipCode:   address.ZipCode,
+		Label:     address.Label,
+		IsDefault: address.IsDefault,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+)
+
+// fakeAddressRepo holds addresses in memory, keyed by ID; the methods it does not implement panic through the
+// nil interface
+type fakeAddressRepo struct {
+	domain.AddressRepositoryInterface
+	addresses map[uint]domain.Address
+	// updated records the changes UpdateAddress was called with
+	updated []domain.Address
+}
+
+func (f *fakeAddressRepo) GetAddressByID(_ context.Context, id uint) (domain.Address, error) {
+	address, ok := f.addresses[id]
+	if !ok {
+		return domain.Address{}, repository.ErrAddressNotFound
+	}
+	return address, nil
+}
+
+func (f *fakeAddressRepo) UpdateAddress(_ context.Context, id uint, changes domain.Address) (domain.Address, error) {
+	f.updated = append(f.updated, changes)
+	return f.addresses[id], nil
+}
+
+func TestUpdateAddressChecksOwnership(t *testing.T) {
+	tests := []struct {
+		name             string
+		requestingUserID int32
+		addressID        int32
+		wantErr          error
+	}{
+		{name: "owner", requestingUserID: 7, addressID: 42},
+		{name: "other user", requestingUserID: 8, addressID: 42, wantErr: domain.ErrAddressNotOwned},
+		{name: "missing address", requestingUserID: 7, addressID: 43, wantErr: repository.ErrAddressNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addresses := &fakeAddressRepo{addresses: map[uint]domain.Address{42: {ID: 42, UserID: 7, City: "Giza"}}}
+			u := NewAddressUsecase(addresses, nil)
+
+			err := u.UpdateAddress(context.Background(), &dto.UpdateAddressRequest{
+				Id:               tt.addressID,
+				RequestingUserID: tt.requestingUserID,
+				City:             "Cairo",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateAddress = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(addresses.updated) > 0 {
+					t.Fatalf("the address was updated with %+v", addresses.updated)
+				}
+				return
+			}
+			// The owner is never part of the changes, so an update cannot move the address to another user
+			if len(addresses.updated) != 1 || addresses.updated[0].City != "Cairo" || addresses.updated[0].UserID != 0 {
+				t.Fatalf("updates = %+v, want one setting the city only", addresses.updated)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+)
+
+// maxBackoffDoublings bounds the shift in LoginPolicy.backoff, long before it could overflow
+const maxBackoffDoublings = 20
+
+// LoginPolicy is how failed logins for an email are throttled
+type LoginPolicy struct {
+	// BackoffAfter is how many failures an email gets before each further attempt has to wait; 0 never waits
+	BackoffAfter int
+	// BackoffBase is the wait after BackoffAfter failures, doubling with every failure after that
+	BackoffBase time.Duration
+	// LockAfter is how many failures lock the email for LockFor; 0 never locks
+	LockAfter int
+	// LockFor is how long a lock lasts, and how long failures count towards one
+	LockFor time.Duration
+}
+
+// check refuses an attempt while attempt is locked or backing off
+func (p LoginPolicy) check(attempt domain.LoginAttempt, now time.Time) error {
+	if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+		return domain.ErrAccountLocked
+	}
+	if p.BackoffAfter == 0 || attempt.Failures < p.BackoffAfter {
+		return nil
+	}
+
+	retryAt := attempt.LastFailedAt.Add(p.backoff(attempt.Failures))
+	if now.Before(retryAt) {
+		return fmt.Errorf("%w, retry in %ds", domain.ErrLoginThrottled, int(math.Ceil(retryAt.Sub(now).Seconds())))
+	}
+	return nil
+}
+
+// backoff returns the wait after failures failures, never longer than a lock
+func (p LoginPolicy) backoff(failures int) time.Duration {
+	wait := p.BackoffBase << min(failures-p.BackoffAfter, maxBackoffDoublings)
+	return min(wait, p.LockFor)
+}
+
+// expired reports whether attempt no longer counts: its lock ran out, or its last failure is older than a lock
+// lasts
+func (p LoginPolicy) expired(attempt domain.LoginAttempt, now time.Time) bool {
+	if attempt.LockedUntil != nil {
+		return !now.Before(*attempt.LockedUntil)
+	}
+	return now.Sub(attempt.LastFailedAt) >= p.LockFor
+}
+
+// loginKey is the email failed logins are counted under, so that differently cased spellings share a count
+func loginKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// loginLockout counts failed logins in repo and throttles them by policy. Wrong two-factor codes count as
+// failed logins too, so a password alone cannot be used to guess them.
+type loginLockout struct {
+	repo   domain.LoginAttemptRepositoryInterface
+	policy LoginPolicy
+}
+
+// allow returns the failed logins counted for key, or the error refusing another attempt while key is locked
+// or backing off
+func (l loginLockout) allow(ctx context.Context, key string, now time.Time) (domain.LoginAttempt, error) {
+	attempt, err := l.attempt(ctx, key, now)
+	if err != nil {
+		return attempt, err
+	}
+	return attempt, l.policy.check(attempt, now)
+}
+
+// attempt returns the failed logins counted for key, forgetting them once they have expired
+func (l loginLockout) attempt(ctx context.Context, key string, now time.Time) (domain.LoginAttempt, error) {
+	attempt, err := l.repo.GetLoginAttempt(ctx, key)
+	if errors.Is(err, repository.ErrLoginAttemptNotFound) {
+		return domain.LoginAttempt{}, nil
+	}
+	if err != nil || !l.policy.expired(attempt, now) {
+		return attempt, err
+	}
+
+	if err := l.repo.DeleteLoginAttempt(ctx, key); err != nil {
+		return domain.LoginAttempt{}, err
+	}
+	return domain.LoginAttempt{}, nil
+}
+
+func (l loginLockout) recordFailure(ctx context.Context, key string, now time.Time) error {
+	attempt, err := l.repo.RecordLoginFailure(ctx, key, now, l.policy.LockAfter, now.Add(l.policy.LockFor))
+	if err != nil {
+		return err
+	}
+	if attempt.Failures == l.policy.LockAfter {
+		logger.Warnf("event=account_locked email=%s failures=%d locked_until=%s", key, attempt.Failures, attempt.LockedUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// reset forgets the failed logins counted for key after a successful login; it only logs when that fails
+func (l loginLockout) reset(ctx context.Context, key string, userID uint) {
+	if err := l.repo.DeleteLoginAttempt(ctx, key); err != nil {
+		logger.Warnf("event=login_attempts_reset_failed user_id=%d error=%v", userID, err)
+	}
+}
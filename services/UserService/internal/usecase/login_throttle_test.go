@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/password"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+)
+
+func TestLoginPolicyCheck(t *testing.T) {
+	policy := LoginPolicy{BackoffAfter: 3, BackoffBase: time.Second, LockAfter: 10, LockFor: 15 * time.Minute}
+	now := time.Now()
+	failedAgo := func(failures int, ago time.Duration) domain.LoginAttempt {
+		return domain.LoginAttempt{Failures: failures, LastFailedAt: now.Add(-ago)}
+	}
+	lockedUntil := func(at time.Time) domain.LoginAttempt {
+		attempt := failedAgo(10, time.Second)
+		attempt.LockedUntil = &at
+		return attempt
+	}
+
+	tests := []struct {
+		name    string
+		policy  LoginPolicy
+		attempt domain.LoginAttempt
+		want    error
+		wantMsg string
+	}{
+		{name: "no failures", policy: policy},
+		{name: "below the backoff", policy: policy, attempt: failedAgo(2, 0)},
+		{name: "backing off", policy: policy, attempt: failedAgo(3, 0), want: domain.ErrLoginThrottled, wantMsg: "too many failed login attempts, retry in 1s"},
+		{name: "backoff waited out", policy: policy, attempt: failedAgo(3, 2*time.Second)},
+		{name: "backoff doubles", policy: policy, attempt: failedAgo(5, 2*time.Second), want: domain.ErrLoginThrottled, wantMsg: "too many failed login attempts, retry in 2s"},
+		{name: "backoff never outlasts a lock", policy: policy, attempt: failedAgo(9, 15*time.Minute)},
+		{name: "backoff off", policy: LoginPolicy{LockAfter: 10, LockFor: time.Minute}, attempt: failedAgo(9, 0)},
+		{name: "locked", policy: policy, attempt: lockedUntil(now.Add(time.Minute)), want: domain.ErrAccountLocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.check(tt.attempt, now)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("check = %v, want %v", err, tt.want)
+			}
+			if tt.wantMsg != "" && err.Error() != tt.wantMsg {
+				t.Errorf("check = %q, want %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestLoginLocksTheEmail(t *testing.T) {
+	hash, err := password.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &fakeUserRepo{users: map[uint]domain.User{7: {ID: 7, Email: "mona@example.com", Password: hash, Role: domain.CustomerRole}}}
+	attempts := &fakeLoginAttemptRepo{attempts: make(map[string]domain.LoginAttempt)}
+	u := NewUserUsecase(users, attempts, &fakeTwoFactorRepo{}, nil, LoginPolicy{LockAfter: 3, LockFor: 15 * time.Minute})
+	ctx := context.Background()
+
+	// Differently cased spellings of the email share one count
+	for _, email := range []string{"mona@example.com", "Mona@Example.com", " MONA@example.com"} {
+		if _, err := u.Login(ctx, email, "wrong"); err == nil {
+			t.Fatalf("Login(%q) with a wrong password succeeded", email)
+		}
+	}
+	if _, err := u.Login(ctx, "mona@example.com", "correct horse"); !errors.Is(err, domain.ErrAccountLocked) {
+		t.Fatalf("Login with the right password while locked = %v, want %v", err, domain.ErrAccountLocked)
+	}
+
+	// Once the lock runs out the count starts over, and a successful login clears it
+	attempt := attempts.attempts["mona@example.com"]
+	*attempt.LockedUntil = time.Now().Add(-time.Second)
+	attempts.attempts["mona@example.com"] = attempt
+	if _, err := u.Login(ctx, "mona@example.com", "wrong"); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("Login after the lock = %v, want %v", err, domain.ErrInvalidCredentials)
+	}
+	if _, err := u.Login(ctx, "mona@example.com", "correct horse"); err != nil {
+		t.Fatalf("Login = %v", err)
+	}
+	if _, ok := attempts.attempts["mona@example.com"]; ok {
+		t.Errorf("a successful login left %+v", attempts.attempts["mona@example.com"])
+	}
+
+	// Unknown emails lock exactly like accounts
+	for range 3 {
+		if _, err := u.Login(ctx, "nobody@example.com", "guess"); errors.Is(err, domain.ErrAccountLocked) {
+			t.Fatal("an unknown email locked early")
+		}
+	}
+	if _, err := u.Login(ctx, "nobody@example.com", "guess"); !errors.Is(err, domain.ErrAccountLocked) {
+		t.Errorf("Login of an unknown email after 3 failures = %v, want %v", err, domain.ErrAccountLocked)
+	}
+}
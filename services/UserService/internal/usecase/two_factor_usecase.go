@@ -0,0 +1,390 @@
+package usecase
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/totp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// recoveryCodeCount is how many recovery codes enabling two-factor authentication hands out
+	recoveryCodeCount = 10
+	// totpSkew is how many steps before or after the current one a code is still accepted from
+	totpSkew = 1
+	// challengeMaxFailures is how many wrong codes use up a challenge, after which the user has to log in again
+	challengeMaxFailures = 5
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TwoFactorUsecase struct {
+	twoFactorRepo domain.TwoFactorRepositoryInterface
+	userRepo      domain.UserRepositoryInterface
+	lockout       loginLockout
+	// aead encrypts the secrets; nil without an encryption key, which leaves two-factor authentication off
+	aead   cipher.AEAD
+	issuer string
+	tracer trace.Tracer
+}
+
+var _ domain.TwoFactorUsecaseInterface = (*TwoFactorUsecase)(nil)
+
+// NewTwoFactorUsecase creates the usecase. Secrets are stored encrypted with encryptionKey, an AES-256 key;
+// without one, two-factor authentication cannot be set up. issuer names the service in authenticator apps.
+// Wrong codes are counted in loginAttemptRepo and throttled by loginPolicy, like wrong passwords.
+func NewTwoFactorUsecase(twoFactorRepo domain.TwoFactorRepositoryInterface, userRepo domain.UserRepositoryInterface, loginAttemptRepo domain.LoginAttemptRepositoryInterface, loginPolicy LoginPolicy, encryptionKey []byte, issuer string) (domain.TwoFactorUsecaseInterface, error) {
+	usecase := &TwoFactorUsecase{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		lockout:       loginLockout{repo: loginAttemptRepo, policy: loginPolicy},
+		issuer:        issuer,
+		tracer:        otel.Tracer("two_factor_usecase"),
+	}
+	if len(encryptionKey) == 0 {
+		return usecase, nil
+	}
+
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	usecase.aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return usecase, nil
+}
+
+func (t *TwoFactorUsecase) SetupTwoFactor(ctx context.Context, userID uint) (*dto.TwoFactorSetupResponse, error) {
+	ctx, span := t.tracer.Start(ctx, "TwoFactorUsecase.SetupTwoFactor")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", int64(userID)))
+
+	if t.aead == nil {
+		return nil, domain.ErrTwoFactorNotConfigured
+	}
+
+	user, err := t.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	sealed, err := t.seal(secret)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	saved, err := t.twoFactorRepo.SaveTwoFactor(ctx, &domain.TwoFactor{UserID: userID, Secret: sealed, CreatedAt: time.Now()})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if !saved {
+		span.SetStatus(codes.Error, domain.ErrTwoFactorAlreadyEnabled.Error())
+		return nil, domain.ErrTwoFactorAlreadyEnabled
+	}
+
+	logger.Infof("event=two_factor_setup user_id=%d", userID)
+	return &dto.TwoFactorSetupResponse{
+		Secret: totp.EncodeSecret(secret),
+		URL:    totp.URL(t.issuer, user.Email, secret),
+	}, nil
+}
+
+func (t *TwoFactorUsecase) EnableTwoFactor(ctx context.Context, userID uint, code string) ([]string, error) {
+	ctx, span := t.tracer.Start(ctx, "TwoFactorUsecase.EnableTwoFactor")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", int64(userID)))
+
+	twoFactor, err := t.twoFactorRepo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			err = domain.ErrTwoFactorNotSetUp
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if twoFactor.EnabledAt != nil {
+		span.SetStatus(codes.Error, domain.ErrTwoFactorAlreadyEnabled.Error())
+		return nil, domain.ErrTwoFactorAlreadyEnabled
+	}
+
+	// The code is used up here, so it cannot also complete a login
+	if err := t.useCode(ctx, twoFactor, code); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	recoveryCodes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range recoveryCodes {
+		recoveryCodes[i], err = newRecoveryCode()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		hashes[i] = hashRecoveryCode(recoveryCodes[i])
+	}
+
+	if err := t.twoFactorRepo.EnableTwoFactor(ctx, userID, time.Now(), hashes); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	logger.Infof("event=two_factor_enabled user_id=%d", userID)
+	return recoveryCodes, nil
+}
+
+func (t *TwoFactorUsecase) TwoFactorEnabled(ctx context.Context, userID uint) (bool, error) {
+	ctx, span := t.tracer.Start(ctx, "TwoFactorUsecase.TwoFactorEnabled")
+	defer span.End()
+
+	enabled, err := twoFactorEnabled(ctx, t.twoFactorRepo, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, err
+	}
+	return enabled, nil
+}
+
+func (t *TwoFactorUsecase) StartChallenge(ctx context.Context, userID uint) (string, error) {
+	ctx, span := t.tracer.Start(ctx, "TwoFactorUsecase.StartChallenge")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", int64(userID)))
+
+	challengeID := uuid.NewString()
+	if err := t.twoFactorRepo.StartChallenge(ctx, userID, challengeID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+	return challengeID, nil
+}
+
+func (t *TwoFactorUsecase) VerifyTwoFactor(ctx context.Context, userID uint, challengeID, code string) (*dto.UserResponse, error) {
+	ctx, span := t.tracer.Start(ctx, "TwoFactorUsecase.VerifyTwoFactor")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", int64(userID)))
+
+	twoFactor, err := t.twoFactorRepo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			err = domain.ErrInvalidTwoFactorCode
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if twoFactor.EnabledAt == nil {
+		span.SetStatus(codes.Error, domain.ErrInvalidTwoFactorCode.Error())
+		return nil, domain.ErrInvalidTwoFactorCode
+	}
+	if twoFactor.ChallengeID == nil || *twoFactor.ChallengeID != challengeID {
+		span.SetStatus(codes.Error, domain.ErrInvalidChallenge.Error())
+		return nil, domain.ErrInvalidChallenge
+	}
+
+	user, err := t.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// Codes are throttled and locked under the same count as passwords, which Login left for this step to reset
+	key := loginKey(user.Email)
+	now := time.Now()
+	attempt, err := t.lockout.allow(ctx, key, now)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if isTOTPCode(code) {
+		err = t.useCode(ctx, twoFactor, code)
+	} else {
+		err = t.useRecoveryCode(ctx, userID, code)
+	}
+	if errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+		logger.Warnf("event=two_factor_failed user_id=%d", userID)
+		if recordErr := t.lockout.recordFailure(ctx, key, now); recordErr != nil {
+			err = recordErr
+		} else if failErr := t.twoFactorRepo.FailChallenge(ctx, userID, challengeID, challengeMaxFailures); failErr != nil {
+			err = failErr
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// A request racing with the same challenge may have ended it first
+	ended, err := t.twoFactorRepo.EndChallenge(ctx, userID, challengeID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if !ended {
+		span.SetStatus(codes.Error, domain.ErrInvalidChallenge.Error())
+		return nil, domain.ErrInvalidChallenge
+	}
+	if attempt.Failures > 0 {
+		t.lockout.reset(ctx, key, userID)
+	}
+
+	roles, err := rolesOf(ctx, t.userRepo, user)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &dto.UserResponse{
+		ID:    user.ID,
+		Email: user.Email,
+		Name:  user.Name,
+		Role:  string(user.Role),
+		Roles: roles,
+	}, nil
+}
+
+// twoFactorEnabled reports whether the user has two-factor authentication enabled
+func twoFactorEnabled(ctx context.Context, twoFactorRepo domain.TwoFactorRepositoryInterface, userID uint) (bool, error) {
+	twoFactor, err := twoFactorRepo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.EnabledAt != nil, nil
+}
+
+// useCode accepts a TOTP code for the secret of twoFactor once, refusing codes of a step already used
+func (t *TwoFactorUsecase) useCode(ctx context.Context, twoFactor domain.TwoFactor, code string) error {
+	if t.aead == nil {
+		return domain.ErrTwoFactorNotConfigured
+	}
+	secret, err := t.open(twoFactor.Secret)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Match(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return domain.ErrInvalidTwoFactorCode
+	}
+	fresh, err := t.twoFactorRepo.UseTwoFactorStep(ctx, twoFactor.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return domain.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (t *TwoFactorUsecase) useRecoveryCode(ctx context.Context, userID uint, code string) error {
+	used, err := t.twoFactorRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return domain.ErrInvalidTwoFactorCode
+	}
+	logger.Infof("event=two_factor_recovery_code_used user_id=%d", userID)
+	return nil
+}
+
+// seal encrypts a secret for storage, prefixed with its nonce
+func (t *TwoFactorUsecase) seal(secret []byte) (string, error) {
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(t.aead.Seal(nonce, nonce, secret, nil)), nil
+}
+
+func (t *TwoFactorUsecase) open(sealed string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < t.aead.NonceSize() {
+		return nil, errors.New("sealed two-factor secret is too short")
+	}
+	nonce, ciphertext := data[:t.aead.NonceSize()], data[t.aead.NonceSize():]
+	return t.aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func isTOTPCode(code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecoveryCode returns 80 random bits as e.g. "k3jd-72mf-xq7c-a4zt", easy to write down
+func newRecoveryCode() (string, error) {
+	random := make([]byte, 10)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(random))
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16], nil
+}
+
+// hashRecoveryCode hashes a recovery code as typed, ignoring case, dashes and spaces
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
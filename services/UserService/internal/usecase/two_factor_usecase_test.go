@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kareemhamed001/e-commerce/pkg/password"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/totp"
+)
+
+// fakeTwoFactorRepo keeps one user's two-factor row in memory; the methods it does not implement panic through
+// the nil interface
+type fakeTwoFactorRepo struct {
+	domain.TwoFactorRepositoryInterface
+	twoFactor domain.TwoFactor
+}
+
+func (f *fakeTwoFactorRepo) GetTwoFactor(_ context.Context, userID uint) (domain.TwoFactor, error) {
+	if f.twoFactor.UserID != userID {
+		return domain.TwoFactor{}, repository.ErrTwoFactorNotFound
+	}
+	return f.twoFactor, nil
+}
+
+func (f *fakeTwoFactorRepo) UseTwoFactorStep(_ context.Context, _ uint, step int64) (bool, error) {
+	if step <= f.twoFactor.LastUsedStep {
+		return false, nil
+	}
+	f.twoFactor.LastUsedStep = step
+	return true, nil
+}
+
+func (f *fakeTwoFactorRepo) UseRecoveryCode(context.Context, uint, string, time.Time) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeTwoFactorRepo) StartChallenge(_ context.Context, _ uint, challengeID string) error {
+	f.twoFactor.ChallengeID = &challengeID
+	f.twoFactor.ChallengeFailures = 0
+	return nil
+}
+
+func (f *fakeTwoFactorRepo) FailChallenge(_ context.Context, _ uint, challengeID string, maxFailures int) error {
+	if f.twoFactor.ChallengeID == nil || *f.twoFactor.ChallengeID != challengeID {
+		return nil
+	}
+	f.twoFactor.ChallengeFailures++
+	if f.twoFactor.ChallengeFailures >= maxFailures {
+		f.twoFactor.ChallengeID = nil
+	}
+	return nil
+}
+
+func (f *fakeTwoFactorRepo) EndChallenge(_ context.Context, _ uint, challengeID string) (bool, error) {
+	if f.twoFactor.ChallengeID == nil || *f.twoFactor.ChallengeID != challengeID {
+		return false, nil
+	}
+	f.twoFactor.ChallengeID = nil
+	return true, nil
+}
+
+// fakeLoginAttemptRepo counts failed logins in memory
+type fakeLoginAttemptRepo struct {
+	attempts map[string]domain.LoginAttempt
+}
+
+func (f *fakeLoginAttemptRepo) GetLoginAttempt(_ context.Context, email string) (domain.LoginAttempt, error) {
+	attempt, ok := f.attempts[email]
+	if !ok {
+		return domain.LoginAttempt{}, repository.ErrLoginAttemptNotFound
+	}
+	return attempt, nil
+}
+
+func (f *fakeLoginAttemptRepo) RecordLoginFailure(_ context.Context, email string, failedAt time.Time, lockAfter int, lockedUntil time.Time) (domain.LoginAttempt, error) {
+	attempt := f.attempts[email]
+	attempt.Email = email
+	attempt.Failures++
+	attempt.LastFailedAt = failedAt
+	if attempt.Failures == lockAfter {
+		attempt.LockedUntil = &lockedUntil
+	}
+	f.attempts[email] = attempt
+	return attempt, nil
+}
+
+func (f *fakeLoginAttemptRepo) DeleteLoginAttempt(_ context.Context, email string) error {
+	delete(f.attempts, email)
+	return nil
+}
+
+// twoFactorFixture is Mona, user 7, with two-factor authentication enabled
+type twoFactorFixture struct {
+	users     *fakeUserRepo
+	twoFactor *fakeTwoFactorRepo
+	attempts  *fakeLoginAttemptRepo
+	usecase   domain.TwoFactorUsecaseInterface
+	secret    []byte
+}
+
+func newTwoFactorFixture(t *testing.T, policy LoginPolicy) *twoFactorFixture {
+	t.Helper()
+	hash, err := password.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &twoFactorFixture{
+		users:     &fakeUserRepo{users: map[uint]domain.User{7: {ID: 7, Email: "mona@example.com", Password: hash, Role: domain.CustomerRole}}},
+		twoFactor: &fakeTwoFactorRepo{},
+		attempts:  &fakeLoginAttemptRepo{attempts: make(map[string]domain.LoginAttempt)},
+	}
+	f.usecase, err = NewTwoFactorUsecase(f.twoFactor, f.users, f.attempts, policy, make([]byte, 32), "Shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.secret, err = totp.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := f.usecase.(*TwoFactorUsecase).seal(f.secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabledAt := time.Now()
+	f.twoFactor.twoFactor = domain.TwoFactor{UserID: 7, Secret: sealed, EnabledAt: &enabledAt}
+	return f
+}
+
+// challenge starts a challenge as a password login does
+func (f *twoFactorFixture) challenge(t *testing.T) string {
+	t.Helper()
+	challengeID, err := f.usecase.StartChallenge(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return challengeID
+}
+
+// code is the authenticator's current code, which a test can only use once
+func (f *twoFactorFixture) code() string {
+	return totp.Code(f.secret, totp.Step(time.Now()))
+}
+
+func TestWrongTwoFactorCodesCountTowardsTheLockout(t *testing.T) {
+	f := newTwoFactorFixture(t, LoginPolicy{LockAfter: 3, LockFor: 15 * time.Minute})
+	challengeID := f.challenge(t)
+
+	for i := range 3 {
+		if _, err := f.usecase.VerifyTwoFactor(context.Background(), 7, challengeID, "000000"); !errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+			t.Fatalf("wrong code %d: err = %v, want %v", i+1, err, domain.ErrInvalidTwoFactorCode)
+		}
+	}
+	if failures := f.attempts.attempts["mona@example.com"].Failures; failures != 3 {
+		t.Fatalf("failures = %d, want the 3 wrong codes", failures)
+	}
+
+	// The account is locked, so even the right code is refused without being checked
+	if _, err := f.usecase.VerifyTwoFactor(context.Background(), 7, challengeID, f.code()); !errors.Is(err, domain.ErrAccountLocked) {
+		t.Fatalf("right code while locked: err = %v, want %v", err, domain.ErrAccountLocked)
+	}
+	if f.twoFactor.twoFactor.LastUsedStep != 0 {
+		t.Error("the right code was used up while the account was locked")
+	}
+}
+
+func TestFailedLoginsAreOnlyResetOnceTheCodeIsAccepted(t *testing.T) {
+	f := newTwoFactorFixture(t, LoginPolicy{LockAfter: 10, LockFor: 15 * time.Minute})
+	u := NewUserUsecase(f.users, f.attempts, f.twoFactor, nil, LoginPolicy{LockAfter: 10, LockFor: 15 * time.Minute})
+	f.attempts.attempts["mona@example.com"] = domain.LoginAttempt{Email: "mona@example.com", Failures: 4, LastFailedAt: time.Now()}
+
+	if _, err := u.Login(context.Background(), "mona@example.com", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if failures := f.attempts.attempts["mona@example.com"].Failures; failures != 4 {
+		t.Fatalf("after the password: failures = %d, want the 4 kept until the code is checked", failures)
+	}
+
+	challengeID := f.challenge(t)
+	if _, err := f.usecase.VerifyTwoFactor(context.Background(), 7, challengeID, "000000"); !errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+		t.Fatalf("wrong code: err = %v, want %v", err, domain.ErrInvalidTwoFactorCode)
+	}
+	if failures := f.attempts.attempts["mona@example.com"].Failures; failures != 5 {
+		t.Fatalf("after a wrong code: failures = %d, want 5", failures)
+	}
+
+	user, err := f.usecase.VerifyTwoFactor(context.Background(), 7, challengeID, f.code())
+	if err != nil || user.ID != 7 {
+		t.Fatalf("right code: %+v, %v, want user 7", user, err)
+	}
+	if attempt, ok := f.attempts.attempts["mona@example.com"]; ok {
+		t.Errorf("after the right code: attempt = %+v, want it forgotten", attempt)
+	}
+}
+
+func TestLoginWithoutTwoFactorResetsFailedLogins(t *testing.T) {
+	f := newTwoFactorFixture(t, LoginPolicy{})
+	f.twoFactor.twoFactor.EnabledAt = nil
+	u := NewUserUsecase(f.users, f.attempts, f.twoFactor, nil, LoginPolicy{LockAfter: 10, LockFor: 15 * time.Minute})
+	f.attempts.attempts["mona@example.com"] = domain.LoginAttempt{Email: "mona@example.com", Failures: 4, LastFailedAt: time.Now()}
+
+	if _, err := u.Login(context.Background(), "mona@example.com", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if attempt, ok := f.attempts.attempts["mona@example.com"]; ok {
+		t.Errorf("attempt = %+v, want it forgotten", attempt)
+	}
+}
+
+func TestChallengeIsUsedUp(t *testing.T) {
+	tests := []struct {
+		name string
+		// use sends codes with the challenge until it should be used up
+		use func(t *testing.T, f *twoFactorFixture, challengeID string)
+	}{
+		{
+			name: "after a right code",
+			use: func(t *testing.T, f *twoFactorFixture, challengeID string) {
+				if _, err := f.usecase.VerifyTwoFactor(context.Background(), 7, challengeID, f.code()); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "after too many wrong codes",
+			use: func(t *testing.T, f *twoFactorFixture, challengeID string) {
+				for range challengeMaxFailures {
+					if _, err := f.usecase.VerifyTwoFactor(context.Background(), 7, challengeID, "000000"); !errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+						t.Fatalf("err = %v, want %v", err, domain.ErrInvalidTwoFactorCode)
+					}
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Never locks, so only the challenge refuses the code
+			f := newTwoFactorFixture(t, LoginPolicy{})
+			challengeID := f.challenge(t)
+			tt.use(t, f, challengeID)
+
+			// A code of another step, so that only the challenge can refuse it
+			code := totp.Code(f.secret, totp.Step(time.Now())+1)
+			if _, err := f.usecase.VerifyTwoFactor(context.Background(), 7, challengeID, code); !errors.Is(err, domain.ErrInvalidChallenge) {
+				t.Fatalf("err = %v, want %v", err, domain.ErrInvalidChallenge)
+			}
+
+			// A new login starts a new challenge, which accepts the code
+			if _, err := f.usecase.VerifyTwoFactor(context.Background(), 7, f.challenge(t), code); err != nil {
+				t.Fatalf("new challenge: err = %v, want the code accepted", err)
+			}
+		})
+	}
+}
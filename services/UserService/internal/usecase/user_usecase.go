@@ -2,10 +2,15 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"time"
 
+	"github.com/kareemhamed001/e-commerce/pkg/logger"
 	"github.com/kareemhamed001/e-commerce/pkg/password"
 	"github.com/kareemhamed001/e-commerce/services/UserService/internal/delivery/grpc/dto"
 	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -24,15 +29,23 @@ import (
 // 	DeleteUser(context.Context, uint) error
 // }
 
+const notificationTimeout = 10 * time.Second
+
 type UserUsecase struct {
-	userRepo domain.UserRepositoryInterface
-	tracer   trace.Tracer
+	userRepo      domain.UserRepositoryInterface
+	twoFactorRepo domain.TwoFactorRepositoryInterface
+	notifier      domain.NotifierInterface
+	lockout       loginLockout
+	tracer        trace.Tracer
 }
 
-func NewUserUsecase(userRepo domain.UserRepositoryInterface) domain.UserUsecaseInterface {
+func NewUserUsecase(userRepo domain.UserRepositoryInterface, loginAttemptRepo domain.LoginAttemptRepositoryInterface, twoFactorRepo domain.TwoFactorRepositoryInterface, notifier domain.NotifierInterface, loginPolicy LoginPolicy) domain.UserUsecaseInterface {
 	return &UserUsecase{
-		userRepo: userRepo,
-		tracer:   otel.Tracer("user_usecase"),
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		notifier:      notifier,
+		lockout:       loginLockout{repo: loginAttemptRepo, policy: loginPolicy},
+		tracer:        otel.Tracer("user_usecase"),
 	}
 }
 
@@ -40,11 +53,27 @@ func (u *UserUsecase) Login(ctx context.Context, email, passwords string) (*dto.
 	ctx, span := u.tracer.Start(ctx, "UserUsecase.Login")
 	defer span.End()
 
+	key := loginKey(email)
+	now := time.Now()
+	attempt, err := u.lockout.allow(ctx, key, now)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	gettinUserByEmailCtx, gettingUserByEmailSpan := u.tracer.Start(ctx, "userRepo.GetUserByEmail")
 	user, err := u.userRepo.GetUserByEmail(gettinUserByEmailCtx, email)
 	if err != nil {
 		gettingUserByEmailSpan.RecordError(err)
 		gettingUserByEmailSpan.SetStatus(codes.Error, err.Error())
+		gettingUserByEmailSpan.End()
+		// Unknown emails count as failures too, so they cannot be told apart from accounts by never locking
+		if errors.Is(err, repository.ErrUserNotFound) {
+			if recordErr := u.lockout.recordFailure(ctx, key, now); recordErr != nil {
+				err = recordErr
+			}
+		}
 		return nil, err
 	}
 	gettingUserByEmailSpan.End()
@@ -58,20 +87,118 @@ func (u *UserUsecase) Login(ctx context.Context, email, passwords string) (*dto.
 		validatePasswordSpan.SetStatus(codes.Error, err.Error())
 		validatePasswordSpan.End()
 
+		if recordErr := u.lockout.recordFailure(ctx, key, now); recordErr != nil {
+			err = recordErr
+		}
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 	validatePasswordSpan.End()
 
+	// With two-factor authentication the password alone is not a successful login: the failures are only
+	// forgotten once VerifyTwoFactor accepts a code, or the codes could be guessed without ever locking
+	if attempt.Failures > 0 {
+		twoFactor, err := twoFactorEnabled(ctx, u.twoFactorRepo, user.ID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		if !twoFactor {
+			u.lockout.reset(ctx, key, user.ID)
+		}
+	}
+
+	roles, err := rolesOf(ctx, u.userRepo, user)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	return &dto.UserResponse{
 		ID:    user.ID,
 		Email: user.Email,
 		Name:  user.Name,
 		Role:  string(user.Role),
+		Roles: roles,
 	}, nil
 }
 
+// UnlockUser forgets the failed logins for the user's email, lifting a lock or backoff early
+func (u *UserUsecase) UnlockUser(ctx context.Context, id uint) error {
+	ctx, span := u.tracer.Start(ctx, "UserUsecase.UnlockUser")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", int64(id)))
+
+	user, err := u.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	if err := u.lockout.repo.DeleteLoginAttempt(ctx, loginKey(user.Email)); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	logger.Infof("event=account_unlocked user_id=%d", id)
+	return nil
+}
+
+// ImpersonateUser returns the user as Login does, for the token an admin acts as them with. Admins cannot
+// be impersonated, so an impersonation token never carries admin rights.
+func (u *UserUsecase) ImpersonateUser(ctx context.Context, id uint) (*dto.UserResponse, error) {
+	ctx, span := u.tracer.Start(ctx, "UserUsecase.ImpersonateUser")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", int64(id)))
+
+	user, err := u.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	roles, err := rolesOf(ctx, u.userRepo, user)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if slices.Contains(roles, string(domain.AdminRole)) {
+		span.SetStatus(codes.Error, domain.ErrImpersonateAdmin.Error())
+		return nil, domain.ErrImpersonateAdmin
+	}
+
+	return &dto.UserResponse{
+		ID:    user.ID,
+		Email: user.Email,
+		Name:  user.Name,
+		Role:  string(user.Role),
+		Roles: roles,
+	}, nil
+}
+
+// rolesOf returns the user's primary role followed by the roles granted on top of it
+func rolesOf(ctx context.Context, userRepo domain.UserRepositoryInterface, user domain.User) ([]string, error) {
+	grants, err := userRepo.ListRoleGrants(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []string{string(user.Role)}
+	for _, grant := range grants {
+		if grant != user.Role {
+			roles = append(roles, string(grant))
+		}
+	}
+	return roles, nil
+}
+
 func (u *UserUsecase) CreateUser(ctx context.Context, req *dto.CreateUserRequest) (*dto.UserResponse, error) {
 	ctx, span := u.tracer.Start(ctx, "UserUsecase.CreateUser")
 	defer span.End()
@@ -112,6 +239,10 @@ func (u *UserUsecase) CreateUser(ctx context.Context, req *dto.CreateUserRequest
 	}
 
 	createUserSpan.End()
+
+	// The welcome email must not block or fail registration
+	go u.sendWelcome(user.ID, user.Email, user.Name)
+
 	return &dto.UserResponse{
 		ID:    uint(user.ID),
 		Email: user.Email,
@@ -215,17 +346,28 @@ func (u *UserUsecase) ListUsersByRole(ctx context.Context, role string, limit, o
 	return userResponses, nil
 }
 
-func (u *UserUsecase) SearchUsers(ctx context.Context, query string, limit, offset int) ([]*dto.UserResponse, error) {
+func (u *UserUsecase) SearchUsers(ctx context.Context, req *dto.SearchUsersRequest, limit, offset int) ([]*dto.UserResponse, int, error) {
 	ctx, span := u.tracer.Start(ctx, "UserUsecase.SearchUsers")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("query", query), attribute.Int("limit", limit), attribute.Int("offset", offset))
-
-	users, err := u.userRepo.SearchUsers(ctx, query, limit, offset)
+	span.SetAttributes(
+		attribute.String("query", req.Query),
+		attribute.String("role", req.Role),
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	)
+
+	filter := domain.UserSearchFilter{
+		Query:    req.Query,
+		Role:     domain.UserRole(req.Role),
+		SortBy:   domain.UserSortField(req.SortBy),
+		SortDesc: req.SortOrder == "desc",
+	}
+	users, total, err := u.userRepo.SearchUsers(ctx, filter, limit, offset)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, err
+		return nil, 0, err
 	}
 
 	userResponses := make([]*dto.UserResponse, len(users))
@@ -238,7 +380,7 @@ func (u *UserUsecase) SearchUsers(ctx context.Context, query string, limit, offs
 		}
 	}
 
-	return userResponses, nil
+	return userResponses, total, nil
 }
 
 func (u *UserUsecase) UpdateUser(ctx context.Context, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
@@ -305,3 +447,12 @@ func (u *UserUsecase) DeleteUser(ctx context.Context, id uint) error {
 
 	return nil
 }
+
+func (u *UserUsecase) sendWelcome(userID uint, email, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+	defer cancel()
+
+	if err := u.notifier.SendWelcome(ctx, userID, email, name); err != nil {
+		logger.Warnf("event=welcome_email_failed email=%s error=%v", email, err)
+	}
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/delivery/grpc/dto"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/domain"
+	"github.com/kareemhamed001/e-commerce/services/UserService/internal/repository"
+)
+
+// fakeUserRepo answers GetUserByID and GetUserByEmail from users and ListRoleGrants from grants; the methods it
+// does not implement panic through the nil interface
+type fakeUserRepo struct {
+	domain.UserRepositoryInterface
+	users  map[uint]domain.User
+	grants map[uint][]domain.UserRole
+	// searched records the filter SearchUsers was called with
+	searched *domain.UserSearchFilter
+}
+
+func (f *fakeUserRepo) GetUserByID(_ context.Context, id uint) (domain.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return domain.User{}, repository.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUserRepo) GetUserByEmail(_ context.Context, email string) (domain.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return domain.User{}, repository.ErrUserNotFound
+}
+
+func (f *fakeUserRepo) ListRoleGrants(_ context.Context, userID uint) ([]domain.UserRole, error) {
+	return f.grants[userID], nil
+}
+
+func (f *fakeUserRepo) SearchUsers(_ context.Context, filter domain.UserSearchFilter, _, _ int) ([]domain.User, int, error) {
+	f.searched = &filter
+	return []domain.User{{ID: 1, Name: "Mona", Role: domain.AdminRole}}, 1, nil
+}
+
+func TestRolesOfPutsThePrimaryRoleFirst(t *testing.T) {
+	users := &fakeUserRepo{grants: map[uint][]domain.UserRole{
+		7: {domain.CustomerRole, "support"},
+	}}
+
+	tests := []struct {
+		name string
+		user domain.User
+		want []string
+	}{
+		{name: "granted roles", user: domain.User{ID: 7, Role: domain.CustomerRole}, want: []string{"customer", "support"}},
+		{name: "no grants", user: domain.User{ID: 8, Role: domain.AdminRole}, want: []string{"admin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles, err := rolesOf(context.Background(), users, tt.user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(roles, tt.want) {
+				t.Errorf("roles = %v, want %v", roles, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchUsersFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		req  dto.SearchUsersRequest
+		want domain.UserSearchFilter
+	}{
+		{name: "query only", req: dto.SearchUsersRequest{Query: "mona"}, want: domain.UserSearchFilter{Query: "mona"}},
+		{
+			name: "admins newest first",
+			req:  dto.SearchUsersRequest{Role: "admin", SortBy: "created_at", SortOrder: "desc"},
+			want: domain.UserSearchFilter{Role: domain.AdminRole, SortBy: domain.UserSortByCreatedAt, SortDesc: true},
+		},
+		{
+			name: "ascending",
+			req:  dto.SearchUsersRequest{Role: "customer", SortBy: "id", SortOrder: "asc"},
+			want: domain.UserSearchFilter{Role: domain.CustomerRole, SortBy: domain.UserSortByID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{}
+			u := NewUserUsecase(users, nil, nil, nil, LoginPolicy{})
+
+			found, total, err := u.SearchUsers(context.Background(), &tt.req, 10, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if users.searched == nil || *users.searched != tt.want {
+				t.Fatalf("searched with %+v, want %+v", users.searched, tt.want)
+			}
+			if total != 1 || len(found) != 1 || found[0].Role != "admin" {
+				t.Errorf("found %d: %+v, want Mona as admin", total, found)
+			}
+		})
+	}
+}
+
+func TestImpersonateUserRefusesAdmins(t *testing.T) {
+	users := &fakeUserRepo{
+		users: map[uint]domain.User{
+			7: {ID: 7, Email: "mona@example.com", Role: domain.CustomerRole},
+			8: {ID: 8, Role: domain.AdminRole},
+			9: {ID: 9, Role: domain.CustomerRole},
+		},
+		grants: map[uint][]domain.UserRole{
+			7: {domain.CustomerRole, "support"},
+			9: {domain.CustomerRole, domain.AdminRole},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		id      uint
+		wantErr error
+	}{
+		{name: "customer", id: 7},
+		{name: "admin", id: 8, wantErr: domain.ErrImpersonateAdmin},
+		{name: "granted admin", id: 9, wantErr: domain.ErrImpersonateAdmin},
+		{name: "missing user", id: 10, wantErr: repository.ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := NewUserUsecase(users, nil, nil, nil, LoginPolicy{})
+
+			user, err := u.ImpersonateUser(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ImpersonateUser = %+v, %v, want %v", user, err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (user.ID != 7 || !reflect.DeepEqual(user.Roles, []string{"customer", "support"})) {
+				t.Errorf("user = %+v, want user 7 with their granted roles", user)
+			}
+		})
+	}
+}
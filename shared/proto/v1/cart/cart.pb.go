@@ -24,6 +24,7 @@ const (
 type GetCartRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	GuestToken    string                 `protobuf:"bytes,2,opt,name=guest_token,json=guestToken,proto3" json:"guest_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,11 +66,19 @@ func (x *GetCartRequest) GetUserId() int64 {
 	return 0
 }
 
+func (x *GetCartRequest) GetGuestToken() string {
+	if x != nil {
+		return x.GuestToken
+	}
+	return ""
+}
+
 type AddItemRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	ProductId     int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
 	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	GuestToken    string                 `protobuf:"bytes,4,opt,name=guest_token,json=guestToken,proto3" json:"guest_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -125,11 +134,19 @@ func (x *AddItemRequest) GetQuantity() int32 {
 	return 0
 }
 
+func (x *AddItemRequest) GetGuestToken() string {
+	if x != nil {
+		return x.GuestToken
+	}
+	return ""
+}
+
 type UpdateItemRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	ProductId     int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
 	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	GuestToken    string                 `protobuf:"bytes,4,opt,name=guest_token,json=guestToken,proto3" json:"guest_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -185,10 +202,18 @@ func (x *UpdateItemRequest) GetQuantity() int32 {
 	return 0
 }
 
+func (x *UpdateItemRequest) GetGuestToken() string {
+	if x != nil {
+		return x.GuestToken
+	}
+	return ""
+}
+
 type RemoveItemRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	ProductId     int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	GuestToken    string                 `protobuf:"bytes,3,opt,name=guest_token,json=guestToken,proto3" json:"guest_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -237,9 +262,17 @@ func (x *RemoveItemRequest) GetProductId() int64 {
 	return 0
 }
 
+func (x *RemoveItemRequest) GetGuestToken() string {
+	if x != nil {
+		return x.GuestToken
+	}
+	return ""
+}
+
 type ClearCartRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	GuestToken    string                 `protobuf:"bytes,2,opt,name=guest_token,json=guestToken,proto3" json:"guest_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -281,6 +314,65 @@ func (x *ClearCartRequest) GetUserId() int64 {
 	return 0
 }
 
+func (x *ClearCartRequest) GetGuestToken() string {
+	if x != nil {
+		return x.GuestToken
+	}
+	return ""
+}
+
+type MergeCartsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	GuestToken    string                 `protobuf:"bytes,2,opt,name=guest_token,json=guestToken,proto3" json:"guest_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MergeCartsRequest) Reset() {
+	*x = MergeCartsRequest{}
+	mi := &file_shared_proto_v1_cart_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergeCartsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeCartsRequest) ProtoMessage() {}
+
+func (x *MergeCartsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_cart_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergeCartsRequest.ProtoReflect.Descriptor instead.
+func (*MergeCartsRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_cart_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MergeCartsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *MergeCartsRequest) GetGuestToken() string {
+	if x != nil {
+		return x.GuestToken
+	}
+	return ""
+}
+
 type ClearCartResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -290,7 +382,7 @@ type ClearCartResponse struct {
 
 func (x *ClearCartResponse) Reset() {
 	*x = ClearCartResponse{}
-	mi := &file_shared_proto_v1_cart_proto_msgTypes[5]
+	mi := &file_shared_proto_v1_cart_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -302,7 +394,7 @@ func (x *ClearCartResponse) String() string {
 func (*ClearCartResponse) ProtoMessage() {}
 
 func (x *ClearCartResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_cart_proto_msgTypes[5]
+	mi := &file_shared_proto_v1_cart_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -315,7 +407,7 @@ func (x *ClearCartResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClearCartResponse.ProtoReflect.Descriptor instead.
 func (*ClearCartResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_cart_proto_rawDescGZIP(), []int{5}
+	return file_shared_proto_v1_cart_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ClearCartResponse) GetSuccess() bool {
@@ -335,7 +427,7 @@ type CartItem struct {
 
 func (x *CartItem) Reset() {
 	*x = CartItem{}
-	mi := &file_shared_proto_v1_cart_proto_msgTypes[6]
+	mi := &file_shared_proto_v1_cart_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -347,7 +439,7 @@ func (x *CartItem) String() string {
 func (*CartItem) ProtoMessage() {}
 
 func (x *CartItem) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_cart_proto_msgTypes[6]
+	mi := &file_shared_proto_v1_cart_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -360,7 +452,7 @@ func (x *CartItem) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CartItem.ProtoReflect.Descriptor instead.
 func (*CartItem) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_cart_proto_rawDescGZIP(), []int{6}
+	return file_shared_proto_v1_cart_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *CartItem) GetProductId() int64 {
@@ -388,7 +480,7 @@ type CartResponse struct {
 
 func (x *CartResponse) Reset() {
 	*x = CartResponse{}
-	mi := &file_shared_proto_v1_cart_proto_msgTypes[7]
+	mi := &file_shared_proto_v1_cart_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -400,7 +492,7 @@ func (x *CartResponse) String() string {
 func (*CartResponse) ProtoMessage() {}
 
 func (x *CartResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_cart_proto_msgTypes[7]
+	mi := &file_shared_proto_v1_cart_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -413,7 +505,7 @@ func (x *CartResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CartResponse.ProtoReflect.Descriptor instead.
 func (*CartResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_cart_proto_rawDescGZIP(), []int{7}
+	return file_shared_proto_v1_cart_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *CartResponse) GetUserId() int64 {
@@ -441,25 +533,39 @@ var File_shared_proto_v1_cart_proto protoreflect.FileDescriptor
 
 const file_shared_proto_v1_cart_proto_rawDesc = "" +
 	"\n" +
-	"\x1ashared/proto/v1/cart.proto\x12\x04cart\")\n" +
+	"\x1ashared/proto/v1/cart.proto\x12\x04cart\"J\n" +
 	"\x0eGetCartRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\"d\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1f\n" +
+	"\vguest_token\x18\x02 \x01(\tR\n" +
+	"guestToken\"\x85\x01\n" +
 	"\x0eAddItemRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x02 \x01(\x03R\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x03 \x01(\x05R\bquantity\"g\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\x12\x1f\n" +
+	"\vguest_token\x18\x04 \x01(\tR\n" +
+	"guestToken\"\x88\x01\n" +
 	"\x11UpdateItemRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x02 \x01(\x03R\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x03 \x01(\x05R\bquantity\"K\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\x12\x1f\n" +
+	"\vguest_token\x18\x04 \x01(\tR\n" +
+	"guestToken\"l\n" +
 	"\x11RemoveItemRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1d\n" +
 	"\n" +
-	"product_id\x18\x02 \x01(\x03R\tproductId\"+\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\x12\x1f\n" +
+	"\vguest_token\x18\x03 \x01(\tR\n" +
+	"guestToken\"L\n" +
 	"\x10ClearCartRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\"-\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1f\n" +
+	"\vguest_token\x18\x02 \x01(\tR\n" +
+	"guestToken\"M\n" +
+	"\x11MergeCartsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1f\n" +
+	"\vguest_token\x18\x02 \x01(\tR\n" +
+	"guestToken\"-\n" +
 	"\x11ClearCartResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\"E\n" +
 	"\bCartItem\x12\x1d\n" +
@@ -469,7 +575,7 @@ const file_shared_proto_v1_cart_proto_rawDesc = "" +
 	"\fCartResponse\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12$\n" +
 	"\x05items\x18\x02 \x03(\v2\x0e.cart.CartItemR\x05items\x12%\n" +
-	"\x0etotal_quantity\x18\x03 \x01(\x05R\rtotalQuantity2\xab\x02\n" +
+	"\x0etotal_quantity\x18\x03 \x01(\x05R\rtotalQuantity2\xe6\x02\n" +
 	"\vCartService\x123\n" +
 	"\aGetCart\x12\x14.cart.GetCartRequest\x1a\x12.cart.CartResponse\x123\n" +
 	"\aAddItem\x12\x14.cart.AddItemRequest\x1a\x12.cart.CartResponse\x129\n" +
@@ -477,7 +583,9 @@ const file_shared_proto_v1_cart_proto_rawDesc = "" +
 	"UpdateItem\x12\x17.cart.UpdateItemRequest\x1a\x12.cart.CartResponse\x129\n" +
 	"\n" +
 	"RemoveItem\x12\x17.cart.RemoveItemRequest\x1a\x12.cart.CartResponse\x12<\n" +
-	"\tClearCart\x12\x16.cart.ClearCartRequest\x1a\x17.cart.ClearCartResponseB\x1bZ\x19shared/proto/v1/cart;cartb\x06proto3"
+	"\tClearCart\x12\x16.cart.ClearCartRequest\x1a\x17.cart.ClearCartResponse\x129\n" +
+	"\n" +
+	"MergeCarts\x12\x17.cart.MergeCartsRequest\x1a\x12.cart.CartResponseB\x1bZ\x19shared/proto/v1/cart;cartb\x06proto3"
 
 var (
 	file_shared_proto_v1_cart_proto_rawDescOnce sync.Once
@@ -491,31 +599,34 @@ func file_shared_proto_v1_cart_proto_rawDescGZIP() []byte {
 	return file_shared_proto_v1_cart_proto_rawDescData
 }
 
-var file_shared_proto_v1_cart_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_shared_proto_v1_cart_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_shared_proto_v1_cart_proto_goTypes = []any{
 	(*GetCartRequest)(nil),    // 0: cart.GetCartRequest
 	(*AddItemRequest)(nil),    // 1: cart.AddItemRequest
 	(*UpdateItemRequest)(nil), // 2: cart.UpdateItemRequest
 	(*RemoveItemRequest)(nil), // 3: cart.RemoveItemRequest
 	(*ClearCartRequest)(nil),  // 4: cart.ClearCartRequest
-	(*ClearCartResponse)(nil), // 5: cart.ClearCartResponse
-	(*CartItem)(nil),          // 6: cart.CartItem
-	(*CartResponse)(nil),      // 7: cart.CartResponse
+	(*MergeCartsRequest)(nil), // 5: cart.MergeCartsRequest
+	(*ClearCartResponse)(nil), // 6: cart.ClearCartResponse
+	(*CartItem)(nil),          // 7: cart.CartItem
+	(*CartResponse)(nil),      // 8: cart.CartResponse
 }
 var file_shared_proto_v1_cart_proto_depIdxs = []int32{
-	6, // 0: cart.CartResponse.items:type_name -> cart.CartItem
+	7, // 0: cart.CartResponse.items:type_name -> cart.CartItem
 	0, // 1: cart.CartService.GetCart:input_type -> cart.GetCartRequest
 	1, // 2: cart.CartService.AddItem:input_type -> cart.AddItemRequest
 	2, // 3: cart.CartService.UpdateItem:input_type -> cart.UpdateItemRequest
 	3, // 4: cart.CartService.RemoveItem:input_type -> cart.RemoveItemRequest
 	4, // 5: cart.CartService.ClearCart:input_type -> cart.ClearCartRequest
-	7, // 6: cart.CartService.GetCart:output_type -> cart.CartResponse
-	7, // 7: cart.CartService.AddItem:output_type -> cart.CartResponse
-	7, // 8: cart.CartService.UpdateItem:output_type -> cart.CartResponse
-	7, // 9: cart.CartService.RemoveItem:output_type -> cart.CartResponse
-	5, // 10: cart.CartService.ClearCart:output_type -> cart.ClearCartResponse
-	6, // [6:11] is the sub-list for method output_type
-	1, // [1:6] is the sub-list for method input_type
+	5, // 6: cart.CartService.MergeCarts:input_type -> cart.MergeCartsRequest
+	8, // 7: cart.CartService.GetCart:output_type -> cart.CartResponse
+	8, // 8: cart.CartService.AddItem:output_type -> cart.CartResponse
+	8, // 9: cart.CartService.UpdateItem:output_type -> cart.CartResponse
+	8, // 10: cart.CartService.RemoveItem:output_type -> cart.CartResponse
+	6, // 11: cart.CartService.ClearCart:output_type -> cart.ClearCartResponse
+	8, // 12: cart.CartService.MergeCarts:output_type -> cart.CartResponse
+	7, // [7:13] is the sub-list for method output_type
+	1, // [1:7] is the sub-list for method input_type
 	1, // [1:1] is the sub-list for extension type_name
 	1, // [1:1] is the sub-list for extension extendee
 	0, // [0:1] is the sub-list for field type_name
@@ -532,7 +643,7 @@ func file_shared_proto_v1_cart_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_shared_proto_v1_cart_proto_rawDesc), len(file_shared_proto_v1_cart_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
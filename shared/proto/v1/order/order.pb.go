@@ -80,8 +80,12 @@ type CreateOrderRequest struct {
 	ShippingDurationDays int32                  `protobuf:"varint,3,opt,name=shipping_duration_days,json=shippingDurationDays,proto3" json:"shipping_duration_days,omitempty"`
 	Discount             float32                `protobuf:"fixed32,4,opt,name=discount,proto3" json:"discount,omitempty"`
 	Items                []*OrderItemInput      `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`
-	unknownFields        protoimpl.UnknownFields
-	sizeCache            protoimpl.SizeCache
+	AddressId            int64                  `protobuf:"varint,6,opt,name=address_id,json=addressId,proto3" json:"address_id,omitempty"`
+	// An option from ShippingService.GetShippingQuote. When set, shipping_cost and
+	// shipping_duration_days are ignored and taken from the option instead.
+	ShippingOptionId string `protobuf:"bytes,7,opt,name=shipping_option_id,json=shippingOptionId,proto3" json:"shipping_option_id,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *CreateOrderRequest) Reset() {
@@ -149,6 +153,20 @@ func (x *CreateOrderRequest) GetItems() []*OrderItemInput {
 	return nil
 }
 
+func (x *CreateOrderRequest) GetAddressId() int64 {
+	if x != nil {
+		return x.AddressId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetShippingOptionId() string {
+	if x != nil {
+		return x.ShippingOptionId
+	}
+	return ""
+}
+
 type CreateOrderResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Order         *Order                 `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
@@ -282,10 +300,18 @@ func (x *GetOrderByIDResponse) GetOrder() *Order {
 }
 
 type ListOrdersRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
-	PerPage       int32                  `protobuf:"varint,2,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
-	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Page    int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage int32                  `protobuf:"varint,2,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	UserId  int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Status  string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	// Inclusive YYYY-MM-DD bounds on created_at
+	StartDate string `protobuf:"bytes,5,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate   string `protobuf:"bytes,6,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	// id, created_at or total; defaults to id
+	SortBy string `protobuf:"bytes,7,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	// asc or desc; defaults to desc
+	SortOrder     string `protobuf:"bytes,8,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -341,6 +367,41 @@ func (x *ListOrdersRequest) GetUserId() int64 {
 	return 0
 }
 
+func (x *ListOrdersRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListOrdersRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *ListOrdersRequest) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *ListOrdersRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListOrdersRequest) GetSortOrder() string {
+	if x != nil {
+		return x.SortOrder
+	}
+	return ""
+}
+
 type ListOrdersResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Orders        []*Order               `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
@@ -593,28 +654,29 @@ func (x *RemoveOrderItemResponse) GetOrder() *Order {
 	return nil
 }
 
-type UpdateOrderStatusRequest struct {
+type UpdateOrderItemQuantityRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	ItemId        int64                  `protobuf:"varint,2,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateOrderStatusRequest) Reset() {
-	*x = UpdateOrderStatusRequest{}
+func (x *UpdateOrderItemQuantityRequest) Reset() {
+	*x = UpdateOrderItemQuantityRequest{}
 	mi := &file_shared_proto_v1_order_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateOrderStatusRequest) String() string {
+func (x *UpdateOrderItemQuantityRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateOrderStatusRequest) ProtoMessage() {}
+func (*UpdateOrderItemQuantityRequest) ProtoMessage() {}
 
-func (x *UpdateOrderStatusRequest) ProtoReflect() protoreflect.Message {
+func (x *UpdateOrderItemQuantityRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_shared_proto_v1_order_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -626,46 +688,53 @@ func (x *UpdateOrderStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateOrderStatusRequest.ProtoReflect.Descriptor instead.
-func (*UpdateOrderStatusRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateOrderItemQuantityRequest.ProtoReflect.Descriptor instead.
+func (*UpdateOrderItemQuantityRequest) Descriptor() ([]byte, []int) {
 	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *UpdateOrderStatusRequest) GetOrderId() int64 {
+func (x *UpdateOrderItemQuantityRequest) GetOrderId() int64 {
 	if x != nil {
 		return x.OrderId
 	}
 	return 0
 }
 
-func (x *UpdateOrderStatusRequest) GetStatus() string {
+func (x *UpdateOrderItemQuantityRequest) GetItemId() int64 {
 	if x != nil {
-		return x.Status
+		return x.ItemId
 	}
-	return ""
+	return 0
 }
 
-type UpdateOrderStatusResponse struct {
+func (x *UpdateOrderItemQuantityRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type UpdateOrderItemQuantityResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Order         *Order                 `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateOrderStatusResponse) Reset() {
-	*x = UpdateOrderStatusResponse{}
+func (x *UpdateOrderItemQuantityResponse) Reset() {
+	*x = UpdateOrderItemQuantityResponse{}
 	mi := &file_shared_proto_v1_order_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateOrderStatusResponse) String() string {
+func (x *UpdateOrderItemQuantityResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateOrderStatusResponse) ProtoMessage() {}
+func (*UpdateOrderItemQuantityResponse) ProtoMessage() {}
 
-func (x *UpdateOrderStatusResponse) ProtoReflect() protoreflect.Message {
+func (x *UpdateOrderItemQuantityResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_shared_proto_v1_order_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -677,48 +746,40 @@ func (x *UpdateOrderStatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateOrderStatusResponse.ProtoReflect.Descriptor instead.
-func (*UpdateOrderStatusResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateOrderItemQuantityResponse.ProtoReflect.Descriptor instead.
+func (*UpdateOrderItemQuantityResponse) Descriptor() ([]byte, []int) {
 	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *UpdateOrderStatusResponse) GetOrder() *Order {
+func (x *UpdateOrderItemQuantityResponse) GetOrder() *Order {
 	if x != nil {
 		return x.Order
 	}
 	return nil
 }
 
-type Order struct {
-	state                protoimpl.MessageState `protogen:"open.v1"`
-	Id                   int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	UserId               int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	ShippingCost         float32                `protobuf:"fixed32,3,opt,name=shipping_cost,json=shippingCost,proto3" json:"shipping_cost,omitempty"`
-	ShippingDurationDays int32                  `protobuf:"varint,4,opt,name=shipping_duration_days,json=shippingDurationDays,proto3" json:"shipping_duration_days,omitempty"`
-	Discount             float32                `protobuf:"fixed32,5,opt,name=discount,proto3" json:"discount,omitempty"`
-	Total                float32                `protobuf:"fixed32,6,opt,name=total,proto3" json:"total,omitempty"`
-	Status               string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
-	Items                []*OrderItem           `protobuf:"bytes,8,rep,name=items,proto3" json:"items,omitempty"`
-	CreatedAt            string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt            string                 `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	unknownFields        protoimpl.UnknownFields
-	sizeCache            protoimpl.SizeCache
+type UpdateOrderStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Order) Reset() {
-	*x = Order{}
+func (x *UpdateOrderStatusRequest) Reset() {
+	*x = UpdateOrderStatusRequest{}
 	mi := &file_shared_proto_v1_order_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Order) String() string {
+func (x *UpdateOrderStatusRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Order) ProtoMessage() {}
+func (*UpdateOrderStatusRequest) ProtoMessage() {}
 
-func (x *Order) ProtoReflect() protoreflect.Message {
+func (x *UpdateOrderStatusRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_shared_proto_v1_order_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -730,108 +791,151 @@ func (x *Order) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Order.ProtoReflect.Descriptor instead.
-func (*Order) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateOrderStatusRequest.ProtoReflect.Descriptor instead.
+func (*UpdateOrderStatusRequest) Descriptor() ([]byte, []int) {
 	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *Order) GetId() int64 {
+func (x *UpdateOrderStatusRequest) GetOrderId() int64 {
 	if x != nil {
-		return x.Id
+		return x.OrderId
 	}
 	return 0
 }
 
-func (x *Order) GetUserId() int64 {
+func (x *UpdateOrderStatusRequest) GetStatus() string {
 	if x != nil {
-		return x.UserId
+		return x.Status
 	}
-	return 0
+	return ""
 }
 
-func (x *Order) GetShippingCost() float32 {
-	if x != nil {
-		return x.ShippingCost
-	}
-	return 0
+type UpdateOrderStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Order         *Order                 `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Order) GetShippingDurationDays() int32 {
-	if x != nil {
-		return x.ShippingDurationDays
-	}
-	return 0
+func (x *UpdateOrderStatusResponse) Reset() {
+	*x = UpdateOrderStatusResponse{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Order) GetDiscount() float32 {
+func (x *UpdateOrderStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOrderStatusResponse) ProtoMessage() {}
+
+func (x *UpdateOrderStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[14]
 	if x != nil {
-		return x.Discount
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *Order) GetTotal() float32 {
+// Deprecated: Use UpdateOrderStatusResponse.ProtoReflect.Descriptor instead.
+func (*UpdateOrderStatusResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UpdateOrderStatusResponse) GetOrder() *Order {
 	if x != nil {
-		return x.Total
+		return x.Order
 	}
-	return 0
+	return nil
 }
 
-func (x *Order) GetStatus() string {
+type GetRevenueSummaryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartDate     string                 `protobuf:"bytes,1,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate       string                 `protobuf:"bytes,2,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	Granularity   string                 `protobuf:"bytes,3,opt,name=granularity,proto3" json:"granularity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRevenueSummaryRequest) Reset() {
+	*x = GetRevenueSummaryRequest{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRevenueSummaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRevenueSummaryRequest) ProtoMessage() {}
+
+func (x *GetRevenueSummaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[15]
 	if x != nil {
-		return x.Status
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Order) GetItems() []*OrderItem {
+// Deprecated: Use GetRevenueSummaryRequest.ProtoReflect.Descriptor instead.
+func (*GetRevenueSummaryRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetRevenueSummaryRequest) GetStartDate() string {
 	if x != nil {
-		return x.Items
+		return x.StartDate
 	}
-	return nil
+	return ""
 }
 
-func (x *Order) GetCreatedAt() string {
+func (x *GetRevenueSummaryRequest) GetEndDate() string {
 	if x != nil {
-		return x.CreatedAt
+		return x.EndDate
 	}
 	return ""
 }
 
-func (x *Order) GetUpdatedAt() string {
+func (x *GetRevenueSummaryRequest) GetGranularity() string {
 	if x != nil {
-		return x.UpdatedAt
+		return x.Granularity
 	}
 	return ""
 }
 
-type OrderItem struct {
+type GetRevenueSummaryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	OrderId       int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	ProductId     int64                  `protobuf:"varint,3,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
-	Quantity      int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
-	UnitPrice     float32                `protobuf:"fixed32,5,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
-	TotalPrice    float32                `protobuf:"fixed32,6,opt,name=total_price,json=totalPrice,proto3" json:"total_price,omitempty"`
+	Periods       []*RevenuePeriod       `protobuf:"bytes,1,rep,name=periods,proto3" json:"periods,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *OrderItem) Reset() {
-	*x = OrderItem{}
-	mi := &file_shared_proto_v1_order_proto_msgTypes[14]
+func (x *GetRevenueSummaryResponse) Reset() {
+	*x = GetRevenueSummaryResponse{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *OrderItem) String() string {
+func (x *GetRevenueSummaryResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*OrderItem) ProtoMessage() {}
+func (*GetRevenueSummaryResponse) ProtoMessage() {}
 
-func (x *OrderItem) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_order_proto_msgTypes[14]
+func (x *GetRevenueSummaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -842,54 +946,1279 @@ func (x *OrderItem) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use OrderItem.ProtoReflect.Descriptor instead.
-func (*OrderItem) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use GetRevenueSummaryResponse.ProtoReflect.Descriptor instead.
+func (*GetRevenueSummaryResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *OrderItem) GetId() int64 {
+func (x *GetRevenueSummaryResponse) GetPeriods() []*RevenuePeriod {
 	if x != nil {
-		return x.Id
+		return x.Periods
 	}
-	return 0
+	return nil
 }
 
-func (x *OrderItem) GetOrderId() int64 {
+type RevenuePeriod struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         string                 `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           string                 `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	Revenue       float64                `protobuf:"fixed64,3,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	OrderCount    int64                  `protobuf:"varint,4,opt,name=order_count,json=orderCount,proto3" json:"order_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevenuePeriod) Reset() {
+	*x = RevenuePeriod{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevenuePeriod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevenuePeriod) ProtoMessage() {}
+
+func (x *RevenuePeriod) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[17]
 	if x != nil {
-		return x.OrderId
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *OrderItem) GetProductId() int64 {
+// Deprecated: Use RevenuePeriod.ProtoReflect.Descriptor instead.
+func (*RevenuePeriod) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RevenuePeriod) GetStart() string {
 	if x != nil {
-		return x.ProductId
+		return x.Start
 	}
-	return 0
+	return ""
 }
 
-func (x *OrderItem) GetQuantity() int32 {
+func (x *RevenuePeriod) GetEnd() string {
 	if x != nil {
-		return x.Quantity
+		return x.End
 	}
-	return 0
+	return ""
 }
 
-func (x *OrderItem) GetUnitPrice() float32 {
+func (x *RevenuePeriod) GetRevenue() float64 {
 	if x != nil {
-		return x.UnitPrice
+		return x.Revenue
 	}
 	return 0
 }
 
-func (x *OrderItem) GetTotalPrice() float32 {
+func (x *RevenuePeriod) GetOrderCount() int64 {
 	if x != nil {
-		return x.TotalPrice
+		return x.OrderCount
 	}
 	return 0
 }
 
-var File_shared_proto_v1_order_proto protoreflect.FileDescriptor
+// from and to are YYYY-MM-DD, both inclusive, at most a year apart
+type GetSalesReportRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	From  string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To    string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// group_by is day, week or month
+	GroupBy       string `protobuf:"bytes,3,opt,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSalesReportRequest) Reset() {
+	*x = GetSalesReportRequest{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSalesReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSalesReportRequest) ProtoMessage() {}
+
+func (x *GetSalesReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSalesReportRequest.ProtoReflect.Descriptor instead.
+func (*GetSalesReportRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetSalesReportRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetSalesReportRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *GetSalesReportRequest) GetGroupBy() string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return ""
+}
+
+type GetSalesReportResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// periods covers the range without gaps, oldest first
+	Periods       []*SalesPeriod `protobuf:"bytes,1,rep,name=periods,proto3" json:"periods,omitempty"`
+	Total         *SalesPeriod   `protobuf:"bytes,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSalesReportResponse) Reset() {
+	*x = GetSalesReportResponse{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSalesReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSalesReportResponse) ProtoMessage() {}
+
+func (x *GetSalesReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSalesReportResponse.ProtoReflect.Descriptor instead.
+func (*GetSalesReportResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetSalesReportResponse) GetPeriods() []*SalesPeriod {
+	if x != nil {
+		return x.Periods
+	}
+	return nil
+}
+
+func (x *GetSalesReportResponse) GetTotal() *SalesPeriod {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+type SalesPeriod struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Start string                 `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   string                 `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	// revenue and order_count cover paid and delivered orders
+	Revenue           float64 `protobuf:"fixed64,3,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	OrderCount        int64   `protobuf:"varint,4,opt,name=order_count,json=orderCount,proto3" json:"order_count,omitempty"`
+	AverageOrderValue float64 `protobuf:"fixed64,5,opt,name=average_order_value,json=averageOrderValue,proto3" json:"average_order_value,omitempty"`
+	CanceledCount     int64   `protobuf:"varint,6,opt,name=canceled_count,json=canceledCount,proto3" json:"canceled_count,omitempty"`
+	CanceledValue     float64 `protobuf:"fixed64,7,opt,name=canceled_value,json=canceledValue,proto3" json:"canceled_value,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SalesPeriod) Reset() {
+	*x = SalesPeriod{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SalesPeriod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SalesPeriod) ProtoMessage() {}
+
+func (x *SalesPeriod) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SalesPeriod.ProtoReflect.Descriptor instead.
+func (*SalesPeriod) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SalesPeriod) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *SalesPeriod) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+func (x *SalesPeriod) GetRevenue() float64 {
+	if x != nil {
+		return x.Revenue
+	}
+	return 0
+}
+
+func (x *SalesPeriod) GetOrderCount() int64 {
+	if x != nil {
+		return x.OrderCount
+	}
+	return 0
+}
+
+func (x *SalesPeriod) GetAverageOrderValue() float64 {
+	if x != nil {
+		return x.AverageOrderValue
+	}
+	return 0
+}
+
+func (x *SalesPeriod) GetCanceledCount() int64 {
+	if x != nil {
+		return x.CanceledCount
+	}
+	return 0
+}
+
+func (x *SalesPeriod) GetCanceledValue() float64 {
+	if x != nil {
+		return x.CanceledValue
+	}
+	return 0
+}
+
+type GetTopProductsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	From  string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To    string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// limit defaults to 10, at most 100
+	Limit         int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTopProductsRequest) Reset() {
+	*x = GetTopProductsRequest{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTopProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopProductsRequest) ProtoMessage() {}
+
+func (x *GetTopProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopProductsRequest.ProtoReflect.Descriptor instead.
+func (*GetTopProductsRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetTopProductsRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetTopProductsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *GetTopProductsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetTopProductsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// products is ordered by revenue, highest first
+	Products      []*ProductSales `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTopProductsResponse) Reset() {
+	*x = GetTopProductsResponse{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTopProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopProductsResponse) ProtoMessage() {}
+
+func (x *GetTopProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopProductsResponse.ProtoReflect.Descriptor instead.
+func (*GetTopProductsResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetTopProductsResponse) GetProducts() []*ProductSales {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+type ProductSales struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// name is empty when the product service could not be reached or the product was deleted
+	Name          string  `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Quantity      int64   `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Revenue       float64 `protobuf:"fixed64,4,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	OrderCount    int64   `protobuf:"varint,5,opt,name=order_count,json=orderCount,proto3" json:"order_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductSales) Reset() {
+	*x = ProductSales{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductSales) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductSales) ProtoMessage() {}
+
+func (x *ProductSales) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductSales.ProtoReflect.Descriptor instead.
+func (*ProductSales) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ProductSales) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ProductSales) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProductSales) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *ProductSales) GetRevenue() float64 {
+	if x != nil {
+		return x.Revenue
+	}
+	return 0
+}
+
+func (x *ProductSales) GetOrderCount() int64 {
+	if x != nil {
+		return x.OrderCount
+	}
+	return 0
+}
+
+type AnonymiseUserOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnonymiseUserOrdersRequest) Reset() {
+	*x = AnonymiseUserOrdersRequest{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnonymiseUserOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnonymiseUserOrdersRequest) ProtoMessage() {}
+
+func (x *AnonymiseUserOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnonymiseUserOrdersRequest.ProtoReflect.Descriptor instead.
+func (*AnonymiseUserOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AnonymiseUserOrdersRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type AnonymiseUserOrdersResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AnonymisedCount int64                  `protobuf:"varint,1,opt,name=anonymised_count,json=anonymisedCount,proto3" json:"anonymised_count,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AnonymiseUserOrdersResponse) Reset() {
+	*x = AnonymiseUserOrdersResponse{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnonymiseUserOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnonymiseUserOrdersResponse) ProtoMessage() {}
+
+func (x *AnonymiseUserOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnonymiseUserOrdersResponse.ProtoReflect.Descriptor instead.
+func (*AnonymiseUserOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *AnonymiseUserOrdersResponse) GetAnonymisedCount() int64 {
+	if x != nil {
+		return x.AnonymisedCount
+	}
+	return 0
+}
+
+type WatchOrderStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchOrderStatusRequest) Reset() {
+	*x = WatchOrderStatusRequest{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchOrderStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchOrderStatusRequest) ProtoMessage() {}
+
+func (x *WatchOrderStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchOrderStatusRequest.ProtoReflect.Descriptor instead.
+func (*WatchOrderStatusRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *WatchOrderStatusRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type OrderStatusEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderStatusEvent) Reset() {
+	*x = OrderStatusEvent{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderStatusEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderStatusEvent) ProtoMessage() {}
+
+func (x *OrderStatusEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderStatusEvent.ProtoReflect.Descriptor instead.
+func (*OrderStatusEvent) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *OrderStatusEvent) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderStatusEvent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *OrderStatusEvent) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type HasPurchasedProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ProductId     int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HasPurchasedProductRequest) Reset() {
+	*x = HasPurchasedProductRequest{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HasPurchasedProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HasPurchasedProductRequest) ProtoMessage() {}
+
+func (x *HasPurchasedProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HasPurchasedProductRequest.ProtoReflect.Descriptor instead.
+func (*HasPurchasedProductRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *HasPurchasedProductRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *HasPurchasedProductRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+type HasPurchasedProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Purchased     bool                   `protobuf:"varint,1,opt,name=purchased,proto3" json:"purchased,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HasPurchasedProductResponse) Reset() {
+	*x = HasPurchasedProductResponse{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HasPurchasedProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HasPurchasedProductResponse) ProtoMessage() {}
+
+func (x *HasPurchasedProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HasPurchasedProductResponse.ProtoReflect.Descriptor instead.
+func (*HasPurchasedProductResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *HasPurchasedProductResponse) GetPurchased() bool {
+	if x != nil {
+		return x.Purchased
+	}
+	return false
+}
+
+type GetOrderInvoiceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderInvoiceRequest) Reset() {
+	*x = GetOrderInvoiceRequest{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderInvoiceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderInvoiceRequest) ProtoMessage() {}
+
+func (x *GetOrderInvoiceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderInvoiceRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderInvoiceRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetOrderInvoiceRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type GetOrderInvoiceResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// e.g. INV-000042; numbers run without gaps in the order invoices were issued
+	InvoiceNumber string `protobuf:"bytes,1,opt,name=invoice_number,json=invoiceNumber,proto3" json:"invoice_number,omitempty"`
+	OrderId       int64  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	IssuedAt      string `protobuf:"bytes,3,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	// The same bytes on every request
+	Pdf           []byte `protobuf:"bytes,4,opt,name=pdf,proto3" json:"pdf,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderInvoiceResponse) Reset() {
+	*x = GetOrderInvoiceResponse{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderInvoiceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderInvoiceResponse) ProtoMessage() {}
+
+func (x *GetOrderInvoiceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderInvoiceResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderInvoiceResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetOrderInvoiceResponse) GetInvoiceNumber() string {
+	if x != nil {
+		return x.InvoiceNumber
+	}
+	return ""
+}
+
+func (x *GetOrderInvoiceResponse) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *GetOrderInvoiceResponse) GetIssuedAt() string {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return ""
+}
+
+func (x *GetOrderInvoiceResponse) GetPdf() []byte {
+	if x != nil {
+		return x.Pdf
+	}
+	return nil
+}
+
+type ExportOrdersRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Status string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Inclusive YYYY-MM-DD bounds on created_at
+	StartDate     string `protobuf:"bytes,2,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate       string `protobuf:"bytes,3,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportOrdersRequest) Reset() {
+	*x = ExportOrdersRequest{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportOrdersRequest) ProtoMessage() {}
+
+func (x *ExportOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ExportOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ExportOrdersRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ExportOrdersRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *ExportOrdersRequest) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+// ExportOrdersResponse is one batch of orders, without their items
+type ExportOrdersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*Order               `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportOrdersResponse) Reset() {
+	*x = ExportOrdersResponse{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportOrdersResponse) ProtoMessage() {}
+
+func (x *ExportOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportOrdersResponse.ProtoReflect.Descriptor instead.
+func (*ExportOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ExportOrdersResponse) GetOrders() []*Order {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+type Order struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId               int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ShippingCost         float32                `protobuf:"fixed32,3,opt,name=shipping_cost,json=shippingCost,proto3" json:"shipping_cost,omitempty"`
+	ShippingDurationDays int32                  `protobuf:"varint,4,opt,name=shipping_duration_days,json=shippingDurationDays,proto3" json:"shipping_duration_days,omitempty"`
+	Discount             float32                `protobuf:"fixed32,5,opt,name=discount,proto3" json:"discount,omitempty"`
+	Total                float32                `protobuf:"fixed32,6,opt,name=total,proto3" json:"total,omitempty"`
+	Status               string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	Items                []*OrderItem           `protobuf:"bytes,8,rep,name=items,proto3" json:"items,omitempty"`
+	CreatedAt            string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt            string                 `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ShippingAddress      *ShippingAddress       `protobuf:"bytes,11,opt,name=shipping_address,json=shippingAddress,proto3" json:"shipping_address,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *Order) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Order) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Order) GetShippingCost() float32 {
+	if x != nil {
+		return x.ShippingCost
+	}
+	return 0
+}
+
+func (x *Order) GetShippingDurationDays() int32 {
+	if x != nil {
+		return x.ShippingDurationDays
+	}
+	return 0
+}
+
+func (x *Order) GetDiscount() float32 {
+	if x != nil {
+		return x.Discount
+	}
+	return 0
+}
+
+func (x *Order) GetTotal() float32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Order) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Order) GetItems() []*OrderItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *Order) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Order) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *Order) GetShippingAddress() *ShippingAddress {
+	if x != nil {
+		return x.ShippingAddress
+	}
+	return nil
+}
+
+// ShippingAddress is a snapshot of the user's address taken when the order was placed
+type ShippingAddress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AddressId     int64                  `protobuf:"varint,1,opt,name=address_id,json=addressId,proto3" json:"address_id,omitempty"`
+	Street        string                 `protobuf:"bytes,2,opt,name=street,proto3" json:"street,omitempty"`
+	City          string                 `protobuf:"bytes,3,opt,name=city,proto3" json:"city,omitempty"`
+	State         string                 `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
+	Country       string                 `protobuf:"bytes,5,opt,name=country,proto3" json:"country,omitempty"`
+	PostalCode    string                 `protobuf:"bytes,6,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShippingAddress) Reset() {
+	*x = ShippingAddress{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShippingAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShippingAddress) ProtoMessage() {}
+
+func (x *ShippingAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShippingAddress.ProtoReflect.Descriptor instead.
+func (*ShippingAddress) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ShippingAddress) GetAddressId() int64 {
+	if x != nil {
+		return x.AddressId
+	}
+	return 0
+}
+
+func (x *ShippingAddress) GetStreet() string {
+	if x != nil {
+		return x.Street
+	}
+	return ""
+}
+
+func (x *ShippingAddress) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *ShippingAddress) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ShippingAddress) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *ShippingAddress) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+type OrderItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrderId       int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	ProductId     int64                  `protobuf:"varint,3,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	UnitPrice     float32                `protobuf:"fixed32,5,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	TotalPrice    float32                `protobuf:"fixed32,6,opt,name=total_price,json=totalPrice,proto3" json:"total_price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderItem) Reset() {
+	*x = OrderItem{}
+	mi := &file_shared_proto_v1_order_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderItem) ProtoMessage() {}
+
+func (x *OrderItem) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_order_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderItem.ProtoReflect.Descriptor instead.
+func (*OrderItem) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_order_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *OrderItem) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *OrderItem) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderItem) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *OrderItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *OrderItem) GetUnitPrice() float32 {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return 0
+}
+
+func (x *OrderItem) GetTotalPrice() float32 {
+	if x != nil {
+		return x.TotalPrice
+	}
+	return 0
+}
+
+var File_shared_proto_v1_order_proto protoreflect.FileDescriptor
 
 const file_shared_proto_v1_order_proto_rawDesc = "" +
 	"\n" +
@@ -897,23 +2226,33 @@ const file_shared_proto_v1_order_proto_rawDesc = "" +
 	"\x0eOrderItemInput\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\xd1\x01\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\x9e\x02\n" +
 	"\x12CreateOrderRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12#\n" +
 	"\rshipping_cost\x18\x02 \x01(\x02R\fshippingCost\x124\n" +
 	"\x16shipping_duration_days\x18\x03 \x01(\x05R\x14shippingDurationDays\x12\x1a\n" +
 	"\bdiscount\x18\x04 \x01(\x02R\bdiscount\x12+\n" +
-	"\x05items\x18\x05 \x03(\v2\x15.order.OrderItemInputR\x05items\"9\n" +
+	"\x05items\x18\x05 \x03(\v2\x15.order.OrderItemInputR\x05items\x12\x1d\n" +
+	"\n" +
+	"address_id\x18\x06 \x01(\x03R\taddressId\x12,\n" +
+	"\x12shipping_option_id\x18\a \x01(\tR\x10shippingOptionId\"9\n" +
 	"\x13CreateOrderResponse\x12\"\n" +
 	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"%\n" +
 	"\x13GetOrderByIDRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\":\n" +
 	"\x14GetOrderByIDResponse\x12\"\n" +
-	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"[\n" +
+	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"\xe5\x01\n" +
 	"\x11ListOrdersRequest\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x19\n" +
 	"\bper_page\x18\x02 \x01(\x05R\aperPage\x12\x17\n" +
-	"\auser_id\x18\x03 \x01(\x03R\x06userId\"[\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x05 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x06 \x01(\tR\aendDate\x12\x17\n" +
+	"\asort_by\x18\a \x01(\tR\x06sortBy\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\b \x01(\tR\tsortOrder\"[\n" +
 	"\x12ListOrdersResponse\x12$\n" +
 	"\x06orders\x18\x01 \x03(\v2\f.order.OrderR\x06orders\x12\x1f\n" +
 	"\vtotal_count\x18\x02 \x01(\x05R\n" +
@@ -929,12 +2268,92 @@ const file_shared_proto_v1_order_proto_rawDesc = "" +
 	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x17\n" +
 	"\aitem_id\x18\x02 \x01(\x03R\x06itemId\"=\n" +
 	"\x17RemoveOrderItemResponse\x12\"\n" +
+	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"p\n" +
+	"\x1eUpdateOrderItemQuantityRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x17\n" +
+	"\aitem_id\x18\x02 \x01(\x03R\x06itemId\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\"E\n" +
+	"\x1fUpdateOrderItemQuantityResponse\x12\"\n" +
 	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"M\n" +
 	"\x18UpdateOrderStatusRequest\x12\x19\n" +
 	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\"?\n" +
 	"\x19UpdateOrderStatusResponse\x12\"\n" +
-	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"\xbb\x02\n" +
+	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"v\n" +
+	"\x18GetRevenueSummaryRequest\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x01 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x02 \x01(\tR\aendDate\x12 \n" +
+	"\vgranularity\x18\x03 \x01(\tR\vgranularity\"K\n" +
+	"\x19GetRevenueSummaryResponse\x12.\n" +
+	"\aperiods\x18\x01 \x03(\v2\x14.order.RevenuePeriodR\aperiods\"r\n" +
+	"\rRevenuePeriod\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\tR\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\tR\x03end\x12\x18\n" +
+	"\arevenue\x18\x03 \x01(\x01R\arevenue\x12\x1f\n" +
+	"\vorder_count\x18\x04 \x01(\x03R\n" +
+	"orderCount\"V\n" +
+	"\x15GetSalesReportRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x19\n" +
+	"\bgroup_by\x18\x03 \x01(\tR\agroupBy\"p\n" +
+	"\x16GetSalesReportResponse\x12,\n" +
+	"\aperiods\x18\x01 \x03(\v2\x12.order.SalesPeriodR\aperiods\x12(\n" +
+	"\x05total\x18\x02 \x01(\v2\x12.order.SalesPeriodR\x05total\"\xee\x01\n" +
+	"\vSalesPeriod\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\tR\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\tR\x03end\x12\x18\n" +
+	"\arevenue\x18\x03 \x01(\x01R\arevenue\x12\x1f\n" +
+	"\vorder_count\x18\x04 \x01(\x03R\n" +
+	"orderCount\x12.\n" +
+	"\x13average_order_value\x18\x05 \x01(\x01R\x11averageOrderValue\x12%\n" +
+	"\x0ecanceled_count\x18\x06 \x01(\x03R\rcanceledCount\x12%\n" +
+	"\x0ecanceled_value\x18\a \x01(\x01R\rcanceledValue\"Q\n" +
+	"\x15GetTopProductsRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"I\n" +
+	"\x16GetTopProductsResponse\x12/\n" +
+	"\bproducts\x18\x01 \x03(\v2\x13.order.ProductSalesR\bproducts\"\x98\x01\n" +
+	"\fProductSales\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x03R\bquantity\x12\x18\n" +
+	"\arevenue\x18\x04 \x01(\x01R\arevenue\x12\x1f\n" +
+	"\vorder_count\x18\x05 \x01(\x03R\n" +
+	"orderCount\"5\n" +
+	"\x1aAnonymiseUserOrdersRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"H\n" +
+	"\x1bAnonymiseUserOrdersResponse\x12)\n" +
+	"\x10anonymised_count\x18\x01 \x01(\x03R\x0fanonymisedCount\"4\n" +
+	"\x17WatchOrderStatusRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\"d\n" +
+	"\x10OrderStatusEvent\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\tR\tupdatedAt\"T\n" +
+	"\x1aHasPurchasedProductRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\";\n" +
+	"\x1bHasPurchasedProductResponse\x12\x1c\n" +
+	"\tpurchased\x18\x01 \x01(\bR\tpurchased\"3\n" +
+	"\x16GetOrderInvoiceRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\"\x8a\x01\n" +
+	"\x17GetOrderInvoiceResponse\x12%\n" +
+	"\x0einvoice_number\x18\x01 \x01(\tR\rinvoiceNumber\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\x12\x1b\n" +
+	"\tissued_at\x18\x03 \x01(\tR\bissuedAt\x12\x10\n" +
+	"\x03pdf\x18\x04 \x01(\fR\x03pdf\"g\n" +
+	"\x13ExportOrdersRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x02 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x03 \x01(\tR\aendDate\"<\n" +
+	"\x14ExportOrdersResponse\x12$\n" +
+	"\x06orders\x18\x01 \x03(\v2\f.order.OrderR\x06orders\"\xfe\x02\n" +
 	"\x05Order\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12#\n" +
@@ -948,7 +2367,17 @@ const file_shared_proto_v1_order_proto_rawDesc = "" +
 	"created_at\x18\t \x01(\tR\tcreatedAt\x12\x1d\n" +
 	"\n" +
 	"updated_at\x18\n" +
-	" \x01(\tR\tupdatedAt\"\xb1\x01\n" +
+	" \x01(\tR\tupdatedAt\x12A\n" +
+	"\x10shipping_address\x18\v \x01(\v2\x16.order.ShippingAddressR\x0fshippingAddress\"\xad\x01\n" +
+	"\x0fShippingAddress\x12\x1d\n" +
+	"\n" +
+	"address_id\x18\x01 \x01(\x03R\taddressId\x12\x16\n" +
+	"\x06street\x18\x02 \x01(\tR\x06street\x12\x12\n" +
+	"\x04city\x18\x03 \x01(\tR\x04city\x12\x14\n" +
+	"\x05state\x18\x04 \x01(\tR\x05state\x12\x18\n" +
+	"\acountry\x18\x05 \x01(\tR\acountry\x12\x1f\n" +
+	"\vpostal_code\x18\x06 \x01(\tR\n" +
+	"postalCode\"\xb1\x01\n" +
 	"\tOrderItem\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
 	"\border_id\x18\x02 \x01(\x03R\aorderId\x12\x1d\n" +
@@ -958,15 +2387,24 @@ const file_shared_proto_v1_order_proto_rawDesc = "" +
 	"\n" +
 	"unit_price\x18\x05 \x01(\x02R\tunitPrice\x12\x1f\n" +
 	"\vtotal_price\x18\x06 \x01(\x02R\n" +
-	"totalPrice2\xd3\x03\n" +
+	"totalPrice2\xdb\t\n" +
 	"\fOrderService\x12D\n" +
 	"\vCreateOrder\x12\x19.order.CreateOrderRequest\x1a\x1a.order.CreateOrderResponse\x12G\n" +
 	"\fGetOrderByID\x12\x1a.order.GetOrderByIDRequest\x1a\x1b.order.GetOrderByIDResponse\x12A\n" +
 	"\n" +
 	"ListOrders\x12\x18.order.ListOrdersRequest\x1a\x19.order.ListOrdersResponse\x12G\n" +
 	"\fAddOrderItem\x12\x1a.order.AddOrderItemRequest\x1a\x1b.order.AddOrderItemResponse\x12P\n" +
-	"\x0fRemoveOrderItem\x12\x1d.order.RemoveOrderItemRequest\x1a\x1e.order.RemoveOrderItemResponse\x12V\n" +
-	"\x11UpdateOrderStatus\x12\x1f.order.UpdateOrderStatusRequest\x1a .order.UpdateOrderStatusResponseB\x1dZ\x1bshared/proto/v1/order;orderb\x06proto3"
+	"\x0fRemoveOrderItem\x12\x1d.order.RemoveOrderItemRequest\x1a\x1e.order.RemoveOrderItemResponse\x12h\n" +
+	"\x17UpdateOrderItemQuantity\x12%.order.UpdateOrderItemQuantityRequest\x1a&.order.UpdateOrderItemQuantityResponse\x12V\n" +
+	"\x11UpdateOrderStatus\x12\x1f.order.UpdateOrderStatusRequest\x1a .order.UpdateOrderStatusResponse\x12V\n" +
+	"\x11GetRevenueSummary\x12\x1f.order.GetRevenueSummaryRequest\x1a .order.GetRevenueSummaryResponse\x12M\n" +
+	"\x0eGetSalesReport\x12\x1c.order.GetSalesReportRequest\x1a\x1d.order.GetSalesReportResponse\x12M\n" +
+	"\x0eGetTopProducts\x12\x1c.order.GetTopProductsRequest\x1a\x1d.order.GetTopProductsResponse\x12\\\n" +
+	"\x13AnonymiseUserOrders\x12!.order.AnonymiseUserOrdersRequest\x1a\".order.AnonymiseUserOrdersResponse\x12M\n" +
+	"\x10WatchOrderStatus\x12\x1e.order.WatchOrderStatusRequest\x1a\x17.order.OrderStatusEvent0\x01\x12\\\n" +
+	"\x13HasPurchasedProduct\x12!.order.HasPurchasedProductRequest\x1a\".order.HasPurchasedProductResponse\x12P\n" +
+	"\x0fGetOrderInvoice\x12\x1d.order.GetOrderInvoiceRequest\x1a\x1e.order.GetOrderInvoiceResponse\x12I\n" +
+	"\fExportOrders\x12\x1a.order.ExportOrdersRequest\x1a\x1b.order.ExportOrdersResponse0\x01B\x1dZ\x1bshared/proto/v1/order;orderb\x06proto3"
 
 var (
 	file_shared_proto_v1_order_proto_rawDescOnce sync.Once
@@ -980,50 +2418,97 @@ func file_shared_proto_v1_order_proto_rawDescGZIP() []byte {
 	return file_shared_proto_v1_order_proto_rawDescData
 }
 
-var file_shared_proto_v1_order_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_shared_proto_v1_order_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
 var file_shared_proto_v1_order_proto_goTypes = []any{
-	(*OrderItemInput)(nil),            // 0: order.OrderItemInput
-	(*CreateOrderRequest)(nil),        // 1: order.CreateOrderRequest
-	(*CreateOrderResponse)(nil),       // 2: order.CreateOrderResponse
-	(*GetOrderByIDRequest)(nil),       // 3: order.GetOrderByIDRequest
-	(*GetOrderByIDResponse)(nil),      // 4: order.GetOrderByIDResponse
-	(*ListOrdersRequest)(nil),         // 5: order.ListOrdersRequest
-	(*ListOrdersResponse)(nil),        // 6: order.ListOrdersResponse
-	(*AddOrderItemRequest)(nil),       // 7: order.AddOrderItemRequest
-	(*AddOrderItemResponse)(nil),      // 8: order.AddOrderItemResponse
-	(*RemoveOrderItemRequest)(nil),    // 9: order.RemoveOrderItemRequest
-	(*RemoveOrderItemResponse)(nil),   // 10: order.RemoveOrderItemResponse
-	(*UpdateOrderStatusRequest)(nil),  // 11: order.UpdateOrderStatusRequest
-	(*UpdateOrderStatusResponse)(nil), // 12: order.UpdateOrderStatusResponse
-	(*Order)(nil),                     // 13: order.Order
-	(*OrderItem)(nil),                 // 14: order.OrderItem
+	(*OrderItemInput)(nil),                  // 0: order.OrderItemInput
+	(*CreateOrderRequest)(nil),              // 1: order.CreateOrderRequest
+	(*CreateOrderResponse)(nil),             // 2: order.CreateOrderResponse
+	(*GetOrderByIDRequest)(nil),             // 3: order.GetOrderByIDRequest
+	(*GetOrderByIDResponse)(nil),            // 4: order.GetOrderByIDResponse
+	(*ListOrdersRequest)(nil),               // 5: order.ListOrdersRequest
+	(*ListOrdersResponse)(nil),              // 6: order.ListOrdersResponse
+	(*AddOrderItemRequest)(nil),             // 7: order.AddOrderItemRequest
+	(*AddOrderItemResponse)(nil),            // 8: order.AddOrderItemResponse
+	(*RemoveOrderItemRequest)(nil),          // 9: order.RemoveOrderItemRequest
+	(*RemoveOrderItemResponse)(nil),         // 10: order.RemoveOrderItemResponse
+	(*UpdateOrderItemQuantityRequest)(nil),  // 11: order.UpdateOrderItemQuantityRequest
+	(*UpdateOrderItemQuantityResponse)(nil), // 12: order.UpdateOrderItemQuantityResponse
+	(*UpdateOrderStatusRequest)(nil),        // 13: order.UpdateOrderStatusRequest
+	(*UpdateOrderStatusResponse)(nil),       // 14: order.UpdateOrderStatusResponse
+	(*GetRevenueSummaryRequest)(nil),        // 15: order.GetRevenueSummaryRequest
+	(*GetRevenueSummaryResponse)(nil),       // 16: order.GetRevenueSummaryResponse
+	(*RevenuePeriod)(nil),                   // 17: order.RevenuePeriod
+	(*GetSalesReportRequest)(nil),           // 18: order.GetSalesReportRequest
+	(*GetSalesReportResponse)(nil),          // 19: order.GetSalesReportResponse
+	(*SalesPeriod)(nil),                     // 20: order.SalesPeriod
+	(*GetTopProductsRequest)(nil),           // 21: order.GetTopProductsRequest
+	(*GetTopProductsResponse)(nil),          // 22: order.GetTopProductsResponse
+	(*ProductSales)(nil),                    // 23: order.ProductSales
+	(*AnonymiseUserOrdersRequest)(nil),      // 24: order.AnonymiseUserOrdersRequest
+	(*AnonymiseUserOrdersResponse)(nil),     // 25: order.AnonymiseUserOrdersResponse
+	(*WatchOrderStatusRequest)(nil),         // 26: order.WatchOrderStatusRequest
+	(*OrderStatusEvent)(nil),                // 27: order.OrderStatusEvent
+	(*HasPurchasedProductRequest)(nil),      // 28: order.HasPurchasedProductRequest
+	(*HasPurchasedProductResponse)(nil),     // 29: order.HasPurchasedProductResponse
+	(*GetOrderInvoiceRequest)(nil),          // 30: order.GetOrderInvoiceRequest
+	(*GetOrderInvoiceResponse)(nil),         // 31: order.GetOrderInvoiceResponse
+	(*ExportOrdersRequest)(nil),             // 32: order.ExportOrdersRequest
+	(*ExportOrdersResponse)(nil),            // 33: order.ExportOrdersResponse
+	(*Order)(nil),                           // 34: order.Order
+	(*ShippingAddress)(nil),                 // 35: order.ShippingAddress
+	(*OrderItem)(nil),                       // 36: order.OrderItem
 }
 var file_shared_proto_v1_order_proto_depIdxs = []int32{
 	0,  // 0: order.CreateOrderRequest.items:type_name -> order.OrderItemInput
-	13, // 1: order.CreateOrderResponse.order:type_name -> order.Order
-	13, // 2: order.GetOrderByIDResponse.order:type_name -> order.Order
-	13, // 3: order.ListOrdersResponse.orders:type_name -> order.Order
-	13, // 4: order.AddOrderItemResponse.order:type_name -> order.Order
-	13, // 5: order.RemoveOrderItemResponse.order:type_name -> order.Order
-	13, // 6: order.UpdateOrderStatusResponse.order:type_name -> order.Order
-	14, // 7: order.Order.items:type_name -> order.OrderItem
-	1,  // 8: order.OrderService.CreateOrder:input_type -> order.CreateOrderRequest
-	3,  // 9: order.OrderService.GetOrderByID:input_type -> order.GetOrderByIDRequest
-	5,  // 10: order.OrderService.ListOrders:input_type -> order.ListOrdersRequest
-	7,  // 11: order.OrderService.AddOrderItem:input_type -> order.AddOrderItemRequest
-	9,  // 12: order.OrderService.RemoveOrderItem:input_type -> order.RemoveOrderItemRequest
-	11, // 13: order.OrderService.UpdateOrderStatus:input_type -> order.UpdateOrderStatusRequest
-	2,  // 14: order.OrderService.CreateOrder:output_type -> order.CreateOrderResponse
-	4,  // 15: order.OrderService.GetOrderByID:output_type -> order.GetOrderByIDResponse
-	6,  // 16: order.OrderService.ListOrders:output_type -> order.ListOrdersResponse
-	8,  // 17: order.OrderService.AddOrderItem:output_type -> order.AddOrderItemResponse
-	10, // 18: order.OrderService.RemoveOrderItem:output_type -> order.RemoveOrderItemResponse
-	12, // 19: order.OrderService.UpdateOrderStatus:output_type -> order.UpdateOrderStatusResponse
-	14, // [14:20] is the sub-list for method output_type
-	8,  // [8:14] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	34, // 1: order.CreateOrderResponse.order:type_name -> order.Order
+	34, // 2: order.GetOrderByIDResponse.order:type_name -> order.Order
+	34, // 3: order.ListOrdersResponse.orders:type_name -> order.Order
+	34, // 4: order.AddOrderItemResponse.order:type_name -> order.Order
+	34, // 5: order.RemoveOrderItemResponse.order:type_name -> order.Order
+	34, // 6: order.UpdateOrderItemQuantityResponse.order:type_name -> order.Order
+	34, // 7: order.UpdateOrderStatusResponse.order:type_name -> order.Order
+	17, // 8: order.GetRevenueSummaryResponse.periods:type_name -> order.RevenuePeriod
+	20, // 9: order.GetSalesReportResponse.periods:type_name -> order.SalesPeriod
+	20, // 10: order.GetSalesReportResponse.total:type_name -> order.SalesPeriod
+	23, // 11: order.GetTopProductsResponse.products:type_name -> order.ProductSales
+	34, // 12: order.ExportOrdersResponse.orders:type_name -> order.Order
+	36, // 13: order.Order.items:type_name -> order.OrderItem
+	35, // 14: order.Order.shipping_address:type_name -> order.ShippingAddress
+	1,  // 15: order.OrderService.CreateOrder:input_type -> order.CreateOrderRequest
+	3,  // 16: order.OrderService.GetOrderByID:input_type -> order.GetOrderByIDRequest
+	5,  // 17: order.OrderService.ListOrders:input_type -> order.ListOrdersRequest
+	7,  // 18: order.OrderService.AddOrderItem:input_type -> order.AddOrderItemRequest
+	9,  // 19: order.OrderService.RemoveOrderItem:input_type -> order.RemoveOrderItemRequest
+	11, // 20: order.OrderService.UpdateOrderItemQuantity:input_type -> order.UpdateOrderItemQuantityRequest
+	13, // 21: order.OrderService.UpdateOrderStatus:input_type -> order.UpdateOrderStatusRequest
+	15, // 22: order.OrderService.GetRevenueSummary:input_type -> order.GetRevenueSummaryRequest
+	18, // 23: order.OrderService.GetSalesReport:input_type -> order.GetSalesReportRequest
+	21, // 24: order.OrderService.GetTopProducts:input_type -> order.GetTopProductsRequest
+	24, // 25: order.OrderService.AnonymiseUserOrders:input_type -> order.AnonymiseUserOrdersRequest
+	26, // 26: order.OrderService.WatchOrderStatus:input_type -> order.WatchOrderStatusRequest
+	28, // 27: order.OrderService.HasPurchasedProduct:input_type -> order.HasPurchasedProductRequest
+	30, // 28: order.OrderService.GetOrderInvoice:input_type -> order.GetOrderInvoiceRequest
+	32, // 29: order.OrderService.ExportOrders:input_type -> order.ExportOrdersRequest
+	2,  // 30: order.OrderService.CreateOrder:output_type -> order.CreateOrderResponse
+	4,  // 31: order.OrderService.GetOrderByID:output_type -> order.GetOrderByIDResponse
+	6,  // 32: order.OrderService.ListOrders:output_type -> order.ListOrdersResponse
+	8,  // 33: order.OrderService.AddOrderItem:output_type -> order.AddOrderItemResponse
+	10, // 34: order.OrderService.RemoveOrderItem:output_type -> order.RemoveOrderItemResponse
+	12, // 35: order.OrderService.UpdateOrderItemQuantity:output_type -> order.UpdateOrderItemQuantityResponse
+	14, // 36: order.OrderService.UpdateOrderStatus:output_type -> order.UpdateOrderStatusResponse
+	16, // 37: order.OrderService.GetRevenueSummary:output_type -> order.GetRevenueSummaryResponse
+	19, // 38: order.OrderService.GetSalesReport:output_type -> order.GetSalesReportResponse
+	22, // 39: order.OrderService.GetTopProducts:output_type -> order.GetTopProductsResponse
+	25, // 40: order.OrderService.AnonymiseUserOrders:output_type -> order.AnonymiseUserOrdersResponse
+	27, // 41: order.OrderService.WatchOrderStatus:output_type -> order.OrderStatusEvent
+	29, // 42: order.OrderService.HasPurchasedProduct:output_type -> order.HasPurchasedProductResponse
+	31, // 43: order.OrderService.GetOrderInvoice:output_type -> order.GetOrderInvoiceResponse
+	33, // 44: order.OrderService.ExportOrders:output_type -> order.ExportOrdersResponse
+	30, // [30:45] is the sub-list for method output_type
+	15, // [15:30] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_shared_proto_v1_order_proto_init() }
@@ -1037,7 +2522,7 @@ func file_shared_proto_v1_order_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_shared_proto_v1_order_proto_rawDesc), len(file_shared_proto_v1_order_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   15,
+			NumMessages:   37,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,12 +19,21 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	OrderService_CreateOrder_FullMethodName       = "/order.OrderService/CreateOrder"
-	OrderService_GetOrderByID_FullMethodName      = "/order.OrderService/GetOrderByID"
-	OrderService_ListOrders_FullMethodName        = "/order.OrderService/ListOrders"
-	OrderService_AddOrderItem_FullMethodName      = "/order.OrderService/AddOrderItem"
-	OrderService_RemoveOrderItem_FullMethodName   = "/order.OrderService/RemoveOrderItem"
-	OrderService_UpdateOrderStatus_FullMethodName = "/order.OrderService/UpdateOrderStatus"
+	OrderService_CreateOrder_FullMethodName             = "/order.OrderService/CreateOrder"
+	OrderService_GetOrderByID_FullMethodName            = "/order.OrderService/GetOrderByID"
+	OrderService_ListOrders_FullMethodName              = "/order.OrderService/ListOrders"
+	OrderService_AddOrderItem_FullMethodName            = "/order.OrderService/AddOrderItem"
+	OrderService_RemoveOrderItem_FullMethodName         = "/order.OrderService/RemoveOrderItem"
+	OrderService_UpdateOrderItemQuantity_FullMethodName = "/order.OrderService/UpdateOrderItemQuantity"
+	OrderService_UpdateOrderStatus_FullMethodName       = "/order.OrderService/UpdateOrderStatus"
+	OrderService_GetRevenueSummary_FullMethodName       = "/order.OrderService/GetRevenueSummary"
+	OrderService_GetSalesReport_FullMethodName          = "/order.OrderService/GetSalesReport"
+	OrderService_GetTopProducts_FullMethodName          = "/order.OrderService/GetTopProducts"
+	OrderService_AnonymiseUserOrders_FullMethodName     = "/order.OrderService/AnonymiseUserOrders"
+	OrderService_WatchOrderStatus_FullMethodName        = "/order.OrderService/WatchOrderStatus"
+	OrderService_HasPurchasedProduct_FullMethodName     = "/order.OrderService/HasPurchasedProduct"
+	OrderService_GetOrderInvoice_FullMethodName         = "/order.OrderService/GetOrderInvoice"
+	OrderService_ExportOrders_FullMethodName            = "/order.OrderService/ExportOrders"
 )
 
 // OrderServiceClient is the client API for OrderService service.
@@ -37,14 +46,32 @@ type OrderServiceClient interface {
 	CreateOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*CreateOrderResponse, error)
 	// Get order by id
 	GetOrderByID(ctx context.Context, in *GetOrderByIDRequest, opts ...grpc.CallOption) (*GetOrderByIDResponse, error)
-	// List orders with pagination and optional user filter
+	// List orders with pagination, optionally filtered by user, status and creation date
 	ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error)
 	// Add item to order
 	AddOrderItem(ctx context.Context, in *AddOrderItemRequest, opts ...grpc.CallOption) (*AddOrderItemResponse, error)
 	// Remove item from order
 	RemoveOrderItem(ctx context.Context, in *RemoveOrderItemRequest, opts ...grpc.CallOption) (*RemoveOrderItemResponse, error)
+	// Change the quantity of an item on a pending order
+	UpdateOrderItemQuantity(ctx context.Context, in *UpdateOrderItemQuantityRequest, opts ...grpc.CallOption) (*UpdateOrderItemQuantityResponse, error)
 	// Update order status
 	UpdateOrderStatus(ctx context.Context, in *UpdateOrderStatusRequest, opts ...grpc.CallOption) (*UpdateOrderStatusResponse, error)
+	// Aggregate revenue and order counts per day, week or month
+	GetRevenueSummary(ctx context.Context, in *GetRevenueSummaryRequest, opts ...grpc.CallOption) (*GetRevenueSummaryResponse, error)
+	// Aggregate the sales of paid and delivered orders per day, week or month, with canceled orders apart
+	GetSalesReport(ctx context.Context, in *GetSalesReportRequest, opts ...grpc.CallOption) (*GetSalesReportResponse, error)
+	// Rank products by their sales in paid and delivered orders
+	GetTopProducts(ctx context.Context, in *GetTopProductsRequest, opts ...grpc.CallOption) (*GetTopProductsResponse, error)
+	// Detach a user's orders from them and strip personal shipping details
+	AnonymiseUserOrders(ctx context.Context, in *AnonymiseUserOrdersRequest, opts ...grpc.CallOption) (*AnonymiseUserOrdersResponse, error)
+	// Stream the order's current status and every change until it is delivered or canceled
+	WatchOrderStatus(ctx context.Context, in *WatchOrderStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderStatusEvent], error)
+	// Report whether the user has a paid, shipped or delivered order containing the product
+	HasPurchasedProduct(ctx context.Context, in *HasPurchasedProductRequest, opts ...grpc.CallOption) (*HasPurchasedProductResponse, error)
+	// Return the order's invoice as a PDF, issuing it with the next invoice number on the first request
+	GetOrderInvoice(ctx context.Context, in *GetOrderInvoiceRequest, opts ...grpc.CallOption) (*GetOrderInvoiceResponse, error)
+	// Stream every order matching the filters in id order, in batches, for exports too large for ListOrders pages
+	ExportOrders(ctx context.Context, in *ExportOrdersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportOrdersResponse], error)
 }
 
 type orderServiceClient struct {
@@ -105,6 +132,16 @@ func (c *orderServiceClient) RemoveOrderItem(ctx context.Context, in *RemoveOrde
 	return out, nil
 }
 
+func (c *orderServiceClient) UpdateOrderItemQuantity(ctx context.Context, in *UpdateOrderItemQuantityRequest, opts ...grpc.CallOption) (*UpdateOrderItemQuantityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateOrderItemQuantityResponse)
+	err := c.cc.Invoke(ctx, OrderService_UpdateOrderItemQuantity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *orderServiceClient) UpdateOrderStatus(ctx context.Context, in *UpdateOrderStatusRequest, opts ...grpc.CallOption) (*UpdateOrderStatusResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(UpdateOrderStatusResponse)
@@ -115,6 +152,104 @@ func (c *orderServiceClient) UpdateOrderStatus(ctx context.Context, in *UpdateOr
 	return out, nil
 }
 
+func (c *orderServiceClient) GetRevenueSummary(ctx context.Context, in *GetRevenueSummaryRequest, opts ...grpc.CallOption) (*GetRevenueSummaryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRevenueSummaryResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetRevenueSummary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetSalesReport(ctx context.Context, in *GetSalesReportRequest, opts ...grpc.CallOption) (*GetSalesReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSalesReportResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetSalesReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetTopProducts(ctx context.Context, in *GetTopProductsRequest, opts ...grpc.CallOption) (*GetTopProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTopProductsResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetTopProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) AnonymiseUserOrders(ctx context.Context, in *AnonymiseUserOrdersRequest, opts ...grpc.CallOption) (*AnonymiseUserOrdersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnonymiseUserOrdersResponse)
+	err := c.cc.Invoke(ctx, OrderService_AnonymiseUserOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) WatchOrderStatus(ctx context.Context, in *WatchOrderStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderStatusEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OrderService_ServiceDesc.Streams[0], OrderService_WatchOrderStatus_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchOrderStatusRequest, OrderStatusEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderService_WatchOrderStatusClient = grpc.ServerStreamingClient[OrderStatusEvent]
+
+func (c *orderServiceClient) HasPurchasedProduct(ctx context.Context, in *HasPurchasedProductRequest, opts ...grpc.CallOption) (*HasPurchasedProductResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HasPurchasedProductResponse)
+	err := c.cc.Invoke(ctx, OrderService_HasPurchasedProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetOrderInvoice(ctx context.Context, in *GetOrderInvoiceRequest, opts ...grpc.CallOption) (*GetOrderInvoiceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrderInvoiceResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetOrderInvoice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ExportOrders(ctx context.Context, in *ExportOrdersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportOrdersResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OrderService_ServiceDesc.Streams[1], OrderService_ExportOrders_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportOrdersRequest, ExportOrdersResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderService_ExportOrdersClient = grpc.ServerStreamingClient[ExportOrdersResponse]
+
 // OrderServiceServer is the server API for OrderService service.
 // All implementations must embed UnimplementedOrderServiceServer
 // for forward compatibility.
@@ -125,14 +260,32 @@ type OrderServiceServer interface {
 	CreateOrder(context.Context, *CreateOrderRequest) (*CreateOrderResponse, error)
 	// Get order by id
 	GetOrderByID(context.Context, *GetOrderByIDRequest) (*GetOrderByIDResponse, error)
-	// List orders with pagination and optional user filter
+	// List orders with pagination, optionally filtered by user, status and creation date
 	ListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error)
 	// Add item to order
 	AddOrderItem(context.Context, *AddOrderItemRequest) (*AddOrderItemResponse, error)
 	// Remove item from order
 	RemoveOrderItem(context.Context, *RemoveOrderItemRequest) (*RemoveOrderItemResponse, error)
+	// Change the quantity of an item on a pending order
+	UpdateOrderItemQuantity(context.Context, *UpdateOrderItemQuantityRequest) (*UpdateOrderItemQuantityResponse, error)
 	// Update order status
 	UpdateOrderStatus(context.Context, *UpdateOrderStatusRequest) (*UpdateOrderStatusResponse, error)
+	// Aggregate revenue and order counts per day, week or month
+	GetRevenueSummary(context.Context, *GetRevenueSummaryRequest) (*GetRevenueSummaryResponse, error)
+	// Aggregate the sales of paid and delivered orders per day, week or month, with canceled orders apart
+	GetSalesReport(context.Context, *GetSalesReportRequest) (*GetSalesReportResponse, error)
+	// Rank products by their sales in paid and delivered orders
+	GetTopProducts(context.Context, *GetTopProductsRequest) (*GetTopProductsResponse, error)
+	// Detach a user's orders from them and strip personal shipping details
+	AnonymiseUserOrders(context.Context, *AnonymiseUserOrdersRequest) (*AnonymiseUserOrdersResponse, error)
+	// Stream the order's current status and every change until it is delivered or canceled
+	WatchOrderStatus(*WatchOrderStatusRequest, grpc.ServerStreamingServer[OrderStatusEvent]) error
+	// Report whether the user has a paid, shipped or delivered order containing the product
+	HasPurchasedProduct(context.Context, *HasPurchasedProductRequest) (*HasPurchasedProductResponse, error)
+	// Return the order's invoice as a PDF, issuing it with the next invoice number on the first request
+	GetOrderInvoice(context.Context, *GetOrderInvoiceRequest) (*GetOrderInvoiceResponse, error)
+	// Stream every order matching the filters in id order, in batches, for exports too large for ListOrders pages
+	ExportOrders(*ExportOrdersRequest, grpc.ServerStreamingServer[ExportOrdersResponse]) error
 	mustEmbedUnimplementedOrderServiceServer()
 }
 
@@ -158,9 +311,36 @@ func (UnimplementedOrderServiceServer) AddOrderItem(context.Context, *AddOrderIt
 func (UnimplementedOrderServiceServer) RemoveOrderItem(context.Context, *RemoveOrderItemRequest) (*RemoveOrderItemResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RemoveOrderItem not implemented")
 }
+func (UnimplementedOrderServiceServer) UpdateOrderItemQuantity(context.Context, *UpdateOrderItemQuantityRequest) (*UpdateOrderItemQuantityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateOrderItemQuantity not implemented")
+}
 func (UnimplementedOrderServiceServer) UpdateOrderStatus(context.Context, *UpdateOrderStatusRequest) (*UpdateOrderStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateOrderStatus not implemented")
 }
+func (UnimplementedOrderServiceServer) GetRevenueSummary(context.Context, *GetRevenueSummaryRequest) (*GetRevenueSummaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRevenueSummary not implemented")
+}
+func (UnimplementedOrderServiceServer) GetSalesReport(context.Context, *GetSalesReportRequest) (*GetSalesReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSalesReport not implemented")
+}
+func (UnimplementedOrderServiceServer) GetTopProducts(context.Context, *GetTopProductsRequest) (*GetTopProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTopProducts not implemented")
+}
+func (UnimplementedOrderServiceServer) AnonymiseUserOrders(context.Context, *AnonymiseUserOrdersRequest) (*AnonymiseUserOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnonymiseUserOrders not implemented")
+}
+func (UnimplementedOrderServiceServer) WatchOrderStatus(*WatchOrderStatusRequest, grpc.ServerStreamingServer[OrderStatusEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOrderStatus not implemented")
+}
+func (UnimplementedOrderServiceServer) HasPurchasedProduct(context.Context, *HasPurchasedProductRequest) (*HasPurchasedProductResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HasPurchasedProduct not implemented")
+}
+func (UnimplementedOrderServiceServer) GetOrderInvoice(context.Context, *GetOrderInvoiceRequest) (*GetOrderInvoiceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderInvoice not implemented")
+}
+func (UnimplementedOrderServiceServer) ExportOrders(*ExportOrdersRequest, grpc.ServerStreamingServer[ExportOrdersResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportOrders not implemented")
+}
 func (UnimplementedOrderServiceServer) mustEmbedUnimplementedOrderServiceServer() {}
 func (UnimplementedOrderServiceServer) testEmbeddedByValue()                      {}
 
@@ -272,6 +452,24 @@ func _OrderService_RemoveOrderItem_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrderService_UpdateOrderItemQuantity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateOrderItemQuantityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).UpdateOrderItemQuantity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_UpdateOrderItemQuantity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).UpdateOrderItemQuantity(ctx, req.(*UpdateOrderItemQuantityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OrderService_UpdateOrderStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateOrderStatusRequest)
 	if err := dec(in); err != nil {
@@ -290,6 +488,136 @@ func _OrderService_UpdateOrderStatus_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrderService_GetRevenueSummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRevenueSummaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetRevenueSummary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetRevenueSummary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetRevenueSummary(ctx, req.(*GetRevenueSummaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetSalesReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSalesReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetSalesReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetSalesReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetSalesReport(ctx, req.(*GetSalesReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetTopProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTopProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetTopProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetTopProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetTopProducts(ctx, req.(*GetTopProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_AnonymiseUserOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnonymiseUserOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).AnonymiseUserOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_AnonymiseUserOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).AnonymiseUserOrders(ctx, req.(*AnonymiseUserOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_WatchOrderStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchOrderStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrderServiceServer).WatchOrderStatus(m, &grpc.GenericServerStream[WatchOrderStatusRequest, OrderStatusEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderService_WatchOrderStatusServer = grpc.ServerStreamingServer[OrderStatusEvent]
+
+func _OrderService_HasPurchasedProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HasPurchasedProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).HasPurchasedProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_HasPurchasedProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).HasPurchasedProduct(ctx, req.(*HasPurchasedProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetOrderInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderInvoiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetOrderInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetOrderInvoice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetOrderInvoice(ctx, req.(*GetOrderInvoiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ExportOrders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportOrdersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrderServiceServer).ExportOrders(m, &grpc.GenericServerStream[ExportOrdersRequest, ExportOrdersResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderService_ExportOrdersServer = grpc.ServerStreamingServer[ExportOrdersResponse]
+
 // OrderService_ServiceDesc is the grpc.ServiceDesc for OrderService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -317,11 +645,50 @@ var OrderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RemoveOrderItem",
 			Handler:    _OrderService_RemoveOrderItem_Handler,
 		},
+		{
+			MethodName: "UpdateOrderItemQuantity",
+			Handler:    _OrderService_UpdateOrderItemQuantity_Handler,
+		},
 		{
 			MethodName: "UpdateOrderStatus",
 			Handler:    _OrderService_UpdateOrderStatus_Handler,
 		},
+		{
+			MethodName: "GetRevenueSummary",
+			Handler:    _OrderService_GetRevenueSummary_Handler,
+		},
+		{
+			MethodName: "GetSalesReport",
+			Handler:    _OrderService_GetSalesReport_Handler,
+		},
+		{
+			MethodName: "GetTopProducts",
+			Handler:    _OrderService_GetTopProducts_Handler,
+		},
+		{
+			MethodName: "AnonymiseUserOrders",
+			Handler:    _OrderService_AnonymiseUserOrders_Handler,
+		},
+		{
+			MethodName: "HasPurchasedProduct",
+			Handler:    _OrderService_HasPurchasedProduct_Handler,
+		},
+		{
+			MethodName: "GetOrderInvoice",
+			Handler:    _OrderService_GetOrderInvoice_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOrderStatus",
+			Handler:       _OrderService_WatchOrderStatus_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportOrders",
+			Handler:       _OrderService_ExportOrders_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "shared/proto/v1/order.proto",
 }
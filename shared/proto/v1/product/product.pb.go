@@ -9,6 +9,7 @@ package product
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -215,8 +216,10 @@ func (x *CreateProductResponse) GetProduct() *Product {
 }
 
 type GetProductByIDRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Product fields to return; empty returns every field
+	Fields        *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=fields,proto3" json:"fields,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -258,6 +261,13 @@ func (x *GetProductByIDRequest) GetId() int64 {
 	return 0
 }
 
+func (x *GetProductByIDRequest) GetFields() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
 type GetProductByIDResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
@@ -302,29 +312,649 @@ func (x *GetProductByIDResponse) GetProduct() *Product {
 	return nil
 }
 
+type GetProductsByIDsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// At most 100 ids; duplicates are ignored
+	Ids []int64 `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	// Product fields to return; empty returns every field
+	Fields        *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductsByIDsRequest) Reset() {
+	*x = GetProductsByIDsRequest{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductsByIDsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductsByIDsRequest) ProtoMessage() {}
+
+func (x *GetProductsByIDsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductsByIDsRequest.ProtoReflect.Descriptor instead.
+func (*GetProductsByIDsRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetProductsByIDsRequest) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *GetProductsByIDsRequest) GetFields() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type GetProductsByIDsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Found products in the order their ids were first requested
+	Products []*Product `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	// Requested ids with no product, in request order
+	MissingIds    []int64 `protobuf:"varint,2,rep,packed,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductsByIDsResponse) Reset() {
+	*x = GetProductsByIDsResponse{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductsByIDsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductsByIDsResponse) ProtoMessage() {}
+
+func (x *GetProductsByIDsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductsByIDsResponse.ProtoReflect.Descriptor instead.
+func (*GetProductsByIDsResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetProductsByIDsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+func (x *GetProductsByIDsResponse) GetMissingIds() []int64 {
+	if x != nil {
+		return x.MissingIds
+	}
+	return nil
+}
+
 type ListProductsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
-	PerPage       int32                  `protobuf:"varint,2,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Page    int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage int32                  `protobuf:"varint,2,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	// Opaque next_cursor from a previous response; when set, page is ignored
+	Cursor string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Product fields to return; empty returns every field
+	Fields        *fieldmaskpb.FieldMask `protobuf:"bytes,4,opt,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsRequest) Reset() {
+	*x = ListProductsRequest{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsRequest) ProtoMessage() {}
+
+func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
+func (*ListProductsRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListProductsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListProductsRequest) GetPerPage() int32 {
+	if x != nil {
+		return x.PerPage
+	}
+	return 0
+}
+
+func (x *ListProductsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListProductsRequest) GetFields() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type ListProductsResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Products   []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	TotalCount int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	// Empty when there are no more products
+	NextCursor    string `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsResponse) Reset() {
+	*x = ListProductsResponse{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsResponse) ProtoMessage() {}
+
+func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
+func (*ListProductsResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+func (x *ListProductsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *ListProductsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type UpdateProductRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ShortDescription string                 `protobuf:"bytes,3,opt,name=short_description,json=shortDescription,proto3" json:"short_description,omitempty"`
+	Description      string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Price            float32                `protobuf:"fixed32,5,opt,name=price,proto3" json:"price,omitempty"`
+	DiscountType     DiscountType           `protobuf:"varint,6,opt,name=discount_type,json=discountType,proto3,enum=product.DiscountType" json:"discount_type,omitempty"`
+	DiscountValue    float32                `protobuf:"fixed32,7,opt,name=discount_value,json=discountValue,proto3" json:"discount_value,omitempty"`
+	ImageUrl         string                 `protobuf:"bytes,8,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	Quantity         int32                  `protobuf:"varint,9,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// Stock at or below which the product is low; left unchanged when unset
+	LowStockThreshold *int32 `protobuf:"varint,10,opt,name=low_stock_threshold,json=lowStockThreshold,proto3,oneof" json:"low_stock_threshold,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *UpdateProductRequest) Reset() {
+	*x = UpdateProductRequest{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProductRequest) ProtoMessage() {}
+
+func (x *UpdateProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProductRequest.ProtoReflect.Descriptor instead.
+func (*UpdateProductRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateProductRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateProductRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateProductRequest) GetShortDescription() string {
+	if x != nil {
+		return x.ShortDescription
+	}
+	return ""
+}
+
+func (x *UpdateProductRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UpdateProductRequest) GetPrice() float32 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *UpdateProductRequest) GetDiscountType() DiscountType {
+	if x != nil {
+		return x.DiscountType
+	}
+	return DiscountType_DISCOUNT_NONE
+}
+
+func (x *UpdateProductRequest) GetDiscountValue() float32 {
+	if x != nil {
+		return x.DiscountValue
+	}
+	return 0
+}
+
+func (x *UpdateProductRequest) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+func (x *UpdateProductRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *UpdateProductRequest) GetLowStockThreshold() int32 {
+	if x != nil && x.LowStockThreshold != nil {
+		return *x.LowStockThreshold
+	}
+	return 0
+}
+
+type UpdateProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProductResponse) Reset() {
+	*x = UpdateProductResponse{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProductResponse) ProtoMessage() {}
+
+func (x *UpdateProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProductResponse.ProtoReflect.Descriptor instead.
+func (*UpdateProductResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateProductResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type DeleteProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductRequest) Reset() {
+	*x = DeleteProductRequest{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductRequest) ProtoMessage() {}
+
+func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteProductRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductResponse) Reset() {
+	*x = DeleteProductResponse{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductResponse) ProtoMessage() {}
+
+func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProductResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeleteProductResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type AdjustProductStockRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Positive to restock, negative to deduct; must not be zero
+	Delta int32 `protobuf:"varint,2,opt,name=delta,proto3" json:"delta,omitempty"`
+	// recount, damage, return, correction or order; defaults to correction
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// The order behind an order movement; required for them and refused for the others
+	OrderId       int64 `protobuf:"varint,4,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustProductStockRequest) Reset() {
+	*x = AdjustProductStockRequest{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustProductStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustProductStockRequest) ProtoMessage() {}
+
+func (x *AdjustProductStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustProductStockRequest.ProtoReflect.Descriptor instead.
+func (*AdjustProductStockRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AdjustProductStockRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *AdjustProductStockRequest) GetDelta() int32 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *AdjustProductStockRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AdjustProductStockRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type AdjustProductStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	StockLevel    int32                  `protobuf:"varint,2,opt,name=stock_level,json=stockLevel,proto3" json:"stock_level,omitempty"`
+	Movement      *StockMovement         `protobuf:"bytes,3,opt,name=movement,proto3" json:"movement,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustProductStockResponse) Reset() {
+	*x = AdjustProductStockResponse{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustProductStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustProductStockResponse) ProtoMessage() {}
+
+func (x *AdjustProductStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustProductStockResponse.ProtoReflect.Descriptor instead.
+func (*AdjustProductStockResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AdjustProductStockResponse) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *AdjustProductStockResponse) GetStockLevel() int32 {
+	if x != nil {
+		return x.StockLevel
+	}
+	return 0
+}
+
+func (x *AdjustProductStockResponse) GetMovement() *StockMovement {
+	if x != nil {
+		return x.Movement
+	}
+	return nil
+}
+
+type ListStockMovementsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage       int32                  `protobuf:"varint,3,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListProductsRequest) Reset() {
-	*x = ListProductsRequest{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[4]
+func (x *ListStockMovementsRequest) Reset() {
+	*x = ListStockMovementsRequest{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListProductsRequest) String() string {
+func (x *ListStockMovementsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListProductsRequest) ProtoMessage() {}
+func (*ListStockMovementsRequest) ProtoMessage() {}
 
-func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[4]
+func (x *ListStockMovementsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -335,48 +965,56 @@ func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
-func (*ListProductsRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ListStockMovementsRequest.ProtoReflect.Descriptor instead.
+func (*ListStockMovementsRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *ListProductsRequest) GetPage() int32 {
+func (x *ListStockMovementsRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ListStockMovementsRequest) GetPage() int32 {
 	if x != nil {
 		return x.Page
 	}
 	return 0
 }
 
-func (x *ListProductsRequest) GetPerPage() int32 {
+func (x *ListStockMovementsRequest) GetPerPage() int32 {
 	if x != nil {
 		return x.PerPage
 	}
 	return 0
 }
 
-type ListProductsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
-	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+type ListStockMovementsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first
+	Movements     []*StockMovement `protobuf:"bytes,1,rep,name=movements,proto3" json:"movements,omitempty"`
+	TotalCount    int32            `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListProductsResponse) Reset() {
-	*x = ListProductsResponse{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[5]
+func (x *ListStockMovementsResponse) Reset() {
+	*x = ListStockMovementsResponse{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListProductsResponse) String() string {
+func (x *ListStockMovementsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListProductsResponse) ProtoMessage() {}
+func (*ListStockMovementsResponse) ProtoMessage() {}
 
-func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[5]
+func (x *ListStockMovementsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -387,55 +1025,58 @@ func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
-func (*ListProductsResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use ListStockMovementsResponse.ProtoReflect.Descriptor instead.
+func (*ListStockMovementsResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *ListProductsResponse) GetProducts() []*Product {
+func (x *ListStockMovementsResponse) GetMovements() []*StockMovement {
 	if x != nil {
-		return x.Products
+		return x.Movements
 	}
 	return nil
 }
 
-func (x *ListProductsResponse) GetTotalCount() int32 {
+func (x *ListStockMovementsResponse) GetTotalCount() int32 {
 	if x != nil {
 		return x.TotalCount
 	}
 	return 0
 }
 
-type UpdateProductRequest struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	Id               int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	ShortDescription string                 `protobuf:"bytes,3,opt,name=short_description,json=shortDescription,proto3" json:"short_description,omitempty"`
-	Description      string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
-	Price            float32                `protobuf:"fixed32,5,opt,name=price,proto3" json:"price,omitempty"`
-	DiscountType     DiscountType           `protobuf:"varint,6,opt,name=discount_type,json=discountType,proto3,enum=product.DiscountType" json:"discount_type,omitempty"`
-	DiscountValue    float32                `protobuf:"fixed32,7,opt,name=discount_value,json=discountValue,proto3" json:"discount_value,omitempty"`
-	ImageUrl         string                 `protobuf:"bytes,8,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
-	Quantity         int32                  `protobuf:"varint,9,opt,name=quantity,proto3" json:"quantity,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+type StockMovement struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Delta     int32                  `protobuf:"varint,3,opt,name=delta,proto3" json:"delta,omitempty"`
+	Reason    string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	// 0 unless the reason is order
+	OrderId int64 `protobuf:"varint,5,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	// The user who made the adjustment; 0 for a service acting on its own, such as for an order
+	UserId int64 `protobuf:"varint,6,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// The product's stock right after the movement
+	StockAfter int32 `protobuf:"varint,7,opt,name=stock_after,json=stockAfter,proto3" json:"stock_after,omitempty"`
+	// RFC 3339
+	CreatedAt     string `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateProductRequest) Reset() {
-	*x = UpdateProductRequest{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[6]
+func (x *StockMovement) Reset() {
+	*x = StockMovement{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateProductRequest) String() string {
+func (x *StockMovement) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateProductRequest) ProtoMessage() {}
+func (*StockMovement) ProtoMessage() {}
 
-func (x *UpdateProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[6]
+func (x *StockMovement) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -446,96 +1087,90 @@ func (x *UpdateProductRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateProductRequest.ProtoReflect.Descriptor instead.
-func (*UpdateProductRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use StockMovement.ProtoReflect.Descriptor instead.
+func (*StockMovement) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *UpdateProductRequest) GetId() int32 {
+func (x *StockMovement) GetId() int64 {
 	if x != nil {
 		return x.Id
 	}
 	return 0
 }
 
-func (x *UpdateProductRequest) GetName() string {
+func (x *StockMovement) GetProductId() int64 {
 	if x != nil {
-		return x.Name
+		return x.ProductId
 	}
-	return ""
+	return 0
 }
 
-func (x *UpdateProductRequest) GetShortDescription() string {
+func (x *StockMovement) GetDelta() int32 {
 	if x != nil {
-		return x.ShortDescription
+		return x.Delta
 	}
-	return ""
+	return 0
 }
 
-func (x *UpdateProductRequest) GetDescription() string {
+func (x *StockMovement) GetReason() string {
 	if x != nil {
-		return x.Description
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *UpdateProductRequest) GetPrice() float32 {
+func (x *StockMovement) GetOrderId() int64 {
 	if x != nil {
-		return x.Price
+		return x.OrderId
 	}
 	return 0
 }
 
-func (x *UpdateProductRequest) GetDiscountType() DiscountType {
+func (x *StockMovement) GetUserId() int64 {
 	if x != nil {
-		return x.DiscountType
+		return x.UserId
 	}
-	return DiscountType_DISCOUNT_NONE
+	return 0
 }
 
-func (x *UpdateProductRequest) GetDiscountValue() float32 {
+func (x *StockMovement) GetStockAfter() int32 {
 	if x != nil {
-		return x.DiscountValue
+		return x.StockAfter
 	}
 	return 0
 }
 
-func (x *UpdateProductRequest) GetImageUrl() string {
+func (x *StockMovement) GetCreatedAt() string {
 	if x != nil {
-		return x.ImageUrl
+		return x.CreatedAt
 	}
 	return ""
 }
 
-func (x *UpdateProductRequest) GetQuantity() int32 {
-	if x != nil {
-		return x.Quantity
-	}
-	return 0
-}
-
-type UpdateProductResponse struct {
+type ListLowStockProductsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage       int32                  `protobuf:"varint,2,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateProductResponse) Reset() {
-	*x = UpdateProductResponse{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[7]
+func (x *ListLowStockProductsRequest) Reset() {
+	*x = ListLowStockProductsRequest{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateProductResponse) String() string {
+func (x *ListLowStockProductsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateProductResponse) ProtoMessage() {}
+func (*ListLowStockProductsRequest) ProtoMessage() {}
 
-func (x *UpdateProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[7]
+func (x *ListLowStockProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -546,84 +1181,49 @@ func (x *UpdateProductResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateProductResponse.ProtoReflect.Descriptor instead.
-func (*UpdateProductResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{7}
-}
-
-func (x *UpdateProductResponse) GetProduct() *Product {
-	if x != nil {
-		return x.Product
-	}
-	return nil
-}
-
-type DeleteProductRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *DeleteProductRequest) Reset() {
-	*x = DeleteProductRequest{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[8]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *DeleteProductRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+// Deprecated: Use ListLowStockProductsRequest.ProtoReflect.Descriptor instead.
+func (*ListLowStockProductsRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{17}
 }
 
-func (*DeleteProductRequest) ProtoMessage() {}
-
-func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[8]
+func (x *ListLowStockProductsRequest) GetPage() int32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Page
 	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use DeleteProductRequest.ProtoReflect.Descriptor instead.
-func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{8}
+	return 0
 }
 
-func (x *DeleteProductRequest) GetId() int64 {
+func (x *ListLowStockProductsRequest) GetPerPage() int32 {
 	if x != nil {
-		return x.Id
+		return x.PerPage
 	}
 	return 0
 }
 
-type DeleteProductResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+type ListLowStockProductsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Lowest stock first, then furthest below the threshold
+	Products      []*Product `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	TotalCount    int32      `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteProductResponse) Reset() {
-	*x = DeleteProductResponse{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[9]
+func (x *ListLowStockProductsResponse) Reset() {
+	*x = ListLowStockProductsResponse{}
+	mi := &file_shared_proto_v1_product_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteProductResponse) String() string {
+func (x *ListLowStockProductsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteProductResponse) ProtoMessage() {}
+func (*ListLowStockProductsResponse) ProtoMessage() {}
 
-func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[9]
+func (x *ListLowStockProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_product_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -634,16 +1234,23 @@ func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteProductResponse.ProtoReflect.Descriptor instead.
-func (*DeleteProductResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use ListLowStockProductsResponse.ProtoReflect.Descriptor instead.
+func (*ListLowStockProductsResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *DeleteProductResponse) GetSuccess() bool {
+func (x *ListLowStockProductsResponse) GetProducts() []*Product {
 	if x != nil {
-		return x.Success
+		return x.Products
 	}
-	return false
+	return nil
+}
+
+func (x *ListLowStockProductsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
 }
 
 type Product struct {
@@ -657,13 +1264,18 @@ type Product struct {
 	DiscountValue    float32                `protobuf:"fixed32,7,opt,name=discount_value,json=discountValue,proto3" json:"discount_value,omitempty"`
 	ImageUrl         string                 `protobuf:"bytes,8,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
 	Quantity         int32                  `protobuf:"varint,9,opt,name=quantity,proto3" json:"quantity,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	// Only set by GetProductByID; 0 when the product has no reviews
+	AverageRating float32 `protobuf:"fixed32,10,opt,name=average_rating,json=averageRating,proto3" json:"average_rating,omitempty"`
+	ReviewCount   int32   `protobuf:"varint,11,opt,name=review_count,json=reviewCount,proto3" json:"review_count,omitempty"`
+	// Only set by UpdateProduct and ListLowStockProducts
+	LowStockThreshold int32 `protobuf:"varint,12,opt,name=low_stock_threshold,json=lowStockThreshold,proto3" json:"low_stock_threshold,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *Product) Reset() {
 	*x = Product{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[10]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -675,7 +1287,7 @@ func (x *Product) String() string {
 func (*Product) ProtoMessage() {}
 
 func (x *Product) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[10]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -688,7 +1300,7 @@ func (x *Product) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Product.ProtoReflect.Descriptor instead.
 func (*Product) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{10}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *Product) GetId() int32 {
@@ -754,6 +1366,27 @@ func (x *Product) GetQuantity() int32 {
 	return 0
 }
 
+func (x *Product) GetAverageRating() float32 {
+	if x != nil {
+		return x.AverageRating
+	}
+	return 0
+}
+
+func (x *Product) GetReviewCount() int32 {
+	if x != nil {
+		return x.ReviewCount
+	}
+	return 0
+}
+
+func (x *Product) GetLowStockThreshold() int32 {
+	if x != nil {
+		return x.LowStockThreshold
+	}
+	return 0
+}
+
 type CreateCategoryRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -764,7 +1397,7 @@ type CreateCategoryRequest struct {
 
 func (x *CreateCategoryRequest) Reset() {
 	*x = CreateCategoryRequest{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[11]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -776,7 +1409,7 @@ func (x *CreateCategoryRequest) String() string {
 func (*CreateCategoryRequest) ProtoMessage() {}
 
 func (x *CreateCategoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[11]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -789,7 +1422,7 @@ func (x *CreateCategoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateCategoryRequest.ProtoReflect.Descriptor instead.
 func (*CreateCategoryRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{11}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *CreateCategoryRequest) GetName() string {
@@ -816,7 +1449,7 @@ type CreateCategoryResponse struct {
 
 func (x *CreateCategoryResponse) Reset() {
 	*x = CreateCategoryResponse{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[12]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -828,7 +1461,7 @@ func (x *CreateCategoryResponse) String() string {
 func (*CreateCategoryResponse) ProtoMessage() {}
 
 func (x *CreateCategoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[12]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -841,7 +1474,7 @@ func (x *CreateCategoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateCategoryResponse.ProtoReflect.Descriptor instead.
 func (*CreateCategoryResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{12}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *CreateCategoryResponse) GetSuccess() bool {
@@ -867,7 +1500,7 @@ type GetCategoryByIDRequest struct {
 
 func (x *GetCategoryByIDRequest) Reset() {
 	*x = GetCategoryByIDRequest{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[13]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -879,7 +1512,7 @@ func (x *GetCategoryByIDRequest) String() string {
 func (*GetCategoryByIDRequest) ProtoMessage() {}
 
 func (x *GetCategoryByIDRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[13]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -892,7 +1525,7 @@ func (x *GetCategoryByIDRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetCategoryByIDRequest.ProtoReflect.Descriptor instead.
 func (*GetCategoryByIDRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{13}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *GetCategoryByIDRequest) GetId() int64 {
@@ -911,7 +1544,7 @@ type GetCategoryByIDResponse struct {
 
 func (x *GetCategoryByIDResponse) Reset() {
 	*x = GetCategoryByIDResponse{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[14]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -923,7 +1556,7 @@ func (x *GetCategoryByIDResponse) String() string {
 func (*GetCategoryByIDResponse) ProtoMessage() {}
 
 func (x *GetCategoryByIDResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[14]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -936,7 +1569,7 @@ func (x *GetCategoryByIDResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetCategoryByIDResponse.ProtoReflect.Descriptor instead.
 func (*GetCategoryByIDResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{14}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *GetCategoryByIDResponse) GetCategory() *Category {
@@ -956,7 +1589,7 @@ type ListCategoriesRequest struct {
 
 func (x *ListCategoriesRequest) Reset() {
 	*x = ListCategoriesRequest{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[15]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -968,7 +1601,7 @@ func (x *ListCategoriesRequest) String() string {
 func (*ListCategoriesRequest) ProtoMessage() {}
 
 func (x *ListCategoriesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[15]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -981,7 +1614,7 @@ func (x *ListCategoriesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListCategoriesRequest.ProtoReflect.Descriptor instead.
 func (*ListCategoriesRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{15}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *ListCategoriesRequest) GetPage() int32 {
@@ -1008,7 +1641,7 @@ type ListCategoriesResponse struct {
 
 func (x *ListCategoriesResponse) Reset() {
 	*x = ListCategoriesResponse{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[16]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1020,7 +1653,7 @@ func (x *ListCategoriesResponse) String() string {
 func (*ListCategoriesResponse) ProtoMessage() {}
 
 func (x *ListCategoriesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[16]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1033,7 +1666,7 @@ func (x *ListCategoriesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListCategoriesResponse.ProtoReflect.Descriptor instead.
 func (*ListCategoriesResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{16}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *ListCategoriesResponse) GetCategories() []*Category {
@@ -1061,7 +1694,7 @@ type UpdateCategoryRequest struct {
 
 func (x *UpdateCategoryRequest) Reset() {
 	*x = UpdateCategoryRequest{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[17]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1073,7 +1706,7 @@ func (x *UpdateCategoryRequest) String() string {
 func (*UpdateCategoryRequest) ProtoMessage() {}
 
 func (x *UpdateCategoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[17]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1086,7 +1719,7 @@ func (x *UpdateCategoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateCategoryRequest.ProtoReflect.Descriptor instead.
 func (*UpdateCategoryRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{17}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *UpdateCategoryRequest) GetId() int32 {
@@ -1120,7 +1753,7 @@ type UpdateCategoryResponse struct {
 
 func (x *UpdateCategoryResponse) Reset() {
 	*x = UpdateCategoryResponse{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[18]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1132,7 +1765,7 @@ func (x *UpdateCategoryResponse) String() string {
 func (*UpdateCategoryResponse) ProtoMessage() {}
 
 func (x *UpdateCategoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[18]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1145,7 +1778,7 @@ func (x *UpdateCategoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateCategoryResponse.ProtoReflect.Descriptor instead.
 func (*UpdateCategoryResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{18}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *UpdateCategoryResponse) GetSuccess() bool {
@@ -1171,7 +1804,7 @@ type DeleteCategoryRequest struct {
 
 func (x *DeleteCategoryRequest) Reset() {
 	*x = DeleteCategoryRequest{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[19]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1183,7 +1816,7 @@ func (x *DeleteCategoryRequest) String() string {
 func (*DeleteCategoryRequest) ProtoMessage() {}
 
 func (x *DeleteCategoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[19]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1196,7 +1829,7 @@ func (x *DeleteCategoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteCategoryRequest.ProtoReflect.Descriptor instead.
 func (*DeleteCategoryRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{19}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *DeleteCategoryRequest) GetId() int64 {
@@ -1215,7 +1848,7 @@ type DeleteCategoryResponse struct {
 
 func (x *DeleteCategoryResponse) Reset() {
 	*x = DeleteCategoryResponse{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[20]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1227,7 +1860,7 @@ func (x *DeleteCategoryResponse) String() string {
 func (*DeleteCategoryResponse) ProtoMessage() {}
 
 func (x *DeleteCategoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[20]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1240,7 +1873,7 @@ func (x *DeleteCategoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteCategoryResponse.ProtoReflect.Descriptor instead.
 func (*DeleteCategoryResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{20}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *DeleteCategoryResponse) GetSuccess() bool {
@@ -1261,7 +1894,7 @@ type Category struct {
 
 func (x *Category) Reset() {
 	*x = Category{}
-	mi := &file_shared_proto_v1_product_proto_msgTypes[21]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1273,7 +1906,7 @@ func (x *Category) String() string {
 func (*Category) ProtoMessage() {}
 
 func (x *Category) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_product_proto_msgTypes[21]
+	mi := &file_shared_proto_v1_product_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1286,7 +1919,7 @@ func (x *Category) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Category.ProtoReflect.Descriptor instead.
 func (*Category) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{21}
+	return file_shared_proto_v1_product_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *Category) GetId() int32 {
@@ -1314,7 +1947,7 @@ var File_shared_proto_v1_product_proto protoreflect.FileDescriptor
 
 const file_shared_proto_v1_product_proto_rawDesc = "" +
 	"\n" +
-	"\x1dshared/proto/v1/product.proto\x12\aproduct\"\xab\x02\n" +
+	"\x1dshared/proto/v1/product.proto\x12\aproduct\x1a google/protobuf/field_mask.proto\"\xab\x02\n" +
 	"\x14CreateProductRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12+\n" +
 	"\x11short_description\x18\x02 \x01(\tR\x10shortDescription\x12 \n" +
@@ -1325,18 +1958,30 @@ const file_shared_proto_v1_product_proto_rawDesc = "" +
 	"\timage_url\x18\a \x01(\tR\bimageUrl\x12\x1a\n" +
 	"\bquantity\x18\b \x01(\x05R\bquantity\"C\n" +
 	"\x15CreateProductResponse\x12*\n" +
-	"\aproduct\x18\x01 \x01(\v2\x10.product.ProductR\aproduct\"'\n" +
+	"\aproduct\x18\x01 \x01(\v2\x10.product.ProductR\aproduct\"[\n" +
 	"\x15GetProductByIDRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"D\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x122\n" +
+	"\x06fields\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\x06fields\"D\n" +
 	"\x16GetProductByIDResponse\x12*\n" +
-	"\aproduct\x18\x01 \x01(\v2\x10.product.ProductR\aproduct\"D\n" +
+	"\aproduct\x18\x01 \x01(\v2\x10.product.ProductR\aproduct\"_\n" +
+	"\x17GetProductsByIDsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x03R\x03ids\x122\n" +
+	"\x06fields\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\x06fields\"i\n" +
+	"\x18GetProductsByIDsResponse\x12,\n" +
+	"\bproducts\x18\x01 \x03(\v2\x10.product.ProductR\bproducts\x12\x1f\n" +
+	"\vmissing_ids\x18\x02 \x03(\x03R\n" +
+	"missingIds\"\x90\x01\n" +
 	"\x13ListProductsRequest\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x19\n" +
-	"\bper_page\x18\x02 \x01(\x05R\aperPage\"e\n" +
+	"\bper_page\x18\x02 \x01(\x05R\aperPage\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\x122\n" +
+	"\x06fields\x18\x04 \x01(\v2\x1a.google.protobuf.FieldMaskR\x06fields\"\x86\x01\n" +
 	"\x14ListProductsResponse\x12,\n" +
 	"\bproducts\x18\x01 \x03(\v2\x10.product.ProductR\bproducts\x12\x1f\n" +
 	"\vtotal_count\x18\x02 \x01(\x05R\n" +
-	"totalCount\"\xbb\x02\n" +
+	"totalCount\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\"\x88\x03\n" +
 	"\x14UpdateProductRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12+\n" +
@@ -1346,13 +1991,56 @@ const file_shared_proto_v1_product_proto_rawDesc = "" +
 	"\rdiscount_type\x18\x06 \x01(\x0e2\x15.product.DiscountTypeR\fdiscountType\x12%\n" +
 	"\x0ediscount_value\x18\a \x01(\x02R\rdiscountValue\x12\x1b\n" +
 	"\timage_url\x18\b \x01(\tR\bimageUrl\x12\x1a\n" +
-	"\bquantity\x18\t \x01(\x05R\bquantity\"C\n" +
+	"\bquantity\x18\t \x01(\x05R\bquantity\x123\n" +
+	"\x13low_stock_threshold\x18\n" +
+	" \x01(\x05H\x00R\x11lowStockThreshold\x88\x01\x01B\x16\n" +
+	"\x14_low_stock_threshold\"C\n" +
 	"\x15UpdateProductResponse\x12*\n" +
 	"\aproduct\x18\x01 \x01(\v2\x10.product.ProductR\aproduct\"&\n" +
 	"\x14DeleteProductRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\"1\n" +
 	"\x15DeleteProductResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x97\x02\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x83\x01\n" +
+	"\x19AdjustProductStockRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x14\n" +
+	"\x05delta\x18\x02 \x01(\x05R\x05delta\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x19\n" +
+	"\border_id\x18\x04 \x01(\x03R\aorderId\"\x90\x01\n" +
+	"\x1aAdjustProductStockResponse\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x1f\n" +
+	"\vstock_level\x18\x02 \x01(\x05R\n" +
+	"stockLevel\x122\n" +
+	"\bmovement\x18\x03 \x01(\v2\x16.product.StockMovementR\bmovement\"i\n" +
+	"\x19ListStockMovementsRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x19\n" +
+	"\bper_page\x18\x03 \x01(\x05R\aperPage\"s\n" +
+	"\x1aListStockMovementsResponse\x124\n" +
+	"\tmovements\x18\x01 \x03(\v2\x16.product.StockMovementR\tmovements\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\"\xe0\x01\n" +
+	"\rStockMovement\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\x12\x14\n" +
+	"\x05delta\x18\x03 \x01(\x05R\x05delta\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x19\n" +
+	"\border_id\x18\x05 \x01(\x03R\aorderId\x12\x17\n" +
+	"\auser_id\x18\x06 \x01(\x03R\x06userId\x12\x1f\n" +
+	"\vstock_after\x18\a \x01(\x05R\n" +
+	"stockAfter\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\tR\tcreatedAt\"L\n" +
+	"\x1bListLowStockProductsRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x19\n" +
+	"\bper_page\x18\x02 \x01(\x05R\aperPage\"m\n" +
+	"\x1cListLowStockProductsResponse\x12,\n" +
+	"\bproducts\x18\x01 \x03(\v2\x10.product.ProductR\bproducts\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\"\x91\x03\n" +
 	"\aProduct\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12+\n" +
@@ -1362,7 +2050,11 @@ const file_shared_proto_v1_product_proto_rawDesc = "" +
 	"\rdiscount_type\x18\x06 \x01(\tR\fdiscountType\x12%\n" +
 	"\x0ediscount_value\x18\a \x01(\x02R\rdiscountValue\x12\x1b\n" +
 	"\timage_url\x18\b \x01(\tR\bimageUrl\x12\x1a\n" +
-	"\bquantity\x18\t \x01(\x05R\bquantity\"M\n" +
+	"\bquantity\x18\t \x01(\x05R\bquantity\x12%\n" +
+	"\x0eaverage_rating\x18\n" +
+	" \x01(\x02R\raverageRating\x12!\n" +
+	"\freview_count\x18\v \x01(\x05R\vreviewCount\x12.\n" +
+	"\x13low_stock_threshold\x18\f \x01(\x05R\x11lowStockThreshold\"M\n" +
 	"\x15CreateCategoryRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\"L\n" +
@@ -1400,13 +2092,17 @@ const file_shared_proto_v1_product_proto_rawDesc = "" +
 	"\fDiscountType\x12\x11\n" +
 	"\rDISCOUNT_NONE\x10\x00\x12\x14\n" +
 	"\x10DISCOUNT_PERCENT\x10\x01\x12\x12\n" +
-	"\x0eDISCOUNT_FIXED\x10\x022\xc2\x06\n" +
+	"\x0eDISCOUNT_FIXED\x10\x022\xbe\t\n" +
 	"\x0eProductService\x12N\n" +
 	"\rCreateProduct\x12\x1d.product.CreateProductRequest\x1a\x1e.product.CreateProductResponse\x12Q\n" +
-	"\x0eGetProductByID\x12\x1e.product.GetProductByIDRequest\x1a\x1f.product.GetProductByIDResponse\x12K\n" +
+	"\x0eGetProductByID\x12\x1e.product.GetProductByIDRequest\x1a\x1f.product.GetProductByIDResponse\x12W\n" +
+	"\x10GetProductsByIDs\x12 .product.GetProductsByIDsRequest\x1a!.product.GetProductsByIDsResponse\x12K\n" +
 	"\fListProducts\x12\x1c.product.ListProductsRequest\x1a\x1d.product.ListProductsResponse\x12N\n" +
 	"\rUpdateProduct\x12\x1d.product.UpdateProductRequest\x1a\x1e.product.UpdateProductResponse\x12N\n" +
-	"\rDeleteProduct\x12\x1d.product.DeleteProductRequest\x1a\x1e.product.DeleteProductResponse\x12Q\n" +
+	"\rDeleteProduct\x12\x1d.product.DeleteProductRequest\x1a\x1e.product.DeleteProductResponse\x12]\n" +
+	"\x12AdjustProductStock\x12\".product.AdjustProductStockRequest\x1a#.product.AdjustProductStockResponse\x12]\n" +
+	"\x12ListStockMovements\x12\".product.ListStockMovementsRequest\x1a#.product.ListStockMovementsResponse\x12c\n" +
+	"\x14ListLowStockProducts\x12$.product.ListLowStockProductsRequest\x1a%.product.ListLowStockProductsResponse\x12Q\n" +
 	"\x0eCreateCategory\x12\x1e.product.CreateCategoryRequest\x1a\x1f.product.CreateCategoryResponse\x12T\n" +
 	"\x0fGetCategoryByID\x12\x1f.product.GetCategoryByIDRequest\x1a .product.GetCategoryByIDResponse\x12Q\n" +
 	"\x0eListCategories\x12\x1e.product.ListCategoriesRequest\x1a\x1f.product.ListCategoriesResponse\x12Q\n" +
@@ -1426,66 +2122,91 @@ func file_shared_proto_v1_product_proto_rawDescGZIP() []byte {
 }
 
 var file_shared_proto_v1_product_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_shared_proto_v1_product_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_shared_proto_v1_product_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_shared_proto_v1_product_proto_goTypes = []any{
-	(DiscountType)(0),               // 0: product.DiscountType
-	(*CreateProductRequest)(nil),    // 1: product.CreateProductRequest
-	(*CreateProductResponse)(nil),   // 2: product.CreateProductResponse
-	(*GetProductByIDRequest)(nil),   // 3: product.GetProductByIDRequest
-	(*GetProductByIDResponse)(nil),  // 4: product.GetProductByIDResponse
-	(*ListProductsRequest)(nil),     // 5: product.ListProductsRequest
-	(*ListProductsResponse)(nil),    // 6: product.ListProductsResponse
-	(*UpdateProductRequest)(nil),    // 7: product.UpdateProductRequest
-	(*UpdateProductResponse)(nil),   // 8: product.UpdateProductResponse
-	(*DeleteProductRequest)(nil),    // 9: product.DeleteProductRequest
-	(*DeleteProductResponse)(nil),   // 10: product.DeleteProductResponse
-	(*Product)(nil),                 // 11: product.Product
-	(*CreateCategoryRequest)(nil),   // 12: product.CreateCategoryRequest
-	(*CreateCategoryResponse)(nil),  // 13: product.CreateCategoryResponse
-	(*GetCategoryByIDRequest)(nil),  // 14: product.GetCategoryByIDRequest
-	(*GetCategoryByIDResponse)(nil), // 15: product.GetCategoryByIDResponse
-	(*ListCategoriesRequest)(nil),   // 16: product.ListCategoriesRequest
-	(*ListCategoriesResponse)(nil),  // 17: product.ListCategoriesResponse
-	(*UpdateCategoryRequest)(nil),   // 18: product.UpdateCategoryRequest
-	(*UpdateCategoryResponse)(nil),  // 19: product.UpdateCategoryResponse
-	(*DeleteCategoryRequest)(nil),   // 20: product.DeleteCategoryRequest
-	(*DeleteCategoryResponse)(nil),  // 21: product.DeleteCategoryResponse
-	(*Category)(nil),                // 22: product.Category
+	(DiscountType)(0),                    // 0: product.DiscountType
+	(*CreateProductRequest)(nil),         // 1: product.CreateProductRequest
+	(*CreateProductResponse)(nil),        // 2: product.CreateProductResponse
+	(*GetProductByIDRequest)(nil),        // 3: product.GetProductByIDRequest
+	(*GetProductByIDResponse)(nil),       // 4: product.GetProductByIDResponse
+	(*GetProductsByIDsRequest)(nil),      // 5: product.GetProductsByIDsRequest
+	(*GetProductsByIDsResponse)(nil),     // 6: product.GetProductsByIDsResponse
+	(*ListProductsRequest)(nil),          // 7: product.ListProductsRequest
+	(*ListProductsResponse)(nil),         // 8: product.ListProductsResponse
+	(*UpdateProductRequest)(nil),         // 9: product.UpdateProductRequest
+	(*UpdateProductResponse)(nil),        // 10: product.UpdateProductResponse
+	(*DeleteProductRequest)(nil),         // 11: product.DeleteProductRequest
+	(*DeleteProductResponse)(nil),        // 12: product.DeleteProductResponse
+	(*AdjustProductStockRequest)(nil),    // 13: product.AdjustProductStockRequest
+	(*AdjustProductStockResponse)(nil),   // 14: product.AdjustProductStockResponse
+	(*ListStockMovementsRequest)(nil),    // 15: product.ListStockMovementsRequest
+	(*ListStockMovementsResponse)(nil),   // 16: product.ListStockMovementsResponse
+	(*StockMovement)(nil),                // 17: product.StockMovement
+	(*ListLowStockProductsRequest)(nil),  // 18: product.ListLowStockProductsRequest
+	(*ListLowStockProductsResponse)(nil), // 19: product.ListLowStockProductsResponse
+	(*Product)(nil),                      // 20: product.Product
+	(*CreateCategoryRequest)(nil),        // 21: product.CreateCategoryRequest
+	(*CreateCategoryResponse)(nil),       // 22: product.CreateCategoryResponse
+	(*GetCategoryByIDRequest)(nil),       // 23: product.GetCategoryByIDRequest
+	(*GetCategoryByIDResponse)(nil),      // 24: product.GetCategoryByIDResponse
+	(*ListCategoriesRequest)(nil),        // 25: product.ListCategoriesRequest
+	(*ListCategoriesResponse)(nil),       // 26: product.ListCategoriesResponse
+	(*UpdateCategoryRequest)(nil),        // 27: product.UpdateCategoryRequest
+	(*UpdateCategoryResponse)(nil),       // 28: product.UpdateCategoryResponse
+	(*DeleteCategoryRequest)(nil),        // 29: product.DeleteCategoryRequest
+	(*DeleteCategoryResponse)(nil),       // 30: product.DeleteCategoryResponse
+	(*Category)(nil),                     // 31: product.Category
+	(*fieldmaskpb.FieldMask)(nil),        // 32: google.protobuf.FieldMask
 }
 var file_shared_proto_v1_product_proto_depIdxs = []int32{
 	0,  // 0: product.CreateProductRequest.discount_type:type_name -> product.DiscountType
-	11, // 1: product.CreateProductResponse.product:type_name -> product.Product
-	11, // 2: product.GetProductByIDResponse.product:type_name -> product.Product
-	11, // 3: product.ListProductsResponse.products:type_name -> product.Product
-	0,  // 4: product.UpdateProductRequest.discount_type:type_name -> product.DiscountType
-	11, // 5: product.UpdateProductResponse.product:type_name -> product.Product
-	22, // 6: product.GetCategoryByIDResponse.category:type_name -> product.Category
-	22, // 7: product.ListCategoriesResponse.categories:type_name -> product.Category
-	1,  // 8: product.ProductService.CreateProduct:input_type -> product.CreateProductRequest
-	3,  // 9: product.ProductService.GetProductByID:input_type -> product.GetProductByIDRequest
-	5,  // 10: product.ProductService.ListProducts:input_type -> product.ListProductsRequest
-	7,  // 11: product.ProductService.UpdateProduct:input_type -> product.UpdateProductRequest
-	9,  // 12: product.ProductService.DeleteProduct:input_type -> product.DeleteProductRequest
-	12, // 13: product.ProductService.CreateCategory:input_type -> product.CreateCategoryRequest
-	14, // 14: product.ProductService.GetCategoryByID:input_type -> product.GetCategoryByIDRequest
-	16, // 15: product.ProductService.ListCategories:input_type -> product.ListCategoriesRequest
-	18, // 16: product.ProductService.UpdateCategory:input_type -> product.UpdateCategoryRequest
-	20, // 17: product.ProductService.DeleteCategory:input_type -> product.DeleteCategoryRequest
-	2,  // 18: product.ProductService.CreateProduct:output_type -> product.CreateProductResponse
-	4,  // 19: product.ProductService.GetProductByID:output_type -> product.GetProductByIDResponse
-	6,  // 20: product.ProductService.ListProducts:output_type -> product.ListProductsResponse
-	8,  // 21: product.ProductService.UpdateProduct:output_type -> product.UpdateProductResponse
-	10, // 22: product.ProductService.DeleteProduct:output_type -> product.DeleteProductResponse
-	13, // 23: product.ProductService.CreateCategory:output_type -> product.CreateCategoryResponse
-	15, // 24: product.ProductService.GetCategoryByID:output_type -> product.GetCategoryByIDResponse
-	17, // 25: product.ProductService.ListCategories:output_type -> product.ListCategoriesResponse
-	19, // 26: product.ProductService.UpdateCategory:output_type -> product.UpdateCategoryResponse
-	21, // 27: product.ProductService.DeleteCategory:output_type -> product.DeleteCategoryResponse
-	18, // [18:28] is the sub-list for method output_type
-	8,  // [8:18] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	20, // 1: product.CreateProductResponse.product:type_name -> product.Product
+	32, // 2: product.GetProductByIDRequest.fields:type_name -> google.protobuf.FieldMask
+	20, // 3: product.GetProductByIDResponse.product:type_name -> product.Product
+	32, // 4: product.GetProductsByIDsRequest.fields:type_name -> google.protobuf.FieldMask
+	20, // 5: product.GetProductsByIDsResponse.products:type_name -> product.Product
+	32, // 6: product.ListProductsRequest.fields:type_name -> google.protobuf.FieldMask
+	20, // 7: product.ListProductsResponse.products:type_name -> product.Product
+	0,  // 8: product.UpdateProductRequest.discount_type:type_name -> product.DiscountType
+	20, // 9: product.UpdateProductResponse.product:type_name -> product.Product
+	17, // 10: product.AdjustProductStockResponse.movement:type_name -> product.StockMovement
+	17, // 11: product.ListStockMovementsResponse.movements:type_name -> product.StockMovement
+	20, // 12: product.ListLowStockProductsResponse.products:type_name -> product.Product
+	31, // 13: product.GetCategoryByIDResponse.category:type_name -> product.Category
+	31, // 14: product.ListCategoriesResponse.categories:type_name -> product.Category
+	1,  // 15: product.ProductService.CreateProduct:input_type -> product.CreateProductRequest
+	3,  // 16: product.ProductService.GetProductByID:input_type -> product.GetProductByIDRequest
+	5,  // 17: product.ProductService.GetProductsByIDs:input_type -> product.GetProductsByIDsRequest
+	7,  // 18: product.ProductService.ListProducts:input_type -> product.ListProductsRequest
+	9,  // 19: product.ProductService.UpdateProduct:input_type -> product.UpdateProductRequest
+	11, // 20: product.ProductService.DeleteProduct:input_type -> product.DeleteProductRequest
+	13, // 21: product.ProductService.AdjustProductStock:input_type -> product.AdjustProductStockRequest
+	15, // 22: product.ProductService.ListStockMovements:input_type -> product.ListStockMovementsRequest
+	18, // 23: product.ProductService.ListLowStockProducts:input_type -> product.ListLowStockProductsRequest
+	21, // 24: product.ProductService.CreateCategory:input_type -> product.CreateCategoryRequest
+	23, // 25: product.ProductService.GetCategoryByID:input_type -> product.GetCategoryByIDRequest
+	25, // 26: product.ProductService.ListCategories:input_type -> product.ListCategoriesRequest
+	27, // 27: product.ProductService.UpdateCategory:input_type -> product.UpdateCategoryRequest
+	29, // 28: product.ProductService.DeleteCategory:input_type -> product.DeleteCategoryRequest
+	2,  // 29: product.ProductService.CreateProduct:output_type -> product.CreateProductResponse
+	4,  // 30: product.ProductService.GetProductByID:output_type -> product.GetProductByIDResponse
+	6,  // 31: product.ProductService.GetProductsByIDs:output_type -> product.GetProductsByIDsResponse
+	8,  // 32: product.ProductService.ListProducts:output_type -> product.ListProductsResponse
+	10, // 33: product.ProductService.UpdateProduct:output_type -> product.UpdateProductResponse
+	12, // 34: product.ProductService.DeleteProduct:output_type -> product.DeleteProductResponse
+	14, // 35: product.ProductService.AdjustProductStock:output_type -> product.AdjustProductStockResponse
+	16, // 36: product.ProductService.ListStockMovements:output_type -> product.ListStockMovementsResponse
+	19, // 37: product.ProductService.ListLowStockProducts:output_type -> product.ListLowStockProductsResponse
+	22, // 38: product.ProductService.CreateCategory:output_type -> product.CreateCategoryResponse
+	24, // 39: product.ProductService.GetCategoryByID:output_type -> product.GetCategoryByIDResponse
+	26, // 40: product.ProductService.ListCategories:output_type -> product.ListCategoriesResponse
+	28, // 41: product.ProductService.UpdateCategory:output_type -> product.UpdateCategoryResponse
+	30, // 42: product.ProductService.DeleteCategory:output_type -> product.DeleteCategoryResponse
+	29, // [29:43] is the sub-list for method output_type
+	15, // [15:29] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_shared_proto_v1_product_proto_init() }
@@ -1493,13 +2214,14 @@ func file_shared_proto_v1_product_proto_init() {
 	if File_shared_proto_v1_product_proto != nil {
 		return
 	}
+	file_shared_proto_v1_product_proto_msgTypes[8].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_shared_proto_v1_product_proto_rawDesc), len(file_shared_proto_v1_product_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   22,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
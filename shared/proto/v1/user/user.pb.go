@@ -186,11 +186,15 @@ func (x *LoginRequest) GetPassword() string {
 }
 
 type LoginResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
-	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	User  *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Token string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// two_factor_required is set instead of token for users with two-factor authentication enabled, who
+	// exchange challenge_token and a code at VerifyTwoFactor for it
+	TwoFactorRequired bool   `protobuf:"varint,3,opt,name=two_factor_required,json=twoFactorRequired,proto3" json:"two_factor_required,omitempty"`
+	ChallengeToken    string `protobuf:"bytes,4,opt,name=challenge_token,json=challengeToken,proto3" json:"challenge_token,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *LoginResponse) Reset() {
@@ -237,6 +241,20 @@ func (x *LoginResponse) GetToken() string {
 	return ""
 }
 
+func (x *LoginResponse) GetTwoFactorRequired() bool {
+	if x != nil {
+		return x.TwoFactorRequired
+	}
+	return false
+}
+
+func (x *LoginResponse) GetChallengeToken() string {
+	if x != nil {
+		return x.ChallengeToken
+	}
+	return ""
+}
+
 type GetUserByIDRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -282,10 +300,16 @@ func (x *GetUserByIDRequest) GetId() int32 {
 }
 
 type SearchUsersRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	PageNumber    int32                  `protobuf:"varint,2,opt,name=page_number,json=pageNumber,proto3" json:"page_number,omitempty"`
-	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Query      string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	PageNumber int32                  `protobuf:"varint,2,opt,name=page_number,json=pageNumber,proto3" json:"page_number,omitempty"`
+	PageSize   int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// customer or admin, matching the primary role or a granted one; empty matches every role
+	Role string `protobuf:"bytes,4,opt,name=role,proto3" json:"role,omitempty"`
+	// id or created_at; defaults to id
+	SortBy string `protobuf:"bytes,5,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	// asc or desc; defaults to asc
+	SortOrder     string `protobuf:"bytes,6,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -341,6 +365,27 @@ func (x *SearchUsersRequest) GetPageSize() int32 {
 	return 0
 }
 
+func (x *SearchUsersRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *SearchUsersRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *SearchUsersRequest) GetSortOrder() string {
+	if x != nil {
+		return x.SortOrder
+	}
+	return ""
+}
+
 type UpdateUserRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -505,6 +550,191 @@ func (x *DeleteUserResponse) GetSuccess() bool {
 	return false
 }
 
+type UnlockUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockUserRequest) Reset() {
+	*x = UnlockUserRequest{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockUserRequest) ProtoMessage() {}
+
+func (x *UnlockUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockUserRequest.ProtoReflect.Descriptor instead.
+func (*UnlockUserRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UnlockUserRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type UnlockUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockUserResponse) Reset() {
+	*x = UnlockUserResponse{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockUserResponse) ProtoMessage() {}
+
+func (x *UnlockUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockUserResponse.ProtoReflect.Descriptor instead.
+func (*UnlockUserResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UnlockUserResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ImpersonateUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int32                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImpersonateUserRequest) Reset() {
+	*x = ImpersonateUserRequest{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImpersonateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImpersonateUserRequest) ProtoMessage() {}
+
+func (x *ImpersonateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImpersonateUserRequest.ProtoReflect.Descriptor instead.
+func (*ImpersonateUserRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ImpersonateUserRequest) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type ImpersonateUserResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Token string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// expires_at is RFC 3339
+	ExpiresAt     string `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImpersonateUserResponse) Reset() {
+	*x = ImpersonateUserResponse{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImpersonateUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImpersonateUserResponse) ProtoMessage() {}
+
+func (x *ImpersonateUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImpersonateUserResponse.ProtoReflect.Descriptor instead.
+func (*ImpersonateUserResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ImpersonateUserResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ImpersonateUserResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
 type SearchUsersResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
@@ -515,7 +745,7 @@ type SearchUsersResponse struct {
 
 func (x *SearchUsersResponse) Reset() {
 	*x = SearchUsersResponse{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[9]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -527,7 +757,7 @@ func (x *SearchUsersResponse) String() string {
 func (*SearchUsersResponse) ProtoMessage() {}
 
 func (x *SearchUsersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[9]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -540,7 +770,7 @@ func (x *SearchUsersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SearchUsersResponse.ProtoReflect.Descriptor instead.
 func (*SearchUsersResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{9}
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *SearchUsersResponse) GetUsers() []*User {
@@ -569,7 +799,7 @@ type User struct {
 
 func (x *User) Reset() {
 	*x = User{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[10]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -581,7 +811,7 @@ func (x *User) String() string {
 func (*User) ProtoMessage() {}
 
 func (x *User) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[10]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -594,7 +824,7 @@ func (x *User) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use User.ProtoReflect.Descriptor instead.
 func (*User) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{10}
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *User) GetId() int32 {
@@ -633,13 +863,15 @@ type CreateAddressRequest struct {
 	State         string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
 	Street        string                 `protobuf:"bytes,6,opt,name=street,proto3" json:"street,omitempty"`
 	ZipCode       string                 `protobuf:"bytes,7,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	IsDefault     bool                   `protobuf:"varint,8,opt,name=is_default,json=isDefault,proto3" json:"is_default,omitempty"`
+	Label         string                 `protobuf:"bytes,9,opt,name=label,proto3" json:"label,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateAddressRequest) Reset() {
 	*x = CreateAddressRequest{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[11]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -651,7 +883,7 @@ func (x *CreateAddressRequest) String() string {
 func (*CreateAddressRequest) ProtoMessage() {}
 
 func (x *CreateAddressRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[11]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -664,7 +896,7 @@ func (x *CreateAddressRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateAddressRequest.ProtoReflect.Descriptor instead.
 func (*CreateAddressRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{11}
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *CreateAddressRequest) GetUserId() int32 {
@@ -709,6 +941,20 @@ func (x *CreateAddressRequest) GetZipCode() string {
 	return ""
 }
 
+func (x *CreateAddressRequest) GetIsDefault() bool {
+	if x != nil {
+		return x.IsDefault
+	}
+	return false
+}
+
+func (x *CreateAddressRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
 type CreateAddressResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Address       *Address               `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
@@ -718,7 +964,7 @@ type CreateAddressResponse struct {
 
 func (x *CreateAddressResponse) Reset() {
 	*x = CreateAddressResponse{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[12]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -730,7 +976,7 @@ func (x *CreateAddressResponse) String() string {
 func (*CreateAddressResponse) ProtoMessage() {}
 
 func (x *CreateAddressResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[12]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -743,7 +989,7 @@ func (x *CreateAddressResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateAddressResponse.ProtoReflect.Descriptor instead.
 func (*CreateAddressResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{12}
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *CreateAddressResponse) GetAddress() *Address {
@@ -762,7 +1008,7 @@ type GetAddressByIDRequest struct {
 
 func (x *GetAddressByIDRequest) Reset() {
 	*x = GetAddressByIDRequest{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[13]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -774,7 +1020,7 @@ func (x *GetAddressByIDRequest) String() string {
 func (*GetAddressByIDRequest) ProtoMessage() {}
 
 func (x *GetAddressByIDRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[13]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -787,7 +1033,7 @@ func (x *GetAddressByIDRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAddressByIDRequest.ProtoReflect.Descriptor instead.
 func (*GetAddressByIDRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{13}
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *GetAddressByIDRequest) GetId() int32 {
@@ -806,7 +1052,7 @@ type GetAddressByIDResponse struct {
 
 func (x *GetAddressByIDResponse) Reset() {
 	*x = GetAddressByIDResponse{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[14]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -818,7 +1064,7 @@ func (x *GetAddressByIDResponse) String() string {
 func (*GetAddressByIDResponse) ProtoMessage() {}
 
 func (x *GetAddressByIDResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[14]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -831,7 +1077,7 @@ func (x *GetAddressByIDResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAddressByIDResponse.ProtoReflect.Descriptor instead.
 func (*GetAddressByIDResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{14}
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *GetAddressByIDResponse) GetAddress() *Address {
@@ -850,7 +1096,7 @@ type ListAddressesByUserIDRequest struct {
 
 func (x *ListAddressesByUserIDRequest) Reset() {
 	*x = ListAddressesByUserIDRequest{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[15]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -862,7 +1108,7 @@ func (x *ListAddressesByUserIDRequest) String() string {
 func (*ListAddressesByUserIDRequest) ProtoMessage() {}
 
 func (x *ListAddressesByUserIDRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[15]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -875,7 +1121,7 @@ func (x *ListAddressesByUserIDRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListAddressesByUserIDRequest.ProtoReflect.Descriptor instead.
 func (*ListAddressesByUserIDRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{15}
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *ListAddressesByUserIDRequest) GetUserId() int32 {
@@ -894,7 +1140,7 @@ type ListAddressesByUserIDResponse struct {
 
 func (x *ListAddressesByUserIDResponse) Reset() {
 	*x = ListAddressesByUserIDResponse{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[16]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -906,7 +1152,7 @@ func (x *ListAddressesByUserIDResponse) String() string {
 func (*ListAddressesByUserIDResponse) ProtoMessage() {}
 
 func (x *ListAddressesByUserIDResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[16]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -919,7 +1165,7 @@ func (x *ListAddressesByUserIDResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListAddressesByUserIDResponse.ProtoReflect.Descriptor instead.
 func (*ListAddressesByUserIDResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{16}
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *ListAddressesByUserIDResponse) GetAddresses() []*Address {
@@ -930,20 +1176,23 @@ func (x *ListAddressesByUserIDResponse) GetAddresses() []*Address {
 }
 
 type UpdateAddressRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Country       string                 `protobuf:"bytes,1,opt,name=country,proto3" json:"country,omitempty"`
-	City          string                 `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
-	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
-	Street        string                 `protobuf:"bytes,4,opt,name=street,proto3" json:"street,omitempty"`
-	ZipCode       string                 `protobuf:"bytes,5,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
-	Id            int32                  `protobuf:"varint,6,opt,name=id,proto3" json:"id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Country string                 `protobuf:"bytes,1,opt,name=country,proto3" json:"country,omitempty"`
+	City    string                 `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+	State   string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	Street  string                 `protobuf:"bytes,4,opt,name=street,proto3" json:"street,omitempty"`
+	ZipCode string                 `protobuf:"bytes,5,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Id      int32                  `protobuf:"varint,6,opt,name=id,proto3" json:"id,omitempty"`
+	// requesting_user_id must own the address; the gateway sets it from the JWT
+	RequestingUserId int32  `protobuf:"varint,7,opt,name=requesting_user_id,json=requestingUserId,proto3" json:"requesting_user_id,omitempty"`
+	Label            string `protobuf:"bytes,8,opt,name=label,proto3" json:"label,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *UpdateAddressRequest) Reset() {
 	*x = UpdateAddressRequest{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[17]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -955,7 +1204,7 @@ func (x *UpdateAddressRequest) String() string {
 func (*UpdateAddressRequest) ProtoMessage() {}
 
 func (x *UpdateAddressRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[17]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -968,7 +1217,7 @@ func (x *UpdateAddressRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateAddressRequest.ProtoReflect.Descriptor instead.
 func (*UpdateAddressRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{17}
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *UpdateAddressRequest) GetCountry() string {
@@ -1013,6 +1262,20 @@ func (x *UpdateAddressRequest) GetId() int32 {
 	return 0
 }
 
+func (x *UpdateAddressRequest) GetRequestingUserId() int32 {
+	if x != nil {
+		return x.RequestingUserId
+	}
+	return 0
+}
+
+func (x *UpdateAddressRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
 type UpdateAddressResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Address       *Address               `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
@@ -1022,7 +1285,7 @@ type UpdateAddressResponse struct {
 
 func (x *UpdateAddressResponse) Reset() {
 	*x = UpdateAddressResponse{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[18]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1034,7 +1297,7 @@ func (x *UpdateAddressResponse) String() string {
 func (*UpdateAddressResponse) ProtoMessage() {}
 
 func (x *UpdateAddressResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[18]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1047,7 +1310,7 @@ func (x *UpdateAddressResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateAddressResponse.ProtoReflect.Descriptor instead.
 func (*UpdateAddressResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{18}
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *UpdateAddressResponse) GetAddress() *Address {
@@ -1066,7 +1329,7 @@ type DeleteAddressRequest struct {
 
 func (x *DeleteAddressRequest) Reset() {
 	*x = DeleteAddressRequest{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[19]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1078,7 +1341,7 @@ func (x *DeleteAddressRequest) String() string {
 func (*DeleteAddressRequest) ProtoMessage() {}
 
 func (x *DeleteAddressRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[19]
+	mi := &file_shared_proto_v1_user_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1091,38 +1354,645 @@ func (x *DeleteAddressRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteAddressRequest.ProtoReflect.Descriptor instead.
 func (*DeleteAddressRequest) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{19}
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeleteAddressRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteAddressResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAddressResponse) Reset() {
+	*x = DeleteAddressResponse{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAddressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAddressResponse) ProtoMessage() {}
+
+func (x *DeleteAddressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAddressResponse.ProtoReflect.Descriptor instead.
+func (*DeleteAddressResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *DeleteAddressResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type SetDefaultAddressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        int32                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDefaultAddressRequest) Reset() {
+	*x = SetDefaultAddressRequest{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDefaultAddressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDefaultAddressRequest) ProtoMessage() {}
+
+func (x *SetDefaultAddressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDefaultAddressRequest.ProtoReflect.Descriptor instead.
+func (*SetDefaultAddressRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SetDefaultAddressRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SetDefaultAddressRequest) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type SetDefaultAddressResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       *Address               `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDefaultAddressResponse) Reset() {
+	*x = SetDefaultAddressResponse{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDefaultAddressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDefaultAddressResponse) ProtoMessage() {}
+
+func (x *SetDefaultAddressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDefaultAddressResponse.ProtoReflect.Descriptor instead.
+func (*SetDefaultAddressResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SetDefaultAddressResponse) GetAddress() *Address {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+type Address struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        int32                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Country       string                 `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
+	City          string                 `protobuf:"bytes,4,opt,name=city,proto3" json:"city,omitempty"`
+	State         string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	Street        string                 `protobuf:"bytes,6,opt,name=street,proto3" json:"street,omitempty"`
+	ZipCode       string                 `protobuf:"bytes,7,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	IsDefault     bool                   `protobuf:"varint,8,opt,name=is_default,json=isDefault,proto3" json:"is_default,omitempty"`
+	Label         string                 `protobuf:"bytes,9,opt,name=label,proto3" json:"label,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Address) Reset() {
+	*x = Address{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Address) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Address) ProtoMessage() {}
+
+func (x *Address) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Address.ProtoReflect.Descriptor instead.
+func (*Address) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *Address) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Address) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Address) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Address) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Address) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Address) GetStreet() string {
+	if x != nil {
+		return x.Street
+	}
+	return ""
+}
+
+func (x *Address) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+func (x *Address) GetIsDefault() bool {
+	if x != nil {
+		return x.IsDefault
+	}
+	return false
+}
+
+func (x *Address) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+type APIKey struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Label string                 `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	// scopes are the permissions the key grants, e.g. product:write
+	Scopes []string `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	// prefix is the start of the key, enough to tell keys apart
+	Prefix string `protobuf:"bytes,4,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// user_id is the account the key acts as
+	UserId    int32  `protobuf:"varint,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CreatedAt string `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// last_used_at is empty for a key never used
+	LastUsedAt string `protobuf:"bytes,7,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	// revoked_at is empty for a key still in force
+	RevokedAt     string `protobuf:"bytes,8,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *APIKey) Reset() {
+	*x = APIKey{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *APIKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKey) ProtoMessage() {}
+
+func (x *APIKey) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKey.ProtoReflect.Descriptor instead.
+func (*APIKey) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *APIKey) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *APIKey) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *APIKey) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *APIKey) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *APIKey) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *APIKey) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *APIKey) GetLastUsedAt() string {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return ""
+}
+
+func (x *APIKey) GetRevokedAt() string {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return ""
+}
+
+type CreateAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Scopes        []string               `protobuf:"bytes,2,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	UserId        int32                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyRequest) Reset() {
+	*x = CreateAPIKeyRequest{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyRequest) ProtoMessage() {}
+
+func (x *CreateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CreateAPIKeyRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *CreateAPIKeyRequest) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type CreateAPIKeyResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	ApiKey *APIKey                `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	// key is the secret the consumer sends; only its hash is stored
+	Key           string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyResponse) Reset() {
+	*x = CreateAPIKeyResponse{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyResponse) ProtoMessage() {}
+
+func (x *CreateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CreateAPIKeyResponse) GetApiKey() *APIKey {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+func (x *CreateAPIKeyResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type RevokeAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyRequest) Reset() {
+	*x = RevokeAPIKeyRequest{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyRequest) ProtoMessage() {}
+
+func (x *RevokeAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RevokeAPIKeyRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type RevokeAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApiKey        *APIKey                `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyResponse) Reset() {
+	*x = RevokeAPIKeyResponse{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyResponse) ProtoMessage() {}
+
+func (x *RevokeAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *RevokeAPIKeyResponse) GetApiKey() *APIKey {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+type ListAPIKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage       int32                  `protobuf:"varint,2,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysRequest) Reset() {
+	*x = ListAPIKeysRequest{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteAddressRequest) GetId() int32 {
+func (x *ListAPIKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysRequest) ProtoMessage() {}
+
+func (x *ListAPIKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[33]
 	if x != nil {
-		return x.Id
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListAPIKeysRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
 	}
 	return 0
 }
 
-type DeleteAddressResponse struct {
+func (x *ListAPIKeysRequest) GetPerPage() int32 {
+	if x != nil {
+		return x.PerPage
+	}
+	return 0
+}
+
+type ListAPIKeysResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	ApiKeys       []*APIKey              `protobuf:"bytes,1,rep,name=api_keys,json=apiKeys,proto3" json:"api_keys,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteAddressResponse) Reset() {
-	*x = DeleteAddressResponse{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[20]
+func (x *ListAPIKeysResponse) Reset() {
+	*x = ListAPIKeysResponse{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteAddressResponse) String() string {
+func (x *ListAPIKeysResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteAddressResponse) ProtoMessage() {}
+func (*ListAPIKeysResponse) ProtoMessage() {}
 
-func (x *DeleteAddressResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[20]
+func (x *ListAPIKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1133,46 +2003,47 @@ func (x *DeleteAddressResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteAddressResponse.ProtoReflect.Descriptor instead.
-func (*DeleteAddressResponse) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use ListAPIKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *DeleteAddressResponse) GetSuccess() bool {
+func (x *ListAPIKeysResponse) GetApiKeys() []*APIKey {
 	if x != nil {
-		return x.Success
+		return x.ApiKeys
 	}
-	return false
+	return nil
 }
 
-type Address struct {
+func (x *ListAPIKeysResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type VerifyAPIKeyRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	UserId        int32                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Country       string                 `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
-	City          string                 `protobuf:"bytes,4,opt,name=city,proto3" json:"city,omitempty"`
-	State         string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
-	Street        string                 `protobuf:"bytes,6,opt,name=street,proto3" json:"street,omitempty"`
-	ZipCode       string                 `protobuf:"bytes,7,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Address) Reset() {
-	*x = Address{}
-	mi := &file_shared_proto_v1_user_proto_msgTypes[21]
+func (x *VerifyAPIKeyRequest) Reset() {
+	*x = VerifyAPIKeyRequest{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Address) String() string {
+func (x *VerifyAPIKeyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Address) ProtoMessage() {}
+func (*VerifyAPIKeyRequest) ProtoMessage() {}
 
-func (x *Address) ProtoReflect() protoreflect.Message {
-	mi := &file_shared_proto_v1_user_proto_msgTypes[21]
+func (x *VerifyAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1183,56 +2054,305 @@ func (x *Address) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Address.ProtoReflect.Descriptor instead.
-func (*Address) Descriptor() ([]byte, []int) {
-	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use VerifyAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *Address) GetId() int32 {
+func (x *VerifyAPIKeyRequest) GetKey() string {
 	if x != nil {
-		return x.Id
+		return x.Key
 	}
-	return 0
+	return ""
 }
 
-func (x *Address) GetUserId() int32 {
+type VerifyAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApiKey        *APIKey                `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyAPIKeyResponse) Reset() {
+	*x = VerifyAPIKeyResponse{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyAPIKeyResponse) ProtoMessage() {}
+
+func (x *VerifyAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *VerifyAPIKeyResponse) GetApiKey() *APIKey {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+type SetupTwoFactorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int32                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetupTwoFactorRequest) Reset() {
+	*x = SetupTwoFactorRequest{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetupTwoFactorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetupTwoFactorRequest) ProtoMessage() {}
+
+func (x *SetupTwoFactorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetupTwoFactorRequest.ProtoReflect.Descriptor instead.
+func (*SetupTwoFactorRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SetupTwoFactorRequest) GetUserId() int32 {
 	if x != nil {
 		return x.UserId
 	}
 	return 0
 }
 
-func (x *Address) GetCountry() string {
+type SetupTwoFactorResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// secret is base32, for typing into an authenticator; otpauth_url is the same as a QR code would carry
+	Secret        string `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	OtpauthUrl    string `protobuf:"bytes,2,opt,name=otpauth_url,json=otpauthUrl,proto3" json:"otpauth_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetupTwoFactorResponse) Reset() {
+	*x = SetupTwoFactorResponse{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetupTwoFactorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetupTwoFactorResponse) ProtoMessage() {}
+
+func (x *SetupTwoFactorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[38]
 	if x != nil {
-		return x.Country
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetupTwoFactorResponse.ProtoReflect.Descriptor instead.
+func (*SetupTwoFactorResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SetupTwoFactorResponse) GetSecret() string {
+	if x != nil {
+		return x.Secret
 	}
 	return ""
 }
 
-func (x *Address) GetCity() string {
+func (x *SetupTwoFactorResponse) GetOtpauthUrl() string {
 	if x != nil {
-		return x.City
+		return x.OtpauthUrl
 	}
 	return ""
 }
 
-func (x *Address) GetState() string {
+type EnableTwoFactorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int32                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableTwoFactorRequest) Reset() {
+	*x = EnableTwoFactorRequest{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableTwoFactorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableTwoFactorRequest) ProtoMessage() {}
+
+func (x *EnableTwoFactorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[39]
 	if x != nil {
-		return x.State
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableTwoFactorRequest.ProtoReflect.Descriptor instead.
+func (*EnableTwoFactorRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *EnableTwoFactorRequest) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *EnableTwoFactorRequest) GetCode() string {
+	if x != nil {
+		return x.Code
 	}
 	return ""
 }
 
-func (x *Address) GetStreet() string {
+type EnableTwoFactorResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// recovery_codes each stand in for a code once; they are only ever returned here
+	RecoveryCodes []string `protobuf:"bytes,1,rep,name=recovery_codes,json=recoveryCodes,proto3" json:"recovery_codes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableTwoFactorResponse) Reset() {
+	*x = EnableTwoFactorResponse{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableTwoFactorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableTwoFactorResponse) ProtoMessage() {}
+
+func (x *EnableTwoFactorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[40]
 	if x != nil {
-		return x.Street
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableTwoFactorResponse.ProtoReflect.Descriptor instead.
+func (*EnableTwoFactorResponse) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *EnableTwoFactorResponse) GetRecoveryCodes() []string {
+	if x != nil {
+		return x.RecoveryCodes
+	}
+	return nil
+}
+
+type VerifyTwoFactorRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ChallengeToken string                 `protobuf:"bytes,1,opt,name=challenge_token,json=challengeToken,proto3" json:"challenge_token,omitempty"`
+	// code is a TOTP code or a recovery code
+	Code          string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyTwoFactorRequest) Reset() {
+	*x = VerifyTwoFactorRequest{}
+	mi := &file_shared_proto_v1_user_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyTwoFactorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyTwoFactorRequest) ProtoMessage() {}
+
+func (x *VerifyTwoFactorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shared_proto_v1_user_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyTwoFactorRequest.ProtoReflect.Descriptor instead.
+func (*VerifyTwoFactorRequest) Descriptor() ([]byte, []int) {
+	return file_shared_proto_v1_user_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *VerifyTwoFactorRequest) GetChallengeToken() string {
+	if x != nil {
+		return x.ChallengeToken
 	}
 	return ""
 }
 
-func (x *Address) GetZipCode() string {
+func (x *VerifyTwoFactorRequest) GetCode() string {
 	if x != nil {
-		return x.ZipCode
+		return x.Code
 	}
 	return ""
 }
@@ -1252,18 +2372,24 @@ const file_shared_proto_v1_user_proto_rawDesc = "" +
 	".user.UserR\x04user\"@\n" +
 	"\fLoginRequest\x12\x14\n" +
 	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"E\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"\x9e\x01\n" +
 	"\rLoginResponse\x12\x1e\n" +
 	"\x04user\x18\x01 \x01(\v2\n" +
 	".user.UserR\x04user\x12\x14\n" +
-	"\x05token\x18\x02 \x01(\tR\x05token\"$\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12.\n" +
+	"\x13two_factor_required\x18\x03 \x01(\bR\x11twoFactorRequired\x12'\n" +
+	"\x0fchallenge_token\x18\x04 \x01(\tR\x0echallengeToken\"$\n" +
 	"\x12GetUserByIDRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x05R\x02id\"h\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"\xb4\x01\n" +
 	"\x12SearchUsersRequest\x12\x14\n" +
 	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1f\n" +
 	"\vpage_number\x18\x02 \x01(\x05R\n" +
 	"pageNumber\x12\x1b\n" +
-	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"}\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x12\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\x12\x17\n" +
+	"\asort_by\x18\x05 \x01(\tR\x06sortBy\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\x06 \x01(\tR\tsortOrder\"}\n" +
 	"\x11UpdateUserRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
@@ -1273,7 +2399,17 @@ const file_shared_proto_v1_user_proto_rawDesc = "" +
 	"\x11DeleteUserRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x05R\x02id\".\n" +
 	"\x12DeleteUserResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"M\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"#\n" +
+	"\x11UnlockUserRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\".\n" +
+	"\x12UnlockUserResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"1\n" +
+	"\x16ImpersonateUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x05R\x06userId\"N\n" +
+	"\x17ImpersonateUserResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\tR\texpiresAt\"M\n" +
 	"\x13SearchUsersResponse\x12 \n" +
 	"\x05users\x18\x01 \x03(\v2\n" +
 	".user.UserR\x05users\x12\x14\n" +
@@ -1282,14 +2418,17 @@ const file_shared_proto_v1_user_proto_rawDesc = "" +
 	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
 	"\x05email\x18\x03 \x01(\tR\x05email\x12\x12\n" +
-	"\x04role\x18\x04 \x01(\tR\x04role\"\xa6\x01\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\"\xdb\x01\n" +
 	"\x14CreateAddressRequest\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\x05R\x06userId\x12\x18\n" +
 	"\acountry\x18\x03 \x01(\tR\acountry\x12\x12\n" +
 	"\x04city\x18\x04 \x01(\tR\x04city\x12\x14\n" +
 	"\x05state\x18\x05 \x01(\tR\x05state\x12\x16\n" +
 	"\x06street\x18\x06 \x01(\tR\x06street\x12\x19\n" +
-	"\bzip_code\x18\a \x01(\tR\azipCode\"@\n" +
+	"\bzip_code\x18\a \x01(\tR\azipCode\x12\x1d\n" +
+	"\n" +
+	"is_default\x18\b \x01(\bR\tisDefault\x12\x14\n" +
+	"\x05label\x18\t \x01(\tR\x05label\"@\n" +
 	"\x15CreateAddressResponse\x12'\n" +
 	"\aaddress\x18\x01 \x01(\v2\r.user.AddressR\aaddress\"'\n" +
 	"\x15GetAddressByIDRequest\x12\x0e\n" +
@@ -1299,20 +2438,27 @@ const file_shared_proto_v1_user_proto_rawDesc = "" +
 	"\x1cListAddressesByUserIDRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x05R\x06userId\"L\n" +
 	"\x1dListAddressesByUserIDResponse\x12+\n" +
-	"\taddresses\x18\x01 \x03(\v2\r.user.AddressR\taddresses\"\x9d\x01\n" +
+	"\taddresses\x18\x01 \x03(\v2\r.user.AddressR\taddresses\"\xe1\x01\n" +
 	"\x14UpdateAddressRequest\x12\x18\n" +
 	"\acountry\x18\x01 \x01(\tR\acountry\x12\x12\n" +
 	"\x04city\x18\x02 \x01(\tR\x04city\x12\x14\n" +
 	"\x05state\x18\x03 \x01(\tR\x05state\x12\x16\n" +
 	"\x06street\x18\x04 \x01(\tR\x06street\x12\x19\n" +
 	"\bzip_code\x18\x05 \x01(\tR\azipCode\x12\x0e\n" +
-	"\x02id\x18\x06 \x01(\x05R\x02id\"@\n" +
+	"\x02id\x18\x06 \x01(\x05R\x02id\x12,\n" +
+	"\x12requesting_user_id\x18\a \x01(\x05R\x10requestingUserId\x12\x14\n" +
+	"\x05label\x18\b \x01(\tR\x05label\"@\n" +
 	"\x15UpdateAddressResponse\x12'\n" +
 	"\aaddress\x18\x01 \x01(\v2\r.user.AddressR\aaddress\"&\n" +
 	"\x14DeleteAddressRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x05R\x02id\"1\n" +
 	"\x15DeleteAddressResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xa9\x01\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"C\n" +
+	"\x18SetDefaultAddressRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x05R\x06userId\"D\n" +
+	"\x19SetDefaultAddressResponse\x12'\n" +
+	"\aaddress\x18\x01 \x01(\v2\r.user.AddressR\aaddress\"\xde\x01\n" +
 	"\aAddress\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\x05R\x06userId\x12\x18\n" +
@@ -1320,7 +2466,57 @@ const file_shared_proto_v1_user_proto_rawDesc = "" +
 	"\x04city\x18\x04 \x01(\tR\x04city\x12\x14\n" +
 	"\x05state\x18\x05 \x01(\tR\x05state\x12\x16\n" +
 	"\x06street\x18\x06 \x01(\tR\x06street\x12\x19\n" +
-	"\bzip_code\x18\a \x01(\tR\azipCode2\xfa\x05\n" +
+	"\bzip_code\x18\a \x01(\tR\azipCode\x12\x1d\n" +
+	"\n" +
+	"is_default\x18\b \x01(\bR\tisDefault\x12\x14\n" +
+	"\x05label\x18\t \x01(\tR\x05label\"\xd7\x01\n" +
+	"\x06APIKey\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x12\x16\n" +
+	"\x06scopes\x18\x03 \x03(\tR\x06scopes\x12\x16\n" +
+	"\x06prefix\x18\x04 \x01(\tR\x06prefix\x12\x17\n" +
+	"\auser_id\x18\x05 \x01(\x05R\x06userId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12 \n" +
+	"\flast_used_at\x18\a \x01(\tR\n" +
+	"lastUsedAt\x12\x1d\n" +
+	"\n" +
+	"revoked_at\x18\b \x01(\tR\trevokedAt\"\\\n" +
+	"\x13CreateAPIKeyRequest\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x16\n" +
+	"\x06scopes\x18\x02 \x03(\tR\x06scopes\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x05R\x06userId\"O\n" +
+	"\x14CreateAPIKeyResponse\x12%\n" +
+	"\aapi_key\x18\x01 \x01(\v2\f.user.APIKeyR\x06apiKey\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\"%\n" +
+	"\x13RevokeAPIKeyRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"=\n" +
+	"\x14RevokeAPIKeyResponse\x12%\n" +
+	"\aapi_key\x18\x01 \x01(\v2\f.user.APIKeyR\x06apiKey\"C\n" +
+	"\x12ListAPIKeysRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x19\n" +
+	"\bper_page\x18\x02 \x01(\x05R\aperPage\"T\n" +
+	"\x13ListAPIKeysResponse\x12'\n" +
+	"\bapi_keys\x18\x01 \x03(\v2\f.user.APIKeyR\aapiKeys\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"'\n" +
+	"\x13VerifyAPIKeyRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"=\n" +
+	"\x14VerifyAPIKeyResponse\x12%\n" +
+	"\aapi_key\x18\x01 \x01(\v2\f.user.APIKeyR\x06apiKey\"0\n" +
+	"\x15SetupTwoFactorRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x05R\x06userId\"Q\n" +
+	"\x16SetupTwoFactorResponse\x12\x16\n" +
+	"\x06secret\x18\x01 \x01(\tR\x06secret\x12\x1f\n" +
+	"\votpauth_url\x18\x02 \x01(\tR\n" +
+	"otpauthUrl\"E\n" +
+	"\x16EnableTwoFactorRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x05R\x06userId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"@\n" +
+	"\x17EnableTwoFactorResponse\x12%\n" +
+	"\x0erecovery_codes\x18\x01 \x03(\tR\rrecoveryCodes\"U\n" +
+	"\x16VerifyTwoFactorRequest\x12'\n" +
+	"\x0fchallenge_token\x18\x01 \x01(\tR\x0echallengeToken\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code2\xdd\v\n" +
 	"\vUserService\x12?\n" +
 	"\n" +
 	"CreateUser\x12\x17.user.CreateUserRequest\x1a\x18.user.CreateUserResponse\x120\n" +
@@ -1332,12 +2528,23 @@ const file_shared_proto_v1_user_proto_rawDesc = "" +
 	"UpdateUser\x12\x17.user.UpdateUserRequest\x1a\n" +
 	".user.User\x12?\n" +
 	"\n" +
-	"DeleteUser\x12\x17.user.DeleteUserRequest\x1a\x18.user.DeleteUserResponse\x12H\n" +
+	"DeleteUser\x12\x17.user.DeleteUserRequest\x1a\x18.user.DeleteUserResponse\x12?\n" +
+	"\n" +
+	"UnlockUser\x12\x17.user.UnlockUserRequest\x1a\x18.user.UnlockUserResponse\x12N\n" +
+	"\x0fImpersonateUser\x12\x1c.user.ImpersonateUserRequest\x1a\x1d.user.ImpersonateUserResponse\x12H\n" +
 	"\rCreateAddress\x12\x1a.user.CreateAddressRequest\x1a\x1b.user.CreateAddressResponse\x12K\n" +
 	"\x0eGetAddressByID\x12\x1b.user.GetAddressByIDRequest\x1a\x1c.user.GetAddressByIDResponse\x12`\n" +
 	"\x15ListAddressesByUserID\x12\".user.ListAddressesByUserIDRequest\x1a#.user.ListAddressesByUserIDResponse\x12H\n" +
 	"\rUpdateAddress\x12\x1a.user.UpdateAddressRequest\x1a\x1b.user.UpdateAddressResponse\x12H\n" +
-	"\rDeleteAddress\x12\x1a.user.DeleteAddressRequest\x1a\x1b.user.DeleteAddressResponseB\x1bZ\x19shared/proto/v1/user;userb\x06proto3"
+	"\rDeleteAddress\x12\x1a.user.DeleteAddressRequest\x1a\x1b.user.DeleteAddressResponse\x12T\n" +
+	"\x11SetDefaultAddress\x12\x1e.user.SetDefaultAddressRequest\x1a\x1f.user.SetDefaultAddressResponse\x12E\n" +
+	"\fCreateAPIKey\x12\x19.user.CreateAPIKeyRequest\x1a\x1a.user.CreateAPIKeyResponse\x12E\n" +
+	"\fRevokeAPIKey\x12\x19.user.RevokeAPIKeyRequest\x1a\x1a.user.RevokeAPIKeyResponse\x12B\n" +
+	"\vListAPIKeys\x12\x18.user.ListAPIKeysRequest\x1a\x19.user.ListAPIKeysResponse\x12E\n" +
+	"\fVerifyAPIKey\x12\x19.user.VerifyAPIKeyRequest\x1a\x1a.user.VerifyAPIKeyResponse\x12K\n" +
+	"\x0eSetupTwoFactor\x12\x1b.user.SetupTwoFactorRequest\x1a\x1c.user.SetupTwoFactorResponse\x12N\n" +
+	"\x0fEnableTwoFactor\x12\x1c.user.EnableTwoFactorRequest\x1a\x1d.user.EnableTwoFactorResponse\x12D\n" +
+	"\x0fVerifyTwoFactor\x12\x1c.user.VerifyTwoFactorRequest\x1a\x13.user.LoginResponseB\x1bZ\x19shared/proto/v1/user;userb\x06proto3"
 
 var (
 	file_shared_proto_v1_user_proto_rawDescOnce sync.Once
@@ -1351,7 +2558,7 @@ func file_shared_proto_v1_user_proto_rawDescGZIP() []byte {
 	return file_shared_proto_v1_user_proto_rawDescData
 }
 
-var file_shared_proto_v1_user_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_shared_proto_v1_user_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
 var file_shared_proto_v1_user_proto_goTypes = []any{
 	(*CreateUserRequest)(nil),             // 0: user.CreateUserRequest
 	(*CreateUserResponse)(nil),            // 1: user.CreateUserResponse
@@ -1362,55 +2569,100 @@ var file_shared_proto_v1_user_proto_goTypes = []any{
 	(*UpdateUserRequest)(nil),             // 6: user.UpdateUserRequest
 	(*DeleteUserRequest)(nil),             // 7: user.DeleteUserRequest
 	(*DeleteUserResponse)(nil),            // 8: user.DeleteUserResponse
-	(*SearchUsersResponse)(nil),           // 9: user.SearchUsersResponse
-	(*User)(nil),                          // 10: user.User
-	(*CreateAddressRequest)(nil),          // 11: user.CreateAddressRequest
-	(*CreateAddressResponse)(nil),         // 12: user.CreateAddressResponse
-	(*GetAddressByIDRequest)(nil),         // 13: user.GetAddressByIDRequest
-	(*GetAddressByIDResponse)(nil),        // 14: user.GetAddressByIDResponse
-	(*ListAddressesByUserIDRequest)(nil),  // 15: user.ListAddressesByUserIDRequest
-	(*ListAddressesByUserIDResponse)(nil), // 16: user.ListAddressesByUserIDResponse
-	(*UpdateAddressRequest)(nil),          // 17: user.UpdateAddressRequest
-	(*UpdateAddressResponse)(nil),         // 18: user.UpdateAddressResponse
-	(*DeleteAddressRequest)(nil),          // 19: user.DeleteAddressRequest
-	(*DeleteAddressResponse)(nil),         // 20: user.DeleteAddressResponse
-	(*Address)(nil),                       // 21: user.Address
+	(*UnlockUserRequest)(nil),             // 9: user.UnlockUserRequest
+	(*UnlockUserResponse)(nil),            // 10: user.UnlockUserResponse
+	(*ImpersonateUserRequest)(nil),        // 11: user.ImpersonateUserRequest
+	(*ImpersonateUserResponse)(nil),       // 12: user.ImpersonateUserResponse
+	(*SearchUsersResponse)(nil),           // 13: user.SearchUsersResponse
+	(*User)(nil),                          // 14: user.User
+	(*CreateAddressRequest)(nil),          // 15: user.CreateAddressRequest
+	(*CreateAddressResponse)(nil),         // 16: user.CreateAddressResponse
+	(*GetAddressByIDRequest)(nil),         // 17: user.GetAddressByIDRequest
+	(*GetAddressByIDResponse)(nil),        // 18: user.GetAddressByIDResponse
+	(*ListAddressesByUserIDRequest)(nil),  // 19: user.ListAddressesByUserIDRequest
+	(*ListAddressesByUserIDResponse)(nil), // 20: user.ListAddressesByUserIDResponse
+	(*UpdateAddressRequest)(nil),          // 21: user.UpdateAddressRequest
+	(*UpdateAddressResponse)(nil),         // 22: user.UpdateAddressResponse
+	(*DeleteAddressRequest)(nil),          // 23: user.DeleteAddressRequest
+	(*DeleteAddressResponse)(nil),         // 24: user.DeleteAddressResponse
+	(*SetDefaultAddressRequest)(nil),      // 25: user.SetDefaultAddressRequest
+	(*SetDefaultAddressResponse)(nil),     // 26: user.SetDefaultAddressResponse
+	(*Address)(nil),                       // 27: user.Address
+	(*APIKey)(nil),                        // 28: user.APIKey
+	(*CreateAPIKeyRequest)(nil),           // 29: user.CreateAPIKeyRequest
+	(*CreateAPIKeyResponse)(nil),          // 30: user.CreateAPIKeyResponse
+	(*RevokeAPIKeyRequest)(nil),           // 31: user.RevokeAPIKeyRequest
+	(*RevokeAPIKeyResponse)(nil),          // 32: user.RevokeAPIKeyResponse
+	(*ListAPIKeysRequest)(nil),            // 33: user.ListAPIKeysRequest
+	(*ListAPIKeysResponse)(nil),           // 34: user.ListAPIKeysResponse
+	(*VerifyAPIKeyRequest)(nil),           // 35: user.VerifyAPIKeyRequest
+	(*VerifyAPIKeyResponse)(nil),          // 36: user.VerifyAPIKeyResponse
+	(*SetupTwoFactorRequest)(nil),         // 37: user.SetupTwoFactorRequest
+	(*SetupTwoFactorResponse)(nil),        // 38: user.SetupTwoFactorResponse
+	(*EnableTwoFactorRequest)(nil),        // 39: user.EnableTwoFactorRequest
+	(*EnableTwoFactorResponse)(nil),       // 40: user.EnableTwoFactorResponse
+	(*VerifyTwoFactorRequest)(nil),        // 41: user.VerifyTwoFactorRequest
 }
 var file_shared_proto_v1_user_proto_depIdxs = []int32{
-	10, // 0: user.CreateUserResponse.user:type_name -> user.User
-	10, // 1: user.LoginResponse.user:type_name -> user.User
-	10, // 2: user.SearchUsersResponse.users:type_name -> user.User
-	21, // 3: user.CreateAddressResponse.address:type_name -> user.Address
-	21, // 4: user.GetAddressByIDResponse.address:type_name -> user.Address
-	21, // 5: user.ListAddressesByUserIDResponse.addresses:type_name -> user.Address
-	21, // 6: user.UpdateAddressResponse.address:type_name -> user.Address
-	0,  // 7: user.UserService.CreateUser:input_type -> user.CreateUserRequest
-	2,  // 8: user.UserService.Login:input_type -> user.LoginRequest
-	4,  // 9: user.UserService.GetUserByID:input_type -> user.GetUserByIDRequest
-	5,  // 10: user.UserService.SearchUsers:input_type -> user.SearchUsersRequest
-	6,  // 11: user.UserService.UpdateUser:input_type -> user.UpdateUserRequest
-	7,  // 12: user.UserService.DeleteUser:input_type -> user.DeleteUserRequest
-	11, // 13: user.UserService.CreateAddress:input_type -> user.CreateAddressRequest
-	13, // 14: user.UserService.GetAddressByID:input_type -> user.GetAddressByIDRequest
-	15, // 15: user.UserService.ListAddressesByUserID:input_type -> user.ListAddressesByUserIDRequest
-	17, // 16: user.UserService.UpdateAddress:input_type -> user.UpdateAddressRequest
-	19, // 17: user.UserService.DeleteAddress:input_type -> user.DeleteAddressRequest
-	1,  // 18: user.UserService.CreateUser:output_type -> user.CreateUserResponse
-	3,  // 19: user.UserService.Login:output_type -> user.LoginResponse
-	10, // 20: user.UserService.GetUserByID:output_type -> user.User
-	9,  // 21: user.UserService.SearchUsers:output_type -> user.SearchUsersResponse
-	10, // 22: user.UserService.UpdateUser:output_type -> user.User
-	8,  // 23: user.UserService.DeleteUser:output_type -> user.DeleteUserResponse
-	12, // 24: user.UserService.CreateAddress:output_type -> user.CreateAddressResponse
-	14, // 25: user.UserService.GetAddressByID:output_type -> user.GetAddressByIDResponse
-	16, // 26: user.UserService.ListAddressesByUserID:output_type -> user.ListAddressesByUserIDResponse
-	18, // 27: user.UserService.UpdateAddress:output_type -> user.UpdateAddressResponse
-	20, // 28: user.UserService.DeleteAddress:output_type -> user.DeleteAddressResponse
-	18, // [18:29] is the sub-list for method output_type
-	7,  // [7:18] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	14, // 0: user.CreateUserResponse.user:type_name -> user.User
+	14, // 1: user.LoginResponse.user:type_name -> user.User
+	14, // 2: user.SearchUsersResponse.users:type_name -> user.User
+	27, // 3: user.CreateAddressResponse.address:type_name -> user.Address
+	27, // 4: user.GetAddressByIDResponse.address:type_name -> user.Address
+	27, // 5: user.ListAddressesByUserIDResponse.addresses:type_name -> user.Address
+	27, // 6: user.UpdateAddressResponse.address:type_name -> user.Address
+	27, // 7: user.SetDefaultAddressResponse.address:type_name -> user.Address
+	28, // 8: user.CreateAPIKeyResponse.api_key:type_name -> user.APIKey
+	28, // 9: user.RevokeAPIKeyResponse.api_key:type_name -> user.APIKey
+	28, // 10: user.ListAPIKeysResponse.api_keys:type_name -> user.APIKey
+	28, // 11: user.VerifyAPIKeyResponse.api_key:type_name -> user.APIKey
+	0,  // 12: user.UserService.CreateUser:input_type -> user.CreateUserRequest
+	2,  // 13: user.UserService.Login:input_type -> user.LoginRequest
+	4,  // 14: user.UserService.GetUserByID:input_type -> user.GetUserByIDRequest
+	5,  // 15: user.UserService.SearchUsers:input_type -> user.SearchUsersRequest
+	6,  // 16: user.UserService.UpdateUser:input_type -> user.UpdateUserRequest
+	7,  // 17: user.UserService.DeleteUser:input_type -> user.DeleteUserRequest
+	9,  // 18: user.UserService.UnlockUser:input_type -> user.UnlockUserRequest
+	11, // 19: user.UserService.ImpersonateUser:input_type -> user.ImpersonateUserRequest
+	15, // 20: user.UserService.CreateAddress:input_type -> user.CreateAddressRequest
+	17, // 21: user.UserService.GetAddressByID:input_type -> user.GetAddressByIDRequest
+	19, // 22: user.UserService.ListAddressesByUserID:input_type -> user.ListAddressesByUserIDRequest
+	21, // 23: user.UserService.UpdateAddress:input_type -> user.UpdateAddressRequest
+	23, // 24: user.UserService.DeleteAddress:input_type -> user.DeleteAddressRequest
+	25, // 25: user.UserService.SetDefaultAddress:input_type -> user.SetDefaultAddressRequest
+	29, // 26: user.UserService.CreateAPIKey:input_type -> user.CreateAPIKeyRequest
+	31, // 27: user.UserService.RevokeAPIKey:input_type -> user.RevokeAPIKeyRequest
+	33, // 28: user.UserService.ListAPIKeys:input_type -> user.ListAPIKeysRequest
+	35, // 29: user.UserService.VerifyAPIKey:input_type -> user.VerifyAPIKeyRequest
+	37, // 30: user.UserService.SetupTwoFactor:input_type -> user.SetupTwoFactorRequest
+	39, // 31: user.UserService.EnableTwoFactor:input_type -> user.EnableTwoFactorRequest
+	41, // 32: user.UserService.VerifyTwoFactor:input_type -> user.VerifyTwoFactorRequest
+	1,  // 33: user.UserService.CreateUser:output_type -> user.CreateUserResponse
+	3,  // 34: user.UserService.Login:output_type -> user.LoginResponse
+	14, // 35: user.UserService.GetUserByID:output_type -> user.User
+	13, // 36: user.UserService.SearchUsers:output_type -> user.SearchUsersResponse
+	14, // 37: user.UserService.UpdateUser:output_type -> user.User
+	8,  // 38: user.UserService.DeleteUser:output_type -> user.DeleteUserResponse
+	10, // 39: user.UserService.UnlockUser:output_type -> user.UnlockUserResponse
+	12, // 40: user.UserService.ImpersonateUser:output_type -> user.ImpersonateUserResponse
+	16, // 41: user.UserService.CreateAddress:output_type -> user.CreateAddressResponse
+	18, // 42: user.UserService.GetAddressByID:output_type -> user.GetAddressByIDResponse
+	20, // 43: user.UserService.ListAddressesByUserID:output_type -> user.ListAddressesByUserIDResponse
+	22, // 44: user.UserService.UpdateAddress:output_type -> user.UpdateAddressResponse
+	24, // 45: user.UserService.DeleteAddress:output_type -> user.DeleteAddressResponse
+	26, // 46: user.UserService.SetDefaultAddress:output_type -> user.SetDefaultAddressResponse
+	30, // 47: user.UserService.CreateAPIKey:output_type -> user.CreateAPIKeyResponse
+	32, // 48: user.UserService.RevokeAPIKey:output_type -> user.RevokeAPIKeyResponse
+	34, // 49: user.UserService.ListAPIKeys:output_type -> user.ListAPIKeysResponse
+	36, // 50: user.UserService.VerifyAPIKey:output_type -> user.VerifyAPIKeyResponse
+	38, // 51: user.UserService.SetupTwoFactor:output_type -> user.SetupTwoFactorResponse
+	40, // 52: user.UserService.EnableTwoFactor:output_type -> user.EnableTwoFactorResponse
+	3,  // 53: user.UserService.VerifyTwoFactor:output_type -> user.LoginResponse
+	33, // [33:54] is the sub-list for method output_type
+	12, // [12:33] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_shared_proto_v1_user_proto_init() }
@@ -1424,7 +2676,7 @@ func file_shared_proto_v1_user_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_shared_proto_v1_user_proto_rawDesc), len(file_shared_proto_v1_user_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   22,
+			NumMessages:   42,
 			NumExtensions: 0,
 			NumServices:   1,
 		},